@@ -0,0 +1,18 @@
+package affinity
+
+import "context"
+
+// ctxKey is the context key for the affinity key
+type ctxKey struct{}
+
+// NewContext returns a copy of ctx whose backend calls should stick to the
+// replica chosen for key, usually the signed-in user's ID
+func NewContext(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, key)
+}
+
+// FromContext returns the affinity key carried by ctx, or an empty string
+func FromContext(ctx context.Context) string {
+	key, _ := ctx.Value(ctxKey{}).(string)
+	return key
+}
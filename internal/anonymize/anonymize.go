@@ -0,0 +1,199 @@
+package anonymize
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Kinds of personal data, each pseudonymized in its own format
+const (
+	kindEmail      = "email"
+	kindFullName   = "full_name"
+	kindFirstName  = "first_name"
+	kindLastName   = "last_name"
+	kindPhone      = "phone"
+	kindStreet     = "street"
+	kindPostalCode = "postal_code"
+)
+
+// fieldKinds maps field names, lowercased with separators removed, to the
+// kind of personal data they hold. "name" is only treated as a person's
+// name in objects that also have an email, so product names are kept.
+var fieldKinds = map[string]string{
+	"email":         kindEmail,
+	"firstname":     kindFirstName,
+	"givenname":     kindFirstName,
+	"lastname":      kindLastName,
+	"familyname":    kindLastName,
+	"surname":       kindLastName,
+	"fullname":      kindFullName,
+	"displayname":   kindFullName,
+	"customername":  kindFullName,
+	"recipientname": kindFullName,
+	"authorname":    kindFullName,
+	"phone":         kindPhone,
+	"phonenumber":   kindPhone,
+	"mobile":        kindPhone,
+	"street":        kindStreet,
+	"street2":       kindStreet,
+	"addressline1":  kindStreet,
+	"addressline2":  kindStreet,
+	"line1":         kindStreet,
+	"line2":         kindStreet,
+	"postalcode":    kindPostalCode,
+	"zip":           kindPostalCode,
+	"zipcode":       kindPostalCode,
+	"postcode":      kindPostalCode,
+}
+
+var firstNames = []string{
+	"Alex", "Blair", "Casey", "Dana", "Eli", "Frankie", "Gray", "Harper",
+	"Indy", "Jordan", "Kai", "Logan", "Morgan", "Noel", "Oakley", "Parker",
+	"Quinn", "Riley", "Sage", "Taylor", "Uma", "Val", "Wren", "Xan",
+	"Yael", "Zion", "Avery", "Brook", "Cameron", "Drew", "Emery", "Finley",
+}
+
+var lastNames = []string{
+	"Adams", "Baker", "Carter", "Dalton", "Ellis", "Fisher", "Garcia", "Hayes",
+	"Irwin", "Jensen", "Keller", "Lopez", "Mason", "Novak", "Owens", "Patel",
+	"Quincy", "Reyes", "Sato", "Turner", "Underwood", "Vance", "Walsh", "Xu",
+	"Young", "Zimmer", "Abbott", "Brennan", "Chen", "Dorsey", "Evans", "Flores",
+}
+
+var streetNames = []string{
+	"Maple", "Oak", "Cedar", "Pine", "Elm", "Birch", "Willow", "Aspen",
+	"Lake", "Hill", "River", "Park", "Meadow", "Forest", "Sunset", "Harbor",
+}
+
+var streetSuffixes = []string{"St", "Ave", "Rd", "Ln", "Dr", "Ct", "Way", "Pl"}
+
+// Pseudonymizer replaces personal data in JSON documents with realistic
+// stand-ins. Each stand-in is derived from the real value with a keyed hash,
+// so the same person always gets the same pseudonym and records can still
+// be matched up, but the real value can't be recovered without the key.
+type Pseudonymizer struct {
+	key []byte
+}
+
+// New creates a pseudonymizer keyed with key
+func New(key []byte) *Pseudonymizer {
+	return &Pseudonymizer{key: key}
+}
+
+// JSON returns a copy of a JSON document with personal data pseudonymized
+// at any depth, or the document unchanged if it has none or isn't JSON
+func (p *Pseudonymizer) JSON(data []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return data
+	}
+	if !p.walk(doc) {
+		return data
+	}
+	rewritten, err := json.Marshal(doc)
+	if err != nil {
+		return data
+	}
+	return rewritten
+}
+
+// walk pseudonymizes the personal data in a decoded JSON value, reporting
+// whether anything was replaced
+func (p *Pseudonymizer) walk(value any) bool {
+	changed := false
+	switch v := value.(type) {
+	case map[string]any:
+		_, hasEmail := v["email"]
+		for key, child := range v {
+			s, isString := child.(string)
+			if !isString {
+				changed = p.walk(child) || changed
+				continue
+			}
+			if s == "" {
+				continue
+			}
+
+			kind := fieldKinds[normalizeKey(key)]
+			if kind == "" && key == "name" && hasEmail {
+				kind = kindFullName
+			}
+			if kind != "" {
+				v[key] = p.Value(kind, s)
+				changed = true
+			}
+		}
+	case []any:
+		for _, child := range v {
+			changed = p.walk(child) || changed
+		}
+	}
+	return changed
+}
+
+// Value returns the pseudonym for a value of the given kind
+func (p *Pseudonymizer) Value(kind, value string) string {
+	h := p.hash(kind, value)
+	pick := func(i int, list []string) string {
+		return list[binary.BigEndian.Uint16(h[i:])%uint16(len(list))]
+	}
+
+	switch kind {
+	case kindEmail:
+		return "user-" + hex.EncodeToString(h[:5]) + "@example.com"
+	case kindFirstName:
+		return pick(0, firstNames)
+	case kindLastName:
+		return pick(2, lastNames)
+	case kindFullName:
+		return pick(0, firstNames) + " " + pick(2, lastNames)
+	case kindPhone:
+		return "+1555" + strconv.FormatUint(uint64(binary.BigEndian.Uint32(h[4:])%10000000+10000000), 10)[1:]
+	case kindStreet:
+		number := binary.BigEndian.Uint16(h[4:])%9999 + 1
+		return strconv.Itoa(int(number)) + " " + pick(0, streetNames) + " " + pick(2, streetSuffixes)
+	case kindPostalCode:
+		return reshape(value, h)
+	}
+	return value
+}
+
+// hash derives the bytes a pseudonym is built from
+func (p *Pseudonymizer) hash(kind, value string) []byte {
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte(kind + "\x00" + strings.ToLower(strings.TrimSpace(value))))
+	return mac.Sum(nil)
+}
+
+// reshape replaces each digit and letter of value with one derived from h,
+// keeping the value's format, e.g. "SW1A 1AA" stays letter-digit shaped
+func reshape(value string, h []byte) string {
+	out := []byte(value)
+	for i, c := range out {
+		b := h[i%len(h)] + byte(i/len(h))
+		switch {
+		case c >= '0' && c <= '9':
+			out[i] = '0' + b%10
+		case c >= 'A' && c <= 'Z':
+			out[i] = 'A' + b%26
+		case c >= 'a' && c <= 'z':
+			out[i] = 'a' + b%26
+		}
+	}
+	return string(out)
+}
+
+// normalizeKey lowercases a field name and drops separators, so
+// "postal_code", "postalCode" and "Postal-Code" match alike
+func normalizeKey(key string) string {
+	key = strings.ToLower(key)
+	return strings.NewReplacer("_", "", "-", "", ".", "").Replace(key)
+}
@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// Diff compares two snapshots of a resource field by field, using the JSON
+// field names clients see, and returns the fields whose values differ in
+// name order. Fields listed in ignore, such as timestamps, are skipped.
+func Diff(before, after interface{}, ignore ...string) ([]models.FieldChange, error) {
+	old, err := toFields(before)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := toFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	skip := make(map[string]bool, len(ignore))
+	for _, field := range ignore {
+		skip[field] = true
+	}
+
+	names := make(map[string]bool, len(old)+len(updated))
+	for name := range old {
+		names[name] = true
+	}
+	for name := range updated {
+		names[name] = true
+	}
+
+	changes := []models.FieldChange{}
+	for name := range names {
+		if skip[name] || reflect.DeepEqual(old[name], updated[name]) {
+			continue
+		}
+		changes = append(changes, models.FieldChange{
+			Field:  name,
+			Before: old[name],
+			After:  updated[name],
+		})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes, nil
+}
+
+// toFields flattens a value into its top-level JSON fields
+func toFields(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
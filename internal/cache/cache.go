@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Response is a cached HTTP response
+type Response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// Store holds cached responses until their TTL expires
+type Store interface {
+	// Get returns the response cached under key, or nil if there is none
+	Get(ctx context.Context, key string) (*Response, error)
+	// Set caches a response under key for ttl, tagged so it can be
+	// invalidated with related responses
+	Set(ctx context.Context, key string, response *Response, ttl time.Duration, tags ...string) error
+	// Invalidate removes every response cached with any of the tags
+	Invalidate(ctx context.Context, tags ...string) error
+}
+
+// RedisStore is a Store shared by all gateway replicas
+type RedisStore struct {
+	client *goredis.Client
+	prefix string
+}
+
+// NewRedisStore creates a store using client, namespacing keys with prefix
+func NewRedisStore(client *goredis.Client, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Get returns the cached response, or nil on a miss
+func (s *RedisStore) Get(ctx context.Context, key string) (*Response, error) {
+	data, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var response Response
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Set caches a response for ttl, recording its key in a set per tag. Tag
+// sets live as long as their newest response.
+func (s *RedisStore) Set(ctx context.Context, key string, response *Response, ttl time.Duration, tags ...string) error {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.Set(ctx, s.prefix+key, data, ttl)
+	for _, tag := range tags {
+		pipe.SAdd(ctx, s.tagKey(tag), s.prefix+key)
+		pipe.Expire(ctx, s.tagKey(tag), ttl)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Invalidate deletes the responses recorded under each tag
+func (s *RedisStore) Invalidate(ctx context.Context, tags ...string) error {
+	for _, tag := range tags {
+		keys, err := s.client.SMembers(ctx, s.tagKey(tag)).Result()
+		if err != nil {
+			return err
+		}
+		if err := s.client.Del(ctx, append(keys, s.tagKey(tag))...).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tagKey is the Redis key of the set of responses with a tag
+func (s *RedisStore) tagKey(tag string) string {
+	return s.prefix + "tag:" + tag
+}
@@ -0,0 +1,226 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	goredis "github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// Cache layers reported in metrics
+const (
+	layerMemory  = "memory"
+	layerRedis   = "redis"
+	layerBackend = "backend"
+)
+
+var productCacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "product_cache_lookups_total",
+	Help: "Product lookups by the layer that served them: memory, redis, or backend.",
+}, []string{"layer"})
+
+// Response cache tags for product reads
+const ProductListTag = "products"
+
+// ProductTag tags cached responses showing a single product
+func ProductTag(id string) string {
+	return "product:" + id
+}
+
+// invalidationChannel is the Redis channel replicas use to purge products
+// from each other's local caches
+const invalidationChannel = "cache:invalidate:product"
+
+// ProductCache serves products from a process-local LRU, then Redis when
+// configured, then the listing service. Concurrent misses for the same
+// product share one lookup.
+type ProductCache struct {
+	clients   *grpcclient.Clients
+	lru       *LRU[*models.Product]
+	redis     *goredis.Client
+	redisTTL  time.Duration
+	responses Store
+	publish   bool
+	group     singleflight.Group
+
+	// version counts invalidations seen by this replica. A lookup only
+	// caches what it fetched if no product was invalidated meanwhile, so a
+	// fill that raced a write can't put the old product back.
+	version atomic.Uint64
+}
+
+// NewProductCache creates a product cache. redisClient and responses may be
+// nil.
+func NewProductCache(clients *grpcclient.Clients, redisClient *goredis.Client, responses Store, cfg *config.Config) *ProductCache {
+	return &ProductCache{
+		clients:   clients,
+		lru:       NewLRU[*models.Product](cfg.ProductLRUSize, time.Duration(cfg.ProductLRUTTLSec)*time.Second),
+		redis:     redisClient,
+		redisTTL:  time.Duration(cfg.ProductRedisTTLSec) * time.Second,
+		responses: responses,
+		publish:   redisClient != nil && cfg.CacheInvalidationPubSub,
+	}
+}
+
+// Invalidate purges a changed product from the local LRU, Redis, and cached
+// product responses, and tells other replicas to purge it from their LRUs.
+// Failures are logged; stale entries expire with their TTLs.
+func (p *ProductCache) Invalidate(ctx context.Context, id string) {
+	p.purgeLocal(id)
+	logger := logging.FromContext(ctx)
+
+	if p.redis != nil {
+		if err := p.redis.Del(ctx, productKey(id)).Err(); err != nil {
+			logger.Warn("Failed to invalidate cached product", "product_id", id, "error", err)
+		}
+	}
+	if p.responses != nil {
+		if err := p.responses.Invalidate(ctx, ProductTag(id), ProductListTag); err != nil {
+			logger.Warn("Failed to invalidate cached responses", "product_id", id, "error", err)
+		}
+	}
+	if p.publish {
+		if err := p.redis.Publish(ctx, invalidationChannel, id).Err(); err != nil {
+			logger.Warn("Failed to publish cache invalidation", "product_id", id, "error", err)
+		}
+	}
+}
+
+// Subscribe purges products invalidated by other replicas from the local
+// LRU until the context is cancelled
+func (p *ProductCache) Subscribe(ctx context.Context) {
+	sub := p.redis.Subscribe(ctx, invalidationChannel)
+	defer sub.Close()
+
+	messages := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			p.purgeLocal(msg.Payload)
+		}
+	}
+}
+
+// purgeLocal drops a product from the LRU and keeps lookups already in
+// flight from caching it or sharing it with later callers
+func (p *ProductCache) purgeLocal(id string) {
+	p.version.Add(1)
+	p.group.Forget(id)
+	p.lru.Remove(id)
+}
+
+// Get returns a copy of the product, which the caller may modify
+func (p *ProductCache) Get(ctx context.Context, id string) (*models.Product, error) {
+	if product, ok := p.lru.Get(id); ok {
+		productCacheLookups.WithLabelValues(layerMemory).Inc()
+		return product.Clone(), nil
+	}
+
+	// The shared lookup outlives any one caller's cancellation
+	shared := context.WithoutCancel(ctx)
+	result, err, _ := p.group.Do(id, func() (interface{}, error) {
+		return p.load(shared, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*models.Product).Clone(), nil
+}
+
+// load fetches a product from Redis or the listing service and caches it,
+// unless a product was invalidated while it was being fetched
+func (p *ProductCache) load(ctx context.Context, id string) (*models.Product, error) {
+	version := p.version.Load()
+	if product := p.getRedis(ctx, id); product != nil {
+		productCacheLookups.WithLabelValues(layerRedis).Inc()
+		p.fill(ctx, id, product, version, false)
+		return product, nil
+	}
+
+	product, err := p.clients.GetProduct(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	productCacheLookups.WithLabelValues(layerBackend).Inc()
+	p.fill(ctx, id, product, version, true)
+	return product, nil
+}
+
+// fill caches a fetched product locally, and in Redis if toRedis, unless a
+// product was invalidated since version was read. A fill an invalidation
+// overlaps is undone.
+func (p *ProductCache) fill(ctx context.Context, id string, product *models.Product, version uint64, toRedis bool) {
+	if p.version.Load() != version {
+		return
+	}
+	p.lru.Add(id, product)
+	if toRedis {
+		p.setRedis(ctx, id, product)
+	}
+	if p.version.Load() == version {
+		return
+	}
+	p.lru.Remove(id)
+	if toRedis && p.redis != nil {
+		if err := p.redis.Del(ctx, productKey(id)).Err(); err != nil {
+			logging.FromContext(ctx).Warn("Failed to undo product cache fill", "product_id", id, "error", err)
+		}
+	}
+}
+
+// getRedis returns the product cached in Redis, or nil
+func (p *ProductCache) getRedis(ctx context.Context, id string) *models.Product {
+	if p.redis == nil || p.redisTTL <= 0 {
+		return nil
+	}
+
+	data, err := p.redis.Get(ctx, productKey(id)).Bytes()
+	if err != nil {
+		if !errors.Is(err, goredis.Nil) {
+			logging.FromContext(ctx).Warn("Product cache read failed", "product_id", id, "error", err)
+		}
+		return nil
+	}
+
+	var product models.Product
+	if err := json.Unmarshal(data, &product); err != nil {
+		return nil
+	}
+	return &product
+}
+
+// setRedis caches a product in Redis, logging rather than failing on error
+func (p *ProductCache) setRedis(ctx context.Context, id string, product *models.Product) {
+	if p.redis == nil || p.redisTTL <= 0 {
+		return
+	}
+
+	data, err := json.Marshal(product)
+	if err != nil {
+		return
+	}
+	if err := p.redis.Set(ctx, productKey(id), data, p.redisTTL).Err(); err != nil {
+		logging.FromContext(ctx).Warn("Product cache write failed", "product_id", id, "error", err)
+	}
+}
+
+// productKey is the Redis key for a cached product
+func productKey(id string) string {
+	return "product:" + id
+}
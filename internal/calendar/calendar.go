@@ -0,0 +1,209 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// DateLayout is the layout of calendar dates
+const DateLayout = "2006-01-02"
+
+// region is a parsed holiday calendar
+type region struct {
+	loc      *time.Location // nil to use the country's
+	holidays map[string]string
+}
+
+// Calendar looks up holidays and blackout dates by region. Regions are
+// country codes such as US, or subdivisions such as US-CA that observe
+// their country's holidays as well as their own. Holidays come from the
+// configured file, plus those fetched from an external source if one is
+// configured.
+type Calendar struct {
+	sourceURL string
+	client    *http.Client
+
+	mu         sync.RWMutex
+	configured map[string]*region
+	fetched    map[string]*region
+}
+
+// New creates a calendar of the configured holidays. Invalid dates and time
+// zones are logged and skipped.
+func New(cfg *config.Config) *Calendar {
+	return &Calendar{
+		sourceURL:  cfg.HolidaySourceURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		configured: parse("file", cfg.HolidayCalendar),
+		fetched:    make(map[string]*region),
+	}
+}
+
+// parse validates holiday calendars by region
+func parse(source string, regions map[string]*config.HolidayRegion) map[string]*region {
+	parsed := make(map[string]*region, len(regions))
+	for name, r := range regions {
+		if r == nil {
+			continue
+		}
+		name = strings.ToUpper(name)
+		cal := &region{holidays: make(map[string]string, len(r.Holidays))}
+		if r.TimeZone != "" {
+			loc, err := time.LoadLocation(r.TimeZone)
+			if err != nil {
+				slog.Warn("Invalid holiday calendar time zone, using the country's", "source", source, "region", name, "time_zone", r.TimeZone)
+			} else {
+				cal.loc = loc
+			}
+		}
+		for _, h := range r.Holidays {
+			if _, err := time.Parse(DateLayout, h.Date); err != nil {
+				slog.Warn("Invalid holiday date, ignoring it", "source", source, "region", name, "date", h.Date)
+				continue
+			}
+			cal.holidays[h.Date] = h.Name
+		}
+		parsed[name] = cal
+	}
+	return parsed
+}
+
+// lineage returns the regions whose holidays a region observes: itself,
+// then its country
+func lineage(name string) []string {
+	name = strings.ToUpper(name)
+	if country, _, ok := strings.Cut(name, "-"); ok {
+		return []string{name, country}
+	}
+	return []string{name}
+}
+
+// Holiday returns the holiday on date, as YYYY-MM-DD, in a region, or nil
+// if it is a business day there
+func (c *Calendar) Holiday(name, date string) *models.Holiday {
+	if name == "" {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, r := range lineage(name) {
+		for _, source := range []map[string]*region{c.configured, c.fetched} {
+			if cal, ok := source[r]; ok {
+				if holiday, ok := cal.holidays[date]; ok {
+					return &models.Holiday{Date: date, Name: holiday, Region: r}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// HolidayAt returns the holiday in a region on the local date of t, or nil
+// if it is a business day there
+func (c *Calendar) HolidayAt(name string, t time.Time) *models.Holiday {
+	return c.Holiday(name, t.In(c.Location(name)).Format(DateLayout))
+}
+
+// Location returns the time zone a region's dates are local to: its own,
+// else its country's, else UTC
+func (c *Calendar) Location(name string) *time.Location {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, r := range lineage(name) {
+		for _, source := range []map[string]*region{c.configured, c.fetched} {
+			if cal, ok := source[r]; ok && cal.loc != nil {
+				return cal.loc
+			}
+		}
+	}
+	return time.UTC
+}
+
+// Holidays returns a region's holidays from one date to another inclusive,
+// as YYYY-MM-DD, in date order
+func (c *Calendar) Holidays(name, from, to string) []models.Holiday {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	byDate := make(map[string]models.Holiday)
+	for _, r := range lineage(name) {
+		for _, source := range []map[string]*region{c.configured, c.fetched} {
+			cal, ok := source[r]
+			if !ok {
+				continue
+			}
+			for date, holiday := range cal.holidays {
+				if _, seen := byDate[date]; !seen && date >= from && date <= to {
+					byDate[date] = models.Holiday{Date: date, Name: holiday, Region: r}
+				}
+			}
+		}
+	}
+
+	holidays := make([]models.Holiday, 0, len(byDate))
+	for _, holiday := range byDate {
+		holidays = append(holidays, holiday)
+	}
+	sort.Slice(holidays, func(i, j int) bool { return holidays[i].Date < holidays[j].Date })
+	return holidays
+}
+
+// Refresh fetches holidays from the external source every interval until
+// the context is cancelled. The previously fetched holidays are kept if a
+// fetch fails. It returns at once if no source is configured.
+func (c *Calendar) Refresh(ctx context.Context, interval time.Duration) {
+	if c.sourceURL == "" {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.fetch(ctx); err != nil {
+			slog.Warn("Failed to fetch holiday calendar", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// fetch replaces the fetched holidays with the source's
+func (c *Calendar) fetch(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.sourceURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("holiday source returned status %d", resp.StatusCode)
+	}
+	var regions map[string]*config.HolidayRegion
+	if err := json.NewDecoder(resp.Body).Decode(&regions); err != nil {
+		return err
+	}
+
+	fetched := parse("source", regions)
+	c.mu.Lock()
+	c.fetched = fetched
+	c.mu.Unlock()
+	slog.Debug("Fetched holiday calendar", "regions", len(fetched))
+	return nil
+}
@@ -0,0 +1,419 @@
+package cart
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/ecommerce/be-api-gin/internal/coupon"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	redisclient "github.com/ecommerce/be-api-gin/pkg/redis"
+)
+
+// SessionHeader carries a guest's cart session ID
+const SessionHeader = "X-Cart-Session"
+
+// maxUpdateRetries bounds retries of a Redis update that lost a race
+const maxUpdateRetries = 5
+
+// ErrConflict is returned when a cart update kept losing races with others
+var ErrConflict = errors.New("cart was modified concurrently")
+
+// sessionPattern matches session IDs issued by NewSessionID
+var sessionPattern = regexp.MustCompile(`^cs-[0-9a-f]{32}$`)
+
+// NewSessionID creates an ID for a guest's cart session
+func NewSessionID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "cs-" + hex.EncodeToString(buf), nil
+}
+
+// ValidSessionID reports whether id is a well-formed session ID
+func ValidSessionID(id string) bool {
+	return sessionPattern.MatchString(id)
+}
+
+// UserOwner returns the owner key of a signed-in user's cart
+func UserOwner(userID string) string {
+	return "user:" + userID
+}
+
+// GuestOwner returns the owner key of a guest session's cart
+func GuestOwner(sessionID string) string {
+	return "guest:" + sessionID
+}
+
+// Recalculate updates a cart's totals from its items and coupon
+func Recalculate(cart *models.Cart) {
+	cart.ItemCount = 0
+	subtotal := 0.0
+	for i := range cart.Items {
+		item := &cart.Items[i]
+		item.LineTotal = roundCents(item.UnitPrice * float64(item.Quantity))
+		cart.ItemCount += int(item.Quantity)
+		subtotal += item.LineTotal
+	}
+	cart.Subtotal = roundCents(subtotal)
+	cart.Discount = 0
+	if cart.Coupon != nil {
+		cart.Discount = coupon.Discount(cart.Coupon.Type, cart.Coupon.Value, cart.Subtotal)
+	}
+	cart.Total = roundCents(cart.Subtotal - cart.Discount)
+}
+
+// roundCents rounds an amount to whole cents
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// Strategies for merging a guest cart into a user's cart when both hold the
+// same product
+const (
+	MergeSum = "sum" // add the quantities
+	MergeMax = "max" // keep the larger quantity
+)
+
+// ErrUnknownStrategy is returned for a merge strategy other than MergeSum
+// and MergeMax
+var ErrUnknownStrategy = errors.New("unknown cart merge strategy")
+
+// ValidateStrategy returns ErrUnknownStrategy unless strategy is MergeSum or
+// MergeMax
+func ValidateStrategy(strategy string) error {
+	if strategy != MergeSum && strategy != MergeMax {
+		return fmt.Errorf("%w %q, want %q or %q", ErrUnknownStrategy, strategy, MergeSum, MergeMax)
+	}
+	return nil
+}
+
+// MergeResult counts what happened to a guest cart's lines in a merge
+type MergeResult struct {
+	Added    int // products only in the guest cart
+	Combined int // products in both carts
+	Dropped  int // products left out because the user's cart was full or they are out of stock
+}
+
+// Merge moves the items of a guest cart into a user's cart. Products in both
+// are combined with strategy, keeping the user's price snapshot; quantities
+// are capped at maxQuantity and at the product's stock where stock has it,
+// and products beyond maxItems or out of stock are dropped. The guest's
+// coupon carries over if the user's cart has none.
+func Merge(user, guest *models.Cart, strategy string, maxItems, maxQuantity int, stock map[string]int32) MergeResult {
+	var result MergeResult
+	if user.Coupon == nil {
+		user.Coupon = guest.Coupon
+	}
+	for _, item := range guest.Items {
+		limit := int32(maxQuantity)
+		if available, ok := stock[item.ProductID]; ok {
+			limit = min(limit, available)
+		}
+
+		existing := -1
+		for i := range user.Items {
+			if user.Items[i].ProductID == item.ProductID {
+				existing = i
+				break
+			}
+		}
+
+		if existing < 0 {
+			if len(user.Items) >= maxItems || limit <= 0 {
+				result.Dropped++
+				continue
+			}
+			item.Quantity = min(item.Quantity, limit)
+			user.Items = append(user.Items, item)
+			result.Added++
+			continue
+		}
+
+		// The guest's quantity is capped, but the user's own line is never
+		// reduced
+		line := &user.Items[existing]
+		merged := line.Quantity + item.Quantity
+		if strategy == MergeMax {
+			merged = max(line.Quantity, item.Quantity)
+		}
+		line.Quantity = max(line.Quantity, min(merged, limit))
+		result.Combined++
+	}
+	return result
+}
+
+// RemoveOrdered takes the ordered quantities of each product out of a cart,
+// dropping lines with nothing left, and drops the coupon if the order used
+// it. Items added or increased since the order was read from the cart stay.
+func RemoveOrdered(cart *models.Cart, items []models.CreateOrderItem, couponCode string) {
+	ordered := make(map[string]int32, len(items))
+	for _, item := range items {
+		ordered[item.ProductID] += item.Quantity
+	}
+
+	kept := cart.Items[:0]
+	for _, item := range cart.Items {
+		item.Quantity -= ordered[item.ProductID]
+		if item.Quantity > 0 {
+			kept = append(kept, item)
+		}
+	}
+	cart.Items = kept
+	if cart.Coupon != nil && couponCode != "" && strings.EqualFold(cart.Coupon.Code, couponCode) {
+		cart.Coupon = nil
+	}
+}
+
+// Store holds carts by owner key
+type Store interface {
+	// Get returns the owner's cart, or nil if it has none
+	Get(ctx context.Context, owner string) (*models.Cart, error)
+	// Update applies fn to the owner's cart, creating an empty one if it
+	// has none, and saves the result with its totals recalculated to expire
+	// after ttl. Nothing is saved if fn returns an error.
+	Update(ctx context.Context, owner string, ttl time.Duration, fn func(cart *models.Cart) error) (*models.Cart, error)
+	// Delete removes the owner's cart
+	Delete(ctx context.Context, owner string) error
+	// Take removes the owner's cart and returns it, or nil if it has none.
+	// Of concurrent callers, only one gets the cart.
+	Take(ctx context.Context, owner string) (*models.Cart, error)
+	// Purge removes the carts of owners starting with ownerPrefix that were
+	// last updated before the given time, returning how many it removed
+	Purge(ctx context.Context, ownerPrefix string, before time.Time) (int, error)
+}
+
+// MemoryStore is an in-process Store. Carts are only visible to the gateway
+// instance that holds them.
+type MemoryStore struct {
+	mu    sync.Mutex
+	carts map[string]*memoryCart
+}
+
+// memoryCart is a cart with its expiry
+type memoryCart struct {
+	cart      *models.Cart
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		carts: make(map[string]*memoryCart),
+	}
+}
+
+// Get returns the owner's unexpired cart
+func (s *MemoryStore) Get(ctx context.Context, owner string) (*models.Cart, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.carts[owner]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, nil
+	}
+	return cloneCart(entry.cart), nil
+}
+
+// Update applies fn to the owner's cart under the store's lock
+func (s *MemoryStore) Update(ctx context.Context, owner string, ttl time.Duration, fn func(cart *models.Cart) error) (*models.Cart, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Purge expired carts
+	now := time.Now()
+	for key, entry := range s.carts {
+		if now.After(entry.expiresAt) {
+			delete(s.carts, key)
+		}
+	}
+
+	cart := &models.Cart{Items: []models.CartItem{}}
+	if entry, ok := s.carts[owner]; ok {
+		cart = cloneCart(entry.cart)
+	}
+	if err := fn(cart); err != nil {
+		return nil, err
+	}
+	Recalculate(cart)
+	cart.UpdatedAt = models.TimestampPtr(now)
+	s.carts[owner] = &memoryCart{cart: cloneCart(cart), expiresAt: now.Add(ttl)}
+	return cart, nil
+}
+
+// Delete removes the owner's cart
+func (s *MemoryStore) Delete(ctx context.Context, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.carts, owner)
+	return nil
+}
+
+// Take removes and returns the owner's unexpired cart under the store's lock
+func (s *MemoryStore) Take(ctx context.Context, owner string) (*models.Cart, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.carts[owner]
+	if !ok {
+		return nil, nil
+	}
+	delete(s.carts, owner)
+	if time.Now().After(entry.expiresAt) {
+		return nil, nil
+	}
+	return entry.cart, nil
+}
+
+// Purge removes matching carts last updated before the given time
+func (s *MemoryStore) Purge(ctx context.Context, ownerPrefix string, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purged := 0
+	for owner, entry := range s.carts {
+		if strings.HasPrefix(owner, ownerPrefix) && updatedBefore(entry.cart, before) {
+			delete(s.carts, owner)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// updatedBefore reports whether a cart was last updated before the given time
+func updatedBefore(cart *models.Cart, before time.Time) bool {
+	return cart.UpdatedAt == nil || cart.UpdatedAt.Time.Before(before)
+}
+
+// cloneCart returns a copy of cart that shares no items with it
+func cloneCart(cart *models.Cart) *models.Cart {
+	clone := *cart
+	clone.Items = append([]models.CartItem{}, cart.Items...)
+	return &clone
+}
+
+// RedisStore is a Store shared by all gateway replicas. Each cart is a JSON
+// value updated with optimistic locking, so concurrent changes to the same
+// cart from several devices are not lost.
+type RedisStore struct {
+	client *goredis.Client
+	prefix string
+}
+
+// NewRedisStore creates a store using client, namespacing keys with prefix
+func NewRedisStore(client *goredis.Client, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Get returns the owner's cart
+func (s *RedisStore) Get(ctx context.Context, owner string) (*models.Cart, error) {
+	return s.get(ctx, s.client, owner)
+}
+
+// get reads a cart with cmd, which may be a transaction
+func (s *RedisStore) get(ctx context.Context, cmd goredis.Cmdable, owner string) (*models.Cart, error) {
+	data, err := cmd.Get(ctx, s.prefix+owner).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cart models.Cart
+	if err := json.Unmarshal(data, &cart); err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// Update applies fn to the owner's cart, retrying if another update to the
+// same cart lands in between
+func (s *RedisStore) Update(ctx context.Context, owner string, ttl time.Duration, fn func(cart *models.Cart) error) (*models.Cart, error) {
+	key := s.prefix + owner
+	var updated *models.Cart
+	txn := func(tx *goredis.Tx) error {
+		cart, err := s.get(ctx, tx, owner)
+		if err != nil {
+			return err
+		}
+		if cart == nil {
+			cart = &models.Cart{Items: []models.CartItem{}}
+		}
+		if err := fn(cart); err != nil {
+			return err
+		}
+		Recalculate(cart)
+		cart.UpdatedAt = models.TimestampPtr(time.Now())
+		data, err := json.Marshal(cart)
+		if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+			pipe.Set(ctx, key, data, ttl)
+			return nil
+		})
+		if err == nil {
+			updated = cart
+		}
+		return err
+	}
+
+	for i := 0; i < maxUpdateRetries; i++ {
+		err := s.client.Watch(ctx, txn, key)
+		if errors.Is(err, goredis.TxFailedErr) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return updated, nil
+	}
+	return nil, ErrConflict
+}
+
+// Delete removes the owner's cart
+func (s *RedisStore) Delete(ctx context.Context, owner string) error {
+	return s.client.Del(ctx, s.prefix+owner).Err()
+}
+
+// Take removes and returns the owner's cart with GETDEL, so it is read and
+// deleted in one step
+func (s *RedisStore) Take(ctx context.Context, owner string) (*models.Cart, error) {
+	data, err := s.client.GetDel(ctx, s.prefix+owner).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cart models.Cart
+	if err := json.Unmarshal(data, &cart); err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// Purge removes matching carts last updated before the given time
+func (s *RedisStore) Purge(ctx context.Context, ownerPrefix string, before time.Time) (int, error) {
+	return redisclient.Purge(ctx, s.client, s.prefix+ownerPrefix+"*", func(value []byte) bool {
+		var cart models.Cart
+		return json.Unmarshal(value, &cart) == nil && updatedBefore(&cart, before)
+	})
+}
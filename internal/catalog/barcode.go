@@ -0,0 +1,93 @@
+package catalog
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// gtinLength is the length barcodes are stored at, zero-padded on the left
+const gtinLength = 14
+
+// gtinLengths are the lengths of the GTIN family: EAN-8, UPC-A, EAN-13,
+// and GTIN-14
+var gtinLengths = map[int]bool{8: true, 12: true, 13: true, 14: true}
+
+// barcodeSeparators are dropped from barcodes typed in by hand
+var barcodeSeparators = strings.NewReplacer(" ", "", "-", "")
+
+// NormalizeBarcode returns the 14-digit GTIN a scanned barcode encodes, so
+// the UPC-A and EAN-13 forms of a code match. It accepts the digits of an
+// EAN-8, UPC-A, EAN-13, or GTIN-14, or a GS1 Digital Link URL as printed in
+// QR codes (https://id.gs1.org/01/09506000134352), and checks the check
+// digit.
+func NormalizeBarcode(code string) (string, error) {
+	digits := barcodeSeparators.Replace(strings.TrimSpace(code))
+	if strings.Contains(digits, "/") {
+		gtin, err := digitalLinkGTIN(digits)
+		if err != nil {
+			return "", err
+		}
+		digits = gtin
+	}
+
+	if !gtinLengths[len(digits)] {
+		return "", fmt.Errorf("barcode %q must have 8, 12, 13, or 14 digits", code)
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", fmt.Errorf("barcode %q must only contain digits", code)
+		}
+	}
+	if !validCheckDigit(digits) {
+		return "", fmt.Errorf("barcode %q has an invalid check digit", code)
+	}
+	return strings.Repeat("0", gtinLength-len(digits)) + digits, nil
+}
+
+// NormalizeBarcodes normalizes a product's barcodes, dropping duplicates
+func NormalizeBarcodes(codes []string) ([]string, error) {
+	normalized := make([]string, 0, len(codes))
+	seen := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		gtin, err := NormalizeBarcode(code)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[gtin] {
+			seen[gtin] = true
+			normalized = append(normalized, gtin)
+		}
+	}
+	return normalized, nil
+}
+
+// digitalLinkGTIN returns the GTIN following the "01" application
+// identifier in a GS1 Digital Link URL
+func digitalLinkGTIN(link string) (string, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", fmt.Errorf("barcode %q is not a valid link", link)
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		if segments[i] == "01" || segments[i] == "gtin" {
+			return segments[i+1], nil
+		}
+	}
+	return "", fmt.Errorf("link %q does not name a GTIN", link)
+}
+
+// validCheckDigit reports whether the last digit of a GTIN matches the GS1
+// check digit of the others: weights alternate 3 and 1 from the right
+func validCheckDigit(digits string) bool {
+	sum := 0
+	for i := len(digits) - 2; i >= 0; i-- {
+		weight := 3
+		if (len(digits)-2-i)%2 == 1 {
+			weight = 1
+		}
+		sum += int(digits[i]-'0') * weight
+	}
+	return (10-sum%10)%10 == int(digits[len(digits)-1]-'0')
+}
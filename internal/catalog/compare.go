@@ -0,0 +1,80 @@
+package catalog
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// attributeKeySeparators are replaced with underscores so keys such as
+// "Screen Size" and "screen-size" line up
+var attributeKeySeparators = strings.NewReplacer(" ", "_", "-", "_")
+
+// Compare lines up the attributes of products side by side. Keys are
+// matched case-insensitively, ignoring whether words are separated by
+// spaces, hyphens, or underscores. Each row has one value per product, in
+// the order given, nil where a product lacks the attribute, and is marked as
+// differing if any two values differ, ignoring case and surrounding
+// whitespace. Rows are sorted by key.
+func Compare(products []*models.Product) *models.ProductComparison {
+	comparison := &models.ProductComparison{
+		Products:   make([]models.ComparedProduct, len(products)),
+		Attributes: []models.ComparisonRow{},
+	}
+
+	rows := make(map[string]*models.ComparisonRow)
+	for i, product := range products {
+		comparison.Products[i] = models.ComparedProduct{
+			ID:       product.ID,
+			Name:     product.Name,
+			Price:    product.Price,
+			Currency: product.Currency,
+			Category: product.Category,
+			ImageUrl: product.ImageUrl,
+			InStock:  product.InStock,
+		}
+		for key, value := range product.Attributes {
+			key = normalizeAttributeKey(key)
+			if key == "" {
+				continue
+			}
+			row, ok := rows[key]
+			if !ok {
+				row = &models.ComparisonRow{Key: key, Values: make([]*string, len(products))}
+				rows[key] = row
+			}
+			value := strings.TrimSpace(value)
+			row.Values[i] = &value
+		}
+	}
+
+	for _, row := range rows {
+		row.Differs = differs(row.Values)
+		comparison.Attributes = append(comparison.Attributes, *row)
+	}
+	sort.Slice(comparison.Attributes, func(i, j int) bool {
+		return comparison.Attributes[i].Key < comparison.Attributes[j].Key
+	})
+	return comparison
+}
+
+// normalizeAttributeKey lower-cases a key and joins its words with
+// underscores
+func normalizeAttributeKey(key string) string {
+	key = attributeKeySeparators.Replace(strings.ToLower(strings.TrimSpace(key)))
+	for strings.Contains(key, "__") {
+		key = strings.ReplaceAll(key, "__", "_")
+	}
+	return strings.Trim(key, "_")
+}
+
+// differs reports whether values are not all present and equal
+func differs(values []*string) bool {
+	for _, value := range values {
+		if value == nil || !strings.EqualFold(*value, *values[0]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package catalog
+
+import (
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// Duplicate policies
+const (
+	DuplicatePolicyOff   = "off"
+	DuplicatePolicyWarn  = "warn"
+	DuplicatePolicyBlock = "block"
+)
+
+// Similarity weights. Signals that are absent on either side are left out
+// and the remaining weights are renormalized.
+const (
+	titleWeight     = 0.5
+	attributeWeight = 0.2
+	imageWeight     = 0.3
+
+	// maxImageHashDistance is the largest Hamming distance between two 64-bit
+	// perceptual hashes still considered the same image
+	maxImageHashDistance = 6
+)
+
+// FindDuplicates returns the existing products whose similarity to the
+// candidate meets the threshold, most similar first
+func FindDuplicates(candidate *models.Product, existing []*models.Product, threshold float64) []models.DuplicateMatch {
+	var matches []models.DuplicateMatch
+	seen := make(map[string]bool, len(existing))
+	for _, p := range existing {
+		if p.ID == candidate.ID || seen[p.ID] {
+			continue
+		}
+		seen[p.ID] = true
+
+		score, reasons := Similarity(candidate, p)
+		if score >= threshold {
+			matches = append(matches, models.DuplicateMatch{
+				ProductID:   p.ID,
+				ProductName: p.Name,
+				SellerID:    p.SellerID,
+				Score:       score,
+				Reasons:     reasons,
+			})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches
+}
+
+// Similarity scores how alike two products are from 0 to 1 based on title,
+// attributes, and image hashes, along with the signals that matched
+func Similarity(a, b *models.Product) (float64, []string) {
+	var score, weight float64
+	var reasons []string
+
+	titleScore := jaccard(titleTokens(a.Name), titleTokens(b.Name))
+	score += titleScore * titleWeight
+	weight += titleWeight
+	if titleScore >= 0.8 {
+		reasons = append(reasons, "similar_title")
+	}
+
+	if len(a.Attributes) > 0 && len(b.Attributes) > 0 {
+		attrScore := attributeOverlap(a.Attributes, b.Attributes)
+		score += attrScore * attributeWeight
+		weight += attributeWeight
+		if attrScore >= 0.8 {
+			reasons = append(reasons, "matching_attributes")
+		}
+	}
+
+	if len(a.ImageHashes) > 0 && len(b.ImageHashes) > 0 {
+		imgScore := 0.0
+		if sharesImage(a.ImageHashes, b.ImageHashes) {
+			imgScore = 1
+			reasons = append(reasons, "matching_image")
+		}
+		score += imgScore * imageWeight
+		weight += imageWeight
+	}
+
+	return score / weight, reasons
+}
+
+// titleTokens normalizes a title into a set of lowercase words
+func titleTokens(title string) map[string]bool {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	tokens := make(map[string]bool, len(words))
+	for _, w := range words {
+		tokens[w] = true
+	}
+	return tokens
+}
+
+// jaccard returns the Jaccard index of two sets
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	intersection := 0
+	for k := range a {
+		if b[k] {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
+
+// attributeOverlap returns the fraction of shared attribute keys whose
+// values match, ignoring case and surrounding whitespace
+func attributeOverlap(a, b map[string]string) float64 {
+	shared, equal := 0, 0
+	for k, va := range a {
+		vb, ok := b[k]
+		if !ok {
+			continue
+		}
+		shared++
+		if strings.EqualFold(strings.TrimSpace(va), strings.TrimSpace(vb)) {
+			equal++
+		}
+	}
+	if shared == 0 {
+		return 0
+	}
+	return float64(equal) / float64(shared)
+}
+
+// sharesImage reports whether any pair of 64-bit hex perceptual hashes are
+// within the match distance
+func sharesImage(a, b []string) bool {
+	for _, ha := range a {
+		va, err := strconv.ParseUint(ha, 16, 64)
+		if err != nil {
+			continue
+		}
+		for _, hb := range b {
+			vb, err := strconv.ParseUint(hb, 16, 64)
+			if err != nil {
+				continue
+			}
+			if bits.OnesCount64(va^vb) <= maxImageHashDistance {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package catalog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// Finding severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Lint thresholds
+const (
+	minDescriptionLength = 50
+	minRecommendedImages = 3
+	minPrice             = 0.5
+	maxPrice             = 100000
+	priceOutlierFactor   = 10
+)
+
+// RequiredAttributes lists the attributes shoppers expect for each category
+var RequiredAttributes = map[string][]string{
+	"electronics": {"brand", "model", "warranty"},
+	"clothing":    {"size", "color", "material"},
+	"shoes":       {"size", "color"},
+	"books":       {"author", "isbn"},
+	"furniture":   {"dimensions", "material"},
+}
+
+// Lint scans products for catalog quality problems. Prices are also compared
+// against the median price of the seller's other products in the same category.
+func Lint(products []*models.Product) []models.CatalogIssue {
+	medians := categoryMedians(products)
+
+	var issues []models.CatalogIssue
+	for _, p := range products {
+		add := func(code, field, severity, message, suggestion string) {
+			issues = append(issues, models.CatalogIssue{
+				ProductID:   p.ID,
+				ProductName: p.Name,
+				Code:        code,
+				Field:       field,
+				Severity:    severity,
+				Message:     message,
+				Suggestion:  suggestion,
+			})
+		}
+
+		// Images
+		switch {
+		case len(p.Images) == 0:
+			add("missing_images", "images", SeverityError,
+				"Product has no images",
+				"Add at least one clear photo of the product")
+		case len(p.Images) < minRecommendedImages:
+			add("few_images", "images", SeverityInfo,
+				fmt.Sprintf("Product has %d image(s)", len(p.Images)),
+				fmt.Sprintf("Listings with %d or more images convert better", minRecommendedImages))
+		}
+
+		// Description
+		description := strings.TrimSpace(p.Description)
+		switch {
+		case description == "":
+			add("missing_description", "description", SeverityError,
+				"Product has no description",
+				"Describe the product's features, condition, and what is included")
+		case len(description) < minDescriptionLength:
+			add("short_description", "description", SeverityWarning,
+				fmt.Sprintf("Description is only %d characters", len(description)),
+				fmt.Sprintf("Expand the description to at least %d characters", minDescriptionLength))
+		}
+
+		// Category
+		category := strings.ToLower(strings.TrimSpace(p.Category))
+		if category == "" {
+			add("missing_category", "category", SeverityError,
+				"Product has no category",
+				"Assign a category so shoppers can find the product")
+		}
+
+		// Price
+		switch {
+		case p.Price <= 0:
+			add("invalid_price", "price", SeverityError,
+				"Product price is zero or negative",
+				"Set a positive price")
+		case p.Price < minPrice:
+			add("suspicious_price", "price", SeverityWarning,
+				fmt.Sprintf("Price %.2f is unusually low", p.Price),
+				"Check the price for a misplaced decimal point")
+		case p.Price > maxPrice:
+			add("suspicious_price", "price", SeverityWarning,
+				fmt.Sprintf("Price %.2f is unusually high", p.Price),
+				"Check the price for extra digits")
+		default:
+			if median, ok := medians[category]; ok && median > 0 {
+				if p.Price > median*priceOutlierFactor || p.Price < median/priceOutlierFactor {
+					add("price_outlier", "price", SeverityWarning,
+						fmt.Sprintf("Price %.2f differs greatly from your category median of %.2f", p.Price, median),
+						"Confirm the price is correct for this product")
+				}
+			}
+		}
+
+		// Category attributes
+		for _, attr := range RequiredAttributes[category] {
+			if strings.TrimSpace(p.Attributes[attr]) == "" {
+				add("missing_attribute", "attributes."+attr, SeverityWarning,
+					fmt.Sprintf("Missing %q attribute expected for %s", attr, category),
+					fmt.Sprintf("Add the %q attribute", attr))
+			}
+		}
+	}
+
+	return issues
+}
+
+// PublishBlockers returns the error-severity issues that prevent a product
+// from being published. A product must also have a seller to answer for it.
+func PublishBlockers(product *models.Product) []models.CatalogIssue {
+	var blockers []models.CatalogIssue
+	if product.SellerID == "" {
+		blockers = append(blockers, models.CatalogIssue{
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			Code:        "missing_seller",
+			Field:       "seller_id",
+			Severity:    SeverityError,
+			Message:     "Product has no seller",
+			Suggestion:  "Assign the product to a seller before publishing it",
+		})
+	}
+	for _, issue := range Lint([]*models.Product{product}) {
+		if issue.Severity == SeverityError {
+			blockers = append(blockers, issue)
+		}
+	}
+	return blockers
+}
+
+// categoryMedians returns the median price per category, only for
+// categories with enough products to make the comparison meaningful
+func categoryMedians(products []*models.Product) map[string]float64 {
+	prices := make(map[string][]float64)
+	for _, p := range products {
+		if p.Price <= 0 {
+			continue
+		}
+		category := strings.ToLower(strings.TrimSpace(p.Category))
+		prices[category] = append(prices[category], p.Price)
+	}
+
+	medians := make(map[string]float64, len(prices))
+	for category, list := range prices {
+		if category == "" || len(list) < 3 {
+			continue
+		}
+		sort.Float64s(list)
+		mid := len(list) / 2
+		if len(list)%2 == 0 {
+			medians[category] = (list[mid-1] + list[mid]) / 2
+		} else {
+			medians[category] = list[mid]
+		}
+	}
+	return medians
+}
@@ -0,0 +1,86 @@
+package catalog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// fitMessages tell shoppers what to make of each fit hint
+var fitMessages = map[models.Fit]string{
+	models.FitRunsSmall:  "Most customers say this runs small; consider a size up",
+	models.FitTrueToSize: "Most customers say this fits true to size",
+	models.FitRunsLarge:  "Most customers say this runs large; consider a size down",
+}
+
+// NewSizeGuide checks a size guide request and returns the guide it sets.
+// Measurement names are lower-cased and must be distinct, as must size
+// labels, and every size must give a positive value for each measurement
+// and nothing else.
+func NewSizeGuide(req *models.SizeGuideRequest) (*models.SizeGuide, error) {
+	guide := &models.SizeGuide{
+		Unit:         req.Unit,
+		Measurements: make([]string, len(req.Measurements)),
+		Sizes:        make([]models.SizeGuideSize, len(req.Sizes)),
+	}
+
+	measured := make(map[string]bool, len(req.Measurements))
+	for i, name := range req.Measurements {
+		name = normalizeAttributeKey(name)
+		if name == "" {
+			return nil, fmt.Errorf("measurement %d has no name", i+1)
+		}
+		if measured[name] {
+			return nil, fmt.Errorf("measurement %q is listed twice", name)
+		}
+		measured[name] = true
+		guide.Measurements[i] = name
+	}
+
+	labels := make(map[string]bool, len(req.Sizes))
+	for i, size := range req.Sizes {
+		if labels[strings.ToUpper(size.Label)] {
+			return nil, fmt.Errorf("size %q is listed twice", size.Label)
+		}
+		labels[strings.ToUpper(size.Label)] = true
+
+		values := make(map[string]float64, len(size.Values))
+		for name, value := range size.Values {
+			name = normalizeAttributeKey(name)
+			if !measured[name] {
+				return nil, fmt.Errorf("size %q gives %q, which is not one of the measurements", size.Label, name)
+			}
+			if value <= 0 {
+				return nil, fmt.Errorf("size %q must give a positive %s", size.Label, name)
+			}
+			values[name] = value
+		}
+		if len(values) != len(guide.Measurements) {
+			return nil, fmt.Errorf("size %q must give every measurement", size.Label)
+		}
+		guide.Sizes[i] = models.SizeGuideSize{Label: size.Label, Values: values}
+	}
+	return guide, nil
+}
+
+// FitHint sets the summary's hint to the fit most reviewers report, once at
+// least minResponses have reported one. With no majority there is no hint.
+func FitHint(summary *models.FitSummary, minResponses int) {
+	summary.Hint, summary.Message = "", ""
+	if summary.Responses == 0 || summary.Responses < int64(minResponses) {
+		return
+	}
+	counts := map[models.Fit]int64{
+		models.FitRunsSmall:  summary.RunsSmall,
+		models.FitTrueToSize: summary.TrueToSize,
+		models.FitRunsLarge:  summary.RunsLarge,
+	}
+	for _, fit := range models.Fits {
+		if counts[fit]*2 > summary.Responses {
+			summary.Hint = fit
+			summary.Message = fitMessages[fit]
+			return
+		}
+	}
+}
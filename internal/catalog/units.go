@@ -0,0 +1,217 @@
+package catalog
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Unit systems for displaying measurements
+const (
+	UnitSystemMetric   = "metric"
+	UnitSystemImperial = "imperial"
+)
+
+// Canonical storage units
+const (
+	canonicalWeightUnit = "kg"
+	canonicalLengthUnit = "cm"
+)
+
+// weightUnits converts weight units to kilograms
+var weightUnits = map[string]float64{
+	"kg": 1, "kgs": 1, "kilogram": 1, "kilograms": 1,
+	"g": 0.001, "gram": 0.001, "grams": 0.001,
+	"lb": 0.45359237, "lbs": 0.45359237, "pound": 0.45359237, "pounds": 0.45359237,
+	"oz": 0.028349523125, "ounce": 0.028349523125, "ounces": 0.028349523125,
+}
+
+// lengthUnits converts length units to centimeters
+var lengthUnits = map[string]float64{
+	"cm": 1, "centimeter": 1, "centimeters": 1,
+	"mm": 0.1, "millimeter": 0.1, "millimeters": 0.1,
+	"m": 100, "meter": 100, "meters": 100,
+	"in": 2.54, "inch": 2.54, "inches": 2.54, `"`: 2.54,
+	"ft": 30.48, "foot": 30.48, "feet": 30.48, "'": 30.48,
+}
+
+// Attributes holding measurements, by the kind of unit they take
+var (
+	weightAttributes    = map[string]bool{"weight": true, "shipping_weight": true}
+	lengthAttributes    = map[string]bool{"length": true, "width": true, "height": true, "depth": true}
+	dimensionAttributes = map[string]bool{"dimensions": true}
+)
+
+// imperialRegions display measurements in pounds and inches
+var imperialRegions = map[string]bool{"us": true, "lr": true, "mm": true}
+
+// quantityPattern matches a number followed by an optional unit
+var quantityPattern = regexp.MustCompile(`^(\d+(?:[.,]\d+)?)\s*([a-zA-Z"']*)$`)
+
+// dimensionSeparator splits "30 x 20 x 10 cm" into its parts
+var dimensionSeparator = regexp.MustCompile(`\s*[xX×*]\s*`)
+
+// UnitError describes a measurement attribute that couldn't be parsed
+type UnitError struct {
+	Attribute string
+	Value     string
+}
+
+func (e *UnitError) Error() string {
+	return fmt.Sprintf("attribute %q has unrecognized measurement %q; include a unit such as kg, lb, cm, or in", e.Attribute, e.Value)
+}
+
+// NormalizeUnits converts measurement attributes to canonical units in
+// place: weights to kilograms and lengths and dimensions to centimeters,
+// e.g. "2 lb" becomes "0.907 kg" and "12x8x4 in" becomes "30.48 x 20.32 x
+// 10.16 cm". Other attributes are left unchanged.
+func NormalizeUnits(attributes map[string]string) error {
+	for name, value := range attributes {
+		key := strings.ToLower(name)
+		var normalized string
+		var ok bool
+		switch {
+		case weightAttributes[key]:
+			var kg float64
+			kg, ok = parseQuantity(value, weightUnits)
+			normalized = formatWeight(kg)
+		case lengthAttributes[key]:
+			normalized, ok = normalizeQuantity(value, lengthUnits, canonicalLengthUnit)
+		case dimensionAttributes[key]:
+			normalized, ok = normalizeDimensions(value)
+		default:
+			continue
+		}
+		if !ok {
+			return &UnitError{Attribute: name, Value: value}
+		}
+		attributes[name] = normalized
+	}
+	return nil
+}
+
+// DisplayUnits converts canonical measurement attributes in place for
+// display in the given unit system
+func DisplayUnits(attributes map[string]string, system string) {
+	if system != UnitSystemImperial {
+		return
+	}
+	for name, value := range attributes {
+		key := strings.ToLower(name)
+		switch {
+		case weightAttributes[key]:
+			if kg, ok := parseQuantity(value, weightUnits); ok {
+				attributes[name] = formatQuantity(kg/weightUnits["lb"], "lb")
+			}
+		case lengthAttributes[key]:
+			if cm, ok := parseQuantity(value, lengthUnits); ok {
+				attributes[name] = formatQuantity(cm/lengthUnits["in"], "in")
+			}
+		case dimensionAttributes[key]:
+			if parts, ok := parseDimensions(value); ok {
+				attributes[name] = formatDimensions(parts, lengthUnits["in"], "in")
+			}
+		}
+	}
+}
+
+// UnitSystemFor picks the unit system for the first language tag of an
+// Accept-Language header, using imperial units for regions such as the US
+func UnitSystemFor(acceptLanguage string) string {
+	first, _, _ := strings.Cut(acceptLanguage, ",")
+	tag, _, _ := strings.Cut(first, ";")
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	if _, region, ok := strings.Cut(tag, "-"); ok && imperialRegions[region] {
+		return UnitSystemImperial
+	}
+	return UnitSystemMetric
+}
+
+// normalizeQuantity converts a single measurement to the canonical unit
+func normalizeQuantity(value string, units map[string]float64, canonical string) (string, bool) {
+	amount, ok := parseQuantity(value, units)
+	if !ok {
+		return "", false
+	}
+	return formatQuantity(amount, canonical), true
+}
+
+// parseQuantity returns a measurement in the base unit of units. The unit is
+// required.
+func parseQuantity(value string, units map[string]float64) (float64, bool) {
+	m := quantityPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return 0, false
+	}
+	factor, ok := units[strings.ToLower(m[2])]
+	if !ok {
+		return 0, false
+	}
+	amount, err := strconv.ParseFloat(strings.Replace(m[1], ",", ".", 1), 64)
+	if err != nil {
+		return 0, false
+	}
+	return amount * factor, true
+}
+
+// normalizeDimensions converts "L x W x H unit" to centimeters
+func normalizeDimensions(value string) (string, bool) {
+	parts, ok := parseDimensions(value)
+	if !ok {
+		return "", false
+	}
+	return formatDimensions(parts, 1, canonicalLengthUnit), true
+}
+
+// parseDimensions returns each dimension in centimeters. A single unit after
+// the last dimension applies to all of them.
+func parseDimensions(value string) ([]float64, bool) {
+	raw := dimensionSeparator.Split(strings.TrimSpace(value), -1)
+	if len(raw) < 2 || len(raw) > 3 {
+		return nil, false
+	}
+
+	// Carry the trailing unit to dimensions given without one
+	last := quantityPattern.FindStringSubmatch(raw[len(raw)-1])
+	if last == nil || last[2] == "" {
+		return nil, false
+	}
+	parts := make([]float64, len(raw))
+	for i, part := range raw {
+		if m := quantityPattern.FindStringSubmatch(part); m != nil && m[2] == "" {
+			part += " " + last[2]
+		}
+		cm, ok := parseQuantity(part, lengthUnits)
+		if !ok {
+			return nil, false
+		}
+		parts[i] = cm
+	}
+	return parts, true
+}
+
+// formatDimensions writes dimensions given in centimeters in another unit
+func formatDimensions(parts []float64, factor float64, unit string) string {
+	formatted := make([]string, len(parts))
+	for i, part := range parts {
+		formatted[i] = formatNumber(part / factor)
+	}
+	return strings.Join(formatted, " x ") + " " + unit
+}
+
+// formatQuantity writes an amount rounded to two decimals with its unit
+func formatQuantity(amount float64, unit string) string {
+	return formatNumber(amount) + " " + unit
+}
+
+// formatNumber rounds to two decimals without trailing zeros
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(math.Round(n*100)/100, 'f', -1, 64)
+}
+
+// formatWeight writes kilograms to the gram so conversions round-trip
+func formatWeight(kg float64) string {
+	return strconv.FormatFloat(math.Round(kg*1000)/1000, 'f', -1, 64) + " " + canonicalWeightUnit
+}
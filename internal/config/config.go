@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds all configuration for the application
@@ -11,35 +13,649 @@ type Config struct {
 	Port        string
 	Environment string
 
+	// Pseudonymize personal data in responses, for environments running on
+	// copies of production data, keyed so pseudonyms are stable
+	AnonymizeResponses bool
+	AnonymizationKey   string
+
+	// On SIGTERM, report not ready for the drain period before refusing new
+	// connections, then wait up to the shutdown timeout for in-flight requests
+	ShutdownDrainSec   int
+	ShutdownTimeoutSec int
+
 	// JWT settings
 	JWTSecret     string
 	JWTExpiration int // in hours
+	JWKSURL       string
+	// Accept tokens not known locally to be revoked while Redis is down
+	RevocationFailOpen bool
+
+	// OAuth2 token introspection (RFC 7662) for high-value routes
+	TokenIntrospectionURL          string
+	TokenIntrospectionClientID     string
+	TokenIntrospectionClientSecret string
+	TokenIntrospectionCacheSec     int // how long an active result is trusted
+
+	// OIDC login providers, keyed by provider name
+	OIDCProviders map[string]OIDCProvider
 
 	// gRPC service addresses
 	UserServiceAddr      string
 	ListingServiceAddr   string
 	InventoryServiceAddr string
+	PaymentServiceAddr   string
+
+	// Multi-region routing: the gateway's own region, whose backends are the
+	// service addresses above; each service's deployments in other regions
+	// as region=addr pairs; how the region for calls is chosen (static or
+	// latency); and the order regions are tried while the local one is down
+	Region                      string
+	UserServiceRegionAddrs      map[string]string
+	ListingServiceRegionAddrs   map[string]string
+	InventoryServiceRegionAddrs map[string]string
+	PaymentServiceRegionAddrs   map[string]string
+	RegionSelection             string
+	RegionFailoverOrder         []string
+
+	// gRPC connection pool size per backend service
+	GRPCPoolSize int
+
+	// gRPC calls slower than this are logged with their redacted request
+	GRPCSlowCallThresholdMs int
+
+	// Per-backend bulkheads: concurrent unary calls allowed to each backend
+	// (0 disables), overridable per backend (user, listing, inventory,
+	// payment), and how many calls may wait, and for how long, once the
+	// limit is reached
+	GRPCMaxConcurrentCalls          int
+	GRPCMaxConcurrentCallsByBackend map[string]int
+	GRPCMaxQueuedCalls              int
+	GRPCQueueTimeoutMs              int
+
+	// Circuit breakers: stop calling a backend after this many consecutive
+	// failures (0 disables), and try again after the cooldown
+	GRPCBreakerFailures    int
+	GRPCBreakerCooldownSec int
+
+	// Idempotent reads (GetProduct, GetInventory) send a second request if
+	// the first has not answered within this delay (0 disables)
+	GRPCHedgeDelayMs int
+
+	// Backends (user, listing, inventory, payment) whose calls for a user
+	// stick to one replica by consistent hashing of the user ID
+	GRPCAffinityBackends []string
+
+	// When a backend's target changes at runtime, traffic moves to the
+	// warmed new target over the shift period, and the old target's
+	// connections are closed after the drain period that follows
+	GRPCTargetShiftSec int
+	GRPCTargetDrainSec int
+
+	// Role-based access control
+	Permissions PermissionMatrix
+
+	// How long product deletions and bulk price changes can be undone
+	UndoWindowSec int
+
+	// Shopping carts: signed-in users' carts outlive guest sessions' carts,
+	// and both are capped in distinct products and quantity per product
+	CartTTLDays       int
+	CartGuestTTLHours int
+	CartMaxItems      int
+	CartMaxQuantity   int
+	CartMergeStrategy string // sum or max, for products in both carts at login
+
+	// Guest checkout: how long email verification codes and the guest
+	// tokens issued for them stay valid, and wrong guesses allowed per code.
+	// Codes sent and wrong guesses are also limited per email and per cart
+	// session, which are locked out for GuestLockoutMin once they reach
+	// either limit.
+	GuestCheckoutEnabled bool
+	GuestCodeTTLMin      int
+	GuestCodeMaxAttempts int
+	GuestTokenTTLMin     int
+	GuestMaxCodes        int
+	GuestMaxFailures     int
+	GuestLockoutMin      int
+
+	// Currency order payments are charged in unless the seller lists in
+	// another, and that exchange rates are quoted from
+	PaymentCurrency string
+
+	// Exchange rates for showing prices in other currencies and converting
+	// platform charges into sellers' currencies: a static table from the
+	// payment currency, or an external provider's JSON API (optional)
+	ExchangeRateProvider    string             // static, http, or empty to convert nothing
+	ExchangeRates           map[string]float64 // static rates, e.g. EUR=0.92
+	ExchangeRateURL         string
+	ExchangeRateAPIKey      string
+	ExchangeRateTimeoutSec  int
+	ExchangeRateCacheTTLSec int
+
+	// Store credit: whether checkout applies it unless the request says
+	// otherwise, and the most one goodwill issuance can grant (0 is no cap)
+	StoreCreditAutoApply   bool
+	StoreCreditMaxGoodwill float64
+
+	// Smallest and largest gift card amounts that can be purchased
+	GiftCardMinAmount float64
+	GiftCardMaxAmount float64
+	// How many times an hour one gift card code, and one client IP, may be
+	// looked up by balance checks and checkouts (0 for no limit)
+	GiftCardLookupsPerHour int
+
+	// Holidays and blackout dates by region, from a file and optionally an
+	// external source refreshed periodically, and the region each
+	// warehouse observes
+	HolidayCalendar           map[string]*HolidayRegion
+	HolidaySourceURL          string
+	HolidaySourceRefreshHours int
+	WarehouseRegions          map[string]string
+
+	// Dispatch cutoffs by warehouse and seller, for same-day dispatch
+	// promises on products and carts (optional)
+	Dispatch *DispatchConfig
+
+	// Sales tax calculated at checkout: flat rates by region, or an external
+	// provider's JSON API (optional)
+	TaxProvider    string             // flat, http, or empty to charge no tax
+	TaxRate        float64            // flat rate where no regional rate applies
+	TaxRates       map[string]float64 // flat rates by country or country-state, e.g. US-CA
+	TaxProviderURL string
+	TaxAPIKey      string
+	TaxTimeoutSec  int
+
+	// Shipping methods quoted for carts and charged on orders: a table of
+	// rates, or an external carrier-rate provider's JSON API (optional)
+	ShippingRateProvider   string            // table, http, or empty to quote no shipping
+	ShippingRates          []*ShippingMethod // table rates
+	ShippingRateURL        string
+	ShippingRateAPIKey     string
+	ShippingRateTimeoutSec int
+
+	// How long rendered invoices are kept and served again, and how many
+	// each replica keeps in memory
+	InvoiceCacheTTLHours int
+	InvoiceLRUSize       int
+
+	// How long responses to requests with an Idempotency-Key are kept for
+	// replaying to retries
+	IdempotencyKeyTTLHours int
+
+	// How often scheduled products are checked for publishing
+	PublishSchedulerIntervalSec int
+
+	// Data retention: hours each category of gateway-owned data is kept
+	// before it is purged, and how often to purge (0 disables)
+	RetentionHours            map[string]int
+	RetentionPurgeIntervalSec int
+
+	// How long an order can stay unpaid before it is cancelled and its
+	// reserved stock released (0 disables), and how often to check
+	UnpaidOrderTimeoutMin       int
+	UnpaidOrderSweepIntervalSec int
+
+	// How long shared draft preview links stay valid, and the public base
+	// URL they are built on (relative links when empty)
+	PreviewTokenTTLSec int
+	PublicBaseURL      string
+
+	// Fit reports a sized product needs before its page says how it fits
+	FitHintMinResponses int
+
+	// OAuth2 client credentials. Client tokens are signed with their own
+	// secret, so a token signed with JWTSecret can't pass as one.
+	OAuthClients     map[string]*OAuthClient
+	OAuthTokenTTLSec int
+	OAuthTokenSecret string
+
+	// In-store kiosks and tills: how long their tokens last and the secret
+	// they are signed with, how long after it was taken an order queued
+	// offline is still accepted, and how often orders that failed to place
+	// are retried
+	POSDevices          map[string]*POSDevice
+	POSTokenTTLSec      int
+	POSTokenSecret      string
+	POSMaxOfflineHours  int
+	POSRetryIntervalSec int
+
+	// Duplicate product detection
+	DuplicatePolicy    string  // off, warn, or block
+	DuplicateThreshold float64 // similarity score from 0 to 1
+
+	// Content moderation
+	ModerationWordlistFile        string
+	ModerationProviderURL         string
+	ModerationProviderAPIKey      string
+	ModerationImageProviderURL    string
+	ModerationRejectThreshold     float64
+	ModerationQuarantineThreshold float64
+
+	// Product categories accepted by the catalog; empty leaves them unchecked
+	ProductCategories []string
+
+	// Catalog localization
+	DefaultLocale             string
+	SupportedLocales          []string
+	TranslationProviderURL    string
+	TranslationProviderAPIKey string
+	AbuseTakedownThreshold    int // open reports that quarantine content; 0 disables
+
+	// Media uploads
+	MaxUploadSize int64 // in bytes
+
+	// Malware scanning of uploads
+	VirusScanProvider    string // clamav, icap, or empty to disable
+	VirusScanAddr        string // host:port of clamd or the ICAP server
+	VirusScanICAPService string // ICAP service path, e.g. avscan
+	VirusScanTimeoutSec  int
+
+	// Logging
+	LogLevel  string // debug, info, warn, or error
+	LogFormat string // json or text
+
+	// Access logging
+	AccessLogEnabled      bool
+	AccessLogFormat       string   // json or text; defaults to LogFormat
+	AccessLogSampleRate   float64  // fraction of successful requests logged
+	AccessLogExcludePaths []string // paths never logged, e.g. /health
+
+	// Debug logging of redacted request and response bodies
+	BodyLogSampleRate float64  // fraction of requests logged
+	BodyLogRoutes     []string // routes always logged, e.g. "POST /api/v1/orders"
+	BodyLogMaxBytes   int      // larger bodies are omitted
+
+	// OpenTelemetry tracing. Spans are exported over OTLP/gRPC when an
+	// endpoint (host:port) is set.
+	TracingOTLPEndpoint string
+	TracingOTLPInsecure bool
+	TracingServiceName  string
+	TracingSampleRatio  float64
+
+	// Bearer token required to scrape /metrics (optional)
+	MetricsToken string
+
+	// Redis response cache TTLs for product reads (0 disables)
+	ProductListCacheTTLSec int
+	ProductCacheTTLSec     int
+
+	// Product lookups cached in process and in Redis (0 disables a layer)
+	ProductLRUSize     int
+	ProductLRUTTLSec   int
+	ProductRedisTTLSec int
+
+	// Tell other replicas over Redis pub/sub to purge changed products
+	CacheInvalidationPubSub bool
+
+	// Shed low-priority requests when in-flight requests or average latency
+	// cross these thresholds (0 disables a check)
+	LoadShedMaxInFlight    int
+	LoadShedLatencyMs      int
+	LoadShedCriticalRoutes []string // never shed, e.g. "POST /api/v1/orders"
+
+	// Reject unknown request body fields for these route groups, and for
+	// partner integrations on every group
+	StrictJSONGroups   []string
+	StrictJSONPartners bool
+
+	// Cap page sizes on paginated routes, overridable per route (e.g.
+	// "GET /orders"), and the item lists of aggregate reports in bytes
+	MaxPageSize               int
+	MaxPageSizes              map[string]int
+	MaxAggregateResponseBytes int
+
+	// Response compression for the listed content types once bodies reach a
+	// minimum size, which can be overridden per type
+	CompressionEnabled        bool
+	CompressionTypes          []string
+	CompressionMinBytes       int
+	CompressionMinBytesByType map[string]int
+
+	// In-memory catalog index used when the search service is down
+	SearchFallbackEnabled    bool
+	SearchFallbackRefreshSec int
+
+	// Service level objectives tracked over a rolling window
+	SLOs             []*SLO
+	SLOWindowMinutes int
 
-	// CORS settings
-	AllowedOrigins []string
+	// Panic reporting: Sentry takes precedence over the generic webhook
+	SentryDSN       string
+	ErrorWebhookURL string
+
+	// Admin listener for pprof and runtime debugging (disabled when either is empty)
+	AdminAddr  string
+	AdminToken string
+
+	// Network access control. Lists accept IPs or CIDR ranges.
+	TrustedProxies   []string // proxies whose X-Forwarded-For is honored
+	AdminIPAllowlist []string // enables the admin allowlist; empty allows admin routes from anywhere
+	IPDenylist       []string
+	BlockedCountries []string // ISO 3166-1 alpha-2 codes
+	GeoCountryHeader string   // header set by the CDN with the client's country
+
+	// HMAC request signing for partners
+	PartnerSecrets      map[string]string // partner ID to signing secret
+	SignatureMaxSkewSec int               // allowed clock skew for signed requests
+
+	// Payment provider webhooks: the signing secret (empty disables the
+	// endpoint), the allowed age of a signature, and how long event IDs are
+	// remembered so redelivered events are applied once
+	PaymentWebhookSecret        string
+	PaymentWebhookToleranceSec  int
+	PaymentWebhookEventTTLHours int
+
+	// Carrier tracking webhooks, signed like payment webhooks with their own
+	// secret (empty disables the endpoint)
+	CarrierWebhookSecret string
+
+	// Request inspection for attack signatures
+	WAFMode           string   // off, log, or block
+	WAFMaxHeaderBytes int      // largest header value allowed; 0 disables
+	WAFExclusions     []string // rule IDs, path prefixes, or rule@/prefix
+
+	// Account risk scoring
+	RiskVelocityLimit   int // risky actions per hour before velocity adds risk
+	RiskStepUpThreshold int // score requiring step-up verification; 0 disables
+	RiskReviewThreshold int // score holding the account for manual review; 0 disables
+	RiskStepUpMaxAge    int // in minutes, how recent an MFA login satisfies step-up
+
+	// Customer segments for targeting: resolved by the rules engine when its
+	// URL is set, otherwise by the user service, and cached per customer
+	SegmentRulesEngineURL    string
+	SegmentRulesEngineAPIKey string
+	SegmentCacheTTLSec       int // 0 disables caching
+
+	// CORS settings. Origins may be exact, "*", or a subdomain wildcard such
+	// as https://*.example.com.
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	CORSMaxAge       int // preflight cache lifetime in seconds
 
 	// Rate limiting
-	RateLimit int // requests per second
+	RateLimit  int            // default requests per second
+	RateLimits map[string]int // requests per second by route group
+
+	// Partner SLA tiers assigned to API keys, overriding request timeouts,
+	// response caching, and rate limits
+	SLATiers       map[string]*SLATier
+	DefaultSLATier string // for API keys without a tier; empty for none
+
+	// Redis connection, e.g. redis://localhost:6379/0 (optional)
+	RedisURL string
+
+	// ID verification provider for age-restricted items (optional)
+	IDVerificationURL    string
+	IDVerificationAPIKey string
+}
+
+// OIDCProvider holds settings for an OpenID Connect login provider
+type OIDCProvider struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
 }
 
 // Load reads configuration from environment variables
 func Load() *Config {
 	return &Config{
-		Port:                 getEnv("PORT", "8080"),
-		Environment:          getEnv("ENVIRONMENT", "development"),
-		JWTSecret:            getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
-		JWTExpiration:        getEnvAsInt("JWT_EXPIRATION_HOURS", 24),
-		UserServiceAddr:      getEnv("USER_SERVICE_ADDR", "localhost:50051"),
-		ListingServiceAddr:   getEnv("LISTING_SERVICE_ADDR", "localhost:50052"),
-		InventoryServiceAddr: getEnv("INVENTORY_SERVICE_ADDR", "localhost:50053"),
-		AllowedOrigins:       getEnvAsSlice("ALLOWED_ORIGINS", []string{"http://localhost:3000"}),
-		RateLimit:            getEnvAsInt("RATE_LIMIT", 100),
+		Port:                            getEnv("PORT", "8080"),
+		Environment:                     getEnv("ENVIRONMENT", "development"),
+		AnonymizeResponses:              getEnvAsBool("ANONYMIZE_RESPONSES", false),
+		AnonymizationKey:                getEnv("ANONYMIZATION_KEY", ""),
+		ShutdownDrainSec:                getEnvAsInt("SHUTDOWN_DRAIN_SECONDS", 5),
+		ShutdownTimeoutSec:              getEnvAsInt("SHUTDOWN_TIMEOUT_SECONDS", 30),
+		JWTSecret:                       getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
+		JWTExpiration:                   getEnvAsInt("JWT_EXPIRATION_HOURS", 24),
+		JWKSURL:                         getEnv("JWKS_URL", ""),
+		RevocationFailOpen:              getEnvAsBool("REVOCATION_FAIL_OPEN", true),
+		TokenIntrospectionURL:           getEnv("TOKEN_INTROSPECTION_URL", ""),
+		TokenIntrospectionClientID:      getEnv("TOKEN_INTROSPECTION_CLIENT_ID", ""),
+		TokenIntrospectionClientSecret:  getEnv("TOKEN_INTROSPECTION_CLIENT_SECRET", ""),
+		TokenIntrospectionCacheSec:      getEnvAsInt("TOKEN_INTROSPECTION_CACHE_SECONDS", 30),
+		OIDCProviders:                   loadOIDCProviders(),
+		UserServiceAddr:                 getEnv("USER_SERVICE_ADDR", "localhost:50051"),
+		ListingServiceAddr:              getEnv("LISTING_SERVICE_ADDR", "localhost:50052"),
+		InventoryServiceAddr:            getEnv("INVENTORY_SERVICE_ADDR", "localhost:50053"),
+		PaymentServiceAddr:              getEnv("PAYMENT_SERVICE_ADDR", "localhost:50054"),
+		Region:                          getEnv("GATEWAY_REGION", ""),
+		UserServiceRegionAddrs:          getEnvAsStringMap("USER_SERVICE_REGIONS"),
+		ListingServiceRegionAddrs:       getEnvAsStringMap("LISTING_SERVICE_REGIONS"),
+		InventoryServiceRegionAddrs:     getEnvAsStringMap("INVENTORY_SERVICE_REGIONS"),
+		PaymentServiceRegionAddrs:       getEnvAsStringMap("PAYMENT_SERVICE_REGIONS"),
+		RegionSelection:                 getEnv("REGION_SELECTION", "static"),
+		RegionFailoverOrder:             getEnvAsSlice("REGION_FAILOVER_ORDER", []string{}),
+		GRPCPoolSize:                    getEnvAsInt("GRPC_POOL_SIZE", 1),
+		GRPCSlowCallThresholdMs:         getEnvAsInt("GRPC_SLOW_CALL_THRESHOLD_MS", 500),
+		GRPCMaxConcurrentCalls:          getEnvAsInt("GRPC_MAX_CONCURRENT_CALLS", 200),
+		GRPCMaxConcurrentCallsByBackend: getEnvAsIntMap("GRPC_MAX_CONCURRENT_CALLS_BY_BACKEND"),
+		GRPCMaxQueuedCalls:              getEnvAsInt("GRPC_MAX_QUEUED_CALLS", 100),
+		GRPCQueueTimeoutMs:              getEnvAsInt("GRPC_QUEUE_TIMEOUT_MS", 200),
+		GRPCBreakerFailures:             getEnvAsInt("GRPC_BREAKER_FAILURES", 5),
+		GRPCBreakerCooldownSec:          getEnvAsInt("GRPC_BREAKER_COOLDOWN_SECONDS", 30),
+		GRPCAffinityBackends:            getEnvAsSlice("GRPC_AFFINITY_BACKENDS", []string{}),
+		GRPCTargetShiftSec:              getEnvAsInt("GRPC_TARGET_SHIFT_SECONDS", 30),
+		GRPCTargetDrainSec:              getEnvAsInt("GRPC_TARGET_DRAIN_SECONDS", 30),
+		GRPCHedgeDelayMs:                getEnvAsInt("GRPC_HEDGE_DELAY_MS", 0),
+		Permissions:                     loadPermissions(getEnv("RBAC_POLICY_FILE", "")),
+		UndoWindowSec:                   getEnvAsInt("UNDO_WINDOW_SECONDS", 30),
+		CartTTLDays:                     getEnvAsInt("CART_TTL_DAYS", 30),
+		CartGuestTTLHours:               getEnvAsInt("CART_GUEST_TTL_HOURS", 72),
+		CartMaxItems:                    getEnvAsInt("CART_MAX_ITEMS", 100),
+		CartMaxQuantity:                 getEnvAsInt("CART_MAX_QUANTITY", 99),
+		CartMergeStrategy:               getEnv("CART_MERGE_STRATEGY", "sum"),
+		GuestCheckoutEnabled:            getEnvAsBool("GUEST_CHECKOUT_ENABLED", true),
+		GuestCodeTTLMin:                 getEnvAsInt("GUEST_CODE_TTL_MINUTES", 10),
+		GuestCodeMaxAttempts:            getEnvAsInt("GUEST_CODE_MAX_ATTEMPTS", 5),
+		GuestTokenTTLMin:                getEnvAsInt("GUEST_TOKEN_TTL_MINUTES", 60),
+		GuestMaxCodes:                   getEnvAsInt("GUEST_MAX_CODES", 5),
+		GuestMaxFailures:                getEnvAsInt("GUEST_MAX_FAILURES", 10),
+		GuestLockoutMin:                 getEnvAsInt("GUEST_LOCKOUT_MINUTES", 60),
+		PaymentCurrency:                 getEnv("PAYMENT_CURRENCY", "USD"),
+		ExchangeRateProvider:            getEnv("EXCHANGE_RATE_PROVIDER", ""),
+		ExchangeRates:                   getEnvAsFloatMap("EXCHANGE_RATES"),
+		ExchangeRateURL:                 getEnv("EXCHANGE_RATE_PROVIDER_URL", ""),
+		ExchangeRateAPIKey:              getEnv("EXCHANGE_RATE_API_KEY", ""),
+		ExchangeRateTimeoutSec:          getEnvAsInt("EXCHANGE_RATE_TIMEOUT_SECONDS", 5),
+		ExchangeRateCacheTTLSec:         getEnvAsInt("EXCHANGE_RATE_CACHE_TTL_SECONDS", 3600),
+		StoreCreditAutoApply:            getEnvAsBool("STORE_CREDIT_AUTO_APPLY", false),
+		StoreCreditMaxGoodwill:          getEnvAsFloat("STORE_CREDIT_MAX_GOODWILL", 500),
+		GiftCardMinAmount:               getEnvAsFloat("GIFT_CARD_MIN_AMOUNT", 5),
+		GiftCardMaxAmount:               getEnvAsFloat("GIFT_CARD_MAX_AMOUNT", 500),
+		GiftCardLookupsPerHour:          getEnvAsInt("GIFT_CARD_LOOKUPS_PER_HOUR", 10),
+		HolidayCalendar:                 loadHolidayCalendar(getEnv("HOLIDAY_CALENDAR_FILE", "")),
+		HolidaySourceURL:                getEnv("HOLIDAY_SOURCE_URL", ""),
+		HolidaySourceRefreshHours:       getEnvAsInt("HOLIDAY_SOURCE_REFRESH_HOURS", 24),
+		WarehouseRegions:                getEnvAsStringMap("WAREHOUSE_REGIONS"),
+		Dispatch:                        loadDispatchConfig(getEnv("DISPATCH_SCHEDULE_FILE", "")),
+		TaxProvider:                     getEnv("TAX_PROVIDER", ""),
+		TaxRate:                         getEnvAsFloat("TAX_RATE", 0),
+		TaxRates:                        getEnvAsFloatMap("TAX_RATES"),
+		TaxProviderURL:                  getEnv("TAX_PROVIDER_URL", ""),
+		TaxAPIKey:                       getEnv("TAX_API_KEY", ""),
+		TaxTimeoutSec:                   getEnvAsInt("TAX_TIMEOUT_SECONDS", 5),
+		ShippingRateProvider:            getEnv("SHIPPING_RATE_PROVIDER", ""),
+		ShippingRates:                   loadShippingRates(getEnv("SHIPPING_RATES_FILE", "")),
+		ShippingRateURL:                 getEnv("SHIPPING_RATE_PROVIDER_URL", ""),
+		ShippingRateAPIKey:              getEnv("SHIPPING_RATE_API_KEY", ""),
+		ShippingRateTimeoutSec:          getEnvAsInt("SHIPPING_RATE_TIMEOUT_SECONDS", 5),
+		InvoiceCacheTTLHours:            getEnvAsInt("INVOICE_CACHE_TTL_HOURS", 720),
+		InvoiceLRUSize:                  getEnvAsInt("INVOICE_LRU_SIZE", 500),
+		IdempotencyKeyTTLHours:          getEnvAsInt("IDEMPOTENCY_KEY_TTL_HOURS", 24),
+		PublishSchedulerIntervalSec:     getEnvAsInt("PUBLISH_SCHEDULER_INTERVAL_SECONDS", 60),
+		RetentionHours:                  getEnvAsIntMap("RETENTION_HOURS"),
+		RetentionPurgeIntervalSec:       getEnvAsInt("RETENTION_PURGE_INTERVAL_SECONDS", 3600),
+		UnpaidOrderTimeoutMin:           getEnvAsInt("UNPAID_ORDER_TIMEOUT_MINUTES", 30),
+		UnpaidOrderSweepIntervalSec:     getEnvAsInt("UNPAID_ORDER_SWEEP_INTERVAL_SECONDS", 60),
+		PreviewTokenTTLSec:              getEnvAsInt("PREVIEW_TOKEN_TTL_SECONDS", 86400),
+		FitHintMinResponses:             getEnvAsInt("FIT_HINT_MIN_RESPONSES", 5),
+		PublicBaseURL:                   strings.TrimSuffix(getEnv("PUBLIC_BASE_URL", ""), "/"),
+		OAuthClients:                    loadOAuthClients(getEnv("OAUTH_CLIENTS_FILE", "")),
+		OAuthTokenTTLSec:                getEnvAsInt("OAUTH_TOKEN_TTL_SECONDS", 3600),
+		OAuthTokenSecret:                getEnv("OAUTH_TOKEN_SECRET", ""),
+		POSDevices:                      loadPOSDevices(getEnv("POS_DEVICES_FILE", "")),
+		POSTokenTTLSec:                  getEnvAsInt("POS_TOKEN_TTL_SECONDS", 900),
+		POSTokenSecret:                  getEnv("POS_TOKEN_SECRET", ""),
+		POSMaxOfflineHours:              getEnvAsInt("POS_MAX_OFFLINE_HOURS", 72),
+		POSRetryIntervalSec:             getEnvAsInt("POS_RETRY_INTERVAL_SECONDS", 30),
+		DuplicatePolicy:                 getEnv("DUPLICATE_POLICY", "warn"),
+		DuplicateThreshold:              getEnvAsFloat("DUPLICATE_THRESHOLD", 0.85),
+		ModerationWordlistFile:          getEnv("MODERATION_WORDLIST_FILE", ""),
+		ModerationProviderURL:           getEnv("MODERATION_PROVIDER_URL", ""),
+		ModerationProviderAPIKey:        getEnv("MODERATION_PROVIDER_API_KEY", ""),
+		ModerationImageProviderURL:      getEnv("MODERATION_IMAGE_PROVIDER_URL", ""),
+		ProductCategories:               getEnvAsSlice("PRODUCT_CATEGORIES", nil),
+		DefaultLocale:                   getEnv("DEFAULT_LOCALE", "en"),
+		SupportedLocales:                getEnvAsSlice("SUPPORTED_LOCALES", []string{"en", "de", "fr", "es"}),
+		TranslationProviderURL:          getEnv("TRANSLATION_PROVIDER_URL", ""),
+		TranslationProviderAPIKey:       getEnv("TRANSLATION_PROVIDER_API_KEY", ""),
+		ModerationRejectThreshold:       getEnvAsFloat("MODERATION_REJECT_THRESHOLD", 0.9),
+		ModerationQuarantineThreshold:   getEnvAsFloat("MODERATION_QUARANTINE_THRESHOLD", 0.6),
+		AbuseTakedownThreshold:          getEnvAsInt("ABUSE_TAKEDOWN_THRESHOLD", 5),
+		MaxUploadSize:                   int64(getEnvAsInt("MAX_UPLOAD_SIZE_MB", 10)) << 20,
+		VirusScanProvider:               getEnv("VIRUS_SCAN_PROVIDER", ""),
+		VirusScanAddr:                   getEnv("VIRUS_SCAN_ADDR", ""),
+		VirusScanICAPService:            getEnv("VIRUS_SCAN_ICAP_SERVICE", "avscan"),
+		VirusScanTimeoutSec:             getEnvAsInt("VIRUS_SCAN_TIMEOUT_SECONDS", 30),
+		LogLevel:                        getEnv("LOG_LEVEL", "info"),
+		LogFormat:                       getEnv("LOG_FORMAT", "json"),
+		AccessLogEnabled:                getEnvAsBool("ACCESS_LOG_ENABLED", true),
+		AccessLogFormat:                 getEnv("ACCESS_LOG_FORMAT", ""),
+		AccessLogSampleRate:             getEnvAsFloat("ACCESS_LOG_SAMPLE_RATE", 1.0),
+		AccessLogExcludePaths:           getEnvAsSlice("ACCESS_LOG_EXCLUDE_PATHS", []string{"/health", "/ready", "/metrics"}),
+		BodyLogSampleRate:               getEnvAsFloat("BODY_LOG_SAMPLE_RATE", 0),
+		BodyLogRoutes:                   getEnvAsSlice("BODY_LOG_ROUTES", nil),
+		BodyLogMaxBytes:                 getEnvAsInt("BODY_LOG_MAX_BYTES", 8192),
+		TracingOTLPEndpoint:             getEnv("TRACING_OTLP_ENDPOINT", ""),
+		TracingOTLPInsecure:             getEnvAsBool("TRACING_OTLP_INSECURE", false),
+		TracingServiceName:              getEnv("TRACING_SERVICE_NAME", "be-api-gin"),
+		TracingSampleRatio:              getEnvAsFloat("TRACING_SAMPLE_RATIO", 1.0),
+		MetricsToken:                    getEnv("METRICS_TOKEN", ""),
+		ProductListCacheTTLSec:          getEnvAsInt("PRODUCT_LIST_CACHE_TTL_SECONDS", 30),
+		ProductCacheTTLSec:              getEnvAsInt("PRODUCT_CACHE_TTL_SECONDS", 60),
+		ProductLRUSize:                  getEnvAsInt("PRODUCT_LRU_SIZE", 1000),
+		ProductLRUTTLSec:                getEnvAsInt("PRODUCT_LRU_TTL_SECONDS", 10),
+		CacheInvalidationPubSub:         getEnvAsBool("CACHE_INVALIDATION_PUBSUB", true),
+		LoadShedMaxInFlight:             getEnvAsInt("LOAD_SHED_MAX_IN_FLIGHT", 1000),
+		LoadShedLatencyMs:               getEnvAsInt("LOAD_SHED_LATENCY_MS", 2000),
+		LoadShedCriticalRoutes:          getEnvAsSlice("LOAD_SHED_CRITICAL_ROUTES", []string{"POST /api/v1/orders", "POST /api/orders", "POST /api/v1/checkout", "POST /api/checkout", "POST /api/v1/guest/checkout", "POST /api/guest/checkout"}),
+		StrictJSONGroups:                getEnvAsSlice("STRICT_JSON_GROUPS", nil),
+		StrictJSONPartners:              getEnvAsBool("STRICT_JSON_PARTNERS", true),
+		MaxPageSize:                     getEnvAsInt("MAX_PAGE_SIZE", 100),
+		MaxPageSizes:                    getEnvAsIntMap("MAX_PAGE_SIZES"),
+		MaxAggregateResponseBytes:       getEnvAsInt("MAX_AGGREGATE_RESPONSE_BYTES", 1048576),
+		CompressionEnabled:              getEnvAsBool("COMPRESSION_ENABLED", true),
+		CompressionTypes:                getEnvAsSlice("COMPRESSION_TYPES", []string{"application/json", "application/problem+json", "application/xml", "text/*"}),
+		CompressionMinBytes:             getEnvAsInt("COMPRESSION_MIN_BYTES", 1024),
+		CompressionMinBytesByType:       getEnvAsIntMap("COMPRESSION_MIN_BYTES_BY_TYPE"),
+		SearchFallbackEnabled:           getEnvAsBool("SEARCH_FALLBACK_ENABLED", true),
+		SearchFallbackRefreshSec:        getEnvAsInt("SEARCH_FALLBACK_REFRESH_SECONDS", 300),
+		ProductRedisTTLSec:              getEnvAsInt("PRODUCT_REDIS_TTL_SECONDS", 60),
+		SLOs:                            loadSLOs(getEnv("SLO_FILE", "")),
+		SLOWindowMinutes:                getEnvAsInt("SLO_WINDOW_MINUTES", 60),
+		SentryDSN:                       getEnv("SENTRY_DSN", ""),
+		ErrorWebhookURL:                 getEnv("ERROR_WEBHOOK_URL", ""),
+		AdminAddr:                       getEnv("ADMIN_ADDR", ""),
+		AdminToken:                      getEnv("ADMIN_TOKEN", ""),
+		TrustedProxies:                  getEnvAsSlice("TRUSTED_PROXIES", nil),
+		AdminIPAllowlist:                getEnvAsSlice("ADMIN_IP_ALLOWLIST", nil),
+		IPDenylist:                      getEnvAsSlice("IP_DENYLIST", nil),
+		BlockedCountries:                getEnvAsSlice("BLOCKED_COUNTRIES", nil),
+		GeoCountryHeader:                getEnv("GEO_COUNTRY_HEADER", ""),
+		PartnerSecrets:                  getEnvAsStringMap("PARTNER_SECRETS"),
+		SignatureMaxSkewSec:             getEnvAsInt("SIGNATURE_MAX_SKEW_SECONDS", 300),
+		PaymentWebhookSecret:            getEnv("PAYMENT_WEBHOOK_SECRET", ""),
+		PaymentWebhookToleranceSec:      getEnvAsInt("PAYMENT_WEBHOOK_TOLERANCE_SECONDS", 300),
+		PaymentWebhookEventTTLHours:     getEnvAsInt("PAYMENT_WEBHOOK_EVENT_TTL_HOURS", 72),
+		CarrierWebhookSecret:            getEnv("CARRIER_WEBHOOK_SECRET", ""),
+		WAFMode:                         getEnv("WAF_MODE", "log"),
+		WAFMaxHeaderBytes:               getEnvAsInt("WAF_MAX_HEADER_BYTES", 8192),
+		WAFExclusions:                   getEnvAsSlice("WAF_EXCLUSIONS", nil),
+		RiskVelocityLimit:               getEnvAsInt("RISK_VELOCITY_LIMIT", 20),
+		RiskStepUpThreshold:             getEnvAsInt("RISK_STEP_UP_THRESHOLD", 50),
+		RiskReviewThreshold:             getEnvAsInt("RISK_REVIEW_THRESHOLD", 80),
+		RiskStepUpMaxAge:                getEnvAsInt("RISK_STEP_UP_MAX_AGE_MINUTES", 15),
+		SegmentRulesEngineURL:           getEnv("SEGMENT_RULES_ENGINE_URL", ""),
+		SegmentRulesEngineAPIKey:        getEnv("SEGMENT_RULES_ENGINE_API_KEY", ""),
+		SegmentCacheTTLSec:              getEnvAsInt("SEGMENT_CACHE_TTL_SECONDS", 300),
+		AllowedOrigins:                  getEnvAsSlice("ALLOWED_ORIGINS", []string{"http://localhost:3000"}),
+		AllowedMethods:                  getEnvAsSlice("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}),
+		AllowedHeaders:                  getEnvAsSlice("CORS_ALLOWED_HEADERS", []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Request-ID", "X-API-Key", "X-Device-ID", "If-None-Match", "X-Cart-Session", "X-Guest-Token", "Idempotency-Key"}),
+		ExposedHeaders:                  getEnvAsSlice("CORS_EXPOSED_HEADERS", []string{"Content-Length", "Content-Type", "X-Request-ID", "X-RateLimit-Limit", "Retry-After", "ETag", "Deprecation", "Sunset", "Link", "Warning", "X-Cart-Session", "Idempotent-Replayed"}),
+		AllowCredentials:                getEnvAsBool("CORS_ALLOW_CREDENTIALS", true),
+		CORSMaxAge:                      getEnvAsInt("CORS_MAX_AGE", 86400),
+		RateLimit:                       getEnvAsInt("RATE_LIMIT", 100),
+		RateLimits:                      getEnvAsIntMap("RATE_LIMITS"),
+		SLATiers:                        loadSLATiers(getEnv("SLA_TIERS_FILE", "")),
+		DefaultSLATier:                  getEnv("DEFAULT_SLA_TIER", ""),
+		RedisURL:                        getEnv("REDIS_URL", ""),
+		IDVerificationURL:               getEnv("ID_VERIFICATION_URL", ""),
+		IDVerificationAPIKey:            getEnv("ID_VERIFICATION_API_KEY", ""),
+	}
+}
+
+// loadOIDCProviders reads the providers named in OIDC_PROVIDERS, each
+// configured by OIDC_<NAME>_* variables
+func loadOIDCProviders() map[string]OIDCProvider {
+	providers := make(map[string]OIDCProvider)
+	for _, name := range getEnvAsSlice("OIDC_PROVIDERS", nil) {
+		name = strings.ToLower(strings.TrimSpace(name))
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		providers[name] = OIDCProvider{
+			Name:         name,
+			IssuerURL:    getEnv(prefix+"ISSUER_URL", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", ""),
+			Scopes:       getEnvAsSlice(prefix+"SCOPES", []string{"openid", "email", "profile"}),
+		}
+	}
+	return providers
+}
+
+// RateLimitFor returns the requests-per-second limit for a route group
+func (c *Config) RateLimitFor(group string) int {
+	if limit, ok := c.RateLimits[group]; ok {
+		return limit
+	}
+	return c.RateLimit
+}
+
+// Categories of gateway-owned data with a retention period
+const (
+	RetentionIdempotencyRecords = "idempotency_records"
+	RetentionWebhookEvents      = "webhook_events"
+	RetentionGuestCarts         = "guest_carts"
+	RetentionJobs               = "jobs"
+	RetentionPOSOrders          = "pos_orders"
+)
+
+// RetentionFor returns how long a category of gateway-owned data is kept.
+// Categories default to the lifetime their data is already given; 0 means
+// the category is never purged.
+func (c *Config) RetentionFor(category string) time.Duration {
+	hours, ok := c.RetentionHours[category]
+	if !ok {
+		switch category {
+		case RetentionIdempotencyRecords:
+			hours = c.IdempotencyKeyTTLHours
+		case RetentionWebhookEvents:
+			hours = c.PaymentWebhookEventTTLHours
+		case RetentionGuestCarts:
+			hours = c.CartGuestTTLHours
+		case RetentionJobs:
+			hours = 24
+		case RetentionPOSOrders:
+			hours = 2 * c.POSMaxOfflineHours
+		}
 	}
+	return time.Duration(hours) * time.Hour
 }
 
 // getEnv gets an environment variable or returns a default value
@@ -60,6 +676,26 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvAsFloat gets an environment variable as a float or returns a default value
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value, exists := os.LookupEnv(key); exists {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsBool gets an environment variable as a boolean or returns a default value
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
 // getEnvAsSlice gets an environment variable as a slice or returns a default value
 func getEnvAsSlice(key string, defaultValue []string) []string {
 	if value, exists := os.LookupEnv(key); exists && value != "" {
@@ -80,3 +716,49 @@ func getEnvAsSlice(key string, defaultValue []string) []string {
 	}
 	return defaultValue
 }
+
+// getEnvAsIntMap gets an environment variable of comma-separated key=value
+// pairs with integer values, skipping malformed entries
+func getEnvAsIntMap(key string) map[string]int {
+	result := make(map[string]int)
+	for _, pair := range getEnvAsSlice(key, nil) {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		if intValue, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+			result[strings.TrimSpace(name)] = intValue
+		}
+	}
+	return result
+}
+
+// getEnvAsFloatMap gets an environment variable of comma-separated
+// key=value pairs with decimal values, skipping malformed entries
+func getEnvAsFloatMap(key string) map[string]float64 {
+	result := make(map[string]float64)
+	for _, pair := range getEnvAsSlice(key, nil) {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		if floatValue, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+			result[strings.TrimSpace(name)] = floatValue
+		}
+	}
+	return result
+}
+
+// getEnvAsStringMap gets an environment variable of comma-separated
+// key=value pairs, skipping malformed entries
+func getEnvAsStringMap(key string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range getEnvAsSlice(key, nil) {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			continue
+		}
+		result[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return result
+}
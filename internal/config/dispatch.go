@@ -0,0 +1,49 @@
+package config
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+)
+
+// DispatchSchedule is when a warehouse or seller hands orders to carriers:
+// orders placed before the cutoff on a dispatch day leave that day
+type DispatchSchedule struct {
+	TimeZone  string   `json:"time_zone"`           // IANA name; UTC when empty
+	Cutoff    string   `json:"cutoff"`              // local time of day as HH:MM
+	Days      []string `json:"days,omitempty"`      // mon to sun; Monday to Friday when empty
+	Holidays  []string `json:"holidays,omitempty"`  // local dates as YYYY-MM-DD with no dispatch
+	Region    string   `json:"region,omitempty"`    // holiday calendar observed; a warehouse's region when empty
+	Warehouse string   `json:"warehouse,omitempty"` // sellers only: dispatch on this warehouse's schedule
+}
+
+// DispatchConfig holds the dispatch schedules of warehouses and sellers.
+// Sellers without a schedule of their own use Default, and Holidays close
+// every schedule.
+type DispatchConfig struct {
+	Default    *DispatchSchedule            `json:"default"`
+	Warehouses map[string]*DispatchSchedule `json:"warehouses,omitempty"`
+	Sellers    map[string]*DispatchSchedule `json:"sellers,omitempty"`
+	Holidays   []string                     `json:"holidays,omitempty"`
+}
+
+// loadDispatchConfig reads dispatch schedules from a JSON file. It returns
+// nil, leaving dispatch promises off, if the file is missing or invalid.
+func loadDispatchConfig(path string) *DispatchConfig {
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		slog.Warn("Failed to read dispatch schedule file", "path", path, "error", err)
+		return nil
+	}
+
+	var dispatch DispatchConfig
+	if err := json.Unmarshal(data, &dispatch); err != nil {
+		slog.Warn("Failed to parse dispatch schedule file", "path", path, "error", err)
+		return nil
+	}
+	return &dispatch
+}
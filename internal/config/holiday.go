@@ -0,0 +1,43 @@
+package config
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+)
+
+// HolidayRegion is the holiday calendar of a country, e.g. US, or of a
+// subdivision, e.g. US-CA, whose holidays add to its country's
+type HolidayRegion struct {
+	TimeZone string        `json:"time_zone,omitempty"` // IANA name that dates are local to; the country's or UTC when empty
+	Holidays []HolidayDate `json:"holidays"`
+}
+
+// HolidayDate is a named date with no business, e.g. a public holiday or
+// a blackout date
+type HolidayDate struct {
+	Date string `json:"date"` // YYYY-MM-DD
+	Name string `json:"name"`
+}
+
+// loadHolidayCalendar reads holiday calendars from a JSON file of the form
+// {"region": {...}}. No region has holidays if the file is missing or
+// invalid.
+func loadHolidayCalendar(path string) map[string]*HolidayRegion {
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		slog.Warn("Failed to read holiday calendar file", "path", path, "error", err)
+		return nil
+	}
+
+	var regions map[string]*HolidayRegion
+	if err := json.Unmarshal(data, &regions); err != nil {
+		slog.Warn("Failed to parse holiday calendar file", "path", path, "error", err)
+		return nil
+	}
+	return regions
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"os"
+)
+
+// OAuth2 scopes granted to machine clients
+const (
+	ScopeCatalogRead    = "catalog:read"
+	ScopeInventoryWrite = "inventory:write"
+)
+
+// OAuthClient is a machine client allowed to obtain tokens with the client
+// credentials grant. Only the SHA-256 hash of its secret is stored.
+type OAuthClient struct {
+	ClientID   string   `json:"client_id"`
+	SecretHash string   `json:"secret_sha256"`
+	Scopes     []string `json:"scopes"`
+	Roles      []string `json:"roles,omitempty"`
+	OwnerID    string   `json:"owner_id,omitempty"`
+}
+
+// VerifySecret reports whether secret matches the client's stored hash
+func (c *OAuthClient) VerifySecret(secret string) bool {
+	sum := sha256.Sum256([]byte(secret))
+	return subtle.ConstantTimeCompare([]byte(hex.EncodeToString(sum[:])), []byte(c.SecretHash)) == 1
+}
+
+// loadOAuthClients reads machine clients from a JSON file containing a list
+// of clients. No clients are registered if the file is missing or invalid.
+func loadOAuthClients(path string) map[string]*OAuthClient {
+	clients := make(map[string]*OAuthClient)
+	if path == "" {
+		return clients
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		slog.Warn("Failed to read OAuth clients file", "path", path, "error", err)
+		return clients
+	}
+
+	var list []*OAuthClient
+	if err := json.Unmarshal(data, &list); err != nil {
+		slog.Warn("Failed to parse OAuth clients file", "path", path, "error", err)
+		return clients
+	}
+	for _, client := range list {
+		if client.ClientID != "" && client.SecretHash != "" {
+			clients[client.ClientID] = client
+		}
+	}
+	return clients
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"os"
+)
+
+// POSDevice is an in-store kiosk or till allowed to use the POS API. Only
+// the SHA-256 hash of its secret is stored.
+type POSDevice struct {
+	DeviceID   string `json:"device_id"`
+	SecretHash string `json:"secret_sha256"`
+	StoreID    string `json:"store_id"`
+	// Disabled devices can't get tokens, and their outstanding tokens stop
+	// working
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// VerifySecret reports whether secret matches the device's stored hash
+func (d *POSDevice) VerifySecret(secret string) bool {
+	sum := sha256.Sum256([]byte(secret))
+	return subtle.ConstantTimeCompare([]byte(hex.EncodeToString(sum[:])), []byte(d.SecretHash)) == 1
+}
+
+// loadPOSDevices reads in-store devices from a JSON file containing a list
+// of devices. No devices are registered if the file is missing or invalid.
+func loadPOSDevices(path string) map[string]*POSDevice {
+	devices := make(map[string]*POSDevice)
+	if path == "" {
+		return devices
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		slog.Warn("Failed to read POS devices file", "path", path, "error", err)
+		return devices
+	}
+
+	var list []*POSDevice
+	if err := json.Unmarshal(data, &list); err != nil {
+		slog.Warn("Failed to parse POS devices file", "path", path, "error", err)
+		return devices
+	}
+	for _, device := range list {
+		if device.DeviceID != "" && device.SecretHash != "" && device.StoreID != "" {
+			devices[device.DeviceID] = device
+		}
+	}
+	return devices
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+)
+
+// Permissions used by route declarations
+const (
+	PermProductsCreate    = "products:create"
+	PermProductsUpdate    = "products:update"
+	PermProductsDelete    = "products:delete"
+	PermInventoryUpdate   = "inventory:update"
+	PermInventoryTransfer = "inventory:transfer"
+	PermInventoryAdjust   = "inventory:adjust"
+	PermSellerRead        = "seller:read"
+	PermProductsModerate  = "products:moderate"
+	PermContentModerate   = "content:moderate"
+	PermMediaUpload       = "media:upload"
+	PermReviewsRespond    = "reviews:respond"
+	PermRiskManage        = "risk:manage"
+	PermNetworkManage     = "network:manage"
+	PermTokensRevoke      = "tokens:revoke"
+	PermAuditRead         = "audit:read"
+	PermLoggingManage     = "logging:manage"
+	PermSearchManage      = "search:manage"
+	PermErrorsRead        = "errors:read"
+	PermAPIKeysManage     = "api_keys:manage"
+	PermBackendsManage    = "backends:manage"
+	PermInFlightRead      = "inflight:read"
+	PermReturnsManage     = "returns:manage"
+	PermReturnsManageAll  = "returns:manage_all"
+	PermCreditIssue       = "credit:issue"
+	PermOrdersFulfill     = "orders:fulfill"
+	PermOrdersRead        = "orders:read"
+	PermCatalogManage     = "catalog:manage"
+	PermSellerOrdersShip  = "seller_orders:ship"
+	PermProductsManageAll = "products:manage_all"
+)
+
+// PermissionMatrix maps each role to the permissions it grants. A permission
+// of "*" grants everything, and "<resource>:*" grants every action on a resource.
+type PermissionMatrix map[string][]string
+
+// DefaultPermissions is the permission matrix used when no policy file is configured
+var DefaultPermissions = PermissionMatrix{
+	"admin": {"*"},
+	"seller": {
+		PermProductsCreate,
+		PermProductsUpdate,
+		PermProductsDelete,
+		PermInventoryUpdate,
+		PermSellerRead,
+		PermMediaUpload,
+		PermReviewsRespond,
+		PermReturnsManage,
+		PermSellerOrdersShip,
+	},
+	"warehouse": {
+		PermInventoryTransfer,
+		PermInventoryAdjust,
+		PermOrdersFulfill,
+	},
+}
+
+// loadPermissions reads the permission matrix from a JSON policy file of the
+// form {"role": ["permission", ...]}, falling back to the defaults
+func loadPermissions(path string) PermissionMatrix {
+	if path == "" {
+		return DefaultPermissions
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		slog.Warn("Failed to read RBAC policy file, using defaults", "path", path, "error", err)
+		return DefaultPermissions
+	}
+
+	var matrix PermissionMatrix
+	if err := json.Unmarshal(data, &matrix); err != nil {
+		slog.Warn("Failed to parse RBAC policy file, using defaults", "path", path, "error", err)
+		return DefaultPermissions
+	}
+	return matrix
+}
+
+// Allows reports whether any of the roles grants the permission
+func (m PermissionMatrix) Allows(roles []string, permission string) bool {
+	resource := permission
+	for i := 0; i < len(permission); i++ {
+		if permission[i] == ':' {
+			resource = permission[:i]
+			break
+		}
+	}
+
+	for _, role := range roles {
+		for _, granted := range m[role] {
+			if granted == "*" || granted == permission || granted == resource+":*" {
+				return true
+			}
+		}
+	}
+	return false
+}
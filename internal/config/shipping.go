@@ -0,0 +1,43 @@
+package config
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+)
+
+// ShippingMethod is a shipping service offered at a table rate: BasePrice
+// per order plus PerItem for each unit, free for orders of FreeOver or more
+type ShippingMethod struct {
+	ID             string   `json:"id"`
+	Carrier        string   `json:"carrier"`
+	Name           string   `json:"name"`
+	Countries      []string `json:"countries,omitempty"` // destinations served; everywhere when empty
+	BasePrice      float64  `json:"base_price"`
+	PerItem        float64  `json:"per_item,omitempty"`
+	FreeOver       float64  `json:"free_over,omitempty"` // 0 for never free
+	MinTransitDays int      `json:"min_transit_days"`    // business days after dispatch
+	MaxTransitDays int      `json:"max_transit_days"`
+}
+
+// loadShippingRates reads the table of shipping methods from a JSON file of
+// the form [{...}]. No methods are offered if the file is missing or
+// invalid.
+func loadShippingRates(path string) []*ShippingMethod {
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		slog.Warn("Failed to read shipping rates file", "path", path, "error", err)
+		return nil
+	}
+
+	var methods []*ShippingMethod
+	if err := json.Unmarshal(data, &methods); err != nil {
+		slog.Warn("Failed to parse shipping rates file", "path", path, "error", err)
+		return nil
+	}
+	return methods
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+)
+
+// SLO is a service level objective for one route. Requests count against
+// availability when they fail with a 5xx status and against latency when
+// they take longer than LatencyThresholdMs.
+type SLO struct {
+	Name                  string  `json:"name"`
+	Method                string  `json:"method"`
+	Route                 string  `json:"route"`
+	AvailabilityObjective float64 `json:"availability_objective"`
+	LatencyThresholdMs    int     `json:"latency_threshold_ms"`
+	LatencyObjective      float64 `json:"latency_objective"`
+}
+
+// defaultSLOs cover the routes most critical to shoppers
+var defaultSLOs = []*SLO{
+	{
+		Name:                  "product-detail",
+		Method:                "GET",
+		Route:                 "/api/v1/products/:id",
+		AvailabilityObjective: 0.999,
+		LatencyThresholdMs:    300,
+		LatencyObjective:      0.99,
+	},
+	{
+		Name:                  "product-list",
+		Method:                "GET",
+		Route:                 "/api/v1/products",
+		AvailabilityObjective: 0.999,
+		LatencyThresholdMs:    500,
+		LatencyObjective:      0.99,
+	},
+	{
+		Name:                  "order-create",
+		Method:                "POST",
+		Route:                 "/api/v1/orders",
+		AvailabilityObjective: 0.999,
+		LatencyThresholdMs:    1000,
+		LatencyObjective:      0.95,
+	},
+	{
+		Name:                  "order-list",
+		Method:                "GET",
+		Route:                 "/api/v1/orders",
+		AvailabilityObjective: 0.995,
+		LatencyThresholdMs:    500,
+		LatencyObjective:      0.99,
+	},
+}
+
+// loadSLOs reads objectives from a JSON file containing a list of SLOs,
+// falling back to the built-in objectives if the file is missing or invalid
+func loadSLOs(path string) []*SLO {
+	if path == "" {
+		return defaultSLOs
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		slog.Warn("Failed to read SLO file, using defaults", "path", path, "error", err)
+		return defaultSLOs
+	}
+
+	var slos []*SLO
+	if err := json.Unmarshal(data, &slos); err != nil {
+		slog.Warn("Failed to parse SLO file, using defaults", "path", path, "error", err)
+		return defaultSLOs
+	}
+	return slos
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+)
+
+// SLATier sets the response guarantees for partners whose API keys are
+// assigned to it. Zero values fall back to the gateway's defaults: no
+// request timeout, no response caching, and the route group's rate limit.
+type SLATier struct {
+	TimeoutMs   int `json:"timeout_ms"`    // deadline for the whole request
+	CacheTTLSec int `json:"cache_ttl_sec"` // how long cached reads may be served
+	RateLimit   int `json:"rate_limit"`    // requests per second in every route group
+}
+
+// defaultSLATiers trade freshness and headroom for cost: gold partners get
+// uncached reads and long timeouts, free partners cached reads and tight limits
+var defaultSLATiers = map[string]*SLATier{
+	"gold": {
+		TimeoutMs:   30000,
+		CacheTTLSec: 0,
+		RateLimit:   500,
+	},
+	"standard": {
+		TimeoutMs:   10000,
+		CacheTTLSec: 30,
+		RateLimit:   100,
+	},
+	"free": {
+		TimeoutMs:   5000,
+		CacheTTLSec: 300,
+		RateLimit:   10,
+	},
+}
+
+// loadSLATiers reads tiers from a JSON file of the form {"tier": {...}},
+// falling back to the built-in tiers if the file is missing or invalid
+func loadSLATiers(path string) map[string]*SLATier {
+	if path == "" {
+		return defaultSLATiers
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		slog.Warn("Failed to read SLA tier file, using defaults", "path", path, "error", err)
+		return defaultSLATiers
+	}
+
+	var tiers map[string]*SLATier
+	if err := json.Unmarshal(data, &tiers); err != nil {
+		slog.Warn("Failed to parse SLA tier file, using defaults", "path", path, "error", err)
+		return defaultSLATiers
+	}
+	return tiers
+}
+
+// SLATierFor returns the tier of an API key, using DefaultSLATier for keys
+// without one. It returns nil if the tier is not configured.
+func (c *Config) SLATierFor(name string) *SLATier {
+	if name == "" {
+		name = c.DefaultSLATier
+	}
+	return c.SLATiers[name]
+}
@@ -0,0 +1,80 @@
+package coupon
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+	"github.com/ecommerce/be-api-gin/internal/segment"
+)
+
+// Reasons a promo code can't be redeemed
+var (
+	ErrNotStarted   = errors.New("promo code is not active yet")
+	ErrExpired      = errors.New("promo code has expired")
+	ErrUsedUp       = errors.New("promo code has reached its usage limit")
+	ErrUserLimit    = errors.New("promo code has already been used the maximum number of times on this account")
+	ErrBelowMinimum = errors.New("subtotal is below the promo code's minimum")
+	ErrNotEligible  = errors.New("promo code is not available to this account")
+)
+
+// Check returns why the customer making the request can't redeem coupon on
+// a subtotal at now, or nil if they can. Codes limited to segments are
+// never available to guests.
+func Check(ctx context.Context, coupon *models.Coupon, subtotal float64, now time.Time) error {
+	if coupon.StartsAt != nil && now.Before(coupon.StartsAt.Time) {
+		return ErrNotStarted
+	}
+	if coupon.ExpiresAt != nil && !now.Before(coupon.ExpiresAt.Time) {
+		return ErrExpired
+	}
+	if coupon.MaxRedemptions > 0 && coupon.Redemptions >= coupon.MaxRedemptions {
+		return ErrUsedUp
+	}
+	if coupon.PerUserLimit > 0 && coupon.UserRedemptions >= coupon.PerUserLimit {
+		return ErrUserLimit
+	}
+	if subtotal < coupon.MinSubtotal {
+		return ErrBelowMinimum
+	}
+	if len(coupon.Segments) > 0 && !inAnySegment(ctx, coupon.Segments) {
+		return ErrNotEligible
+	}
+	return nil
+}
+
+// inAnySegment reports whether the customer making the request is in one of
+// the segments
+func inAnySegment(ctx context.Context, segments []string) bool {
+	for _, s := range segments {
+		if segment.Has(ctx, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Discount returns what a coupon of the given type and value takes off a
+// subtotal, rounded to cents and never more than the subtotal
+func Discount(couponType string, value, subtotal float64) float64 {
+	var discount float64
+	switch couponType {
+	case models.CouponTypePercentage:
+		discount = subtotal * value / 100
+	case models.CouponTypeFixedAmount:
+		discount = value
+	}
+	discount = math.Round(discount*100) / 100
+	return math.Max(0, math.Min(discount, subtotal))
+}
+
+// Applied returns the part of coupon a cart keeps
+func Applied(coupon *models.Coupon) *models.AppliedCoupon {
+	return &models.AppliedCoupon{
+		Code:  coupon.Code,
+		Type:  coupon.Type,
+		Value: coupon.Value,
+	}
+}
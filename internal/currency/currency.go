@@ -0,0 +1,206 @@
+package currency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+)
+
+// Exchange rate providers
+const (
+	ProviderStatic = "static"
+	ProviderHTTP   = "http"
+)
+
+// ErrUnsupported is returned for currencies there is no exchange rate for
+var ErrUnsupported = errors.New("unsupported currency")
+
+// RateProvider returns exchange rates from a base currency: how much of
+// each other currency one unit of base buys
+type RateProvider interface {
+	Rates(ctx context.Context, base string) (map[string]float64, error)
+}
+
+// NewRateProvider returns the exchange rate provider configured for the
+// application, or nil if prices are not converted
+func NewRateProvider(cfg *config.Config) RateProvider {
+	switch cfg.ExchangeRateProvider {
+	case "":
+		return nil
+	case ProviderStatic:
+		return &StaticProvider{Base: cfg.PaymentCurrency, Table: cfg.ExchangeRates}
+	case ProviderHTTP:
+		return &HTTPProvider{
+			URL:    cfg.ExchangeRateURL,
+			APIKey: cfg.ExchangeRateAPIKey,
+			Client: &http.Client{Timeout: time.Duration(cfg.ExchangeRateTimeoutSec) * time.Second},
+		}
+	}
+	slog.Warn("Unknown exchange rate provider, prices will not be converted", "provider", cfg.ExchangeRateProvider)
+	return nil
+}
+
+// Normalize upper-cases an ISO 4217 currency code, returning "" if it isn't
+// three letters
+func Normalize(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 {
+		return ""
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return ""
+		}
+	}
+	return code
+}
+
+// StaticProvider serves configured rates from Base
+type StaticProvider struct {
+	Base  string
+	Table map[string]float64
+}
+
+// Rates returns the configured rates, which are only known from Base
+func (s *StaticProvider) Rates(ctx context.Context, base string) (map[string]float64, error) {
+	if base != s.Base {
+		return nil, fmt.Errorf("static exchange rates are from %s, not %s", s.Base, base)
+	}
+	rates := make(map[string]float64, len(s.Table))
+	for code, rate := range s.Table {
+		rates[strings.ToUpper(code)] = rate
+	}
+	return rates, nil
+}
+
+// HTTPProvider is a RateProvider adapter for exchange rate services exposing
+// a JSON endpoint that takes ?base=USD and returns {"rates": {"EUR": 0.92}}
+type HTTPProvider struct {
+	URL    string
+	APIKey string
+	Client *http.Client
+}
+
+// Rates asks the provider for the rates from base
+func (h *HTTPProvider) Rates(ctx context.Context, base string) (map[string]float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.URL+"?base="+url.QueryEscape(base), nil)
+	if err != nil {
+		return nil, err
+	}
+	if h.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.APIKey)
+	}
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("exchange rate provider returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.Rates, nil
+}
+
+// Converter converts amounts between currencies through rates from the
+// payment currency, cached for a TTL. If the provider can't be reached once
+// the TTL passes, the last rates fetched are used until it can. A nil
+// Converter only converts a currency to itself.
+type Converter struct {
+	provider RateProvider
+	base     string
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	rates   map[string]float64
+	fetched time.Time
+}
+
+// NewConverter creates a converter for the configured rate provider, or
+// returns nil if prices are not converted
+func NewConverter(cfg *config.Config) *Converter {
+	provider := NewRateProvider(cfg)
+	if provider == nil {
+		return nil
+	}
+	return &Converter{
+		provider: provider,
+		base:     cfg.PaymentCurrency,
+		ttl:      time.Duration(cfg.ExchangeRateCacheTTLSec) * time.Second,
+	}
+}
+
+// Convert converts amount from one currency to another, rounded to the cent
+func (c *Converter) Convert(ctx context.Context, amount float64, from, to string) (float64, error) {
+	if from == to {
+		return amount, nil
+	}
+	if c == nil {
+		return 0, fmt.Errorf("%w: exchange rates are not configured", ErrUnsupported)
+	}
+	rates, err := c.currentRates(ctx)
+	if err != nil {
+		return 0, err
+	}
+	rate := func(code string) (float64, error) {
+		if code == c.base {
+			return 1, nil
+		}
+		if r := rates[code]; r > 0 {
+			return r, nil
+		}
+		return 0, fmt.Errorf("%w: %s", ErrUnsupported, code)
+	}
+	fromRate, err := rate(from)
+	if err != nil {
+		return 0, err
+	}
+	toRate, err := rate(to)
+	if err != nil {
+		return 0, err
+	}
+	return math.Round(amount/fromRate*toRate*100) / 100, nil
+}
+
+// currentRates returns the cached rates, refreshing them once they are
+// older than the TTL
+func (c *Converter) currentRates(ctx context.Context) (map[string]float64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.rates != nil && time.Since(c.fetched) < c.ttl {
+		return c.rates, nil
+	}
+
+	rates, err := c.provider.Rates(ctx, c.base)
+	if err != nil {
+		if c.rates != nil {
+			slog.Warn("Failed to refresh exchange rates, using stale rates", "fetched_at", c.fetched, "error", err)
+			return c.rates, nil
+		}
+		return nil, err
+	}
+	normalized := make(map[string]float64, len(rates))
+	for code, rate := range rates {
+		normalized[strings.ToUpper(code)] = rate
+	}
+	c.rates, c.fetched = normalized, time.Now()
+	return c.rates, nil
+}
@@ -0,0 +1,159 @@
+package deprecation
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// usageTTL is how long usage is kept after a consumer's last deprecated call
+const usageTTL = 90 * 24 * time.Hour
+
+// Field locations
+const (
+	InQuery    = "query"
+	InBody     = "body"
+	InResponse = "response"
+)
+
+// Notice announces that a surface is deprecated
+type Notice struct {
+	Since     time.Time // when the surface was deprecated
+	Sunset    time.Time // when it will be removed, zero if not yet scheduled
+	Successor string    // what to use instead
+}
+
+// Field is a deprecated query parameter, request body field, or response
+// field of a route
+type Field struct {
+	Name string
+	In   string
+	Notice
+}
+
+// Surface names the field in reports and metrics, e.g. "GET /products response inStock"
+func (f Field) Surface(route string) string {
+	return route + " " + f.In + " " + f.Name
+}
+
+// Registry lists deprecated surfaces. Routes are keyed "METHOD /path",
+// relative to the API root.
+type Registry struct {
+	Routes map[string]Notice
+	Fields map[string][]Field
+}
+
+// Usage counts a consumer's calls to one deprecated surface
+type Usage struct {
+	Count    int64
+	LastSeen time.Time
+}
+
+// Store records which consumers still use deprecated surfaces
+type Store interface {
+	// Record counts a call to surface by consumer
+	Record(ctx context.Context, consumer, surface string, at time.Time) error
+	// Usage returns a consumer's usage by surface
+	Usage(ctx context.Context, consumer string) (map[string]Usage, error)
+}
+
+// MemoryStore is an in-process Store. Usage only covers calls handled by
+// this gateway instance.
+type MemoryStore struct {
+	mu    sync.Mutex
+	usage map[string]map[string]Usage
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		usage: make(map[string]map[string]Usage),
+	}
+}
+
+// Record counts a call to surface by consumer
+func (s *MemoryStore) Record(ctx context.Context, consumer, surface string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	surfaces, ok := s.usage[consumer]
+	if !ok {
+		surfaces = make(map[string]Usage)
+		s.usage[consumer] = surfaces
+	}
+	usage := surfaces[surface]
+	usage.Count++
+	usage.LastSeen = at
+	surfaces[surface] = usage
+	return nil
+}
+
+// Usage returns a consumer's usage by surface
+func (s *MemoryStore) Usage(ctx context.Context, consumer string) (map[string]Usage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make(map[string]Usage, len(s.usage[consumer]))
+	for surface, usage := range s.usage[consumer] {
+		result[surface] = usage
+	}
+	return result, nil
+}
+
+// RedisStore is a Store shared by all gateway replicas. Each consumer's usage
+// is a hash of call counts and last-seen times by surface.
+type RedisStore struct {
+	client *goredis.Client
+	prefix string
+}
+
+// NewRedisStore creates a store using client, namespacing keys with prefix
+func NewRedisStore(client *goredis.Client, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// lastSeenSuffix marks the hash field holding a surface's last-seen time
+const lastSeenSuffix = "|last_seen"
+
+// Record counts a call to surface by consumer
+func (s *RedisStore) Record(ctx context.Context, consumer, surface string, at time.Time) error {
+	key := s.prefix + consumer
+	pipe := s.client.TxPipeline()
+	pipe.HIncrBy(ctx, key, surface, 1)
+	pipe.HSet(ctx, key, surface+lastSeenSuffix, at.Unix())
+	pipe.Expire(ctx, key, usageTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Usage returns a consumer's usage by surface
+func (s *RedisStore) Usage(ctx context.Context, consumer string) (map[string]Usage, error) {
+	fields, err := s.client.HGetAll(ctx, s.prefix+consumer).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]Usage)
+	for field, value := range fields {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		surface, isLastSeen := strings.CutSuffix(field, lastSeenSuffix)
+		usage := result[surface]
+		if isLastSeen {
+			usage.LastSeen = time.Unix(n, 0)
+		} else {
+			usage.Count = n
+		}
+		result[surface] = usage
+	}
+	return result, nil
+}
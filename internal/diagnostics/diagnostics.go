@@ -0,0 +1,70 @@
+package diagnostics
+
+import (
+	"crypto/subtle"
+	"expvar"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	runtimepprof "runtime/pprof"
+	"strings"
+	"time"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+)
+
+func init() {
+	expvar.Publish("goroutines", expvar.Func(func() interface{} {
+		return runtime.NumGoroutine()
+	}))
+}
+
+// NewServer creates the admin listener serving pprof profiles, expvar
+// variables, and a full goroutine dump. It returns nil when no admin
+// address is configured, or when no admin token is, since profiles and
+// dumps shouldn't be served without authentication. The listener is
+// separate from the public API so it can be bound to localhost or a
+// private network.
+func NewServer(cfg *config.Config) *http.Server {
+	if cfg.AdminAddr == "" {
+		return nil
+	}
+	if cfg.AdminToken == "" {
+		slog.Warn("ADMIN_ADDR is set without ADMIN_TOKEN, admin listener disabled")
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/debug/goroutines", goroutineDump)
+
+	return &http.Server{
+		Addr:              cfg.AdminAddr,
+		Handler:           requireToken(cfg.AdminToken, mux),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+
+// goroutineDump writes the stack of every goroutine as plain text
+func goroutineDump(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	runtimepprof.Lookup("goroutine").WriteTo(w, 2)
+}
+
+// requireToken rejects requests without the bearer token
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			http.Error(w, "a valid admin token is required", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,215 @@
+package dispatch
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/ecommerce/be-api-gin/internal/calendar"
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// horizonDays is how far ahead a dispatch day is looked for before giving
+// up on a promise
+const horizonDays = 60
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// schedule is a parsed dispatch schedule
+type schedule struct {
+	loc      *time.Location
+	hour     int
+	minute   int
+	days     map[time.Weekday]bool
+	holidays map[string]bool
+	region   string // whose holidays close the schedule too
+}
+
+// Planner promises when orders will be dispatched from the configured
+// schedules
+type Planner struct {
+	fallback *schedule
+	sellers  map[string]*schedule
+	calendar *calendar.Calendar
+}
+
+// NewPlanner parses the configured schedules, logging and skipping any
+// that are invalid. Dispatch also stops on the holidays of each schedule's
+// region, or for a warehouse the region it observes. It returns nil if
+// dispatch promises are not configured, and a nil Planner makes no
+// promises.
+func NewPlanner(appConfig *config.Config, holidays *calendar.Calendar) *Planner {
+	cfg := appConfig.Dispatch
+	if cfg == nil {
+		return nil
+	}
+
+	warehouses := make(map[string]*schedule, len(cfg.Warehouses))
+	for id, s := range cfg.Warehouses {
+		if parsed := parse("warehouse "+id, s, cfg.Holidays); parsed != nil {
+			if parsed.region == "" {
+				parsed.region = appConfig.WarehouseRegions[id]
+			}
+			warehouses[id] = parsed
+		}
+	}
+
+	p := &Planner{
+		sellers:  make(map[string]*schedule, len(cfg.Sellers)),
+		calendar: holidays,
+	}
+	if cfg.Default != nil {
+		p.fallback = parse("default", cfg.Default, cfg.Holidays)
+	}
+	for id, s := range cfg.Sellers {
+		if s.Warehouse == "" {
+			if parsed := parse("seller "+id, s, cfg.Holidays); parsed != nil {
+				p.sellers[id] = parsed
+			}
+			continue
+		}
+		if warehouse, ok := warehouses[s.Warehouse]; ok {
+			p.sellers[id] = warehouse
+			continue
+		}
+		slog.Warn("Dispatch schedule names an unknown warehouse, using the default", "seller_id", id, "warehouse", s.Warehouse)
+	}
+	return p
+}
+
+// parse validates a schedule, returning nil if it is invalid
+func parse(name string, s *config.DispatchSchedule, holidays []string) *schedule {
+	parsed := &schedule{
+		loc:      time.UTC,
+		days:     make(map[time.Weekday]bool),
+		holidays: make(map[string]bool),
+		region:   s.Region,
+	}
+	if s.TimeZone != "" {
+		loc, err := time.LoadLocation(s.TimeZone)
+		if err != nil {
+			slog.Warn("Invalid dispatch time zone, skipping schedule", "schedule", name, "time_zone", s.TimeZone)
+			return nil
+		}
+		parsed.loc = loc
+	}
+
+	cutoff, err := time.Parse("15:04", s.Cutoff)
+	if err != nil {
+		slog.Warn("Invalid dispatch cutoff, skipping schedule", "schedule", name, "cutoff", s.Cutoff)
+		return nil
+	}
+	parsed.hour, parsed.minute = cutoff.Hour(), cutoff.Minute()
+
+	days := s.Days
+	if len(days) == 0 {
+		days = []string{"mon", "tue", "wed", "thu", "fri"}
+	}
+	for _, day := range days {
+		weekday, ok := weekdays[strings.ToLower(day)]
+		if !ok {
+			slog.Warn("Invalid dispatch day, skipping schedule", "schedule", name, "day", day)
+			return nil
+		}
+		parsed.days[weekday] = true
+	}
+
+	for _, list := range [][]string{holidays, s.Holidays} {
+		for _, date := range list {
+			if _, err := time.Parse(calendar.DateLayout, date); err != nil {
+				slog.Warn("Invalid dispatch holiday, ignoring it", "schedule", name, "date", date)
+				continue
+			}
+			parsed.holidays[date] = true
+		}
+	}
+	return parsed
+}
+
+// Promise returns when an order for the seller's products placed at now
+// would be dispatched, or nil if there is no schedule for the seller or no
+// dispatch day within the next 60 days
+func (p *Planner) Promise(sellerID string, now time.Time) *models.DispatchPromise {
+	if p == nil {
+		return nil
+	}
+	s, ok := p.sellers[sellerID]
+	if !ok {
+		s = p.fallback
+	}
+	if s == nil {
+		return nil
+	}
+
+	local := now.In(s.loc)
+	for i := 0; i < horizonDays; i++ {
+		// Step by calendar date rather than 24 hours to stay on the right
+		// day across daylight saving changes
+		day := time.Date(local.Year(), local.Month(), local.Day()+i, 0, 0, 0, 0, s.loc)
+		date := day.Format(calendar.DateLayout)
+		if !s.days[day.Weekday()] || s.holidays[date] || p.calendar.Holiday(s.region, date) != nil {
+			continue
+		}
+		cutoff := time.Date(day.Year(), day.Month(), day.Day(), s.hour, s.minute, 0, 0, s.loc)
+		if !now.Before(cutoff) {
+			continue
+		}
+		return newPromise(day, cutoff, i == 0, now)
+	}
+	return nil
+}
+
+// Latest returns the promise of whichever products will be dispatched last,
+// which is when an order of them all is complete. Between promises for the
+// same day the earliest cutoff wins, as every item must make it.
+func Latest(promises ...*models.DispatchPromise) *models.DispatchPromise {
+	var latest *models.DispatchPromise
+	for _, promise := range promises {
+		if promise == nil {
+			continue
+		}
+		if latest == nil || promise.DispatchDate > latest.DispatchDate ||
+			(promise.DispatchDate == latest.DispatchDate && promise.OrderBy.Before(latest.OrderBy.Time)) {
+			latest = promise
+		}
+	}
+	return latest
+}
+
+// newPromise describes dispatch on day for orders placed by cutoff
+func newPromise(day, cutoff time.Time, sameDay bool, now time.Time) *models.DispatchPromise {
+	promise := &models.DispatchPromise{
+		SameDay:      sameDay,
+		DispatchDate: day.Format(calendar.DateLayout),
+		OrderBy:      models.NewTimestamp(cutoff),
+	}
+	if sameDay {
+		promise.Message = "Order within " + formatRemaining(cutoff.Sub(now)) + " for same-day dispatch"
+	} else {
+		promise.Message = "Order now for dispatch on " + day.Format("Mon 2 Jan")
+	}
+	return promise
+}
+
+// formatRemaining formats the time left before a cutoff in hours and whole
+// minutes, e.g. 2h 13m
+func formatRemaining(d time.Duration) string {
+	minutes := int(d / time.Minute)
+	if minutes < 1 {
+		minutes = 1
+	}
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh %dm", minutes/60, minutes%60)
+}
@@ -0,0 +1,96 @@
+package document
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Formats documents can be rendered in
+const (
+	FormatPDF  = "pdf"
+	FormatCSV  = "csv"
+	FormatHTML = "html"
+)
+
+// ErrUnknownFormat is returned for a format documents can't be rendered in
+var ErrUnknownFormat = errors.New("unknown document format")
+
+// Document is a titled table to print or import. Notes are lines about the
+// document as a whole, shown above the table in PDFs and HTML, and Summary
+// lines, such as totals, are shown below it; CSVs hold only the table.
+type Document struct {
+	Title   string
+	Notes   []string
+	Columns []string
+	Rows    [][]string
+	Summary []string
+}
+
+// ParseFormat returns the format named by a ?format= query value, PDF when
+// it is empty
+func ParseFormat(name string) (string, error) {
+	switch name {
+	case "", FormatPDF:
+		return FormatPDF, nil
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatHTML:
+		return FormatHTML, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+// ContentType returns the media type of a format
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	}
+	return "application/pdf"
+}
+
+// Render writes doc to w in format
+func Render(w io.Writer, doc *Document, format string) error {
+	switch format {
+	case FormatPDF:
+		return renderPDF(w, doc)
+	case FormatCSV:
+		return renderCSV(w, doc)
+	case FormatHTML:
+		return renderHTML(w, doc)
+	}
+	return ErrUnknownFormat
+}
+
+// renderCSV writes the document's table with its columns as the header row
+func renderCSV(w io.Writer, doc *Document) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(csvRow(doc.Columns)); err != nil {
+		return err
+	}
+	for _, row := range doc.Rows {
+		if err := out.Write(csvRow(row)); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// csvRow guards a row's cells against formula injection: cells starting
+// with a character spreadsheets read as a formula, such as a seller's
+// product name "=HYPERLINK(...)", are prefixed with ' so they stay text
+func csvRow(cells []string) []string {
+	row := make([]string, len(cells))
+	for i, cell := range cells {
+		if cell != "" && strings.ContainsRune("=+-@", rune(cell[0])) {
+			cell = "'" + cell
+		}
+		row[i] = cell
+	}
+	return row
+}
@@ -0,0 +1,38 @@
+package document
+
+import (
+	"html/template"
+	"io"
+)
+
+// htmlTemplate lays a document out as a standalone page that prints cleanly
+var htmlTemplate = template.Must(template.New("document").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; margin: 2em; }
+table { border-collapse: collapse; width: 100%; margin: 1em 0; }
+th, td { border-bottom: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+p { margin: 0.2em 0; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Notes}}<p>{{.}}</p>
+{{end}}<table>
+<thead><tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+{{range .Summary}}<p>{{.}}</p>
+{{end}}</body>
+</html>
+`))
+
+// renderHTML writes the document as an HTML page, escaping its text
+func renderHTML(w io.Writer, doc *Document) error {
+	return htmlTemplate.Execute(w, doc)
+}
@@ -0,0 +1,185 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Page layout of rendered PDFs: US Letter in points, set in 9pt Courier so
+// columns line up by padding alone
+const (
+	pageWidth    = 612
+	pageHeight   = 792
+	margin       = 54
+	fontSize     = 9
+	titleSize    = 14
+	lineHeight   = 12
+	lineChars    = (pageWidth - 2*margin) * 10 / (fontSize * 6) // Courier glyphs are 0.6em wide
+	linesPerPage = (pageHeight-2*margin)/lineHeight - 3         // less the title and the gap below it
+	columnGap    = "  "
+)
+
+// renderPDF writes the document as a PDF, continuing long tables on new
+// pages under a repeated column header, with the summary after the table.
+// Text outside printable ASCII is replaced, as the standard fonts don't
+// carry it.
+func renderPDF(w io.Writer, doc *Document) error {
+	header, rows := tableLines(doc)
+
+	var pages [][]string
+	var page []string
+	for _, note := range doc.Notes {
+		page = append(page, truncate(printable(note)))
+	}
+	if len(page) > 0 {
+		page = append(page, "")
+	}
+	page = append(page, header...)
+	for _, row := range rows {
+		if len(page) >= linesPerPage {
+			pages = append(pages, page)
+			page = append([]string{}, header...)
+		}
+		page = append(page, row)
+	}
+	if len(doc.Summary) > 0 {
+		page = append(page, "")
+	}
+	for _, line := range doc.Summary {
+		if len(page) >= linesPerPage {
+			pages = append(pages, page)
+			page = nil
+		}
+		page = append(page, truncate(printable(line)))
+	}
+	pages = append(pages, page)
+
+	pdf := &pdfWriter{}
+	pdf.buf.WriteString("%PDF-1.4\n")
+
+	// Objects 1 to 4 are the catalog, page tree, and fonts; each page then
+	// takes a page object and a content stream
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	pdf.object("<< /Type /Catalog /Pages 2 0 R >>")
+	pdf.object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	pdf.object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	pdf.object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+	for i, lines := range pages {
+		pdf.object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+2*i))
+		pdf.stream(pageContent(doc.Title, lines, i+1, len(pages)))
+	}
+
+	_, err := w.Write(pdf.finish())
+	return err
+}
+
+// tableLines lays out the document's columns and rows as text lines, each
+// column padded to its widest cell and every line cut to the page width
+func tableLines(doc *Document) (header, rows []string) {
+	widths := make([]int, len(doc.Columns))
+	for i, col := range doc.Columns {
+		widths[i] = len(printable(col))
+	}
+	for _, row := range doc.Rows {
+		for i, cell := range row {
+			if i < len(widths) && len(printable(cell)) > widths[i] {
+				widths[i] = len(printable(cell))
+			}
+		}
+	}
+
+	line := func(cells []string) string {
+		padded := make([]string, len(widths))
+		for i := range widths {
+			cell := ""
+			if i < len(cells) {
+				cell = printable(cells[i])
+			}
+			padded[i] = cell + strings.Repeat(" ", widths[i]-len(cell))
+		}
+		return truncate(strings.TrimRight(strings.Join(padded, columnGap), " "))
+	}
+
+	rule := make([]string, len(widths))
+	for i, width := range widths {
+		rule[i] = strings.Repeat("-", width)
+	}
+	header = []string{line(doc.Columns), line(rule)}
+	for _, row := range doc.Rows {
+		rows = append(rows, line(row))
+	}
+	return header, rows
+}
+
+// truncate cuts a line that doesn't fit the page width
+func truncate(line string) string {
+	if len(line) <= lineChars {
+		return line
+	}
+	return line[:lineChars-3] + "..."
+}
+
+// pageContent returns the content stream drawing a page: the title, the
+// lines, and the page number at the foot
+func pageContent(title string, lines []string, number, total int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "BT\n/F2 %d Tf\n%d %d Td\n%d TL\n(%s) Tj\n", titleSize, margin, pageHeight-margin-titleSize, lineHeight, pdfText(title))
+	fmt.Fprintf(&b, "/F1 %d Tf\nT*\nT*\n", fontSize)
+	for _, line := range lines {
+		fmt.Fprintf(&b, "(%s) Tj\nT*\n", pdfText(line))
+	}
+	b.WriteString("ET\n")
+	fmt.Fprintf(&b, "BT\n/F1 %d Tf\n%d %d Td\n(Page %d of %d) Tj\nET\n", fontSize, margin, margin/2, number, total)
+	return b.String()
+}
+
+// printable replaces the characters of s the standard fonts can't show
+func printable(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e {
+			return '?'
+		}
+		return r
+	}, s)
+}
+
+// pdfText escapes text for a PDF string literal
+func pdfText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(printable(s))
+}
+
+// pdfWriter assembles numbered PDF objects and the cross-reference table
+// locating them
+type pdfWriter struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+// object appends the next object with the given body
+func (p *pdfWriter) object(body string) {
+	p.offsets = append(p.offsets, p.buf.Len())
+	fmt.Fprintf(&p.buf, "%d 0 obj\n%s\nendobj\n", len(p.offsets), body)
+}
+
+// stream appends the next object as a stream of content
+func (p *pdfWriter) stream(content string) {
+	p.object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content))
+}
+
+// finish appends the cross-reference table and trailer, returning the
+// complete file
+func (p *pdfWriter) finish() []byte {
+	xref := p.buf.Len()
+	fmt.Fprintf(&p.buf, "xref\n0 %d\n0000000000 65535 f \n", len(p.offsets)+1)
+	for _, offset := range p.offsets {
+		fmt.Fprintf(&p.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&p.buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(p.offsets)+1, xref)
+	return p.buf.Bytes()
+}
@@ -0,0 +1,293 @@
+package errorcodes
+
+import (
+	"net/http"
+	"sort"
+)
+
+// Codes set explicitly by handlers and middleware
+const (
+	InsufficientScope        = "insufficient_scope"
+	UnsupportedGrantType     = "unsupported_grant_type"
+	InvalidClient            = "invalid_client"
+	InvalidScope             = "invalid_scope"
+	SignatureMissing         = "signature_missing"
+	SignatureUnknownPartner  = "signature_unknown_partner"
+	SignatureExpired         = "signature_expired"
+	SignatureInvalid         = "signature_invalid"
+	SignatureReplayed        = "signature_replayed"
+	IdempotencyKeyInProgress = "idempotency_key_in_progress"
+	IdempotencyKeyReused     = "idempotency_key_reused"
+	InvalidDevice            = "invalid_device"
+	LocalIDReused            = "local_id_reused"
+)
+
+// Codes derived from the response status when no more specific code is set
+const (
+	BadRequest           = "bad_request"
+	Unauthorized         = "unauthorized"
+	PaymentRequired      = "payment_required"
+	Forbidden            = "forbidden"
+	NotFound             = "not_found"
+	MethodNotAllowed     = "method_not_allowed"
+	Conflict             = "conflict"
+	PayloadTooLarge      = "payload_too_large"
+	UnsupportedMediaType = "unsupported_media_type"
+	UnprocessableEntity  = "unprocessable_entity"
+	TooManyRequests      = "too_many_requests"
+	HeadersTooLarge      = "headers_too_large"
+	InternalError        = "internal_error"
+	BadGateway           = "bad_gateway"
+	ServiceUnavailable   = "service_unavailable"
+	GatewayTimeout       = "gateway_timeout"
+	ClientError          = "client_error"
+	ServerError          = "server_error"
+)
+
+// Doc is the documentation for an error code
+type Doc struct {
+	Code        string `json:"code"`
+	Status      int    `json:"status"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Resolution  string `json:"resolution"`
+}
+
+// byStatus is the default code for each status
+var byStatus = map[int]string{
+	http.StatusBadRequest:                  BadRequest,
+	http.StatusUnauthorized:                Unauthorized,
+	http.StatusPaymentRequired:             PaymentRequired,
+	http.StatusForbidden:                   Forbidden,
+	http.StatusNotFound:                    NotFound,
+	http.StatusMethodNotAllowed:            MethodNotAllowed,
+	http.StatusConflict:                    Conflict,
+	http.StatusRequestEntityTooLarge:       PayloadTooLarge,
+	http.StatusUnsupportedMediaType:        UnsupportedMediaType,
+	http.StatusUnprocessableEntity:         UnprocessableEntity,
+	http.StatusTooManyRequests:             TooManyRequests,
+	http.StatusRequestHeaderFieldsTooLarge: HeadersTooLarge,
+	http.StatusInternalServerError:         InternalError,
+	http.StatusBadGateway:                  BadGateway,
+	http.StatusServiceUnavailable:          ServiceUnavailable,
+	http.StatusGatewayTimeout:              GatewayTimeout,
+}
+
+// ForStatus returns the default code for an error status
+func ForStatus(status int) string {
+	if code, ok := byStatus[status]; ok {
+		return code
+	}
+	if status >= http.StatusInternalServerError {
+		return ServerError
+	}
+	return ClientError
+}
+
+// docs documents every code
+var docs = map[string]Doc{
+	BadRequest: {
+		Status:      http.StatusBadRequest,
+		Title:       "Bad request",
+		Description: "The request body or query parameters are malformed or fail validation. The message names the offending field.",
+		Resolution:  "Fix the request as the message describes. Retrying it unchanged will fail again.",
+	},
+	Unauthorized: {
+		Status:      http.StatusUnauthorized,
+		Title:       "Unauthorized",
+		Description: "The request has no credentials, or its token or API key is invalid, expired, or revoked.",
+		Resolution:  "Send a valid bearer token or API key. Refresh an expired access token with POST /api/v1/auth/refresh.",
+	},
+	PaymentRequired: {
+		Status:      http.StatusPaymentRequired,
+		Title:       "Payment required",
+		Description: "The payment method was declined, so the payment or order was not completed.",
+		Resolution:  "Retry with another payment method. Retrying with the same one will usually be declined again.",
+	},
+	Forbidden: {
+		Status:      http.StatusForbidden,
+		Title:       "Forbidden",
+		Description: "The caller is authenticated but its roles do not grant the permission the route requires, or the resource belongs to someone else.",
+		Resolution:  "Ask an administrator for the required role. Retrying will not help.",
+	},
+	NotFound: {
+		Status:      http.StatusNotFound,
+		Title:       "Not found",
+		Description: "The route or resource does not exist, or is not visible to the caller.",
+		Resolution:  "Check the path and ID. Unpublished and moderated content is reported as not found.",
+	},
+	MethodNotAllowed: {
+		Status:      http.StatusMethodNotAllowed,
+		Title:       "Method not allowed",
+		Description: "The route exists but does not support the HTTP method.",
+		Resolution:  "Use one of the methods documented for the route.",
+	},
+	Conflict: {
+		Status:      http.StatusConflict,
+		Title:       "Conflict",
+		Description: "The request conflicts with the resource's current state, for example resolving an item twice or overselling inventory.",
+		Resolution:  "Fetch the resource's current state and decide whether the request still applies.",
+	},
+	PayloadTooLarge: {
+		Status:      http.StatusRequestEntityTooLarge,
+		Title:       "Payload too large",
+		Description: "The request body exceeds the route's size limit.",
+		Resolution:  "Send a smaller body, for example a smaller file or fewer items per request.",
+	},
+	UnsupportedMediaType: {
+		Status:      http.StatusUnsupportedMediaType,
+		Title:       "Unsupported media type",
+		Description: "The request body's content type is not accepted by the route.",
+		Resolution:  "Send one of the content types the route accepts.",
+	},
+	UnprocessableEntity: {
+		Status:      http.StatusUnprocessableEntity,
+		Title:       "Unprocessable entity",
+		Description: "The request is well formed but cannot be carried out, for example publishing an incomplete product.",
+		Resolution:  "Fix the problems listed in the message and retry.",
+	},
+	TooManyRequests: {
+		Status:      http.StatusTooManyRequests,
+		Title:       "Too many requests",
+		Description: "The caller exceeded the rate limit for the route group.",
+		Resolution:  "Wait for the number of seconds in Retry-After before retrying.",
+	},
+	HeadersTooLarge: {
+		Status:      http.StatusRequestHeaderFieldsTooLarge,
+		Title:       "Request headers too large",
+		Description: "The request's headers exceed the gateway's size limit.",
+		Resolution:  "Send fewer or smaller headers, such as cookies.",
+	},
+	InternalError: {
+		Status:      http.StatusInternalServerError,
+		Title:       "Internal error",
+		Description: "The gateway or a backend service failed unexpectedly.",
+		Resolution:  "Retry later. If it persists, contact support with the request_id and trace_id from the response.",
+	},
+	BadGateway: {
+		Status:      http.StatusBadGateway,
+		Title:       "Bad gateway",
+		Description: "An upstream service returned an invalid response.",
+		Resolution:  "Retry later. If it persists, contact support with the request_id and trace_id from the response.",
+	},
+	ServiceUnavailable: {
+		Status:      http.StatusServiceUnavailable,
+		Title:       "Service unavailable",
+		Description: "The gateway is overloaded or shutting down, or a backend service it depends on is down.",
+		Resolution:  "Retry after the delay in Retry-After, if present, with exponential backoff.",
+	},
+	GatewayTimeout: {
+		Status:      http.StatusGatewayTimeout,
+		Title:       "Gateway timeout",
+		Description: "A backend service did not answer in time.",
+		Resolution:  "Retry idempotent requests with backoff. Check the state of the resource before retrying others.",
+	},
+	ClientError: {
+		Status:      http.StatusBadRequest,
+		Title:       "Client error",
+		Description: "The request failed with a 4xx status that has no more specific code.",
+		Resolution:  "Read the message and fix the request.",
+	},
+	ServerError: {
+		Status:      http.StatusInternalServerError,
+		Title:       "Server error",
+		Description: "The request failed with a 5xx status that has no more specific code.",
+		Resolution:  "Retry later. If it persists, contact support with the request_id and trace_id from the response.",
+	},
+	InsufficientScope: {
+		Status:      http.StatusForbidden,
+		Title:       "Insufficient scope",
+		Description: "The client credentials token or API key does not carry a scope that covers the route.",
+		Resolution:  "Request a token with one of the scopes listed in the WWW-Authenticate header, or issue an API key whose scopes include the route.",
+	},
+	UnsupportedGrantType: {
+		Status:      http.StatusBadRequest,
+		Title:       "Unsupported grant type",
+		Description: "The token endpoint only supports the client_credentials grant.",
+		Resolution:  "Send grant_type=client_credentials.",
+	},
+	InvalidClient: {
+		Status:      http.StatusUnauthorized,
+		Title:       "Invalid client",
+		Description: "The OAuth client ID or secret is wrong.",
+		Resolution:  "Check the client credentials. Retrying will not help.",
+	},
+	InvalidScope: {
+		Status:      http.StatusBadRequest,
+		Title:       "Invalid scope",
+		Description: "The token request asked for a scope the client is not allowed.",
+		Resolution:  "Request only the scopes the client is registered for.",
+	},
+	SignatureMissing: {
+		Status:      http.StatusUnauthorized,
+		Title:       "Signature missing",
+		Description: "A signed partner request is missing X-Signature, X-Signature-Timestamp, or X-Signature-Nonce.",
+		Resolution:  "Send all three signature headers.",
+	},
+	SignatureUnknownPartner: {
+		Status:      http.StatusUnauthorized,
+		Title:       "Unknown partner",
+		Description: "No signing key is configured for the partner named in the request.",
+		Resolution:  "Check the partner ID, or ask for the partner to be onboarded.",
+	},
+	SignatureExpired: {
+		Status:      http.StatusUnauthorized,
+		Title:       "Signature expired",
+		Description: "The signature timestamp is malformed or outside the allowed clock skew.",
+		Resolution:  "Sign with the current Unix time and keep the signing host's clock in sync.",
+	},
+	SignatureInvalid: {
+		Status:      http.StatusUnauthorized,
+		Title:       "Signature invalid",
+		Description: "The request signature does not match the method, URI, timestamp, nonce, and body.",
+		Resolution:  "Check the signing key and that the body is signed exactly as sent.",
+	},
+	SignatureReplayed: {
+		Status:      http.StatusUnauthorized,
+		Title:       "Signature replayed",
+		Description: "The signature nonce has already been used.",
+		Resolution:  "Use a fresh nonce for every request, including retries.",
+	},
+	IdempotencyKeyInProgress: {
+		Status:      http.StatusConflict,
+		Title:       "Idempotency key in progress",
+		Description: "An earlier request with the same Idempotency-Key is still being processed.",
+		Resolution:  "Retry after the delay in Retry-After to get the earlier request's response.",
+	},
+	IdempotencyKeyReused: {
+		Status:      http.StatusUnprocessableEntity,
+		Title:       "Idempotency key reused",
+		Description: "The Idempotency-Key was already used for a request with a different method, path, or body.",
+		Resolution:  "Use a fresh key for every new request, and the same key only for retries of it.",
+	},
+	InvalidDevice: {
+		Status:      http.StatusUnauthorized,
+		Title:       "Invalid device",
+		Description: "The POS device is unknown, disabled, or gave the wrong secret.",
+		Resolution:  "Check the device ID and secret, and that the device is registered for its store.",
+	},
+	LocalIDReused: {
+		Status:      http.StatusUnprocessableEntity,
+		Title:       "Local order ID reused",
+		Description: "The device already submitted a different order under this local_id.",
+		Resolution:  "Give every order queued on the device its own local_id, and reuse it only to resubmit that order.",
+	},
+}
+
+// Lookup returns the documentation for a code
+func Lookup(code string) (Doc, bool) {
+	doc, ok := docs[code]
+	doc.Code = code
+	return doc, ok
+}
+
+// All returns the documentation for every code, sorted by code
+func All() []Doc {
+	all := make([]Doc, 0, len(docs))
+	for code := range docs {
+		doc, _ := Lookup(code)
+		all = append(all, doc)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Code < all[j].Code })
+	return all
+}
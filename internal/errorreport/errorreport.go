@@ -0,0 +1,176 @@
+package errorreport
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+)
+
+// reportTimeout bounds how long delivering a single report may take
+const reportTimeout = 5 * time.Second
+
+// Event describes a recovered panic and the request that caused it
+type Event struct {
+	Message   string    `json:"message"`
+	Stack     string    `json:"stack"`
+	RequestID string    `json:"request_id,omitempty"`
+	TraceID   string    `json:"trace_id,omitempty"`
+	Method    string    `json:"method"`
+	Route     string    `json:"route,omitempty"`
+	Path      string    `json:"path"`
+	UserID    string    `json:"user_id,omitempty"`
+	ClientIP  string    `json:"client_ip,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// Reporter sends events to an error tracking service. Report returns
+// immediately; delivery happens in the background and failures are logged.
+type Reporter interface {
+	Report(event *Event)
+}
+
+// NewReporter creates the reporter configured for the application: Sentry
+// when a DSN is set, otherwise a generic webhook. It returns nil when
+// neither is configured.
+func NewReporter(cfg *config.Config) Reporter {
+	if cfg.SentryDSN != "" {
+		reporter, err := newSentryReporter(cfg.SentryDSN, cfg.Environment)
+		if err != nil {
+			slog.Warn("Invalid SENTRY_DSN, panics will not be reported", "error", err)
+			return nil
+		}
+		return reporter
+	}
+	if cfg.ErrorWebhookURL != "" {
+		return &webhookReporter{
+			url:    cfg.ErrorWebhookURL,
+			client: &http.Client{Timeout: reportTimeout},
+		}
+	}
+	return nil
+}
+
+// webhookReporter posts events as JSON to a URL
+type webhookReporter struct {
+	url    string
+	client *http.Client
+}
+
+// Report posts the event to the webhook
+func (r *webhookReporter) Report(event *Event) {
+	go func() {
+		if err := post(r.client, r.url, event, nil); err != nil {
+			slog.Warn("Failed to report error to webhook", "request_id", event.RequestID, "error", err)
+		}
+	}()
+}
+
+// sentryReporter sends events to Sentry's store endpoint
+type sentryReporter struct {
+	storeURL    string
+	auth        string
+	environment string
+	client      *http.Client
+}
+
+// newSentryReporter parses a DSN of the form
+// https://<key>@<host>/<project>
+func newSentryReporter(dsn, environment string) (*sentryReporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+	project := strings.TrimPrefix(u.Path, "/")
+	if u.User == nil || u.User.Username() == "" || project == "" {
+		return nil, fmt.Errorf("dsn must include a public key and project ID")
+	}
+
+	return &sentryReporter{
+		storeURL:    fmt.Sprintf("%s://%s/api/%s/store/", u.Scheme, u.Host, project),
+		auth:        fmt.Sprintf("Sentry sentry_version=7, sentry_client=be-api-gin/1.0, sentry_key=%s", u.User.Username()),
+		environment: environment,
+		client:      &http.Client{Timeout: reportTimeout},
+	}, nil
+}
+
+// Report sends the event to Sentry
+func (r *sentryReporter) Report(event *Event) {
+	id := make([]byte, 16)
+	rand.Read(id)
+
+	payload := map[string]interface{}{
+		"event_id":    hex.EncodeToString(id),
+		"timestamp":   event.Time.UTC().Format(time.RFC3339),
+		"level":       "error",
+		"platform":    "go",
+		"environment": r.environment,
+		"message":     event.Message,
+		"transaction": event.Method + " " + event.Route,
+		"exception": map[string]interface{}{
+			"values": []map[string]string{{"type": "panic", "value": event.Message}},
+		},
+		"request": map[string]string{
+			"method": event.Method,
+			"url":    event.Path,
+		},
+		"user": map[string]string{
+			"id":         event.UserID,
+			"ip_address": event.ClientIP,
+		},
+		"tags": map[string]string{
+			"request_id": event.RequestID,
+			"trace_id":   event.TraceID,
+			"route":      event.Route,
+		},
+		"extra": map[string]string{
+			"stack": event.Stack,
+		},
+	}
+
+	go func() {
+		if err := post(r.client, r.storeURL, payload, map[string]string{"X-Sentry-Auth": r.auth}); err != nil {
+			slog.Warn("Failed to report error to Sentry", "request_id", event.RequestID, "error", err)
+		}
+	}()
+}
+
+// post sends body as JSON, treating any non-2xx response as an error
+func post(client *http.Client, url string, body interface{}, headers map[string]string) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), reportTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("error sink returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package expiry
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/ecommerce/be-api-gin/internal/holds"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	"github.com/ecommerce/be-api-gin/internal/publishing"
+	"github.com/ecommerce/be-api-gin/internal/suborder"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// maxReleaseAttempts is how many ticks an expired order's holds are retried
+// for before the sweeper gives up on them
+const maxReleaseAttempts = 10
+
+// Sweeper cancels orders left unpaid past a timeout, releasing the stock
+// reserved for them
+type Sweeper struct {
+	clients  *grpcclient.Clients
+	claimer  publishing.Claimer
+	timeout  time.Duration
+	interval time.Duration
+
+	// unreleased holds orders this sweeper cancelled whose holds failed to
+	// release, by order ID, with the attempts made so far. Only Run's
+	// goroutine touches it.
+	unreleased map[string]*pendingRelease
+}
+
+// pendingRelease is an expired order whose holds are still to be released
+type pendingRelease struct {
+	order    *models.Order
+	attempts int
+}
+
+// NewSweeper creates a sweeper that checks every interval for pending
+// orders older than timeout
+func NewSweeper(clients *grpcclient.Clients, claimer publishing.Claimer, timeout, interval time.Duration) *Sweeper {
+	return &Sweeper{
+		clients:    clients,
+		claimer:    claimer,
+		timeout:    timeout,
+		interval:   interval,
+		unreleased: make(map[string]*pendingRelease),
+	}
+}
+
+// Run checks for unpaid orders on every tick until the context is cancelled
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.tick(ctx, now.Truncate(s.interval))
+		}
+	}
+}
+
+// tick retries holds left unreleased, then cancels pending orders created
+// more than the timeout before now whose payment has not gone through
+func (s *Sweeper) tick(ctx context.Context, now time.Time) {
+	s.retryReleases(ctx)
+
+	claimed, err := s.claimer.Claim(ctx, "order-expiry:"+strconv.FormatInt(now.Unix(), 10), s.interval)
+	if err != nil {
+		slog.Warn("Failed to claim order expiry tick", "error", err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	orders, err := s.clients.ListPendingOrders(ctx, now.Add(-s.timeout))
+	if err != nil {
+		slog.Warn("Failed to fetch pending orders", "error", err)
+		return
+	}
+
+	for _, order := range orders {
+		payment, unpaid := s.unpaid(ctx, order)
+		if !unpaid {
+			continue
+		}
+		if err := s.expire(ctx, order, payment); err != nil {
+			slog.Warn("Failed to cancel unpaid order", "order_id", order.ID, "error", err)
+			continue
+		}
+		slog.Info("Cancelled unpaid order", "order_id", order.ID, "reservations", len(order.ReservationIDs))
+	}
+}
+
+// unpaid reports whether an order has no payment that succeeded or is
+// still being processed, returning its payment if it has one. Orders paid
+// in full with gift cards and store credit have no payment, and orders
+// whose payment can't be checked are left for the next tick.
+func (s *Sweeper) unpaid(ctx context.Context, order *models.Order) (*models.PaymentIntent, bool) {
+	if covered := order.StoreCreditApplied + order.GiftCardApplied; covered > 0 && covered >= order.TotalAmount {
+		return nil, false
+	}
+	payment, err := s.clients.GetOrderPayment(ctx, order.ID, order.UserID)
+	if err == grpcclient.ErrNotFound {
+		return nil, true
+	}
+	if err != nil {
+		slog.Warn("Failed to check order payment", "order_id", order.ID, "error", err)
+		return nil, false
+	}
+	return payment, payment.Status != models.PaymentStatusSucceeded && payment.Status != models.PaymentStatusProcessing
+}
+
+// expire cancels an unpaid order's unconfirmed payment intent, then the
+// order with its sub-orders, releases its reservations, promo code
+// redemption, store credit, and gift card amounts, and tells the customer.
+// The order is left pending for the next tick if its payment can't be
+// cancelled, so it can't be paid for once cancelled, and holds that fail to
+// release are retried on later ticks.
+func (s *Sweeper) expire(ctx context.Context, order *models.Order, payment *models.PaymentIntent) error {
+	if payment != nil && payment.Status == models.PaymentStatusRequiresConfirmation {
+		if err := s.clients.CancelPayment(ctx, payment.ID, order.UserID); err != nil {
+			return err
+		}
+	}
+	if err := suborder.SetStatus(ctx, s.clients, order.ID, order.UserID, models.OrderStatusCancelled); err != nil {
+		return err
+	}
+	if err := s.clients.CancelOrder(ctx, order.ID, order.UserID); err != nil {
+		return err
+	}
+	if err := holds.Release(ctx, s.clients, order); err != nil {
+		slog.Warn("Failed to release expired order's holds, will retry", "order_id", order.ID, "error", err)
+		s.unreleased[order.ID] = &pendingRelease{order: order, attempts: 1}
+	}
+
+	err := s.clients.NotifyUser(ctx, order.UserID, &models.Notification{
+		Type:    "order_expired",
+		Title:   "Order cancelled",
+		Message: "Your order was cancelled because it wasn't paid in time",
+		Data: map[string]string{
+			"order_id": order.ID,
+		},
+	})
+	if err != nil {
+		slog.Warn("Failed to notify customer", "user_id", order.UserID, "error", err)
+	}
+	return nil
+}
+
+// retryReleases tries again to release the holds of orders whose release
+// failed when they expired, giving up after maxReleaseAttempts
+func (s *Sweeper) retryReleases(ctx context.Context) {
+	for id, pending := range s.unreleased {
+		err := holds.Release(ctx, s.clients, pending.order)
+		if err == nil {
+			delete(s.unreleased, id)
+			slog.Info("Released expired order's holds", "order_id", id, "attempts", pending.attempts+1)
+			continue
+		}
+		pending.attempts++
+		if pending.attempts >= maxReleaseAttempts {
+			delete(s.unreleased, id)
+			slog.Error("Giving up releasing expired order's holds", "order_id", id, "attempts", pending.attempts, "error", err)
+			continue
+		}
+		slog.Warn("Failed to release expired order's holds, will retry", "order_id", id, "attempts", pending.attempts, "error", err)
+	}
+}
@@ -0,0 +1,542 @@
+package guest
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// TokenHeader carries a guest's checkout token
+const TokenHeader = "X-Guest-Token"
+
+// codeDigits is the length of email verification codes
+const codeDigits = 6
+
+// Errors returned when confirming a verification code
+var (
+	ErrNoChallenge      = errors.New("no verification is pending for this session")
+	ErrCodeMismatch     = errors.New("verification code is incorrect")
+	ErrTooManyAttempts  = errors.New("too many incorrect verification codes")
+	ErrEmailMismatch    = errors.New("email does not match the pending verification")
+	ErrSessionNotActive = errors.New("guest token is invalid or expired")
+	ErrTooManyCodes     = errors.New("too many verification codes requested")
+	ErrLocked           = errors.New("too many incorrect verification codes; verification is locked")
+)
+
+// Limits bounds how often codes are sent and guessed for an email address
+// and for a cart session. The counts outlast individual codes, so
+// requesting a new code doesn't buy more guesses. Once either reaches its
+// limit, the email or session is locked out until Lockout has passed since
+// its last counted send or wrong code.
+type Limits struct {
+	MaxCodes    int
+	MaxFailures int
+	Lockout     time.Duration
+}
+
+// Challenge is an email verification code sent to a guest, bound to their
+// cart session
+type Challenge struct {
+	SessionID string    `json:"session_id"`
+	Email     string    `json:"email"`
+	CodeHash  string    `json:"code_hash"`
+	Attempts  int       `json:"attempts"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Session is a guest's verified email, usable for checkout from the cart
+// session it was verified in until it expires
+type Session struct {
+	Token     string    `json:"token"`
+	SessionID string    `json:"session_id"`
+	Email     string    `json:"email"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// UserID returns the ID guest orders placed with a verified email are kept
+// under, the same for every session verifying that email
+func UserID(email string) string {
+	sum := sha256.Sum256([]byte(NormalizeEmail(email)))
+	return "guest-" + hex.EncodeToString(sum[:12])
+}
+
+// NormalizeEmail lowercases and trims an email address for comparison
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Store holds pending verifications and verified guest sessions until they
+// expire
+type Store interface {
+	// SaveChallenge keeps a session's pending verification, replacing any
+	// earlier one, until its ExpiresAt
+	SaveChallenge(ctx context.Context, challenge *Challenge) error
+	// GetChallenge returns a session's pending verification, or nil if
+	// there is none
+	GetChallenge(ctx context.Context, sessionID string) (*Challenge, error)
+	// DeleteChallenge removes a session's pending verification
+	DeleteChallenge(ctx context.Context, sessionID string) error
+	// SaveSession keeps a verified guest session until its ExpiresAt
+	SaveSession(ctx context.Context, session *Session) error
+	// GetSession returns the session a token was issued for, or nil if it
+	// does not exist or has expired
+	GetSession(ctx context.Context, token string) (*Session, error)
+	// DeleteSession ends a verified guest session
+	DeleteSession(ctx context.Context, token string) error
+	// Increment adds one to a counter, which expires window after its last
+	// increment, and returns the new count
+	Increment(ctx context.Context, key string, window time.Duration) (int, error)
+	// Count returns a counter's count and how long until it expires
+	Count(ctx context.Context, key string) (int, time.Duration, error)
+}
+
+// Verifier confirms guests' email addresses with one-time codes and issues
+// the tokens they check out with
+type Verifier struct {
+	store       Store
+	codeTTL     time.Duration
+	sessionTTL  time.Duration
+	maxAttempts int
+	limits      Limits
+}
+
+// NewVerifier creates a verifier whose codes are valid for codeTTL and
+// maxAttempts guesses, and whose tokens are valid for sessionTTL
+func NewVerifier(store Store, codeTTL, sessionTTL time.Duration, maxAttempts int, limits Limits) *Verifier {
+	return &Verifier{
+		store:       store,
+		codeTTL:     codeTTL,
+		sessionTTL:  sessionTTL,
+		maxAttempts: maxAttempts,
+		limits:      limits,
+	}
+}
+
+// LockedError is returned while an email or cart session is locked out,
+// with how long until it is let through again
+type LockedError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *LockedError) Unwrap() error {
+	return e.Err
+}
+
+// counterKeys returns the keys counting kind, sends or failures, for an
+// email and for a cart session
+func counterKeys(kind, sessionID, email string) []string {
+	sum := sha256.Sum256([]byte(NormalizeEmail(email)))
+	return []string{
+		kind + ":email:" + hex.EncodeToString(sum[:]),
+		kind + ":session:" + sessionID,
+	}
+}
+
+// checkLimit returns a LockedError wrapping err if any of the counters has
+// reached limit. A limit that isn't positive is no limit.
+func (v *Verifier) checkLimit(ctx context.Context, keys []string, limit int, err error) error {
+	if limit <= 0 {
+		return nil
+	}
+	for _, key := range keys {
+		count, ttl, countErr := v.store.Count(ctx, key)
+		if countErr != nil {
+			return countErr
+		}
+		if count >= limit {
+			return &LockedError{Err: err, RetryAfter: ttl}
+		}
+	}
+	return nil
+}
+
+// increment adds one to each of the counters
+func (v *Verifier) increment(ctx context.Context, keys []string) error {
+	for _, key := range keys {
+		if _, err := v.store.Increment(ctx, key, v.limits.Lockout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Start creates a verification code for email in a cart session, replacing
+// any pending one, and returns the code to send to the guest. It fails with
+// a LockedError once too many codes have been sent to the email or in the
+// session, or either is locked out after too many wrong codes.
+func (v *Verifier) Start(ctx context.Context, sessionID, email string) (string, error) {
+	if err := v.checkLimit(ctx, counterKeys("failures", sessionID, email), v.limits.MaxFailures, ErrLocked); err != nil {
+		return "", err
+	}
+	sends := counterKeys("sends", sessionID, email)
+	if err := v.checkLimit(ctx, sends, v.limits.MaxCodes, ErrTooManyCodes); err != nil {
+		return "", err
+	}
+	if err := v.increment(ctx, sends); err != nil {
+		return "", err
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+	code := fmt.Sprintf("%0*d", codeDigits, n.Int64())
+
+	challenge := &Challenge{
+		SessionID: sessionID,
+		Email:     NormalizeEmail(email),
+		CodeHash:  hashCode(sessionID, code),
+		ExpiresAt: time.Now().Add(v.codeTTL),
+	}
+	if err := v.store.SaveChallenge(ctx, challenge); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// Confirm checks a code sent for email in a cart session and, if it is
+// correct, issues a guest session. A challenge is discarded after it is
+// confirmed or has been guessed wrong too many times. Wrong codes also count
+// against the email and the session, which are locked out, with a
+// LockedError, once they reach the limit.
+func (v *Verifier) Confirm(ctx context.Context, sessionID, email, code string) (*Session, error) {
+	failures := counterKeys("failures", sessionID, email)
+	if err := v.checkLimit(ctx, failures, v.limits.MaxFailures, ErrLocked); err != nil {
+		return nil, err
+	}
+
+	challenge, err := v.store.GetChallenge(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if challenge == nil {
+		return nil, ErrNoChallenge
+	}
+	if challenge.Email != NormalizeEmail(email) {
+		return nil, ErrEmailMismatch
+	}
+
+	if !hmac.Equal([]byte(challenge.CodeHash), []byte(hashCode(sessionID, code))) {
+		if err := v.increment(ctx, failures); err != nil {
+			return nil, err
+		}
+		challenge.Attempts++
+		if challenge.Attempts >= v.maxAttempts {
+			if err := v.store.DeleteChallenge(ctx, sessionID); err != nil {
+				return nil, err
+			}
+			return nil, ErrTooManyAttempts
+		}
+		if err := v.store.SaveChallenge(ctx, challenge); err != nil {
+			return nil, err
+		}
+		return nil, ErrCodeMismatch
+	}
+
+	if err := v.store.DeleteChallenge(ctx, sessionID); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	session := &Session{
+		Token:     "gt-" + hex.EncodeToString(buf),
+		SessionID: sessionID,
+		Email:     challenge.Email,
+		ExpiresAt: time.Now().Add(v.sessionTTL),
+	}
+	if err := v.store.SaveSession(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Session returns the guest session a token was issued for in a cart
+// session, or ErrSessionNotActive if the token is unknown, expired, or was
+// issued to another cart session
+func (v *Verifier) Session(ctx context.Context, token, sessionID string) (*Session, error) {
+	session, err := v.store.GetSession(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || session.SessionID != sessionID {
+		return nil, ErrSessionNotActive
+	}
+	return session, nil
+}
+
+// End ends a guest session so its token can no longer be used
+func (v *Verifier) End(ctx context.Context, session *Session) error {
+	return v.store.DeleteSession(ctx, session.Token)
+}
+
+// hashCode hashes a code with the session it was sent in, so a code leaked
+// from the store can't be replayed in another session
+func hashCode(sessionID, code string) string {
+	sum := sha256.Sum256([]byte(sessionID + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+// MemoryStore is an in-process Store. Guests must verify and check out
+// through the same gateway instance.
+type MemoryStore struct {
+	mu         sync.Mutex
+	challenges map[string]*Challenge
+	sessions   map[string]*Session
+	counters   map[string]*counter
+}
+
+// counter is a count kept by MemoryStore until it expires
+type counter struct {
+	count     int
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		challenges: make(map[string]*Challenge),
+		sessions:   make(map[string]*Session),
+		counters:   make(map[string]*counter),
+	}
+}
+
+// SaveChallenge keeps a pending verification until it expires
+func (s *MemoryStore) SaveChallenge(ctx context.Context, challenge *Challenge) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purge()
+	saved := *challenge
+	s.challenges[challenge.SessionID] = &saved
+	return nil
+}
+
+// GetChallenge returns an unexpired pending verification
+func (s *MemoryStore) GetChallenge(ctx context.Context, sessionID string) (*Challenge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	challenge, ok := s.challenges[sessionID]
+	if !ok || time.Now().After(challenge.ExpiresAt) {
+		return nil, nil
+	}
+	found := *challenge
+	return &found, nil
+}
+
+// DeleteChallenge removes a pending verification
+func (s *MemoryStore) DeleteChallenge(ctx context.Context, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.challenges, sessionID)
+	return nil
+}
+
+// SaveSession keeps a guest session until it expires
+func (s *MemoryStore) SaveSession(ctx context.Context, session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purge()
+	saved := *session
+	s.sessions[session.Token] = &saved
+	return nil
+}
+
+// GetSession returns an unexpired guest session
+func (s *MemoryStore) GetSession(ctx context.Context, token string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[token]
+	if !ok || time.Now().After(session.ExpiresAt) {
+		return nil, nil
+	}
+	found := *session
+	return &found, nil
+}
+
+// DeleteSession removes a guest session
+func (s *MemoryStore) DeleteSession(ctx context.Context, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, token)
+	return nil
+}
+
+// Increment adds one to a counter and extends it to window from now
+func (s *MemoryStore) Increment(ctx context.Context, key string, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purge()
+	c, ok := s.counters[key]
+	if !ok {
+		c = &counter{}
+		s.counters[key] = c
+	}
+	c.count++
+	c.expiresAt = time.Now().Add(window)
+	return c.count, nil
+}
+
+// Count returns an unexpired counter's count and time left
+func (s *MemoryStore) Count(ctx context.Context, key string) (int, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.counters[key]
+	if !ok || time.Now().After(c.expiresAt) {
+		return 0, 0, nil
+	}
+	return c.count, time.Until(c.expiresAt), nil
+}
+
+// purge drops expired challenges, sessions, and counters. Callers must hold
+// s.mu.
+func (s *MemoryStore) purge() {
+	now := time.Now()
+	for key, c := range s.counters {
+		if now.After(c.expiresAt) {
+			delete(s.counters, key)
+		}
+	}
+	for key, challenge := range s.challenges {
+		if now.After(challenge.ExpiresAt) {
+			delete(s.challenges, key)
+		}
+	}
+	for key, session := range s.sessions {
+		if now.After(session.ExpiresAt) {
+			delete(s.sessions, key)
+		}
+	}
+}
+
+// RedisStore is a Store shared by all gateway replicas
+type RedisStore struct {
+	client *goredis.Client
+	prefix string
+}
+
+// NewRedisStore creates a store using client, namespacing keys with prefix
+func NewRedisStore(client *goredis.Client, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// SaveChallenge keeps a pending verification until it expires
+func (s *RedisStore) SaveChallenge(ctx context.Context, challenge *Challenge) error {
+	return s.set(ctx, s.prefix+"challenge:"+challenge.SessionID, challenge, challenge.ExpiresAt)
+}
+
+// GetChallenge returns an unexpired pending verification
+func (s *RedisStore) GetChallenge(ctx context.Context, sessionID string) (*Challenge, error) {
+	var challenge Challenge
+	found, err := s.get(ctx, s.prefix+"challenge:"+sessionID, &challenge)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+// DeleteChallenge removes a pending verification
+func (s *RedisStore) DeleteChallenge(ctx context.Context, sessionID string) error {
+	return s.client.Del(ctx, s.prefix+"challenge:"+sessionID).Err()
+}
+
+// SaveSession keeps a guest session until it expires
+func (s *RedisStore) SaveSession(ctx context.Context, session *Session) error {
+	return s.set(ctx, s.prefix+"session:"+session.Token, session, session.ExpiresAt)
+}
+
+// GetSession returns an unexpired guest session
+func (s *RedisStore) GetSession(ctx context.Context, token string) (*Session, error) {
+	var session Session
+	found, err := s.get(ctx, s.prefix+"session:"+token, &session)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// DeleteSession removes a guest session
+func (s *RedisStore) DeleteSession(ctx context.Context, token string) error {
+	return s.client.Del(ctx, s.prefix+"session:"+token).Err()
+}
+
+// Increment adds one to a counter and extends it to window from now
+func (s *RedisStore) Increment(ctx context.Context, key string, window time.Duration) (int, error) {
+	pipe := s.client.TxPipeline()
+	incr := pipe.Incr(ctx, s.prefix+key)
+	pipe.Expire(ctx, s.prefix+key, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return int(incr.Val()), nil
+}
+
+// Count returns a counter's count and time left
+func (s *RedisStore) Count(ctx context.Context, key string) (int, time.Duration, error) {
+	pipe := s.client.Pipeline()
+	get := pipe.Get(ctx, s.prefix+key)
+	ttl := pipe.PTTL(ctx, s.prefix+key)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, goredis.Nil) {
+		return 0, 0, err
+	}
+	count, err := get.Int()
+	if errors.Is(err, goredis.Nil) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	return count, max(ttl.Val(), 0), nil
+}
+
+// set stores value as JSON under key until expiresAt
+func (s *RedisStore) set(ctx context.Context, key string, value any, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, key, data, ttl).Err()
+}
+
+// get decodes the JSON under key into value, reporting whether it exists
+func (s *RedisStore) get(ctx context.Context, key string, value any) (bool, error) {
+	data, err := s.client.Get(ctx, key).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal(data, value)
+}
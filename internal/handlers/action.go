@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/cache"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	"github.com/ecommerce/be-api-gin/internal/undo"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// ActionHandler handles reversing recent destructive seller actions
+type ActionHandler struct {
+	grpcClients *grpcclient.Clients
+	undo        undo.Store
+	products    *cache.ProductCache
+}
+
+// NewActionHandler creates a new action handler
+func NewActionHandler(clients *grpcclient.Clients, undoStore undo.Store, products *cache.ProductCache) *ActionHandler {
+	return &ActionHandler{
+		grpcClients: clients,
+		undo:        undoStore,
+		products:    products,
+	}
+}
+
+// UndoAction reverses a product deletion or bulk price change while its
+// undo window is open
+// POST /api/v1/actions/:id/undo
+func (h *ActionHandler) UndoAction(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	action, err := h.undo.Take(ctx, userID, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch action",
+			Message: err.Error(),
+		})
+		return
+	}
+	if action == nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Action not found",
+			Message: "No undoable action exists with the given ID, or its undo window has closed",
+		})
+		return
+	}
+
+	var skipped []string
+	switch action.Type {
+	case models.ActionProductDelete:
+		_, err = h.grpcClients.RestoreProduct(ctx, action.ProductID, userID)
+		if err == nil {
+			h.products.Invalidate(ctx, action.ProductID)
+		}
+	case models.ActionPriceChange:
+		skipped, err = restorePrices(ctx, h.grpcClients, h.products, action.Prices, action.Applied, userID)
+	}
+	if err != nil {
+		// Keep the action so the undo can be retried within the window
+		if saveErr := h.undo.Save(ctx, action); saveErr != nil {
+			logging.FromContext(ctx).Warn("Failed to keep undo action for retry", "action_id", action.ID, "error", saveErr)
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to undo action",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.UndoResponse{
+		Message: "Action undone successfully",
+		Skipped: skipped,
+	})
+}
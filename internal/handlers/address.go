@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// postalCodePatterns are the postal code formats checked for countries
+// where they are well defined; other countries accept any code
+var postalCodePatterns = map[string]*regexp.Regexp{
+	"US": regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+	"CA": regexp.MustCompile(`^[A-Z]\d[A-Z] ?\d[A-Z]\d$`),
+	"GB": regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`),
+	"DE": regexp.MustCompile(`^\d{5}$`),
+	"FR": regexp.MustCompile(`^\d{5}$`),
+	"AU": regexp.MustCompile(`^\d{4}$`),
+	"JP": regexp.MustCompile(`^\d{3}-?\d{4}$`),
+}
+
+// AddressHandler handles the signed-in user's saved shipping addresses
+type AddressHandler struct {
+	grpcClients *grpcclient.Clients
+}
+
+// NewAddressHandler creates a new address handler
+func NewAddressHandler(clients *grpcclient.Clients) *AddressHandler {
+	return &AddressHandler{
+		grpcClients: clients,
+	}
+}
+
+// ListAddresses returns the user's saved addresses, the default first
+// GET /api/v1/me/addresses
+func (h *AddressHandler) ListAddresses(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	addresses, err := h.grpcClients.ListAddresses(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch addresses",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"addresses": addresses,
+	})
+}
+
+// GetAddress returns one of the user's saved addresses
+// GET /api/v1/me/addresses/:id
+func (h *AddressHandler) GetAddress(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	address, err := h.grpcClients.GetAddress(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		respondAddressError(c, "Failed to fetch address", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, address)
+}
+
+// CreateAddress saves a new shipping address to the user's account
+// POST /api/v1/me/addresses
+func (h *AddressHandler) CreateAddress(c *gin.Context) {
+	var req models.AddressRequest
+	if !bindAddress(c, &req) {
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	address, err := h.grpcClients.CreateAddress(c.Request.Context(), savedAddress(&req, "", userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to save address",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, address)
+}
+
+// UpdateAddress replaces one of the user's saved addresses
+// PUT /api/v1/me/addresses/:id
+func (h *AddressHandler) UpdateAddress(c *gin.Context) {
+	var req models.AddressRequest
+	if !bindAddress(c, &req) {
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	address, err := h.grpcClients.UpdateAddress(c.Request.Context(), savedAddress(&req, c.Param("id"), userID))
+	if err != nil {
+		respondAddressError(c, "Failed to update address", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, address)
+}
+
+// DeleteAddress removes one of the user's saved addresses
+// DELETE /api/v1/me/addresses/:id
+func (h *AddressHandler) DeleteAddress(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.grpcClients.DeleteAddress(c.Request.Context(), c.Param("id"), userID); err != nil {
+		respondAddressError(c, "Failed to delete address", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// bindAddress binds and validates an address request, checking the postal
+// code's format for countries with a known one. It responds 400 and
+// returns false if the address is invalid.
+func bindAddress(c *gin.Context, req *models.AddressRequest) bool {
+	if err := bindJSON(c, req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return false
+	}
+	if pattern, ok := postalCodePatterns[req.Country]; ok && !pattern.MatchString(req.PostalCode) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid postal code",
+			Message: "Postal code " + req.PostalCode + " is not valid for " + req.Country,
+		})
+		return false
+	}
+	return true
+}
+
+// savedAddress builds the saved address described by an address request
+func savedAddress(req *models.AddressRequest, id, userID string) *models.SavedAddress {
+	return &models.SavedAddress{
+		ID:            id,
+		UserID:        userID,
+		Label:         req.Label,
+		RecipientName: req.RecipientName,
+		Phone:         req.Phone,
+		Address: models.Address{
+			Street:     req.Street,
+			City:       req.City,
+			State:      req.State,
+			PostalCode: req.PostalCode,
+			Country:    req.Country,
+		},
+		IsDefault: req.IsDefault,
+	}
+}
+
+// respondAddressError responds 404 for addresses the user doesn't have, or
+// 500 with title otherwise
+func respondAddressError(c *gin.Context, title string, err error) {
+	if err == grpcclient.ErrNotFound || err == grpcclient.ErrUnauthorized {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Address not found",
+			Message: "No saved address exists with the given ID",
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		Error:   title,
+		Message: err.Error(),
+	})
+}
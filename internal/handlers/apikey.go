@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/middleware"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// APIKeyHandler handles API key management requests
+type APIKeyHandler struct {
+	grpcClients *grpcclient.Clients
+	config      *config.Config
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(clients *grpcclient.Clients, cfg *config.Config) *APIKeyHandler {
+	return &APIKeyHandler{
+		grpcClients: clients,
+		config:      cfg,
+	}
+}
+
+// CreateAPIKey issues a new API key for the authenticated user
+// POST /api/v1/api-keys
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req models.CreateAPIKeyRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: "expires_at must be in the future",
+		})
+		return
+	}
+
+	userID, ok := h.requireTokenUser(c)
+	if !ok {
+		return
+	}
+
+	key, prefix, hash, err := middleware.GenerateAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to generate API key",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Call user service via gRPC
+	apiKey, err := h.grpcClients.CreateAPIKey(c.Request.Context(), &models.APIKey{
+		Name:      req.Name,
+		OwnerID:   userID,
+		Prefix:    prefix,
+		KeyHash:   hash,
+		Scopes:    req.Scopes,
+		Roles:     middleware.GetRoles(c),
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to create API key",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.IssuedAPIKey{APIKey: apiKey, Key: key})
+}
+
+// ListAPIKeys returns the authenticated user's API keys
+// GET /api/v1/api-keys
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	userID, ok := h.requireTokenUser(c)
+	if !ok {
+		return
+	}
+
+	// Call user service via gRPC
+	keys, err := h.grpcClients.ListAPIKeys(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch API keys",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
+}
+
+// GetAPIKey returns a single API key by ID
+// GET /api/v1/api-keys/:id
+func (h *APIKeyHandler) GetAPIKey(c *gin.Context) {
+	userID, ok := h.requireTokenUser(c)
+	if !ok {
+		return
+	}
+
+	apiKey, ok := h.fetchAPIKey(c, userID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, apiKey)
+}
+
+// UpdateAPIKey updates an API key's name or scopes
+// PUT /api/v1/api-keys/:id
+func (h *APIKeyHandler) UpdateAPIKey(c *gin.Context) {
+	var req models.UpdateAPIKeyRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := h.requireTokenUser(c)
+	if !ok {
+		return
+	}
+
+	apiKey, ok := h.fetchAPIKey(c, userID)
+	if !ok {
+		return
+	}
+
+	if req.Name != nil {
+		apiKey.Name = *req.Name
+	}
+	if req.Scopes != nil {
+		apiKey.Scopes = *req.Scopes
+	}
+
+	// Call user service via gRPC
+	updated, err := h.grpcClients.UpdateAPIKey(c.Request.Context(), apiKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to update API key",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// RotateAPIKey replaces an API key's secret, invalidating the old one
+// POST /api/v1/api-keys/:id/rotate
+func (h *APIKeyHandler) RotateAPIKey(c *gin.Context) {
+	userID, ok := h.requireTokenUser(c)
+	if !ok {
+		return
+	}
+
+	apiKey, ok := h.fetchAPIKey(c, userID)
+	if !ok {
+		return
+	}
+
+	if apiKey.RevokedAt != nil {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Cannot rotate API key",
+			Message: "Revoked API keys cannot be rotated",
+		})
+		return
+	}
+
+	key, prefix, hash, err := middleware.GenerateAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to generate API key",
+			Message: err.Error(),
+		})
+		return
+	}
+	apiKey.Prefix = prefix
+	apiKey.KeyHash = hash
+
+	// Call user service via gRPC
+	updated, err := h.grpcClients.UpdateAPIKey(c.Request.Context(), apiKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to rotate API key",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.IssuedAPIKey{APIKey: updated, Key: key})
+}
+
+// RevokeAPIKey revokes an API key
+// DELETE /api/v1/api-keys/:id
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	userID, ok := h.requireTokenUser(c)
+	if !ok {
+		return
+	}
+
+	// Call user service via gRPC
+	err := h.grpcClients.RevokeAPIKey(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "API key not found",
+				Message: "No API key exists with the given ID",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to revoke API key",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "API key revoked successfully",
+	})
+}
+
+// SetAPIKeyTier assigns an API key to an SLA tier, changing its timeouts,
+// response caching, and rate limits
+// PUT /api/v1/admin/api-keys/:id/tier
+func (h *APIKeyHandler) SetAPIKeyTier(c *gin.Context) {
+	var req models.SetAPIKeyTierRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+	if _, ok := h.config.SLATiers[req.Tier]; req.Tier != "" && !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Unknown tier",
+			Message: "No SLA tier is configured with the name " + req.Tier,
+		})
+		return
+	}
+
+	// Call user service via gRPC
+	updated, err := h.grpcClients.SetAPIKeyTier(c.Request.Context(), c.Param("id"), req.Tier)
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "API key not found",
+				Message: "No API key exists with the given ID",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to update API key",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// requireTokenUser returns the authenticated user's ID, rejecting requests
+// authenticated with an API key so keys cannot mint or manage other keys
+func (h *APIKeyHandler) requireTokenUser(c *gin.Context) (string, bool) {
+	if middleware.IsAPIKeyAuth(c) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "Forbidden",
+			Message: "API keys cannot be managed using API key authentication",
+		})
+		return "", false
+	}
+	return requireUserID(c)
+}
+
+// fetchAPIKey loads the API key named by the :id path parameter,
+// responding with an error if it cannot be fetched
+func (h *APIKeyHandler) fetchAPIKey(c *gin.Context, userID string) (*models.APIKey, bool) {
+	apiKey, err := h.grpcClients.GetAPIKey(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "API key not found",
+				Message: "No API key exists with the given ID",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch API key",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	return apiKey, true
+}
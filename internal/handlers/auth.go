@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/middleware"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// AuthHandler handles token lifecycle requests
+type AuthHandler struct {
+	grpcClients *grpcclient.Clients
+	config      *config.Config
+}
+
+// NewAuthHandler creates a new auth handler
+func NewAuthHandler(clients *grpcclient.Clients, cfg *config.Config) *AuthHandler {
+	return &AuthHandler{
+		grpcClients: clients,
+		config:      cfg,
+	}
+}
+
+// Refresh exchanges a refresh token for a new token pair
+// POST /api/v1/auth/refresh
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Call user service via gRPC
+	tokens, err := h.grpcClients.RefreshToken(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		if err == grpcclient.ErrUnauthorized {
+			c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+				Error:   "Invalid refresh token",
+				Message: "The provided refresh token is invalid, expired, or revoked",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to refresh token",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout revokes the current access token and, if provided, the refresh token
+// POST /api/v1/auth/logout
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req models.LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := bindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid request body",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	// Revoke the access token until it would have expired
+	claims, _ := middleware.GetClaims(c)
+	tokenID, _ := middleware.GetTokenID(c)
+	expiresAt := time.Now().Add(time.Hour)
+	if claims != nil && claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	if err := middleware.RevokeToken(c.Request.Context(), tokenID, expiresAt); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to log out",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Revoke the refresh token via the user service
+	if req.RefreshToken != "" {
+		if err := h.grpcClients.RevokeRefreshToken(c.Request.Context(), userID, req.RefreshToken); err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Failed to revoke refresh token",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Logged out successfully",
+	})
+}
+
+// RevokeToken revokes an access token by its ID (the jti claim) on every
+// gateway replica until it expires
+// POST /api/v1/admin/tokens/revoke
+func (h *AuthHandler) RevokeToken(c *gin.Context) {
+	var req models.RevokeTokenRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	expiresAt := time.Now().Add(time.Duration(h.config.JWTExpiration) * time.Hour)
+	if req.ExpiresAt != nil {
+		expiresAt = req.ExpiresAt.Time
+	}
+
+	if err := middleware.RevokeToken(c.Request.Context(), req.TokenID, expiresAt); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to revoke token",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Token revoked successfully",
+	})
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// BackendHandler handles runtime changes to backend service targets
+type BackendHandler struct {
+	grpcClients *grpcclient.Clients
+}
+
+// NewBackendHandler creates a new backend handler
+func NewBackendHandler(clients *grpcclient.Clients) *BackendHandler {
+	return &BackendHandler{
+		grpcClients: clients,
+	}
+}
+
+// ListBackendTargets returns this instance's target for each backend
+// service and region
+// GET /api/v1/admin/backends
+func (h *BackendHandler) ListBackendTargets(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"targets": h.grpcClients.BackendTargets(),
+	})
+}
+
+// SetBackendTarget moves a backend service's calls to a new address, once
+// the new address is connected and healthy. Other replicas follow when
+// targets are shared.
+// PUT /api/v1/admin/backends/:backend/target
+func (h *BackendHandler) SetBackendTarget(c *gin.Context) {
+	var req models.SetBackendTargetRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	target, err := h.grpcClients.SetBackendTarget(c.Request.Context(), c.Param("backend"), req.Region, req.Addr)
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Backend not found",
+				Message: "No backend service is deployed in the given region",
+			})
+			return
+		}
+		if err == grpcclient.ErrTargetUnhealthy {
+			c.JSON(http.StatusBadGateway, models.ErrorResponse{
+				Error:   "Backend target unhealthy",
+				Message: "The new address could not be connected to or failed its health check; traffic was not moved",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to change backend target",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, target)
+}
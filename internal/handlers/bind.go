@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	"github.com/ecommerce/be-api-gin/internal/middleware"
+	"github.com/ecommerce/be-api-gin/internal/normalize"
+)
+
+// UnknownFieldsError lists request body fields the endpoint does not accept
+type UnknownFieldsError struct {
+	Fields []string
+}
+
+// Error names the unknown fields
+func (e *UnknownFieldsError) Error() string {
+	return "unknown fields: " + strings.Join(e.Fields, ", ")
+}
+
+// bindJSON binds the JSON request body into obj, normalizes its tagged
+// strings, and validates it. Routes in strict mode reject bodies containing
+// fields obj does not declare, listing every offending field rather than
+// silently ignoring them.
+func bindJSON(c *gin.Context, obj any) error {
+	if c.Request.Body == nil {
+		return errors.New("invalid request")
+	}
+	body, err := c.GetRawData()
+	if err != nil {
+		return err
+	}
+
+	if middleware.StrictJSON(c) {
+		var raw any
+		if len(body) > 0 && json.Unmarshal(body, &raw) == nil {
+			if fields := unknownFields(raw, reflect.TypeOf(obj), ""); len(fields) > 0 {
+				sort.Strings(fields)
+				return &UnknownFieldsError{Fields: fields}
+			}
+		}
+	}
+
+	if err := json.NewDecoder(bytes.NewReader(body)).Decode(obj); err != nil {
+		return err
+	}
+	normalize.Struct(obj)
+	return binding.Validator.ValidateStruct(obj)
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// unknownFields returns the paths of object keys in value that do not match
+// a field of t. Keys match field names case-insensitively, as in
+// encoding/json. Types with custom unmarshaling are not inspected.
+func unknownFields(value any, t reflect.Type, path string) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return nil
+	}
+
+	var unknown []string
+	switch v := value.(type) {
+	case map[string]any:
+		switch t.Kind() {
+		case reflect.Struct:
+			fields := jsonFields(t)
+			for key, child := range v {
+				field, ok := lookupField(fields, key)
+				if !ok {
+					unknown = append(unknown, joinPath(path, key))
+					continue
+				}
+				unknown = append(unknown, unknownFields(child, field, joinPath(path, key))...)
+			}
+		case reflect.Map:
+			for key, child := range v {
+				unknown = append(unknown, unknownFields(child, t.Elem(), joinPath(path, key))...)
+			}
+		}
+	case []any:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, child := range v {
+				unknown = append(unknown, unknownFields(child, t.Elem(), path+"["+strconv.Itoa(i)+"]")...)
+			}
+		}
+	}
+	return unknown
+}
+
+// jsonFields maps the JSON names of a struct's fields, including those
+// promoted from embedded structs, to their types
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			embedded := f.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for n, ft := range jsonFields(embedded) {
+					if _, exists := fields[n]; !exists {
+						fields[n] = ft
+					}
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+// lookupField finds a field by exact name, then case-insensitively
+func lookupField(fields map[string]reflect.Type, key string) (reflect.Type, bool) {
+	if t, ok := fields[key]; ok {
+		return t, true
+	}
+	for name, t := range fields {
+		if strings.EqualFold(name, key) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// joinPath appends a key to a dotted field path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/calendar"
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// CalendarHandler serves regional holiday calendars
+type CalendarHandler struct {
+	calendar *calendar.Calendar
+}
+
+// NewCalendarHandler creates a new calendar handler
+func NewCalendarHandler(holidays *calendar.Calendar) *CalendarHandler {
+	return &CalendarHandler{
+		calendar: holidays,
+	}
+}
+
+// ListHolidays returns a region's holidays between two dates, defaulting to
+// the year from today
+// GET /api/v1/calendar/holidays?region=US-CA&from=2026-01-01&to=2026-12-31
+func (h *CalendarHandler) ListHolidays(c *gin.Context) {
+	region, ok := requireRegion(c)
+	if !ok {
+		return
+	}
+
+	today := time.Now().In(h.calendar.Location(region))
+	from := c.DefaultQuery("from", today.Format(calendar.DateLayout))
+	to := c.DefaultQuery("to", today.AddDate(1, 0, 0).Format(calendar.DateLayout))
+	for _, date := range []string{from, to} {
+		if _, err := time.Parse(calendar.DateLayout, date); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid date",
+				Message: "Dates must be formatted as YYYY-MM-DD",
+			})
+			return
+		}
+	}
+	if to < from {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid date range",
+			Message: "to must not be before from",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HolidaysResponse{
+		Region:   region,
+		From:     from,
+		To:       to,
+		Holidays: h.calendar.Holidays(region, from, to),
+	})
+}
+
+// GetDay returns whether a date is a holiday in a region
+// GET /api/v1/calendar/days/:date?region=US-CA
+func (h *CalendarHandler) GetDay(c *gin.Context) {
+	region, ok := requireRegion(c)
+	if !ok {
+		return
+	}
+
+	date := c.Param("date")
+	if _, err := time.Parse(calendar.DateLayout, date); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid date",
+			Message: "Dates must be formatted as YYYY-MM-DD",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.CalendarDay{
+		Date:    date,
+		Region:  region,
+		Holiday: h.calendar.Holiday(region, date),
+	})
+}
+
+// requireRegion returns the region query parameter, upper-cased, responding
+// with 400 if it is missing
+func requireRegion(c *gin.Context) (string, bool) {
+	region := strings.ToUpper(strings.TrimSpace(c.Query("region")))
+	if region == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Missing region",
+			Message: "A region such as US or US-CA is required",
+		})
+		return "", false
+	}
+	return region, true
+}
@@ -0,0 +1,641 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/cache"
+	"github.com/ecommerce/be-api-gin/internal/cart"
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/coupon"
+	"github.com/ecommerce/be-api-gin/internal/dispatch"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/middleware"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// Errors returned by cart updates, answered with 400 or 404
+var (
+	errCartFull       = errors.New("cart is full")
+	errCartQuantity   = errors.New("quantity exceeds the per-product limit")
+	errCartItemAbsent = errors.New("product is not in the cart")
+)
+
+// CartHandler handles shopping cart requests for signed-in users and guests
+type CartHandler struct {
+	grpcClients *grpcclient.Clients
+	products    *cache.ProductCache
+	store       cart.Store
+	dispatch    *dispatch.Planner
+	config      *config.Config
+}
+
+// NewCartHandler creates a new cart handler
+func NewCartHandler(clients *grpcclient.Clients, products *cache.ProductCache, store cart.Store, planner *dispatch.Planner, cfg *config.Config) *CartHandler {
+	return &CartHandler{
+		grpcClients: clients,
+		products:    products,
+		store:       store,
+		dispatch:    planner,
+		config:      cfg,
+	}
+}
+
+// cartOwner identifies the caller's cart: the signed-in user's, or the guest
+// session's named by the X-Cart-Session header. With create set, guests
+// without a session are issued one in the response header. The owner is
+// empty if the caller has no cart yet.
+func (h *CartHandler) cartOwner(c *gin.Context, create bool) (owner, sessionID string, ok bool) {
+	sessionID = c.GetHeader(cart.SessionHeader)
+	if sessionID != "" && !cart.ValidSessionID(sessionID) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid cart session",
+			Message: "The " + cart.SessionHeader + " header is not a cart session ID issued by this API",
+		})
+		return "", "", false
+	}
+
+	if userID, signedIn := middleware.GetUserID(c); signedIn {
+		return cart.UserOwner(userID), "", true
+	}
+
+	if sessionID != "" {
+		return cart.GuestOwner(sessionID), sessionID, true
+	}
+	if !create {
+		return "", "", true
+	}
+
+	sessionID, err := cart.NewSessionID()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to create cart session",
+			Message: err.Error(),
+		})
+		return "", "", false
+	}
+	c.Header(cart.SessionHeader, sessionID)
+	return cart.GuestOwner(sessionID), sessionID, true
+}
+
+// mergeGuestCart moves a guest session's cart into the user's cart, capping
+// quantities at the products' stock, and logs a cart_merged event for
+// analytics. The guest cart is taken from the store atomically, so
+// concurrent requests merge it once. A failed merge puts the guest cart
+// back, to be retried on the user's next cart fetch.
+func (h *CartHandler) mergeGuestCart(c *gin.Context, userID, sessionID string) {
+	ctx := c.Request.Context()
+	logger := logging.FromContext(ctx)
+
+	peek, err := h.store.Get(ctx, cart.GuestOwner(sessionID))
+	if err != nil {
+		logger.Warn("Failed to fetch guest cart for merge", "error", err)
+		return
+	}
+	if peek == nil {
+		return
+	}
+	stock := make(map[string]int32, len(peek.Items))
+	for _, item := range peek.Items {
+		inventory, err := h.grpcClients.GetInventory(ctx, item.ProductID)
+		if err != nil {
+			logger.Warn("Failed to check stock for guest cart merge", "product_id", item.ProductID, "error", err)
+			return
+		}
+		stock[item.ProductID] = max(inventory.Quantity-inventory.Reserved, 0)
+	}
+
+	guest, err := h.store.Take(ctx, cart.GuestOwner(sessionID))
+	if err != nil {
+		logger.Warn("Failed to take guest cart for merge", "error", err)
+		return
+	}
+	if guest == nil {
+		// Another request merged it first
+		return
+	}
+
+	var result cart.MergeResult
+	merged, err := h.store.Update(ctx, cart.UserOwner(userID), h.ttl(""), func(sc *models.Cart) error {
+		result = cart.Merge(sc, guest, h.config.CartMergeStrategy, h.config.CartMaxItems, h.config.CartMaxQuantity, stock)
+		return nil
+	})
+	if err != nil {
+		logger.Warn("Failed to merge guest cart", "error", err)
+		_, restoreErr := h.store.Update(ctx, cart.GuestOwner(sessionID), h.ttl(sessionID), func(gc *models.Cart) error {
+			cart.Merge(gc, guest, cart.MergeMax, h.config.CartMaxItems, h.config.CartMaxQuantity, nil)
+			return nil
+		})
+		if restoreErr != nil {
+			logger.Error("Failed to restore guest cart after failed merge", "session_id", sessionID, "error", restoreErr)
+		}
+		return
+	}
+
+	logger.Info("Guest cart merged",
+		"event", "cart_merged",
+		"session_id", sessionID,
+		"strategy", h.config.CartMergeStrategy,
+		"guest_items", len(guest.Items),
+		"added", result.Added,
+		"combined", result.Combined,
+		"dropped", result.Dropped,
+		"item_count", merged.ItemCount,
+		"subtotal", merged.Subtotal,
+	)
+}
+
+// ttl returns how long a cart lives after its last change
+func (h *CartHandler) ttl(sessionID string) time.Duration {
+	if sessionID != "" {
+		return time.Duration(h.config.CartGuestTTLHours) * time.Hour
+	}
+	return time.Duration(h.config.CartTTLDays) * 24 * time.Hour
+}
+
+// present fills in who owns a cart, when it expires, and when it would be
+// dispatched
+func (h *CartHandler) present(c *gin.Context, sc *models.Cart, sessionID string) *models.Cart {
+	if sc == nil {
+		sc = &models.Cart{Items: []models.CartItem{}}
+	}
+	cart.Recalculate(sc)
+	if userID, ok := middleware.GetUserID(c); ok {
+		sc.UserID = userID
+	}
+	sc.SessionID = sessionID
+	if sc.UpdatedAt != nil {
+		sc.ExpiresAt = models.TimestampPtr(sc.UpdatedAt.Add(h.ttl(sessionID)))
+	}
+	now := time.Now()
+	promises := make([]*models.DispatchPromise, 0, len(sc.Items))
+	for _, item := range sc.Items {
+		promises = append(promises, h.dispatch.Promise(item.SellerID, now))
+	}
+	sc.Dispatch = dispatch.Latest(promises...)
+	return sc
+}
+
+// GetCart returns the caller's cart, empty if they have none. A signed-in
+// user still sending a guest session has just logged in, and the guest
+// cart is merged into theirs first.
+// GET /api/v1/cart
+func (h *CartHandler) GetCart(c *gin.Context) {
+	owner, sessionID, ok := h.cartOwner(c, false)
+	if !ok {
+		return
+	}
+	if userID, signedIn := middleware.GetUserID(c); signedIn {
+		if guestSession := c.GetHeader(cart.SessionHeader); guestSession != "" {
+			h.mergeGuestCart(c, userID, guestSession)
+		}
+	}
+
+	var sc *models.Cart
+	if owner != "" {
+		var err error
+		if sc, err = h.store.Get(c.Request.Context(), owner); err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Failed to fetch cart",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, h.present(c, sc, sessionID))
+}
+
+// ClearCart empties the caller's cart
+// DELETE /api/v1/cart
+func (h *CartHandler) ClearCart(c *gin.Context) {
+	owner, _, ok := h.cartOwner(c, false)
+	if !ok {
+		return
+	}
+
+	if owner != "" {
+		if err := h.store.Delete(c.Request.Context(), owner); err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Failed to clear cart",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AddItem adds a product to the caller's cart at its current price, or adds
+// to the quantity of a product already in it. Guests without a cart session
+// are issued one in the X-Cart-Session header.
+// POST /api/v1/cart/items
+func (h *CartHandler) AddItem(c *gin.Context) {
+	var req models.AddCartItemRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	owner, sessionID, ok := h.cartOwner(c, true)
+	if !ok {
+		return
+	}
+
+	product, ok := h.loadProduct(c, req.ProductID)
+	if !ok {
+		return
+	}
+
+	// Check stock for the quantity the cart will hold after the addition
+	existing, err := h.store.Get(c.Request.Context(), owner)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch cart",
+			Message: err.Error(),
+		})
+		return
+	}
+	quantity := req.Quantity
+	if existing != nil {
+		if item := findCartItem(existing, req.ProductID); item != nil {
+			quantity += item.Quantity
+		}
+	}
+	if !h.checkQuantity(c, req.ProductID, quantity) {
+		return
+	}
+
+	sc, err := h.store.Update(c.Request.Context(), owner, h.ttl(sessionID), func(sc *models.Cart) error {
+		if item := findCartItem(sc, req.ProductID); item != nil {
+			if int(item.Quantity+req.Quantity) > h.config.CartMaxQuantity {
+				return errCartQuantity
+			}
+			item.Quantity += req.Quantity
+			return nil
+		}
+		if len(sc.Items) >= h.config.CartMaxItems {
+			return errCartFull
+		}
+		sc.Items = append(sc.Items, models.CartItem{
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			SellerID:    product.SellerID,
+			Quantity:    req.Quantity,
+			UnitPrice:   product.Price,
+			AddedAt:     models.Now(),
+		})
+		return nil
+	})
+	if err != nil {
+		h.respondUpdateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.present(c, sc, sessionID))
+}
+
+// UpdateItem sets the quantity of a product in the caller's cart, keeping the
+// price it was added at. A quantity of zero removes it.
+// PATCH /api/v1/cart/items/:productId
+func (h *CartHandler) UpdateItem(c *gin.Context) {
+	productID := c.Param("productId")
+
+	var req models.UpdateCartItemRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	owner, sessionID, ok := h.cartOwner(c, false)
+	if !ok {
+		return
+	}
+	if owner == "" {
+		h.respondUpdateError(c, errCartItemAbsent)
+		return
+	}
+
+	quantity := *req.Quantity
+	if quantity > 0 && !h.checkQuantity(c, productID, quantity) {
+		return
+	}
+
+	sc, err := h.store.Update(c.Request.Context(), owner, h.ttl(sessionID), func(sc *models.Cart) error {
+		if findCartItem(sc, productID) == nil {
+			return errCartItemAbsent
+		}
+		setCartQuantity(sc, productID, quantity)
+		return nil
+	})
+	if err != nil {
+		h.respondUpdateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.present(c, sc, sessionID))
+}
+
+// RemoveItem removes a product from the caller's cart
+// DELETE /api/v1/cart/items/:productId
+func (h *CartHandler) RemoveItem(c *gin.Context) {
+	productID := c.Param("productId")
+
+	owner, sessionID, ok := h.cartOwner(c, false)
+	if !ok {
+		return
+	}
+	if owner == "" {
+		h.respondUpdateError(c, errCartItemAbsent)
+		return
+	}
+
+	sc, err := h.store.Update(c.Request.Context(), owner, h.ttl(sessionID), func(sc *models.Cart) error {
+		if findCartItem(sc, productID) == nil {
+			return errCartItemAbsent
+		}
+		setCartQuantity(sc, productID, 0)
+		return nil
+	})
+	if err != nil {
+		h.respondUpdateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.present(c, sc, sessionID))
+}
+
+// ValidateCoupon reports what a promo code would take off the caller's cart
+// without applying it
+// POST /api/v1/cart/coupon/validate
+func (h *CartHandler) ValidateCoupon(c *gin.Context) {
+	var req models.CouponRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	owner, _, ok := h.cartOwner(c, false)
+	if !ok {
+		return
+	}
+
+	sc := &models.Cart{Items: []models.CartItem{}}
+	if owner != "" {
+		existing, err := h.store.Get(c.Request.Context(), owner)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Failed to fetch cart",
+				Message: err.Error(),
+			})
+			return
+		}
+		if existing != nil {
+			sc = existing
+		}
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	found, ok := redeemableCoupon(c, h.grpcClients, req.Code, userID, sc.Subtotal)
+	if !ok {
+		return
+	}
+
+	sc.Coupon = coupon.Applied(found)
+	cart.Recalculate(sc)
+	c.JSON(http.StatusOK, models.CouponQuote{
+		Code:         found.Code,
+		Type:         found.Type,
+		Discount:     sc.Discount,
+		FreeShipping: found.Type == models.CouponTypeFreeShipping,
+		Subtotal:     sc.Subtotal,
+		Total:        sc.Total,
+		ExpiresAt:    found.ExpiresAt,
+	})
+}
+
+// ApplyCoupon applies a promo code to the caller's cart, replacing any
+// applied before. The code is checked again at checkout.
+// PUT /api/v1/cart/coupon
+func (h *CartHandler) ApplyCoupon(c *gin.Context) {
+	var req models.CouponRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	owner, sessionID, ok := h.cartOwner(c, true)
+	if !ok {
+		return
+	}
+
+	existing, err := h.store.Get(c.Request.Context(), owner)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch cart",
+			Message: err.Error(),
+		})
+		return
+	}
+	subtotal := 0.0
+	if existing != nil {
+		subtotal = existing.Subtotal
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	found, ok := redeemableCoupon(c, h.grpcClients, req.Code, userID, subtotal)
+	if !ok {
+		return
+	}
+
+	sc, err := h.store.Update(c.Request.Context(), owner, h.ttl(sessionID), func(sc *models.Cart) error {
+		sc.Coupon = coupon.Applied(found)
+		return nil
+	})
+	if err != nil {
+		h.respondUpdateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.present(c, sc, sessionID))
+}
+
+// RemoveCoupon removes the promo code applied to the caller's cart
+// DELETE /api/v1/cart/coupon
+func (h *CartHandler) RemoveCoupon(c *gin.Context) {
+	owner, sessionID, ok := h.cartOwner(c, false)
+	if !ok {
+		return
+	}
+	if owner == "" {
+		c.JSON(http.StatusOK, h.present(c, nil, sessionID))
+		return
+	}
+
+	sc, err := h.store.Update(c.Request.Context(), owner, h.ttl(sessionID), func(sc *models.Cart) error {
+		sc.Coupon = nil
+		return nil
+	})
+	if err != nil {
+		h.respondUpdateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.present(c, sc, sessionID))
+}
+
+// redeemableCoupon fetches a promo code userID can redeem on subtotal,
+// responding with 422 and returning false if it is unknown or can't be
+// redeemed. Guests have an empty userID until they check out.
+func redeemableCoupon(c *gin.Context, clients *grpcclient.Clients, code, userID string, subtotal float64) (*models.Coupon, bool) {
+	found, err := clients.GetCoupon(c.Request.Context(), code, userID)
+	if err == grpcclient.ErrNotFound {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Error:   "Invalid promo code",
+			Message: "Promo code " + code + " does not exist",
+		})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, models.ErrorResponse{
+			Error:   "Failed to fetch promo code",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+
+	if err := coupon.Check(c.Request.Context(), found, subtotal, time.Now()); err != nil {
+		message := err.Error()
+		if err == coupon.ErrBelowMinimum {
+			message = fmt.Sprintf("%s of %.2f", message, found.MinSubtotal)
+		}
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Error:   "Promo code not applicable",
+			Message: message,
+		})
+		return nil, false
+	}
+	return found, true
+}
+
+// loadProduct fetches a product that can be bought, responding with 404 if
+// it does not exist or is not publicly visible
+func (h *CartHandler) loadProduct(c *gin.Context, id string) (*models.Product, bool) {
+	product, err := h.products.Get(c.Request.Context(), id)
+	if err != nil && err != grpcclient.ErrNotFound {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch product",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	if err == grpcclient.ErrNotFound || !isPubliclyVisible(product) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Product not found",
+			Message: "No product exists with the given ID",
+		})
+		return nil, false
+	}
+	return product, true
+}
+
+// checkQuantity validates a cart quantity against the per-product limit and
+// the product's stock, responding with 400 if it is too large
+func (h *CartHandler) checkQuantity(c *gin.Context, productID string, quantity int32) bool {
+	if int(quantity) > h.config.CartMaxQuantity {
+		h.respondUpdateError(c, errCartQuantity)
+		return false
+	}
+
+	available, err := h.grpcClients.CheckInventory(c.Request.Context(), productID, quantity)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to check inventory",
+			Message: err.Error(),
+		})
+		return false
+	}
+	if !available {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Insufficient inventory",
+			Message: "Product " + productID + " does not have enough stock",
+		})
+		return false
+	}
+	return true
+}
+
+// respondUpdateError answers a failed cart update
+func (h *CartHandler) respondUpdateError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errCartFull):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Cart is full",
+			Message: fmt.Sprintf("A cart can hold at most %d different products", h.config.CartMaxItems),
+		})
+	case errors.Is(err, errCartQuantity):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Quantity too large",
+			Message: fmt.Sprintf("A cart can hold at most %d of each product", h.config.CartMaxQuantity),
+		})
+	case errors.Is(err, errCartItemAbsent):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Item not found",
+			Message: "The product is not in the cart",
+		})
+	case errors.Is(err, cart.ErrConflict):
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Cart update conflict",
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to update cart",
+			Message: err.Error(),
+		})
+	}
+}
+
+// findCartItem returns the cart's line for a product, or nil
+func findCartItem(sc *models.Cart, productID string) *models.CartItem {
+	for i := range sc.Items {
+		if sc.Items[i].ProductID == productID {
+			return &sc.Items[i]
+		}
+	}
+	return nil
+}
+
+// setCartQuantity sets the quantity of a product in the cart, removing its
+// line at zero
+func setCartQuantity(sc *models.Cart, productID string, quantity int32) {
+	for i := range sc.Items {
+		if sc.Items[i].ProductID != productID {
+			continue
+		}
+		if quantity == 0 {
+			sc.Items = append(sc.Items[:i], sc.Items[i+1:]...)
+		} else {
+			sc.Items[i].Quantity = quantity
+		}
+		return
+	}
+}
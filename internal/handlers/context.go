@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/middleware"
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// requireUserID returns the authenticated user's ID set by the auth
+// middleware, responding with 401 if it is missing
+func requireUserID(c *gin.Context) (string, bool) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Authentication required",
+		})
+		return "", false
+	}
+	return userID, true
+}
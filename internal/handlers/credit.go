@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// StoreCreditHandler handles store credit balances and issuance
+type StoreCreditHandler struct {
+	grpcClients *grpcclient.Clients
+	config      *config.Config
+}
+
+// NewStoreCreditHandler creates a new store credit handler
+func NewStoreCreditHandler(clients *grpcclient.Clients, cfg *config.Config) *StoreCreditHandler {
+	return &StoreCreditHandler{
+		grpcClients: clients,
+		config:      cfg,
+	}
+}
+
+// GetBalance returns the signed-in user's store credit balance in each
+// currency, always including the payment currency
+// GET /api/v1/users/me/credit
+func (h *StoreCreditHandler) GetBalance(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	balances, err := h.grpcClients.ListStoreCredit(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch store credit",
+			Message: err.Error(),
+		})
+		return
+	}
+	if !slices.ContainsFunc(balances, func(b *models.StoreCreditBalance) bool { return b.Currency == h.config.PaymentCurrency }) {
+		balances = append(balances, &models.StoreCreditBalance{
+			UserID:    userID,
+			Currency:  h.config.PaymentCurrency,
+			UpdatedAt: models.Now(),
+		})
+	}
+
+	c.JSON(http.StatusOK, models.StoreCreditBalances{Balances: balances})
+}
+
+// IssueCredit issues store credit to a user as goodwill or as the refund of
+// one of their returns, which is then recorded as refunded. Refund credit is
+// in the currency of the return's order, which its refund amount is in.
+// POST /api/v1/admin/users/:id/credit
+func (h *StoreCreditHandler) IssueCredit(c *gin.Context) {
+	var req models.IssueStoreCreditRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	issuerID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := c.Param("id")
+	credit := &models.StoreCredit{
+		UserID:   userID,
+		Amount:   req.Amount,
+		Currency: h.config.PaymentCurrency,
+		Reason:   req.Reason,
+		Note:     req.Note,
+		IssuedBy: issuerID,
+	}
+
+	var ret *models.Return
+	switch req.Reason {
+	case models.StoreCreditReasonGoodwill:
+		if limit := h.config.StoreCreditMaxGoodwill; limit > 0 && req.Amount > limit {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Credit exceeds limit",
+				Message: "Goodwill credit can be at most " + strconv.FormatFloat(limit, 'f', 2, 64),
+			})
+			return
+		}
+	case models.StoreCreditReasonRefund:
+		ret, ok = h.refundableReturn(c, req.ReturnID, userID)
+		if !ok {
+			return
+		}
+		if credit.Amount == 0 {
+			credit.Amount = ret.RefundAmount
+		}
+		if credit.Amount > ret.RefundAmount {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Credit exceeds refund",
+				Message: "Refund credit can be at most the return's refund amount of " + strconv.FormatFloat(ret.RefundAmount, 'f', 2, 64),
+			})
+			return
+		}
+		credit.ReturnID = ret.ID
+
+		order, err := h.grpcClients.GetOrder(ctx, ret.OrderID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Failed to fetch order",
+				Message: err.Error(),
+			})
+			return
+		}
+		credit.Currency = orderCurrency(order, h.config)
+	}
+
+	issued, err := h.grpcClients.IssueStoreCredit(ctx, credit)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, models.ErrorResponse{
+			Error:   "Failed to issue store credit",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if ret != nil {
+		ret.Refund = &models.Refund{
+			ID:            issued.ID,
+			ReturnID:      ret.ID,
+			StoreCreditID: issued.ID,
+			Method:        models.RefundMethodStoreCredit,
+			Amount:        issued.Amount,
+			Currency:      issued.Currency,
+			Status:        models.RefundStatusSucceeded,
+			CreatedAt:     issued.CreatedAt,
+		}
+		if _, err := h.grpcClients.UpdateReturn(ctx, ret); err != nil {
+			// The credit has been issued, so it must not be retried blindly
+			logging.FromContext(ctx).Error("Store credit issued but not recorded on return", "return_id", ret.ID, "credit_id", issued.ID, "error", err)
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Failed to record refund",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	notifyUser(ctx, h.grpcClients, userID, &models.Notification{
+		Type:    "store_credit_issued",
+		Title:   "You've received store credit",
+		Message: strconv.FormatFloat(issued.Amount, 'f', 2, 64) + " " + issued.Currency + " of store credit was added to your account",
+		Data: map[string]string{
+			"credit_id": issued.ID,
+			"reason":    issued.Reason,
+		},
+	})
+
+	c.JSON(http.StatusCreated, issued)
+}
+
+// refundableReturn loads one of a user's returns that can still be
+// refunded, responding with an error if there is none
+func (h *StoreCreditHandler) refundableReturn(c *gin.Context, returnID, userID string) (*models.Return, bool) {
+	ret, err := h.grpcClients.GetReturn(c.Request.Context(), returnID, userID)
+	if err != nil {
+		if err == grpcclient.ErrNotFound || err == grpcclient.ErrUnauthorized {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Return not found",
+				Message: "The user has no return with the given ID",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch return",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+
+	if ret.Status != models.ReturnStatusApproved && ret.Status != models.ReturnStatusCompleted {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Cannot refund return",
+			Message: "Return can only be refunded once approved",
+		})
+		return nil, false
+	}
+	if ret.Refund != nil && ret.Refund.Status == models.RefundStatusSucceeded {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Cannot refund return",
+			Message: "Return has already been refunded",
+		})
+		return nil, false
+	}
+	return ret, true
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/currency"
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// AcceptCurrencyHeader names the currency a client wants prices shown in
+// when the request has no currency query parameter
+const AcceptCurrencyHeader = "Accept-Currency"
+
+// displayCurrency returns the currency the caller wants prices shown in,
+// from the currency query parameter or Accept-Currency, or "" for each
+// product's own. It responds with 400 and returns false if the code is
+// malformed.
+func displayCurrency(c *gin.Context) (string, bool) {
+	c.Writer.Header().Add("Vary", AcceptCurrencyHeader)
+	code := c.Query("currency")
+	if code == "" {
+		code = c.GetHeader(AcceptCurrencyHeader)
+	}
+	if code == "" {
+		return "", true
+	}
+	normalized := currency.Normalize(code)
+	if normalized == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid currency",
+			Message: "currency must be a three-letter ISO 4217 code",
+		})
+		return "", false
+	}
+	return normalized, true
+}
+
+// settlementCurrency returns the currency a product is sold in: its
+// seller's, or the payment currency for products listed without one
+func settlementCurrency(product *models.Product, cfg *config.Config) string {
+	if product.Currency != "" {
+		return product.Currency
+	}
+	return cfg.PaymentCurrency
+}
+
+// orderCurrency returns the currency an order is settled in, which is the
+// payment currency for orders placed before sellers had their own
+func orderCurrency(order *models.Order, cfg *config.Config) string {
+	if order.Currency != "" {
+		return order.Currency
+	}
+	return cfg.PaymentCurrency
+}
+
+// displayPrices shows products' prices in the currency the caller asked
+// for, keeping the price orders are charged in as the settlement price. It
+// responds with an error and returns false if a price can't be converted.
+func displayPrices(c *gin.Context, converter *currency.Converter, cfg *config.Config, products ...*models.Product) bool {
+	display, ok := displayCurrency(c)
+	if !ok {
+		return false
+	}
+	for _, product := range products {
+		own := settlementCurrency(product, cfg)
+		product.Currency = own
+		if display == "" || display == own {
+			continue
+		}
+		price, err := converter.Convert(c.Request.Context(), product.Price, own, display)
+		if err != nil {
+			respondConversionError(c, err)
+			return false
+		}
+		product.SettlementPrice, product.SettlementCurrency = product.Price, own
+		product.Price, product.Currency = price, display
+	}
+	return true
+}
+
+// respondConversionError responds to a failed currency conversion: 400 for
+// currencies without exchange rates, or 502 if the rates can't be fetched
+func respondConversionError(c *gin.Context, err error) {
+	if errors.Is(err, currency.ErrUnsupported) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Unsupported currency",
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusBadGateway, models.ErrorResponse{
+		Error:   "Failed to convert prices",
+		Message: err.Error(),
+	})
+}
@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/calendar"
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// CycleCountHandler handles cycle counts and inventory adjustments
+type CycleCountHandler struct {
+	grpcClients *grpcclient.Clients
+	calendar    *calendar.Calendar
+	config      *config.Config
+}
+
+// NewCycleCountHandler creates a new cycle count handler
+func NewCycleCountHandler(clients *grpcclient.Clients, holidays *calendar.Calendar, cfg *config.Config) *CycleCountHandler {
+	return &CycleCountHandler{
+		grpcClients: clients,
+		calendar:    holidays,
+		config:      cfg,
+	}
+}
+
+// ScheduleCycleCount schedules a cycle count for products at a warehouse
+// POST /api/v1/admin/inventory/cycle-counts
+func (h *CycleCountHandler) ScheduleCycleCount(c *gin.Context) {
+	var req models.ScheduleCycleCountRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	// Nobody is on the warehouse floor to count on its region's holidays
+	if region := h.config.WarehouseRegions[req.WarehouseID]; region != "" {
+		if holiday := h.calendar.HolidayAt(region, req.ScheduledFor.Time); holiday != nil {
+			c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+				Error:   "Cycle count falls on a holiday",
+				Message: holiday.Date + " is " + holiday.Name + " in " + holiday.Region,
+			})
+			return
+		}
+	}
+
+	lines := make([]models.CycleCountLine, 0, len(req.ProductIDs))
+	seen := make(map[string]bool, len(req.ProductIDs))
+	for _, productID := range req.ProductIDs {
+		if seen[productID] {
+			continue
+		}
+		seen[productID] = true
+		lines = append(lines, models.CycleCountLine{ProductID: productID})
+	}
+
+	// Call inventory service via gRPC
+	count, err := h.grpcClients.CreateCycleCount(c.Request.Context(), &models.CycleCount{
+		WarehouseID:  req.WarehouseID,
+		Status:       models.CycleCountStatusScheduled,
+		ScheduledFor: req.ScheduledFor,
+		Lines:        lines,
+		CreatedBy:    userID,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to schedule cycle count",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, count)
+}
+
+// GetCycleCount returns a single cycle count by ID
+// GET /api/v1/admin/inventory/cycle-counts/:id
+func (h *CycleCountHandler) GetCycleCount(c *gin.Context) {
+	count, ok := h.fetchCycleCount(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, count)
+}
+
+// SubmitCounts records counted quantities and computes variances against
+// the system quantities at the time of submission
+// POST /api/v1/admin/inventory/cycle-counts/:id/counts
+func (h *CycleCountHandler) SubmitCounts(c *gin.Context) {
+	var req models.SubmitCycleCountRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	count, ok := h.fetchCycleCount(c)
+	if !ok {
+		return
+	}
+
+	if count.Status == models.CycleCountStatusAdjusted {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Cannot submit counts",
+			Message: "Adjustments have already been applied for this cycle count",
+		})
+		return
+	}
+
+	counted := make(map[string]int32, len(req.Counts))
+	for _, entry := range req.Counts {
+		counted[entry.ProductID] = *entry.Quantity
+	}
+
+	for i := range count.Lines {
+		line := &count.Lines[i]
+		quantity, ok := counted[line.ProductID]
+		if !ok {
+			continue
+		}
+		delete(counted, line.ProductID)
+
+		systemQuantity, err := h.grpcClients.GetWarehouseStock(c.Request.Context(), count.WarehouseID, line.ProductID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Failed to fetch system quantity",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		line.SystemQuantity = systemQuantity
+		line.CountedQuantity = &quantity
+		line.Variance = quantity - systemQuantity
+	}
+
+	for productID := range counted {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid count",
+			Message: "Product " + productID + " is not part of this cycle count",
+		})
+		return
+	}
+
+	now := time.Now()
+	count.Status = models.CycleCountStatusCounted
+	count.CountedBy = userID
+	count.CountedAt = models.TimestampPtr(now)
+
+	// Call inventory service via gRPC
+	updated, err := h.grpcClients.UpdateCycleCount(c.Request.Context(), count)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to submit counts",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// ApplyAdjustments applies the variances of a counted cycle count to
+// on-hand stock, recording each adjustment with its reason code. Each line
+// is marked applied as it goes, and stock changes are keyed by count and
+// product, so retrying after a failure applies only the remaining lines.
+// POST /api/v1/admin/inventory/cycle-counts/:id/adjustments
+func (h *CycleCountHandler) ApplyAdjustments(c *gin.Context) {
+	var req models.ApplyCycleCountRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	count, ok := h.fetchCycleCount(c)
+	if !ok {
+		return
+	}
+
+	if count.Status != models.CycleCountStatusCounted {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Cannot apply adjustments",
+			Message: "Adjustments can only be applied to a counted cycle count",
+		})
+		return
+	}
+
+	adjustments := make([]*models.InventoryAdjustment, 0, len(count.Lines))
+	for i := range count.Lines {
+		line := &count.Lines[i]
+		if line.CountedQuantity == nil || line.Variance == 0 || line.AdjustmentID != "" {
+			continue
+		}
+
+		key := "cycle-count:" + count.ID + ":" + line.ProductID
+		err := h.grpcClients.AdjustWarehouseStock(c.Request.Context(), count.WarehouseID, line.ProductID, line.Variance, req.ReasonCode, key)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Failed to adjust stock",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		adjustment, err := h.grpcClients.RecordInventoryAdjustment(c.Request.Context(), &models.InventoryAdjustment{
+			WarehouseID:      count.WarehouseID,
+			ProductID:        line.ProductID,
+			PreviousQuantity: line.SystemQuantity,
+			NewQuantity:      *line.CountedQuantity,
+			Delta:            line.Variance,
+			ReasonCode:       req.ReasonCode,
+			Notes:            req.Notes,
+			CycleCountID:     count.ID,
+			AdjustedBy:       userID,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Failed to record adjustment",
+				Message: err.Error(),
+			})
+			return
+		}
+		adjustments = append(adjustments, adjustment)
+
+		// Mark the line applied before moving on, so a retry skips it
+		line.AdjustmentID = adjustment.ID
+		if _, err := h.grpcClients.UpdateCycleCount(c.Request.Context(), count); err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Failed to update cycle count",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	now := time.Now()
+	count.Status = models.CycleCountStatusAdjusted
+	count.AdjustedBy = userID
+	count.AdjustedAt = models.TimestampPtr(now)
+
+	// Call inventory service via gRPC
+	updated, err := h.grpcClients.UpdateCycleCount(c.Request.Context(), count)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to update cycle count",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"cycle_count": updated,
+		"adjustments": adjustments,
+	})
+}
+
+// ListAdjustments returns the inventory adjustment audit trail
+// GET /api/v1/admin/inventory/adjustments
+func (h *CycleCountHandler) ListAdjustments(c *gin.Context) {
+	// Parse query parameters
+	page, limit := pageParams(c)
+	warehouseID := c.Query("warehouse_id")
+	productID := c.Query("product_id")
+
+	// Call inventory service via gRPC
+	adjustments, total, err := h.grpcClients.ListInventoryAdjustments(c.Request.Context(), warehouseID, productID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch adjustments",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       adjustments,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// fetchCycleCount loads the cycle count named by the :id path parameter,
+// responding with an error if it cannot be fetched
+func (h *CycleCountHandler) fetchCycleCount(c *gin.Context) (*models.CycleCount, bool) {
+	count, err := h.grpcClients.GetCycleCount(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Cycle count not found",
+				Message: "No cycle count exists with the given ID",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch cycle count",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	return count, true
+}
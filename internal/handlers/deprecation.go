@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/deprecation"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/middleware"
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// DeprecationHandler handles deprecation report requests
+type DeprecationHandler struct {
+	registry *deprecation.Registry
+	store    deprecation.Store
+}
+
+// NewDeprecationHandler creates a new deprecation handler
+func NewDeprecationHandler(registry *deprecation.Registry, store deprecation.Store) *DeprecationHandler {
+	return &DeprecationHandler{
+		registry: registry,
+		store:    store,
+	}
+}
+
+// GetReport lists deprecated routes and fields with their sunset dates. Calls
+// with an API key, a partner signature, or a client credentials token also
+// show how often that integration still uses each one, soonest sunset first.
+// GET /api/v1/deprecations
+func (h *DeprecationHandler) GetReport(c *gin.Context) {
+	consumer := middleware.DeprecationConsumer(c)
+	usage := map[string]deprecation.Usage{}
+	if consumer != "" {
+		var err error
+		if usage, err = h.store.Usage(c.Request.Context(), consumer); err != nil {
+			logging.FromContext(c.Request.Context()).Warn("Failed to load deprecated surface usage", "consumer", consumer, "error", err)
+		}
+	}
+
+	var surfaces []*models.DeprecatedSurface
+	add := func(surface string, notice deprecation.Notice) *models.DeprecatedSurface {
+		entry := &models.DeprecatedSurface{
+			Surface:   surface,
+			Since:     models.NewTimestamp(notice.Since),
+			Successor: notice.Successor,
+		}
+		if !notice.Sunset.IsZero() {
+			entry.Sunset = models.TimestampPtr(notice.Sunset)
+		}
+		if u, ok := usage[surface]; ok {
+			entry.Calls = u.Count
+			if !u.LastSeen.IsZero() {
+				entry.LastCallAt = models.TimestampPtr(u.LastSeen)
+			}
+		}
+		surfaces = append(surfaces, entry)
+		return entry
+	}
+
+	for route, notice := range h.registry.Routes {
+		entry := add(route, notice)
+		entry.Kind = "route"
+		entry.Route = route
+	}
+	for route, fields := range h.registry.Fields {
+		for _, field := range fields {
+			entry := add(field.Surface(route), field.Notice)
+			entry.Kind = "field"
+			entry.Route = route
+			entry.Field = field.Name
+			entry.In = field.In
+		}
+	}
+
+	sort.Slice(surfaces, func(i, j int) bool {
+		a, b := sunsetOrMax(surfaces[i]), sunsetOrMax(surfaces[j])
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return surfaces[i].Surface < surfaces[j].Surface
+	})
+	if surfaces == nil {
+		surfaces = []*models.DeprecatedSurface{}
+	}
+
+	c.JSON(http.StatusOK, models.DeprecationReport{
+		Consumer:     consumer,
+		Deprecations: surfaces,
+	})
+}
+
+// sunsetOrMax returns a surface's sunset, placing unscheduled ones last
+func sunsetOrMax(s *models.DeprecatedSurface) time.Time {
+	if s.Sunset == nil {
+		return time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	}
+	return s.Sunset.Time
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/errorcodes"
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// ErrorCodeHandler serves documentation for the error codes in error responses
+type ErrorCodeHandler struct{}
+
+// NewErrorCodeHandler creates a new error code handler
+func NewErrorCodeHandler() *ErrorCodeHandler {
+	return &ErrorCodeHandler{}
+}
+
+// ListErrorCodes returns the documentation for every error code
+// GET /api/v1/admin/errors
+func (h *ErrorCodeHandler) ListErrorCodes(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"codes": errorcodes.All(),
+	})
+}
+
+// GetErrorCode returns what an error code means and how to resolve it
+// GET /api/v1/admin/errors/:code
+func (h *ErrorCodeHandler) GetErrorCode(c *gin.Context) {
+	doc, ok := errorcodes.Lookup(c.Param("code"))
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Error code not found",
+			Message: "No error code exists with the given name",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, doc)
+}
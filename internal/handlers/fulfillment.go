@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/cache"
+	"github.com/ecommerce/be-api-gin/internal/document"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// FulfillmentHandler generates the documents warehouse staff fulfil orders
+// with
+type FulfillmentHandler struct {
+	grpcClients *grpcclient.Clients
+}
+
+// NewFulfillmentHandler creates a new fulfillment handler
+func NewFulfillmentHandler(clients *grpcclient.Clients) *FulfillmentHandler {
+	return &FulfillmentHandler{
+		grpcClients: clients,
+	}
+}
+
+// PackingSlip renders an order's packing slip as a PDF, or with
+// ?format=csv its items as a CSV
+// GET /api/v1/admin/orders/:id/packing-slip
+func (h *FulfillmentHandler) PackingSlip(c *gin.Context) {
+	format, ok := documentFormat(c)
+	if !ok {
+		return
+	}
+
+	order, ok := h.fetchOrder(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	if order.Status == models.OrderStatusCancelled {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Order cancelled",
+			Message: "Cancelled orders are not packed",
+		})
+		return
+	}
+
+	addr := order.ShippingAddr
+	notes := []string{"Order: " + order.ID}
+	if !order.CreatedAt.IsZero() {
+		notes = append(notes, "Placed: "+order.CreatedAt.UTC().Format("2006-01-02"))
+	}
+	notes = append(notes, "", "Ship to:", addr.Street, strings.TrimSpace(addr.City+", "+addr.State+" "+addr.PostalCode), addr.Country)
+	if order.SignatureRequired {
+		notes = append(notes, "", "Signature required on delivery")
+	}
+
+	doc := &document.Document{
+		Title:   "Packing Slip",
+		Notes:   notes,
+		Columns: []string{"Product ID", "Product", "Quantity"},
+	}
+	for _, item := range order.Items {
+		doc.Rows = append(doc.Rows, []string{item.ProductID, item.ProductName, strconv.Itoa(int(item.Quantity))})
+	}
+
+	renderDocument(c, doc, format, "packing-slip-"+order.ID)
+}
+
+// pickLine is the quantity of a product to pick for a batch of orders
+type pickLine struct {
+	productID string
+	name      string
+	bin       string
+	quantity  int32
+	orders    []string // order IDs with the quantity of each
+}
+
+// PickList renders the items of a batch of orders as a pick list for a
+// warehouse, one line per product grouped by the bin it is picked from, as
+// a PDF or with ?format=csv a CSV. Only confirmed, processing, and partially
+// shipped orders can be picked, and only their items still to ship;
+// backordered items are left until they are back in stock.
+// POST /api/v1/admin/pick-lists
+func (h *FulfillmentHandler) PickList(c *gin.Context) {
+	var req models.PickListRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	format, ok := documentFormat(c)
+	if !ok {
+		return
+	}
+
+	lines := make(map[string]*pickLine)
+	for _, orderID := range req.OrderIDs {
+		order, ok := h.fetchOrder(c, orderID)
+		if !ok {
+			return
+		}
+		if order.Status != models.OrderStatusConfirmed && order.Status != models.OrderStatusProcessing && order.Status != models.OrderStatusPartiallyShipped {
+			c.JSON(http.StatusConflict, models.ErrorResponse{
+				Error:   "Order not ready to pick",
+				Message: "Order " + order.ID + " is " + string(order.Status) + "; only confirmed, processing, and partially shipped orders are picked",
+			})
+			return
+		}
+		for _, item := range order.Items {
+			if item.Status != "" && item.Status != models.OrderItemStatusPending {
+				continue
+			}
+			line, ok := lines[item.ProductID]
+			if !ok {
+				line = &pickLine{productID: item.ProductID, name: item.ProductName}
+				lines[item.ProductID] = line
+			}
+			line.quantity += item.Quantity
+			line.orders = append(line.orders, order.ID+" x"+strconv.Itoa(int(item.Quantity)))
+		}
+	}
+
+	productIDs := make([]string, 0, len(lines))
+	for productID := range lines {
+		productIDs = append(productIDs, productID)
+	}
+	sort.Strings(productIDs)
+	locations, err := h.grpcClients.GetPickLocations(c.Request.Context(), req.WarehouseID, productIDs)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, models.ErrorResponse{
+			Error:   "Failed to fetch pick locations",
+			Message: err.Error(),
+		})
+		return
+	}
+	for _, location := range locations {
+		if line, ok := lines[location.ProductID]; ok {
+			line.bin = location.Bin
+		}
+	}
+
+	sorted := make([]*pickLine, 0, len(lines))
+	for _, line := range lines {
+		sorted = append(sorted, line)
+	}
+	// Products without a bin at the warehouse go last
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].bin != sorted[j].bin {
+			return sorted[j].bin == "" || (sorted[i].bin != "" && sorted[i].bin < sorted[j].bin)
+		}
+		return sorted[i].productID < sorted[j].productID
+	})
+
+	now := time.Now().UTC()
+	doc := &document.Document{
+		Title: "Pick List",
+		Notes: []string{
+			"Warehouse: " + req.WarehouseID,
+			"Orders: " + strconv.Itoa(len(req.OrderIDs)),
+			"Generated: " + now.Format(time.RFC3339),
+		},
+		Columns: []string{"Bin", "Product ID", "Product", "Quantity", "Orders"},
+	}
+	for _, line := range sorted {
+		bin := line.bin
+		if bin == "" {
+			bin = "UNASSIGNED"
+		}
+		doc.Rows = append(doc.Rows, []string{bin, line.productID, line.name, strconv.Itoa(int(line.quantity)), strings.Join(line.orders, ", ")})
+	}
+
+	renderDocument(c, doc, format, "pick-list-"+req.WarehouseID+"-"+now.Format("20060102T150405Z"))
+}
+
+// fetchOrder loads any customer's order, responding with an error if it
+// cannot be fetched
+func (h *FulfillmentHandler) fetchOrder(c *gin.Context, orderID string) (*models.Order, bool) {
+	order, err := h.grpcClients.GetOrder(c.Request.Context(), orderID, "")
+	if err == grpcclient.ErrNotFound {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Order not found",
+			Message: "No order exists with ID " + orderID,
+		})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch order",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	return order, true
+}
+
+// documentFormat returns the document format named by the ?format= query
+// parameter, responding with 400 if it is unknown
+func documentFormat(c *gin.Context) (string, bool) {
+	format, err := document.ParseFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid format",
+			Message: "format must be " + document.FormatPDF + ", " + document.FormatCSV + ", or " + document.FormatHTML,
+		})
+		return "", false
+	}
+	return format, true
+}
+
+// renderDocument responds with doc in format as a download named after
+// name, less any characters unsafe in a file name
+func renderDocument(c *gin.Context, doc *document.Document, format, name string) {
+	response, err := documentResponse(doc, format, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to render document",
+			Message: err.Error(),
+		})
+		return
+	}
+	writeDocument(c, response)
+}
+
+// documentResponse renders doc in format as a download named after name
+func documentResponse(doc *document.Document, format, name string) (*cache.Response, error) {
+	var buf bytes.Buffer
+	if err := document.Render(&buf, doc, format); err != nil {
+		return nil, err
+	}
+
+	name = strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			return r
+		}
+		return '_'
+	}, name)
+	header := http.Header{}
+	header.Set("Content-Type", document.ContentType(format))
+	header.Set("Content-Disposition", `attachment; filename="`+name+"."+format+`"`)
+	return &cache.Response{Status: http.StatusOK, Header: header, Body: buf.Bytes()}, nil
+}
+
+// writeDocument responds with a rendered document
+func writeDocument(c *gin.Context, response *cache.Response) {
+	c.Header("Content-Disposition", response.Header.Get("Content-Disposition"))
+	c.Data(response.Status, response.Header.Get("Content-Type"), response.Body)
+}
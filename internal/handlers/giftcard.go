@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/middleware"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	"github.com/ecommerce/be-api-gin/internal/saga"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// GiftCardHandler handles gift card purchases and balance lookups
+type GiftCardHandler struct {
+	grpcClients *grpcclient.Clients
+	lookups     *GiftCardLookups
+	config      *config.Config
+}
+
+// NewGiftCardHandler creates a new gift card handler
+func NewGiftCardHandler(clients *grpcclient.Clients, lookups *GiftCardLookups, cfg *config.Config) *GiftCardHandler {
+	return &GiftCardHandler{
+		grpcClients: clients,
+		lookups:     lookups,
+		config:      cfg,
+	}
+}
+
+// GiftCardLookups limits how often gift card codes are looked up, per code
+// and per client IP, so codes can't be found by guessing them
+type GiftCardLookups struct {
+	limiter middleware.Limiter
+	perHour int
+}
+
+// NewGiftCardLookups creates a lookup limit of perHour lookups per code and
+// per client IP; 0 means no limit
+func NewGiftCardLookups(limiter middleware.Limiter, perHour int) *GiftCardLookups {
+	return &GiftCardLookups{limiter: limiter, perHour: perHour}
+}
+
+// allow reports whether code may be looked up for the request, responding
+// with 429 if not. Lookups are allowed if the limiter itself fails.
+func (l *GiftCardLookups) allow(c *gin.Context, code string) bool {
+	if l == nil || l.perHour <= 0 {
+		return true
+	}
+	digest := sha256.Sum256([]byte(strings.ToUpper(code)))
+	keys := []string{
+		"gift-card-lookups:code:" + hex.EncodeToString(digest[:]),
+		"gift-card-lookups:ip:" + c.ClientIP(),
+	}
+	for _, key := range keys {
+		allowed, retryAfter, err := l.limiter.Allow(c.Request.Context(), key, float64(l.perHour)/3600, l.perHour)
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Warn("Gift card lookup limiter failed, allowing lookup", "error", err)
+			continue
+		}
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, models.ErrorResponse{
+				Error:   "Too many requests",
+				Message: "Too many gift card lookups, please retry later",
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// PurchaseGiftCard buys a gift card and charges it to the user's payment
+// method as a saga, so a declined charge voids the card. Once paid for, the
+// card's code is emailed to the recipient.
+// POST /api/v1/gift-cards
+func (h *GiftCardHandler) PurchaseGiftCard(c *gin.Context) {
+	var req models.PurchaseGiftCardRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+	if req.Amount < h.config.GiftCardMinAmount || req.Amount > h.config.GiftCardMaxAmount {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid amount",
+			Message: "Gift cards can be bought for " + strconv.FormatFloat(h.config.GiftCardMinAmount, 'f', 2, 64) + " to " + strconv.FormatFloat(h.config.GiftCardMaxAmount, 'f', 2, 64),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	paymentMethodID := req.PaymentMethodID
+	if req.SavedPaymentMethodID != "" {
+		paymentMethodID, ok = savedPaymentMethod(c, h.grpcClients, req.SavedPaymentMethodID, userID)
+		if !ok {
+			return
+		}
+	}
+
+	var card *models.GiftCard
+	var payment *models.PaymentIntent
+	captured := false
+	purchase := saga.New("purchase-gift-card")
+	purchase.Add(saga.Step{
+		Name: "create-gift-card",
+		Action: func(ctx context.Context) (err error) {
+			card, err = h.grpcClients.CreateGiftCard(ctx, &models.GiftCard{
+				InitialAmount:  req.Amount,
+				Currency:       h.config.PaymentCurrency,
+				PurchaserID:    userID,
+				RecipientEmail: req.RecipientEmail,
+				RecipientName:  req.RecipientName,
+				Message:        req.Message,
+			})
+			return err
+		},
+		Compensate: func(ctx context.Context) error {
+			return h.grpcClients.CancelGiftCard(ctx, card.ID)
+		},
+	})
+	purchase.Add(saga.Step{
+		Name: "create-payment",
+		Action: func(ctx context.Context) (err error) {
+			payment, err = h.grpcClients.CreatePaymentIntent(ctx, userID, card.ID, req.Amount, h.config.PaymentCurrency)
+			return err
+		},
+		Compensate: func(ctx context.Context) error {
+			if captured {
+				return nil
+			}
+			return h.grpcClients.CancelPayment(ctx, payment.ID, userID)
+		},
+	})
+	// A charge taken for a card that then can't be activated is refunded
+	// rather than cancelled
+	purchase.Add(saga.Step{
+		Name: "capture-payment",
+		Action: func(ctx context.Context) error {
+			confirmed, err := h.grpcClients.ConfirmPayment(ctx, payment.ID, userID, paymentMethodID)
+			if err != nil {
+				return err
+			}
+			payment = confirmed
+			captured = true
+			return nil
+		},
+		Compensate: func(ctx context.Context) error {
+			_, err := h.grpcClients.RefundPayment(ctx, payment, req.Amount, "")
+			return err
+		},
+	})
+	purchase.Add(saga.Step{
+		Name: "activate-gift-card",
+		Action: func(ctx context.Context) error {
+			activated, err := h.grpcClients.ActivateGiftCard(ctx, card.ID)
+			if err != nil {
+				return err
+			}
+			card.Status = activated.Status
+			card.ExpiresAt = activated.ExpiresAt
+			return nil
+		},
+	})
+
+	if err := purchase.Run(c.Request.Context()); err != nil {
+		title := "Failed to purchase gift card"
+		var stepErr *saga.StepError
+		if errors.As(err, &stepErr) {
+			switch stepErr.Step {
+			case "create-payment":
+				title = "Failed to create payment"
+			case "capture-payment":
+				respondPaymentError(c, stepErr.Err)
+				return
+			case "activate-gift-card":
+				title = "Failed to activate gift card"
+			}
+			err = stepErr.Err
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   title,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, card)
+}
+
+// GetBalance returns what is left on the gift card with the given code. The
+// code is sent in the body rather than the URL so it stays out of logs.
+// Lookups are limited per code and per client IP, and unknown codes and
+// cards that can't be used get the same 404, so codes can't be probed.
+// POST /api/v1/gift-cards/balance
+func (h *GiftCardHandler) GetBalance(c *gin.Context) {
+	var req models.GiftCardBalanceRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if !h.lookups.allow(c, req.Code) {
+		return
+	}
+
+	card, err := h.grpcClients.GetGiftCard(c.Request.Context(), req.Code)
+	if err != nil && err != grpcclient.ErrNotFound {
+		c.JSON(http.StatusBadGateway, models.ErrorResponse{
+			Error:   "Failed to fetch gift card",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil || !giftCardUsable(card) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Gift card not found",
+			Message: "No usable gift card exists with the given code",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.GiftCardBalance{
+		Last4:     card.Last4,
+		Balance:   card.Balance,
+		Currency:  card.Currency,
+		Status:    card.Status,
+		ExpiresAt: card.ExpiresAt,
+	})
+}
+
+// redeemableGiftCards fetches the gift cards with the given codes,
+// responding and returning false if any can't be looked up, or with
+// respondGiftCardUnusable whether it is unknown, not active, expired, or
+// empty
+func redeemableGiftCards(c *gin.Context, clients *grpcclient.Clients, lookups *GiftCardLookups, codes []string) ([]*models.GiftCard, bool) {
+	cards := make([]*models.GiftCard, 0, len(codes))
+	for _, code := range codes {
+		if !lookups.allow(c, code) {
+			return nil, false
+		}
+		card, err := clients.GetGiftCard(c.Request.Context(), code)
+		if err != nil && err != grpcclient.ErrNotFound {
+			c.JSON(http.StatusBadGateway, models.ErrorResponse{
+				Error:   "Failed to fetch gift card",
+				Message: err.Error(),
+			})
+			return nil, false
+		}
+		if err != nil || !giftCardUsable(card) {
+			respondGiftCardUnusable(c, code)
+			return nil, false
+		}
+		cards = append(cards, card)
+	}
+	return cards, true
+}
+
+// respondGiftCardUnusable responds with the 422 given for every gift card an
+// order can't use, so checkout doesn't reveal which codes exist
+func respondGiftCardUnusable(c *gin.Context, code string) {
+	c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+		Error:   "Gift card not usable",
+		Message: "Gift card ending " + codeSuffix(code) + " can't be used for this order",
+	})
+}
+
+// giftCardUsable reports whether a gift card is active, unexpired, and has
+// a balance left
+func giftCardUsable(card *models.GiftCard) bool {
+	return card.Status == models.GiftCardStatusActive &&
+		(card.ExpiresAt == nil || time.Now().Before(card.ExpiresAt.Time)) &&
+		card.Balance > 0
+}
+
+// codeSuffix returns the last four characters of a gift card code, which are
+// safe to echo back
+func codeSuffix(code string) string {
+	if len(code) <= 4 {
+		return code
+	}
+	return code[len(code)-4:]
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/cart"
+	"github.com/ecommerce/be-api-gin/internal/guest"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// GuestHandler verifies the email addresses of guests checking out without
+// an account
+type GuestHandler struct {
+	grpcClients *grpcclient.Clients
+	verifier    *guest.Verifier
+}
+
+// NewGuestHandler creates a new guest handler
+func NewGuestHandler(clients *grpcclient.Clients, verifier *guest.Verifier) *GuestHandler {
+	return &GuestHandler{
+		grpcClients: clients,
+		verifier:    verifier,
+	}
+}
+
+// RequestVerification emails a code verifying the guest's address, valid
+// only in the guest's cart session
+// POST /api/v1/guest/verification
+func (h *GuestHandler) RequestVerification(c *gin.Context) {
+	var req models.GuestVerificationRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	sessionID, ok := requireCartSession(c)
+	if !ok {
+		return
+	}
+
+	code, err := h.verifier.Start(c.Request.Context(), sessionID, req.Email)
+	if respondGuestLocked(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to start verification",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err := h.grpcClients.SendVerificationEmail(c.Request.Context(), req.Email, code); err != nil {
+		c.JSON(http.StatusBadGateway, models.ErrorResponse{
+			Error:   "Failed to send verification email",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Verification code sent",
+	})
+}
+
+// ConfirmVerification checks the code sent to a guest and issues the guest
+// token they check out with
+// POST /api/v1/guest/verification/confirm
+func (h *GuestHandler) ConfirmVerification(c *gin.Context) {
+	var req models.GuestConfirmRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	sessionID, ok := requireCartSession(c)
+	if !ok {
+		return
+	}
+
+	session, err := h.verifier.Confirm(c.Request.Context(), sessionID, req.Email, req.Code)
+	if respondGuestLocked(c, err) {
+		return
+	}
+	switch err {
+	case nil:
+	case guest.ErrNoChallenge, guest.ErrEmailMismatch:
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Verification not found",
+			Message: "No verification code is pending for this email in this cart session; request a new one",
+		})
+		return
+	case guest.ErrCodeMismatch:
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Error:   "Invalid verification code",
+			Message: err.Error(),
+		})
+		return
+	case guest.ErrTooManyAttempts:
+		c.JSON(http.StatusTooManyRequests, models.ErrorResponse{
+			Error:   "Too many attempts",
+			Message: "The verification code was entered wrong too many times; request a new one",
+		})
+		return
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to verify email",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.GuestSession{
+		Token:     session.Token,
+		Email:     session.Email,
+		ExpiresAt: models.NewTimestamp(session.ExpiresAt),
+	})
+}
+
+// respondGuestLocked responds 429 with Retry-After if err is a lockout of
+// the email or cart session, returning whether it did
+func respondGuestLocked(c *gin.Context, err error) bool {
+	var locked *guest.LockedError
+	if !errors.As(err, &locked) {
+		return false
+	}
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+	message := "Too many incorrect codes were entered for this email or cart session; try again later"
+	if errors.Is(err, guest.ErrTooManyCodes) {
+		message = "Too many verification codes were requested for this email or cart session; try again later"
+	}
+	c.JSON(http.StatusTooManyRequests, models.ErrorResponse{
+		Error:   "Too many attempts",
+		Message: message,
+	})
+	return true
+}
+
+// requireCartSession returns the guest's cart session ID from the
+// X-Cart-Session header, or responds 400 if it is missing or malformed
+func requireCartSession(c *gin.Context) (string, bool) {
+	sessionID := c.GetHeader(cart.SessionHeader)
+	if !cart.ValidSessionID(sessionID) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid cart session",
+			Message: "The " + cart.SessionHeader + " header must carry a cart session ID issued by this API",
+		})
+		return "", false
+	}
+	return sessionID, true
+}
+
+// requireGuestSession returns the verified guest session named by the
+// X-Guest-Token header in the caller's cart session, or responds 401 if
+// there is none
+func requireGuestSession(c *gin.Context, verifier *guest.Verifier) (*guest.Session, bool) {
+	sessionID, ok := requireCartSession(c)
+	if !ok {
+		return nil, false
+	}
+
+	session, err := verifier.Session(c.Request.Context(), c.GetHeader(guest.TokenHeader), sessionID)
+	if err == guest.ErrSessionNotActive {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "Guest verification required",
+			Message: "The " + guest.TokenHeader + " header must carry a guest token issued for this cart session",
+		})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to check guest token",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	return session, true
+}
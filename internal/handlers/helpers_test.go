@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// testConfig loads the default configuration
+func testConfig() *config.Config {
+	return config.Load()
+}
+
+// newTestClients connects clients to a local gRPC server, so handlers run
+// against the clients' stubbed backend calls
+func newTestClients(t *testing.T, cfg *config.Config) *grpcclient.Clients {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := grpc.NewServer()
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	addr := lis.Addr().String()
+	cfg.UserServiceAddr = addr
+	cfg.ListingServiceAddr = addr
+	cfg.InventoryServiceAddr = addr
+	cfg.PaymentServiceAddr = addr
+
+	clients, err := grpcclient.NewClients(cfg)
+	if err != nil {
+		t.Fatalf("NewClients() error = %v", err)
+	}
+	t.Cleanup(clients.Close)
+	return clients
+}
+
+// asUser signs requests in as userID
+func asUser(userID string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Next()
+	}
+}
+
+// serve sends a request to router, with body encoded as JSON unless it is
+// already bytes
+func serve(router http.Handler, method, path string, body any, headers map[string]string) *httptest.ResponseRecorder {
+	var data []byte
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		data = b
+	default:
+		data, _ = json.Marshal(b)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// decodeError decodes an error response
+func decodeError(t *testing.T, w *httptest.ResponseRecorder) models.ErrorResponse {
+	t.Helper()
+	var resp models.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding error response %q: %v", w.Body.String(), err)
+	}
+	return resp
+}
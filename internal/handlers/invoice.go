@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/cache"
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/document"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/middleware"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	"github.com/ecommerce/be-api-gin/internal/tax"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// InvoiceHandler renders invoices for orders
+type InvoiceHandler struct {
+	grpcClients *grpcclient.Clients
+	local       *cache.LRU[*cache.Response]
+	store       cache.Store
+	ttl         time.Duration
+	config      *config.Config
+}
+
+// NewInvoiceHandler creates a new invoice handler. Rendered invoices are
+// kept in memory and, when store is not nil, in store for every replica.
+func NewInvoiceHandler(clients *grpcclient.Clients, store cache.Store, cfg *config.Config) *InvoiceHandler {
+	ttl := time.Duration(cfg.InvoiceCacheTTLHours) * time.Hour
+	return &InvoiceHandler{
+		grpcClients: clients,
+		local:       cache.NewLRU[*cache.Response](cfg.InvoiceLRUSize, ttl),
+		store:       store,
+		ttl:         ttl,
+		config:      cfg,
+	}
+}
+
+// GetInvoice renders an order's invoice as a PDF, or with ?format=html as
+// an HTML page. Only the order's owner and those allowed to read every
+// order may view it. Invoices are issued once the order is paid, and are
+// rendered once and then served from cache.
+// GET /api/v1/orders/:id/invoice
+func (h *InvoiceHandler) GetInvoice(c *gin.Context) {
+	format, err := document.ParseFormat(c.Query("format"))
+	if err != nil || format == document.FormatCSV {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid format",
+			Message: "format must be " + document.FormatPDF + " or " + document.FormatHTML,
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	// Staff read any order; everyone else only their own
+	owner := userID
+	if h.config.Permissions.Allows(middleware.GetRoles(c), config.PermOrdersRead) {
+		owner = ""
+	}
+
+	// Call order service via gRPC
+	ctx := c.Request.Context()
+	order, err := h.grpcClients.GetOrder(ctx, c.Param("id"), owner)
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Order not found",
+				Message: "No order exists with the given ID",
+			})
+			return
+		}
+		if err == grpcclient.ErrUnauthorized {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "Unauthorized",
+				Message: "You don't have permission to view this order",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch order",
+			Message: err.Error(),
+		})
+		return
+	}
+	if order.Status == models.OrderStatusPending {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Invoice not available",
+			Message: "Invoices are issued once the order is paid",
+		})
+		return
+	}
+
+	key := order.ID + "." + format
+	if cached := h.cached(ctx, key); cached != nil {
+		c.Header("X-Cache", "HIT")
+		writeDocument(c, cached)
+		return
+	}
+
+	doc, err := h.invoice(ctx, order)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to generate invoice",
+			Message: err.Error(),
+		})
+		return
+	}
+	response, err := documentResponse(doc, format, "invoice-"+order.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to render document",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.local.Add(key, response)
+	if h.store != nil {
+		if err := h.store.Set(ctx, key, response, h.ttl); err != nil {
+			logging.FromContext(ctx).Warn("Failed to cache invoice", "order_id", order.ID, "error", err)
+		}
+	}
+	c.Header("X-Cache", "MISS")
+	writeDocument(c, response)
+}
+
+// cached returns the invoice rendered under key, from memory or else the
+// shared store, or nil if it hasn't been rendered
+func (h *InvoiceHandler) cached(ctx context.Context, key string) *cache.Response {
+	if response, ok := h.local.Get(key); ok {
+		return response
+	}
+	if h.store == nil {
+		return nil
+	}
+	response, err := h.store.Get(ctx, key)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Invoice cache read failed", "error", err)
+		return nil
+	}
+	if response != nil {
+		h.local.Add(key, response)
+	}
+	return response
+}
+
+// invoice lays out an order's invoice: who sold and who bought each item,
+// the items with their share of any discount and their tax, and the totals
+func (h *InvoiceHandler) invoice(ctx context.Context, order *models.Order) (*document.Document, error) {
+	currency := orderCurrency(order, h.config)
+	money := func(amount float64) string {
+		return fmt.Sprintf("%.2f %s", amount, currency)
+	}
+
+	// Each item is sold by its product's seller
+	sellerIDs := make([]string, len(order.Items))
+	var sellers []*models.SellerProfile
+	seen := make(map[string]*models.SellerProfile)
+	for i, item := range order.Items {
+		product, err := h.grpcClients.GetProduct(ctx, item.ProductID)
+		if err == grpcclient.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sellerIDs[i] = product.SellerID
+		if _, ok := seen[product.SellerID]; ok || product.SellerID == "" {
+			continue
+		}
+		profile, err := h.grpcClients.GetSellerProfile(ctx, product.SellerID)
+		if err != nil && err != grpcclient.ErrNotFound {
+			return nil, err
+		}
+		seen[product.SellerID] = profile
+		if profile != nil {
+			sellers = append(sellers, profile)
+		}
+	}
+
+	addr := order.ShippingAddr
+	notes := []string{
+		"Invoice: INV-" + order.ID,
+		"Invoice date: " + time.Now().UTC().Format("2006-01-02"),
+		"Order: " + order.ID,
+	}
+	if !order.CreatedAt.IsZero() {
+		notes = append(notes, "Order date: "+order.CreatedAt.UTC().Format("2006-01-02"))
+	}
+	notes = append(notes, "", "Bill to:", addr.Street, strings.TrimSpace(addr.City+", "+addr.State+" "+addr.PostalCode), addr.Country)
+	for _, seller := range sellers {
+		a := seller.Address
+		notes = append(notes, "", "Sold by: "+seller.Name, a.Street, strings.TrimSpace(a.City+", "+a.State+" "+a.PostalCode), a.Country)
+		if seller.TaxID != "" {
+			notes = append(notes, "Tax ID: "+seller.TaxID)
+		}
+	}
+
+	doc := &document.Document{
+		Title:   "Invoice",
+		Notes:   notes,
+		Columns: []string{"Product", "Sold by", "Quantity", "Unit price", "Discount", "Tax", "Amount"},
+	}
+
+	// Show each item's share of the order's discount, as it was taxed
+	amounts := make([]float64, len(order.Items))
+	var subtotal float64
+	for i, item := range order.Items {
+		amounts[i] = item.TotalPrice
+		subtotal += item.TotalPrice
+	}
+	net := tax.SpreadDiscount(amounts, order.Discount)
+	for i, item := range order.Items {
+		name := item.ProductName
+		if name == "" {
+			name = item.ProductID
+		}
+		soldBy := ""
+		if seller := seen[sellerIDs[i]]; seller != nil {
+			soldBy = seller.Name
+		}
+		doc.Rows = append(doc.Rows, []string{
+			name,
+			soldBy,
+			strconv.Itoa(int(item.Quantity)),
+			money(item.UnitPrice),
+			money(item.TotalPrice - net[i]),
+			money(item.Tax),
+			money(net[i] + item.Tax),
+		})
+	}
+
+	doc.Summary = append(doc.Summary, "Subtotal: "+money(subtotal))
+	if order.Discount > 0 {
+		discount := "Discount: -" + money(order.Discount)
+		if order.CouponCode != "" {
+			discount += " (" + order.CouponCode + ")"
+		}
+		doc.Summary = append(doc.Summary, discount)
+	}
+	if order.ShippingMethod != "" || order.ShippingAmount > 0 {
+		shipping := "Shipping: " + money(order.ShippingAmount)
+		if order.ShippingMethod != "" {
+			shipping += " (" + order.ShippingMethod + ")"
+		}
+		doc.Summary = append(doc.Summary, shipping)
+	}
+	if order.TaxAmount > 0 {
+		doc.Summary = append(doc.Summary, "Tax: "+money(order.TaxAmount))
+	}
+	doc.Summary = append(doc.Summary, "Total: "+money(order.TotalAmount))
+	if order.GiftCardApplied > 0 {
+		doc.Summary = append(doc.Summary, "Paid by gift card: "+money(order.GiftCardApplied))
+	}
+	if order.StoreCreditApplied > 0 {
+		doc.Summary = append(doc.Summary, "Paid by store credit: "+money(order.StoreCreditApplied))
+	}
+	return doc, nil
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/middleware"
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// IPRuleHandler manages the admin allowlist and global denylist
+type IPRuleHandler struct {
+	filter *middleware.IPFilter
+}
+
+// NewIPRuleHandler creates a new IP rule handler
+func NewIPRuleHandler(filter *middleware.IPFilter) *IPRuleHandler {
+	return &IPRuleHandler{
+		filter: filter,
+	}
+}
+
+// ListIPRules returns the active IP rules, optionally filtered by list
+// GET /api/v1/admin/ip-rules
+func (h *IPRuleHandler) ListIPRules(c *gin.Context) {
+	list := c.Query("list")
+	if list != "" && list != models.IPListAllow && list != models.IPListDeny {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid list",
+			Message: "list must be allow or deny",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"rules": h.filter.Rules(list),
+	})
+}
+
+// CreateIPRule adds an address or range to a list, optionally expiring
+// POST /api/v1/admin/ip-rules
+func (h *IPRuleHandler) CreateIPRule(c *gin.Context) {
+	var req models.CreateIPRuleRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.filter.Add(c.Request.Context(), req.List, req.CIDR, req.Reason, userID, time.Duration(req.TTLSeconds)*time.Second)
+	if err != nil {
+		if err == middleware.ErrInvalidCIDR {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid CIDR",
+				Message: "cidr must be an IP address or CIDR range",
+			})
+			return
+		}
+		if err == middleware.ErrAllowlistDisabled {
+			c.JSON(http.StatusConflict, models.ErrorResponse{
+				Error:   "Admin allowlist not enabled",
+				Message: "Allow rules can only be added when ADMIN_IP_ALLOWLIST is set",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to create IP rule",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// DeleteIPRule removes a rule added at runtime
+// DELETE /api/v1/admin/ip-rules/:id
+func (h *IPRuleHandler) DeleteIPRule(c *gin.Context) {
+	if err := h.filter.Remove(c.Request.Context(), c.Param("id")); err != nil {
+		if err == middleware.ErrIPRuleNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "IP rule not found",
+				Message: "No active IP rule exists with the given ID",
+			})
+			return
+		}
+		if err == middleware.ErrStaticIPRule {
+			c.JSON(http.StatusConflict, models.ErrorResponse{
+				Error:   "Static IP rule",
+				Message: "Rules set by configuration can only be changed in configuration",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to delete IP rule",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "IP rule deleted successfully",
+	})
+}
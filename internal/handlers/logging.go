@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// LoggingHandler handles runtime changes to logging
+type LoggingHandler struct{}
+
+// NewLoggingHandler creates a new logging handler
+func NewLoggingHandler() *LoggingHandler {
+	return &LoggingHandler{}
+}
+
+// GetLogLevel returns this instance's current log level
+// GET /api/v1/admin/loglevel
+func (h *LoggingHandler) GetLogLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, logLevelResponse())
+}
+
+// SetLogLevel changes this instance's log level without a restart,
+// optionally reverting after a duration
+// PUT /api/v1/admin/loglevel
+func (h *LoggingHandler) SetLogLevel(c *gin.Context) {
+	var req models.SetLogLevelRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	logging.SetLevel(req.Level, time.Duration(req.DurationSeconds)*time.Second)
+
+	c.JSON(http.StatusOK, logLevelResponse())
+}
+
+// logLevelResponse describes the current log level and when it reverts
+func logLevelResponse() models.LogLevelResponse {
+	level, revertsAt := logging.Level()
+	resp := models.LogLevelResponse{Level: level}
+	if revertsAt != nil {
+		resp.RevertsAt = models.TimestampPtr(*revertsAt)
+	}
+	return resp
+}
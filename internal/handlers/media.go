@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/jobs"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	"github.com/ecommerce/be-api-gin/internal/moderation"
+	"github.com/ecommerce/be-api-gin/internal/scanning"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// allowedImageTypes lists the image formats accepted for upload
+var allowedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// MediaHandler handles media uploads
+type MediaHandler struct {
+	grpcClients *grpcclient.Clients
+	config      *config.Config
+	moderation  *moderation.Pipeline
+	scanner     scanning.Scanner
+	jobRunner   *jobs.Runner
+}
+
+// NewMediaHandler creates a new media handler. scanner may be nil, in which
+// case uploads are not scanned for malware.
+func NewMediaHandler(clients *grpcclient.Clients, cfg *config.Config, pipeline *moderation.Pipeline, scanner scanning.Scanner, jobRunner *jobs.Runner) *MediaHandler {
+	return &MediaHandler{
+		grpcClients: clients,
+		config:      cfg,
+		moderation:  pipeline,
+		scanner:     scanner,
+		jobRunner:   jobRunner,
+	}
+}
+
+// UploadMedia uploads a product image. Images are screened before they
+// become publicly visible; flagged images are quarantined for review and the
+// seller is notified. When malware scanning is enabled, images also stay
+// private until a background scan finds them clean.
+// POST /api/v1/media/uploads
+func (h *MediaHandler) UploadMedia(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.config.MaxUploadSize+1<<20)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid upload",
+			Message: "A multipart form with a \"file\" field is required",
+		})
+		return
+	}
+	if fileHeader.Size > h.config.MaxUploadSize {
+		c.JSON(http.StatusRequestEntityTooLarge, models.ErrorResponse{
+			Error:   "File too large",
+			Message: "The uploaded file exceeds the maximum upload size",
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid upload",
+			Message: err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, h.config.MaxUploadSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid upload",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Detect the type from the content rather than trusting the client
+	contentType := http.DetectContentType(data)
+	if !allowedImageTypes[contentType] {
+		c.JSON(http.StatusUnsupportedMediaType, models.ErrorResponse{
+			Error:   "Unsupported media type",
+			Message: "Only JPEG, PNG, GIF, and WebP images are accepted",
+		})
+		return
+	}
+
+	// Screen the image before it can become public
+	decision := h.moderation.ScreenImage(c.Request.Context(), data, contentType)
+	if decision.Verdict == moderation.VerdictRejected {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Content rejected",
+			"message": "The image contains prohibited content",
+			"reasons": decision.Reasons,
+		})
+		return
+	}
+
+	var scanStatus string
+	if h.scanner != nil {
+		scanStatus = models.ScanStatusPending
+	}
+
+	// Call listing service via gRPC
+	media, err := h.grpcClients.StoreMedia(c.Request.Context(), &models.Media{
+		OwnerID:          userID,
+		ProductID:        c.PostForm("product_id"),
+		Filename:         fileHeader.Filename,
+		ContentType:      contentType,
+		Size:             int64(len(data)),
+		ModerationStatus: decision.Verdict,
+		ScanStatus:       scanStatus,
+	}, data)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to store media",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Quarantine flagged images and let the seller know
+	if decision.Verdict == moderation.VerdictQuarantined {
+		enqueueModeration(c.Request.Context(), h.grpcClients, models.ContentTypeImage, media.ID, userID, decision)
+		notifyUser(c.Request.Context(), h.grpcClients, userID, &models.Notification{
+			Type:    "media_quarantined",
+			Title:   "Image held for review",
+			Message: "Your image " + media.Filename + " was flagged by automated moderation and will not be visible until reviewed",
+			Data: map[string]string{
+				"media_id": media.ID,
+			},
+		})
+	}
+
+	if h.scanner != nil {
+		h.startScan(c.Request.Context(), media, data)
+	}
+
+	c.JSON(http.StatusCreated, media)
+}
+
+// startScan scans stored media for malware in the background. Infected
+// media is quarantined and its uploader notified; media whose scan fails
+// stays private.
+func (h *MediaHandler) startScan(ctx context.Context, media *models.Media, data []byte) {
+	params := map[string]string{"media_id": media.ID}
+	_, err := h.jobRunner.Start(ctx, models.JobTypeVirusScan, media.OwnerID, params, func(ctx context.Context, progress *jobs.Progress) error {
+		log := logging.FromContext(ctx).With("media_id", media.ID)
+
+		result, err := h.scanner.Scan(ctx, data)
+		if err != nil {
+			if err := h.grpcClients.SetMediaScanStatus(ctx, media.ID, models.ScanStatusFailed, ""); err != nil {
+				log.Warn("Failed to record media scan status", "error", err)
+			}
+			return err
+		}
+
+		if !result.Infected {
+			return h.grpcClients.SetMediaScanStatus(ctx, media.ID, models.ScanStatusClean, "")
+		}
+
+		log.Warn("Malware found in uploaded media", "threat", result.Threat, "owner_id", media.OwnerID)
+		if err := h.grpcClients.SetMediaScanStatus(ctx, media.ID, models.ScanStatusInfected, result.Threat); err != nil {
+			return err
+		}
+		notifyUser(ctx, h.grpcClients, media.OwnerID, &models.Notification{
+			Type:    "media_infected",
+			Title:   "Image quarantined",
+			Message: "Your image " + media.Filename + " was found to contain malware and has been quarantined",
+			Data: map[string]string{
+				"media_id": media.ID,
+			},
+		})
+		return nil
+	})
+	if err != nil {
+		// The media stays private until it can be scanned
+		logging.FromContext(ctx).Error("Failed to start media scan", "media_id", media.ID, "error", err)
+	}
+}
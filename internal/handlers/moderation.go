@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/cache"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	"github.com/ecommerce/be-api-gin/internal/moderation"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// ModerationHandler handles admin review of flagged catalog content
+type ModerationHandler struct {
+	grpcClients *grpcclient.Clients
+	products    *cache.ProductCache
+}
+
+// NewModerationHandler creates a new moderation handler
+func NewModerationHandler(clients *grpcclient.Clients, products *cache.ProductCache) *ModerationHandler {
+	return &ModerationHandler{
+		grpcClients: clients,
+		products:    products,
+	}
+}
+
+// ListDuplicateFlags returns the duplicate review queue
+// GET /api/v1/admin/products/duplicates
+func (h *ModerationHandler) ListDuplicateFlags(c *gin.Context) {
+	// Parse query parameters
+	page, limit := pageParams(c)
+	status := c.DefaultQuery("status", models.DuplicateFlagPending)
+
+	// Call listing service via gRPC
+	flags, total, err := h.grpcClients.ListDuplicateFlags(c.Request.Context(), status, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch duplicate flags",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       flags,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// ResolveDuplicateFlag dismisses a flagged duplicate or removes the listing
+// POST /api/v1/admin/products/duplicates/:id/resolve
+func (h *ModerationHandler) ResolveDuplicateFlag(c *gin.Context) {
+	var req models.ResolveDuplicateFlagRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	flag, err := h.grpcClients.GetDuplicateFlag(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Duplicate flag not found",
+				Message: "No duplicate flag exists with the given ID",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch duplicate flag",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if flag.Status != models.DuplicateFlagPending {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Duplicate flag already resolved",
+			Message: "This duplicate flag has already been resolved",
+		})
+		return
+	}
+
+	// Remove the duplicate listing
+	if req.Resolution == models.DuplicateFlagRemoved {
+		err := h.grpcClients.DeleteProduct(c.Request.Context(), flag.ProductID, flag.SellerID)
+		if err != nil && err != grpcclient.ErrNotFound {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Failed to remove product",
+				Message: err.Error(),
+			})
+			return
+		}
+		h.products.Invalidate(c.Request.Context(), flag.ProductID)
+	}
+
+	now := time.Now()
+	flag.Status = req.Resolution
+	flag.ResolvedBy = userID
+	flag.ResolvedAt = models.TimestampPtr(now)
+	flag.Notes = req.Notes
+
+	// Call listing service via gRPC
+	updated, err := h.grpcClients.UpdateDuplicateFlag(c.Request.Context(), flag)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to resolve duplicate flag",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// ListModerationQueue returns quarantined content awaiting review
+// GET /api/v1/admin/moderation/queue
+func (h *ModerationHandler) ListModerationQueue(c *gin.Context) {
+	// Parse query parameters
+	page, limit := pageParams(c)
+	status := c.DefaultQuery("status", models.ModerationItemPending)
+	contentType := c.Query("content_type")
+
+	// Call listing service via gRPC
+	items, total, err := h.grpcClients.ListModerationItems(c.Request.Context(), status, contentType, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch moderation queue",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       items,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// ResolveModerationItem approves or rejects quarantined content
+// POST /api/v1/admin/moderation/queue/:id/resolve
+func (h *ModerationHandler) ResolveModerationItem(c *gin.Context) {
+	var req models.ResolveModerationItemRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.grpcClients.GetModerationItem(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Moderation item not found",
+				Message: "No moderation item exists with the given ID",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch moderation item",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if item.Status != models.ModerationItemPending {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Moderation item already resolved",
+			Message: "This moderation item has already been resolved",
+		})
+		return
+	}
+
+	// Apply the decision to the content
+	if err := setModerationStatus(c.Request.Context(), h.grpcClients, h.products, item.ContentType, item.ContentID, req.Decision); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to update content status",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	now := time.Now()
+	item.Status = req.Decision
+	item.ReviewedBy = userID
+	item.ReviewedAt = models.TimestampPtr(now)
+	item.Notes = req.Notes
+
+	// Call listing service via gRPC
+	updated, err := h.grpcClients.UpdateModerationItem(c.Request.Context(), item)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to resolve moderation item",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Let the author know the outcome
+	if item.AuthorID != "" {
+		notifyUser(c.Request.Context(), h.grpcClients, item.AuthorID, &models.Notification{
+			Type:    "moderation_resolved",
+			Title:   "Moderation review complete",
+			Message: "Your " + item.ContentType + " was " + req.Decision + " after review",
+			Data: map[string]string{
+				"content_type": item.ContentType,
+				"content_id":   item.ContentID,
+				"decision":     req.Decision,
+			},
+		})
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// notifyUser sends a notification, logging rather than failing on error
+func notifyUser(ctx context.Context, clients *grpcclient.Clients, userID string, notification *models.Notification) {
+	if err := clients.NotifyUser(ctx, userID, notification); err != nil {
+		logging.FromContext(ctx).Warn("Failed to notify user", "notify_user_id", userID, "error", err)
+	}
+}
+
+// enqueueModeration queues quarantined content for admin review. Failures
+// are logged; the content stays quarantined either way.
+func enqueueModeration(ctx context.Context, clients *grpcclient.Clients, contentType, contentID, authorID string, decision moderation.Decision) {
+	_, err := clients.CreateModerationItem(ctx, &models.ModerationItem{
+		ContentType: contentType,
+		ContentID:   contentID,
+		AuthorID:    authorID,
+		Reasons:     decision.Reasons,
+		Status:      models.ModerationItemPending,
+	})
+	if err != nil {
+		logging.FromContext(ctx).Warn("Failed to queue content for moderation", "content_type", contentType, "content_id", contentID, "error", err)
+	}
+}
+
+// setModerationStatus applies a moderation status to content of any type,
+// purging a product's cached copies once its status changes
+func setModerationStatus(ctx context.Context, clients *grpcclient.Clients, products *cache.ProductCache, contentType, contentID, status string) error {
+	switch contentType {
+	case models.ContentTypeProduct:
+		if err := clients.SetProductModerationStatus(ctx, contentID, status); err != nil {
+			return err
+		}
+		products.Invalidate(ctx, contentID)
+		return nil
+	case models.ContentTypeReview:
+		return clients.SetReviewModerationStatus(ctx, contentID, status)
+	case models.ContentTypeReviewResponse:
+		return clients.SetReviewResponseModerationStatus(ctx, contentID, status)
+	case models.ContentTypeQuestion:
+		return clients.SetQuestionModerationStatus(ctx, contentID, status)
+	case models.ContentTypeAnswer:
+		return clients.SetAnswerModerationStatus(ctx, contentID, status)
+	case models.ContentTypeImage:
+		return clients.SetMediaModerationStatus(ctx, contentID, status)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/errorcodes"
+	"github.com/ecommerce/be-api-gin/internal/middleware"
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// OAuthHandler issues tokens to machine clients
+type OAuthHandler struct {
+	config *config.Config
+}
+
+// NewOAuthHandler creates a new OAuth handler
+func NewOAuthHandler(cfg *config.Config) *OAuthHandler {
+	return &OAuthHandler{
+		config: cfg,
+	}
+}
+
+// Token issues an access token with the OAuth2 client credentials grant.
+// Clients authenticate with HTTP Basic auth or the client_id and
+// client_secret form fields.
+// POST /api/v1/oauth/token
+func (h *OAuthHandler) Token(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	if h.config.OAuthTokenSecret == "" {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Not found",
+			Message: "Client credentials tokens are not enabled",
+		})
+		return
+	}
+
+	if c.PostForm("grant_type") != "client_credentials" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Unsupported grant type",
+			Message: "Only the client_credentials grant is supported",
+			Code:    errorcodes.UnsupportedGrantType,
+		})
+		return
+	}
+
+	clientID, secret, ok := basicClientAuth(c)
+	if !ok {
+		clientID, secret = c.PostForm("client_id"), c.PostForm("client_secret")
+	}
+	client, exists := h.config.OAuthClients[clientID]
+	if !exists || !client.VerifySecret(secret) {
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "Invalid client",
+			Message: "Client authentication failed",
+			Code:    errorcodes.InvalidClient,
+		})
+		return
+	}
+
+	scopes, ok := grantedScopes(client, c.PostForm("scope"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid scope",
+			Message: "The requested scope is not granted to this client",
+			Code:    errorcodes.InvalidScope,
+		})
+		return
+	}
+
+	token, ttl, err := middleware.IssueClientToken(h.config, client, scopes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to issue token",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ClientCredentialsToken{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(ttl.Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	})
+}
+
+// basicClientAuth reads form-encoded client credentials from HTTP Basic auth
+func basicClientAuth(c *gin.Context) (string, string, bool) {
+	username, password, ok := c.Request.BasicAuth()
+	if !ok {
+		return "", "", false
+	}
+	clientID, err := url.QueryUnescape(username)
+	if err != nil {
+		return "", "", false
+	}
+	secret, err := url.QueryUnescape(password)
+	if err != nil {
+		return "", "", false
+	}
+	return clientID, secret, true
+}
+
+// grantedScopes returns the requested scopes if the client holds all of
+// them, or every scope the client holds when none are requested
+func grantedScopes(client *config.OAuthClient, requested string) ([]string, bool) {
+	scopes := strings.Fields(requested)
+	if len(scopes) == 0 {
+		return client.Scopes, true
+	}
+	held := make(map[string]bool, len(client.Scopes))
+	for _, scope := range client.Scopes {
+		held[scope] = true
+	}
+	for _, scope := range scopes {
+		if !held[scope] {
+			return nil, false
+		}
+	}
+	return scopes, true
+}
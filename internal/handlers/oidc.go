@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	"github.com/ecommerce/be-api-gin/internal/oidc"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// oidcStateCookie holds the signed login state between login and callback
+const oidcStateCookie = "oidc_state"
+
+// OIDCHandler handles OpenID Connect login requests
+type OIDCHandler struct {
+	grpcClients *grpcclient.Clients
+	oidc        *oidc.Manager
+	config      *config.Config
+}
+
+// NewOIDCHandler creates a new OIDC handler
+func NewOIDCHandler(clients *grpcclient.Clients, manager *oidc.Manager, cfg *config.Config) *OIDCHandler {
+	return &OIDCHandler{
+		grpcClients: clients,
+		oidc:        manager,
+		config:      cfg,
+	}
+}
+
+// Login redirects the user to the provider's authorization page
+// GET /api/v1/auth/oidc/:provider/login
+func (h *OIDCHandler) Login(c *gin.Context) {
+	provider := c.Param("provider")
+
+	authURL, state, err := h.oidc.AuthURL(c.Request.Context(), provider)
+	if err != nil {
+		if err == oidc.ErrUnknownProvider {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Unknown provider",
+				Message: "No login provider is configured with the given name",
+			})
+			return
+		}
+		c.JSON(http.StatusBadGateway, models.ErrorResponse{
+			Error:   "Failed to start login",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.setStateCookie(c, state, 600)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback completes the login and exchanges the provider identity for a
+// gateway session via the user service
+// GET /api/v1/auth/oidc/:provider/callback
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	provider := c.Param("provider")
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "Login failed",
+			Message: providerErr + ": " + c.Query("error_description"),
+		})
+		return
+	}
+
+	code := c.Query("code")
+	state := c.Query("state")
+	stateCookie, err := c.Cookie(oidcStateCookie)
+	if code == "" || state == "" || err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid callback",
+			Message: "The login callback is missing its code or state",
+		})
+		return
+	}
+
+	// The state is single-use
+	h.setStateCookie(c, "", -1)
+
+	identity, err := h.oidc.Exchange(c.Request.Context(), provider, code, state, stateCookie)
+	if err != nil {
+		switch err {
+		case oidc.ErrUnknownProvider:
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Unknown provider",
+				Message: "No login provider is configured with the given name",
+			})
+		case oidc.ErrInvalidState, oidc.ErrInvalidIDToken:
+			c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+				Error:   "Login failed",
+				Message: err.Error(),
+			})
+		default:
+			c.JSON(http.StatusBadGateway, models.ErrorResponse{
+				Error:   "Login failed",
+				Message: err.Error(),
+			})
+		}
+		return
+	}
+
+	// Call user service via gRPC
+	tokens, err := h.grpcClients.LoginWithOIDC(c.Request.Context(), identity)
+	if err != nil {
+		if err == grpcclient.ErrUnauthorized {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "Login failed",
+				Message: "This account is not permitted to sign in",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to create session",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// setStateCookie writes the login state cookie. SameSite=Lax lets the
+// cookie accompany the top-level redirect back from the provider.
+func (h *OIDCHandler) setStateCookie(c *gin.Context, value string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, value, maxAge, "/", "", h.config.Environment == "production", true)
+}
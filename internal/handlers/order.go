@@ -1,24 +1,78 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"math"
 	"net/http"
-	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/ecommerce/be-api-gin/internal/cart"
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/coupon"
+	"github.com/ecommerce/be-api-gin/internal/currency"
+	"github.com/ecommerce/be-api-gin/internal/guest"
+	"github.com/ecommerce/be-api-gin/internal/holds"
+	"github.com/ecommerce/be-api-gin/internal/logging"
 	"github.com/ecommerce/be-api-gin/internal/models"
+	"github.com/ecommerce/be-api-gin/internal/saga"
+	"github.com/ecommerce/be-api-gin/internal/shipping"
+	"github.com/ecommerce/be-api-gin/internal/suborder"
+	"github.com/ecommerce/be-api-gin/internal/tax"
+	"github.com/ecommerce/be-api-gin/internal/verification"
 	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
 )
 
+// storeCreditUse is how an order placement uses the customer's store credit
+type storeCreditUse int
+
+const (
+	// creditNone leaves store credit untouched
+	creditNone storeCreditUse = iota
+	// creditIfUsable applies credit when the rest of the order is charged
+	// at once or nothing is left to charge, and skips it otherwise
+	creditIfUsable
+	// creditRequired applies credit, failing the order if the rest can't be
+	// charged at once
+	creditRequired
+)
+
+// errBalanceShort fails an order whose gift cards and store credit fall
+// short of its total when there is no payment method to charge the rest to
+var errBalanceShort = errors.New("balances do not cover the order total")
+
 // OrderHandler handles order-related requests
 type OrderHandler struct {
 	grpcClients *grpcclient.Clients
+	idVerifier  verification.IDVerifier
+	tax         tax.Calculator
+	shipping    *shipping.Quoter
+	currency    *currency.Converter
+	carts       cart.Store
+	guests      *guest.Verifier
+	lookups     *GiftCardLookups
+	config      *config.Config
 }
 
-// NewOrderHandler creates a new order handler
-func NewOrderHandler(clients *grpcclient.Clients) *OrderHandler {
+// NewOrderHandler creates a new order handler. idVerifier may be nil, in
+// which case age-restricted items are verified by date of birth only,
+// calculator may be nil, in which case no tax is charged, quoter may be
+// nil, in which case no shipping is charged, and converter may be nil, in
+// which case only sellers in the payment currency can be charged shipping
+// or fixed discounts.
+func NewOrderHandler(clients *grpcclient.Clients, idVerifier verification.IDVerifier, calculator tax.Calculator, quoter *shipping.Quoter, converter *currency.Converter, carts cart.Store, guests *guest.Verifier, lookups *GiftCardLookups, cfg *config.Config) *OrderHandler {
 	return &OrderHandler{
 		grpcClients: clients,
+		idVerifier:  idVerifier,
+		tax:         calculator,
+		shipping:    quoter,
+		currency:    converter,
+		carts:       carts,
+		guests:      guests,
+		lookups:     lookups,
+		config:      cfg,
 	}
 }
 
@@ -26,15 +80,28 @@ func NewOrderHandler(clients *grpcclient.Clients) *OrderHandler {
 // GET /api/v1/orders
 func (h *OrderHandler) ListOrders(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
-	userID, _ := c.Get("userID")
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
 
 	// Parse query parameters
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	status := c.Query("status")
+	page, limit := pageParams(c)
+	var status models.OrderStatus
+	if s := c.Query("status"); s != "" {
+		parsed, err := models.ParseOrderStatus(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid status",
+				Message: err.Error(),
+			})
+			return
+		}
+		status = parsed
+	}
 
 	// Call user service via gRPC to get orders
-	orders, total, err := h.grpcClients.ListOrders(c.Request.Context(), userID.(string), page, limit, status)
+	orders, total, err := h.grpcClients.ListOrders(c.Request.Context(), userID, page, limit, status)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "Failed to fetch orders",
@@ -56,10 +123,13 @@ func (h *OrderHandler) ListOrders(c *gin.Context) {
 // GET /api/v1/orders/:id
 func (h *OrderHandler) GetOrder(c *gin.Context) {
 	id := c.Param("id")
-	userID, _ := c.Get("userID")
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
 
 	// Call user service via gRPC
-	order, err := h.grpcClients.GetOrder(c.Request.Context(), id, userID.(string))
+	order, err := h.grpcClients.GetOrder(c.Request.Context(), id, userID)
 	if err != nil {
 		if err == grpcclient.ErrNotFound {
 			c.JSON(http.StatusNotFound, models.ErrorResponse{
@@ -89,7 +159,54 @@ func (h *OrderHandler) GetOrder(c *gin.Context) {
 // POST /api/v1/orders
 func (h *OrderHandler) CreateOrder(c *gin.Context) {
 	var req models.CreateOrderRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	order, ok := h.placeOrder(c, "create-order", userID, &req, "", creditNone, nil)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusCreated, order)
+}
+
+// Checkout places an order for the items in the user's cart and removes them
+// from it
+// POST /api/v1/checkout
+func (h *OrderHandler) Checkout(c *gin.Context) {
+	var req models.CheckoutRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	h.checkoutCart(c, cart.UserOwner(userID), userID, "", &req)
+}
+
+// GuestCheckout places an order for the items in a guest's cart and removes
+// them from it. The guest must have verified their email in the cart session.
+// POST /api/v1/guest/checkout
+func (h *OrderHandler) GuestCheckout(c *gin.Context) {
+	var req models.CheckoutRequest
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error:   "Invalid request body",
 			Message: err.Error(),
@@ -97,7 +214,302 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 		return
 	}
 
-	userID, _ := c.Get("userID")
+	if req.AddressID != "" || req.SavedPaymentMethodID != "" || (req.ApplyStoreCredit != nil && *req.ApplyStoreCredit) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Saved details unavailable",
+			Message: "Guests must give a shipping_address and payment_method_id; saved addresses, payment methods, and store credit need an account",
+		})
+		return
+	}
+
+	session, ok := requireGuestSession(c, h.guests)
+	if !ok {
+		return
+	}
+
+	h.checkoutCart(c, cart.GuestOwner(session.SessionID), guest.UserID(session.Email), session.Email, &req)
+}
+
+// checkoutCart places an order for the items in owner's cart on behalf of
+// userID, recording guestEmail for guest orders, and removes the ordered
+// items from the cart
+func (h *OrderHandler) checkoutCart(c *gin.Context, owner, userID, guestEmail string, req *models.CheckoutRequest) {
+	sc, err := h.carts.Get(c.Request.Context(), owner)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch cart",
+			Message: err.Error(),
+		})
+		return
+	}
+	if sc == nil || len(sc.Items) == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Cart is empty",
+			Message: "Add items to the cart before checking out",
+		})
+		return
+	}
+
+	order := &models.CreateOrderRequest{
+		ShippingAddr:    req.ShippingAddr,
+		AddressID:       req.AddressID,
+		AgeVerification: req.AgeVerification,
+		CouponCode:      req.CouponCode,
+		ShippingMethod:  req.ShippingMethod,
+		GuestEmail:      guestEmail,
+	}
+	if order.CouponCode == "" && sc.Coupon != nil {
+		order.CouponCode = sc.Coupon.Code
+	}
+	for _, item := range sc.Items {
+		order.Items = append(order.Items, models.CreateOrderItem{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	paymentMethodID := req.PaymentMethodID
+	if req.SavedPaymentMethodID != "" {
+		var ok bool
+		paymentMethodID, ok = savedPaymentMethod(c, h.grpcClients, req.SavedPaymentMethodID, userID)
+		if !ok {
+			return
+		}
+	}
+
+	credit := creditNone
+	if req.ApplyStoreCredit != nil && *req.ApplyStoreCredit {
+		credit = creditRequired
+	} else if req.ApplyStoreCredit == nil && guestEmail == "" && h.config.StoreCreditAutoApply {
+		credit = creditIfUsable
+	}
+
+	giftCards, ok := redeemableGiftCards(c, h.grpcClients, h.lookups, req.GiftCardCodes)
+	if !ok {
+		return
+	}
+
+	placed, ok := h.placeOrder(c, "checkout", userID, order, paymentMethodID, credit, giftCards)
+	if !ok {
+		return
+	}
+
+	// Only what was ordered leaves the cart, so items added during checkout
+	// stay. The order stands even if the cart cannot be updated.
+	ttl := time.Duration(h.config.CartTTLDays) * 24 * time.Hour
+	if guestEmail != "" {
+		ttl = time.Duration(h.config.CartGuestTTLHours) * time.Hour
+	}
+	_, err = h.carts.Update(c.Request.Context(), owner, ttl, func(sc *models.Cart) error {
+		cart.RemoveOrdered(sc, order.Items, order.CouponCode)
+		return nil
+	})
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Warn("Failed to remove ordered items from cart after checkout", "order_id", placed.ID, "error", err)
+	}
+
+	c.JSON(http.StatusCreated, placed)
+}
+
+// GetGuestOrder returns an order the guest placed with their verified email
+// GET /api/v1/guest/orders/:id
+func (h *OrderHandler) GetGuestOrder(c *gin.Context) {
+	session, ok := requireGuestSession(c, h.guests)
+	if !ok {
+		return
+	}
+
+	order, err := h.grpcClients.GetOrder(c.Request.Context(), c.Param("id"), guest.UserID(session.Email))
+	if err != nil {
+		if err == grpcclient.ErrNotFound || err == grpcclient.ErrUnauthorized {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Order not found",
+				Message: "No order exists with the given ID",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch order",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// ClaimGuestOrders attaches the orders placed as a guest to the signed-in
+// user's account. The caller proves they own the guest email with the guest
+// token and cart session they checked out with, which are then ended.
+// POST /api/v1/orders/claim
+func (h *OrderHandler) ClaimGuestOrders(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	session, ok := requireGuestSession(c, h.guests)
+	if !ok {
+		return
+	}
+
+	orders, err := h.grpcClients.ClaimGuestOrders(c.Request.Context(), session.Email, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to claim orders",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// The orders are claimed even if the guest session cannot be ended; it
+	// expires on its own
+	if err := h.guests.End(c.Request.Context(), session); err != nil {
+		logging.FromContext(c.Request.Context()).Warn("Failed to end guest session after claim", "error", err)
+	}
+	logging.FromContext(c.Request.Context()).Info("Guest orders claimed",
+		"event", "guest_orders_claimed",
+		"user_id", userID,
+		"orders", len(orders),
+	)
+
+	c.JSON(http.StatusOK, models.ClaimOrdersResponse{
+		Claimed: len(orders),
+		Orders:  orders,
+	})
+}
+
+// placeOrder checks an order's restrictions, stock, and promo code, then
+// reserves its items, creates it, splits it into a sub-order per seller if
+// it has several, redeems the code, applies the gift cards
+// and store credit as credit says, and charges paymentMethodID for the rest
+// if one is given, as a saga, so a failure at any step releases what the
+// earlier steps took. It responds with an error and returns false if the
+// order cannot be placed.
+func (h *OrderHandler) placeOrder(c *gin.Context, name, userID string, req *models.CreateOrderRequest, paymentMethodID string, credit storeCreditUse, giftCards []*models.GiftCard) (*models.Order, bool) {
+	// Ship to the saved address the order names
+	if req.AddressID != "" {
+		saved, err := h.grpcClients.GetAddress(c.Request.Context(), req.AddressID, userID)
+		if err != nil {
+			if err == grpcclient.ErrNotFound || err == grpcclient.ErrUnauthorized {
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{
+					Error:   "Address not found",
+					Message: "Address " + req.AddressID + " is not one of your saved addresses",
+				})
+				return nil, false
+			}
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Failed to fetch address",
+				Message: err.Error(),
+			})
+			return nil, false
+		}
+		req.ShippingAddr = &saved.Address
+	}
+
+	// Collect restrictions, the currency, and the subtotal across all items
+	minimumAge := 0
+	signatureRequired := false
+	subtotal := 0.0
+	vacations := newSellerVacations(h.grpcClients)
+	lines := make([]tax.Line, len(req.Items))
+	shipItems := make([]shipping.Item, len(req.Items))
+	itemSellers := make([]string, len(req.Items))
+	for i, item := range req.Items {
+		product, err := h.grpcClients.GetProduct(c.Request.Context(), item.ProductID)
+		if err != nil {
+			if err == grpcclient.ErrNotFound {
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{
+					Error:   "Product not found",
+					Message: "Product " + item.ProductID + " does not exist",
+				})
+				return nil, false
+			}
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Failed to fetch product",
+				Message: err.Error(),
+			})
+			return nil, false
+		}
+		if until := vacations.Until(c.Request.Context(), product.SellerID); until != nil {
+			c.JSON(http.StatusConflict, models.ErrorResponse{
+				Error:   "Seller on vacation",
+				Message: "Product " + item.ProductID + " can't be ordered until " + until.String(),
+			})
+			return nil, false
+		}
+		// Orders are settled in their sellers' currency, so can't mix them
+		if own := settlementCurrency(product, h.config); i == 0 {
+			req.Currency = own
+		} else if own != req.Currency {
+			c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+				Error:   "Mixed currencies",
+				Message: "Product " + item.ProductID + " is sold in " + own + " and the others in " + req.Currency + "; order them separately",
+			})
+			return nil, false
+		}
+		itemSellers[i] = product.SellerID
+		subtotal += product.Price * float64(item.Quantity)
+		lines[i] = tax.Line{
+			ProductID: item.ProductID,
+			Category:  product.Category,
+			Quantity:  item.Quantity,
+			Amount:    math.Round(product.Price*float64(item.Quantity)*100) / 100,
+		}
+		shipItems[i] = shipping.Item{
+			ProductID: item.ProductID,
+			SellerID:  product.SellerID,
+			Quantity:  item.Quantity,
+			Amount:    lines[i].Amount,
+		}
+		if product.Restriction == nil {
+			continue
+		}
+		if product.Restriction.MinimumAge > minimumAge {
+			minimumAge = product.Restriction.MinimumAge
+		}
+		if product.Restriction.RequiresSignature {
+			signatureRequired = true
+		}
+	}
+
+	sellers := suborder.Sellers(itemSellers)
+	if len(sellers) == 1 {
+		req.SellerID = sellers[0]
+	}
+
+	// Verify age for restricted items
+	if minimumAge > 0 {
+		if req.AgeVerification == nil {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "Age verification required",
+				Message: "This order contains age-restricted items; please provide your date of birth",
+			})
+			return nil, false
+		}
+		err := verification.CheckAge(c.Request.Context(), h.idVerifier, req.AgeVerification.DateOfBirth, req.AgeVerification.IDToken, minimumAge)
+		switch err {
+		case nil:
+		case verification.ErrInvalidDateOfBirth:
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid date of birth",
+				Message: "Date of birth must be a past date in YYYY-MM-DD format",
+			})
+			return nil, false
+		case verification.ErrUnderage, verification.ErrIDNotVerified:
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "Age verification failed",
+				Message: err.Error(),
+			})
+			return nil, false
+		default:
+			c.JSON(http.StatusBadGateway, models.ErrorResponse{
+				Error:   "Failed to verify age",
+				Message: err.Error(),
+			})
+			return nil, false
+		}
+	}
 
 	// Validate inventory availability for all items
 	for _, item := range req.Items {
@@ -107,60 +519,462 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 				Error:   "Failed to check inventory",
 				Message: err.Error(),
 			})
-			return
+			return nil, false
 		}
 		if !available {
 			c.JSON(http.StatusBadRequest, models.ErrorResponse{
 				Error:   "Insufficient inventory",
 				Message: "Product " + item.ProductID + " does not have enough stock",
 			})
-			return
+			return nil, false
 		}
 	}
 
-	// Reserve inventory for all items
-	reservationIDs := make([]string, 0, len(req.Items))
-	for _, item := range req.Items {
-		reservationID, err := h.grpcClients.ReserveInventory(c.Request.Context(), item.ProductID, item.Quantity)
+	// Gift cards only pay for orders in their own currency
+	for _, card := range giftCards {
+		if card.Currency != req.Currency {
+			respondGiftCardUnusable(c, card.Code)
+			return nil, false
+		}
+	}
+
+	// Price the promo code against the items' current prices. Minimums and
+	// fixed amounts are in the payment currency.
+	if req.CouponCode != "" {
+		subtotal = math.Round(subtotal*100) / 100
+		minimumBasis, err := h.currency.Convert(c.Request.Context(), subtotal, req.Currency, h.config.PaymentCurrency)
 		if err != nil {
-			// Rollback previous reservations
-			for _, rid := range reservationIDs {
-				h.grpcClients.CancelReservation(c.Request.Context(), rid)
+			respondChargeConversionError(c, err)
+			return nil, false
+		}
+		found, ok := redeemableCoupon(c, h.grpcClients, req.CouponCode, userID, minimumBasis)
+		if !ok {
+			return nil, false
+		}
+		value := found.Value
+		if found.Type == models.CouponTypeFixedAmount {
+			if value, err = h.currency.Convert(c.Request.Context(), value, h.config.PaymentCurrency, req.Currency); err != nil {
+				respondChargeConversionError(c, err)
+				return nil, false
 			}
-			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-				Error:   "Failed to reserve inventory",
+		}
+		req.Discount = coupon.Discount(found.Type, value, subtotal)
+		req.FreeShipping = found.Type == models.CouponTypeFreeShipping
+	}
+
+	// Charge for the shipping method chosen, or the cheapest, as quoted now
+	// on the discounted amounts
+	if h.shipping != nil {
+		amounts := make([]float64, len(shipItems))
+		for i, item := range shipItems {
+			amounts[i] = item.Amount
+		}
+		for i, amount := range tax.SpreadDiscount(amounts, req.Discount) {
+			shipItems[i].Amount = amount
+		}
+		quoted, err := h.shipping.Quote(c.Request.Context(), *req.ShippingAddr, shipItems, time.Now())
+		if err != nil {
+			c.JSON(http.StatusBadGateway, models.ErrorResponse{
+				Error:   "Failed to quote shipping",
 				Message: err.Error(),
 			})
-			return
+			return nil, false
+		}
+		quote := shipping.Choose(quoted.Quotes, req.ShippingMethod)
+		if quote == nil {
+			message := "No shipping method delivers this order to the shipping address"
+			if req.ShippingMethod != "" {
+				message = "Shipping method " + req.ShippingMethod + " is not available for this order and address"
+			}
+			c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+				Error:   "Shipping method unavailable",
+				Message: message,
+			})
+			return nil, false
+		}
+		req.ShippingMethod = quote.Method
+		if !req.FreeShipping {
+			req.ShippingCost, err = h.currency.Convert(c.Request.Context(), quote.Price, quote.Currency, req.Currency)
+			if err != nil {
+				respondChargeConversionError(c, err)
+				return nil, false
+			}
 		}
-		reservationIDs = append(reservationIDs, reservationID)
+	} else if req.ShippingMethod != "" {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Error:   "Shipping method unavailable",
+			Message: "Shipping methods are not offered",
+		})
+		return nil, false
 	}
 
-	// Create the order
-	order, err := h.grpcClients.CreateOrder(c.Request.Context(), userID.(string), &req, reservationIDs)
-	if err != nil {
-		// Rollback reservations on failure
-		for _, rid := range reservationIDs {
-			h.grpcClients.CancelReservation(c.Request.Context(), rid)
+	// Tax each item on what is paid for it after the discount
+	if h.tax != nil {
+		amounts := make([]float64, len(lines))
+		for i, line := range lines {
+			amounts[i] = line.Amount
+		}
+		for i, amount := range tax.SpreadDiscount(amounts, req.Discount) {
+			lines[i].Amount = amount
+		}
+		taxed, err := h.tax.Calculate(c.Request.Context(), &tax.Request{
+			Currency: req.Currency,
+			Address:  *req.ShippingAddr,
+			Lines:    lines,
+		})
+		if err != nil {
+			c.JSON(http.StatusBadGateway, models.ErrorResponse{
+				Error:   "Failed to calculate tax",
+				Message: err.Error(),
+			})
+			return nil, false
+		}
+		req.ItemTaxes = taxed.Lines
+		req.Tax = taxed.Total
+	}
+
+	// Reserve each item, create the order, then pay for it; if a step fails,
+	// the order is cancelled and the reservations made before it released
+	var order *models.Order
+	reservationIDs := make([]string, len(req.Items))
+	placement := saga.New(name)
+	for i, item := range req.Items {
+		i, item := i, item
+		placement.Add(saga.Step{
+			Name: "reserve-inventory",
+			Action: func(ctx context.Context) (err error) {
+				reservationIDs[i], err = h.grpcClients.ReserveInventory(ctx, item.ProductID, item.Quantity)
+				return err
+			},
+			Compensate: func(ctx context.Context) error {
+				return h.grpcClients.CancelReservation(ctx, reservationIDs[i])
+			},
+		})
+	}
+	placement.Add(saga.Step{
+		Name: "create-order",
+		Action: func(ctx context.Context) (err error) {
+			order, err = h.grpcClients.CreateOrder(ctx, userID, req, reservationIDs, signatureRequired)
+			return err
+		},
+		Compensate: func(ctx context.Context) error {
+			return h.grpcClients.CancelOrder(ctx, order.ID, userID)
+		},
+	})
+	// Each seller gets a sub-order of their own items to fulfill, while the
+	// customer pays for the order as a whole
+	var subOrders []*models.Order
+	if len(sellers) > 1 {
+		placement.Add(saga.Step{
+			Name: "split-order",
+			Action: func(ctx context.Context) (err error) {
+				subOrders, err = h.grpcClients.CreateSubOrders(ctx, userID, order.ID, suborder.Split(order, itemSellers))
+				return err
+			},
+			Compensate: func(ctx context.Context) error {
+				var errs []error
+				for _, sub := range subOrders {
+					errs = append(errs, h.grpcClients.CancelOrder(ctx, sub.ID, userID))
+				}
+				return errors.Join(errs...)
+			},
+		})
+	}
+	// The promo code's usage limits are only enforced once redeemed, so a
+	// code used up since it was checked fails the order
+	if req.CouponCode != "" {
+		var redemptionID string
+		placement.Add(saga.Step{
+			Name: "redeem-coupon",
+			Action: func(ctx context.Context) (err error) {
+				redemptionID, err = h.grpcClients.RedeemCoupon(ctx, req.CouponCode, userID, order.ID)
+				return err
+			},
+			Compensate: func(ctx context.Context) error {
+				return h.grpcClients.ReleaseCouponRedemption(ctx, redemptionID)
+			},
+		})
+	}
+	// Gift cards, then store credit, pay what they can of the order and are
+	// held like any other tender; the payment charges only what they leave
+	giftCardHolds := make([]*models.GiftCardHold, len(giftCards))
+	var creditHold *models.StoreCreditHold
+	// refunded is set once the balances are returned whole, held or
+	// captured, so their holds aren't released again
+	refunded := false
+	refundBalances := func(ctx context.Context) error {
+		var errs []error
+		if len(giftCards) > 0 {
+			errs = append(errs, h.grpcClients.RefundOrderGiftCards(ctx, order.ID))
+		}
+		if creditHold != nil {
+			errs = append(errs, h.grpcClients.RefundOrderStoreCredit(ctx, order.ID))
+		}
+		err := errors.Join(errs...)
+		refunded = err == nil
+		return err
+	}
+	covered := func() float64 {
+		sum := 0.0
+		for _, hold := range giftCardHolds {
+			if hold != nil {
+				sum += hold.Amount
+			}
+		}
+		if creditHold != nil {
+			sum += creditHold.Amount
+		}
+		return math.Round(sum*100) / 100
+	}
+	amountDue := func() float64 {
+		return math.Round((order.TotalAmount-covered())*100) / 100
+	}
+	for i, card := range giftCards {
+		i, card := i, card
+		placement.Add(saga.Step{
+			Name: "hold-gift-card",
+			Action: func(ctx context.Context) (err error) {
+				amount := math.Min(card.Balance, amountDue())
+				if amount <= 0 {
+					return nil
+				}
+				giftCardHolds[i], err = h.grpcClients.HoldGiftCard(ctx, card.ID, order.ID, amount)
+				return err
+			},
+			Compensate: func(ctx context.Context) error {
+				if giftCardHolds[i] == nil || refunded {
+					return nil
+				}
+				return h.grpcClients.ReleaseGiftCard(ctx, giftCardHolds[i].ID)
+			},
+		})
+	}
+	if credit != creditNone {
+		placement.Add(saga.Step{
+			Name: "hold-store-credit",
+			Action: func(ctx context.Context) error {
+				remaining := amountDue()
+				if remaining <= 0 {
+					return nil
+				}
+				balance, err := h.grpcClients.GetStoreCredit(ctx, userID, req.Currency)
+				if err != nil {
+					return err
+				}
+				amount := math.Min(balance.Balance, remaining)
+				if amount <= 0 || (amount < remaining && paymentMethodID == "") {
+					return nil
+				}
+				creditHold, err = h.grpcClients.HoldStoreCredit(ctx, userID, order.ID, amount)
+				return err
+			},
+			Compensate: func(ctx context.Context) error {
+				if creditHold == nil || refunded {
+					return nil
+				}
+				return h.grpcClients.ReleaseStoreCredit(ctx, creditHold.ID)
+			},
+		})
+	}
+	// Balances the customer asked to pay with must cover the order unless
+	// the rest is charged
+	if len(giftCards) > 0 || credit == creditRequired {
+		placement.Add(saga.Step{
+			Name: "check-balances",
+			Action: func(ctx context.Context) error {
+				if paymentMethodID == "" && amountDue() > 0 {
+					return errBalanceShort
+				}
+				return nil
+			},
+		})
+	}
+	// Balances are captured before the card is charged, so a charge never
+	// goes through for an order whose balances turn out to be spent; if the
+	// charge fails, they are refunded
+	captureBalances := func(ctx context.Context) error {
+		for _, hold := range giftCardHolds {
+			if hold == nil {
+				continue
+			}
+			if err := h.grpcClients.CaptureGiftCard(ctx, hold.ID); err != nil {
+				return err
+			}
+		}
+		if creditHold != nil {
+			return h.grpcClients.CaptureStoreCredit(ctx, creditHold.ID)
+		}
+		return nil
+	}
+	if len(giftCards) > 0 || credit != creditNone {
+		placement.Add(saga.Step{
+			Name: "capture-balances",
+			Action: func(ctx context.Context) error {
+				if err := captureBalances(ctx); err != nil {
+					if refundErr := refundBalances(ctx); refundErr != nil {
+						logging.FromContext(ctx).Error("Failed to refund partly captured balances", "order_id", order.ID, "error", refundErr)
+					}
+					return err
+				}
+				if covered() == 0 || amountDue() > 0 {
+					return nil
+				}
+				// Paid in full with balances, so nothing will confirm the order
+				// and its sub-orders the way a payment would
+				if err := suborder.SetStatus(ctx, h.grpcClients, order.ID, userID, models.OrderStatusConfirmed); err != nil {
+					logging.FromContext(ctx).Warn("Failed to confirm sub-orders paid with balances", "order_id", order.ID, "error", err)
+					return nil
+				}
+				if _, err := h.grpcClients.UpdateOrderStatus(ctx, order.ID, userID, models.OrderStatusConfirmed); err != nil {
+					logging.FromContext(ctx).Warn("Failed to confirm order paid with balances", "order_id", order.ID, "error", err)
+				}
+				return nil
+			},
+			Compensate: refundBalances,
+		})
+	}
+	var payment *models.PaymentIntent
+	if paymentMethodID != "" {
+		placement.Add(saga.Step{
+			Name: "create-payment",
+			Action: func(ctx context.Context) (err error) {
+				if amountDue() <= 0 {
+					return nil
+				}
+				payment, err = h.grpcClients.CreatePaymentIntent(ctx, userID, order.ID, amountDue(), req.Currency)
+				return err
+			},
+			Compensate: func(ctx context.Context) error {
+				if payment == nil {
+					return nil
+				}
+				return h.grpcClients.CancelPayment(ctx, payment.ID, userID)
+			},
+		})
+		placement.Add(saga.Step{
+			Name: "capture-payment",
+			Action: func(ctx context.Context) error {
+				if payment == nil {
+					return nil
+				}
+				confirmed, err := h.grpcClients.ConfirmPayment(ctx, payment.ID, userID, paymentMethodID)
+				if err != nil {
+					return err
+				}
+				payment = confirmed
+				return nil
+			},
+		})
+	}
+
+	if err := placement.Run(c.Request.Context()); err != nil {
+		title := "Failed to create order"
+		var stepErr *saga.StepError
+		if errors.As(err, &stepErr) {
+			switch stepErr.Step {
+			case "reserve-inventory":
+				title = "Failed to reserve inventory"
+			case "split-order":
+				title = "Failed to split order"
+			case "redeem-coupon":
+				if stepErr.Err == grpcclient.ErrCouponUsedUp {
+					c.JSON(http.StatusConflict, models.ErrorResponse{
+						Error:   "Promo code not applicable",
+						Message: coupon.ErrUsedUp.Error(),
+					})
+					return nil, false
+				}
+				title = "Failed to redeem promo code"
+			case "hold-gift-card", "hold-store-credit", "check-balances":
+				if respondBalanceError(c, stepErr.Err) {
+					return nil, false
+				}
+				title = "Failed to apply balance"
+			case "create-payment":
+				title = "Failed to create payment"
+			case "capture-payment":
+				respondPaymentError(c, stepErr.Err)
+				return nil, false
+			case "capture-balances":
+				title = "Failed to capture balance"
+			}
+			err = stepErr.Err
 		}
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "Failed to create order",
+			Error:   title,
 			Message: err.Error(),
 		})
-		return
+		return nil, false
+	}
+	order.Payment = payment
+	order.SubOrders = subOrders
+	for _, hold := range giftCardHolds {
+		if hold != nil {
+			order.GiftCardApplied += hold.Amount
+		}
+	}
+	order.GiftCardApplied = math.Round(order.GiftCardApplied*100) / 100
+	if creditHold != nil {
+		order.StoreCreditApplied = creditHold.Amount
+	}
+	if payment == nil && covered() > 0 && amountDue() <= 0 {
+		order.Status = models.OrderStatusConfirmed
+		for _, sub := range subOrders {
+			sub.Status = models.OrderStatusConfirmed
+		}
 	}
+	return order, true
+}
 
-	c.JSON(http.StatusCreated, order)
+// respondChargeConversionError responds to a failure to convert a charge
+// set in the payment currency, such as shipping, into an order's currency
+func respondChargeConversionError(c *gin.Context, err error) {
+	c.JSON(http.StatusBadGateway, models.ErrorResponse{
+		Error:   "Failed to convert charges",
+		Message: err.Error(),
+	})
 }
 
-// UpdateOrderStatus updates the status of an order
+// respondBalanceError responds to gift card or store credit balances that
+// couldn't be held for an order, returning false for errors it doesn't
+// recognize
+func respondBalanceError(c *gin.Context, err error) bool {
+	switch err {
+	case errBalanceShort:
+		c.JSON(http.StatusPaymentRequired, models.ErrorResponse{
+			Error:   "Balance does not cover the order",
+			Message: "Add a payment method to pay the rest of the order",
+		})
+		return true
+	case grpcclient.ErrInsufficientCredit:
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Insufficient store credit",
+			Message: "Your store credit balance changed during checkout; please try again",
+		})
+		return true
+	case grpcclient.ErrInsufficientGiftCard:
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Insufficient gift card balance",
+			Message: "A gift card's balance changed during checkout; please try again",
+		})
+		return true
+	}
+	return false
+}
+
+// UpdateOrderStatus updates the status of an order. Items are moved along
+// by fulfillment with UpdateOrderItems, so customers can't mark their own
+// items delivered to return them.
 // PUT /api/v1/orders/:id/status
 func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
 	id := c.Param("id")
-	userID, _ := c.Get("userID")
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
 
 	var req models.UpdateOrderStatusRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error:   "Invalid request body",
 			Message: err.Error(),
@@ -168,41 +982,171 @@ func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
 		return
 	}
 
+	if len(req.Items) > 0 {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Order items are updated by fulfillment",
+		})
+		return
+	}
+
 	// Call user service via gRPC
-	order, err := h.grpcClients.UpdateOrderStatus(c.Request.Context(), id, userID.(string), req.Status)
+	order, err := h.grpcClients.UpdateOrderStatus(c.Request.Context(), id, userID, req.Status)
 	if err != nil {
-		if err == grpcclient.ErrNotFound {
-			c.JSON(http.StatusNotFound, models.ErrorResponse{
-				Error:   "Order not found",
-				Message: "No order exists with the given ID",
-			})
-			return
+		respondUpdateOrderError(c, err, "Failed to update order status")
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// UpdateOrderItems moves items of an order along individually, so the rest
+// of an order ships while an item is backordered; without a status the
+// order then follows its items
+// PUT /api/v1/admin/orders/:id/items
+func (h *OrderHandler) UpdateOrderItems(c *gin.Context) {
+	var req models.UpdateOrderStatusRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+	if len(req.Items) == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: "items is required",
+		})
+		return
+	}
+
+	h.updateItemStatuses(c, c.Param("id"), &req)
+}
+
+// updateItemStatuses moves items of an order to the statuses requested,
+// checking each can make the move, and the order to the requested status or
+// else the one its items put it in
+func (h *OrderHandler) updateItemStatuses(c *gin.Context, id string, req *models.UpdateOrderStatusRequest) {
+	order, err := h.grpcClients.GetOrder(c.Request.Context(), id, "")
+	if err != nil {
+		respondUpdateOrderError(c, err, "Failed to fetch order")
+		return
+	}
+	if order.Status == models.OrderStatusPending || order.Status == models.OrderStatusCancelled {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Order not being fulfilled",
+			Message: "The order is " + string(order.Status) + "; items are fulfilled once it is confirmed",
+		})
+		return
+	}
+
+	items := append([]models.OrderItem{}, order.Items...)
+	for _, update := range req.Items {
+		found := false
+		for i := range items {
+			if items[i].ProductID != update.ProductID {
+				continue
+			}
+			found = true
+			if !items[i].Status.CanMoveTo(update.Status) {
+				current := items[i].Status
+				if current == "" {
+					current = models.OrderItemStatusPending
+				}
+				c.JSON(http.StatusConflict, models.ErrorResponse{
+					Error:   "Invalid item status change",
+					Message: "Product " + update.ProductID + " can't move from " + string(current) + " to " + string(update.Status),
+				})
+				return
+			}
+			items[i].Status = update.Status
 		}
-		if err == grpcclient.ErrUnauthorized {
-			c.JSON(http.StatusForbidden, models.ErrorResponse{
-				Error:   "Unauthorized",
-				Message: "You don't have permission to update this order",
+		if !found {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid items",
+				Message: "Product " + update.ProductID + " is not in the order",
 			})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "Failed to update order status",
-			Message: err.Error(),
-		})
+	}
+
+	status := req.Status
+	if status == "" {
+		status = itemsOrderStatus(items)
+	}
+	if status == "" {
+		status = order.Status
+	}
+
+	// Call user service via gRPC
+	updated, err := h.grpcClients.UpdateOrderItemStatuses(c.Request.Context(), order, req.Items, status)
+	if err != nil {
+		respondUpdateOrderError(c, err, "Failed to update order status")
 		return
 	}
 
-	c.JSON(http.StatusOK, order)
+	c.JSON(http.StatusOK, updated)
+}
+
+// itemsOrderStatus returns the status an order's items put it in: delivered
+// once every item has been delivered, shipped once all have shipped,
+// partially shipped while some still wait, or empty while none has shipped
+func itemsOrderStatus(items []models.OrderItem) models.OrderStatus {
+	shipped, delivered := 0, 0
+	for _, item := range items {
+		switch item.Status {
+		case models.OrderItemStatusDelivered, models.OrderItemStatusReturned:
+			delivered++
+			shipped++
+		case models.OrderItemStatusShipped:
+			shipped++
+		}
+	}
+	switch {
+	case shipped == 0:
+		return ""
+	case shipped < len(items):
+		return models.OrderStatusPartiallyShipped
+	case delivered == len(items):
+		return models.OrderStatusDelivered
+	}
+	return models.OrderStatusShipped
+}
+
+// respondUpdateOrderError responds to a failed call updating an order
+func respondUpdateOrderError(c *gin.Context, err error, title string) {
+	if err == grpcclient.ErrNotFound {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Order not found",
+			Message: "No order exists with the given ID",
+		})
+		return
+	}
+	if err == grpcclient.ErrUnauthorized {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "You don't have permission to update this order",
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		Error:   title,
+		Message: err.Error(),
+	})
 }
 
 // CancelOrder cancels an order
 // DELETE /api/v1/orders/:id
 func (h *OrderHandler) CancelOrder(c *gin.Context) {
 	id := c.Param("id")
-	userID, _ := c.Get("userID")
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
 
 	// Get the order first to retrieve reservation IDs
-	order, err := h.grpcClients.GetOrder(c.Request.Context(), id, userID.(string))
+	order, err := h.grpcClients.GetOrder(c.Request.Context(), id, userID)
 	if err != nil {
 		if err == grpcclient.ErrNotFound {
 			c.JSON(http.StatusNotFound, models.ErrorResponse{
@@ -226,16 +1170,46 @@ func (h *OrderHandler) CancelOrder(c *gin.Context) {
 	}
 
 	// Check if order can be cancelled
-	if order.Status != "pending" && order.Status != "confirmed" {
+	if !order.Status.Cancellable() {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error:   "Cannot cancel order",
 			Message: "Order can only be cancelled when in pending or confirmed status",
 		})
 		return
 	}
+	subOrders, err := h.grpcClients.ListSubOrders(c.Request.Context(), id, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch order",
+			Message: err.Error(),
+		})
+		return
+	}
+	for _, sub := range subOrders {
+		if sub.Status != models.OrderStatusCancelled && !sub.Status.Cancellable() {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Cannot cancel order",
+				Message: "A seller has already started fulfilling part of the order",
+			})
+			return
+		}
+	}
 
-	// Cancel the order
-	err = h.grpcClients.CancelOrder(c.Request.Context(), id, userID.(string))
+	// Give back the card payment first, so a paid order is refunded and a
+	// pending one's intent can't be confirmed once the order is cancelled
+	if err := cancelOrderPayment(c.Request.Context(), h.grpcClients, order); err != nil {
+		c.JSON(http.StatusBadGateway, models.ErrorResponse{
+			Error:   "Failed to cancel payment",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Cancel the sellers' sub-orders, then the order
+	err = suborder.SetStatus(c.Request.Context(), h.grpcClients, id, userID, models.OrderStatusCancelled)
+	if err == nil {
+		err = h.grpcClients.CancelOrder(c.Request.Context(), id, userID)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "Failed to cancel order",
@@ -244,12 +1218,32 @@ func (h *OrderHandler) CancelOrder(c *gin.Context) {
 		return
 	}
 
-	// Release inventory reservations
-	for _, reservationID := range order.ReservationIDs {
-		h.grpcClients.CancelReservation(c.Request.Context(), reservationID)
+	// Release inventory reservations and the promo code redemption, and
+	// refund store credit and gift cards. The order is already cancelled, so
+	// a failure is logged rather than failing the request.
+	if err := holds.Release(c.Request.Context(), h.grpcClients, order); err != nil {
+		logging.FromContext(c.Request.Context()).Warn("Failed to release cancelled order's holds", "order_id", order.ID, "error", err)
 	}
 
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Message: "Order cancelled successfully",
 	})
 }
+
+// cancelOrderPayment cancels an order's unconfirmed payment intent, or
+// refunds it in full if it was charged. An order with no payment, or whose
+// payment already failed or was given back, has nothing to cancel.
+func cancelOrderPayment(ctx context.Context, clients *grpcclient.Clients, order *models.Order) error {
+	payment, err := clients.GetOrderPayment(ctx, order.ID, order.UserID)
+	if err == grpcclient.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	switch payment.Status {
+	case models.PaymentStatusFailed, models.PaymentStatusCanceled, models.PaymentStatusRefunded:
+		return nil
+	}
+	return clients.CancelPayment(ctx, payment.ID, order.UserID)
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+func TestItemsOrderStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []models.OrderItemStatus
+		want     models.OrderStatus
+	}{
+		{"no items", nil, ""},
+		{"none shipped", []models.OrderItemStatus{models.OrderItemStatusPending, models.OrderItemStatusBackordered}, ""},
+		{"items without statuses", []models.OrderItemStatus{"", ""}, ""},
+		{"some shipped", []models.OrderItemStatus{models.OrderItemStatusShipped, models.OrderItemStatusBackordered}, models.OrderStatusPartiallyShipped},
+		{"some delivered", []models.OrderItemStatus{models.OrderItemStatusDelivered, models.OrderItemStatusPending}, models.OrderStatusPartiallyShipped},
+		{"all shipped", []models.OrderItemStatus{models.OrderItemStatusShipped, models.OrderItemStatusDelivered}, models.OrderStatusShipped},
+		{"all delivered", []models.OrderItemStatus{models.OrderItemStatusDelivered, models.OrderItemStatusDelivered}, models.OrderStatusDelivered},
+		{"delivered and returned", []models.OrderItemStatus{models.OrderItemStatusDelivered, models.OrderItemStatusReturned}, models.OrderStatusDelivered},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := make([]models.OrderItem, len(tt.statuses))
+			for i, status := range tt.statuses {
+				items[i] = models.OrderItem{ProductID: string(rune('a' + i)), Status: status}
+			}
+			if got := itemsOrderStatus(items); got != tt.want {
+				t.Errorf("itemsOrderStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// newOrderRouter routes the order endpoints under test for a signed-in user
+func newOrderRouter(t *testing.T) *gin.Engine {
+	cfg := testConfig()
+	h := NewOrderHandler(newTestClients(t, cfg), nil, nil, nil, nil, nil, nil, nil, cfg)
+
+	router := gin.New()
+	orders := router.Group("/orders", asUser("user-1"))
+	orders.PUT("/:id/status", h.UpdateOrderStatus)
+	orders.DELETE("/:id", h.CancelOrder)
+	router.PUT("/admin/orders/:id/items", h.UpdateOrderItems)
+	return router
+}
+
+func TestUpdateOrderStatusRefusesItems(t *testing.T) {
+	router := newOrderRouter(t)
+
+	w := serve(router, http.MethodPut, "/orders/order-1/status", map[string]any{
+		"items": []map[string]string{{"product_id": "prod-1", "status": "delivered"}},
+	}, nil)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d: %s", w.Code, http.StatusForbidden, w.Body)
+	}
+}
+
+func TestUpdateOrderItems(t *testing.T) {
+	router := newOrderRouter(t)
+
+	tests := []struct {
+		name string
+		body any
+		want int
+	}{
+		{"no items", map[string]any{"status": "shipped"}, http.StatusBadRequest},
+		{"unknown status", map[string]any{"items": []map[string]string{{"product_id": "prod-1", "status": "lost"}}}, http.StatusBadRequest},
+		// Orders are pending until paid, and pending orders aren't fulfilled
+		{"pending order", map[string]any{"items": []map[string]string{{"product_id": "prod-1", "status": "shipped"}}}, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodPut, "/admin/orders/order-1/items", tt.body, nil)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestCancelOrder(t *testing.T) {
+	router := newOrderRouter(t)
+
+	tests := []struct {
+		name    string
+		orderID string
+		want    int
+	}{
+		{"pending order", "order-1", http.StatusOK},
+		{"unknown order", "not-found", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodDelete, "/orders/"+tt.orderID, nil, nil)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/middleware"
+)
+
+// pageParams reads the page and limit query parameters. Missing or invalid
+// values fall back to the first page and the route's default page size, and
+// limits above the route's maximum are clamped to it.
+func pageParams(c *gin.Context) (page, limit int) {
+	limits := middleware.GetResponseLimit(c)
+
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err = strconv.Atoi(c.Query("limit"))
+	if err != nil || limit < 1 {
+		limit = limits.DefaultPageSize
+	}
+	return page, min(limit, limits.MaxPageSize)
+}
+
+// truncateItems cuts items from the end of a list until it encodes to at
+// most the route's byte cap, reporting whether any were cut
+func truncateItems[T any](c *gin.Context, items []T) ([]T, bool) {
+	maxBytes := middleware.GetResponseLimit(c).MaxBytes
+	if maxBytes <= 0 {
+		return items, false
+	}
+
+	size := 2 // enclosing brackets
+	for i, item := range items {
+		encoded, err := json.Marshal(item)
+		if err != nil {
+			continue
+		}
+		size += len(encoded) + 1
+		if size > maxBytes {
+			return items[:i], true
+		}
+	}
+	return items, false
+}
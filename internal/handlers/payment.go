@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// PaymentHandler handles payments for orders
+type PaymentHandler struct {
+	grpcClients *grpcclient.Clients
+	config      *config.Config
+}
+
+// NewPaymentHandler creates a new payment handler
+func NewPaymentHandler(clients *grpcclient.Clients, cfg *config.Config) *PaymentHandler {
+	return &PaymentHandler{
+		grpcClients: clients,
+		config:      cfg,
+	}
+}
+
+// CreatePaymentIntent starts a payment of an order's total
+// POST /api/v1/payments/intents
+func (h *PaymentHandler) CreatePaymentIntent(c *gin.Context) {
+	var req models.CreatePaymentIntentRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	// The amount comes from the order, never from the client
+	order, err := h.grpcClients.GetOrder(c.Request.Context(), req.OrderID, userID)
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Order not found",
+				Message: "No order exists with the given ID",
+			})
+			return
+		}
+		if err == grpcclient.ErrUnauthorized {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "Unauthorized",
+				Message: "You don't have permission to pay for this order",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch order",
+			Message: err.Error(),
+		})
+		return
+	}
+	if order.Status != models.OrderStatusPending {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Order cannot be paid",
+			Message: "The order is " + string(order.Status),
+		})
+		return
+	}
+
+	due := order.TotalAmount - order.StoreCreditApplied - order.GiftCardApplied
+	if due <= 0 {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Order cannot be paid",
+			Message: "The order has been paid in full with gift cards or store credit",
+		})
+		return
+	}
+
+	// An order has one open intent at a time, so a retry gets the same one
+	// rather than a second charge
+	existing, err := h.grpcClients.GetOrderPayment(c.Request.Context(), order.ID, userID)
+	if err != nil && err != grpcclient.ErrNotFound {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch payment",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err == nil {
+		switch existing.Status {
+		case models.PaymentStatusRequiresConfirmation:
+			c.JSON(http.StatusOK, existing)
+			return
+		case models.PaymentStatusProcessing, models.PaymentStatusSucceeded:
+			c.JSON(http.StatusConflict, models.ErrorResponse{
+				Error:   "Order cannot be paid",
+				Message: "The order's payment is " + existing.Status,
+			})
+			return
+		}
+	}
+
+	// Call payment service via gRPC
+	intent, err := h.grpcClients.CreatePaymentIntent(c.Request.Context(), userID, order.ID, due, orderCurrency(order, h.config))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to create payment",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, intent)
+}
+
+// GetPaymentIntent returns a payment intent
+// GET /api/v1/payments/intents/:id
+func (h *PaymentHandler) GetPaymentIntent(c *gin.Context) {
+	intent, ok := h.fetchIntent(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, intent)
+}
+
+// ConfirmPayment charges a payment intent to the customer's payment method
+// POST /api/v1/payments/intents/:id/confirm
+func (h *PaymentHandler) ConfirmPayment(c *gin.Context) {
+	var req models.ConfirmPaymentRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	intent, ok := h.fetchIntent(c)
+	if !ok {
+		return
+	}
+	if intent.Status != models.PaymentStatusRequiresConfirmation {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Payment cannot be confirmed",
+			Message: "The payment is " + intent.Status,
+		})
+		return
+	}
+
+	// The order may have been cancelled or paid since the intent was created
+	order, err := h.grpcClients.GetOrder(c.Request.Context(), intent.OrderID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch order",
+			Message: err.Error(),
+		})
+		return
+	}
+	if order.Status != models.OrderStatusPending {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Payment cannot be confirmed",
+			Message: "The order is " + string(order.Status),
+		})
+		return
+	}
+
+	paymentMethodID := req.PaymentMethodID
+	if req.SavedPaymentMethodID != "" {
+		paymentMethodID, ok = savedPaymentMethod(c, h.grpcClients, req.SavedPaymentMethodID, userID)
+		if !ok {
+			return
+		}
+	}
+
+	// Call payment service via gRPC
+	intent, err = h.grpcClients.ConfirmPayment(c.Request.Context(), intent.ID, userID, paymentMethodID)
+	if err != nil {
+		respondPaymentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, intent)
+}
+
+// GetOrderPayment returns the payment for an order
+// GET /api/v1/orders/:id/payment
+func (h *PaymentHandler) GetOrderPayment(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	// Call payment service via gRPC
+	intent, err := h.grpcClients.GetOrderPayment(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Payment not found",
+				Message: "The order has no payment",
+			})
+			return
+		}
+		if err == grpcclient.ErrUnauthorized {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "Unauthorized",
+				Message: "You don't have permission to view this order",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch payment",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, intent)
+}
+
+// fetchIntent loads the payment intent named in the path, responding with
+// an error and returning false if it cannot be shown to the user
+func (h *PaymentHandler) fetchIntent(c *gin.Context) (*models.PaymentIntent, bool) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return nil, false
+	}
+
+	// Call payment service via gRPC
+	intent, err := h.grpcClients.GetPaymentIntent(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Payment not found",
+				Message: "No payment exists with the given ID",
+			})
+			return nil, false
+		}
+		if err == grpcclient.ErrUnauthorized {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "Unauthorized",
+				Message: "You don't have permission to view this payment",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch payment",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	return intent, true
+}
+
+// respondPaymentError responds to a failed charge, telling a declined
+// payment apart from a payment service failure
+func respondPaymentError(c *gin.Context, err error) {
+	if err == grpcclient.ErrDeclined {
+		c.JSON(http.StatusPaymentRequired, models.ErrorResponse{
+			Error:   "Payment declined",
+			Message: "The payment method was declined; try another one",
+		})
+		return
+	}
+	c.JSON(http.StatusBadGateway, models.ErrorResponse{
+		Error:   "Payment failed",
+		Message: err.Error(),
+	})
+}
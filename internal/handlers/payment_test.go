@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// newPaymentRouter routes the payment endpoints for a signed-in user
+func newPaymentRouter(t *testing.T) *gin.Engine {
+	cfg := testConfig()
+	h := NewPaymentHandler(newTestClients(t, cfg), cfg)
+
+	router := gin.New()
+	payments := router.Group("/payments/intents", asUser("user-1"))
+	payments.POST("", h.CreatePaymentIntent)
+	payments.POST("/:id/confirm", h.ConfirmPayment)
+	return router
+}
+
+func TestCreatePaymentIntent(t *testing.T) {
+	router := newPaymentRouter(t)
+
+	tests := []struct {
+		name    string
+		orderID string
+		want    int
+	}{
+		{"unknown order", "not-found", http.StatusNotFound},
+		// The order has no amount due once balances cover its total
+		{"nothing due", "order-1", http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodPost, "/payments/intents", map[string]string{"order_id": tt.orderID}, nil)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestConfirmPayment(t *testing.T) {
+	router := newPaymentRouter(t)
+
+	tests := []struct {
+		name       string
+		intentID   string
+		body       any
+		want       int
+		wantStatus string
+	}{
+		{
+			name:       "charged",
+			intentID:   "pi-order-1",
+			body:       map[string]string{"payment_method_id": "pm_card_visa"},
+			want:       http.StatusOK,
+			wantStatus: models.PaymentStatusSucceeded,
+		},
+		{
+			name:     "declined",
+			intentID: "pi-order-1",
+			body:     map[string]string{"payment_method_id": "pm_card_declined"},
+			want:     http.StatusPaymentRequired,
+		},
+		{
+			name:     "no payment method",
+			intentID: "pi-order-1",
+			body:     map[string]string{},
+			want:     http.StatusBadRequest,
+		},
+		{
+			name:     "unknown intent",
+			intentID: "not-found",
+			body:     map[string]string{"payment_method_id": "pm_card_visa"},
+			want:     http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodPost, "/payments/intents/"+tt.intentID+"/confirm", tt.body, nil)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if tt.wantStatus == "" {
+				return
+			}
+			var intent models.PaymentIntent
+			if err := json.Unmarshal(w.Body.Bytes(), &intent); err != nil {
+				t.Fatalf("decoding intent: %v", err)
+			}
+			if intent.Status != tt.wantStatus {
+				t.Errorf("intent status = %q, want %q", intent.Status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// PaymentMethodHandler handles the signed-in user's saved payment methods
+type PaymentMethodHandler struct {
+	grpcClients *grpcclient.Clients
+}
+
+// NewPaymentMethodHandler creates a new payment method handler
+func NewPaymentMethodHandler(clients *grpcclient.Clients) *PaymentMethodHandler {
+	return &PaymentMethodHandler{
+		grpcClients: clients,
+	}
+}
+
+// ListPaymentMethods returns the user's saved payment methods, the default
+// first
+// GET /api/v1/me/payment-methods
+func (h *PaymentMethodHandler) ListPaymentMethods(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	methods, err := h.grpcClients.ListPaymentMethods(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch payment methods",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"payment_methods": methods,
+	})
+}
+
+// SavePaymentMethod saves a payment method the client tokenized with the
+// payment provider. Card numbers are refused, so they never reach the
+// gateway's logs or the backend.
+// POST /api/v1/me/payment-methods
+func (h *PaymentMethodHandler) SavePaymentMethod(c *gin.Context) {
+	var req models.SavePaymentMethodRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+	if looksLikeCardNumber(req.Token) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Card numbers not accepted",
+			Message: "Tokenize the card with the payment provider and send its token instead",
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	method, err := h.grpcClients.SavePaymentMethod(c.Request.Context(), userID, req.Token, req.SetDefault)
+	if err != nil {
+		if err == grpcclient.ErrInvalidPaymentMethod {
+			c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+				Error:   "Invalid payment method",
+				Message: "The payment provider did not accept the token",
+			})
+			return
+		}
+		c.JSON(http.StatusBadGateway, models.ErrorResponse{
+			Error:   "Failed to save payment method",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, method)
+}
+
+// SetDefaultPaymentMethod makes a saved payment method the user's default
+// POST /api/v1/me/payment-methods/:id/default
+func (h *PaymentMethodHandler) SetDefaultPaymentMethod(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	method, err := h.grpcClients.SetDefaultPaymentMethod(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		respondPaymentMethodError(c, "Failed to set default payment method", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, method)
+}
+
+// DeletePaymentMethod removes a saved payment method, detaching it from the
+// payment provider as well
+// DELETE /api/v1/me/payment-methods/:id
+func (h *PaymentMethodHandler) DeletePaymentMethod(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.grpcClients.DeletePaymentMethod(c.Request.Context(), c.Param("id"), userID); err != nil {
+		respondPaymentMethodError(c, "Failed to delete payment method", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// savedPaymentMethod returns the provider payment method behind one of the
+// user's saved methods, responding with an error and returning false if it
+// is not theirs or has expired
+func savedPaymentMethod(c *gin.Context, clients *grpcclient.Clients, methodID, userID string) (string, bool) {
+	method, err := clients.GetPaymentMethod(c.Request.Context(), methodID, userID)
+	if err != nil {
+		respondPaymentMethodError(c, "Failed to fetch payment method", err)
+		return "", false
+	}
+	if cardExpired(method, time.Now()) {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Error:   "Payment method expired",
+			Message: "The saved card has expired; add a new payment method",
+		})
+		return "", false
+	}
+	return method.ProviderMethodID, true
+}
+
+// cardExpired reports whether a saved card's expiry month has passed
+func cardExpired(method *models.SavedPaymentMethod, now time.Time) bool {
+	if method.ExpYear == 0 {
+		return false
+	}
+	return now.Year() > method.ExpYear || (now.Year() == method.ExpYear && int(now.Month()) > method.ExpMonth)
+}
+
+// looksLikeCardNumber reports whether s is a plausible card number: 12 to
+// 19 digits, optionally grouped by spaces or dashes, passing the Luhn check
+func looksLikeCardNumber(s string) bool {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(s)
+	if len(digits) < 12 || len(digits) > 19 {
+		return false
+	}
+
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := digits[i]
+		if d < '0' || d > '9' {
+			return false
+		}
+		n := int(d - '0')
+		if (len(digits)-1-i)%2 == 1 {
+			n *= 2
+			if n > 9 {
+				n -= 9
+			}
+		}
+		sum += n
+	}
+	return sum%10 == 0
+}
+
+// respondPaymentMethodError responds 404 for payment methods the user
+// doesn't have, or 502 with title otherwise
+func respondPaymentMethodError(c *gin.Context, title string, err error) {
+	if err == grpcclient.ErrNotFound || err == grpcclient.ErrUnauthorized {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Payment method not found",
+			Message: "No saved payment method exists with the given ID",
+		})
+		return
+	}
+	c.JSON(http.StatusBadGateway, models.ErrorResponse{
+		Error:   title,
+		Message: err.Error(),
+	})
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/errorcodes"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/middleware"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	"github.com/ecommerce/be-api-gin/internal/pos"
+)
+
+// POSHandler handles the API used by in-store kiosks and tills
+type POSHandler struct {
+	queue  *pos.Queue
+	config *config.Config
+}
+
+// NewPOSHandler creates a new POS handler
+func NewPOSHandler(queue *pos.Queue, cfg *config.Config) *POSHandler {
+	return &POSHandler{
+		queue:  queue,
+		config: cfg,
+	}
+}
+
+// Token issues a short-lived POS token to a registered device
+// POST /api/v1/pos/token
+func (h *POSHandler) Token(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	if h.config.POSTokenSecret == "" {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Not found",
+			Message: "POS device tokens are not enabled",
+		})
+		return
+	}
+
+	var req models.POSTokenRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	device, exists := h.config.POSDevices[req.DeviceID]
+	if !exists || device.Disabled || !device.VerifySecret(req.DeviceSecret) {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "Invalid device",
+			Message: "Device authentication failed",
+			Code:    errorcodes.InvalidDevice,
+		})
+		return
+	}
+
+	token, ttl, err := middleware.IssueDeviceToken(h.config, device)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to issue token",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.POSToken{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(ttl.Seconds()),
+		DeviceID:    device.DeviceID,
+		StoreID:     device.StoreID,
+	})
+}
+
+// SubmitOrder accepts an order taken at the device. A new order is answered
+// with 202 and status accepted_pending once it is recorded, so the device
+// can drop it from its local queue; resubmitting it returns its current
+// state with Idempotent-Replayed. On any other failure the device should
+// keep the order queued and resubmit it.
+// POST /api/v1/pos/orders
+func (h *POSHandler) SubmitOrder(c *gin.Context) {
+	var req models.POSOrderRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	deviceID, storeID, _ := middleware.GetDevice(c)
+	order, err := h.queue.Submit(c.Request.Context(), deviceID, storeID, &req)
+	if err != nil {
+		if errors.Is(err, pos.ErrLocalIDReused) {
+			c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+				Error:   "Local order ID reused",
+				Message: err.Error(),
+				Code:    errorcodes.LocalIDReused,
+			})
+			return
+		}
+		logging.FromContext(c.Request.Context()).Warn("POS order queue unavailable", "local_id", req.LocalID, "error", err)
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Error:   "Order queue unavailable",
+			Message: "The order was not recorded; keep it queued and submit it again",
+		})
+		return
+	}
+
+	if order.Replayed {
+		c.Header(middleware.IdempotentReplayedHeader, "true")
+		c.JSON(http.StatusOK, order)
+		return
+	}
+	c.JSON(http.StatusAccepted, order)
+}
+
+// GetOrder reports the state of an order the device submitted
+// GET /api/v1/pos/orders/:localId
+func (h *POSHandler) GetOrder(c *gin.Context) {
+	deviceID, _, _ := middleware.GetDevice(c)
+	order, err := h.queue.Get(c.Request.Context(), deviceID, c.Param("localId"))
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Error:   "Order queue unavailable",
+			Message: err.Error(),
+		})
+		return
+	}
+	if order == nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Order not found",
+			Message: "The device has submitted no order with the given local ID",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// ReconcileOrders resubmits the orders a device queued while offline once
+// it reconnects. Each is accepted or replayed as if submitted on its own,
+// and the results list, in the order given, either its state or why it
+// couldn't be accepted so the device knows which to keep queued.
+// POST /api/v1/pos/orders/reconcile
+func (h *POSHandler) ReconcileOrders(c *gin.Context) {
+	var req models.POSReconcileRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	deviceID, storeID, _ := middleware.GetDevice(c)
+	results := make([]models.POSReconcileResult, len(req.Orders))
+	for i := range req.Orders {
+		submitted := &req.Orders[i]
+		results[i].LocalID = submitted.LocalID
+		order, err := h.queue.Submit(c.Request.Context(), deviceID, storeID, submitted)
+		switch {
+		case err == nil:
+			results[i].Order = order
+		case errors.Is(err, pos.ErrLocalIDReused):
+			results[i].Error = err.Error()
+		default:
+			logging.FromContext(c.Request.Context()).Warn("POS order queue unavailable", "local_id", submitted.LocalID, "error", err)
+			results[i].Error = "the order was not recorded; keep it queued and submit it again"
+		}
+	}
+
+	c.JSON(http.StatusOK, models.POSReconcileResponse{Results: results})
+}
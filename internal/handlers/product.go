@@ -1,24 +1,77 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"io"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/errgroup"
 
+	"github.com/ecommerce/be-api-gin/internal/audit"
+	"github.com/ecommerce/be-api-gin/internal/cache"
+	"github.com/ecommerce/be-api-gin/internal/catalog"
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/currency"
+	"github.com/ecommerce/be-api-gin/internal/dispatch"
+	"github.com/ecommerce/be-api-gin/internal/jobs"
+	"github.com/ecommerce/be-api-gin/internal/localization"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/middleware"
 	"github.com/ecommerce/be-api-gin/internal/models"
+	"github.com/ecommerce/be-api-gin/internal/moderation"
+	"github.com/ecommerce/be-api-gin/internal/preview"
+	"github.com/ecommerce/be-api-gin/internal/requestid"
+	"github.com/ecommerce/be-api-gin/internal/search"
+	"github.com/ecommerce/be-api-gin/internal/undo"
 	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
 )
 
+// productDetailReviews is how many of the latest reviews the product detail
+// endpoint includes
+const productDetailReviews = 5
+
+// maxCompareProducts is how many products can be compared at once
+const maxCompareProducts = 5
+
+// barcodeInventoryFetches is how many products' inventory a barcode lookup
+// fetches at once
+const barcodeInventoryFetches = 10
+
 // ProductHandler handles product-related requests
 type ProductHandler struct {
 	grpcClients *grpcclient.Clients
+	config      *config.Config
+	moderation  *moderation.Pipeline
+	undo        undo.Store
+	localizer   *localization.Localizer
+	products    *cache.ProductCache
+	jobs        *jobs.Runner
+	fallback    *search.Index
+	dispatch    *dispatch.Planner
+	currency    *currency.Converter
 }
 
 // NewProductHandler creates a new product handler
-func NewProductHandler(clients *grpcclient.Clients) *ProductHandler {
+func NewProductHandler(clients *grpcclient.Clients, cfg *config.Config, pipeline *moderation.Pipeline, undoStore undo.Store, localizer *localization.Localizer, products *cache.ProductCache, runner *jobs.Runner, fallback *search.Index, planner *dispatch.Planner, converter *currency.Converter) *ProductHandler {
 	return &ProductHandler{
 		grpcClients: clients,
+		config:      cfg,
+		moderation:  pipeline,
+		undo:        undoStore,
+		localizer:   localizer,
+		products:    products,
+		jobs:        runner,
+		fallback:    fallback,
+		dispatch:    planner,
+		currency:    converter,
 	}
 }
 
@@ -26,21 +79,63 @@ func NewProductHandler(clients *grpcclient.Clients) *ProductHandler {
 // GET /api/v1/products
 func (h *ProductHandler) ListProducts(c *gin.Context) {
 	// Parse query parameters
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	page, limit := pageParams(c)
 	category := c.Query("category")
 	search := c.Query("search")
+	if category != "" {
+		if _, err := models.ParseCategory(category); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid category",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
 
 	// Call listing service via gRPC
-	products, total, err := h.grpcClients.ListProducts(c.Request.Context(), page, limit, category, search)
+	products, total, err := h.grpcClients.ListProducts(c.Request.Context(), page, limit, category, search, true)
+	degraded := false
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "Failed to fetch products",
-			Message: err.Error(),
-		})
+		if h.fallback == nil || !h.fallback.Ready() {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Failed to fetch products",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		// Answer from the in-memory index with reduced quality and keep the
+		// response out of shared caches
+		logging.FromContext(c.Request.Context()).Warn("Listing service unavailable, serving fallback index", "error", err)
+		products, total = h.fallback.Search(search, category, page, limit, isPubliclyVisible)
+		degraded = true
+		c.Header("Cache-Control", "no-store")
+	}
+
+	// Show prices in the caller's currency
+	if !displayPrices(c, h.currency, h.config, products...) {
 		return
 	}
 
+	// Serve stored translations and measurements for the caller's locale
+	locale := h.negotiateLocale(c)
+	system := unitSystem(c)
+	h.localizer.LocalizeAll(c.Request.Context(), products, locale, false)
+	for _, product := range products {
+		catalog.DisplayUnits(product.Attributes, system)
+	}
+
+	// Products of sellers on vacation can't be bought, and those that can
+	// be promise when they would be dispatched
+	vacations := newSellerVacations(h.grpcClients)
+	now := time.Now()
+	for _, product := range products {
+		vacations.Apply(c.Request.Context(), product)
+		if product.Available {
+			product.Dispatch = h.dispatch.Promise(product.SellerID, now)
+		}
+	}
+
 	// Set InStock field for frontend compatibility
 	for i := range products {
 		products[i].InStock = products[i].Available
@@ -55,6 +150,7 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 		Page:     page,
 		Limit:    limit,
 		Total:    total,
+		Degraded: degraded,
 	})
 }
 
@@ -63,8 +159,8 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 func (h *ProductHandler) GetProduct(c *gin.Context) {
 	id := c.Param("id")
 
-	// Call listing service via gRPC
-	product, err := h.grpcClients.GetProduct(c.Request.Context(), id)
+	// Serve hot products from cache, falling back to the listing service
+	product, err := h.products.Get(c.Request.Context(), id)
 	if err != nil {
 		if err == grpcclient.ErrNotFound {
 			c.JSON(http.StatusNotFound, models.ErrorResponse{
@@ -80,6 +176,16 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 		return
 	}
 
+	// Hide content withheld by moderation and unpublished drafts; sellers
+	// can still view their own drafts, as can anyone with a preview link
+	if !isPubliclyVisible(product) && !(isApprovedContent(product.ModerationStatus) && h.canPreview(c, product)) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Product not found",
+			Message: "No product exists with the given ID",
+		})
+		return
+	}
+
 	// Get inventory info
 	inventory, err := h.grpcClients.GetInventory(c.Request.Context(), id)
 	if err == nil {
@@ -87,21 +193,409 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 		product.Available = inventory.Available
 	}
 
+	if !displayPrices(c, h.currency, h.config, product) {
+		return
+	}
+	h.presentProduct(c, product)
+	h.addFitHint(c.Request.Context(), product)
+	c.JSON(http.StatusOK, product)
+}
+
+// presentProduct prepares a product for display to the caller
+func (h *ProductHandler) presentProduct(c *gin.Context, product *models.Product) {
+	// Products of sellers on vacation can't be bought, and those that can
+	// promise when they would be dispatched
+	newSellerVacations(h.grpcClients).Apply(c.Request.Context(), product)
+	if product.Available {
+		product.Dispatch = h.dispatch.Promise(product.SellerID, time.Now())
+	}
+
+	// Serve the caller's locale, queueing a machine translation if needed
+	h.localizer.Localize(c.Request.Context(), product, h.negotiateLocale(c), true)
+	c.Header("Content-Language", product.Locale)
+	catalog.DisplayUnits(product.Attributes, unitSystem(c))
+
 	// Set InStock field for frontend compatibility
 	product.InStock = product.Available
 	// Set ImageUrl from first image if available
 	if len(product.Images) > 0 {
 		product.ImageUrl = product.Images[0]
 	}
+}
 
-	c.JSON(http.StatusOK, product)
+// addFitHint tells shoppers how a sized product fits, from what its
+// reviewers report. The product is shown without it if the counts can't be
+// fetched.
+func (h *ProductHandler) addFitHint(ctx context.Context, product *models.Product) {
+	if !models.Category(product.Category).Sized() {
+		return
+	}
+	summary, err := h.grpcClients.GetFitSummary(ctx, product.ID)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Failed to fetch fit summary", "product_id", product.ID, "error", err)
+		return
+	}
+	catalog.FitHint(summary, h.config.FitHintMinResponses)
+	product.Fit = summary
+}
+
+// GetProductFull returns a product together with its inventory and latest
+// reviews, fetched from their services in parallel. The product is required;
+// if inventory or reviews cannot be fetched the rest is still returned, with
+// a warning naming the missing part.
+// GET /api/v1/products/:id/full
+func (h *ProductHandler) GetProductFull(c *gin.Context) {
+	id := c.Param("id")
+
+	var (
+		product     *models.Product
+		inventory   *models.Inventory
+		reviews     []*models.Review
+		reviewTotal int64
+		warnings    []models.ResponseWarning
+		mu          sync.Mutex
+	)
+	warn := func(component string, err error) {
+		logging.FromContext(c.Request.Context()).Warn("Product detail component unavailable", "product_id", id, "component", component, "error", err)
+		mu.Lock()
+		defer mu.Unlock()
+		warnings = append(warnings, models.ResponseWarning{
+			Component: component,
+			Message:   component + " are temporarily unavailable",
+		})
+	}
+
+	g, ctx := errgroup.WithContext(c.Request.Context())
+	g.Go(func() error {
+		var err error
+		product, err = h.products.Get(ctx, id)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		if inventory, err = h.grpcClients.GetInventory(ctx, id); err != nil && ctx.Err() == nil {
+			warn("inventory", err)
+		}
+		return nil
+	})
+	g.Go(func() error {
+		var err error
+		if reviews, reviewTotal, err = h.grpcClients.ListReviews(ctx, id, 1, productDetailReviews); err != nil && ctx.Err() == nil {
+			warn("reviews", err)
+		}
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Product not found",
+				Message: "No product exists with the given ID",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch product",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Hide content withheld by moderation and unpublished drafts, as GetProduct does
+	if !isPubliclyVisible(product) && !(isApprovedContent(product.ModerationStatus) && h.canPreview(c, product)) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Product not found",
+			Message: "No product exists with the given ID",
+		})
+		return
+	}
+
+	if inventory != nil {
+		product.Stock = inventory.Quantity
+		product.Available = inventory.Available
+	}
+	if !displayPrices(c, h.currency, h.config, product) {
+		return
+	}
+	h.presentProduct(c, product)
+	h.addFitHint(c.Request.Context(), product)
+
+	// Keep partial responses out of shared caches
+	if len(warnings) > 0 {
+		c.Header("Cache-Control", "no-store")
+		sort.Slice(warnings, func(i, j int) bool { return warnings[i].Component < warnings[j].Component })
+	}
+
+	detail := models.ProductDetailResponse{
+		Product:   product,
+		Inventory: inventory,
+		Warnings:  warnings,
+	}
+	if reviews != nil {
+		detail.Reviews = visibleReviews(reviews)
+		detail.ReviewCount = reviewTotal
+	}
+	c.JSON(http.StatusOK, detail)
+}
+
+// CompareProducts lines up the attributes of 2 to 5 products side by side,
+// fetching them and their inventory in parallel
+// GET /api/v1/products/compare?ids=a,b,c
+func (h *ProductHandler) CompareProducts(c *gin.Context) {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, id := range strings.Split(c.Query("ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) < 2 || len(ids) > maxCompareProducts {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid product IDs",
+			Message: "ids must list 2 to " + strconv.Itoa(maxCompareProducts) + " distinct product IDs, separated by commas",
+		})
+		return
+	}
+
+	products := make([]*models.Product, len(ids))
+	g, ctx := errgroup.WithContext(c.Request.Context())
+	for i, id := range ids {
+		i, id := i, id
+		g.Go(func() error {
+			product, err := h.products.Get(ctx, id)
+			if err != nil {
+				if err == grpcclient.ErrNotFound {
+					return &missingProductError{id: id}
+				}
+				return err
+			}
+			// Hide content withheld by moderation and unpublished drafts
+			if !isPubliclyVisible(product) {
+				return &missingProductError{id: id}
+			}
+			// Without inventory the product shows as unavailable
+			if inventory, err := h.grpcClients.GetInventory(ctx, id); err == nil {
+				product.Stock = inventory.Quantity
+				product.Available = inventory.Available
+			}
+			products[i] = product
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		var missing *missingProductError
+		if errors.As(err, &missing) {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Product not found",
+				Message: "No product exists with ID " + missing.id,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch product",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if !displayPrices(c, h.currency, h.config, products...) {
+		return
+	}
+	for _, product := range products {
+		h.presentProduct(c, product)
+	}
+	c.JSON(http.StatusOK, catalog.Compare(products))
+}
+
+// missingProductError names a compared product that doesn't exist or can't
+// be shown
+type missingProductError struct {
+	id string
+}
+
+func (e *missingProductError) Error() string {
+	return "product " + e.id + " not found"
+}
+
+// LookupBarcode returns the product a scanned barcode or QR code belongs to,
+// with its inventory, for in-store and warehouse scanners
+// GET /api/v1/products/lookup?barcode=EAN
+func (h *ProductHandler) LookupBarcode(c *gin.Context) {
+	barcode := c.Query("barcode")
+	if barcode == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid barcode",
+			Message: "barcode is required",
+		})
+		return
+	}
+
+	matches, err := h.lookupBarcodes(c, []string{barcode})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to look up barcode",
+			Message: err.Error(),
+		})
+		return
+	}
+	match := matches[0]
+	if match.GTIN == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid barcode",
+			Message: match.Error,
+		})
+		return
+	}
+	if match.Product == nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Product not found",
+			Message: "No product carries barcode " + match.GTIN,
+		})
+		return
+	}
+	if !displayPrices(c, h.currency, h.config, match.Product) {
+		return
+	}
+
+	c.JSON(http.StatusOK, match)
+}
+
+// LookupBarcodes looks up a batch of scanned barcodes at once, returning a
+// match for each in the order given. Barcodes that are invalid or that no
+// product carries say why in their match rather than failing the batch.
+// POST /api/v1/products/lookup
+func (h *ProductHandler) LookupBarcodes(c *gin.Context) {
+	var req models.BarcodeLookupRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	matches, err := h.lookupBarcodes(c, req.Barcodes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to look up barcodes",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Products scanned more than once are converted once
+	var products []*models.Product
+	seen := make(map[*models.Product]bool)
+	for _, match := range matches {
+		if match.Product != nil && !seen[match.Product] {
+			seen[match.Product] = true
+			products = append(products, match.Product)
+		}
+	}
+	if !displayPrices(c, h.currency, h.config, products...) {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.BarcodeLookupResponse{Results: matches})
+}
+
+// lookupBarcodes matches barcodes to products in one call to the listing
+// service, then fetches the products' inventory in parallel. A product
+// whose inventory can't be fetched is still matched, with a warning.
+func (h *ProductHandler) lookupBarcodes(c *gin.Context, barcodes []string) ([]models.BarcodeMatch, error) {
+	matches := make([]models.BarcodeMatch, len(barcodes))
+	var gtins []string
+	seen := make(map[string]bool)
+	for i, barcode := range barcodes {
+		matches[i].Barcode = barcode
+		gtin, err := catalog.NormalizeBarcode(barcode)
+		if err != nil {
+			matches[i].Error = err.Error()
+			continue
+		}
+		matches[i].GTIN = gtin
+		if !seen[gtin] {
+			seen[gtin] = true
+			gtins = append(gtins, gtin)
+		}
+	}
+	if len(gtins) == 0 {
+		return matches, nil
+	}
+
+	products, err := h.grpcClients.GetProductsByBarcode(c.Request.Context(), gtins)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch each product's inventory once, however often it was scanned.
+	// The products are listed before any fetch starts, and each fetch only
+	// writes its own slot.
+	var unique []*models.Product
+	listed := make(map[*models.Product]bool)
+	for _, product := range products {
+		if !listed[product] && isPubliclyVisible(product) {
+			listed[product] = true
+			unique = append(unique, product)
+		}
+	}
+	fetched := make([]*models.Inventory, len(unique))
+	g, ctx := errgroup.WithContext(c.Request.Context())
+	g.SetLimit(barcodeInventoryFetches)
+	for i, product := range unique {
+		i, product := i, product
+		g.Go(func() error {
+			inventory, err := h.grpcClients.GetInventory(ctx, product.ID)
+			if err != nil {
+				logging.FromContext(ctx).Warn("Scanned product inventory unavailable", "product_id", product.ID, "error", err)
+				return nil
+			}
+			fetched[i] = inventory
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	inventories := make(map[*models.Product]*models.Inventory, len(unique))
+	for i, product := range unique {
+		inventories[product] = fetched[i]
+	}
+
+	for product, inventory := range inventories {
+		if inventory != nil {
+			product.Stock = inventory.Quantity
+			product.Available = inventory.Available
+		}
+		h.presentProduct(c, product)
+	}
+
+	for i := range matches {
+		match := &matches[i]
+		if match.GTIN == "" {
+			continue
+		}
+		product := products[match.GTIN]
+		if product == nil || !isPubliclyVisible(product) {
+			match.Error = "no product carries this barcode"
+			continue
+		}
+		match.Product = product
+		match.Inventory = inventories[product]
+		if match.Inventory == nil {
+			match.Warnings = []models.ResponseWarning{{
+				Component: "inventory",
+				Message:   "inventory is temporarily unavailable",
+			}}
+		}
+	}
+	return matches, nil
 }
 
 // CreateProduct creates a new product
 // POST /api/v1/products
 func (h *ProductHandler) CreateProduct(c *gin.Context) {
 	var req models.CreateProductRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error:   "Invalid request body",
 			Message: err.Error(),
@@ -110,10 +604,98 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 	}
 
 	// Get user ID from context (set by auth middleware)
-	userID, _ := c.Get("userID")
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	// Store measurements in canonical units and barcodes as GTIN-14
+	if err := catalog.NormalizeUnits(req.Attributes); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid attribute",
+			Message: err.Error(),
+		})
+		return
+	}
+	barcodes, err := catalog.NormalizeBarcodes(req.Barcodes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid barcode",
+			Message: err.Error(),
+		})
+		return
+	}
+	req.Barcodes = barcodes
+	if req.Currency == "" {
+		req.Currency = h.config.PaymentCurrency
+	}
+
+	// Resolve the publishing status; scheduled and explicitly published
+	// products must be complete
+	if req.PublishAt != nil {
+		if !req.PublishAt.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid publish time",
+				Message: "publish_at must be in the future",
+			})
+			return
+		}
+		req.Status = models.ProductStatusScheduled
+	}
+	if req.Status == models.ProductStatusScheduled || req.Status == models.ProductStatusPublished {
+		blockers := catalog.PublishBlockers(&models.Product{
+			Name:        req.Name,
+			Description: req.Description,
+			Price:       req.Price,
+			Category:    string(req.Category),
+			Images:      req.Images,
+			Attributes:  req.Attributes,
+		})
+		if len(blockers) > 0 {
+			c.JSON(http.StatusUnprocessableEntity, models.ProductIncompleteResponse{
+				Error:   "Product incomplete",
+				Message: "The product must be complete before it can be published",
+				Issues:  blockers,
+			})
+			return
+		}
+	}
+
+	// Screen title and description
+	decision := h.moderation.Screen(c.Request.Context(), map[string]string{
+		"name":        req.Name,
+		"description": req.Description,
+	})
+	if decision.Verdict == moderation.VerdictRejected {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Content rejected",
+			"message": "The product contains prohibited content",
+			"reasons": decision.Reasons,
+		})
+		return
+	}
+
+	// Check for near-duplicate listings
+	var duplicates []models.DuplicateMatch
+	if h.config.DuplicatePolicy != catalog.DuplicatePolicyOff {
+		var err error
+		duplicates, err = h.findDuplicates(c.Request.Context(), &req, userID)
+		if err != nil {
+			// Don't block listing creation on a failed lookup
+			logging.FromContext(c.Request.Context()).Warn("Duplicate check failed", "error", err)
+		}
+		if len(duplicates) > 0 && h.config.DuplicatePolicy == catalog.DuplicatePolicyBlock {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":               "Duplicate product",
+				"message":             "This listing is too similar to an existing product",
+				"possible_duplicates": duplicates,
+			})
+			return
+		}
+	}
 
 	// Call listing service via gRPC
-	product, err := h.grpcClients.CreateProduct(c.Request.Context(), &req, userID.(string))
+	product, err := h.grpcClients.CreateProduct(c.Request.Context(), &req, userID, decision.Verdict)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "Failed to create product",
@@ -122,13 +704,57 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 		return
 	}
 
+	// Queue quarantined content for admin review
+	if decision.Verdict == moderation.VerdictQuarantined {
+		enqueueModeration(c.Request.Context(), h.grpcClients, models.ContentTypeProduct, product.ID, userID, decision)
+	}
+
+	// Queue possible duplicates for admin review
+	if len(duplicates) > 0 {
+		_, err := h.grpcClients.CreateDuplicateFlag(c.Request.Context(), &models.DuplicateFlag{
+			ProductID: product.ID,
+			SellerID:  userID,
+			Matches:   duplicates,
+			Status:    models.DuplicateFlagPending,
+		})
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Warn("Failed to flag possible duplicate", "product_id", product.ID, "error", err)
+		}
+	}
+
 	// Initialize inventory for the product
 	if err := h.grpcClients.InitializeInventory(c.Request.Context(), product.ID, req.InitialStock); err != nil {
 		// Log error but don't fail the request
 		// Inventory can be updated later
 	}
 
-	c.JSON(http.StatusCreated, product)
+	c.JSON(http.StatusCreated, models.CreateProductResponse{
+		Product:            product,
+		PossibleDuplicates: duplicates,
+	})
+}
+
+// findDuplicates compares a new listing against the seller's own products
+// and products with a similar title in the same category
+func (h *ProductHandler) findDuplicates(ctx context.Context, req *models.CreateProductRequest, sellerID string) ([]models.DuplicateMatch, error) {
+	existing, err := h.grpcClients.ListSellerProducts(ctx, sellerID)
+	if err != nil {
+		return nil, err
+	}
+
+	similar, _, err := h.grpcClients.ListProducts(ctx, 1, 50, string(req.Category), req.Name, false)
+	if err != nil {
+		return nil, err
+	}
+	existing = append(existing, similar...)
+
+	candidate := &models.Product{
+		Name:        req.Name,
+		Category:    string(req.Category),
+		Attributes:  req.Attributes,
+		ImageHashes: req.ImageHashes,
+	}
+	return catalog.FindDuplicates(candidate, existing, h.config.DuplicateThreshold), nil
 }
 
 // UpdateProduct updates an existing product
@@ -137,7 +763,7 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	id := c.Param("id")
 
 	var req models.UpdateProductRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error:   "Invalid request body",
 			Message: err.Error(),
@@ -146,10 +772,76 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	}
 
 	// Get user ID from context
-	userID, _ := c.Get("userID")
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	// Store measurements in canonical units and barcodes as GTIN-14
+	if req.Attributes != nil {
+		if err := catalog.NormalizeUnits(*req.Attributes); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid attribute",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+	if req.Barcodes != nil {
+		barcodes, err := catalog.NormalizeBarcodes(*req.Barcodes)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid barcode",
+				Message: err.Error(),
+			})
+			return
+		}
+		req.Barcodes = &barcodes
+	}
+
+	// Screen changed title and description
+	fields := map[string]string{}
+	if req.Name != nil {
+		fields["name"] = *req.Name
+	}
+	if req.Description != nil {
+		fields["description"] = *req.Description
+	}
+	decision := h.moderation.Screen(c.Request.Context(), fields)
+	if decision.Verdict == moderation.VerdictRejected {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Content rejected",
+			"message": "The product contains prohibited content",
+			"reasons": decision.Reasons,
+		})
+		return
+	}
+
+	// Snapshot the product so the update can be audited
+	before, err := h.grpcClients.GetProduct(c.Request.Context(), id)
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Product not found",
+				Message: "No product exists with the given ID",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch product",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Quarantine changes in the same write, so they're never shown unreviewed
+	status := ""
+	if decision.Verdict == moderation.VerdictQuarantined {
+		status = decision.Verdict
+	}
 
 	// Call listing service via gRPC
-	product, err := h.grpcClients.UpdateProduct(c.Request.Context(), id, &req, userID.(string))
+	product, err := h.grpcClients.UpdateProduct(c.Request.Context(), id, &req, userID, status)
 	if err != nil {
 		if err == grpcclient.ErrNotFound {
 			c.JSON(http.StatusNotFound, models.ErrorResponse{
@@ -172,19 +864,262 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 		return
 	}
 
+	// Queue quarantined changes for admin review
+	if decision.Verdict == moderation.VerdictQuarantined {
+		enqueueModeration(c.Request.Context(), h.grpcClients, models.ContentTypeProduct, product.ID, userID, decision)
+	}
+
+	recordProductChange(c.Request.Context(), h.grpcClients, before, product, userID)
+	h.products.Invalidate(c.Request.Context(), id)
+
 	c.JSON(http.StatusOK, product)
 }
 
+// BulkUpdatePrices changes the prices of several products at once. If any
+// update fails, prices already changed are restored. The change can be
+// undone until the undo window closes.
+// POST /api/v1/products/prices
+func (h *ProductHandler) BulkUpdatePrices(c *gin.Context) {
+	var req models.BulkPriceUpdateRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// Snapshot current prices so the change can be rolled back or undone
+	previous := make([]models.PriceUpdate, 0, len(req.Prices))
+	for _, update := range req.Prices {
+		product, err := h.grpcClients.GetProduct(ctx, update.ProductID)
+		if err != nil {
+			if err == grpcclient.ErrNotFound {
+				c.JSON(http.StatusNotFound, models.ErrorResponse{
+					Error:   "Product not found",
+					Message: "No product exists with ID " + update.ProductID,
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Failed to fetch product",
+				Message: err.Error(),
+			})
+			return
+		}
+		previous = append(previous, models.PriceUpdate{ProductID: product.ID, Price: product.Price})
+	}
+
+	products := make([]*models.Product, 0, len(req.Prices))
+	for i, update := range req.Prices {
+		product, err := updatePrice(ctx, h.grpcClients, update, userID)
+		if err != nil {
+			if _, restoreErr := restorePrices(ctx, h.grpcClients, h.products, previous[:i], req.Prices[:i], userID); restoreErr != nil {
+				logging.FromContext(ctx).Error("Failed to roll back bulk price change", "error", restoreErr)
+			}
+			if err == grpcclient.ErrUnauthorized {
+				c.JSON(http.StatusForbidden, models.ErrorResponse{
+					Error:   "Unauthorized",
+					Message: "You don't have permission to update product " + update.ProductID,
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Failed to update prices",
+				Message: err.Error(),
+			})
+			return
+		}
+		products = append(products, product)
+	}
+
+	// Purge cached copies and, since prices are searchable, refresh the
+	// changed products' index entries
+	ids := make([]string, len(products))
+	for i, product := range products {
+		ids[i] = product.ID
+		h.products.Invalidate(ctx, product.ID)
+	}
+	reindexAfterBulkChange(ctx, h.grpcClients, h.jobs, userID, ids)
+
+	var undoInfo *models.UndoInfo
+	if action := h.newUndoAction(ctx, models.ActionPriceChange, userID); action != nil {
+		action.Prices = previous
+		action.Applied = req.Prices
+		undoInfo = h.saveUndoAction(ctx, action)
+	}
+
+	c.JSON(http.StatusOK, models.BulkPriceUpdateResponse{
+		Products: products,
+		Undo:     undoInfo,
+	})
+}
+
+// updatePrice sets one product's price, auditing the change
+func updatePrice(ctx context.Context, clients *grpcclient.Clients, update models.PriceUpdate, userID string) (*models.Product, error) {
+	before, err := clients.GetProduct(ctx, update.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	price := update.Price
+	product, err := clients.UpdateProduct(ctx, update.ProductID, &models.UpdateProductRequest{Price: &price}, userID, "")
+	if err != nil {
+		return nil, err
+	}
+	recordProductChange(ctx, clients, before, product, userID)
+	return product, nil
+}
+
+// restorePrices sets products back to earlier prices, purging each restored
+// product's cached copies. A product is only restored while it still has the
+// price applied, the matching entry of applied; products whose price was
+// changed since are skipped so later edits aren't overwritten, and their IDs
+// returned. It continues past failures and returns the first error.
+func restorePrices(ctx context.Context, clients *grpcclient.Clients, products *cache.ProductCache, prices, applied []models.PriceUpdate, userID string) ([]string, error) {
+	expected := make(map[string]float64, len(applied))
+	for _, update := range applied {
+		expected[update.ProductID] = update.Price
+	}
+
+	var skipped []string
+	var firstErr error
+	for _, update := range prices {
+		restored, err := restorePrice(ctx, clients, update, expected[update.ProductID], userID)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if !restored {
+			skipped = append(skipped, update.ProductID)
+			continue
+		}
+		products.Invalidate(ctx, update.ProductID)
+	}
+	return skipped, firstErr
+}
+
+// restorePrice sets one product back to an earlier price if its price is
+// still applied, auditing the change. It reports whether the price was
+// restored.
+func restorePrice(ctx context.Context, clients *grpcclient.Clients, update models.PriceUpdate, applied float64, userID string) (bool, error) {
+	before, err := clients.GetProduct(ctx, update.ProductID)
+	if err != nil {
+		return false, err
+	}
+	if before.Price == update.Price {
+		// Already restored, e.g. by an undo being retried
+		return true, nil
+	}
+	if before.Price != applied {
+		return false, nil
+	}
+	price := update.Price
+	product, err := clients.UpdateProduct(ctx, update.ProductID, &models.UpdateProductRequest{Price: &price}, userID, "")
+	if err != nil {
+		return false, err
+	}
+	recordProductChange(ctx, clients, before, product, userID)
+	return true, nil
+}
+
+// newUndoAction creates an undoable action, or returns nil if undo is
+// unavailable
+func (h *ProductHandler) newUndoAction(ctx context.Context, actionType, sellerID string) *models.UndoableAction {
+	if h.config.UndoWindowSec <= 0 {
+		return nil
+	}
+	action, err := undo.NewAction(actionType, sellerID, time.Duration(h.config.UndoWindowSec)*time.Second)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Failed to create undo action", "error", err)
+		return nil
+	}
+	return action
+}
+
+// saveUndoAction stores an action and returns how to undo it. The action has
+// already been applied, so failures are logged and no undo is offered.
+func (h *ProductHandler) saveUndoAction(ctx context.Context, action *models.UndoableAction) *models.UndoInfo {
+	if err := h.undo.Save(ctx, action); err != nil {
+		logging.FromContext(ctx).Warn("Failed to save undo action", "action_type", action.Type, "error", err)
+		return nil
+	}
+	return &models.UndoInfo{
+		ActionID:  action.ID,
+		ExpiresAt: action.ExpiresAt,
+	}
+}
+
+// recordProductChange writes the field-level diff of a product update to the
+// audit log. The update has already been applied, so failures are only logged.
+func recordProductChange(ctx context.Context, clients *grpcclient.Clients, before, after *models.Product, userID string) {
+	changes, err := audit.Diff(before, after, "createdAt", "updatedAt")
+	if err != nil {
+		logging.FromContext(ctx).Warn("Failed to diff product update", "product_id", after.ID, "error", err)
+		return
+	}
+	if len(changes) == 0 {
+		return
+	}
+
+	_, err = clients.RecordProductChange(ctx, &models.ProductChange{
+		ProductID: after.ID,
+		ChangedBy: userID,
+		Changes:   changes,
+		RequestID: requestid.FromContext(ctx),
+	})
+	if err != nil {
+		logging.FromContext(ctx).Warn("Failed to record product change", "product_id", after.ID, "error", err)
+	}
+}
+
+// ListProductHistory returns the audited change timeline of a product,
+// newest first
+// GET /api/v1/admin/products/:id/history
+func (h *ProductHandler) ListProductHistory(c *gin.Context) {
+	// Parse query parameters
+	page, limit := pageParams(c)
+
+	// Call listing service via gRPC
+	changes, total, err := h.grpcClients.ListProductChanges(c.Request.Context(), c.Param("id"), page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch product history",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       changes,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
 // DeleteProduct deletes a product
 // DELETE /api/v1/products/:id
 func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 	id := c.Param("id")
 
 	// Get user ID from context
-	userID, _ := c.Get("userID")
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
 
 	// Call listing service via gRPC
-	err := h.grpcClients.DeleteProduct(c.Request.Context(), id, userID.(string))
+	err := h.grpcClients.DeleteProduct(c.Request.Context(), id, userID)
 	if err != nil {
 		if err == grpcclient.ErrNotFound {
 			c.JSON(http.StatusNotFound, models.ErrorResponse{
@@ -207,8 +1142,17 @@ func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, models.SuccessResponse{
+	h.products.Invalidate(c.Request.Context(), id)
+
+	var undoInfo *models.UndoInfo
+	if action := h.newUndoAction(c.Request.Context(), models.ActionProductDelete, userID); action != nil {
+		action.ProductID = id
+		undoInfo = h.saveUndoAction(c.Request.Context(), action)
+	}
+
+	c.JSON(http.StatusOK, models.UndoableResponse{
 		Message: "Product deleted successfully",
+		Undo:    undoInfo,
 	})
 }
 
@@ -218,7 +1162,7 @@ func (h *ProductHandler) UpdateInventory(c *gin.Context) {
 	id := c.Param("id")
 
 	var req models.UpdateInventoryRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error:   "Invalid request body",
 			Message: err.Error(),
@@ -236,5 +1180,198 @@ func (h *ProductHandler) UpdateInventory(c *gin.Context) {
 		return
 	}
 
+	// Cached product responses include stock levels
+	h.products.Invalidate(c.Request.Context(), id)
+
 	c.JSON(http.StatusOK, inventory)
 }
+
+// PublishProduct publishes a draft immediately or schedules it for publish_at
+// POST /api/v1/products/:id/publish
+func (h *ProductHandler) PublishProduct(c *gin.Context) {
+	var req models.PublishProductRequest
+	if err := bindJSON(c, &req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	product, err := h.grpcClients.GetProduct(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Product not found",
+				Message: "No product exists with the given ID",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch product",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if !canManageProduct(c, h.config, product, userID) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "You don't have permission to publish this product",
+		})
+		return
+	}
+
+	if blockers := catalog.PublishBlockers(product); len(blockers) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, models.ProductIncompleteResponse{
+			Error:   "Product incomplete",
+			Message: "The product must be complete before it can be published",
+			Issues:  blockers,
+		})
+		return
+	}
+
+	status := models.ProductStatusPublished
+	var publishAt *models.Timestamp
+	if req.PublishAt != nil && req.PublishAt.After(time.Now()) {
+		status = models.ProductStatusScheduled
+		publishAt = req.PublishAt
+	}
+
+	// Call listing service via gRPC
+	if err := h.grpcClients.SetProductStatus(c.Request.Context(), product.ID, status, publishAt); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to publish product",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.products.Invalidate(c.Request.Context(), product.ID)
+
+	product.Status = status
+	product.PublishAt = publishAt
+	c.JSON(http.StatusOK, product)
+}
+
+// CreatePreviewToken issues a time-limited link for sharing an unpublished
+// product
+// POST /api/v1/products/:id/preview-token
+func (h *ProductHandler) CreatePreviewToken(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	product, err := h.grpcClients.GetProduct(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Product not found",
+				Message: "No product exists with the given ID",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch product",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if !canManageProduct(c, h.config, product, userID) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "You don't have permission to preview this product",
+		})
+		return
+	}
+
+	token, expiresAt, err := preview.Issue(h.config, product.ID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to create preview token",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.PreviewTokenResponse{
+		Token:     token,
+		URL:       h.config.PublicBaseURL + "/api/v1/products/" + url.PathEscape(product.ID) + "?" + preview.QueryParam + "=" + token,
+		ExpiresAt: models.NewTimestamp(expiresAt),
+	})
+}
+
+// negotiateLocale picks the response locale from the locale query parameter
+// or Accept-Language
+func (h *ProductHandler) negotiateLocale(c *gin.Context) string {
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	if locale := localization.Normalize(c.Query("locale")); h.localizer.Supported(locale) {
+		return locale
+	}
+	return h.localizer.Negotiate(c.GetHeader("Accept-Language"))
+}
+
+// unitSystem picks the unit system for measurements from the units query
+// parameter or the region in Accept-Language
+func unitSystem(c *gin.Context) string {
+	switch system := c.Query("units"); system {
+	case catalog.UnitSystemMetric, catalog.UnitSystemImperial:
+		return system
+	}
+	return catalog.UnitSystemFor(c.GetHeader("Accept-Language"))
+}
+
+// canPreview reports whether the caller may see an unpublished product,
+// either as its seller or with a valid preview token. Previews are marked
+// private so they aren't cached or indexed.
+func (h *ProductHandler) canPreview(c *gin.Context, product *models.Product) bool {
+	if userID, ok := middleware.GetUserID(c); ok && userID == product.SellerID {
+		return true
+	}
+
+	token := c.Query(preview.QueryParam)
+	if token == "" || preview.Verify(h.config, token, product.ID) != nil {
+		return false
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Robots-Tag", "noindex")
+	return true
+}
+
+// canManageProduct reports whether userID may publish or share a product:
+// its seller may, and so may roles granted products:manage_all. A product
+// without a seller can only be managed with products:manage_all.
+func canManageProduct(c *gin.Context, cfg *config.Config, product *models.Product, userID string) bool {
+	if product.SellerID != "" && product.SellerID == userID {
+		return true
+	}
+	return cfg.Permissions.Allows(middleware.GetRoles(c), config.PermProductsManageAll)
+}
+
+// isPubliclyVisible reports whether a product may be shown to shoppers: it
+// must be approved by moderation, or predate it, and be published. Drafts
+// are never visible, and scheduled products only once their publish time
+// has passed.
+func isPubliclyVisible(product *models.Product) bool {
+	return isApprovedContent(product.ModerationStatus) && isPublished(product, time.Now())
+}
+
+// isPublished reports whether a product is live at the given time. Scheduled
+// products go live once their publish time passes, even before the scheduler
+// has updated their status.
+func isPublished(product *models.Product, now time.Time) bool {
+	switch product.Status {
+	case models.ProductStatusDraft:
+		return false
+	case models.ProductStatusScheduled:
+		return product.PublishAt != nil && !product.PublishAt.After(now)
+	}
+	return true
+}
@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+	"github.com/ecommerce/be-api-gin/internal/moderation"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// QuestionHandler handles product Q&A requests
+type QuestionHandler struct {
+	grpcClients *grpcclient.Clients
+	moderation  *moderation.Pipeline
+}
+
+// NewQuestionHandler creates a new question handler
+func NewQuestionHandler(clients *grpcclient.Clients, pipeline *moderation.Pipeline) *QuestionHandler {
+	return &QuestionHandler{
+		grpcClients: clients,
+		moderation:  pipeline,
+	}
+}
+
+// ListQuestions returns approved questions about a product
+// GET /api/v1/products/:id/questions
+func (h *QuestionHandler) ListQuestions(c *gin.Context) {
+	productID := c.Param("id")
+
+	// Parse query parameters
+	page, limit := pageParams(c)
+
+	// Call listing service via gRPC
+	questions, total, err := h.grpcClients.ListQuestions(c.Request.Context(), productID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch questions",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       questions,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// AskQuestion submits a question about a product. The seller is notified
+// unless the question is held for moderation.
+// POST /api/v1/products/:id/questions
+func (h *QuestionHandler) AskQuestion(c *gin.Context) {
+	productID := c.Param("id")
+
+	var req models.CreateQuestionRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	product, ok := h.fetchProduct(c)
+	if !ok {
+		return
+	}
+
+	// Screen the question
+	decision := h.moderation.Screen(c.Request.Context(), map[string]string{
+		"body": req.Body,
+	})
+	if decision.Verdict == moderation.VerdictRejected {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Content rejected",
+			"message": "The question contains prohibited content",
+			"reasons": decision.Reasons,
+		})
+		return
+	}
+
+	// Call listing service via gRPC
+	question, err := h.grpcClients.CreateQuestion(c.Request.Context(), &models.Question{
+		ProductID:        productID,
+		UserID:           userID,
+		Body:             req.Body,
+		ModerationStatus: decision.Verdict,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to create question",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Queue quarantined content for admin review; otherwise tell the seller
+	if decision.Verdict == moderation.VerdictQuarantined {
+		enqueueModeration(c.Request.Context(), h.grpcClients, models.ContentTypeQuestion, question.ID, userID, decision)
+	} else if product.SellerID != "" {
+		notifyUser(c.Request.Context(), h.grpcClients, product.SellerID, &models.Notification{
+			Type:    "question_asked",
+			Title:   "New question about your product",
+			Message: "A shopper asked a question about " + product.Name,
+			Data: map[string]string{
+				"product_id":  productID,
+				"question_id": question.ID,
+			},
+		})
+	}
+
+	c.JSON(http.StatusCreated, question)
+}
+
+// ListAnswers returns approved answers to a question, most helpful first
+// GET /api/v1/products/:id/questions/:qid/answers
+func (h *QuestionHandler) ListAnswers(c *gin.Context) {
+	// Parse query parameters
+	page, limit := pageParams(c)
+
+	question, ok := h.fetchQuestion(c)
+	if !ok {
+		return
+	}
+
+	// Call listing service via gRPC
+	answers, total, err := h.grpcClients.ListAnswers(c.Request.Context(), question.ID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch answers",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       answers,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// AnswerQuestion submits an answer to a product question. Answers from the
+// product's seller are marked as such. The asker is notified unless the
+// answer is held for moderation.
+// POST /api/v1/products/:id/questions/:qid/answers
+func (h *QuestionHandler) AnswerQuestion(c *gin.Context) {
+	var req models.CreateAnswerRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	product, ok := h.fetchProduct(c)
+	if !ok {
+		return
+	}
+
+	question, ok := h.fetchQuestion(c)
+	if !ok {
+		return
+	}
+
+	// Screen the answer
+	decision := h.moderation.Screen(c.Request.Context(), map[string]string{
+		"body": req.Body,
+	})
+	if decision.Verdict == moderation.VerdictRejected {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Content rejected",
+			"message": "The answer contains prohibited content",
+			"reasons": decision.Reasons,
+		})
+		return
+	}
+
+	// Call listing service via gRPC
+	answer, err := h.grpcClients.CreateAnswer(c.Request.Context(), &models.Answer{
+		QuestionID:       question.ID,
+		UserID:           userID,
+		Body:             req.Body,
+		IsSeller:         product.SellerID != "" && product.SellerID == userID,
+		ModerationStatus: decision.Verdict,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to create answer",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Queue quarantined content for admin review; otherwise tell the asker
+	if decision.Verdict == moderation.VerdictQuarantined {
+		enqueueModeration(c.Request.Context(), h.grpcClients, models.ContentTypeAnswer, answer.ID, userID, decision)
+	} else if question.UserID != userID {
+		notifyUser(c.Request.Context(), h.grpcClients, question.UserID, &models.Notification{
+			Type:    "question_answered",
+			Title:   "Your question was answered",
+			Message: "Someone answered your question about " + product.Name,
+			Data: map[string]string{
+				"product_id":  product.ID,
+				"question_id": question.ID,
+				"answer_id":   answer.ID,
+			},
+		})
+	}
+
+	c.JSON(http.StatusCreated, answer)
+}
+
+// VoteAnswer records whether the user found an answer helpful. Each user has
+// one vote per answer; voting again replaces it.
+// POST /api/v1/products/:id/questions/:qid/answers/:aid/vote
+func (h *QuestionHandler) VoteAnswer(c *gin.Context) {
+	var req models.VoteAnswerRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	question, ok := h.fetchQuestion(c)
+	if !ok {
+		return
+	}
+
+	// Call listing service via gRPC
+	answer, err := h.grpcClients.VoteAnswer(c.Request.Context(), question.ID, c.Param("aid"), userID, req.Vote)
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Answer not found",
+				Message: "No answer exists with the given ID for this question",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to record vote",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, answer)
+}
+
+// fetchProduct loads the visible product named in the URL, writing an error
+// response if it cannot be returned
+func (h *QuestionHandler) fetchProduct(c *gin.Context) (*models.Product, bool) {
+	product, err := h.grpcClients.GetProduct(c.Request.Context(), c.Param("id"))
+	if err == nil && !isPubliclyVisible(product) {
+		err = grpcclient.ErrNotFound
+	}
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Product not found",
+				Message: "No product exists with the given ID",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch product",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	return product, true
+}
+
+// fetchQuestion loads the visible question named in the URL, writing an
+// error response if it cannot be returned
+func (h *QuestionHandler) fetchQuestion(c *gin.Context) (*models.Question, bool) {
+	question, err := h.grpcClients.GetQuestion(c.Request.Context(), c.Param("id"), c.Param("qid"))
+	if err == nil && !isApprovedContent(question.ModerationStatus) {
+		err = grpcclient.ErrNotFound
+	}
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Question not found",
+				Message: "No question exists with the given ID for this product",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch question",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	return question, true
+}
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/cache"
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	"github.com/ecommerce/be-api-gin/internal/moderation"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// ReportHandler handles abuse reports on listings and reviews
+type ReportHandler struct {
+	grpcClients *grpcclient.Clients
+	products    *cache.ProductCache
+	config      *config.Config
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(clients *grpcclient.Clients, products *cache.ProductCache, cfg *config.Config) *ReportHandler {
+	return &ReportHandler{
+		grpcClients: clients,
+		products:    products,
+		config:      cfg,
+	}
+}
+
+// ReportProduct reports a listing as abusive
+// POST /api/v1/products/:id/report
+func (h *ReportHandler) ReportProduct(c *gin.Context) {
+	h.createReport(c, models.ContentTypeProduct)
+}
+
+// ReportReview reports a review as abusive
+// POST /api/v1/reviews/:id/report
+func (h *ReportHandler) ReportReview(c *gin.Context) {
+	h.createReport(c, models.ContentTypeReview)
+}
+
+// createReport records a report on the content named in the URL. Once enough
+// distinct users have open reports, the content is taken down and queued for
+// moderation.
+func (h *ReportHandler) createReport(c *gin.Context, contentType string) {
+	contentID := c.Param("id")
+
+	var req models.CreateAbuseReportRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if !validReportReason(contentType, req.Reason) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid report reason",
+			"message": "The reason is not valid for this content type",
+			"reasons": models.ReportReasons[contentType],
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	// Call listing service via gRPC
+	report, err := h.grpcClients.CreateAbuseReport(c.Request.Context(), &models.AbuseReport{
+		ContentType: contentType,
+		ContentID:   contentID,
+		ReporterID:  userID,
+		Reason:      req.Reason,
+		Details:     req.Details,
+		Status:      models.ReportStatusOpen,
+	})
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Content not found",
+				Message: "No " + contentType + " exists with the given ID",
+			})
+			return
+		}
+		if err == grpcclient.ErrConflict {
+			c.JSON(http.StatusConflict, models.ErrorResponse{
+				Error:   "Already reported",
+				Message: "You already have an open report on this " + contentType,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to create report",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.applyTakedownThreshold(c, contentType, contentID)
+
+	c.JSON(http.StatusCreated, report)
+}
+
+// applyTakedownThreshold quarantines content when its open reports reach the
+// configured threshold. Failures are logged; the report is recorded either way.
+func (h *ReportHandler) applyTakedownThreshold(c *gin.Context, contentType, contentID string) {
+	threshold := h.config.AbuseTakedownThreshold
+	if threshold <= 0 {
+		return
+	}
+
+	ctx := c.Request.Context()
+	count, err := h.grpcClients.CountOpenAbuseReports(ctx, contentType, contentID)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Failed to count abuse reports", "content_type", contentType, "content_id", contentID, "error", err)
+		return
+	}
+	// Every report at or past the threshold applies the takedown, so reports
+	// counted concurrently can't skip past it. Quarantining is idempotent, and
+	// content already queued keeps its pending item.
+	if count < int64(threshold) {
+		return
+	}
+
+	if err := setModerationStatus(ctx, h.grpcClients, h.products, contentType, contentID, moderation.VerdictQuarantined); err != nil {
+		logging.FromContext(ctx).Warn("Failed to take down reported content", "content_type", contentType, "content_id", contentID, "error", err)
+		return
+	}
+	enqueueModeration(ctx, h.grpcClients, contentType, contentID, "", moderation.Decision{
+		Verdict: moderation.VerdictQuarantined,
+		Reasons: []string{"reported by " + strconv.FormatInt(count, 10) + " users"},
+	})
+}
+
+// ListReports returns abuse reports awaiting review
+// GET /api/v1/admin/reports
+func (h *ReportHandler) ListReports(c *gin.Context) {
+	// Parse query parameters
+	page, limit := pageParams(c)
+	status := c.DefaultQuery("status", models.ReportStatusOpen)
+	contentType := c.Query("content_type")
+
+	// Call listing service via gRPC
+	reports, total, err := h.grpcClients.ListAbuseReports(c.Request.Context(), status, contentType, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch reports",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       reports,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// ResolveReport actions or dismisses an abuse report. Actioning takes the
+// reported content down. The reporter is notified of the outcome.
+// POST /api/v1/admin/reports/:id/resolve
+func (h *ReportHandler) ResolveReport(c *gin.Context) {
+	var req models.ResolveAbuseReportRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	// Call listing service via gRPC
+	report, err := h.grpcClients.GetAbuseReport(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Report not found",
+				Message: "No abuse report exists with the given ID",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch report",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if report.Status != models.ReportStatusOpen {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Report already resolved",
+			Message: "This abuse report has already been resolved",
+		})
+		return
+	}
+
+	// Take the content down
+	if req.Decision == models.ReportStatusActioned {
+		if err := setModerationStatus(c.Request.Context(), h.grpcClients, h.products, report.ContentType, report.ContentID, moderation.VerdictRejected); err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Failed to take down content",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	now := time.Now()
+	report.Status = req.Decision
+	report.ResolvedBy = userID
+	report.ResolvedAt = models.TimestampPtr(now)
+	report.Notes = req.Notes
+
+	// Call listing service via gRPC
+	updated, err := h.grpcClients.UpdateAbuseReport(c.Request.Context(), report)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to resolve report",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Let the reporter know the outcome
+	notifyUser(c.Request.Context(), h.grpcClients, report.ReporterID, &models.Notification{
+		Type:    "report_resolved",
+		Title:   "Your report was reviewed",
+		Message: "Your report on a " + report.ContentType + " was " + req.Decision,
+		Data: map[string]string{
+			"report_id":    report.ID,
+			"content_type": report.ContentType,
+			"content_id":   report.ContentID,
+			"decision":     req.Decision,
+		},
+	})
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// validReportReason reports whether reason is in the taxonomy for contentType
+func validReportReason(contentType, reason string) bool {
+	for _, allowed := range models.ReportReasons[contentType] {
+		if allowed == reason {
+			return true
+		}
+	}
+	return false
+}
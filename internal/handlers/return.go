@@ -0,0 +1,499 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/middleware"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	"github.com/ecommerce/be-api-gin/internal/tax"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// ReturnHandler handles returns of ordered items and their refunds
+type ReturnHandler struct {
+	grpcClients *grpcclient.Clients
+	config      *config.Config
+}
+
+// NewReturnHandler creates a new return handler
+func NewReturnHandler(clients *grpcclient.Clients, cfg *config.Config) *ReturnHandler {
+	return &ReturnHandler{
+		grpcClients: clients,
+		config:      cfg,
+	}
+}
+
+// CreateReturn requests a return of items from a delivered order
+// POST /api/v1/orders/:id/returns
+func (h *ReturnHandler) CreateReturn(c *gin.Context) {
+	var req models.CreateReturnRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.grpcClients.GetOrder(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Order not found",
+				Message: "No order exists with the given ID",
+			})
+			return
+		}
+		if err == grpcclient.ErrUnauthorized {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "Unauthorized",
+				Message: "You don't have permission to return items from this order",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch order",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if order.Status != models.OrderStatusDelivered && order.Status != models.OrderStatusPartiallyShipped {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Cannot return items",
+			Message: "Items can only be returned once they have been delivered",
+		})
+		return
+	}
+
+	earlier, err := h.grpcClients.ListOrderReturns(c.Request.Context(), order.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch earlier returns",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	refundAmount, message := returnRefundAmount(order, req.Items, earlier)
+	if message != "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid return items",
+			Message: message,
+		})
+		return
+	}
+
+	// Call user service via gRPC
+	ret, err := h.grpcClients.CreateReturn(c.Request.Context(), order, &req, refundAmount)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to create return",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, ret)
+}
+
+// GetReturn returns one of the authenticated user's returns
+// GET /api/v1/returns/:id
+func (h *ReturnHandler) GetReturn(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	ret, ok := h.fetchReturn(c, userID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, ret)
+}
+
+// GetReturnAdmin returns any return for review
+// GET /api/v1/admin/returns/:id
+func (h *ReturnHandler) GetReturnAdmin(c *gin.Context) {
+	ret, ok := h.fetchManagedReturn(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, ret)
+}
+
+// ApproveReturn accepts a requested return, so the customer can send the
+// items back
+// POST /api/v1/admin/returns/:id/approve
+func (h *ReturnHandler) ApproveReturn(c *gin.Context) {
+	h.reviewReturn(c, models.ReturnStatusApproved)
+}
+
+// RejectReturn declines a requested return. The note explaining why is
+// required.
+// POST /api/v1/admin/returns/:id/reject
+func (h *ReturnHandler) RejectReturn(c *gin.Context) {
+	h.reviewReturn(c, models.ReturnStatusRejected)
+}
+
+// reviewReturn moves a requested return to approved or rejected
+func (h *ReturnHandler) reviewReturn(c *gin.Context, status string) {
+	// The body is optional when approving
+	var req models.ReviewReturnRequest
+	if c.Request.ContentLength != 0 {
+		if err := bindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid request body",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+	if status == models.ReturnStatusRejected && req.Note == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: "A note is required when rejecting a return",
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	ret, ok := h.fetchManagedReturn(c)
+	if !ok {
+		return
+	}
+
+	if ret.Status != models.ReturnStatusRequested {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Cannot review return",
+			Message: "Return can only be reviewed when in requested status",
+		})
+		return
+	}
+
+	ret.Status = status
+	ret.ReviewedBy = userID
+	ret.ReviewNote = req.Note
+	ret.ReviewedAt = models.TimestampPtr(time.Now())
+
+	updated, err := h.grpcClients.UpdateReturn(c.Request.Context(), ret)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to review return",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// CompleteReturn records that an approved return's items have arrived back,
+// restocking them
+// POST /api/v1/admin/returns/:id/complete
+func (h *ReturnHandler) CompleteReturn(c *gin.Context) {
+	ret, ok := h.fetchManagedReturn(c)
+	if !ok {
+		return
+	}
+
+	if ret.Status != models.ReturnStatusApproved {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Cannot complete return",
+			Message: "Return can only be completed when approved",
+		})
+		return
+	}
+
+	// Put the returned items back in stock
+	ctx := c.Request.Context()
+	for i, item := range ret.Items {
+		if _, err := h.grpcClients.UpdateInventory(ctx, item.ProductID, item.Quantity, models.InventoryOperationAdd); err != nil {
+			h.unstock(c, ret.Items[:i])
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Failed to restock returned items",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	ret.Status = models.ReturnStatusCompleted
+	ret.CompletedAt = models.TimestampPtr(time.Now())
+
+	updated, err := h.grpcClients.UpdateReturn(ctx, ret)
+	if err != nil {
+		// Rollback the restock
+		h.unstock(c, ret.Items)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to complete return",
+			Message: err.Error(),
+		})
+		return
+	}
+	h.markReturned(ctx, updated)
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// RefundReturn refunds an approved or completed return's amount to the
+// payment method the order was paid with. A failed refund can be retried.
+// POST /api/v1/admin/returns/:id/refund
+func (h *ReturnHandler) RefundReturn(c *gin.Context) {
+	ret, ok := h.fetchManagedReturn(c)
+	if !ok {
+		return
+	}
+
+	if ret.Status != models.ReturnStatusApproved && ret.Status != models.ReturnStatusCompleted {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Cannot refund return",
+			Message: "Return can only be refunded once approved",
+		})
+		return
+	}
+	if ret.Refund != nil && ret.Refund.Status == models.RefundStatusSucceeded {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Cannot refund return",
+			Message: "Return has already been refunded",
+		})
+		return
+	}
+
+	// Call payment service via gRPC
+	ctx := c.Request.Context()
+	payment, err := h.grpcClients.GetOrderPayment(ctx, ret.OrderID, ret.UserID)
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusConflict, models.ErrorResponse{
+				Error:   "Cannot refund return",
+				Message: "The order has no payment to refund",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch payment",
+			Message: err.Error(),
+		})
+		return
+	}
+	if payment.Status != models.PaymentStatusSucceeded {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Cannot refund return",
+			Message: "The order's payment is " + payment.Status,
+		})
+		return
+	}
+
+	refund, err := h.grpcClients.RefundPayment(ctx, payment, ret.RefundAmount, ret.ID)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, models.ErrorResponse{
+			Error:   "Refund failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ret.Refund = refund
+	updated, err := h.grpcClients.UpdateReturn(ctx, ret)
+	if err != nil {
+		// The money has moved, so the refund must not be retried blindly
+		logging.FromContext(ctx).Error("Refund issued but not recorded on return", "return_id", ret.ID, "refund_id", refund.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to record refund",
+			Message: err.Error(),
+		})
+		return
+	}
+	h.markReturned(ctx, updated)
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// markReturned moves the order's delivered items to returned once every
+// unit of them has come back in a completed or refunded return, so they
+// can't be returned again. Items without a status count as delivered once
+// their order is. The return itself has already been recorded, so
+// a failure is only logged.
+func (h *ReturnHandler) markReturned(ctx context.Context, ret *models.Return) {
+	logger := logging.FromContext(ctx).With("return_id", ret.ID, "order_id", ret.OrderID)
+	order, err := h.grpcClients.GetOrder(ctx, ret.OrderID, ret.UserID)
+	if err != nil {
+		logger.Error("Failed to fetch order to mark items returned", "error", err)
+		return
+	}
+	returns, err := h.grpcClients.ListOrderReturns(ctx, ret.OrderID)
+	if err != nil {
+		logger.Error("Failed to fetch returns to mark items returned", "error", err)
+		return
+	}
+
+	settled := make(map[string]int32)
+	for _, item := range ret.Items {
+		settled[item.ProductID] += item.Quantity
+	}
+	for _, other := range returns {
+		refunded := other.Refund != nil && other.Refund.Status == models.RefundStatusSucceeded
+		if other.ID == ret.ID || (other.Status != models.ReturnStatusCompleted && !refunded) {
+			continue
+		}
+		for _, item := range other.Items {
+			settled[item.ProductID] += item.Quantity
+		}
+	}
+
+	var updates []models.OrderItemStatusUpdate
+	for _, item := range order.Items {
+		delivered := item.Status == models.OrderItemStatusDelivered || (item.Status == "" && order.Status == models.OrderStatusDelivered)
+		if delivered && settled[item.ProductID] >= item.Quantity {
+			updates = append(updates, models.OrderItemStatusUpdate{ProductID: item.ProductID, Status: models.OrderItemStatusReturned})
+		}
+	}
+	if len(updates) == 0 {
+		return
+	}
+	if _, err := h.grpcClients.UpdateOrderItemStatuses(ctx, order, updates, order.Status); err != nil {
+		logger.Error("Failed to mark returned items", "error", err)
+	}
+}
+
+// unstock reverses the restock of items, best effort
+func (h *ReturnHandler) unstock(c *gin.Context, items []models.ReturnItem) {
+	for _, item := range items {
+		h.grpcClients.UpdateInventory(c.Request.Context(), item.ProductID, item.Quantity, models.InventoryOperationSubtract)
+	}
+}
+
+// fetchReturn loads the return named by the :id path parameter, restricted
+// to userID's returns unless it is empty, responding with an error if it
+// cannot be fetched
+func (h *ReturnHandler) fetchReturn(c *gin.Context, userID string) (*models.Return, bool) {
+	ret, err := h.grpcClients.GetReturn(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Return not found",
+				Message: "No return exists with the given ID",
+			})
+			return nil, false
+		}
+		if err == grpcclient.ErrUnauthorized {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "Unauthorized",
+				Message: "You don't have permission to view this return",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch return",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	return ret, true
+}
+
+// fetchManagedReturn loads the return named by the :id path parameter for
+// review. Roles granted returns:manage_all manage every return; anyone else,
+// such as a seller, only those of their own products, and others are
+// reported as not found.
+func (h *ReturnHandler) fetchManagedReturn(c *gin.Context) (*models.Return, bool) {
+	ret, ok := h.fetchReturn(c, "")
+	if !ok {
+		return nil, false
+	}
+	if h.config.Permissions.Allows(middleware.GetRoles(c), config.PermReturnsManageAll) {
+		return ret, true
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return nil, false
+	}
+	for _, item := range ret.Items {
+		product, err := h.grpcClients.GetProduct(c.Request.Context(), item.ProductID)
+		if err != nil && err != grpcclient.ErrNotFound {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Failed to fetch product",
+				Message: err.Error(),
+			})
+			return nil, false
+		}
+		if product == nil || product.SellerID == "" || product.SellerID != userID {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Return not found",
+				Message: "No return of your products exists with the given ID",
+			})
+			return nil, false
+		}
+	}
+	return ret, true
+}
+
+// returnRefundAmount checks that the items being returned were ordered and
+// delivered, in at most the ordered quantities less those already returned
+// in earlier returns that weren't rejected, and totals what they were paid for:
+// their share of each line after the order's discount, spread as it was for
+// tax, and of the line's tax. It returns a message describing the first
+// problem found.
+func returnRefundAmount(order *models.Order, items []models.ReturnItem, earlier []*models.Return) (float64, string) {
+	amounts := make([]float64, len(order.Items))
+	for i, item := range order.Items {
+		amounts[i] = item.TotalPrice
+	}
+	net := tax.SpreadDiscount(amounts, order.Discount)
+	ordered := make(map[string]int, len(order.Items))
+	for i, item := range order.Items {
+		ordered[item.ProductID] = i
+	}
+
+	returned := make(map[string]int32, len(items))
+	for _, ret := range earlier {
+		if ret.Status == models.ReturnStatusRejected {
+			continue
+		}
+		for _, item := range ret.Items {
+			returned[item.ProductID] += item.Quantity
+		}
+	}
+
+	var amount float64
+	for _, item := range items {
+		i, ok := ordered[item.ProductID]
+		if !ok {
+			return 0, "Product " + item.ProductID + " is not in the order"
+		}
+		orderItem := order.Items[i]
+		if orderItem.Status != models.OrderItemStatusDelivered && (orderItem.Status != "" || order.Status != models.OrderStatusDelivered) {
+			return 0, "Product " + item.ProductID + " has not been delivered or was already returned"
+		}
+		returned[item.ProductID] += item.Quantity
+		if returned[item.ProductID] > orderItem.Quantity {
+			return 0, "More of product " + item.ProductID + " is being returned than was ordered and not already returned"
+		}
+		share := float64(item.Quantity) / float64(orderItem.Quantity)
+		amount += net[i] * share
+		if orderItem.Tax > 0 {
+			amount += orderItem.Tax * share
+		}
+	}
+	return math.Round(amount*100) / 100, ""
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+func TestReturnRefundAmount(t *testing.T) {
+	// The $4 discount is spread $2 to each line, so line a was paid $18 plus
+	// its $2 tax, and line b $18
+	order := &models.Order{
+		Status:   models.OrderStatusPartiallyShipped,
+		Discount: 4,
+		Items: []models.OrderItem{
+			{ProductID: "a", Quantity: 2, TotalPrice: 20, Tax: 2, Status: models.OrderItemStatusDelivered},
+			{ProductID: "b", Quantity: 1, TotalPrice: 20, Status: models.OrderItemStatusShipped},
+		},
+	}
+	// Items of orders placed before items had statuses follow the order
+	legacy := &models.Order{
+		Status: models.OrderStatusDelivered,
+		Items: []models.OrderItem{
+			{ProductID: "a", Quantity: 1, TotalPrice: 15, Tax: 1.5},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		order       *models.Order
+		items       []models.ReturnItem
+		earlier     []*models.Return
+		wantAmount  float64
+		wantProblem string
+	}{
+		{
+			name:       "part of a line",
+			order:      order,
+			items:      []models.ReturnItem{{ProductID: "a", Quantity: 1}},
+			wantAmount: 10,
+		},
+		{
+			name:       "whole line",
+			order:      order,
+			items:      []models.ReturnItem{{ProductID: "a", Quantity: 2}},
+			wantAmount: 20,
+		},
+		{
+			name:        "item not delivered",
+			order:       order,
+			items:       []models.ReturnItem{{ProductID: "b", Quantity: 1}},
+			wantProblem: "Product b has not been delivered or was already returned",
+		},
+		{
+			name:        "item not ordered",
+			order:       order,
+			items:       []models.ReturnItem{{ProductID: "c", Quantity: 1}},
+			wantProblem: "Product c is not in the order",
+		},
+		{
+			name:        "more than ordered",
+			order:       order,
+			items:       []models.ReturnItem{{ProductID: "a", Quantity: 3}},
+			wantProblem: "More of product a is being returned than was ordered and not already returned",
+		},
+		{
+			name:  "already returned",
+			order: order,
+			items: []models.ReturnItem{{ProductID: "a", Quantity: 2}},
+			earlier: []*models.Return{
+				{Status: models.ReturnStatusRequested, Items: []models.ReturnItem{{ProductID: "a", Quantity: 1}}},
+			},
+			wantProblem: "More of product a is being returned than was ordered and not already returned",
+		},
+		{
+			name:  "rejected returns don't count",
+			order: order,
+			items: []models.ReturnItem{{ProductID: "a", Quantity: 2}},
+			earlier: []*models.Return{
+				{Status: models.ReturnStatusRejected, Items: []models.ReturnItem{{ProductID: "a", Quantity: 2}}},
+			},
+			wantAmount: 20,
+		},
+		{
+			name:       "item without a status of a delivered order",
+			order:      legacy,
+			items:      []models.ReturnItem{{ProductID: "a", Quantity: 1}},
+			wantAmount: 16.5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, problem := returnRefundAmount(tt.order, tt.items, tt.earlier)
+			if problem != tt.wantProblem {
+				t.Fatalf("returnRefundAmount() problem = %q, want %q", problem, tt.wantProblem)
+			}
+			if amount != tt.wantAmount {
+				t.Errorf("returnRefundAmount() = %v, want %v", amount, tt.wantAmount)
+			}
+		})
+	}
+}
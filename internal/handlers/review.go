@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+	"github.com/ecommerce/be-api-gin/internal/moderation"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// ReviewHandler handles product review requests
+type ReviewHandler struct {
+	grpcClients *grpcclient.Clients
+	moderation  *moderation.Pipeline
+}
+
+// NewReviewHandler creates a new review handler
+func NewReviewHandler(clients *grpcclient.Clients, pipeline *moderation.Pipeline) *ReviewHandler {
+	return &ReviewHandler{
+		grpcClients: clients,
+		moderation:  pipeline,
+	}
+}
+
+// ListReviews returns approved reviews for a product
+// GET /api/v1/products/:id/reviews
+func (h *ReviewHandler) ListReviews(c *gin.Context) {
+	productID := c.Param("id")
+
+	// Parse query parameters
+	page, limit := pageParams(c)
+
+	// Call listing service via gRPC
+	reviews, total, err := h.grpcClients.ListReviews(c.Request.Context(), productID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch reviews",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       visibleReviews(reviews),
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// CreateReview submits a review for a product. Reviews are screened before
+// publishing and may be rejected or held for moderation.
+// POST /api/v1/products/:id/reviews
+func (h *ReviewHandler) CreateReview(c *gin.Context) {
+	productID := c.Param("id")
+
+	var req models.CreateReviewRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	// Screen title and body
+	decision := h.moderation.Screen(c.Request.Context(), map[string]string{
+		"title": req.Title,
+		"body":  req.Body,
+	})
+	if decision.Verdict == moderation.VerdictRejected {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Content rejected",
+			"message": "The review contains prohibited content",
+			"reasons": decision.Reasons,
+		})
+		return
+	}
+
+	// Call listing service via gRPC
+	review, err := h.grpcClients.CreateReview(c.Request.Context(), &models.Review{
+		ProductID:        productID,
+		UserID:           userID,
+		Rating:           req.Rating,
+		Title:            req.Title,
+		Body:             req.Body,
+		ModerationStatus: decision.Verdict,
+	})
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Product not found",
+				Message: "No product exists with the given ID",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to create review",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Queue quarantined content for admin review
+	if decision.Verdict == moderation.VerdictQuarantined {
+		enqueueModeration(c.Request.Context(), h.grpcClients, models.ContentTypeReview, review.ID, userID, decision)
+	}
+
+	c.JSON(http.StatusCreated, review)
+}
+
+// RespondToReview publishes the seller's reply to a review on one of their
+// products, replacing any earlier reply. The reviewer is notified unless the
+// reply is held for moderation.
+// POST /api/v1/products/:id/reviews/:rid/response
+func (h *ReviewHandler) RespondToReview(c *gin.Context) {
+	productID := c.Param("id")
+	reviewID := c.Param("rid")
+
+	var req models.CreateReviewResponseRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	// Screen the reply
+	decision := h.moderation.Screen(c.Request.Context(), map[string]string{
+		"body": req.Body,
+	})
+	if decision.Verdict == moderation.VerdictRejected {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Content rejected",
+			"message": "The response contains prohibited content",
+			"reasons": decision.Reasons,
+		})
+		return
+	}
+
+	// Call listing service via gRPC
+	review, err := h.grpcClients.SetReviewResponse(c.Request.Context(), productID, reviewID, userID, &models.ReviewResponse{
+		SellerID:         userID,
+		Body:             req.Body,
+		ModerationStatus: decision.Verdict,
+	})
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Review not found",
+				Message: "No review exists with the given ID for this product",
+			})
+			return
+		}
+		if err == grpcclient.ErrUnauthorized {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "Unauthorized",
+				Message: "Only the product's seller can respond to its reviews",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to respond to review",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Queue quarantined content for admin review; otherwise tell the reviewer
+	if decision.Verdict == moderation.VerdictQuarantined {
+		enqueueModeration(c.Request.Context(), h.grpcClients, models.ContentTypeReviewResponse, review.ID, userID, decision)
+	} else {
+		notifyUser(c.Request.Context(), h.grpcClients, review.UserID, &models.Notification{
+			Type:    "review_response",
+			Title:   "The seller responded to your review",
+			Message: "The seller replied to your review",
+			Data: map[string]string{
+				"product_id": productID,
+				"review_id":  review.ID,
+			},
+		})
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+// isApprovedContent reports whether moderation allows showing user content
+// publicly. Content predating moderation has no status and is visible.
+func isApprovedContent(status string) bool {
+	return status == "" || status == moderation.VerdictApproved
+}
+
+// visibleReviews hides reviews and seller responses withheld by moderation
+func visibleReviews(reviews []*models.Review) []*models.Review {
+	visible := make([]*models.Review, 0, len(reviews))
+	for _, review := range reviews {
+		if isApprovedContent(review.ModerationStatus) {
+			if review.Response != nil && !isApprovedContent(review.Response.ModerationStatus) {
+				review.Response = nil
+			}
+			visible = append(visible, review)
+		}
+	}
+	return visible
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+	"github.com/ecommerce/be-api-gin/internal/risk"
+)
+
+// RiskHandler exposes account risk scores to admins
+type RiskHandler struct {
+	scorer *risk.Scorer
+}
+
+// NewRiskHandler creates a new risk handler
+func NewRiskHandler(scorer *risk.Scorer) *RiskHandler {
+	return &RiskHandler{
+		scorer: scorer,
+	}
+}
+
+// ListRiskyAccounts returns tracked accounts at or above a minimum score,
+// highest first
+// GET /api/v1/admin/risk/accounts
+func (h *RiskHandler) ListRiskyAccounts(c *gin.Context) {
+	minScore, err := strconv.Atoi(c.DefaultQuery("min_score", "1"))
+	if err != nil || minScore < 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid min_score",
+			Message: "min_score must be a non-negative integer",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"accounts": h.scorer.Accounts(c.Request.Context(), minScore),
+	})
+}
+
+// GetAccountRisk returns an account's current risk score and signals
+// GET /api/v1/admin/risk/accounts/:id
+func (h *RiskHandler) GetAccountRisk(c *gin.Context) {
+	c.JSON(http.StatusOK, h.scorer.Score(c.Request.Context(), c.Param("id")))
+}
+
+// ResetAccountRisk clears the gateway-observed signals for an account after
+// a manual review
+// POST /api/v1/admin/risk/accounts/:id/reset
+func (h *RiskHandler) ResetAccountRisk(c *gin.Context) {
+	userID := c.Param("id")
+	h.scorer.Reset(userID)
+
+	c.JSON(http.StatusOK, h.scorer.Score(c.Request.Context(), userID))
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/jobs"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// reindexBatchSize is how many products are sent to the search index at once
+const reindexBatchSize = 100
+
+// SearchHandler handles search index maintenance
+type SearchHandler struct {
+	grpcClients *grpcclient.Clients
+	jobs        *jobs.Runner
+}
+
+// NewSearchHandler creates a new search handler
+func NewSearchHandler(clients *grpcclient.Clients, runner *jobs.Runner) *SearchHandler {
+	return &SearchHandler{
+		grpcClients: clients,
+		jobs:        runner,
+	}
+}
+
+// Reindex starts a job rebuilding the search index for the given products,
+// or for the whole catalog when none are given
+// POST /api/v1/admin/search/reindex
+func (h *SearchHandler) Reindex(c *gin.Context) {
+	var req models.ReindexRequest
+	if err := bindJSON(c, &req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	job, err := startReindex(c.Request.Context(), h.grpcClients, h.jobs, userID, req.ProductIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to start reindex",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.Header("Location", "/api/v1/admin/jobs/"+job.ID)
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetJob reports a background job's progress
+// GET /api/v1/admin/jobs/:id
+func (h *SearchHandler) GetJob(c *gin.Context) {
+	job, err := h.jobs.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch job",
+			Message: err.Error(),
+		})
+		return
+	}
+	if job == nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Job not found",
+			Message: "No job exists with the given ID",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// startReindex starts a reindex job for the given products, or the whole
+// catalog when productIDs is empty
+func startReindex(ctx context.Context, clients *grpcclient.Clients, runner *jobs.Runner, userID string, productIDs []string) (*models.Job, error) {
+	params := map[string]string{"scope": "full"}
+	if len(productIDs) > 0 {
+		params = map[string]string{
+			"scope":       "products",
+			"product_ids": strings.Join(productIDs, ","),
+		}
+	}
+
+	return runner.Start(ctx, models.JobTypeSearchReindex, userID, params, func(ctx context.Context, progress *jobs.Progress) error {
+		if len(productIDs) > 0 {
+			progress.SetTotal(len(productIDs))
+			for start := 0; start < len(productIDs); start += reindexBatchSize {
+				end := min(start+reindexBatchSize, len(productIDs))
+				reindexBatch(ctx, clients, progress, productIDs[start:end])
+			}
+			return nil
+		}
+
+		// Page through the whole catalog
+		for page := 1; ; page++ {
+			ids, total, err := clients.ListProductIDs(ctx, page, reindexBatchSize)
+			if err != nil {
+				return err
+			}
+			if page == 1 {
+				progress.SetTotal(int(total))
+			}
+			if len(ids) == 0 {
+				return nil
+			}
+			reindexBatch(ctx, clients, progress, ids)
+		}
+	})
+}
+
+// reindexBatch sends one batch to the search index, counting a failed batch
+// against the job rather than stopping it
+func reindexBatch(ctx context.Context, clients *grpcclient.Clients, progress *jobs.Progress, ids []string) {
+	if err := clients.ReindexProducts(ctx, ids); err != nil {
+		logging.FromContext(ctx).Warn("Failed to reindex products", "count", len(ids), "first_product_id", ids[0], "error", err)
+		progress.Add(0, len(ids))
+		return
+	}
+	progress.Add(len(ids), 0)
+}
+
+// reindexAfterBulkChange keeps the search index in step with a bulk catalog
+// change, logging rather than failing if the job can't be started
+func reindexAfterBulkChange(ctx context.Context, clients *grpcclient.Clients, runner *jobs.Runner, userID string, productIDs []string) {
+	if len(productIDs) == 0 {
+		return
+	}
+	if _, err := startReindex(ctx, clients, runner, userID, productIDs); err != nil {
+		logging.FromContext(ctx).Warn("Failed to start reindex after bulk change", "count", len(productIDs), "error", err)
+	}
+}
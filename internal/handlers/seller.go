@@ -0,0 +1,500 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/cache"
+	"github.com/ecommerce/be-api-gin/internal/catalog"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// SellerHandler handles seller-facing requests
+type SellerHandler struct {
+	grpcClients *grpcclient.Clients
+	products    *cache.ProductCache
+}
+
+// NewSellerHandler creates a new seller handler
+func NewSellerHandler(clients *grpcclient.Clients, products *cache.ProductCache) *SellerHandler {
+	return &SellerHandler{
+		grpcClients: clients,
+		products:    products,
+	}
+}
+
+// GetInventoryForecast projects days of stock remaining for each of the
+// seller's SKUs and suggests reorder points
+// GET /api/v1/sellers/me/inventory/forecast
+func (h *SellerHandler) GetInventoryForecast(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	// Parse query parameters
+	windowDays, _ := strconv.Atoi(c.DefaultQuery("window_days", "30"))
+	leadTimeDays, _ := strconv.Atoi(c.DefaultQuery("lead_time_days", "7"))
+	safetyStockDays, _ := strconv.Atoi(c.DefaultQuery("safety_stock_days", "3"))
+	if windowDays <= 0 || leadTimeDays < 0 || safetyStockDays < 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid query parameters",
+			Message: "window_days must be positive and lead_time_days and safety_stock_days must not be negative",
+		})
+		return
+	}
+
+	// Call inventory service via gRPC
+	velocities, err := h.grpcClients.ListSalesVelocity(c.Request.Context(), userID, windowDays)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch sales velocity",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	items := make([]*models.InventoryForecast, 0, len(velocities))
+	for _, v := range velocities {
+		items = append(items, forecastInventory(v, leadTimeDays, safetyStockDays))
+	}
+	items, truncated := truncateItems(c, items)
+
+	c.JSON(http.StatusOK, models.InventoryForecastResponse{
+		SellerID:        userID,
+		WindowDays:      windowDays,
+		LeadTimeDays:    leadTimeDays,
+		SafetyStockDays: safetyStockDays,
+		Items:           items,
+		Truncated:       truncated,
+	})
+}
+
+// ListProducts returns the seller's own products, including drafts and
+// scheduled listings. Pass ?status= to filter by publishing status.
+// GET /api/v1/sellers/me/products
+func (h *SellerHandler) ListProducts(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	// Call listing service via gRPC
+	products, err := h.grpcClients.ListSellerProducts(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch products",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Optionally filter by publishing status
+	if status := c.Query("status"); status != "" {
+		filtered := products[:0]
+		for _, product := range products {
+			productStatus := product.Status
+			if productStatus == "" {
+				productStatus = models.ProductStatusPublished
+			}
+			if productStatus == status {
+				filtered = append(filtered, product)
+			}
+		}
+		products = filtered
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"products": products,
+		"total":    len(products),
+	})
+}
+
+// GetCatalogIssues scans the seller's products for quality problems
+// GET /api/v1/sellers/me/catalog/issues
+func (h *SellerHandler) GetCatalogIssues(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	// Call listing service via gRPC
+	products, err := h.grpcClients.ListSellerProducts(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch products",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	issues := catalog.Lint(products)
+
+	// Optionally filter by severity
+	if severity := c.Query("severity"); severity != "" {
+		filtered := issues[:0]
+		for _, issue := range issues {
+			if issue.Severity == severity {
+				filtered = append(filtered, issue)
+			}
+		}
+		issues = filtered
+	}
+
+	summary := map[string]int{
+		catalog.SeverityError:   0,
+		catalog.SeverityWarning: 0,
+		catalog.SeverityInfo:    0,
+	}
+	for _, issue := range issues {
+		summary[issue.Severity]++
+	}
+
+	if issues == nil {
+		issues = []models.CatalogIssue{}
+	}
+	// The summary still counts every issue when the list is truncated
+	issues, truncated := truncateItems(c, issues)
+
+	c.JSON(http.StatusOK, models.CatalogIssuesResponse{
+		SellerID:        userID,
+		ProductsScanned: len(products),
+		Summary:         summary,
+		Issues:          issues,
+		Truncated:       truncated,
+	})
+}
+
+// GetVacation returns the seller's current or upcoming vacation
+// GET /api/v1/sellers/me/vacation
+func (h *SellerHandler) GetVacation(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	// Call listing service via gRPC
+	vacation, err := h.grpcClients.GetSellerVacation(c.Request.Context(), userID)
+	now := time.Now()
+	if err == grpcclient.ErrNotFound || (err == nil && !now.Before(vacation.EndsAt.Time)) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "No vacation scheduled",
+			Message: "Set a vacation with PUT /sellers/me/vacation",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch vacation",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	vacation.Active = vacationActive(vacation, now)
+	c.JSON(http.StatusOK, vacation)
+}
+
+// SetVacation schedules the seller's vacation, replacing any already set.
+// While it lasts their products are shown as unavailable and can't be
+// ordered; it ends by itself at ends_at.
+// PUT /api/v1/sellers/me/vacation
+func (h *SellerHandler) SetVacation(c *gin.Context) {
+	var req models.SellerVacationRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	startsAt := models.NewTimestamp(now)
+	if req.StartsAt != nil {
+		startsAt = *req.StartsAt
+	}
+	if !req.EndsAt.After(startsAt.Time) || !req.EndsAt.After(now) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid vacation",
+			Message: "ends_at must be in the future and after starts_at",
+		})
+		return
+	}
+
+	// Call listing service via gRPC
+	vacation, err := h.grpcClients.SetSellerVacation(c.Request.Context(), &models.SellerVacation{
+		SellerID: userID,
+		StartsAt: startsAt,
+		EndsAt:   req.EndsAt,
+		Message:  req.Message,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to set vacation",
+			Message: err.Error(),
+		})
+		return
+	}
+	h.invalidateProducts(c.Request.Context(), userID)
+
+	vacation.Active = vacationActive(vacation, now)
+	c.JSON(http.StatusOK, vacation)
+}
+
+// EndVacation ends the seller's vacation now, or cancels an upcoming one
+// DELETE /api/v1/sellers/me/vacation
+func (h *SellerHandler) EndVacation(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	// Call listing service via gRPC
+	if err := h.grpcClients.DeleteSellerVacation(c.Request.Context(), userID); err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "No vacation scheduled",
+				Message: "There is no vacation to end",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to end vacation",
+			Message: err.Error(),
+		})
+		return
+	}
+	h.invalidateProducts(c.Request.Context(), userID)
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListOrders returns the orders holding the seller's items: orders from
+// them alone, and their sub-orders of orders split across sellers
+// GET /api/v1/sellers/me/orders
+func (h *SellerHandler) ListOrders(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	page, limit := pageParams(c)
+	var status models.OrderStatus
+	if s := c.Query("status"); s != "" {
+		parsed, err := models.ParseOrderStatus(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid status",
+				Message: err.Error(),
+			})
+			return
+		}
+		status = parsed
+	}
+
+	// Call order service via gRPC
+	orders, total, err := h.grpcClients.ListSellerOrders(c.Request.Context(), userID, page, limit, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch orders",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       orders,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// GetOrder returns one of the seller's orders or sub-orders
+// GET /api/v1/sellers/me/orders/:id
+func (h *SellerHandler) GetOrder(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	// Call order service via gRPC
+	order, err := h.grpcClients.GetSellerOrder(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		respondSellerOrderError(c, err, "Failed to fetch order")
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// UpdateOrderStatus moves one of the seller's orders or sub-orders along as
+// they fulfill it, independently of the other sellers in a split order
+// PUT /api/v1/sellers/me/orders/:id/status
+func (h *SellerHandler) UpdateOrderStatus(c *gin.Context) {
+	var req models.SellerOrderStatusRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	// Orders are only fulfilled once paid for and while not cancelled
+	current, err := h.grpcClients.GetSellerOrder(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		respondSellerOrderError(c, err, "Failed to fetch order")
+		return
+	}
+	if current.Status == models.OrderStatusPending || current.Status == models.OrderStatusCancelled {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Order not ready to fulfill",
+			Message: "The order is " + string(current.Status) + "; only confirmed orders are fulfilled",
+		})
+		return
+	}
+
+	// Call order service via gRPC
+	order, err := h.grpcClients.UpdateSellerOrderStatus(c.Request.Context(), c.Param("id"), userID, req.Status)
+	if err != nil {
+		respondSellerOrderError(c, err, "Failed to update order status")
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// respondSellerOrderError responds to a failed call for one of a seller's
+// orders. Orders of other sellers are reported as not found.
+func respondSellerOrderError(c *gin.Context, err error, title string) {
+	if err == grpcclient.ErrNotFound || err == grpcclient.ErrUnauthorized {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Order not found",
+			Message: "No order of yours exists with the given ID",
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		Error:   title,
+		Message: err.Error(),
+	})
+}
+
+// invalidateProducts purges the seller's products from the caches so their
+// availability reflects a changed vacation. Vacations starting or ending
+// on schedule are picked up as cached responses expire.
+func (h *SellerHandler) invalidateProducts(ctx context.Context, sellerID string) {
+	products, err := h.grpcClients.ListSellerProducts(ctx, sellerID)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Failed to list products to invalidate", "seller_id", sellerID, "error", err)
+		return
+	}
+	for _, product := range products {
+		h.products.Invalidate(ctx, product.ID)
+	}
+}
+
+// vacationActive reports whether a vacation covers now
+func vacationActive(vacation *models.SellerVacation, now time.Time) bool {
+	return !now.Before(vacation.StartsAt.Time) && now.Before(vacation.EndsAt.Time)
+}
+
+// sellerVacations looks up which sellers are on vacation, asking the
+// listing service about each seller once
+type sellerVacations struct {
+	clients *grpcclient.Clients
+	now     time.Time
+	until   map[string]*models.Timestamp
+}
+
+// newSellerVacations creates a lookup of sellers on vacation now
+func newSellerVacations(clients *grpcclient.Clients) *sellerVacations {
+	return &sellerVacations{
+		clients: clients,
+		now:     time.Now(),
+		until:   make(map[string]*models.Timestamp),
+	}
+}
+
+// Until returns when the seller's current vacation ends, or nil if they
+// aren't on vacation. Sellers whose vacation can't be checked are treated
+// as not on vacation, so an outage of the listing service doesn't stop
+// sales.
+func (v *sellerVacations) Until(ctx context.Context, sellerID string) *models.Timestamp {
+	if sellerID == "" {
+		return nil
+	}
+	if until, ok := v.until[sellerID]; ok {
+		return until
+	}
+
+	var until *models.Timestamp
+	vacation, err := v.clients.GetSellerVacation(ctx, sellerID)
+	switch {
+	case err == nil:
+		if vacationActive(vacation, v.now) {
+			until = &vacation.EndsAt
+		}
+	case err != grpcclient.ErrNotFound:
+		logging.FromContext(ctx).Warn("Failed to check seller vacation", "seller_id", sellerID, "error", err)
+	}
+	v.until[sellerID] = until
+	return until
+}
+
+// Apply marks the product unavailable if its seller is on vacation
+func (v *sellerVacations) Apply(ctx context.Context, product *models.Product) {
+	if until := v.Until(ctx, product.SellerID); until != nil {
+		product.VacationUntil = until
+		product.Available = false
+	}
+}
+
+// forecastInventory computes the stock projection for a single SKU. The
+// reorder point covers demand over the supplier lead time plus safety stock,
+// and the suggested quantity restocks up to twice that level.
+func forecastInventory(v *models.SalesVelocity, leadTimeDays, safetyStockDays int) *models.InventoryForecast {
+	forecast := &models.InventoryForecast{
+		ProductID:     v.ProductID,
+		ProductName:   v.ProductName,
+		CurrentStock:  v.CurrentStock,
+		DailyVelocity: v.DailyVelocity,
+	}
+
+	// Without sales there is nothing to project
+	if v.DailyVelocity <= 0 {
+		return forecast
+	}
+
+	days := float64(v.CurrentStock) / v.DailyVelocity
+	forecast.DaysOfStockRemaining = &days
+
+	reorderPoint := int32(math.Ceil(v.DailyVelocity * float64(leadTimeDays+safetyStockDays)))
+	forecast.ReorderPoint = reorderPoint
+	forecast.ShouldReorder = v.CurrentStock <= reorderPoint
+	if forecast.ShouldReorder {
+		forecast.SuggestedReorderQty = 2*reorderPoint - v.CurrentStock
+	}
+
+	return forecast
+}
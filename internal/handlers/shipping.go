@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/cart"
+	"github.com/ecommerce/be-api-gin/internal/middleware"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	"github.com/ecommerce/be-api-gin/internal/shipping"
+	"github.com/ecommerce/be-api-gin/internal/tax"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// ShippingHandler quotes shipping for carts
+type ShippingHandler struct {
+	grpcClients *grpcclient.Clients
+	carts       cart.Store
+	quoter      *shipping.Quoter
+}
+
+// NewShippingHandler creates a new shipping handler
+func NewShippingHandler(clients *grpcclient.Clients, carts cart.Store, quoter *shipping.Quoter) *ShippingHandler {
+	return &ShippingHandler{
+		grpcClients: clients,
+		carts:       carts,
+		quoter:      quoter,
+	}
+}
+
+// QuoteShipping returns the shipping methods available for the caller's
+// cart to the given or saved address, with their prices and expected
+// delivery dates. Guests name their cart with the X-Cart-Session header.
+// POST /api/v1/shipping/quotes
+func (h *ShippingHandler) QuoteShipping(c *gin.Context) {
+	var req models.ShippingQuoteRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var owner string
+	userID, signedIn := middleware.GetUserID(c)
+	if signedIn {
+		owner = cart.UserOwner(userID)
+	} else {
+		if req.AddressID != "" {
+			requireUserID(c)
+			return
+		}
+		sessionID, ok := requireCartSession(c)
+		if !ok {
+			return
+		}
+		owner = cart.GuestOwner(sessionID)
+	}
+
+	addr := req.ShippingAddr
+	if req.AddressID != "" {
+		saved, err := h.grpcClients.GetAddress(c.Request.Context(), req.AddressID, userID)
+		if err != nil {
+			if err == grpcclient.ErrNotFound || err == grpcclient.ErrUnauthorized {
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{
+					Error:   "Address not found",
+					Message: "Address " + req.AddressID + " is not one of your saved addresses",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Failed to fetch address",
+				Message: err.Error(),
+			})
+			return
+		}
+		addr = &saved.Address
+	}
+
+	sc, err := h.carts.Get(c.Request.Context(), owner)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch cart",
+			Message: err.Error(),
+		})
+		return
+	}
+	if sc == nil || len(sc.Items) == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Cart is empty",
+			Message: "Add items to the cart before quoting shipping",
+		})
+		return
+	}
+
+	// Quote on what is paid after the cart's coupon
+	amounts := make([]float64, len(sc.Items))
+	for i, item := range sc.Items {
+		amounts[i] = item.LineTotal
+	}
+	net := tax.SpreadDiscount(amounts, sc.Discount)
+	items := make([]shipping.Item, len(sc.Items))
+	for i, item := range sc.Items {
+		items[i] = shipping.Item{
+			ProductID: item.ProductID,
+			SellerID:  item.SellerID,
+			Quantity:  item.Quantity,
+			Amount:    net[i],
+		}
+	}
+
+	quotes, err := h.quoter.Quote(c.Request.Context(), *addr, items, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadGateway, models.ErrorResponse{
+			Error:   "Failed to quote shipping",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, quotes)
+}
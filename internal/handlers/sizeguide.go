@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/cache"
+	"github.com/ecommerce/be-api-gin/internal/catalog"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// SizeGuideHandler handles size guides and reviewers' fit feedback
+type SizeGuideHandler struct {
+	grpcClients *grpcclient.Clients
+	responses   cache.Store // cached product responses, nil if not cached
+}
+
+// NewSizeGuideHandler creates a new size guide handler. Fit feedback purges
+// the product's cached responses from responses, since they carry its fit
+// hint.
+func NewSizeGuideHandler(clients *grpcclient.Clients, responses cache.Store) *SizeGuideHandler {
+	return &SizeGuideHandler{
+		grpcClients: clients,
+		responses:   responses,
+	}
+}
+
+// GetSizeGuide returns a product's size guide, or its category's if it has
+// none of its own
+// GET /api/v1/products/:id/size-guide
+func (h *SizeGuideHandler) GetSizeGuide(c *gin.Context) {
+	product, ok := h.visibleProduct(c)
+	if !ok {
+		return
+	}
+
+	// Call listing service via gRPC
+	guide, err := h.grpcClients.GetProductSizeGuide(c.Request.Context(), product.ID)
+	if err == grpcclient.ErrNotFound && models.Category(product.Category).Valid() {
+		guide, err = h.grpcClients.GetCategorySizeGuide(c.Request.Context(), models.Category(product.Category))
+	}
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Size guide not found",
+				Message: "Neither the product nor its category has a size guide",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch size guide",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, guide)
+}
+
+// SetProductSizeGuide replaces the size guide of one of the seller's
+// products, which takes precedence over its category's
+// PUT /api/v1/products/:id/size-guide
+func (h *SizeGuideHandler) SetProductSizeGuide(c *gin.Context) {
+	guide, ok := bindSizeGuide(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	guide.ProductID = c.Param("id")
+
+	// Call listing service via gRPC
+	guide, err := h.grpcClients.SetProductSizeGuide(c.Request.Context(), userID, guide)
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Product not found",
+				Message: "No product exists with the given ID",
+			})
+			return
+		}
+		if err == grpcclient.ErrUnauthorized {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "Unauthorized",
+				Message: "Only the product's seller can set its size guide",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to set size guide",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, guide)
+}
+
+// SetCategorySizeGuide replaces the size guide shared by the products of a
+// sized category
+// PUT /api/v1/admin/categories/:category/size-guide
+func (h *SizeGuideHandler) SetCategorySizeGuide(c *gin.Context) {
+	category, err := models.ParseCategory(c.Param("category"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid category",
+			Message: err.Error(),
+		})
+		return
+	}
+	if !category.Sized() {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid category",
+			Message: "Products in " + string(category) + " don't come in sizes",
+		})
+		return
+	}
+
+	guide, ok := bindSizeGuide(c)
+	if !ok {
+		return
+	}
+	guide.Category = category
+
+	// Call listing service via gRPC
+	guide, err = h.grpcClients.SetCategorySizeGuide(c.Request.Context(), guide)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to set size guide",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, guide)
+}
+
+// SubmitFitFeedback records how a sized product fit the author of one of its
+// reviews, replacing any earlier report
+// POST /api/v1/products/:id/reviews/:rid/fit
+func (h *SizeGuideHandler) SubmitFitFeedback(c *gin.Context) {
+	var req models.FitFeedbackRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	product, ok := h.visibleProduct(c)
+	if !ok {
+		return
+	}
+	if !models.Category(product.Category).Sized() {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Error:   "Product has no sizes",
+			Message: "Fit can only be reported for clothing and shoes",
+		})
+		return
+	}
+
+	// Call listing service via gRPC
+	feedback, err := h.grpcClients.SetFitFeedback(c.Request.Context(), &models.FitFeedback{
+		ReviewID:  c.Param("rid"),
+		ProductID: product.ID,
+		UserID:    userID,
+		Fit:       req.Fit,
+		Size:      req.Size,
+	})
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Review not found",
+				Message: "No review exists with the given ID for this product",
+			})
+			return
+		}
+		if err == grpcclient.ErrUnauthorized {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "Unauthorized",
+				Message: "Only the review's author can report how the product fit",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to record fit",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// The product's fit hint changes with its feedback
+	if h.responses != nil {
+		if err := h.responses.Invalidate(c.Request.Context(), cache.ProductTag(product.ID)); err != nil {
+			logging.FromContext(c.Request.Context()).Warn("Failed to invalidate cached responses", "product_id", product.ID, "error", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, feedback)
+}
+
+// visibleProduct fetches the product named in the path, responding with an
+// error if it doesn't exist or isn't public
+func (h *SizeGuideHandler) visibleProduct(c *gin.Context) (*models.Product, bool) {
+	product, err := h.grpcClients.GetProduct(c.Request.Context(), c.Param("id"))
+	if err == nil && !isPubliclyVisible(product) {
+		err = grpcclient.ErrNotFound
+	}
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Product not found",
+				Message: "No product exists with the given ID",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch product",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	return product, true
+}
+
+// bindSizeGuide binds and checks a size guide request, responding with an
+// error if it is invalid
+func bindSizeGuide(c *gin.Context) (*models.SizeGuide, bool) {
+	var req models.SizeGuideRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	guide, err := catalog.NewSizeGuide(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid size guide",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	return guide, true
+}
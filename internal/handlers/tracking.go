@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// orderProgress ranks the order statuses shipments move orders through, so
+// a late tracking event never moves an order back. Cancelled orders are
+// left alone, as are partially shipped ones, which follow their items.
+var orderProgress = map[models.OrderStatus]int{
+	models.OrderStatusPending:    0,
+	models.OrderStatusConfirmed:  1,
+	models.OrderStatusProcessing: 2,
+	models.OrderStatusShipped:    3,
+	models.OrderStatusDelivered:  4,
+}
+
+// TrackingHandler serves the tracking of orders' shipments
+type TrackingHandler struct {
+	grpcClients *grpcclient.Clients
+}
+
+// NewTrackingHandler creates a new tracking handler
+func NewTrackingHandler(clients *grpcclient.Clients) *TrackingHandler {
+	return &TrackingHandler{
+		grpcClients: clients,
+	}
+}
+
+// GetOrderTracking returns an order's status with the carrier, tracking
+// number, and tracking events of each shipment it was sent in
+// GET /api/v1/orders/:id/tracking
+func (h *TrackingHandler) GetOrderTracking(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	// Call order service via gRPC
+	order, err := h.grpcClients.GetOrder(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Order not found",
+				Message: "No order exists with the given ID",
+			})
+			return
+		}
+		if err == grpcclient.ErrUnauthorized {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "Unauthorized",
+				Message: "You don't have permission to view this order",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch order",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	shipments, err := h.grpcClients.ListShipments(c.Request.Context(), order.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch shipments",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.OrderTracking{
+		OrderID:   order.ID,
+		Status:    order.Status,
+		Shipments: shipments,
+	})
+}
+
+// shipmentOrderStatus returns the status an order's shipments put it in:
+// delivered once every shipment is, shipped once any has left the
+// warehouse, or empty while none has
+func shipmentOrderStatus(shipments []*models.Shipment) models.OrderStatus {
+	delivered, left := 0, 0
+	for _, shipment := range shipments {
+		switch shipment.Status {
+		case models.ShipmentStatusDelivered:
+			delivered++
+			left++
+		case models.ShipmentStatusLabelCreated:
+		default:
+			left++
+		}
+	}
+	switch {
+	case len(shipments) > 0 && delivered == len(shipments):
+		return models.OrderStatusDelivered
+	case left > 0:
+		return models.OrderStatusShipped
+	}
+	return ""
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// TransferHandler handles stock transfers between warehouses
+type TransferHandler struct {
+	grpcClients *grpcclient.Clients
+}
+
+// NewTransferHandler creates a new transfer handler
+func NewTransferHandler(clients *grpcclient.Clients) *TransferHandler {
+	return &TransferHandler{
+		grpcClients: clients,
+	}
+}
+
+// CreateTransfer requests a stock transfer between warehouses
+// POST /api/v1/admin/inventory/transfers
+func (h *TransferHandler) CreateTransfer(c *gin.Context) {
+	var req models.CreateStockTransferRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	// Call inventory service via gRPC
+	transfer, err := h.grpcClients.CreateStockTransfer(c.Request.Context(), &req, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to create transfer",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, transfer)
+}
+
+// GetTransfer returns a single stock transfer by ID
+// GET /api/v1/admin/inventory/transfers/:id
+func (h *TransferHandler) GetTransfer(c *gin.Context) {
+	transfer, ok := h.fetchTransfer(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// ApproveTransfer approves a requested transfer, moving it in transit and
+// decrementing stock at the source warehouse. The status moves first, only
+// if the transfer is still requested, so concurrent approvals can't both
+// decrement stock.
+// POST /api/v1/admin/inventory/transfers/:id/approve
+func (h *TransferHandler) ApproveTransfer(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	transfer, ok := h.fetchTransfer(c)
+	if !ok {
+		return
+	}
+
+	if transfer.Status != models.TransferStatusRequested {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Cannot approve transfer",
+			Message: "Transfer can only be approved when in requested status",
+		})
+		return
+	}
+
+	approved := *transfer
+	approved.Status = models.TransferStatusInTransit
+	approved.ApprovedBy = userID
+	approved.ApprovedAt = models.TimestampPtr(time.Now())
+
+	updated, err := h.grpcClients.UpdateStockTransfer(c.Request.Context(), &approved, models.TransferStatusRequested)
+	if err != nil {
+		h.respondUpdateError(c, "Failed to approve transfer", "Transfer is no longer in requested status", err)
+		return
+	}
+
+	// Decrement stock at the source warehouse
+	err = h.grpcClients.AdjustWarehouseStock(c.Request.Context(), transfer.SourceWarehouseID, transfer.ProductID, -transfer.Quantity, "transfer "+transfer.ID, "transfer:"+transfer.ID+":approve")
+	if err != nil {
+		h.respondRolledBack(c, "Failed to decrement source stock", err, transfer, models.TransferStatusInTransit)
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// ReceiveTransfer records receipt of an in-transit transfer, incrementing
+// stock at the destination by the counted quantity and recording any
+// discrepancy for reconciliation. As with approval, the status moves first,
+// only if the transfer is still in transit.
+// POST /api/v1/admin/inventory/transfers/:id/receive
+func (h *TransferHandler) ReceiveTransfer(c *gin.Context) {
+	var req models.ReceiveStockTransferRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	transfer, ok := h.fetchTransfer(c)
+	if !ok {
+		return
+	}
+
+	if transfer.Status != models.TransferStatusInTransit {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Cannot receive transfer",
+			Message: "Transfer can only be received when in transit",
+		})
+		return
+	}
+
+	received := *req.ReceivedQuantity
+	if received > transfer.Quantity {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid received quantity",
+			Message: "Received quantity cannot exceed the quantity shipped",
+		})
+		return
+	}
+
+	// Reconcile shipped against received quantities
+	receipt := *transfer
+	receipt.Status = models.TransferStatusReceived
+	receipt.ReceivedQuantity = received
+	receipt.Discrepancy = transfer.Quantity - received
+	receipt.ReceivedBy = userID
+	receipt.ReceivedAt = models.TimestampPtr(time.Now())
+	if req.Notes != "" {
+		receipt.Notes = req.Notes
+	}
+
+	updated, err := h.grpcClients.UpdateStockTransfer(c.Request.Context(), &receipt, models.TransferStatusInTransit)
+	if err != nil {
+		h.respondUpdateError(c, "Failed to receive transfer", "Transfer is no longer in transit", err)
+		return
+	}
+
+	// Increment stock at the destination warehouse
+	if received > 0 {
+		err := h.grpcClients.AdjustWarehouseStock(c.Request.Context(), transfer.DestinationWarehouseID, transfer.ProductID, received, "transfer "+transfer.ID, "transfer:"+transfer.ID+":receive")
+		if err != nil {
+			h.respondRolledBack(c, "Failed to increment destination stock", err, transfer, models.TransferStatusReceived)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// respondUpdateError responds to a failed conditional status update, with
+// 409 if another request moved the transfer first
+func (h *TransferHandler) respondUpdateError(c *gin.Context, title, conflict string, err error) {
+	if errors.Is(err, grpcclient.ErrStatusChanged) {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   title,
+			Message: conflict,
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		Error:   title,
+		Message: err.Error(),
+	})
+}
+
+// respondRolledBack puts a transfer back in its previous status after its
+// stock adjustment failed. If that fails too, the transfer's status and
+// stock disagree; the failure is logged and reported so it can be
+// reconciled by hand.
+func (h *TransferHandler) respondRolledBack(c *gin.Context, title string, err error, previous *models.StockTransfer, status string) {
+	ctx := context.WithoutCancel(c.Request.Context())
+	if _, rollbackErr := h.grpcClients.UpdateStockTransfer(ctx, previous, status); rollbackErr != nil {
+		logging.FromContext(ctx).Error("Failed to roll back transfer status, needs reconciliation",
+			"transfer_id", previous.ID, "status", status, "error", rollbackErr)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   title,
+			Message: fmt.Sprintf("%v; the transfer could not be rolled back from %s and needs reconciliation: %v", err, status, rollbackErr),
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		Error:   title,
+		Message: err.Error(),
+	})
+}
+
+// fetchTransfer loads the transfer named by the :id path parameter,
+// responding with an error if it cannot be fetched
+func (h *TransferHandler) fetchTransfer(c *gin.Context) (*models.StockTransfer, bool) {
+	transfer, err := h.grpcClients.GetStockTransfer(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Transfer not found",
+				Message: "No transfer exists with the given ID",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch transfer",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	return transfer, true
+}
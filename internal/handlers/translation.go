@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/cache"
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/localization"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	"github.com/ecommerce/be-api-gin/internal/moderation"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// TranslationHandler handles sellers' translations of product content
+type TranslationHandler struct {
+	config      *config.Config
+	grpcClients *grpcclient.Clients
+	moderation  *moderation.Pipeline
+	localizer   *localization.Localizer
+	products    *cache.ProductCache
+}
+
+// NewTranslationHandler creates a new translation handler
+func NewTranslationHandler(cfg *config.Config, clients *grpcclient.Clients, pipeline *moderation.Pipeline, localizer *localization.Localizer, products *cache.ProductCache) *TranslationHandler {
+	return &TranslationHandler{
+		config:      cfg,
+		grpcClients: clients,
+		moderation:  pipeline,
+		localizer:   localizer,
+		products:    products,
+	}
+}
+
+// ListTranslations returns a product's seller and machine translations
+// GET /api/v1/products/:id/translations
+func (h *TranslationHandler) ListTranslations(c *gin.Context) {
+	product, ok := h.ownedProduct(c)
+	if !ok {
+		return
+	}
+
+	// Call listing service via gRPC
+	translations, err := h.grpcClients.ListProductTranslations(c.Request.Context(), product.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch translations",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Translations of text edited since are no longer served
+	hash := localization.SourceHash(product)
+	for _, translation := range translations {
+		translation.Stale = translation.SourceHash != hash
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"product_id":     product.ID,
+		"default_locale": h.localizer.DefaultLocale(),
+		"translations":   translations,
+	})
+}
+
+// SubmitTranslation creates or replaces the seller's translation of a
+// product for a locale, taking precedence over any machine translation
+// PUT /api/v1/products/:id/translations/:locale
+func (h *TranslationHandler) SubmitTranslation(c *gin.Context) {
+	var req models.SubmitTranslationRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	locale := localization.Normalize(c.Param("locale"))
+	if !h.localizer.Supported(locale) || locale == h.localizer.DefaultLocale() {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Unsupported locale",
+			Message: "Translations can be submitted for supported locales other than " + h.localizer.DefaultLocale(),
+		})
+		return
+	}
+
+	product, ok := h.ownedProduct(c)
+	if !ok {
+		return
+	}
+
+	// Translations are screened like the original content
+	decision := h.moderation.Screen(c.Request.Context(), map[string]string{
+		"name":        req.Name,
+		"description": req.Description,
+	})
+	if decision.Verdict != moderation.VerdictApproved {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Content rejected",
+			"message": "The translation contains prohibited or restricted content",
+			"reasons": decision.Reasons,
+		})
+		return
+	}
+
+	translation := &models.ProductTranslation{
+		Locale:      locale,
+		Name:        req.Name,
+		Description: req.Description,
+		Source:      models.TranslationSourceSeller,
+		SourceHash:  localization.SourceHash(product),
+		UpdatedAt:   models.Now(),
+	}
+
+	// Call listing service via gRPC
+	if err := h.grpcClients.UpsertProductTranslation(c.Request.Context(), product.ID, translation); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to save translation",
+			Message: err.Error(),
+		})
+		return
+	}
+	h.products.Invalidate(c.Request.Context(), product.ID)
+
+	c.JSON(http.StatusOK, translation)
+}
+
+// ownedProduct fetches the product in the path, writing an error response
+// unless the caller is its seller or an admin
+func (h *TranslationHandler) ownedProduct(c *gin.Context) (*models.Product, bool) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return nil, false
+	}
+
+	product, err := h.grpcClients.GetProduct(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == grpcclient.ErrNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Product not found",
+				Message: "No product exists with the given ID",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch product",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+
+	if !canManageProduct(c, h.config, product, userID) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "You don't have permission to translate this product",
+		})
+		return nil, false
+	}
+	return product, true
+}
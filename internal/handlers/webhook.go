@@ -0,0 +1,411 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	"github.com/ecommerce/be-api-gin/internal/cache"
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/errorcodes"
+	"github.com/ecommerce/be-api-gin/internal/holds"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/middleware"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	"github.com/ecommerce/be-api-gin/internal/suborder"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// PaymentSignatureHeader carries the payment provider's webhook signature,
+// of the form "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">"
+const PaymentSignatureHeader = "X-Payment-Signature"
+
+// CarrierSignatureHeader carries a carrier's tracking webhook signature, in
+// the same form as PaymentSignatureHeader
+const CarrierSignatureHeader = "X-Carrier-Signature"
+
+// maxWebhookBodyBytes bounds webhook bodies read before verification
+const maxWebhookBodyBytes = 1 << 20
+
+// webhookClaimTTL is how long an event stays claimed while it is processed,
+// so a replica that crashes mid-event only blocks the provider's retries
+// briefly. Applied events are remembered for PaymentWebhookEventTTLHours.
+const webhookClaimTTL = 2 * time.Minute
+
+// paymentEventStatuses maps payment events to the order status they move
+// the order to
+var paymentEventStatuses = map[string]models.OrderStatus{
+	models.PaymentEventSucceeded:       models.OrderStatusConfirmed,
+	models.PaymentEventFailed:          models.OrderStatusCancelled,
+	models.PaymentEventRefundCompleted: models.OrderStatusCancelled,
+}
+
+// paymentEventApplies reports whether a payment event moves the order as it
+// stands. Payments only settle pending orders, so a late event can't revive
+// or cancel an order that has moved on, and only a full refund cancels an
+// order, and only while it can still be cancelled.
+func paymentEventApplies(event *models.PaymentWebhookEvent, order *models.Order) bool {
+	switch event.Type {
+	case models.PaymentEventSucceeded, models.PaymentEventFailed:
+		return order.Status == models.OrderStatusPending
+	case models.PaymentEventRefundCompleted:
+		charged := math.Round((order.TotalAmount-order.StoreCreditApplied-order.GiftCardApplied)*100) / 100
+		return order.Status.Cancellable() && event.Data.Amount >= charged
+	}
+	return false
+}
+
+// WebhookHandler handles notifications from external providers
+type WebhookHandler struct {
+	grpcClients *grpcclient.Clients
+	config      *config.Config
+	events      middleware.IdempotencyStore
+}
+
+// NewWebhookHandler creates a new webhook handler, remembering processed
+// event IDs in events
+func NewWebhookHandler(clients *grpcclient.Clients, cfg *config.Config, events middleware.IdempotencyStore) *WebhookHandler {
+	return &WebhookHandler{
+		grpcClients: clients,
+		config:      cfg,
+		events:      events,
+	}
+}
+
+// PaymentWebhook applies a signed payment event to its order. Each event ID
+// is applied once; redeliveries are acknowledged without repeating it.
+// POST /webhooks/payments
+func (h *WebhookHandler) PaymentWebhook(c *gin.Context) {
+	if h.config.PaymentWebhookSecret == "" {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Not found",
+			Message: "Payment webhooks are not enabled",
+		})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBodyBytes)
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if code, message := h.verifySignature(PaymentSignatureHeader, h.config.PaymentWebhookSecret, c.GetHeader(PaymentSignatureHeader), body); code != "" {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "Invalid webhook signature",
+			Message: message,
+			Code:    code,
+		})
+		return
+	}
+
+	var event models.PaymentWebhookEvent
+	if err := binding.JSON.BindBody(body, &event); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid event",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	log := logging.FromContext(ctx).With("event_id", event.ID, "event_type", event.Type, "order_id", event.Data.OrderID)
+
+	status, ok := paymentEventStatuses[event.Type]
+	if !ok {
+		// Acknowledge events we don't handle so the provider stops sending them
+		log.Info("Ignoring payment event")
+		c.JSON(http.StatusOK, gin.H{"status": "ignored"})
+		return
+	}
+
+	if !h.claimEvent(c, event.ID, event.Type) {
+		return
+	}
+
+	// fail frees the event ID so the provider's retry is applied
+	fail := func(title string, err error) {
+		if err := h.events.Release(ctx, event.ID); err != nil {
+			log.Warn("Failed to release payment event", "error", err)
+		}
+		if err == grpcclient.ErrNotFound {
+			// Retrying won't make the order appear
+			log.Warn("Payment event for unknown order")
+			c.JSON(http.StatusOK, gin.H{"status": "ignored"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   title,
+			Message: err.Error(),
+		})
+	}
+
+	order, err := h.grpcClients.GetOrder(ctx, event.Data.OrderID, event.Data.UserID)
+	if err != nil {
+		fail("Failed to fetch order", err)
+		return
+	}
+	if !paymentEventApplies(&event, order) {
+		// A payment that succeeds after its order was cancelled is given back
+		if event.Type == models.PaymentEventSucceeded && order.Status == models.OrderStatusCancelled && event.Data.PaymentIntentID != "" {
+			if err := h.grpcClients.CancelPayment(ctx, event.Data.PaymentIntentID, event.Data.UserID); err != nil {
+				fail("Failed to refund payment", err)
+				return
+			}
+			log.Info("Refunded payment for cancelled order")
+		}
+		if err := h.completeEvent(ctx, event.ID, event.Type); err != nil {
+			log.Warn("Failed to record payment event", "error", err)
+		}
+		log.Info("Ignoring payment event for order's status", "order_status", order.Status)
+		c.JSON(http.StatusOK, gin.H{"status": "ignored"})
+		return
+	}
+
+	// Move the sellers' sub-orders with the order, so they stop fulfilling
+	// a cancelled one
+	err = suborder.SetStatus(ctx, h.grpcClients, order.ID, event.Data.UserID, status)
+	if err == nil {
+		_, err = h.grpcClients.UpdateOrderStatus(ctx, order.ID, event.Data.UserID, status)
+	}
+	if err != nil {
+		fail("Failed to update order", err)
+		return
+	}
+	if status == models.OrderStatusCancelled {
+		if err := holds.Release(ctx, h.grpcClients, order); err != nil {
+			log.Warn("Failed to release cancelled order's holds", "error", err)
+		}
+	}
+
+	if err := h.completeEvent(ctx, event.ID, event.Type); err != nil {
+		log.Warn("Failed to record payment event", "error", err)
+	}
+	log.Info("Payment event applied", "order_status", status)
+	c.JSON(http.StatusOK, gin.H{"status": "processed"})
+}
+
+// CarrierWebhook records a signed tracking update from a carrier on its
+// shipment, and moves the order to shipped once a shipment has left the
+// warehouse or to delivered once every shipment has arrived. Each event ID
+// is applied once; redeliveries are acknowledged without repeating it.
+// POST /webhooks/carriers
+func (h *WebhookHandler) CarrierWebhook(c *gin.Context) {
+	if h.config.CarrierWebhookSecret == "" {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Not found",
+			Message: "Carrier webhooks are not enabled",
+		})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBodyBytes)
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if code, message := h.verifySignature(CarrierSignatureHeader, h.config.CarrierWebhookSecret, c.GetHeader(CarrierSignatureHeader), body); code != "" {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "Invalid webhook signature",
+			Message: message,
+			Code:    code,
+		})
+		return
+	}
+
+	var event models.CarrierWebhookEvent
+	if err := binding.JSON.BindBody(body, &event); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid event",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	log := logging.FromContext(ctx).With("event_id", event.ID, "carrier", event.Carrier, "tracking_number", event.TrackingNumber, "shipment_status", event.Status)
+
+	// Carrier event IDs are only unique per carrier, and must not collide
+	// with payment event IDs
+	key := "carrier:" + strings.ToLower(event.Carrier) + ":" + event.ID
+	if !h.claimEvent(c, key, string(event.Status)) {
+		return
+	}
+	// Free the event ID on failure so the carrier's retry is applied
+	release := func() {
+		if err := h.events.Release(ctx, key); err != nil {
+			log.Warn("Failed to release carrier event", "error", err)
+		}
+	}
+
+	// Call order service via gRPC
+	shipment, err := h.grpcClients.GetShipmentByTracking(ctx, event.Carrier, event.TrackingNumber)
+	if err != nil {
+		release()
+		if err == grpcclient.ErrNotFound {
+			// Retrying won't make the shipment appear
+			log.Warn("Carrier event for unknown shipment")
+			c.JSON(http.StatusOK, gin.H{"status": "ignored"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to fetch shipment",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	shipment, err = h.grpcClients.AddTrackingEvent(ctx, shipment, models.TrackingEvent{
+		Status:      event.Status,
+		Description: event.Description,
+		Location:    event.Location,
+		OccurredAt:  event.OccurredAt,
+	}, event.EstimatedDelivery)
+	if err != nil {
+		release()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to record tracking event",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	status, err := h.advanceOrder(ctx, shipment)
+	if err != nil {
+		release()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to update order",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.completeEvent(ctx, key, string(event.Status)); err != nil {
+		log.Warn("Failed to record carrier event", "error", err)
+	}
+	log.Info("Carrier event applied", "order_id", shipment.OrderID, "order_status", status)
+	c.JSON(http.StatusOK, gin.H{"status": "processed"})
+}
+
+// advanceOrder moves a shipment's order to the status its shipments put it
+// in, if that is further along than it is, and returns the order's status
+func (h *WebhookHandler) advanceOrder(ctx context.Context, updated *models.Shipment) (models.OrderStatus, error) {
+	shipments, err := h.grpcClients.ListShipments(ctx, updated.OrderID)
+	if err != nil {
+		return "", err
+	}
+	found := false
+	for i, shipment := range shipments {
+		if shipment.ID == updated.ID {
+			shipments[i] = updated
+			found = true
+		}
+	}
+	if !found {
+		shipments = append(shipments, updated)
+	}
+
+	order, err := h.grpcClients.GetOrder(ctx, updated.OrderID, updated.UserID)
+	if err != nil {
+		return "", err
+	}
+	target := shipmentOrderStatus(shipments)
+	// Only orders that are paid for are fulfilled, so a pending order isn't
+	// moved on by its shipments
+	current, tracked := orderProgress[order.Status]
+	if target == "" || !tracked || current < orderProgress[models.OrderStatusConfirmed] || orderProgress[target] <= current {
+		return order.Status, nil
+	}
+	if _, err := h.grpcClients.UpdateOrderStatus(ctx, order.ID, updated.UserID, target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// claimEvent claims an event ID for processing, responding and returning
+// false if it can't be checked, is still being processed, or was already
+// applied
+func (h *WebhookHandler) claimEvent(c *gin.Context, key, eventType string) bool {
+	claimed, record, err := h.events.Begin(c.Request.Context(), key, eventType, webhookClaimTTL)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Error:   "Event check unavailable",
+			Message: "Unable to check whether the event was already processed, please retry",
+		})
+		return false
+	}
+	if !claimed {
+		if record.Response == nil {
+			c.Header("Retry-After", "1")
+			c.JSON(http.StatusConflict, models.ErrorResponse{
+				Error:   "Event in progress",
+				Message: "This event is still being processed",
+			})
+			return false
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "duplicate"})
+		return false
+	}
+	return true
+}
+
+// completeEvent records a claimed event as applied
+func (h *WebhookHandler) completeEvent(ctx context.Context, key, eventType string) error {
+	ttl := time.Duration(h.config.PaymentWebhookEventTTLHours) * time.Hour
+	processed := &middleware.IdempotencyRecord{
+		Fingerprint: eventType,
+		Response:    &cache.Response{Status: http.StatusOK},
+	}
+	return h.events.Complete(ctx, key, processed, ttl)
+}
+
+// verifySignature checks a webhook signature header named name against the
+// body with secret, returning an error code and message if it is not valid
+func (h *WebhookHandler) verifySignature(name, secret, header string, body []byte) (string, string) {
+	var timestamp, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signature = value
+		}
+	}
+	if timestamp == "" || signature == "" {
+		return errorcodes.SignatureMissing, "The " + name + " header is missing or malformed"
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	tolerance := time.Duration(h.config.PaymentWebhookToleranceSec) * time.Second
+	if err != nil || time.Since(time.Unix(unix, 0)).Abs() > tolerance {
+		return errorcodes.SignatureExpired, "The signature timestamp is outside the allowed tolerance"
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	provided, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(provided, mac.Sum(nil)) {
+		return errorcodes.SignatureInvalid, "The signature does not match the body"
+	}
+	return "", ""
+}
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/errorcodes"
+	"github.com/ecommerce/be-api-gin/internal/middleware"
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+const testWebhookSecret = "whsec_test"
+
+// ttlStore records the TTLs events are claimed and completed with
+type ttlStore struct {
+	*middleware.MemoryIdempotencyStore
+	beginTTL    time.Duration
+	completeTTL time.Duration
+}
+
+func (s *ttlStore) Begin(ctx context.Context, key, fingerprint string, ttl time.Duration) (bool, *middleware.IdempotencyRecord, error) {
+	s.beginTTL = ttl
+	return s.MemoryIdempotencyStore.Begin(ctx, key, fingerprint, ttl)
+}
+
+func (s *ttlStore) Complete(ctx context.Context, key string, record *middleware.IdempotencyRecord, ttl time.Duration) error {
+	s.completeTTL = ttl
+	return s.MemoryIdempotencyStore.Complete(ctx, key, record, ttl)
+}
+
+// newWebhookRouter routes payment webhooks, remembering events in store
+func newWebhookRouter(t *testing.T, store middleware.IdempotencyStore) *gin.Engine {
+	cfg := testConfig()
+	cfg.PaymentWebhookSecret = testWebhookSecret
+	h := NewWebhookHandler(newTestClients(t, cfg), cfg, store)
+
+	router := gin.New()
+	router.POST("/webhooks/payments", h.PaymentWebhook)
+	return router
+}
+
+// signWebhook signs body at time at as the payment provider does
+func signWebhook(secret string, body []byte, at time.Time) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// paymentEvent encodes a payment event for an order
+func paymentEvent(id, eventType, orderID string) []byte {
+	data, _ := json.Marshal(models.PaymentWebhookEvent{
+		ID:   id,
+		Type: eventType,
+		Data: models.PaymentWebhookEventData{
+			PaymentIntentID: "pi-" + orderID,
+			OrderID:         orderID,
+			UserID:          "user-1",
+			Amount:          29.99,
+		},
+	})
+	return data
+}
+
+// webhookStatus decodes the status of an acknowledged event
+func webhookStatus(t *testing.T, body []byte) string {
+	t.Helper()
+	var resp struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("decoding response %q: %v", body, err)
+	}
+	return resp.Status
+}
+
+func TestPaymentEventApplies(t *testing.T) {
+	// The order's card was charged $70 after balances paid $30
+	order := func(status models.OrderStatus) *models.Order {
+		return &models.Order{Status: status, TotalAmount: 100, StoreCreditApplied: 20, GiftCardApplied: 10}
+	}
+
+	tests := []struct {
+		name      string
+		eventType string
+		amount    float64
+		order     *models.Order
+		want      bool
+	}{
+		{"success settles a pending order", models.PaymentEventSucceeded, 70, order(models.OrderStatusPending), true},
+		{"late success leaves a cancelled order", models.PaymentEventSucceeded, 70, order(models.OrderStatusCancelled), false},
+		{"late failure leaves a confirmed order", models.PaymentEventFailed, 70, order(models.OrderStatusConfirmed), false},
+		{"failure cancels a pending order", models.PaymentEventFailed, 70, order(models.OrderStatusPending), true},
+		{"full refund cancels a confirmed order", models.PaymentEventRefundCompleted, 70, order(models.OrderStatusConfirmed), true},
+		{"partial refund leaves the order", models.PaymentEventRefundCompleted, 35, order(models.OrderStatusConfirmed), false},
+		{"refund leaves a shipped order", models.PaymentEventRefundCompleted, 70, order(models.OrderStatusShipped), false},
+		{"unknown event", "payment_disputed", 70, order(models.OrderStatusPending), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &models.PaymentWebhookEvent{Type: tt.eventType, Data: models.PaymentWebhookEventData{Amount: tt.amount}}
+			if got := paymentEventApplies(event, tt.order); got != tt.want {
+				t.Errorf("paymentEventApplies() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaymentWebhookSignature(t *testing.T) {
+	router := newWebhookRouter(t, middleware.NewMemoryIdempotencyStore())
+	body := paymentEvent("evt-1", models.PaymentEventSucceeded, "order-1")
+
+	tests := []struct {
+		name      string
+		signature string
+		wantCode  string
+	}{
+		{"missing", "", errorcodes.SignatureMissing},
+		{"malformed", "v1=abc", errorcodes.SignatureMissing},
+		{"wrong secret", signWebhook("other", body, time.Now()), errorcodes.SignatureInvalid},
+		{"other body", signWebhook(testWebhookSecret, []byte("{}"), time.Now()), errorcodes.SignatureInvalid},
+		{"too old", signWebhook(testWebhookSecret, body, time.Now().Add(-time.Hour)), errorcodes.SignatureExpired},
+		{"too far ahead", signWebhook(testWebhookSecret, body, time.Now().Add(time.Hour)), errorcodes.SignatureExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodPost, "/webhooks/payments", body, map[string]string{PaymentSignatureHeader: tt.signature})
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusUnauthorized, w.Body)
+			}
+			if code := decodeError(t, w).Code; code != tt.wantCode {
+				t.Errorf("code = %q, want %q", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestPaymentWebhookDisabled(t *testing.T) {
+	cfg := testConfig()
+	cfg.PaymentWebhookSecret = ""
+	h := NewWebhookHandler(nil, cfg, middleware.NewMemoryIdempotencyStore())
+	router := gin.New()
+	router.POST("/webhooks/payments", h.PaymentWebhook)
+
+	w := serve(router, http.MethodPost, "/webhooks/payments", paymentEvent("evt-1", models.PaymentEventSucceeded, "order-1"), nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestPaymentWebhookEvents(t *testing.T) {
+	tests := []struct {
+		name       string
+		eventType  string
+		orderID    string
+		wantStatus string
+	}{
+		{"succeeded", models.PaymentEventSucceeded, "order-1", "processed"},
+		{"failed", models.PaymentEventFailed, "order-1", "processed"},
+		{"refunded", models.PaymentEventRefundCompleted, "order-1", "processed"},
+		{"unknown order", models.PaymentEventSucceeded, "not-found", "ignored"},
+		{"unhandled type", "payment_disputed", "order-1", "ignored"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newWebhookRouter(t, middleware.NewMemoryIdempotencyStore())
+			body := paymentEvent("evt-1", tt.eventType, tt.orderID)
+
+			w := serve(router, http.MethodPost, "/webhooks/payments", body, map[string]string{PaymentSignatureHeader: signWebhook(testWebhookSecret, body, time.Now())})
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+			if status := webhookStatus(t, w.Body.Bytes()); status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestPaymentWebhookRedelivery(t *testing.T) {
+	store := &ttlStore{MemoryIdempotencyStore: middleware.NewMemoryIdempotencyStore()}
+	router := newWebhookRouter(t, store)
+	body := paymentEvent("evt-1", models.PaymentEventSucceeded, "order-1")
+	headers := map[string]string{PaymentSignatureHeader: signWebhook(testWebhookSecret, body, time.Now())}
+
+	w := serve(router, http.MethodPost, "/webhooks/payments", body, headers)
+	if status := webhookStatus(t, w.Body.Bytes()); status != "processed" {
+		t.Fatalf("first delivery status = %q, want processed", status)
+	}
+	// The event is claimed briefly, and remembered for long once applied
+	if store.beginTTL != webhookClaimTTL {
+		t.Errorf("claimed for %v, want %v", store.beginTTL, webhookClaimTTL)
+	}
+	if want := time.Duration(testConfig().PaymentWebhookEventTTLHours) * time.Hour; store.completeTTL != want {
+		t.Errorf("remembered for %v, want %v", store.completeTTL, want)
+	}
+
+	w = serve(router, http.MethodPost, "/webhooks/payments", body, headers)
+	if w.Code != http.StatusOK {
+		t.Fatalf("redelivery status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if status := webhookStatus(t, w.Body.Bytes()); status != "duplicate" {
+		t.Errorf("redelivery status = %q, want duplicate", status)
+	}
+}
+
+func TestPaymentWebhookInProgress(t *testing.T) {
+	store := middleware.NewMemoryIdempotencyStore()
+	router := newWebhookRouter(t, store)
+	body := paymentEvent("evt-1", models.PaymentEventSucceeded, "order-1")
+	headers := map[string]string{PaymentSignatureHeader: signWebhook(testWebhookSecret, body, time.Now())}
+
+	// Another replica is processing the event
+	if claimed, _, _ := store.Begin(context.Background(), "evt-1", models.PaymentEventSucceeded, time.Minute); !claimed {
+		t.Fatal("failed to claim the event")
+	}
+
+	w := serve(router, http.MethodPost, "/webhooks/payments", body, headers)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusConflict, w.Body)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("missing Retry-After")
+	}
+
+	// Once the claim is released, the provider's retry is applied
+	store.Release(context.Background(), "evt-1")
+	w = serve(router, http.MethodPost, "/webhooks/payments", body, headers)
+	if status := webhookStatus(t, w.Body.Bytes()); status != "processed" {
+		t.Errorf("retry status = %q, want processed", status)
+	}
+}
@@ -0,0 +1,40 @@
+package holds
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// Release gives back what a cancelled order was holding: the stock reserved
+// for its items, its promo code redemption, so the code counts toward its
+// limits again, and the store credit and gift card amounts it held or
+// spent. Every release is attempted, and their errors returned together;
+// releasing again is safe.
+func Release(ctx context.Context, clients *grpcclient.Clients, order *models.Order) error {
+	var errs []error
+	for _, reservationID := range order.ReservationIDs {
+		if err := clients.CancelReservation(ctx, reservationID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if order.CouponCode != "" {
+		redemptionID, err := clients.GetOrderCouponRedemption(ctx, order.ID)
+		switch {
+		case err == nil:
+			errs = append(errs, clients.ReleaseCouponRedemption(ctx, redemptionID))
+		case !errors.Is(err, grpcclient.ErrNotFound):
+			errs = append(errs, err)
+		}
+	}
+	if order.GiftCardApplied > 0 {
+		errs = append(errs, clients.RefundOrderGiftCards(ctx, order.ID))
+	}
+	if order.StoreCreditApplied > 0 {
+		errs = append(errs, clients.RefundOrderStoreCredit(ctx, order.ID))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,258 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	redisclient "github.com/ecommerce/be-api-gin/pkg/redis"
+)
+
+// jobRetention is how long finished jobs can still be looked up
+const jobRetention = 24 * time.Hour
+
+// Store keeps job state so progress can be reported
+type Store interface {
+	// Save creates or replaces a job
+	Save(ctx context.Context, job *models.Job) error
+	// Get returns a job, or nil if it does not exist
+	Get(ctx context.Context, id string) (*models.Job, error)
+	// Purge removes jobs that finished before the given time, returning
+	// how many it removed
+	Purge(ctx context.Context, before time.Time) (int, error)
+}
+
+// Func does a job's work, reporting progress as it goes
+type Func func(ctx context.Context, progress *Progress) error
+
+// Runner runs jobs in the background and records their progress
+type Runner struct {
+	store Store
+}
+
+// NewRunner creates a runner recording jobs in store
+func NewRunner(store Store) *Runner {
+	return &Runner{store: store}
+}
+
+// Get returns a job, or nil if it does not exist
+func (r *Runner) Get(ctx context.Context, id string) (*models.Job, error) {
+	return r.store.Get(ctx, id)
+}
+
+// Start records a queued job and runs fn in the background. The job keeps
+// the request's logging context but isn't cancelled when the request ends.
+func (r *Runner) Start(ctx context.Context, jobType, createdBy string, params map[string]string, fn Func) (*models.Job, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	job := &models.Job{
+		ID:        "job-" + hex.EncodeToString(buf),
+		Type:      jobType,
+		Status:    models.JobStatusQueued,
+		Params:    params,
+		CreatedBy: createdBy,
+		CreatedAt: models.Now(),
+	}
+	if err := r.store.Save(ctx, job); err != nil {
+		return nil, err
+	}
+
+	snapshot := *job
+	go r.run(context.WithoutCancel(ctx), job, fn)
+	return &snapshot, nil
+}
+
+// run executes a job, recording its outcome. Panics fail the job rather
+// than the process.
+func (r *Runner) run(ctx context.Context, job *models.Job, fn Func) {
+	progress := &Progress{runner: r, job: job}
+	logger := logging.FromContext(ctx).With("job_id", job.ID, "job_type", job.Type)
+
+	now := time.Now()
+	progress.update(func(j *models.Job) {
+		j.Status = models.JobStatusRunning
+		j.StartedAt = models.TimestampPtr(now)
+	})
+
+	err := func() (err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				err = fmt.Errorf("job panicked: %v", rec)
+			}
+		}()
+		return fn(ctx, progress)
+	}()
+
+	finished := time.Now()
+	progress.update(func(j *models.Job) {
+		j.FinishedAt = models.TimestampPtr(finished)
+		j.Status = models.JobStatusSucceeded
+		if err != nil {
+			j.Status = models.JobStatusFailed
+			j.Error = err.Error()
+		}
+	})
+	if err != nil {
+		logger.Error("Job failed", "error", err)
+		return
+	}
+	logger.Info("Job completed", "processed", job.Processed, "failed", job.Failed)
+}
+
+// Progress reports a running job's progress
+type Progress struct {
+	runner *Runner
+	mu     sync.Mutex
+	job    *models.Job
+}
+
+// SetTotal records how many items the job will process
+func (p *Progress) SetTotal(total int) {
+	p.update(func(j *models.Job) { j.Total = total })
+}
+
+// Add records processed and failed items
+func (p *Progress) Add(processed, failed int) {
+	p.update(func(j *models.Job) {
+		j.Processed += processed
+		j.Failed += failed
+	})
+}
+
+// update applies a change to the job and saves it, logging rather than
+// failing the job if the store is unavailable
+func (p *Progress) update(change func(*models.Job)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	change(p.job)
+	ctx := context.Background()
+	if err := p.runner.store.Save(ctx, p.job); err != nil {
+		logging.FromContext(ctx).Warn("Failed to save job progress", "job_id", p.job.ID, "error", err)
+	}
+}
+
+// MemoryStore is an in-process Store. Jobs can only be looked up through the
+// gateway instance running them.
+type MemoryStore struct {
+	mu   sync.Mutex
+	jobs map[string]*models.Job
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		jobs: make(map[string]*models.Job),
+	}
+}
+
+// Save stores a copy of the job, purging jobs finished long ago
+func (s *MemoryStore) Save(ctx context.Context, job *models.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, j := range s.jobs {
+		if j.FinishedAt != nil && now.Sub(j.FinishedAt.Time) > jobRetention {
+			delete(s.jobs, id)
+		}
+	}
+
+	snapshot := *job
+	s.jobs[job.ID] = &snapshot
+	return nil
+}
+
+// Get returns a copy of the job
+func (s *MemoryStore) Get(ctx context.Context, id string) (*models.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, nil
+	}
+	snapshot := *job
+	return &snapshot, nil
+}
+
+// Purge removes jobs that finished before the given time
+func (s *MemoryStore) Purge(ctx context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purged := 0
+	for id, job := range s.jobs {
+		if finishedBefore(job, before) {
+			delete(s.jobs, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// finishedBefore reports whether a job finished before the given time.
+// Jobs still running are never purged.
+func finishedBefore(job *models.Job, before time.Time) bool {
+	return job.FinishedAt != nil && job.FinishedAt.Time.Before(before)
+}
+
+// RedisStore is a Store shared by all gateway replicas, so any replica can
+// report a job's progress
+type RedisStore struct {
+	client *goredis.Client
+	prefix string
+}
+
+// NewRedisStore creates a store using client, namespacing keys with prefix
+func NewRedisStore(client *goredis.Client, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Save stores the job, keeping it for the retention period
+func (s *RedisStore) Save(ctx context.Context, job *models.Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.prefix+job.ID, data, jobRetention).Err()
+}
+
+// Get returns the job, or nil if it does not exist
+func (s *RedisStore) Get(ctx context.Context, id string) (*models.Job, error) {
+	data, err := s.client.Get(ctx, s.prefix+id).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var job models.Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Purge removes jobs that finished before the given time
+func (s *RedisStore) Purge(ctx context.Context, before time.Time) (int, error) {
+	return redisclient.Purge(ctx, s.client, s.prefix+"*", func(value []byte) bool {
+		var job models.Job
+		return json.Unmarshal(value, &job) == nil && finishedBefore(&job, before)
+	})
+}
@@ -0,0 +1,374 @@
+package localization
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	"github.com/ecommerce/be-api-gin/internal/moderation"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// Translator machine-translates texts from one locale to another, returning
+// the translations in the same order
+type Translator interface {
+	Translate(ctx context.Context, texts []string, source, target string) ([]string, error)
+}
+
+// Translation queue limits
+const (
+	// queueSize bounds the machine translations waiting for the provider;
+	// requests beyond it are dropped and retried on a later read
+	queueSize = 256
+	// retryAfter is how long a product and locale isn't queued again after
+	// its machine translation failed or was rejected by moderation
+	retryAfter = time.Hour
+)
+
+// Invalidator purges cached copies of a product
+type Invalidator interface {
+	Invalidate(ctx context.Context, id string)
+}
+
+// Localizer resolves product content for a locale. Seller translations are
+// preferred; otherwise content is machine translated in the background,
+// screened by moderation and stored. Translations of text that has since
+// been edited are ignored.
+type Localizer struct {
+	clients       *grpcclient.Clients
+	translator    Translator
+	moderation    *moderation.Pipeline
+	products      Invalidator
+	defaultLocale string
+	supported     map[string]bool
+
+	queue   chan translationJob
+	mu      sync.Mutex
+	pending map[string]bool      // queued or in progress
+	retry   map[string]time.Time // not queued again until
+}
+
+// translationJob is a product's text waiting to be machine translated
+type translationJob struct {
+	productID   string
+	locale      string
+	name        string
+	description string
+	sourceHash  string
+}
+
+// NewLocalizer creates the localizer configured for the application. Machine
+// translations are screened by pipeline, and products purges the cached
+// copies of translated products. Run processes the translation queue.
+func NewLocalizer(clients *grpcclient.Clients, cfg *config.Config, pipeline *moderation.Pipeline, products Invalidator) *Localizer {
+	l := &Localizer{
+		clients:       clients,
+		moderation:    pipeline,
+		products:      products,
+		defaultLocale: Normalize(cfg.DefaultLocale),
+		supported:     make(map[string]bool, len(cfg.SupportedLocales)),
+		queue:         make(chan translationJob, queueSize),
+		pending:       make(map[string]bool),
+		retry:         make(map[string]time.Time),
+	}
+	for _, locale := range cfg.SupportedLocales {
+		l.supported[Normalize(locale)] = true
+	}
+	l.supported[l.defaultLocale] = true
+
+	if cfg.TranslationProviderURL != "" {
+		l.translator = &HTTPTranslator{
+			URL:    cfg.TranslationProviderURL,
+			APIKey: cfg.TranslationProviderAPIKey,
+			Client: &http.Client{Timeout: 5 * time.Second},
+		}
+	}
+	return l
+}
+
+// DefaultLocale returns the locale product content is written in
+func (l *Localizer) DefaultLocale() string {
+	return l.defaultLocale
+}
+
+// Supported reports whether content can be served in the locale
+func (l *Localizer) Supported(locale string) bool {
+	return l.supported[Normalize(locale)]
+}
+
+// Negotiate picks the best supported locale from an Accept-Language header,
+// matching a language-only tag ("de") against regional variants ("de-ch")
+// and falling back to the default locale
+func (l *Localizer) Negotiate(acceptLanguage string) string {
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		if l.supported[tag] {
+			return tag
+		}
+		if base, _, ok := strings.Cut(tag, "-"); ok && l.supported[base] {
+			return base
+		}
+	}
+	return l.defaultLocale
+}
+
+// Localize replaces a product's name and description with their translation
+// for the locale and sets the product's locale. With machineFallback, a
+// missing or stale machine translation is queued; the original content is
+// served until it is stored.
+func (l *Localizer) Localize(ctx context.Context, product *models.Product, locale string, machineFallback bool) {
+	l.LocalizeAll(ctx, []*models.Product{product}, locale, machineFallback)
+}
+
+// LocalizeAll localizes products like Localize, fetching their translations
+// in one call
+func (l *Localizer) LocalizeAll(ctx context.Context, products []*models.Product, locale string, machineFallback bool) {
+	for _, product := range products {
+		product.Locale = l.defaultLocale
+	}
+	if locale == l.defaultLocale || len(products) == 0 {
+		return
+	}
+
+	ids := make([]string, len(products))
+	for i, product := range products {
+		ids[i] = product.ID
+	}
+	translations, err := l.clients.GetProductTranslations(ctx, ids, locale)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Failed to localize products", "locale", locale, "error", err)
+		return
+	}
+
+	for _, product := range products {
+		hash := SourceHash(product)
+		translation := translations[product.ID]
+		if translation == nil || translation.SourceHash != hash {
+			// A stale seller translation is kept for the seller to update
+			// rather than replaced by a machine translation
+			if machineFallback && (translation == nil || translation.Source == models.TranslationSourceMachine) {
+				l.enqueue(ctx, product, locale, hash)
+			}
+			continue
+		}
+
+		if translation.Name != "" {
+			product.Name = translation.Name
+		}
+		if translation.Description != "" {
+			product.Description = translation.Description
+		}
+		product.Locale = locale
+	}
+}
+
+// SourceHash identifies a product's original name and description, so
+// translations of earlier text can be told apart
+func SourceHash(product *models.Product) string {
+	sum := sha256.Sum256([]byte(product.Name + "\x00" + product.Description))
+	return hex.EncodeToString(sum[:16])
+}
+
+// enqueue queues a product for machine translation unless it is already
+// queued, recently failed, or the queue is full
+func (l *Localizer) enqueue(ctx context.Context, product *models.Product, locale, hash string) {
+	if l.translator == nil {
+		return
+	}
+
+	key := product.ID + "|" + locale
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.pending[key] || now.Before(l.retry[key]) {
+		return
+	}
+
+	job := translationJob{
+		productID:   product.ID,
+		locale:      locale,
+		name:        product.Name,
+		description: product.Description,
+		sourceHash:  hash,
+	}
+	select {
+	case l.queue <- job:
+		l.pending[key] = true
+	default:
+		logging.FromContext(ctx).Debug("Translation queue full, skipping product", "product_id", product.ID, "locale", locale)
+	}
+}
+
+// Run machine translates queued products until ctx is cancelled
+func (l *Localizer) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-l.queue:
+			l.process(ctx, job)
+		}
+	}
+}
+
+// process machine translates a queued product, holding it back from the
+// queue for a while if translation fails or is rejected
+func (l *Localizer) process(ctx context.Context, job translationJob) {
+	key := job.productID + "|" + job.locale
+	err := l.machineTranslate(ctx, job)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.pending, key)
+	now := time.Now()
+	for k, until := range l.retry {
+		if now.After(until) {
+			delete(l.retry, k)
+		}
+	}
+	if err != nil {
+		slog.Warn("Failed to machine translate product", "product_id", job.productID, "locale", job.locale, "error", err)
+		l.retry[key] = now.Add(retryAfter)
+	}
+}
+
+// machineTranslate translates a product with the provider, screens the
+// result like seller content and stores it, so the provider is called once
+// per product text and locale
+func (l *Localizer) machineTranslate(ctx context.Context, job translationJob) error {
+	// A seller translation may have been submitted since the job was queued
+	existing, err := l.clients.GetProductTranslations(ctx, []string{job.productID}, job.locale)
+	if err != nil {
+		return err
+	}
+	if current := existing[job.productID]; current != nil &&
+		(current.Source == models.TranslationSourceSeller || current.SourceHash == job.sourceHash) {
+		return nil
+	}
+
+	texts, err := l.translator.Translate(ctx, []string{job.name, job.description}, l.defaultLocale, job.locale)
+	if err != nil {
+		return err
+	}
+	if len(texts) != 2 {
+		return fmt.Errorf("translation provider returned %d texts, expected 2", len(texts))
+	}
+
+	decision := l.moderation.Screen(ctx, map[string]string{
+		"name":        texts[0],
+		"description": texts[1],
+	})
+	if decision.Verdict != moderation.VerdictApproved {
+		return fmt.Errorf("translation not approved by moderation: %s", decision.Verdict)
+	}
+
+	translation := &models.ProductTranslation{
+		Locale:      job.locale,
+		Name:        texts[0],
+		Description: texts[1],
+		Source:      models.TranslationSourceMachine,
+		SourceHash:  job.sourceHash,
+		UpdatedAt:   models.Now(),
+	}
+	if err := l.clients.UpsertProductTranslation(ctx, job.productID, translation); err != nil {
+		return err
+	}
+	l.products.Invalidate(ctx, job.productID)
+	return nil
+}
+
+// Normalize lowercases a locale tag and uses hyphens, e.g. "pt_BR" -> "pt-br"
+func Normalize(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// parseAcceptLanguage returns the header's language tags ordered by quality
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = Normalize(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag, q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
+
+// HTTPTranslator is a Translator adapter for translation services exposing
+// a JSON endpoint
+type HTTPTranslator struct {
+	URL    string
+	APIKey string
+	Client *http.Client
+}
+
+// Translate sends texts to the provider and returns its translations
+func (t *HTTPTranslator) Translate(ctx context.Context, texts []string, source, target string) ([]string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"texts":  texts,
+		"source": source,
+		"target": target,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+t.APIKey)
+	}
+
+	resp, err := t.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("translation provider returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Translations []string `json:"translations"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.Translations, nil
+}
@@ -0,0 +1,113 @@
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Locker grants a named lock to one holder at a time. A held lock lapses
+// after its TTL, so a holder that dies can't keep it forever.
+type Locker interface {
+	// TryLock acquires the lock if it is free. When acquired, release frees
+	// it, unless it has already lapsed and been taken by another holder.
+	TryLock(ctx context.Context, name string, ttl time.Duration) (release func(), acquired bool, err error)
+}
+
+// Memory is an in-process Locker. It only excludes holders in this replica.
+type Memory struct {
+	mu    sync.Mutex
+	locks map[string]memoryLock
+}
+
+// memoryLock is a held lock and its holder
+type memoryLock struct {
+	token     string
+	expiresAt time.Time
+}
+
+// NewMemory creates an in-memory locker
+func NewMemory() *Memory {
+	return &Memory{locks: make(map[string]memoryLock)}
+}
+
+// TryLock acquires the lock if it is free or has lapsed
+func (m *Memory) TryLock(ctx context.Context, name string, ttl time.Duration) (func(), bool, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, false, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if held, ok := m.locks[name]; ok && time.Now().Before(held.expiresAt) {
+		return nil, false, nil
+	}
+	m.locks[name] = memoryLock{token: token, expiresAt: time.Now().Add(ttl)}
+
+	release := func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if m.locks[name].token == token {
+			delete(m.locks, name)
+		}
+	}
+	return release, true, nil
+}
+
+// releaseScript deletes a lock only if it is still held by the given token
+var releaseScript = goredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Redis is a Locker shared by all gateway replicas
+type Redis struct {
+	client *goredis.Client
+	prefix string
+}
+
+// NewRedis creates a locker using client, namespacing keys with prefix
+func NewRedis(client *goredis.Client, prefix string) *Redis {
+	return &Redis{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// TryLock acquires the lock if no other holder has it
+func (r *Redis) TryLock(ctx context.Context, name string, ttl time.Duration) (func(), bool, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, false, err
+	}
+
+	key := r.prefix + name
+	acquired, err := r.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil || !acquired {
+		return nil, false, err
+	}
+
+	release := func() {
+		// Release even if the holder's context was cancelled; a failure
+		// leaves the lock to lapse with its TTL
+		releaseScript.Run(context.WithoutCancel(ctx), r.client, []string{key}, token)
+	}
+	return release, true, nil
+}
+
+// newToken identifies one holding of a lock
+func newToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
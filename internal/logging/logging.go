@@ -0,0 +1,127 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+)
+
+// ctxKey is the context key for the request-scoped logger
+type ctxKey struct{}
+
+// level is the minimum level of the default logger, adjustable at runtime
+var level = new(slog.LevelVar)
+
+// Pending revert of a temporary level change
+var (
+	revertMu    sync.Mutex
+	revertTimer *time.Timer
+	revertAt    time.Time
+	revertTo    slog.Level
+)
+
+// Setup installs the default logger, writing JSON or text to stdout at the
+// configured level. Output from the standard log package is routed through
+// it as well.
+func Setup(cfg *config.Config) {
+	level.Set(parseLevel(cfg.LogLevel))
+	slog.SetDefault(newLogger(cfg.LogFormat, level))
+}
+
+// New creates a logger writing JSON, or text when format is "text", to
+// stdout at the given level
+func New(format, level string) *slog.Logger {
+	return newLogger(format, parseLevel(level))
+}
+
+// newLogger creates a logger writing JSON or text to stdout at level
+func newLogger(format string, level slog.Leveler) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level}
+	if strings.EqualFold(format, "text") {
+		return slog.New(slog.NewTextHandler(os.Stdout, opts))
+	}
+	return slog.New(slog.NewJSONHandler(os.Stdout, opts))
+}
+
+// Level returns the current level of the default logger and, if a temporary
+// change is in effect, when it reverts
+func Level() (string, *time.Time) {
+	revertMu.Lock()
+	defer revertMu.Unlock()
+
+	name := strings.ToLower(level.Level().String())
+	if revertTimer == nil {
+		return name, nil
+	}
+	at := revertAt
+	return name, &at
+}
+
+// SetLevel changes the level of the default logger. When revertAfter is
+// positive the previous level is restored once it elapses. Any pending
+// revert is cancelled.
+func SetLevel(name string, revertAfter time.Duration) {
+	revertMu.Lock()
+	defer revertMu.Unlock()
+
+	// Revert to the level in effect before any temporary change
+	previous := level.Level()
+	if revertTimer != nil {
+		revertTimer.Stop()
+		revertTimer = nil
+		previous = revertTo
+	}
+
+	level.Set(parseLevel(name))
+	slog.Warn("Log level changed", "level", strings.ToLower(level.Level().String()), "revert_after", revertAfter.String())
+
+	if revertAfter > 0 {
+		var timer *time.Timer
+		timer = time.AfterFunc(revertAfter, func() {
+			revertMu.Lock()
+			defer revertMu.Unlock()
+			if revertTimer != timer {
+				return // superseded by a later change
+			}
+			revertTimer = nil
+			level.Set(previous)
+			slog.Warn("Log level reverted", "level", strings.ToLower(previous.String()))
+		})
+		revertTimer = timer
+		revertAt = time.Now().Add(revertAfter)
+		revertTo = previous
+	}
+}
+
+// NewContext returns a copy of ctx carrying logger
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, logger)
+}
+
+// FromContext returns the request-scoped logger carried by ctx, or the
+// default logger
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// parseLevel maps a level name to a slog level, defaulting to info
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
@@ -0,0 +1,49 @@
+package logging
+
+import (
+	"context"
+	"sync"
+)
+
+// upstreamsKey is the context key for the request's upstream recorder
+type upstreamsKey struct{}
+
+// upstreams collects the backend services called while handling a request
+type upstreams struct {
+	mu    sync.Mutex
+	names []string
+}
+
+// WithUpstreams returns a copy of ctx that records the backend services
+// called with it
+func WithUpstreams(ctx context.Context) context.Context {
+	return context.WithValue(ctx, upstreamsKey{}, &upstreams{})
+}
+
+// RecordUpstream notes that service was called. It does nothing if ctx was
+// not prepared with WithUpstreams.
+func RecordUpstream(ctx context.Context, service string) {
+	u, ok := ctx.Value(upstreamsKey{}).(*upstreams)
+	if !ok {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, name := range u.names {
+		if name == service {
+			return
+		}
+	}
+	u.names = append(u.names, service)
+}
+
+// Upstreams returns the backend services recorded on ctx in call order
+func Upstreams(ctx context.Context) []string {
+	u, ok := ctx.Value(upstreamsKey{}).(*upstreams)
+	if !ok {
+		return nil
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return append([]string{}, u.names...)
+}
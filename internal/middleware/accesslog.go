@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"log/slog"
+	"math/rand"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+)
+
+// AccessLogMiddleware writes one structured line per request with its
+// method, path, status, latency, response size, and the backend services it
+// called. Server errors are always logged; other requests are sampled at the
+// configured rate. Register it before RequestIDMiddleware so the logged size
+// includes error bodies.
+func AccessLogMiddleware(cfg *config.Config) gin.HandlerFunc {
+	if !cfg.AccessLogEnabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	format := cfg.AccessLogFormat
+	if format == "" {
+		format = cfg.LogFormat
+	}
+	logger := logging.New(format, "info").With("log", "access")
+
+	excluded := make(map[string]bool, len(cfg.AccessLogExcludePaths))
+	for _, path := range cfg.AccessLogExcludePaths {
+		excluded[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if excluded[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Request = c.Request.WithContext(logging.WithUpstreams(c.Request.Context()))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if status < 500 && rand.Float64() >= cfg.AccessLogSampleRate {
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		size := c.Writer.Size()
+		if size < 0 {
+			size = 0
+		}
+
+		ctx := c.Request.Context()
+		attrs := []any{
+			"request_id", GetRequestID(c),
+			"method", c.Request.Method,
+			"route", route,
+			"path", c.Request.URL.Path,
+			"status", status,
+			"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+			"bytes", size,
+			"client_ip", c.ClientIP(),
+			"upstreams", logging.Upstreams(ctx),
+		}
+		if userID, ok := GetUserID(c); ok {
+			attrs = append(attrs, "user_id", userID)
+		}
+		if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+			attrs = append(attrs, "trace_id", span.TraceID().String())
+		}
+
+		level := slog.LevelInfo
+		if status >= 500 {
+			level = slog.LevelError
+		}
+		logger.Log(ctx, level, "Request completed", attrs...)
+	}
+}
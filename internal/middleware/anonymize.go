@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"log/slog"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/anonymize"
+	"github.com/ecommerce/be-api-gin/internal/config"
+)
+
+// AnonymizedHeader marks responses whose personal data was pseudonymized
+const AnonymizedHeader = "X-Data-Anonymized"
+
+// AnonymizeMiddleware pseudonymizes names, emails, phone numbers and street
+// addresses in JSON responses when ANONYMIZE_RESPONSES is set, so
+// environments running on copies of production data never hand out real
+// personal data. Pseudonyms are stable for a given ANONYMIZATION_KEY.
+func AnonymizeMiddleware(cfg *config.Config) gin.HandlerFunc {
+	if !cfg.AnonymizeResponses {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	key := []byte(cfg.AnonymizationKey)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			panic(err)
+		}
+		slog.Warn("ANONYMIZATION_KEY is not set; pseudonyms will differ between restarts and replicas")
+	}
+	if cfg.Environment == "production" {
+		slog.Warn("Response anonymization is enabled in production; customers will see pseudonymized data")
+	}
+	pseudonymizer := anonymize.New(key)
+
+	return func(c *gin.Context) {
+		original := c.Writer
+		writer := &bufferWriter{ResponseWriter: original}
+		c.Writer = writer
+
+		c.Next()
+
+		c.Writer = original
+		body := writer.body.Bytes()
+		if strings.HasPrefix(writer.Header().Get("Content-Type"), "application/json") {
+			body = pseudonymizer.JSON(body)
+			writer.Header().Set(AnonymizedHeader, "true")
+			writer.Header().Del("Content-Length")
+		}
+		original.Write(body)
+	}
+}
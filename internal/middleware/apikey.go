@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// APIKeyHeader is the header carrying server-to-server API keys
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyPrefix marks gateway-issued API keys
+const apiKeyPrefix = "ak_"
+
+// ErrInvalidAPIKey is returned when an API key is unknown, revoked, or expired
+var ErrInvalidAPIKey = errors.New("invalid api key")
+
+// APIKeyValidator looks up an API key by the hash of its secret. It returns
+// nil without an error when no key matches.
+type APIKeyValidator interface {
+	ValidateAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error)
+}
+
+// apiKeyValidator is the validator consulted by the auth middleware. API key
+// authentication is disabled until one is set.
+var apiKeyValidator APIKeyValidator
+
+// SetAPIKeyValidator sets the validator used to authenticate X-API-Key requests
+func SetAPIKeyValidator(validator APIKeyValidator) {
+	apiKeyValidator = validator
+}
+
+// GenerateAPIKey creates a new random API key, returning the plaintext key,
+// its display prefix, and the hash to persist
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", err
+	}
+	key = apiKeyPrefix + hex.EncodeToString(buf)
+	return key, key[:len(apiKeyPrefix)+8], HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the hash under which an API key is stored
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// authenticateAPIKey validates the request's API key and attaches the key's
+// identity to the context, aborting the request if it is not allowed
+func authenticateAPIKey(c *gin.Context, key string) bool {
+	apiKey, err := resolveAPIKey(c, key)
+	if err != nil {
+		if err == ErrInvalidAPIKey {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+				Error:   "Invalid API key",
+				Message: "The provided API key is invalid, expired, or revoked",
+			})
+			return false
+		}
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Error:   "Authentication unavailable",
+			Message: "Unable to verify API key, please retry",
+		})
+		return false
+	}
+
+	if !ScopeAllows(apiKey.Scopes, c.Request.Method, c.FullPath()) {
+		c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "Forbidden",
+			Message: "The API key is not scoped for this route",
+		})
+		return false
+	}
+
+	setAPIKey(c, apiKey)
+	return true
+}
+
+// lookupAPIKey resolves a plaintext API key to its record
+func lookupAPIKey(ctx context.Context, key string) (*models.APIKey, error) {
+	if apiKeyValidator == nil || !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	apiKey, err := apiKeyValidator.ValidateAPIKey(ctx, HashAPIKey(key))
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == nil || apiKey.RevokedAt != nil {
+		return nil, ErrInvalidAPIKey
+	}
+	if apiKey.ExpiresAt != nil && time.Now().After(apiKey.ExpiresAt.Time) {
+		return nil, ErrInvalidAPIKey
+	}
+	return apiKey, nil
+}
+
+// setAPIKey stores the API key's identity in the context
+func setAPIKey(c *gin.Context, apiKey *models.APIKey) {
+	c.Set("userID", apiKey.OwnerID)
+	c.Set("roles", apiKey.Roles)
+	c.Set("apiKeyID", apiKey.ID)
+	c.Set("scopes", apiKey.Scopes)
+	c.Set("authMethod", "api_key")
+	recordInFlightUser(c, apiKey.OwnerID)
+	addLogAttrs(c, "user_id", apiKey.OwnerID, "api_key_id", apiKey.ID)
+}
+
+// ScopeAllows reports whether any scope permits method on the route pattern.
+// Scopes take the form "<METHOD> <path>", where the method may be "*" and
+// the path is relative to the API root (e.g. "GET /products/:id"). A path
+// ending in "/*" matches everything beneath it, and "*" alone matches all
+// routes.
+func ScopeAllows(scopes []string, method, fullPath string) bool {
+	path := apiRelativePath(fullPath)
+	for _, scope := range scopes {
+		if scope == "*" {
+			return true
+		}
+		parts := strings.SplitN(scope, " ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if parts[0] != "*" && !strings.EqualFold(parts[0], method) {
+			continue
+		}
+		pattern := parts[1]
+		if pattern == path {
+			return true
+		}
+		if strings.HasSuffix(pattern, "/*") {
+			base := strings.TrimSuffix(pattern, "/*")
+			if path == base || strings.HasPrefix(path, base+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// apiRelativePath strips the versioned or unversioned API prefix from a route
+func apiRelativePath(fullPath string) string {
+	for _, prefix := range []string{"/api/v1", "/api"} {
+		if strings.HasPrefix(fullPath, prefix+"/") {
+			return strings.TrimPrefix(fullPath, prefix)
+		}
+	}
+	return fullPath
+}
+
+// IsAPIKeyAuth reports whether the request was authenticated with an API key
+func IsAPIKeyAuth(c *gin.Context) bool {
+	return c.GetString("authMethod") == "api_key"
+}
@@ -7,23 +7,81 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 
+	"github.com/ecommerce/be-api-gin/internal/affinity"
 	"github.com/ecommerce/be-api-gin/internal/config"
 	"github.com/ecommerce/be-api-gin/internal/models"
 )
 
 // Claims represents JWT claims
 type Claims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID string   `json:"user_id"`
+	Email  string   `json:"email"`
+	Role   string   `json:"role"`
+	Roles  []string `json:"roles,omitempty"`
+	// AuthTime and AMR describe the login that issued the token; step-up
+	// verification requires a recent multi-factor login
+	AuthTime int64    `json:"auth_time,omitempty"`
+	AMR      []string `json:"amr,omitempty"`
+	// TokenUse is ClientTokenUse on client credentials tokens, which act for
+	// the machine client named by ClientID, with Scope, rather than a user.
+	// ClientID alone doesn't make a token a client's; identity providers
+	// set it on users' tokens too.
+	TokenUse string `json:"token_use,omitempty"`
+	ClientID string `json:"client_id,omitempty"`
+	Scope    string `json:"scope,omitempty"`
+	// Timezone is the IANA time zone from the user's profile, if set
+	Timezone string `json:"tz,omitempty"`
+	// DeviceID and StoreID are set on POS tokens, which act for an in-store
+	// device and are only accepted by the POS API
+	DeviceID string `json:"device_id,omitempty"`
+	StoreID  string `json:"store_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// AuthMiddleware creates a JWT authentication middleware
+// ClientTokenUse is the token_use claim of client credentials tokens
+const ClientTokenUse = "client_credentials"
+
+// IsClient reports whether the token was issued to a machine client
+func (c *Claims) IsClient() bool {
+	return c.TokenUse == ClientTokenUse
+}
+
+// IsDevice reports whether the token claims to be an in-store device's
+func (c *Claims) IsDevice() bool {
+	return c.DeviceID != ""
+}
+
+// Scopes returns the space-separated scope claim as a list
+func (c *Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+// AllRoles returns the union of the single role claim and the roles list
+func (c *Claims) AllRoles() []string {
+	roles := make([]string, 0, len(c.Roles)+1)
+	if c.Role != "" {
+		roles = append(roles, c.Role)
+	}
+	for _, role := range c.Roles {
+		if role != "" && role != c.Role {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// AuthMiddleware creates a JWT authentication middleware. Requests without an
+// Authorization header may authenticate with an API key instead.
 func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
+			if key := c.GetHeader(APIKeyHeader); key != "" {
+				if authenticateAPIKey(c, key) {
+					c.Next()
+				}
+				return
+			}
 			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
 				Error:   "Missing authorization header",
 				Message: "Please provide a valid JWT token in the Authorization header",
@@ -32,8 +90,8 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 		}
 
 		// Check Bearer prefix
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		tokenString, ok := bearerToken(authHeader)
+		if !ok {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
 				Error:   "Invalid authorization header format",
 				Message: "Authorization header must be in the format: Bearer <token>",
@@ -41,18 +99,8 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		tokenString := parts[1]
-
 		// Parse and validate token
-		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			// Validate signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(cfg.JWTSecret), nil
-		})
-
+		claims, token, err := parseToken(cfg, tokenString)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
 				Error:   "Invalid token",
@@ -61,7 +109,7 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		if !token.Valid {
+		if !token.Valid || (claims.UserID == "" && !claims.IsClient()) {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
 				Error:   "Invalid token",
 				Message: "The provided token is not valid",
@@ -69,11 +117,32 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
+		// Reject tokens revoked before expiry
+		id := tokenID(claims, tokenString)
+		revoked, err := isRevoked(c.Request.Context(), id)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, models.ErrorResponse{
+				Error:   "Authentication unavailable",
+				Message: "Unable to verify token status, please retry",
+			})
+			return
+		}
+		if revoked {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+				Error:   "Invalid token",
+				Message: "The provided token has been revoked",
+			})
+			return
+		}
+
+		// Machine clients may only call routes their scopes cover
+		if claims.IsClient() && !requireRouteScope(c, claims.Scopes()) {
+			return
+		}
+
 		// Set user information in context
-		c.Set("userID", claims.UserID)
-		c.Set("email", claims.Email)
-		c.Set("role", claims.Role)
-		c.Set("claims", claims)
+		setClaims(c, claims)
+		c.Set("tokenID", id)
 
 		c.Next()
 	}
@@ -85,31 +154,30 @@ func OptionalAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
+			if key := c.GetHeader(APIKeyHeader); key != "" {
+				apiKey, err := resolveAPIKey(c, key)
+				if err == nil && ScopeAllows(apiKey.Scopes, c.Request.Method, c.FullPath()) {
+					setAPIKey(c, apiKey)
+				}
+			}
 			c.Next()
 			return
 		}
 
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		tokenString, ok := bearerToken(authHeader)
+		if !ok {
 			c.Next()
 			return
 		}
 
-		tokenString := parts[1]
-
-		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
+		claims, token, err := parseToken(cfg, tokenString)
+		if err == nil && token.Valid && (claims.UserID != "" || claims.IsClient()) {
+			id := tokenID(claims, tokenString)
+			scoped := !claims.IsClient() || routeScopes.Allows(claims.Scopes(), c.Request.Method, c.FullPath())
+			if revoked, err := isRevoked(c.Request.Context(), id); err == nil && !revoked && scoped {
+				setClaims(c, claims)
+				c.Set("tokenID", id)
 			}
-			return []byte(cfg.JWTSecret), nil
-		})
-
-		if err == nil && token.Valid {
-			c.Set("userID", claims.UserID)
-			c.Set("email", claims.Email)
-			c.Set("role", claims.Role)
-			c.Set("claims", claims)
 		}
 
 		c.Next()
@@ -119,8 +187,7 @@ func OptionalAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 // AdminMiddleware ensures the user has admin role
 func AdminMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		role, exists := c.Get("role")
-		if !exists {
+		if _, exists := c.Get("roles"); !exists {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
 				Error:   "Unauthorized",
 				Message: "Authentication required",
@@ -128,7 +195,7 @@ func AdminMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if role != "admin" {
+		if !HasRole(c, "admin") {
 			c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{
 				Error:   "Forbidden",
 				Message: "Admin access required",
@@ -139,3 +206,129 @@ func AdminMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// GetUserID returns the authenticated user's ID from the context
+func GetUserID(c *gin.Context) (string, bool) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		return "", false
+	}
+	id, ok := userID.(string)
+	return id, ok && id != ""
+}
+
+// GetClaims returns the authenticated user's token claims from the context
+func GetClaims(c *gin.Context) (*Claims, bool) {
+	claims, ok := c.Get("claims")
+	if !ok {
+		return nil, false
+	}
+	typed, ok := claims.(*Claims)
+	return typed, ok
+}
+
+// GetTokenID returns the revocation identifier of the request's token
+func GetTokenID(c *gin.Context) (string, bool) {
+	id, ok := c.Get("tokenID")
+	if !ok {
+		return "", false
+	}
+	typed, ok := id.(string)
+	return typed, ok
+}
+
+// GetRoles returns the authenticated user's roles from the context
+func GetRoles(c *gin.Context) []string {
+	roles, _ := c.Get("roles")
+	list, _ := roles.([]string)
+	return list
+}
+
+// HasRole reports whether the authenticated user has the given role
+func HasRole(c *gin.Context, role string) bool {
+	for _, r := range GetRoles(c) {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// bearerToken extracts the token from a "Bearer <token>" header value
+func bearerToken(authHeader string) (string, bool) {
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" || parts[1] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// parseToken parses and validates a JWT. HS256 tokens are verified with the
+// shared secret and RS256 tokens with keys from the configured JWKS endpoint.
+// Tokens without an expiry are rejected.
+func parseToken(cfg *config.Config, tokenString string) (*Claims, *jwt.Token, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		// Validate signing method
+		switch token.Method.(type) {
+		case *jwt.SigningMethodHMAC:
+			// Client tokens this gateway issues are signed with a key of
+			// their own
+			if claims.IsClient() {
+				if cfg.OAuthTokenSecret == "" {
+					return nil, jwt.ErrSignatureInvalid
+				}
+				return []byte(cfg.OAuthTokenSecret), nil
+			}
+			// So are POS tokens, so a token signed with the user key
+			// can't pass as a device's
+			if claims.IsDevice() {
+				if cfg.POSTokenSecret == "" {
+					return nil, jwt.ErrSignatureInvalid
+				}
+				return []byte(cfg.POSTokenSecret), nil
+			}
+			if cfg.JWTSecret == "" {
+				return nil, jwt.ErrSignatureInvalid
+			}
+			return []byte(cfg.JWTSecret), nil
+		case *jwt.SigningMethodRSA:
+			if cfg.JWKSURL == "" {
+				return nil, jwt.ErrSignatureInvalid
+			}
+			kid, _ := token.Header["kid"].(string)
+			return getJWKS(cfg.JWKSURL).Key(kid)
+		default:
+			return nil, jwt.ErrSignatureInvalid
+		}
+	},
+		jwt.WithValidMethods([]string{"HS256", "RS256"}),
+		jwt.WithExpirationRequired(),
+	)
+	return claims, token, err
+}
+
+// setClaims stores the authenticated user's claims in the context
+func setClaims(c *gin.Context, claims *Claims) {
+	userID := claims.UserID
+	if userID == "" {
+		userID = claims.ClientID
+	}
+	c.Set("userID", userID)
+	c.Set("email", claims.Email)
+	c.Set("role", claims.Role)
+	c.Set("roles", claims.AllRoles())
+	c.Set("claims", claims)
+	recordInFlightUser(c, userID)
+	// Backend calls for the user stick to one replica where affinity is on
+	c.Request = c.Request.WithContext(affinity.NewContext(c.Request.Context(), userID))
+	if claims.IsClient() {
+		c.Set("scopes", claims.Scopes())
+		c.Set("authMethod", "client_credentials")
+		addLogAttrs(c, "user_id", userID, "client_id", claims.ClientID)
+		return
+	}
+	c.Set("authMethod", "jwt")
+	addLogAttrs(c, "user_id", userID)
+	attachSegments(c, userID)
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/redact"
+)
+
+// BodyLogMiddleware logs request and response bodies for debugging, for a
+// sample of requests and for every request to the configured routes.
+// Sensitive fields are redacted, non-JSON bodies are omitted, and bodies are
+// truncated to the configured size. Register it after LoggerMiddleware.
+func BodyLogMiddleware(cfg *config.Config) gin.HandlerFunc {
+	if cfg.BodyLogSampleRate <= 0 && len(cfg.BodyLogRoutes) == 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	routes := make(map[string]bool, len(cfg.BodyLogRoutes))
+	for _, route := range cfg.BodyLogRoutes {
+		routes[route] = true
+	}
+	maxBytes := cfg.BodyLogMaxBytes
+
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if !routes[route] && !routes[c.Request.Method+" "+route] && rand.Float64() >= cfg.BodyLogSampleRate {
+			c.Next()
+			return
+		}
+
+		// Capture the start of the request body, leaving it intact for handlers
+		var requestBody []byte
+		if c.Request.Body != nil {
+			requestBody, _ = io.ReadAll(io.LimitReader(c.Request.Body, int64(maxBytes)+1))
+			c.Request.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(requestBody), c.Request.Body), c.Request.Body}
+		}
+
+		writer := &bodyLogWriter{ResponseWriter: c.Writer, max: maxBytes}
+		c.Writer = writer
+
+		c.Next()
+
+		logging.FromContext(c.Request.Context()).Info("Request bodies",
+			"log", "body",
+			"status", c.Writer.Status(),
+			"request_body", loggableBody(c.ContentType(), requestBody, maxBytes),
+			"response_body", loggableBody(c.Writer.Header().Get("Content-Type"), writer.body.Bytes(), maxBytes),
+		)
+	}
+}
+
+// bodyLogWriter copies up to max bytes of the response body
+type bodyLogWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+	max  int
+}
+
+// Write copies the start of the body and passes it through
+func (w *bodyLogWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString copies the start of the body and passes it through
+func (w *bodyLogWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// capture keeps at most one byte beyond the limit so truncation is detectable
+func (w *bodyLogWriter) capture(data []byte) {
+	if remaining := w.max + 1 - w.body.Len(); remaining > 0 {
+		if len(data) > remaining {
+			data = data[:remaining]
+		}
+		w.body.Write(data)
+	}
+}
+
+// loggableBody returns a redacted JSON body, or a note explaining why the
+// body was left out
+func loggableBody(contentType string, body []byte, maxBytes int) string {
+	switch {
+	case len(body) == 0:
+		return ""
+	case !strings.Contains(contentType, "json"):
+		return "[omitted " + contentType + " body]"
+	case len(body) > maxBytes:
+		return "[omitted body larger than " + strconv.Itoa(maxBytes) + " bytes]"
+	}
+	return string(redact.JSON(body))
+}
@@ -0,0 +1,242 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+)
+
+// Supported content encodings, in order of preference
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+)
+
+// CompressionMiddleware compresses responses with brotli or gzip, whichever
+// the client prefers. Only responses of the configured content types are
+// compressed, once they reach that type's minimum size. Register it before
+// middleware that inspects response bodies so they see uncompressed data.
+func CompressionMiddleware(cfg *config.Config) gin.HandlerFunc {
+	if !cfg.CompressionEnabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	rules := &compressionRules{
+		types:      cfg.CompressionTypes,
+		minBytes:   cfg.CompressionMinBytes,
+		byTypeMins: cfg.CompressionMinBytesByType,
+	}
+
+	return func(c *gin.Context) {
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" || c.Request.Method == http.MethodHead || c.GetHeader("Upgrade") != "" {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		writer := &compressWriter{ResponseWriter: original, rules: rules, encoding: encoding}
+		c.Writer = writer
+
+		c.Next()
+
+		if err := writer.finish(); err != nil {
+			logging.FromContext(c.Request.Context()).Warn("Response compression failed", "error", err)
+		}
+		c.Writer = original
+	}
+}
+
+// compressionRules decides which responses are worth compressing
+type compressionRules struct {
+	types      []string
+	minBytes   int
+	byTypeMins map[string]int
+}
+
+// threshold returns the minimum body size for compressing a content type, or
+// false if the type is not compressed. Types may be listed exactly or as a
+// wildcard such as text/*.
+func (r *compressionRules) threshold(contentType string) (int, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return 0, false
+	}
+	wildcard := strings.SplitN(mediaType, "/", 2)[0] + "/*"
+
+	for _, candidate := range []string{mediaType, wildcard} {
+		if limit, ok := r.byTypeMins[candidate]; ok {
+			return limit, true
+		}
+	}
+	for _, t := range r.types {
+		if t == mediaType || t == wildcard {
+			return r.minBytes, true
+		}
+	}
+	return 0, false
+}
+
+// negotiateEncoding picks the preferred supported encoding from an
+// Accept-Encoding header, or "" if the response should not be encoded
+func negotiateEncoding(header string) string {
+	best, bestQ := "", 0.0
+	wildcardQ := -1.0
+	seen := map[string]bool{}
+
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		switch name {
+		case "*":
+			wildcardQ = q
+		case encodingBrotli, encodingGzip:
+			seen[name] = true
+			// Brotli wins ties since it compresses JSON better
+			if q > bestQ || (q == bestQ && q > 0 && name == encodingBrotli) {
+				best, bestQ = name, q
+			}
+		}
+	}
+
+	// A wildcard covers encodings not listed explicitly
+	if best == "" && wildcardQ > 0 {
+		for _, name := range []string{encodingBrotli, encodingGzip} {
+			if !seen[name] {
+				return name
+			}
+		}
+	}
+	return best
+}
+
+// compressWriter buffers the start of a response until it knows whether the
+// body is large enough to compress, then streams through an encoder
+type compressWriter struct {
+	gin.ResponseWriter
+	rules    *compressionRules
+	encoding string
+
+	buf     bytes.Buffer
+	decided bool
+	encoder io.WriteCloser
+}
+
+// Write buffers or compresses the body
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		if w.encoder != nil {
+			return w.encoder.Write(data)
+		}
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.buf.Write(data)
+	if limit, ok := w.eligible(); !ok || w.buf.Len() >= limit {
+		if err := w.decide(ok); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+// WriteString buffers or compresses the body
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Written reports whether any of the body has been written
+func (w *compressWriter) Written() bool {
+	return w.buf.Len() > 0 || w.ResponseWriter.Written()
+}
+
+// Flush sends buffered data immediately, compressing it if the content type
+// allows regardless of size
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_, ok := w.eligible()
+		if err := w.decide(ok); err != nil {
+			return
+		}
+	}
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// eligible returns the minimum size for compressing this response, or false
+// if it must not be compressed
+func (w *compressWriter) eligible() (int, bool) {
+	status := w.ResponseWriter.Status()
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return 0, false
+	}
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" {
+		return 0, false
+	}
+	return w.rules.threshold(header.Get("Content-Type"))
+}
+
+// decide sets up the encoder if compressing and writes out the buffer
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+	if compress {
+		header := w.Header()
+		header.Set("Content-Encoding", w.encoding)
+		header.Add("Vary", "Accept-Encoding")
+		header.Del("Content-Length")
+
+		switch w.encoding {
+		case encodingBrotli:
+			w.encoder = brotli.NewWriterLevel(w.ResponseWriter, brotli.DefaultCompression)
+		case encodingGzip:
+			w.encoder = gzip.NewWriter(w.ResponseWriter)
+		}
+	}
+
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	defer w.buf.Reset()
+	if w.encoder != nil {
+		_, err := w.encoder.Write(w.buf.Bytes())
+		return err
+	}
+	_, err := w.ResponseWriter.Write(w.buf.Bytes())
+	return err
+}
+
+// finish writes out a body too small to compress, or closes the encoder
+func (w *compressWriter) finish() error {
+	if !w.decided {
+		if w.buf.Len() == 0 {
+			return nil
+		}
+		return w.decide(false)
+	}
+	if w.encoder != nil {
+		return w.encoder.Close()
+	}
+	return nil
+}
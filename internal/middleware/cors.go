@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -9,41 +10,127 @@ import (
 	"github.com/ecommerce/be-api-gin/internal/config"
 )
 
-// CORSMiddleware creates a CORS middleware with the given configuration
+// CORSMiddleware creates a CORS middleware with the given configuration.
+// Requests from origins that are not allowed get no CORS headers, and their
+// preflight requests are rejected. An origin matched only by "*" is never
+// allowed to send credentials.
 func CORSMiddleware(cfg *config.Config) gin.HandlerFunc {
+	allowedMethods := normalizeList(cfg.AllowedMethods, strings.ToUpper)
+	allowedHeaders := normalizeList(cfg.AllowedHeaders, strings.TrimSpace)
+	exposedHeaders := strings.Join(normalizeList(cfg.ExposedHeaders, strings.TrimSpace), ", ")
+	maxAge := strconv.Itoa(cfg.CORSMaxAge)
+
 	return func(c *gin.Context) {
 		origin := c.GetHeader("Origin")
+		c.Writer.Header().Add("Vary", "Origin")
+		if origin == "" {
+			// Not a cross-origin request
+			c.Next()
+			return
+		}
 
-		// Check if the origin is allowed
-		allowed := false
-		for _, allowedOrigin := range cfg.AllowedOrigins {
-			if allowedOrigin == "*" || allowedOrigin == origin {
-				allowed = true
-				break
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		explicit, allowed := matchOrigin(cfg.AllowedOrigins, origin)
+		if !allowed {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
 			}
+			c.Next()
+			return
 		}
 
-		if allowed {
+		credentials := cfg.AllowCredentials && explicit
+		if credentials {
 			c.Header("Access-Control-Allow-Origin", origin)
+			c.Header("Access-Control-Allow-Credentials", "true")
+		} else if explicit {
+			c.Header("Access-Control-Allow-Origin", origin)
+		} else {
+			c.Header("Access-Control-Allow-Origin", "*")
 		}
 
-		// Set CORS headers
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-Request-ID")
-		c.Header("Access-Control-Expose-Headers", "Content-Length, Content-Type, X-Request-ID")
-		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Max-Age", "86400") // 24 hours
-
 		// Handle preflight requests
-		if c.Request.Method == http.MethodOptions {
+		if preflight {
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+
+			method := strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))
+			if !containsFold(allowedMethods, method) {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			requested := normalizeList(strings.Split(c.GetHeader("Access-Control-Request-Headers"), ","), strings.TrimSpace)
+			for _, header := range requested {
+				if !containsFold(allowedHeaders, header) && !containsFold(allowedHeaders, "*") {
+					c.AbortWithStatus(http.StatusForbidden)
+					return
+				}
+			}
+
+			c.Header("Access-Control-Allow-Methods", strings.Join(allowedMethods, ", "))
+			if len(requested) > 0 {
+				c.Header("Access-Control-Allow-Headers", strings.Join(requested, ", "))
+			}
+			c.Header("Access-Control-Max-Age", maxAge)
 			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
 
+		if exposedHeaders != "" {
+			c.Header("Access-Control-Expose-Headers", exposedHeaders)
+		}
+
 		c.Next()
 	}
 }
 
+// matchOrigin reports whether origin is allowed, and whether it matched an
+// entry other than "*". Entries of the form scheme://*.domain match any
+// subdomain of domain.
+func matchOrigin(allowedOrigins []string, origin string) (explicit, allowed bool) {
+	wildcard := false
+	for _, allowedOrigin := range allowedOrigins {
+		allowedOrigin = strings.TrimSpace(allowedOrigin)
+		switch {
+		case allowedOrigin == "*":
+			wildcard = true
+		case strings.EqualFold(allowedOrigin, origin):
+			return true, true
+		case strings.Contains(allowedOrigin, "://*."):
+			scheme, domain, _ := strings.Cut(allowedOrigin, "://*")
+			if strings.HasPrefix(strings.ToLower(origin), strings.ToLower(scheme)+"://") &&
+				strings.HasSuffix(strings.ToLower(origin), strings.ToLower(domain)) &&
+				len(origin) > len(scheme)+3+len(domain) {
+				return true, true
+			}
+		}
+	}
+	return false, wildcard
+}
+
+// normalizeList trims and normalizes a list of values, dropping empty ones
+func normalizeList(values []string, canonical func(string) string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, canonical(value))
+		}
+	}
+	return result
+}
+
+// containsFold reports whether list contains value, ignoring case
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
 // SecurityHeadersMiddleware adds security headers to all responses
 func SecurityHeadersMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -65,47 +152,3 @@ func SecurityHeadersMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
-
-// RequestIDMiddleware adds a unique request ID to each request
-func RequestIDMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		requestID := c.GetHeader("X-Request-ID")
-		if requestID == "" {
-			requestID = generateRequestID()
-		}
-
-		c.Set("requestID", requestID)
-		c.Header("X-Request-ID", requestID)
-
-		c.Next()
-	}
-}
-
-// generateRequestID generates a simple unique request ID
-func generateRequestID() string {
-	// Simple implementation - in production use UUID
-	const charset = "abcdefghijklmnopqrstuvwxyz0123456789"
-	var sb strings.Builder
-	sb.WriteString("req-")
-	// Using a simple approach - in production use crypto/rand
-	for i := 0; i < 16; i++ {
-		sb.WriteByte(charset[i%len(charset)])
-	}
-	return sb.String()
-}
-
-// RecoveryMiddleware recovers from panics and returns a 500 error
-func RecoveryMiddleware() gin.HandlerFunc {
-	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
-		if err, ok := recovered.(string); ok {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "Internal Server Error",
-				"message": err,
-			})
-		}
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-			"error":   "Internal Server Error",
-			"message": "An unexpected error occurred",
-		})
-	})
-}
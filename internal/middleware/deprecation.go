@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/ecommerce/be-api-gin/internal/deprecation"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+)
+
+// deprecationMaxBodyBytes bounds how much of a body is read to find
+// deprecated fields; larger bodies are passed on without being checked
+const deprecationMaxBodyBytes = 1 << 20
+
+var deprecatedRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "http_deprecated_requests_total",
+	Help: "Requests using deprecated routes or fields, by surface.",
+}, []string{"surface"})
+
+// DeprecationMiddleware announces deprecated routes with Deprecation, Sunset,
+// and Link headers (RFC 9745 and RFC 8594), warns about deprecated fields in
+// use with a Warning header, and records which consumers still call them.
+func DeprecationMiddleware(registry *deprecation.Registry, store deprecation.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := strings.ToUpper(c.Request.Method) + " " + apiRelativePath(c.FullPath())
+
+		var surfaces []string
+		if notice, ok := registry.Routes[route]; ok {
+			setDeprecationHeaders(c, notice)
+			surfaces = append(surfaces, route)
+		}
+		if fields := registry.Fields[route]; len(fields) > 0 {
+			for _, field := range usedFields(c, fields) {
+				c.Writer.Header().Add("Warning", fieldWarning(field))
+				surfaces = append(surfaces, field.Surface(route))
+			}
+		}
+
+		c.Next()
+
+		if len(surfaces) == 0 {
+			return
+		}
+		// Consumers are only recorded in the store; as metric labels they
+		// would grow without bound
+		consumer := DeprecationConsumer(c)
+		now := time.Now()
+		for _, surface := range surfaces {
+			deprecatedRequestsTotal.WithLabelValues(surface).Inc()
+			if consumer == "" {
+				continue
+			}
+			if err := store.Record(c.Request.Context(), consumer, surface, now); err != nil {
+				logging.FromContext(c.Request.Context()).Warn("Failed to record deprecated surface usage", "surface", surface, "error", err)
+			}
+		}
+	}
+}
+
+// DeprecationConsumer identifies the integration making a request, so it can
+// be told which deprecated surfaces it still uses. First-party users and
+// anonymous callers are not tracked individually and return "".
+func DeprecationConsumer(c *gin.Context) string {
+	if id := c.GetString("apiKeyID"); id != "" {
+		return "api_key:" + id
+	}
+	if id := c.GetString("partnerID"); id != "" {
+		return "partner:" + id
+	}
+	if c.GetString("authMethod") == "client_credentials" {
+		return "client:" + c.GetString("userID")
+	}
+	return ""
+}
+
+// setDeprecationHeaders announces a deprecated route
+func setDeprecationHeaders(c *gin.Context, notice deprecation.Notice) {
+	header := c.Writer.Header()
+	header.Set("Deprecation", "@"+strconv.FormatInt(notice.Since.Unix(), 10))
+	if !notice.Sunset.IsZero() {
+		header.Set("Sunset", notice.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if notice.Successor != "" {
+		header.Add("Link", "<"+notice.Successor+`>; rel="successor-version"`)
+	}
+}
+
+// fieldWarning describes a deprecated field in a Warning header
+func fieldWarning(field deprecation.Field) string {
+	text := fmt.Sprintf("%s %s is deprecated", field.In, field.Name)
+	if field.Successor != "" {
+		text += ", use " + field.Successor
+	}
+	if !field.Sunset.IsZero() {
+		text += ", removal after " + field.Sunset.UTC().Format(time.DateOnly)
+	}
+	return `299 - "` + strings.ReplaceAll(text, `"`, `'`) + `"`
+}
+
+// usedFields returns the deprecated fields the request uses. Response fields
+// are used by every call; query parameters and top-level body fields only
+// when present.
+func usedFields(c *gin.Context, fields []deprecation.Field) []deprecation.Field {
+	var body map[string]json.RawMessage
+	var used []deprecation.Field
+	for _, field := range fields {
+		switch field.In {
+		case deprecation.InResponse:
+			used = append(used, field)
+		case deprecation.InQuery:
+			if c.Request.URL.Query().Has(field.Name) {
+				used = append(used, field)
+			}
+		case deprecation.InBody:
+			if body == nil {
+				body = peekJSONBody(c)
+			}
+			if _, ok := body[field.Name]; ok {
+				used = append(used, field)
+			}
+		}
+	}
+	return used
+}
+
+// peekJSONBody decodes a JSON object body's top-level fields, leaving the
+// body in place for the handler. Bodies over deprecationMaxBodyBytes aren't
+// decoded.
+func peekJSONBody(c *gin.Context) map[string]json.RawMessage {
+	fields := make(map[string]json.RawMessage)
+	if c.Request.Body == nil || !strings.HasPrefix(c.ContentType(), "application/json") {
+		return fields
+	}
+	body := c.Request.Body
+	data, err := io.ReadAll(io.LimitReader(body, deprecationMaxBodyBytes+1))
+	c.Request.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), body), body}
+	if err == nil && len(data) <= deprecationMaxBodyBytes {
+		json.Unmarshal(data, &fields)
+	}
+	return fields
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// IssueDeviceToken signs a short-lived POS token for an in-store device with
+// POSTokenSecret, returning the token and its lifetime. POS tokens carry no
+// user, so the other authentication middleware refuses them.
+func IssueDeviceToken(cfg *config.Config, device *config.POSDevice) (string, time.Duration, error) {
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", 0, err
+	}
+
+	ttl := time.Duration(cfg.POSTokenTTLSec) * time.Second
+	now := time.Now()
+	claims := &Claims{
+		DeviceID: device.DeviceID,
+		StoreID:  device.StoreID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        hex.EncodeToString(jti),
+			Subject:   "device:" + device.DeviceID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(cfg.POSTokenSecret))
+	if err != nil {
+		return "", 0, err
+	}
+	return token, ttl, nil
+}
+
+// DeviceAuthMiddleware authenticates in-store devices with a POS token.
+// Tokens of devices that have since been disabled or removed are refused.
+func DeviceAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, ok := bearerToken(c.GetHeader("Authorization"))
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+				Error:   "Missing authorization header",
+				Message: "Please provide a POS device token in the Authorization header",
+			})
+			return
+		}
+
+		claims, token, err := parseToken(cfg, tokenString)
+		if err != nil || !token.Valid || claims.DeviceID == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+				Error:   "Invalid token",
+				Message: "The provided token is not a valid POS device token",
+			})
+			return
+		}
+
+		device, exists := cfg.POSDevices[claims.DeviceID]
+		if !exists || device.Disabled || device.StoreID != claims.StoreID {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+				Error:   "Invalid token",
+				Message: "The device is no longer registered",
+			})
+			return
+		}
+
+		id := tokenID(claims, tokenString)
+		revoked, err := isRevoked(c.Request.Context(), id)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, models.ErrorResponse{
+				Error:   "Authentication unavailable",
+				Message: "Unable to verify token status, please retry",
+			})
+			return
+		}
+		if revoked {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+				Error:   "Invalid token",
+				Message: "The provided token has been revoked",
+			})
+			return
+		}
+
+		c.Set("deviceID", claims.DeviceID)
+		c.Set("storeID", claims.StoreID)
+		c.Set("tokenID", id)
+		c.Set("authMethod", "device")
+		addLogAttrs(c, "device_id", claims.DeviceID, "store_id", claims.StoreID)
+
+		c.Next()
+	}
+}
+
+// GetDevice returns the authenticated device and its store from the context
+func GetDevice(c *gin.Context) (deviceID, storeID string, ok bool) {
+	deviceID = c.GetString("deviceID")
+	storeID = c.GetString("storeID")
+	return deviceID, storeID, deviceID != ""
+}
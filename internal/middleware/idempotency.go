@@ -0,0 +1,348 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/ecommerce/be-api-gin/internal/cache"
+	"github.com/ecommerce/be-api-gin/internal/cart"
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/errorcodes"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	redisclient "github.com/ecommerce/be-api-gin/pkg/redis"
+)
+
+// Headers for idempotent requests
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// maxIdempotencyKeyLength bounds keys stored in the idempotency store
+const maxIdempotencyKeyLength = 255
+
+// maxIdempotentBodyBytes bounds request bodies read to fingerprint them
+const maxIdempotentBodyBytes = 1 << 20
+
+// idempotencyPendingTTL is how long a key stays claimed by a request that
+// has not finished, so a crashed request does not block its retries for long
+const idempotencyPendingTTL = 2 * time.Minute
+
+// Idempotency error codes returned in ErrorResponse.Code
+const (
+	IdempotencyCodeInProgress = errorcodes.IdempotencyKeyInProgress
+	IdempotencyCodeReused     = errorcodes.IdempotencyKeyReused
+)
+
+// IdempotencyRecord is the state of an idempotency key: the fingerprint of
+// the request that first used it, and its response once it has finished
+type IdempotencyRecord struct {
+	Fingerprint string          `json:"fingerprint"`
+	Response    *cache.Response `json:"response,omitempty"`
+	StoredAt    time.Time       `json:"stored_at"`
+}
+
+// IdempotencyStore remembers the requests made with each idempotency key
+type IdempotencyStore interface {
+	// Begin claims key for a request with fingerprint for ttl, returning
+	// true if it was free. Otherwise it returns the key's existing record.
+	Begin(ctx context.Context, key, fingerprint string, ttl time.Duration) (bool, *IdempotencyRecord, error)
+	// Complete stores the response of the request that claimed key
+	Complete(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error
+	// Release frees key so the request can be retried
+	Release(ctx context.Context, key string) error
+	// Purge removes records stored before the given time, returning how
+	// many it removed
+	Purge(ctx context.Context, before time.Time) (int, error)
+}
+
+// MemoryIdempotencyStore is an in-process IdempotencyStore. It only
+// deduplicates retries that reach the same gateway instance. Expired records
+// are ignored, and dropped by Purge.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*memoryIdempotencyRecord
+}
+
+// memoryIdempotencyRecord is a record with its expiry
+type memoryIdempotencyRecord struct {
+	record    IdempotencyRecord
+	expiresAt time.Time
+}
+
+// NewMemoryIdempotencyStore creates an empty in-memory idempotency store
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		records: make(map[string]*memoryIdempotencyRecord),
+	}
+}
+
+// Begin claims key unless an unexpired record holds it
+func (s *MemoryIdempotencyStore) Begin(ctx context.Context, key, fingerprint string, ttl time.Duration) (bool, *IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if entry, ok := s.records[key]; ok && !now.After(entry.expiresAt) {
+		record := entry.record
+		return false, &record, nil
+	}
+	s.records[key] = &memoryIdempotencyRecord{
+		record:    IdempotencyRecord{Fingerprint: fingerprint, StoredAt: now},
+		expiresAt: now.Add(ttl),
+	}
+	return true, nil, nil
+}
+
+// Complete stores the response for key
+func (s *MemoryIdempotencyStore) Complete(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	stored := *record
+	stored.StoredAt = now
+	s.records[key] = &memoryIdempotencyRecord{record: stored, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// Release frees key
+func (s *MemoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// Purge removes records stored before the given time, along with any
+// that have expired
+func (s *MemoryIdempotencyStore) Purge(ctx context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	purged := 0
+	for key, entry := range s.records {
+		if entry.record.StoredAt.Before(before) || now.After(entry.expiresAt) {
+			delete(s.records, key)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// RedisIdempotencyStore is an IdempotencyStore shared by all gateway
+// replicas
+type RedisIdempotencyStore struct {
+	client *goredis.Client
+	prefix string
+}
+
+// NewRedisIdempotencyStore creates an idempotency store using client,
+// namespacing keys with prefix
+func NewRedisIdempotencyStore(client *goredis.Client, prefix string) *RedisIdempotencyStore {
+	return &RedisIdempotencyStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Begin atomically claims key, reading its record if it is already held
+func (s *RedisIdempotencyStore) Begin(ctx context.Context, key, fingerprint string, ttl time.Duration) (bool, *IdempotencyRecord, error) {
+	data, err := json.Marshal(IdempotencyRecord{Fingerprint: fingerprint, StoredAt: time.Now()})
+	if err != nil {
+		return false, nil, err
+	}
+	claimed, err := s.client.SetNX(ctx, s.prefix+key, data, ttl).Result()
+	if err != nil || claimed {
+		return claimed, nil, err
+	}
+
+	existing, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		// The record expired or was released in between; try again
+		return s.Begin(ctx, key, fingerprint, ttl)
+	}
+	if err != nil {
+		return false, nil, err
+	}
+	var record IdempotencyRecord
+	if err := json.Unmarshal(existing, &record); err != nil {
+		return false, nil, err
+	}
+	return false, &record, nil
+}
+
+// Complete stores the response for key
+func (s *RedisIdempotencyStore) Complete(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error {
+	stored := *record
+	stored.StoredAt = time.Now()
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.prefix+key, data, ttl).Err()
+}
+
+// Release frees key
+func (s *RedisIdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.prefix+key).Err()
+}
+
+// Purge removes records stored before the given time
+func (s *RedisIdempotencyStore) Purge(ctx context.Context, before time.Time) (int, error) {
+	return redisclient.Purge(ctx, s.client, s.prefix+"*", func(value []byte) bool {
+		var record IdempotencyRecord
+		return json.Unmarshal(value, &record) == nil && record.StoredAt.Before(before)
+	})
+}
+
+// IdempotencyMiddleware makes requests carrying an Idempotency-Key header
+// safe to retry. The first request with a key runs normally and its response
+// is stored; retries with the same key and body get the stored response back
+// with an Idempotent-Replayed header instead of running again. A retry while
+// the first request is still running is rejected with 409, and reusing a key
+// for a different request with 422. Keys are scoped to the signed-in user,
+// or for guests to their cart session; guests without one are refused.
+// Server errors are not stored, so the request can be retried once the
+// failure has been compensated. Requests without the header are unaffected.
+func IdempotencyMiddleware(cfg *config.Config, store IdempotencyStore) gin.HandlerFunc {
+	ttl := time.Duration(cfg.IdempotencyKeyTTLHours) * time.Hour
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid idempotency key",
+				Message: "Idempotency-Key must be at most 255 characters",
+			})
+			return
+		}
+
+		// Without a user or cart session, guests' keys would share a scope
+		// and one could be replayed another's response
+		scope, signedIn := GetUserID(c)
+		if !signedIn {
+			session := c.GetHeader(cart.SessionHeader)
+			if session == "" {
+				c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+					Error:   "Cart session required",
+					Message: "Guest requests with an Idempotency-Key must send the " + cart.SessionHeader + " header",
+				})
+				return
+			}
+			scope = "guest:" + session
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodyBytes))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid request body",
+				Message: err.Error(),
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		scopedKey := scope + ":" + key
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.Path, body)
+		ctx := c.Request.Context()
+
+		claimed, record, err := store.Begin(ctx, scopedKey, fingerprint, idempotencyPendingTTL)
+		if err != nil {
+			logging.FromContext(ctx).Warn("Idempotency store read failed", "error", err)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, models.ErrorResponse{
+				Error:   "Idempotency check unavailable",
+				Message: "Unable to check the idempotency key, please retry",
+			})
+			return
+		}
+		if !claimed {
+			replayIdempotent(c, record, fingerprint)
+			return
+		}
+
+		writer := &cacheWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		// Store the outcome even if the client went away, since its retry
+		// is what needs it
+		ctx = context.WithoutCancel(ctx)
+		if writer.Status() >= http.StatusInternalServerError {
+			if err := store.Release(ctx, scopedKey); err != nil {
+				logging.FromContext(ctx).Warn("Idempotency key release failed", "error", err)
+			}
+			return
+		}
+		response := &cache.Response{
+			Status: writer.Status(),
+			Header: http.Header{},
+			Body:   writer.body.Bytes(),
+		}
+		for _, name := range cachedHeaders {
+			if values := writer.Header().Values(name); len(values) > 0 {
+				response.Header[name] = values
+			}
+		}
+		record = &IdempotencyRecord{Fingerprint: fingerprint, Response: response}
+		if err := store.Complete(ctx, scopedKey, record, ttl); err != nil {
+			logging.FromContext(ctx).Warn("Idempotency store write failed", "error", err)
+		}
+	}
+}
+
+// replayIdempotent answers a request whose key is already in use
+func replayIdempotent(c *gin.Context, record *IdempotencyRecord, fingerprint string) {
+	if record.Fingerprint != fingerprint {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Error:   "Idempotency key reused",
+			Message: "This Idempotency-Key was already used for a different request",
+			Code:    IdempotencyCodeReused,
+		})
+		return
+	}
+	if record.Response == nil {
+		c.Header("Retry-After", "1")
+		c.AbortWithStatusJSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Request in progress",
+			Message: "A request with this Idempotency-Key is still being processed",
+			Code:    IdempotencyCodeInProgress,
+		})
+		return
+	}
+
+	for name, values := range record.Response.Header {
+		for _, value := range values {
+			c.Writer.Header().Add(name, value)
+		}
+	}
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Status(record.Response.Status)
+	c.Writer.Write(record.Response.Body)
+	c.Abort()
+}
+
+// requestFingerprint identifies a request by method, path, and body so a key
+// can't be reused for a different request
+func requestFingerprint(method, path string, body []byte) string {
+	sum := sha256.Sum256([]byte(method + "\n" + path + "\n" + string(body)))
+	return hex.EncodeToString(sum[:])
+}
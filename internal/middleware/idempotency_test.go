@@ -0,0 +1,295 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/cart"
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// idempotentRouter routes POST /orders through the idempotency middleware,
+// counting the orders placed. Requests are signed in as the X-User header,
+// and fail with the status in X-Fail.
+func idempotentRouter(store IdempotencyStore) (*gin.Engine, *int) {
+	placed := 0
+	router := gin.New()
+	router.POST("/orders",
+		func(c *gin.Context) {
+			if userID := c.GetHeader("X-User"); userID != "" {
+				c.Set("userID", userID)
+			}
+			c.Next()
+		},
+		IdempotencyMiddleware(&config.Config{IdempotencyKeyTTLHours: 24}, store),
+		func(c *gin.Context) {
+			if status := c.GetHeader("X-Fail"); status != "" {
+				code, _ := strconv.Atoi(status)
+				c.JSON(code, models.ErrorResponse{Error: "Failed"})
+				return
+			}
+			placed++
+			c.JSON(http.StatusCreated, gin.H{"order": placed})
+		})
+	return router, &placed
+}
+
+// postOrder places an order with body and headers
+func postOrder(router http.Handler, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// errorCode decodes the code of an error response
+func errorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var resp models.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding error response %q: %v", w.Body.String(), err)
+	}
+	return resp.Code
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	router, placed := idempotentRouter(NewMemoryIdempotencyStore())
+	headers := map[string]string{"X-User": "user-1", IdempotencyKeyHeader: "key-1"}
+
+	first := postOrder(router, `{"items":1}`, headers)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first status = %d, want %d", first.Code, http.StatusCreated)
+	}
+	retry := postOrder(router, `{"items":1}`, headers)
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Errorf("retry = %d %s, want %d %s", retry.Code, retry.Body, first.Code, first.Body)
+	}
+	if retry.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Error("retry is missing the replayed header")
+	}
+	if *placed != 1 {
+		t.Errorf("placed %d orders, want 1", *placed)
+	}
+}
+
+func TestIdempotencyWithoutKey(t *testing.T) {
+	router, placed := idempotentRouter(NewMemoryIdempotencyStore())
+
+	postOrder(router, `{}`, map[string]string{"X-User": "user-1"})
+	w := postOrder(router, `{}`, map[string]string{"X-User": "user-1"})
+	if w.Header().Get(IdempotentReplayedHeader) != "" || *placed != 2 {
+		t.Errorf("placed %d orders, want 2 without replay", *placed)
+	}
+}
+
+func TestIdempotencyKeyReused(t *testing.T) {
+	router, placed := idempotentRouter(NewMemoryIdempotencyStore())
+	headers := map[string]string{"X-User": "user-1", IdempotencyKeyHeader: "key-1"}
+
+	postOrder(router, `{"items":1}`, headers)
+	w := postOrder(router, `{"items":2}`, headers)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnprocessableEntity)
+	}
+	if code := errorCode(t, w); code != IdempotencyCodeReused {
+		t.Errorf("code = %q, want %q", code, IdempotencyCodeReused)
+	}
+	if *placed != 1 {
+		t.Errorf("placed %d orders, want 1", *placed)
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	store := NewMemoryIdempotencyStore()
+	router, placed := idempotentRouter(store)
+	body := `{"items":1}`
+
+	// The first request is still running
+	fingerprint := requestFingerprint(http.MethodPost, "/orders", []byte(body))
+	store.Begin(context.Background(), "user-1:key-1", fingerprint, time.Minute)
+
+	w := postOrder(router, body, map[string]string{"X-User": "user-1", IdempotencyKeyHeader: "key-1"})
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusConflict)
+	}
+	if code := errorCode(t, w); code != IdempotencyCodeInProgress {
+		t.Errorf("code = %q, want %q", code, IdempotencyCodeInProgress)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("missing Retry-After")
+	}
+	if *placed != 0 {
+		t.Errorf("placed %d orders, want 0", *placed)
+	}
+}
+
+func TestIdempotencyServerErrorsNotStored(t *testing.T) {
+	router, placed := idempotentRouter(NewMemoryIdempotencyStore())
+	headers := map[string]string{"X-User": "user-1", IdempotencyKeyHeader: "key-1"}
+
+	failed := postOrder(router, `{}`, map[string]string{"X-User": "user-1", IdempotencyKeyHeader: "key-1", "X-Fail": "502"})
+	if failed.Code != http.StatusBadGateway {
+		t.Fatalf("status = %d, want %d", failed.Code, http.StatusBadGateway)
+	}
+	w := postOrder(router, `{}`, headers)
+	if w.Code != http.StatusCreated || w.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Errorf("retry = %d, want the order placed", w.Code)
+	}
+	if *placed != 1 {
+		t.Errorf("placed %d orders, want 1", *placed)
+	}
+}
+
+func TestIdempotencyScopes(t *testing.T) {
+	tests := []struct {
+		name       string
+		first      map[string]string
+		second     map[string]string
+		wantPlaced int
+	}{
+		{
+			name:       "different users",
+			first:      map[string]string{"X-User": "user-1"},
+			second:     map[string]string{"X-User": "user-2"},
+			wantPlaced: 2,
+		},
+		{
+			name:       "different guest sessions",
+			first:      map[string]string{cart.SessionHeader: "session-1"},
+			second:     map[string]string{cart.SessionHeader: "session-2"},
+			wantPlaced: 2,
+		},
+		{
+			name:       "same guest session",
+			first:      map[string]string{cart.SessionHeader: "session-1"},
+			second:     map[string]string{cart.SessionHeader: "session-1"},
+			wantPlaced: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, placed := idempotentRouter(NewMemoryIdempotencyStore())
+			for _, headers := range []map[string]string{tt.first, tt.second} {
+				headers[IdempotencyKeyHeader] = "key-1"
+				if w := postOrder(router, `{}`, headers); w.Code != http.StatusCreated {
+					t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+				}
+			}
+			if *placed != tt.wantPlaced {
+				t.Errorf("placed %d orders, want %d", *placed, tt.wantPlaced)
+			}
+		})
+	}
+}
+
+func TestIdempotencyRefusals(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		headers map[string]string
+	}{
+		{
+			name:    "guest without a cart session",
+			body:    `{}`,
+			headers: map[string]string{IdempotencyKeyHeader: "key-1"},
+		},
+		{
+			name:    "key too long",
+			body:    `{}`,
+			headers: map[string]string{"X-User": "user-1", IdempotencyKeyHeader: strings.Repeat("k", maxIdempotencyKeyLength+1)},
+		},
+		{
+			name:    "body too large",
+			body:    `{"note":"` + strings.Repeat("x", maxIdempotentBodyBytes) + `"}`,
+			headers: map[string]string{"X-User": "user-1", IdempotencyKeyHeader: "key-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, placed := idempotentRouter(NewMemoryIdempotencyStore())
+			w := postOrder(router, tt.body, tt.headers)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+			if *placed != 0 {
+				t.Errorf("placed %d orders, want 0", *placed)
+			}
+		})
+	}
+}
+
+func TestMemoryIdempotencyStoreExpiry(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryIdempotencyStore()
+
+	if claimed, _, _ := store.Begin(ctx, "expired", "a", -time.Second); !claimed {
+		t.Fatal("Begin() didn't claim a free key")
+	}
+	if claimed, _, _ := store.Begin(ctx, "held", "a", time.Hour); !claimed {
+		t.Fatal("Begin() didn't claim a free key")
+	}
+
+	// An expired claim no longer holds its key
+	if claimed, _, _ := store.Begin(ctx, "expired", "b", time.Hour); !claimed {
+		t.Error("Begin() didn't claim an expired key")
+	}
+	claimed, record, _ := store.Begin(ctx, "held", "b", time.Hour)
+	if claimed || record == nil || record.Fingerprint != "a" {
+		t.Errorf("Begin() = %v, %+v; want the held key's record", claimed, record)
+	}
+}
+
+func TestMemoryIdempotencyStorePurge(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryIdempotencyStore()
+	store.Begin(ctx, "expired", "a", -time.Second)
+	store.Begin(ctx, "old", "a", time.Hour)
+	store.Begin(ctx, "new", "a", time.Hour)
+	store.records["old"].record.StoredAt = time.Now().Add(-2 * time.Hour)
+
+	purged, err := store.Purge(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if purged != 2 {
+		t.Errorf("Purge() = %d, want 2", purged)
+	}
+	if _, ok := store.records["new"]; !ok || len(store.records) != 1 {
+		t.Errorf("records left = %v, want only new", store.records)
+	}
+}
+
+func TestRequestFingerprint(t *testing.T) {
+	base := requestFingerprint(http.MethodPost, "/orders", []byte(`{}`))
+	for _, other := range []string{
+		requestFingerprint(http.MethodPut, "/orders", []byte(`{}`)),
+		requestFingerprint(http.MethodPost, "/checkout", []byte(`{}`)),
+		requestFingerprint(http.MethodPost, "/orders", []byte(`{"a":1}`)),
+	} {
+		if other == base {
+			t.Error("different requests share a fingerprint")
+		}
+	}
+	if requestFingerprint(http.MethodPost, "/orders", []byte(`{}`)) != base {
+		t.Error("the same request has different fingerprints")
+	}
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+	"github.com/ecommerce/be-api-gin/internal/requestid"
+)
+
+// inFlightKey is the gin context key for the request's in-flight entry
+const inFlightKey = "inFlightRequest"
+
+// inFlightRequest is a request the gateway is still handling
+type inFlightRequest struct {
+	requestID string
+	method    string
+	route     string
+	path      string
+	clientIP  string
+	startedAt time.Time
+	userID    atomic.Value // string, set once the caller is authenticated
+}
+
+// inFlightRegistry tracks the requests being handled by this instance
+type inFlightRegistry struct {
+	mu       sync.Mutex
+	next     uint64
+	requests map[uint64]*inFlightRequest
+}
+
+// inFlight is the instance's registry of in-flight requests
+var inFlight = &inFlightRegistry{
+	requests: make(map[uint64]*inFlightRequest),
+}
+
+// add registers a request and returns its key in the registry
+func (r *inFlightRegistry) add(req *inFlightRequest) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.next++
+	r.requests[r.next] = req
+	return r.next
+}
+
+// remove drops a finished request
+func (r *inFlightRegistry) remove(id uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.requests, id)
+}
+
+// InFlightMiddleware records each request in the instance's in-flight
+// registry until its handler returns
+func InFlightMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		req := &inFlightRequest{
+			requestID: requestid.FromContext(c.Request.Context()),
+			method:    c.Request.Method,
+			route:     route,
+			path:      c.Request.URL.Path,
+			clientIP:  c.ClientIP(),
+			startedAt: time.Now(),
+		}
+		id := inFlight.add(req)
+		defer inFlight.remove(id)
+
+		c.Set(inFlightKey, req)
+		c.Next()
+	}
+}
+
+// recordInFlightUser notes the authenticated caller of an in-flight request
+func recordInFlightUser(c *gin.Context, userID string) {
+	if req, ok := c.Get(inFlightKey); ok {
+		req.(*inFlightRequest).userID.Store(userID)
+	}
+}
+
+// InFlightRequests returns the requests this instance is handling, oldest
+// first
+func InFlightRequests() []models.InFlightRequest {
+	inFlight.mu.Lock()
+	requests := make([]*inFlightRequest, 0, len(inFlight.requests))
+	for _, req := range inFlight.requests {
+		requests = append(requests, req)
+	}
+	inFlight.mu.Unlock()
+
+	sort.Slice(requests, func(i, j int) bool { return requests[i].startedAt.Before(requests[j].startedAt) })
+
+	now := time.Now()
+	snapshot := make([]models.InFlightRequest, len(requests))
+	for i, req := range requests {
+		userID, _ := req.userID.Load().(string)
+		snapshot[i] = models.InFlightRequest{
+			RequestID: req.requestID,
+			Method:    req.method,
+			Route:     req.route,
+			Path:      req.path,
+			UserID:    userID,
+			ClientIP:  req.clientIP,
+			StartedAt: models.NewTimestamp(req.startedAt),
+			AgeMs:     now.Sub(req.startedAt).Milliseconds(),
+		}
+	}
+	return snapshot
+}
@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// IntrospectionMiddleware confirms with the identity provider that the
+// request's JWT is still active before allowing a high-value action. Tokens
+// the provider reports inactive are added to the revocation list, and
+// requests are rejected with 503 if the provider cannot be reached. It does
+// nothing when no introspection endpoint is configured or the request
+// authenticated with an API key. Must run after AuthMiddleware.
+func IntrospectionMiddleware(cfg *config.Config) gin.HandlerFunc {
+	if cfg.TokenIntrospectionURL == "" {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	introspector := &tokenIntrospector{
+		url:          cfg.TokenIntrospectionURL,
+		clientID:     cfg.TokenIntrospectionClientID,
+		clientSecret: cfg.TokenIntrospectionClientSecret,
+		cacheTTL:     time.Duration(cfg.TokenIntrospectionCacheSec) * time.Second,
+		client:       &http.Client{Timeout: 5 * time.Second},
+		active:       make(map[string]time.Time),
+	}
+
+	return func(c *gin.Context) {
+		if c.GetString("authMethod") != "jwt" {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		tokenString, _ := bearerToken(c.GetHeader("Authorization"))
+		id, _ := GetTokenID(c)
+
+		active, err := introspector.Active(ctx, id, tokenString)
+		if err != nil {
+			logging.FromContext(ctx).Warn("Token introspection failed", "error", err)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, models.ErrorResponse{
+				Error:   "Authentication unavailable",
+				Message: "Unable to verify token status, please retry",
+			})
+			return
+		}
+
+		if !active {
+			// Remember the result so every route rejects the token
+			expiresAt := time.Now().Add(time.Duration(cfg.JWTExpiration) * time.Hour)
+			if claims, ok := GetClaims(c); ok && claims.ExpiresAt != nil {
+				expiresAt = claims.ExpiresAt.Time
+			}
+			if err := RevokeToken(ctx, id, expiresAt); err != nil {
+				logging.FromContext(ctx).Warn("Failed to revoke inactive token", "error", err)
+			}
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+				Error:   "Invalid token",
+				Message: "The provided token is no longer active",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// tokenIntrospector queries an RFC 7662 introspection endpoint, caching
+// active results briefly to limit calls to the provider
+type tokenIntrospector struct {
+	url          string
+	clientID     string
+	clientSecret string
+	cacheTTL     time.Duration
+	client       *http.Client
+
+	mu     sync.Mutex
+	active map[string]time.Time
+}
+
+// Active reports whether the provider considers the token active
+func (t *tokenIntrospector) Active(ctx context.Context, tokenID, tokenString string) (bool, error) {
+	now := time.Now()
+
+	t.mu.Lock()
+	until, ok := t.active[tokenID]
+	t.mu.Unlock()
+	if ok && now.Before(until) {
+		return true, nil
+	}
+
+	form := url.Values{
+		"token":           {tokenString},
+		"token_type_hint": {"access_token"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if t.clientID != "" {
+		req.SetBasicAuth(url.QueryEscape(t.clientID), url.QueryEscape(t.clientSecret))
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("introspection endpoint returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Active bool `json:"active"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, err
+	}
+
+	if result.Active && t.cacheTTL > 0 {
+		t.mu.Lock()
+		for id, exp := range t.active {
+			if now.After(exp) {
+				delete(t.active, id)
+			}
+		}
+		t.active[tokenID] = now.Add(t.cacheTTL)
+		t.mu.Unlock()
+	}
+	return result.Active, nil
+}
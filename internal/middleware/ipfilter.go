@@ -0,0 +1,384 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// IP filter errors
+var (
+	ErrInvalidCIDR    = errors.New("invalid ip address or cidr range")
+	ErrIPRuleNotFound = errors.New("ip rule not found")
+	ErrStaticIPRule   = errors.New("ip rule is set by configuration")
+	// ErrAllowlistDisabled is returned when adding an allow rule while the
+	// admin allowlist isn't enabled by configuration
+	ErrAllowlistDisabled = errors.New("admin allowlist is not enabled")
+)
+
+// ipRulesKey is the Redis hash of runtime rules, by rule ID
+const ipRulesKey = "ip-rules"
+
+// ipRuleSyncInterval is how often runtime rules are reloaded from Redis
+const ipRuleSyncInterval = 5 * time.Second
+
+// ipRule is an IPRule with its parsed network
+type ipRule struct {
+	models.IPRule
+	network *net.IPNet
+}
+
+// IPFilter holds the admin allowlist, the global denylist, and blocked
+// countries. Rules from configuration are static. The admin allowlist is
+// enforced only when configured, so rules added at runtime can widen it but
+// never switch it on or off. Runtime rules may expire; they are shared
+// through Redis when configured and otherwise apply to this instance only.
+type IPFilter struct {
+	countryHeader    string
+	blockedCountries map[string]bool
+	allowlist        bool
+	redis            *goredis.Client
+
+	mu    sync.RWMutex
+	rules map[string]*ipRule
+}
+
+// NewIPFilter creates a filter from the configured lists. Invalid entries
+// are logged and skipped. redisClient may be nil.
+func NewIPFilter(cfg *config.Config, redisClient *goredis.Client) *IPFilter {
+	f := &IPFilter{
+		countryHeader:    cfg.GeoCountryHeader,
+		blockedCountries: make(map[string]bool),
+		redis:            redisClient,
+		rules:            make(map[string]*ipRule),
+	}
+	for _, country := range cfg.BlockedCountries {
+		if country = strings.ToUpper(strings.TrimSpace(country)); country != "" {
+			f.blockedCountries[country] = true
+		}
+	}
+
+	now := time.Now()
+	for list, entries := range map[string][]string{
+		models.IPListAllow: cfg.AdminIPAllowlist,
+		models.IPListDeny:  cfg.IPDenylist,
+	} {
+		for _, entry := range entries {
+			rule, err := newIPRule(list, entry)
+			if err != nil {
+				slog.Warn("Ignoring invalid IP list entry", "list", list, "entry", entry, "error", err)
+				continue
+			}
+			// Static rules get the same ID on every replica
+			sum := sha256.Sum256([]byte(list + " " + rule.CIDR))
+			rule.ID = "ipr_cfg_" + hex.EncodeToString(sum[:8])
+			rule.Static = true
+			rule.Reason = "configuration"
+			rule.CreatedAt = models.NewTimestamp(now)
+			f.rules[rule.ID] = rule
+			if list == models.IPListAllow {
+				f.allowlist = true
+			}
+		}
+	}
+	if f.redis != nil {
+		if err := f.load(context.Background()); err != nil {
+			slog.Warn("Failed to load IP rules", "error", err)
+		}
+	}
+	return f
+}
+
+// Sync reloads the runtime rules from Redis every ipRuleSyncInterval until
+// the context is cancelled, so rules added or removed on other replicas
+// apply here too. It returns at once when Redis isn't configured.
+func (f *IPFilter) Sync(ctx context.Context) {
+	if f.redis == nil {
+		return
+	}
+	ticker := time.NewTicker(ipRuleSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Keep the last rules loaded if Redis is unavailable
+			if err := f.load(ctx); err != nil {
+				slog.Warn("Failed to sync IP rules", "error", err)
+			}
+		}
+	}
+}
+
+// load replaces the runtime rules with those stored in Redis, deleting any
+// that have expired
+func (f *IPFilter) load(ctx context.Context) error {
+	stored, err := f.redis.HGetAll(ctx, ipRulesKey).Result()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	rules := make(map[string]*ipRule, len(stored))
+	var expired []string
+	for id, data := range stored {
+		var rule models.IPRule
+		if err := json.Unmarshal([]byte(data), &rule); err != nil {
+			slog.Warn("Ignoring malformed IP rule", "rule_id", id, "error", err)
+			continue
+		}
+		network, err := parseCIDR(rule.CIDR)
+		if err != nil {
+			slog.Warn("Ignoring malformed IP rule", "rule_id", id, "error", err)
+			continue
+		}
+		r := &ipRule{IPRule: rule, network: network}
+		if r.expired(now) {
+			expired = append(expired, id)
+			continue
+		}
+		rules[id] = r
+	}
+	if len(expired) > 0 {
+		if err := f.redis.HDel(ctx, ipRulesKey, expired...).Err(); err != nil {
+			slog.Warn("Failed to delete expired IP rules", "error", err)
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for id, rule := range f.rules {
+		if !rule.Static {
+			delete(f.rules, id)
+		}
+	}
+	for id, rule := range rules {
+		f.rules[id] = rule
+	}
+	return nil
+}
+
+// Add adds a rule, expiring after ttl if it is positive. Allow rules can
+// only be added while the admin allowlist is enabled by configuration.
+func (f *IPFilter) Add(ctx context.Context, list, cidr, reason, createdBy string, ttl time.Duration) (*models.IPRule, error) {
+	if list == models.IPListAllow && !f.allowlist {
+		return nil, ErrAllowlistDisabled
+	}
+	rule, err := newIPRule(list, cidr)
+	if err != nil {
+		return nil, err
+	}
+	rule.Reason = reason
+	rule.CreatedBy = createdBy
+	rule.CreatedAt = models.Now()
+	if ttl > 0 {
+		expiresAt := rule.CreatedAt.Add(ttl)
+		rule.ExpiresAt = models.TimestampPtr(expiresAt)
+	}
+
+	if f.redis != nil {
+		data, err := json.Marshal(rule.IPRule)
+		if err != nil {
+			return nil, err
+		}
+		if err := f.redis.HSet(ctx, ipRulesKey, rule.ID, data).Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	f.mu.Lock()
+	f.rules[rule.ID] = rule
+	f.mu.Unlock()
+
+	result := rule.IPRule
+	return &result, nil
+}
+
+// Remove deletes a runtime rule
+func (f *IPFilter) Remove(ctx context.Context, id string) error {
+	f.mu.RLock()
+	rule, ok := f.rules[id]
+	f.mu.RUnlock()
+	if ok && rule.Static {
+		return ErrStaticIPRule
+	}
+
+	if f.redis != nil {
+		// Another replica may have added the rule since the last sync
+		deleted, err := f.redis.HDel(ctx, ipRulesKey, id).Result()
+		if err != nil {
+			return err
+		}
+		ok = deleted > 0
+	} else if ok && rule.expired(time.Now()) {
+		ok = false
+	}
+	if !ok {
+		return ErrIPRuleNotFound
+	}
+
+	f.mu.Lock()
+	delete(f.rules, id)
+	f.mu.Unlock()
+	return nil
+}
+
+// Rules returns the unexpired rules, optionally filtered by list, oldest first
+func (f *IPFilter) Rules(list string) []models.IPRule {
+	now := time.Now()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	rules := make([]models.IPRule, 0, len(f.rules))
+	for id, rule := range f.rules {
+		if rule.expired(now) {
+			delete(f.rules, id)
+			continue
+		}
+		if list == "" || rule.List == list {
+			rules = append(rules, rule.IPRule)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].CreatedAt.Before(rules[j].CreatedAt.Time)
+	})
+	return rules
+}
+
+// DenyMiddleware refuses requests from denylisted addresses and blocked
+// countries
+func (f *IPFilter) DenyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if f.countryBlocked(c) {
+			c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "Forbidden",
+				Message: "This service is not available in your region",
+			})
+			return
+		}
+
+		ip := net.ParseIP(c.ClientIP())
+		if ip != nil && f.matches(models.IPListDeny, ip) {
+			c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "Forbidden",
+				Message: "Access from your network is not permitted",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// AllowlistMiddleware restricts a route group to allowlisted addresses when
+// the admin allowlist is enabled by configuration, and allows every address
+// otherwise
+func (f *IPFilter) AllowlistMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !f.allowlist {
+			c.Next()
+			return
+		}
+		ip := net.ParseIP(c.ClientIP())
+		if ip == nil || !f.matches(models.IPListAllow, ip) {
+			c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "Forbidden",
+				Message: "Access from your network is not permitted",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// matches reports whether ip is in an unexpired rule on list
+func (f *IPFilter) matches(list string, ip net.IP) bool {
+	now := time.Now()
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for _, rule := range f.rules {
+		if rule.List == list && !rule.expired(now) && rule.network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// countryBlocked reports whether the CDN placed the request in a blocked country
+func (f *IPFilter) countryBlocked(c *gin.Context) bool {
+	if f.countryHeader == "" || len(f.blockedCountries) == 0 {
+		return false
+	}
+	country := strings.ToUpper(strings.TrimSpace(c.GetHeader(f.countryHeader)))
+	return f.blockedCountries[country]
+}
+
+// expired reports whether the rule has passed its expiry
+func (r *ipRule) expired(now time.Time) bool {
+	return r.ExpiresAt != nil && now.After(r.ExpiresAt.Time)
+}
+
+// newIPRule parses an IP address or CIDR range into a rule with a new ID
+func newIPRule(list, entry string) (*ipRule, error) {
+	network, err := parseCIDR(strings.TrimSpace(entry))
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+
+	return &ipRule{
+		IPRule: models.IPRule{
+			ID:   "ipr_" + hex.EncodeToString(buf),
+			List: list,
+			CIDR: network.String(),
+		},
+		network: network,
+	}, nil
+}
+
+// parseCIDR parses a CIDR range, treating a bare address as a single host
+func parseCIDR(entry string) (*net.IPNet, error) {
+	if strings.Contains(entry, "/") {
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, ErrInvalidCIDR
+		}
+		return network, nil
+	}
+
+	ip := net.ParseIP(entry)
+	if ip == nil {
+		return nil, ErrInvalidCIDR
+	}
+	bits := 128
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, 32
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
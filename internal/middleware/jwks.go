@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// jwksRefreshInterval is how long fetched signing keys are cached
+	jwksRefreshInterval = time.Hour
+	// jwksMinRefreshInterval is the least time between fetches, so tokens
+	// with made-up key IDs can't send every request to the endpoint
+	jwksMinRefreshInterval = 30 * time.Second
+	// jwksMissTTL is how long a key ID missing from the set is remembered
+	jwksMissTTL = time.Minute
+	// jwksMaxMisses bounds the remembered missing key IDs
+	jwksMaxMisses = 1000
+	// jwksMaxBodyBytes bounds the key set read from the endpoint
+	jwksMaxBodyBytes = 1 << 20
+)
+
+// ErrUnknownKeyID is returned when a token references a key not in the JWKS
+var ErrUnknownKeyID = errors.New("unknown signing key id")
+
+// jwks caches RSA public keys fetched from a JSON Web Key Set endpoint
+type jwks struct {
+	url    string
+	client *http.Client
+	group  singleflight.Group
+
+	mu          sync.RWMutex
+	keys        map[string]*rsa.PublicKey
+	misses      map[string]time.Time // unknown key ID to when it was looked up
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	lastErr     error
+}
+
+var (
+	jwksMu    sync.Mutex
+	jwksByURL = map[string]*jwks{}
+)
+
+// getJWKS returns the shared key set for url so all middleware instances
+// reuse the same cache
+func getJWKS(url string) *jwks {
+	jwksMu.Lock()
+	defer jwksMu.Unlock()
+
+	if set, ok := jwksByURL[url]; ok {
+		return set
+	}
+	set := &jwks{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+		keys:   map[string]*rsa.PublicKey{},
+		misses: map[string]time.Time{},
+	}
+	jwksByURL[url] = set
+	return set
+}
+
+// Key returns the public key with the given key ID, refreshing the cache if
+// it is stale or the key is unknown. A key ID found missing is answered from
+// memory for jwksMissTTL, and refreshes are rate limited.
+func (s *jwks) Key(kid string) (*rsa.PublicKey, error) {
+	s.mu.RLock()
+	key, ok := s.keys[kid]
+	fresh := time.Since(s.fetchedAt) < jwksRefreshInterval
+	missedAt, missed := s.misses[kid]
+	s.mu.RUnlock()
+
+	if ok && fresh {
+		return key, nil
+	}
+	if !ok && missed && time.Since(missedAt) < jwksMissTTL {
+		return nil, ErrUnknownKeyID
+	}
+
+	if err := s.refresh(); err != nil {
+		// Fall back to a cached key if the endpoint is temporarily unavailable
+		if ok {
+			return key, nil
+		}
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+	if len(s.misses) >= jwksMaxMisses {
+		s.misses = map[string]time.Time{}
+	}
+	s.misses[kid] = time.Now()
+	return nil, ErrUnknownKeyID
+}
+
+// refresh fetches the key set from the JWKS endpoint, at most once per
+// jwksMinRefreshInterval. Callers within the interval get the last fetch's
+// result, and concurrent callers share one fetch.
+func (s *jwks) refresh() error {
+	_, err, _ := s.group.Do(s.url, func() (any, error) {
+		s.mu.Lock()
+		if time.Since(s.attemptedAt) < jwksMinRefreshInterval {
+			err := s.lastErr
+			s.mu.Unlock()
+			return nil, err
+		}
+		s.attemptedAt = time.Now()
+		s.mu.Unlock()
+
+		err := s.fetch()
+		s.mu.Lock()
+		s.lastErr = err
+		s.mu.Unlock()
+		return nil, err
+	})
+	return err
+}
+
+// fetch replaces the cached keys with the set at the JWKS endpoint
+func (s *jwks) fetch() error {
+	resp, err := s.client.Get(s.url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("jwks endpoint returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, jwksMaxBodyBytes)).Decode(&body); err != nil {
+		return err
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(body.Keys))
+	for _, k := range body.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		key, err := parseRSAPublicKey(k.N, k.E)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.misses = map[string]time.Time{}
+	s.fetchedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// parseRSAPublicKey builds an RSA public key from base64url-encoded modulus
+// and exponent
+func parseRSAPublicKey(n, e string) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, err
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, err
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}, nil
+}
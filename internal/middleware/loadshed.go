@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// Load shedding tuning
+const (
+	// latencySmoothing is the weight of each new request in the latency average
+	latencySmoothing = 0.1
+	// probeRate is the share of low-priority requests still admitted while
+	// latency is high, so the average can recover once the backend does
+	probeRate = 0.05
+	// shedRetryAfter is the Retry-After sent with shed requests
+	shedRetryAfter = 5 * time.Second
+)
+
+// Reasons a request was shed
+const (
+	shedReasonInFlight = "in_flight"
+	shedReasonLatency  = "latency"
+)
+
+// loadShedExempt lists infrastructure routes that are never shed
+var loadShedExempt = []string{"/health", "/ready", "/metrics"}
+
+var requestsShedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "http_requests_shed_total",
+	Help: "Low-priority requests rejected by load shedding.",
+}, []string{"reason"})
+
+// LoadShedder tracks in-flight requests and a moving average of latency to
+// decide when the gateway is overloaded
+type LoadShedder struct {
+	maxInFlight int64
+	maxLatency  time.Duration
+	critical    map[string]bool
+
+	inFlight atomic.Int64
+
+	mu      sync.Mutex
+	latency float64 // exponentially weighted average, in seconds
+}
+
+// NewLoadShedder creates a load shedder from the configured thresholds. A
+// zero threshold disables that check.
+func NewLoadShedder(cfg *config.Config) *LoadShedder {
+	critical := make(map[string]bool, len(cfg.LoadShedCriticalRoutes))
+	for _, route := range cfg.LoadShedCriticalRoutes {
+		critical[route] = true
+	}
+	return &LoadShedder{
+		maxInFlight: int64(cfg.LoadShedMaxInFlight),
+		maxLatency:  time.Duration(cfg.LoadShedLatencyMs) * time.Millisecond,
+		critical:    critical,
+	}
+}
+
+// Middleware rejects low-priority requests with 503 while the number of
+// in-flight requests or the average latency is over its threshold. Critical
+// routes, such as order creation, are always admitted so they keep the
+// capacity that shedding frees up.
+func (s *LoadShedder) Middleware() gin.HandlerFunc {
+	if s.maxInFlight <= 0 && s.maxLatency <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if slices.Contains(loadShedExempt, route) {
+			c.Next()
+			return
+		}
+
+		inFlight := s.inFlight.Add(1)
+		defer s.inFlight.Add(-1)
+
+		if !s.critical[c.Request.Method+" "+route] {
+			if reason := s.overloaded(inFlight); reason != "" {
+				requestsShedTotal.WithLabelValues(reason).Inc()
+				logging.FromContext(c.Request.Context()).Warn("Request shed", "reason", reason, "in_flight", inFlight)
+				c.Header("Retry-After", strconv.Itoa(int(shedRetryAfter.Seconds())))
+				c.AbortWithStatusJSON(http.StatusServiceUnavailable, models.ErrorResponse{
+					Error:   "Service overloaded",
+					Message: "The service is busy, please retry shortly",
+				})
+				return
+			}
+		}
+
+		start := time.Now()
+		c.Next()
+		s.observe(time.Since(start))
+	}
+}
+
+// overloaded returns why a low-priority request should be shed, or "" to
+// admit it
+func (s *LoadShedder) overloaded(inFlight int64) string {
+	if s.maxInFlight > 0 && inFlight > s.maxInFlight {
+		return shedReasonInFlight
+	}
+	if s.maxLatency > 0 && s.averageLatency() > s.maxLatency && rand.Float64() >= probeRate {
+		return shedReasonLatency
+	}
+	return ""
+}
+
+// observe folds a completed request's latency into the moving average
+func (s *LoadShedder) observe(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.latency == 0 {
+		s.latency = d.Seconds()
+		return
+	}
+	s.latency += latencySmoothing * (d.Seconds() - s.latency)
+}
+
+// averageLatency returns the moving average of request latency
+func (s *LoadShedder) averageLatency() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Duration(math.Round(s.latency * float64(time.Second)))
+}
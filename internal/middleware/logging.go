@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ecommerce/be-api-gin/internal/logging"
+)
+
+// LoggerMiddleware attaches a request-scoped logger carrying the request ID,
+// method, route, and trace ID to the request context, and logs each
+// completed request with its status and latency. Authentication adds the
+// user ID. Must run after RequestIDMiddleware.
+func LoggerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		ctx := c.Request.Context()
+		logger := logging.FromContext(ctx).With(
+			"request_id", GetRequestID(c),
+			"method", c.Request.Method,
+			"route", route,
+		)
+		if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+			logger = logger.With("trace_id", span.TraceID().String())
+		}
+		c.Request = c.Request.WithContext(logging.NewContext(ctx, logger))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		size := c.Writer.Size()
+		if size < 0 {
+			size = 0
+		}
+		level := slog.LevelInfo
+		if status >= 500 {
+			level = slog.LevelError
+		}
+		logging.FromContext(c.Request.Context()).Log(c.Request.Context(), level, "Request completed",
+			"path", c.Request.URL.Path,
+			"status", status,
+			"latency_ms", float64(time.Since(start).Microseconds())/1000,
+			"client_ip", c.ClientIP(),
+			"size", size,
+		)
+	}
+}
+
+// addLogAttrs adds attributes to the request-scoped logger
+func addLogAttrs(c *gin.Context, args ...any) {
+	ctx := c.Request.Context()
+	c.Request = c.Request.WithContext(logging.NewContext(ctx, logging.FromContext(ctx).With(args...)))
+}
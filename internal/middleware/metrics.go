@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// unmatchedRoute labels requests that match no route, keeping label
+// cardinality bounded regardless of the paths clients request
+const unmatchedRoute = "unmatched"
+
+var (
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests handled by the gateway.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	httpResponseSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_response_size_bytes",
+		Help:    "HTTP response body size.",
+		Buckets: prometheus.ExponentialBuckets(100, 4, 8),
+	}, []string{"method", "route", "status"})
+
+	httpRequestsInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "HTTP requests currently being handled.",
+	})
+)
+
+// MetricsMiddleware records request counts, latency, and response sizes by
+// route and status. Register it first so the sizes include bodies written by
+// the request ID middleware.
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		httpRequestsInFlight.Inc()
+		defer httpRequestsInFlight.Dec()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		size := c.Writer.Size()
+		if size < 0 {
+			size = 0
+		}
+
+		httpRequestsTotal.WithLabelValues(c.Request.Method, route, status).Inc()
+		httpRequestDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+		httpResponseSize.WithLabelValues(c.Request.Method, route, status).Observe(float64(size))
+	}
+}
+
+// MetricsHandler serves metrics in the Prometheus exposition format. When
+// token is set, scrapers must send it as a bearer token. When region is set,
+// every metric is labelled with it.
+func MetricsHandler(token, region string) gin.HandlerFunc {
+	handler := promhttp.Handler()
+	if region != "" {
+		handler = promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer,
+			promhttp.HandlerFor(regionGatherer(prometheus.DefaultGatherer, region), promhttp.HandlerOpts{}))
+	}
+
+	return func(c *gin.Context) {
+		if token != "" {
+			provided, ok := bearerToken(c.GetHeader("Authorization"))
+			if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+					Error:   "Unauthorized",
+					Message: "A valid metrics token is required",
+				})
+				return
+			}
+		}
+
+		handler.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// regionGatherer adds a region label to every metric gathered from g
+func regionGatherer(g prometheus.Gatherer, region string) prometheus.Gatherer {
+	name := "region"
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		families, err := g.Gather()
+		for _, family := range families {
+			for _, metric := range family.Metric {
+				metric.Label = append(metric.Label, &dto.LabelPair{Name: &name, Value: &region})
+			}
+		}
+		return families, err
+	})
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// NonceStore remembers request nonces so signed requests can't be replayed
+type NonceStore interface {
+	// Claim records a nonce for ttl, returning false if it was already seen
+	Claim(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+}
+
+// MemoryNonceStore is an in-process NonceStore. It only protects against
+// replays to the same gateway instance.
+type MemoryNonceStore struct {
+	mu     sync.Mutex
+	nonces map[string]time.Time
+}
+
+// NewMemoryNonceStore creates an empty in-memory nonce store
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{
+		nonces: make(map[string]time.Time),
+	}
+}
+
+// Claim records a nonce until ttl elapses
+func (s *MemoryNonceStore) Claim(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Purge expired entries
+	now := time.Now()
+	for n, exp := range s.nonces {
+		if now.After(exp) {
+			delete(s.nonces, n)
+		}
+	}
+
+	if _, seen := s.nonces[nonce]; seen {
+		return false, nil
+	}
+	s.nonces[nonce] = now.Add(ttl)
+	return true, nil
+}
+
+// RedisNonceStore is a NonceStore shared by all gateway replicas
+type RedisNonceStore struct {
+	client *goredis.Client
+	prefix string
+}
+
+// NewRedisNonceStore creates a nonce store using client, namespacing keys
+// with prefix
+func NewRedisNonceStore(client *goredis.Client, prefix string) *RedisNonceStore {
+	return &RedisNonceStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Claim atomically records a nonce until ttl elapses
+func (s *RedisNonceStore) Claim(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, s.prefix+nonce, 1, ttl).Result()
+}
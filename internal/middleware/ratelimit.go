@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// Limiter decides whether a request identified by key may proceed under a
+// token bucket refilled at rate tokens per second and holding at most burst
+// tokens. When the request is denied it returns how long to wait.
+type Limiter interface {
+	Allow(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error)
+}
+
+// RateLimitMiddleware limits requests per API key, user, or client IP (in
+// that order of preference) within the named route group. API keys in an SLA
+// tier with a rate limit get the lower of the tier's and the group's limit,
+// so a tier never lifts a group's stricter limit. Requests are allowed
+// through if the limiter itself fails.
+func RateLimitMiddleware(limiter Limiter, group string, groupRate int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rate := groupRate
+		if tier := GetSLATier(c); tier != nil && tier.RateLimit > 0 && (rate <= 0 || tier.RateLimit < rate) {
+			rate = tier.RateLimit
+		}
+		if rate <= 0 {
+			c.Next()
+			return
+		}
+		burst := rate
+
+		key := "ip:" + c.ClientIP()
+		if apiKeyID := c.GetString("apiKeyID"); apiKeyID != "" {
+			key = "apikey:" + apiKeyID
+		} else if apiKey := requestAPIKey(c); apiKey != nil {
+			key = "apikey:" + apiKey.ID
+		} else if userID, ok := GetUserID(c); ok {
+			key = "user:" + userID
+		}
+
+		allowed, retryAfter, err := limiter.Allow(c.Request.Context(), group+":"+key, float64(rate), burst)
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Warn("Rate limiter failed, allowing request", "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(rate))
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, models.ErrorResponse{
+				Error:   "Too many requests",
+				Message: "Rate limit exceeded, please retry later",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// memoryLimiterIdleTTL is how long an untouched bucket is kept in memory
+const memoryLimiterIdleTTL = 10 * time.Minute
+
+// MemoryLimiter is an in-process token bucket Limiter. Limits are enforced
+// per gateway instance.
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// tokenBucket holds the state of a single key's bucket
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewMemoryLimiter creates an empty in-memory limiter
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow takes a token from the key's bucket if one is available
+func (l *MemoryLimiter) Allow(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(burst), last: now}
+		l.buckets[key] = b
+	}
+
+	// Refill for the time elapsed since the last request
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+
+	wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	return false, wait, nil
+}
+
+// sweep drops buckets that have been idle long enough to have refilled
+func (l *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > memoryLimiterIdleTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
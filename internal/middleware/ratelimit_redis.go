@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// redisDegradedCooldown is how long the limiter uses local limiting after a
+// Redis failure before trying Redis again
+const redisDegradedCooldown = 5 * time.Second
+
+// tokenBucketScript atomically refills and takes from a bucket stored as a
+// hash. Redis server time is used so all replicas share one clock.
+var tokenBucketScript = goredis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local data = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(data[1]) or burst
+local ts = tonumber(data[2]) or now
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) / 1000 * rate)
+
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) / rate * 1000)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+return {allowed, wait}
+`)
+
+// RedisLimiter is a token bucket Limiter shared across gateway replicas
+// through Redis. While Redis is unavailable it falls back to per-instance
+// limiting.
+type RedisLimiter struct {
+	client   *goredis.Client
+	prefix   string
+	fallback *MemoryLimiter
+
+	mu            sync.Mutex
+	degradedUntil time.Time
+}
+
+// NewRedisLimiter creates a limiter storing buckets under the given key prefix
+func NewRedisLimiter(client *goredis.Client, prefix string) *RedisLimiter {
+	return &RedisLimiter{
+		client:   client,
+		prefix:   prefix,
+		fallback: NewMemoryLimiter(),
+	}
+}
+
+// Allow takes a token from the key's shared bucket
+func (l *RedisLimiter) Allow(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+	if l.degraded() {
+		return l.fallback.Allow(ctx, key, rate, burst)
+	}
+
+	result, err := tokenBucketScript.Run(ctx, l.client, []string{l.prefix + key}, rate, burst).Int64Slice()
+	if err != nil || len(result) != 2 {
+		l.degrade(err)
+		return l.fallback.Allow(ctx, key, rate, burst)
+	}
+
+	return result[0] == 1, time.Duration(result[1]) * time.Millisecond, nil
+}
+
+// degraded reports whether the limiter is currently using local limiting
+func (l *RedisLimiter) degraded() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return time.Now().Before(l.degradedUntil)
+}
+
+// degrade switches to local limiting for the cooldown period
+func (l *RedisLimiter) degrade(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if time.Now().Before(l.degradedUntil) {
+		return
+	}
+	l.degradedUntil = time.Now().Add(redisDegradedCooldown)
+	slog.Warn("Redis rate limiter unavailable, using local limits", "cooldown", redisDegradedCooldown.String(), "error", err)
+}
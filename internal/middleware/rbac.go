@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// RequirePermission ensures the authenticated user's roles grant all of the
+// given permissions according to the configured permission matrix. It must
+// run after AuthMiddleware.
+func RequirePermission(cfg *config.Config, permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("roles"); !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+				Error:   "Unauthorized",
+				Message: "Authentication required",
+			})
+			return
+		}
+
+		roles := GetRoles(c)
+		for _, permission := range permissions {
+			if !cfg.Permissions.Allows(roles, permission) {
+				c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{
+					Error:   "Forbidden",
+					Message: "Missing required permission: " + permission,
+				})
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// RequireRole ensures the authenticated user has at least one of the given
+// roles. It must run after AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("roles"); !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+				Error:   "Unauthorized",
+				Message: "Authentication required",
+			})
+			return
+		}
+
+		for _, role := range roles {
+			if HasRole(c, role) {
+				c.Next()
+				return
+			}
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "Forbidden",
+			Message: "Insufficient role for this resource",
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ecommerce/be-api-gin/internal/errorreport"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// RecoveryMiddleware recovers from panics in later handlers, logs the stack
+// trace, sends it with the request's context to the error reporter when one
+// is configured, and returns a generic 500 response. Panic details are
+// never included in the response.
+func RecoveryMiddleware(reporter errorreport.Reporter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// Let net/http handle deliberate connection aborts
+			if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(recovered)
+			}
+
+			ctx := c.Request.Context()
+			event := &errorreport.Event{
+				Message:   fmt.Sprint(recovered),
+				Stack:     string(debug.Stack()),
+				RequestID: GetRequestID(c),
+				Method:    c.Request.Method,
+				Route:     c.FullPath(),
+				Path:      c.Request.URL.Path,
+				ClientIP:  c.ClientIP(),
+				Time:      time.Now(),
+			}
+			event.UserID, _ = GetUserID(c)
+			if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+				event.TraceID = span.TraceID().String()
+			}
+
+			logging.FromContext(ctx).Error("Panic recovered", "panic", event.Message, "stack", event.Stack)
+			if reporter != nil {
+				reporter.Report(event)
+			}
+
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Internal Server Error",
+				Message: "An unexpected error occurred",
+			})
+		}()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ecommerce/be-api-gin/internal/errorcodes"
+	"github.com/ecommerce/be-api-gin/internal/requestid"
+	"github.com/ecommerce/be-api-gin/internal/retryhint"
+)
+
+// RequestIDMiddleware assigns each request an ID, reusing a well-formed
+// X-Request-ID from the client. The ID is echoed in the response header,
+// stored on the request context so it propagates to backend gRPC calls, and
+// added to every JSON error body together with the trace ID, a stable error
+// code (see internal/errorcodes), and a retry hint (see internal/retryhint).
+// Register it first so errors written by later middleware, including panic
+// recovery, carry them too.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.Generate()
+		}
+
+		c.Set("requestID", id)
+		c.Header(requestid.Header, id)
+		c.Request = c.Request.WithContext(retryhint.WithRecorder(requestid.NewContext(c.Request.Context(), id)))
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("request.id", id))
+
+		writer := &requestIDWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		writer.flush(c.Request.Context(), id)
+	}
+}
+
+// GetRequestID returns the request's ID from the context
+func GetRequestID(c *gin.Context) string {
+	return c.GetString("requestID")
+}
+
+// requestIDWriter buffers error response bodies so the request ID can be
+// added to them before they are sent
+type requestIDWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write buffers the body of error responses and passes others through
+func (w *requestIDWriter) Write(data []byte) (int, error) {
+	if w.Status() >= 400 {
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString buffers the body of error responses and passes others through
+func (w *requestIDWriter) WriteString(s string) (int, error) {
+	if w.Status() >= 400 {
+		return w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// traceID returns the ID of the request's trace, or "" if it has none
+func traceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
+
+// flush writes any buffered error body. When the body is a JSON object it
+// gains the request ID, trace ID, the status's default error code, and
+// whether and when to retry, unless the handler set them.
+func (w *requestIDWriter) flush(ctx context.Context, id string) {
+	if w.body.Len() == 0 {
+		return
+	}
+
+	// Retry hints come from the status, Retry-After, and any failed backend call
+	hint := retryhint.ForResponse(ctx, w.Status(), w.Header().Get("Retry-After"))
+	retryAfter := int(hint.RetryAfter.Round(time.Second) / time.Second)
+	if retryAfter > 0 && w.Header().Get("Retry-After") == "" {
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	}
+
+	data := w.body.Bytes()
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err == nil && fields != nil {
+		setDefault(fields, "request_id", id)
+		setDefault(fields, "trace_id", traceID(ctx))
+		setDefault(fields, "code", errorcodes.ForStatus(w.Status()))
+		setDefault(fields, "retryable", hint.Retryable)
+		if retryAfter > 0 {
+			setDefault(fields, "retry_after", retryAfter)
+		}
+		if encoded, err := json.Marshal(fields); err == nil {
+			data = encoded
+		}
+	}
+	w.ResponseWriter.Write(data)
+}
+
+// setDefault sets a field unless it is already present or value is an empty
+// string
+func setDefault(fields map[string]json.RawMessage, key string, value any) {
+	if value == "" {
+		return
+	}
+	if existing, ok := fields[key]; ok && string(existing) != `""` {
+		return
+	}
+	fields[key], _ = json.Marshal(value)
+}
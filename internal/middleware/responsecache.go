@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/ecommerce/be-api-gin/internal/cache"
+	"github.com/ecommerce/be-api-gin/internal/catalog"
+	"github.com/ecommerce/be-api-gin/internal/localization"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/preview"
+)
+
+// cachedHeaders are the response headers stored with cached bodies
+var cachedHeaders = []string{"Content-Type", "Content-Language"}
+
+// ResponseCacheMiddleware serves successful responses from the store for
+// ttl, tagging them with tags(c) so they can be invalidated when the
+// underlying data changes. The cache key includes the path, query parameters,
+// the locale and unit system negotiated from Accept-Language, and
+// Accept-Currency. Concurrent misses for a key on this replica wait for one
+// of them to fill it. Authenticated requests and previews bypass the cache since
+// their responses can include content others must not see, except for API
+// keys whose SLA tier allows cached reads: they are cached per key for the
+// tier's TTL. Store failures are logged and the request is handled normally.
+func ResponseCacheMiddleware(store cache.Store, localizer *localization.Localizer, defaultTTL time.Duration, tags func(c *gin.Context) []string) gin.HandlerFunc {
+	var fills singleflight.Group
+	return func(c *gin.Context) {
+		ttl, key, ok := responseCachePolicy(c, localizer, defaultTTL)
+		if store == nil || ttl <= 0 || !ok {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		cached, err := store.Get(ctx, key)
+		if err != nil {
+			logging.FromContext(ctx).Warn("Response cache read failed", "error", err)
+		}
+		if cached != nil {
+			writeCachedResponse(c, cached)
+			return
+		}
+
+		filled := false
+		shared, _, _ := fills.Do(key, func() (interface{}, error) {
+			filled = true
+			return fillResponse(c, store, key, ttl, tags), nil
+		})
+		if filled {
+			return
+		}
+
+		// Another request filled the key meanwhile; handle this one normally
+		// if its response couldn't be shared
+		if response, _ := shared.(*cache.Response); response != nil {
+			writeCachedResponse(c, response)
+			return
+		}
+		c.Header("X-Cache", "MISS")
+		c.Next()
+	}
+}
+
+// fillResponse handles a request and caches its response, returning it, or
+// nil if it must not be served to other callers
+func fillResponse(c *gin.Context, store cache.Store, key string, ttl time.Duration, tags func(c *gin.Context) []string) *cache.Response {
+	ctx := c.Request.Context()
+
+	c.Header("X-Cache", "MISS")
+	writer := &cacheWriter{ResponseWriter: c.Writer}
+	c.Writer = writer
+
+	c.Next()
+
+	// Private and degraded responses must not be served to other callers
+	cacheControl := writer.Header().Get("Cache-Control")
+	if writer.Status() != http.StatusOK || strings.Contains(cacheControl, "private") || strings.Contains(cacheControl, "no-store") {
+		return nil
+	}
+	response := &cache.Response{
+		Status: writer.Status(),
+		Header: http.Header{},
+		Body:   writer.body.Bytes(),
+	}
+	for _, name := range cachedHeaders {
+		if values := writer.Header().Values(name); len(values) > 0 {
+			response.Header[name] = values
+		}
+	}
+	if err := store.Set(ctx, key, response, ttl, tags(c)...); err != nil {
+		logging.FromContext(ctx).Warn("Response cache write failed", "error", err)
+	}
+	return response
+}
+
+// writeCachedResponse serves a cached response and skips the handlers
+func writeCachedResponse(c *gin.Context, cached *cache.Response) {
+	for name, values := range cached.Header {
+		for _, value := range values {
+			c.Writer.Header().Add(name, value)
+		}
+	}
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	c.Writer.Header().Add("Vary", "Accept-Currency")
+	c.Header("X-Cache", "HIT")
+	c.Status(cached.Status)
+	c.Writer.Write(cached.Body)
+	c.Abort()
+}
+
+// responseCachePolicy returns how long a request's response may be cached
+// and under which key, or false if it must not be
+func responseCachePolicy(c *gin.Context, localizer *localization.Localizer, defaultTTL time.Duration) (time.Duration, string, bool) {
+	if cacheable(c) {
+		return defaultTTL, responseCacheKey(c, localizer), true
+	}
+
+	// Tiered API keys read through their own cache, having passed the same
+	// scope check authentication would apply
+	tier, apiKey := GetSLATier(c), requestAPIKey(c)
+	if tier == nil || apiKey == nil || tier.CacheTTLSec <= 0 ||
+		c.Request.Method != http.MethodGet || c.GetHeader("Authorization") != "" ||
+		c.Query(preview.QueryParam) != "" || !ScopeAllows(apiKey.Scopes, c.Request.Method, c.FullPath()) {
+		return 0, "", false
+	}
+	return time.Duration(tier.CacheTTLSec) * time.Second, "apikey:" + apiKey.ID + "|" + responseCacheKey(c, localizer), true
+}
+
+// cacheable reports whether a request's response may be shared between
+// callers
+func cacheable(c *gin.Context) bool {
+	return c.Request.Method == http.MethodGet &&
+		c.GetHeader("Authorization") == "" &&
+		c.GetHeader(APIKeyHeader) == "" &&
+		c.Query(preview.QueryParam) == ""
+}
+
+// responseCacheKey identifies a response by path, sorted query parameters,
+// and the locale, unit system and currency it is served in. Accept-Language
+// headers that resolve to the same locale and units share an entry.
+func responseCacheKey(c *gin.Context, localizer *localization.Localizer) string {
+	acceptLanguage := c.GetHeader("Accept-Language")
+	return c.Request.URL.Path + "?" + c.Request.URL.Query().Encode() +
+		"|" + localizer.Negotiate(acceptLanguage) +
+		"|" + catalog.UnitSystemFor(acceptLanguage) +
+		"|" + strings.ToUpper(c.GetHeader("Accept-Currency"))
+}
+
+// cacheWriter copies the response body so it can be cached
+type cacheWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write copies the body and passes it through
+func (w *cacheWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString copies the body and passes it through
+func (w *cacheWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+)
+
+// responseLimitKey is the context key holding the route's response limits
+const responseLimitKey = "responseLimit"
+
+// DefaultPageSize and MaxPageSize apply to paginated routes missing from the
+// response limit table
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 100
+)
+
+// ResponseLimit bounds the size of a route's responses
+type ResponseLimit struct {
+	DefaultPageSize int  // page size when the request sets no limit
+	MaxPageSize     int  // largest page size a request may ask for
+	Truncates       bool // item lists are cut to fit MaxBytes
+	MaxBytes        int  // approximate cap on the item list's encoded size
+}
+
+// Paginated reports whether the route takes page and limit parameters
+func (l ResponseLimit) Paginated() bool {
+	return l.MaxPageSize > 0
+}
+
+// ResponseLimits maps "METHOD /path" routes, relative to the API root, to
+// their response limits
+type ResponseLimits map[string]ResponseLimit
+
+// WithConfig returns the table with maximum page sizes set by the route's
+// entry in MAX_PAGE_SIZES or else capped at MAX_PAGE_SIZE, and byte caps set
+// from MAX_AGGREGATE_RESPONSE_BYTES
+func (t ResponseLimits) WithConfig(cfg *config.Config) ResponseLimits {
+	resolved := make(ResponseLimits, len(t))
+	for route, limit := range t {
+		if limit.Paginated() {
+			if override, ok := cfg.MaxPageSizes[route]; ok && override > 0 {
+				limit.MaxPageSize = override
+			} else if cfg.MaxPageSize > 0 {
+				limit.MaxPageSize = min(limit.MaxPageSize, cfg.MaxPageSize)
+			}
+			limit.DefaultPageSize = min(limit.DefaultPageSize, limit.MaxPageSize)
+		}
+		if limit.Truncates {
+			limit.MaxBytes = cfg.MaxAggregateResponseBytes
+		}
+		resolved[route] = limit
+	}
+	return resolved
+}
+
+// Lookup returns the limits for a route
+func (t ResponseLimits) Lookup(method, fullPath string) (ResponseLimit, bool) {
+	limit, ok := t[strings.ToUpper(method)+" "+apiRelativePath(fullPath)]
+	return limit, ok
+}
+
+// ResponseLimitMiddleware makes the matched route's response limits
+// available to its handler
+func ResponseLimitMiddleware(table ResponseLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limit, ok := table.Lookup(c.Request.Method, c.FullPath()); ok {
+			c.Set(responseLimitKey, limit)
+		}
+		c.Next()
+	}
+}
+
+// GetResponseLimit returns the route's response limits, falling back to the
+// default page sizes for routes without an entry
+func GetResponseLimit(c *gin.Context) ResponseLimit {
+	if value, exists := c.Get(responseLimitKey); exists {
+		return value.(ResponseLimit)
+	}
+	return ResponseLimit{DefaultPageSize: DefaultPageSize, MaxPageSize: MaxPageSize}
+}
@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+var revocationCheckFailures = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "token_revocation_check_failures_total",
+	Help: "Revocation checks that couldn't reach Redis and fell back to revocations known locally.",
+})
+
+// revocationMirrorTTL is how long a revocation without an expiry in Redis
+// is remembered locally
+const revocationMirrorTTL = time.Hour
+
+// RevocationList tracks tokens that were invalidated before their expiry
+type RevocationList interface {
+	Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+}
+
+var (
+	revocationMu sync.RWMutex
+	// revocationList is the list consulted by the auth middleware
+	revocationList RevocationList = NewMemoryRevocationList()
+)
+
+// SetRevocationList replaces the revocation list consulted by the auth middleware
+func SetRevocationList(list RevocationList) {
+	revocationMu.Lock()
+	defer revocationMu.Unlock()
+	revocationList = list
+}
+
+// currentRevocationList returns the revocation list in use
+func currentRevocationList() RevocationList {
+	revocationMu.RLock()
+	defer revocationMu.RUnlock()
+	return revocationList
+}
+
+// RevokeToken adds a token to the revocation list until it expires
+func RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	return currentRevocationList().Revoke(ctx, tokenID, expiresAt)
+}
+
+// isRevoked reports whether the revocation list has revoked a token
+func isRevoked(ctx context.Context, tokenID string) (bool, error) {
+	return currentRevocationList().IsRevoked(ctx, tokenID)
+}
+
+// MemoryRevocationList is an in-process RevocationList. Entries are dropped
+// once the token they refer to has expired.
+type MemoryRevocationList struct {
+	mu      sync.RWMutex
+	revoked map[string]time.Time
+}
+
+// NewMemoryRevocationList creates an empty in-memory revocation list
+func NewMemoryRevocationList() *MemoryRevocationList {
+	return &MemoryRevocationList{
+		revoked: make(map[string]time.Time),
+	}
+}
+
+// Revoke marks a token as revoked until expiresAt
+func (l *MemoryRevocationList) Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Purge expired entries
+	now := time.Now()
+	for id, exp := range l.revoked {
+		if now.After(exp) {
+			delete(l.revoked, id)
+		}
+	}
+
+	l.revoked[tokenID] = expiresAt
+	return nil
+}
+
+// IsRevoked reports whether a token has been revoked
+func (l *MemoryRevocationList) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	exp, ok := l.revoked[tokenID]
+	return ok && time.Now().Before(exp), nil
+}
+
+// RedisRevocationList is a RevocationList shared by all gateway replicas.
+// Entries expire from Redis along with the token they refer to. Revocations
+// made or seen by this replica are mirrored in memory; with failOpen set, a
+// check that can't reach Redis is answered from the mirror instead of
+// failing.
+type RedisRevocationList struct {
+	client   *goredis.Client
+	prefix   string
+	failOpen bool
+	local    *MemoryRevocationList
+}
+
+// NewRedisRevocationList creates a revocation list using client, namespacing
+// keys with prefix
+func NewRedisRevocationList(client *goredis.Client, prefix string, failOpen bool) *RedisRevocationList {
+	return &RedisRevocationList{
+		client:   client,
+		prefix:   prefix,
+		failOpen: failOpen,
+		local:    NewMemoryRevocationList(),
+	}
+}
+
+// Revoke marks a token as revoked until expiresAt
+func (l *RedisRevocationList) Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	l.local.Revoke(ctx, tokenID, expiresAt)
+	return l.client.Set(ctx, l.prefix+tokenID, 1, ttl).Err()
+}
+
+// IsRevoked reports whether a token has been revoked
+func (l *RedisRevocationList) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	ttl, err := l.client.PTTL(ctx, l.prefix+tokenID).Result()
+	if err != nil {
+		if !l.failOpen {
+			return false, err
+		}
+		revocationCheckFailures.Inc()
+		return l.local.IsRevoked(ctx, tokenID)
+	}
+
+	switch {
+	case ttl == -2: // no such key
+		return false, nil
+	case ttl < 0: // no expiry
+		ttl = revocationMirrorTTL
+	}
+	l.local.Revoke(ctx, tokenID, time.Now().Add(ttl))
+	return true, nil
+}
+
+// tokenID identifies a token for revocation, using the jti claim when
+// present and a hash of the raw token otherwise
+func tokenID(claims *Claims, tokenString string) string {
+	if claims.ID != "" {
+		return claims.ID
+	}
+	sum := sha256.Sum256([]byte(tokenString))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/segment"
+)
+
+// segmentResolver resolves the segments of signed-in customers, or is nil
+// when segments are not used
+var segmentResolver segment.Resolver
+
+// SetSegmentResolver sets the resolver the auth middleware attaches to
+// signed-in customers' requests, making their segments available through
+// segment.FromContext
+func SetSegmentResolver(resolver segment.Resolver) {
+	segmentResolver = resolver
+}
+
+// attachSegments makes the customer's segments resolvable from the
+// request context
+func attachSegments(c *gin.Context, userID string) {
+	if segmentResolver == nil {
+		return
+	}
+	c.Request = c.Request.WithContext(segment.NewContext(c.Request.Context(), segmentResolver, userID))
+}
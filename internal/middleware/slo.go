@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/slo"
+)
+
+// SLOMiddleware feeds the status and latency of requests to SLO routes into
+// the tracker. Register it next to MetricsMiddleware so both see the same
+// requests.
+func SLOMiddleware(tracker *slo.Tracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		tracker.Observe(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"slices"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+)
+
+// strictJSONKey is the context key holding a route group's strict decoding mode
+const strictJSONKey = "strictJSON"
+
+// strictJSONMode records when request bodies must not contain unknown fields
+type strictJSONMode struct {
+	group    bool
+	partners bool
+}
+
+// StrictJSONMiddleware marks requests to a route group whose JSON bodies must
+// be decoded strictly, rejecting fields the endpoint does not know. Strict
+// decoding applies to every request in the groups listed in
+// STRICT_JSON_GROUPS, and to partner integrations (signed partner requests,
+// API keys, and OAuth client credentials) when STRICT_JSON_PARTNERS is set.
+func StrictJSONMiddleware(cfg *config.Config, group string) gin.HandlerFunc {
+	mode := strictJSONMode{
+		group:    slices.Contains(cfg.StrictJSONGroups, group),
+		partners: cfg.StrictJSONPartners,
+	}
+	return func(c *gin.Context) {
+		c.Set(strictJSONKey, mode)
+		c.Next()
+	}
+}
+
+// StrictJSON reports whether the request body must be decoded strictly. It is
+// evaluated when the body is bound so that authentication on the route has run.
+func StrictJSON(c *gin.Context) bool {
+	value, exists := c.Get(strictJSONKey)
+	if !exists {
+		return false
+	}
+	mode := value.(strictJSONMode)
+	if mode.group {
+		return true
+	}
+	return mode.partners && isPartnerRequest(c)
+}
+
+// isPartnerRequest reports whether the caller is a partner integration rather
+// than a first-party client
+func isPartnerRequest(c *gin.Context) bool {
+	return c.GetString("partnerID") != "" ||
+		c.GetHeader(APIKeyHeader) != "" ||
+		c.GetString("authMethod") == "client_credentials"
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// Context keys for the request's API key record and SLA tier
+const (
+	apiKeyRecordKey = "apiKeyRecord"
+	slaTierKey      = "slaTier"
+)
+
+// SLATierMiddleware looks up the SLA tier of the request's API key before
+// route groups run, so that rate limiting and response caching, which come
+// before route-level authentication, can honor it. The tier's timeout bounds
+// the rest of the request. Invalid keys are ignored here and rejected by
+// authentication.
+func SLATierMiddleware(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(APIKeyHeader)
+		if key == "" || c.GetHeader("Authorization") != "" {
+			c.Next()
+			return
+		}
+
+		apiKey, err := resolveAPIKey(c, key)
+		if err != nil {
+			c.Next()
+			return
+		}
+		tier := cfg.SLATierFor(apiKey.Tier)
+		if tier == nil {
+			c.Next()
+			return
+		}
+		c.Set(slaTierKey, tier)
+		name := apiKey.Tier
+		if name == "" {
+			name = cfg.DefaultSLATier
+		}
+		addLogAttrs(c, "sla_tier", name)
+
+		if tier.TimeoutMs > 0 {
+			ctx, cancel := context.WithTimeout(c.Request.Context(), time.Duration(tier.TimeoutMs)*time.Millisecond)
+			defer cancel()
+			c.Request = c.Request.WithContext(ctx)
+		}
+
+		c.Next()
+	}
+}
+
+// GetSLATier returns the SLA tier of the request's API key, or nil
+func GetSLATier(c *gin.Context) *config.SLATier {
+	tier, _ := c.Value(slaTierKey).(*config.SLATier)
+	return tier
+}
+
+// resolveAPIKey looks up the request's API key once, reusing the record for
+// later middleware
+func resolveAPIKey(c *gin.Context, key string) (*models.APIKey, error) {
+	if apiKey, ok := c.Value(apiKeyRecordKey).(*models.APIKey); ok {
+		return apiKey, nil
+	}
+	apiKey, err := lookupAPIKey(c.Request.Context(), key)
+	if err != nil {
+		return nil, err
+	}
+	c.Set(apiKeyRecordKey, apiKey)
+	return apiKey, nil
+}
+
+// requestAPIKey returns the record of a valid API key sent with the request,
+// whether or not authentication has run yet
+func requestAPIKey(c *gin.Context) *models.APIKey {
+	apiKey, _ := c.Value(apiKeyRecordKey).(*models.APIKey)
+	return apiKey
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// LocalTimeSuffix is appended to a timestamp field's name for its display hint
+const LocalTimeSuffix = "_local"
+
+// locations caches loaded time zones by name
+var locations sync.Map
+
+// TimezoneHintsMiddleware adds a display hint next to every timestamp in
+// JSON responses to users whose profile sets a time zone. Timestamps stay in
+// RFC 3339 UTC, and each gains a sibling field with the same time in the
+// user's zone, e.g. created_at_local. Anonymous responses are untouched.
+func TimezoneHintsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		writer := &bufferWriter{ResponseWriter: original}
+		c.Writer = writer
+
+		c.Next()
+
+		c.Writer = original
+		body := writer.body.Bytes()
+		if loc := userLocation(c); loc != nil && writer.Status() < http.StatusMultipleChoices &&
+			strings.HasPrefix(writer.Header().Get("Content-Type"), "application/json") {
+			body = addLocalTimes(body, loc)
+		}
+		original.Write(body)
+	}
+}
+
+// userLocation returns the time zone from the user's token, or nil if none
+// is set or it is not a known zone
+func userLocation(c *gin.Context) *time.Location {
+	claims, ok := GetClaims(c)
+	if !ok || claims.Timezone == "" || claims.Timezone == "UTC" {
+		return nil
+	}
+	if loc, ok := locations.Load(claims.Timezone); ok {
+		return loc.(*time.Location)
+	}
+	loc, err := time.LoadLocation(claims.Timezone)
+	if err != nil {
+		return nil
+	}
+	locations.Store(claims.Timezone, loc)
+	return loc
+}
+
+// addLocalTimes returns body with local time hints added, or body unchanged
+// if it is not JSON
+func addLocalTimes(body []byte, loc *time.Location) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return body
+	}
+	if !annotate(value, loc) {
+		return body
+	}
+	rewritten, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return rewritten
+}
+
+// annotate adds local time hints to the objects in value, reporting whether
+// any were added
+func annotate(value any, loc *time.Location) bool {
+	changed := false
+	switch v := value.(type) {
+	case map[string]any:
+		hints := make(map[string]string)
+		for key, child := range v {
+			if s, ok := child.(string); ok {
+				if t, ok := parseResponseTimestamp(s); ok {
+					hints[key+LocalTimeSuffix] = t.In(loc).Format(time.RFC3339)
+				}
+				continue
+			}
+			changed = annotate(child, loc) || changed
+		}
+		for key, hint := range hints {
+			v[key] = hint
+			changed = true
+		}
+	case []any:
+		for _, child := range v {
+			changed = annotate(child, loc) || changed
+		}
+	}
+	return changed
+}
+
+// parseResponseTimestamp recognizes timestamps written by models.Timestamp
+func parseResponseTimestamp(s string) (time.Time, bool) {
+	if len(s) != len("2006-01-02T15:04:05Z") || s[len(s)-1] != 'Z' {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(models.TimestampLayout, s)
+	return t, err == nil && !t.IsZero()
+}
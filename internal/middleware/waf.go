@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	"github.com/ecommerce/be-api-gin/internal/waf"
+)
+
+var wafRuleHits = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "waf_rule_hits_total",
+	Help: "Requests matching WAF rules, by rule and action taken.",
+}, []string{"rule", "action"})
+
+// WAFMiddleware inspects requests for common attack signatures. In log mode
+// matches are logged and counted; in block mode the request is also refused.
+func WAFMiddleware(cfg *config.Config) gin.HandlerFunc {
+	engine := waf.NewEngine(cfg.WAFMaxHeaderBytes, cfg.WAFExclusions)
+
+	return func(c *gin.Context) {
+		if cfg.WAFMode != waf.ModeLog && cfg.WAFMode != waf.ModeBlock {
+			c.Next()
+			return
+		}
+
+		matches := engine.Inspect(c.Request)
+		if len(matches) == 0 {
+			c.Next()
+			return
+		}
+
+		action := "logged"
+		if cfg.WAFMode == waf.ModeBlock {
+			action = "blocked"
+		}
+		for _, match := range matches {
+			wafRuleHits.WithLabelValues(match.RuleID, action).Inc()
+			logging.FromContext(c.Request.Context()).Warn("WAF rule matched",
+				"rule", match.RuleID,
+				"location", match.Location,
+				"client_ip", c.ClientIP(),
+				"action", action,
+				"value", match.Value,
+			)
+		}
+
+		if cfg.WAFMode == waf.ModeBlock {
+			c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "Request blocked",
+				Message: "The request was rejected by security rules",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
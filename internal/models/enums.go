@@ -0,0 +1,334 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Category is a product category
+type Category string
+
+// Categories that come in sizes
+const (
+	CategoryClothing Category = "clothing"
+	CategoryShoes    Category = "shoes"
+)
+
+// Categories lists the product categories the catalog accepts. It is set
+// from configuration at startup by SetCategories; while empty, any category
+// is accepted and left to the catalog service to check.
+var Categories []Category
+
+// SetCategories sets the accepted product categories
+func SetCategories(names []string) {
+	Categories = make([]Category, 0, len(names))
+	for _, name := range names {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" && !slices.Contains(Categories, Category(name)) {
+			Categories = append(Categories, Category(name))
+		}
+	}
+}
+
+// ParseCategory converts a string to a Category
+func ParseCategory(s string) (Category, error) {
+	if len(Categories) == 0 {
+		return Category(s), nil
+	}
+	return parseEnum("category", s, Categories)
+}
+
+// Valid reports whether c is an accepted category
+func (c Category) Valid() bool {
+	if len(Categories) == 0 {
+		return c != ""
+	}
+	return slices.Contains(Categories, c)
+}
+
+// UnmarshalJSON rejects unknown categories
+func (c *Category) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, c, ParseCategory)
+}
+
+// Sized reports whether products in the category come in sizes, so shoppers
+// are asked how they fit
+func (c Category) Sized() bool {
+	return c == CategoryClothing || c == CategoryShoes
+}
+
+// OrderStatus is the lifecycle state of an order
+type OrderStatus string
+
+// Order statuses
+const (
+	OrderStatusPending    OrderStatus = "pending"
+	OrderStatusConfirmed  OrderStatus = "confirmed"
+	OrderStatusProcessing OrderStatus = "processing"
+	// OrderStatusPartiallyShipped is an order some of whose items have
+	// shipped while others wait, such as for a backorder
+	OrderStatusPartiallyShipped OrderStatus = "partially_shipped"
+	OrderStatusShipped          OrderStatus = "shipped"
+	OrderStatusDelivered        OrderStatus = "delivered"
+	OrderStatusCancelled        OrderStatus = "cancelled"
+)
+
+// OrderStatuses lists every order status
+var OrderStatuses = []OrderStatus{OrderStatusPending, OrderStatusConfirmed, OrderStatusProcessing, OrderStatusPartiallyShipped, OrderStatusShipped, OrderStatusDelivered, OrderStatusCancelled}
+
+// ParseOrderStatus converts a string to an OrderStatus
+func ParseOrderStatus(s string) (OrderStatus, error) {
+	return parseEnum("order status", s, OrderStatuses)
+}
+
+// Valid reports whether s is a known order status
+func (s OrderStatus) Valid() bool {
+	return slices.Contains(OrderStatuses, s)
+}
+
+// Cancellable reports whether an order in this status can still be cancelled.
+// Every status is listed so a new one has to be classified here.
+func (s OrderStatus) Cancellable() bool {
+	switch s {
+	case OrderStatusPending, OrderStatusConfirmed:
+		return true
+	case OrderStatusProcessing, OrderStatusPartiallyShipped, OrderStatusShipped, OrderStatusDelivered, OrderStatusCancelled:
+		return false
+	}
+	return false
+}
+
+// UnmarshalJSON rejects unknown order statuses
+func (s *OrderStatus) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, s, ParseOrderStatus)
+}
+
+// OrderItemStatus is where an item of an order is in fulfillment, tracked
+// per item so the rest of an order can ship while one item waits
+type OrderItemStatus string
+
+// Order item statuses
+const (
+	OrderItemStatusPending     OrderItemStatus = "pending"
+	OrderItemStatusBackordered OrderItemStatus = "backordered"
+	OrderItemStatusShipped     OrderItemStatus = "shipped"
+	OrderItemStatusDelivered   OrderItemStatus = "delivered"
+	OrderItemStatusReturned    OrderItemStatus = "returned"
+)
+
+// OrderItemStatuses lists every order item status
+var OrderItemStatuses = []OrderItemStatus{OrderItemStatusPending, OrderItemStatusBackordered, OrderItemStatusShipped, OrderItemStatusDelivered, OrderItemStatusReturned}
+
+// ParseOrderItemStatus converts a string to an OrderItemStatus
+func ParseOrderItemStatus(s string) (OrderItemStatus, error) {
+	return parseEnum("order item status", s, OrderItemStatuses)
+}
+
+// Valid reports whether s is a known order item status
+func (s OrderItemStatus) Valid() bool {
+	return slices.Contains(OrderItemStatuses, s)
+}
+
+// CanMoveTo reports whether an item in this status can be moved to next.
+// Items wait as pending or backordered until they ship, and only delivered
+// items are returned. Items of orders placed before items had statuses have
+// none and count as pending.
+func (s OrderItemStatus) CanMoveTo(next OrderItemStatus) bool {
+	switch s {
+	case "", OrderItemStatusPending, OrderItemStatusBackordered:
+		return next == OrderItemStatusPending || next == OrderItemStatusBackordered || next == OrderItemStatusShipped
+	case OrderItemStatusShipped:
+		return next == OrderItemStatusDelivered
+	case OrderItemStatusDelivered:
+		return next == OrderItemStatusReturned
+	case OrderItemStatusReturned:
+		return false
+	}
+	return false
+}
+
+// UnmarshalJSON rejects unknown order item statuses
+func (s *OrderItemStatus) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, s, ParseOrderItemStatus)
+}
+
+// ShipmentStatus is where a shipment is in the carrier's network
+type ShipmentStatus string
+
+// Shipment statuses
+const (
+	ShipmentStatusLabelCreated   ShipmentStatus = "label_created"
+	ShipmentStatusInTransit      ShipmentStatus = "in_transit"
+	ShipmentStatusOutForDelivery ShipmentStatus = "out_for_delivery"
+	ShipmentStatusDelivered      ShipmentStatus = "delivered"
+	ShipmentStatusException      ShipmentStatus = "exception"
+)
+
+// ShipmentStatuses lists every shipment status
+var ShipmentStatuses = []ShipmentStatus{ShipmentStatusLabelCreated, ShipmentStatusInTransit, ShipmentStatusOutForDelivery, ShipmentStatusDelivered, ShipmentStatusException}
+
+// ParseShipmentStatus converts a string to a ShipmentStatus
+func ParseShipmentStatus(s string) (ShipmentStatus, error) {
+	return parseEnum("shipment status", s, ShipmentStatuses)
+}
+
+// Valid reports whether s is a known shipment status
+func (s ShipmentStatus) Valid() bool {
+	return slices.Contains(ShipmentStatuses, s)
+}
+
+// UnmarshalJSON rejects unknown shipment statuses
+func (s *ShipmentStatus) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, s, ParseShipmentStatus)
+}
+
+// Fit is how a sized product fits compared to its labelled size
+type Fit string
+
+// Fits
+const (
+	FitRunsSmall  Fit = "runs_small"
+	FitTrueToSize Fit = "true_to_size"
+	FitRunsLarge  Fit = "runs_large"
+)
+
+// Fits lists every fit
+var Fits = []Fit{FitRunsSmall, FitTrueToSize, FitRunsLarge}
+
+// ParseFit converts a string to a Fit
+func ParseFit(s string) (Fit, error) {
+	return parseEnum("fit", s, Fits)
+}
+
+// Valid reports whether f is a known fit
+func (f Fit) Valid() bool {
+	return slices.Contains(Fits, f)
+}
+
+// UnmarshalJSON rejects unknown fits
+func (f *Fit) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, f, ParseFit)
+}
+
+// InventoryOperation is how an inventory update changes the stock quantity
+type InventoryOperation string
+
+// Inventory operations
+const (
+	InventoryOperationSet      InventoryOperation = "set"
+	InventoryOperationAdd      InventoryOperation = "add"
+	InventoryOperationSubtract InventoryOperation = "subtract"
+)
+
+// InventoryOperations lists every inventory operation
+var InventoryOperations = []InventoryOperation{InventoryOperationSet, InventoryOperationAdd, InventoryOperationSubtract}
+
+// ParseInventoryOperation converts a string to an InventoryOperation
+func ParseInventoryOperation(s string) (InventoryOperation, error) {
+	return parseEnum("operation", s, InventoryOperations)
+}
+
+// Valid reports whether o is a known inventory operation
+func (o InventoryOperation) Valid() bool {
+	return slices.Contains(InventoryOperations, o)
+}
+
+// UnmarshalJSON rejects unknown inventory operations
+func (o *InventoryOperation) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, o, ParseInventoryOperation)
+}
+
+// POSOrderStatus is where an order submitted by an in-store device is in
+// being placed
+type POSOrderStatus string
+
+// POS order statuses
+const (
+	POSOrderStatusAcceptedPending POSOrderStatus = "accepted_pending"
+	POSOrderStatusPlaced          POSOrderStatus = "placed"
+	POSOrderStatusRejected        POSOrderStatus = "rejected"
+)
+
+// POSOrderStatuses lists every POS order status
+var POSOrderStatuses = []POSOrderStatus{POSOrderStatusAcceptedPending, POSOrderStatusPlaced, POSOrderStatusRejected}
+
+// ParsePOSOrderStatus converts a string to a POSOrderStatus
+func ParsePOSOrderStatus(s string) (POSOrderStatus, error) {
+	return parseEnum("POS order status", s, POSOrderStatuses)
+}
+
+// Valid reports whether s is a known POS order status
+func (s POSOrderStatus) Valid() bool {
+	return slices.Contains(POSOrderStatuses, s)
+}
+
+// UnmarshalJSON rejects unknown POS order statuses
+func (s *POSOrderStatus) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, s, ParsePOSOrderStatus)
+}
+
+// POSPaymentMethod is how a customer paid at an in-store device
+type POSPaymentMethod string
+
+// POS payment methods
+const (
+	POSPaymentCash POSPaymentMethod = "cash"
+	POSPaymentCard POSPaymentMethod = "card"
+)
+
+// POSPaymentMethods lists every POS payment method
+var POSPaymentMethods = []POSPaymentMethod{POSPaymentCash, POSPaymentCard}
+
+// ParsePOSPaymentMethod converts a string to a POSPaymentMethod
+func ParsePOSPaymentMethod(s string) (POSPaymentMethod, error) {
+	return parseEnum("POS payment method", s, POSPaymentMethods)
+}
+
+// Valid reports whether m is a known POS payment method
+func (m POSPaymentMethod) Valid() bool {
+	return slices.Contains(POSPaymentMethods, m)
+}
+
+// UnmarshalJSON rejects unknown POS payment methods
+func (m *POSPaymentMethod) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, m, ParsePOSPaymentMethod)
+}
+
+// EnumError reports a value outside an enum's allowed values
+type EnumError struct {
+	Name    string
+	Value   string
+	Allowed []string
+}
+
+// Error lists the allowed values
+func (e *EnumError) Error() string {
+	return fmt.Sprintf("invalid %s %q: must be one of %s", e.Name, e.Value, strings.Join(e.Allowed, ", "))
+}
+
+// parseEnum returns s as T if it is one of the allowed values
+func parseEnum[T ~string](name, s string, allowed []T) (T, error) {
+	if slices.Contains(allowed, T(s)) {
+		return T(s), nil
+	}
+	names := make([]string, len(allowed))
+	for i, v := range allowed {
+		names[i] = string(v)
+	}
+	return "", &EnumError{Name: name, Value: s, Allowed: names}
+}
+
+// unmarshalEnum decodes a JSON string into an enum using its parser
+func unmarshalEnum[T ~string](data []byte, dst *T, parse func(string) (T, error)) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := parse(s)
+	if err != nil {
+		return err
+	}
+	*dst = v
+	return nil
+}
@@ -0,0 +1,36 @@
+package models
+
+import "testing"
+
+func TestOrderItemStatusCanMoveTo(t *testing.T) {
+	statuses := []OrderItemStatus{
+		OrderItemStatusPending,
+		OrderItemStatusBackordered,
+		OrderItemStatusShipped,
+		OrderItemStatusDelivered,
+		OrderItemStatusReturned,
+	}
+	// allowed lists the moves each status can make; any other is refused
+	allowed := map[OrderItemStatus][]OrderItemStatus{
+		"":                         {OrderItemStatusPending, OrderItemStatusBackordered, OrderItemStatusShipped},
+		OrderItemStatusPending:     {OrderItemStatusPending, OrderItemStatusBackordered, OrderItemStatusShipped},
+		OrderItemStatusBackordered: {OrderItemStatusPending, OrderItemStatusBackordered, OrderItemStatusShipped},
+		OrderItemStatusShipped:     {OrderItemStatusDelivered},
+		OrderItemStatusDelivered:   {OrderItemStatusReturned},
+		OrderItemStatusReturned:    nil,
+	}
+
+	for from, moves := range allowed {
+		for _, to := range statuses {
+			want := false
+			for _, move := range moves {
+				if move == to {
+					want = true
+				}
+			}
+			if got := from.CanMoveTo(to); got != want {
+				t.Errorf("%q.CanMoveTo(%q) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+}
@@ -1,11 +1,21 @@
 package models
 
-import "time"
+import (
+	"maps"
+	"slices"
+)
 
-// ErrorResponse represents an error response
+// ErrorResponse represents an error response. Code, RequestID, TraceID, and
+// the retry hints are filled in by RequestIDMiddleware when a handler leaves
+// them empty.
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message"`
+	Error      string `json:"error"`
+	Message    string `json:"message"`
+	Code       string `json:"code,omitempty"`
+	RequestID  string `json:"request_id,omitempty"`
+	TraceID    string `json:"trace_id,omitempty"`
+	Retryable  *bool  `json:"retryable,omitempty"`
+	RetryAfter int    `json:"retry_after,omitempty"` // seconds
 }
 
 // SuccessResponse represents a success response
@@ -22,48 +32,661 @@ type PaginatedResponse struct {
 	TotalPages int64       `json:"total_pages"`
 }
 
+// ResponseWarning explains a part of a response left out because a backend
+// service was unavailable
+type ResponseWarning struct {
+	Component string `json:"component"`
+	Message   string `json:"message"`
+}
+
+// ProductDetailResponse represents a product with its inventory and latest
+// reviews. Parts that could not be fetched are omitted and listed in Warnings.
+type ProductDetailResponse struct {
+	Product     *Product          `json:"product"`
+	Inventory   *Inventory        `json:"inventory,omitempty"`
+	Reviews     []*Review         `json:"reviews,omitempty"`
+	ReviewCount int64             `json:"review_count"`
+	Warnings    []ResponseWarning `json:"warnings,omitempty"`
+}
+
+// BarcodeMatch is the product a scanned barcode belongs to, with its
+// inventory. Error says why nothing matched; parts that could not be
+// fetched are omitted and listed in Warnings.
+type BarcodeMatch struct {
+	Barcode   string            `json:"barcode"`
+	GTIN      string            `json:"gtin,omitempty"`
+	Product   *Product          `json:"product,omitempty"`
+	Inventory *Inventory        `json:"inventory,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	Warnings  []ResponseWarning `json:"warnings,omitempty"`
+}
+
+// BarcodeLookupRequest represents a batch of scanned barcodes to look up
+type BarcodeLookupRequest struct {
+	Barcodes []string `json:"barcodes" binding:"required,min=1,max=100"`
+}
+
+// BarcodeLookupResponse lists the match for each barcode, in the order given
+type BarcodeLookupResponse struct {
+	Results []BarcodeMatch `json:"results"`
+}
+
+// ProductComparison lines up the attributes of products side by side, with
+// one value per product in each row in the order of Products
+type ProductComparison struct {
+	Products   []ComparedProduct `json:"products"`
+	Attributes []ComparisonRow   `json:"attributes"`
+}
+
+// ComparedProduct summarizes a product in a comparison
+type ComparedProduct struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Price    float64 `json:"price"`
+	Currency string  `json:"currency,omitempty"`
+	Category string  `json:"category,omitempty"`
+	ImageUrl string  `json:"imageUrl,omitempty"`
+	InStock  bool    `json:"inStock"`
+}
+
+// ComparisonRow is an attribute's value for each compared product, null
+// where a product lacks it. Differs is set unless every product has the
+// same value.
+type ComparisonRow struct {
+	Key     string    `json:"key"`
+	Values  []*string `json:"values"`
+	Differs bool      `json:"differs"`
+}
+
 // ProductsResponse represents a paginated products response
 type ProductsResponse struct {
-	Products   []*Product `json:"products"`
-	Page       int        `json:"page"`
-	Limit      int        `json:"limit"`
-	Total      int64      `json:"total"`
+	Products []*Product `json:"products"`
+	Page     int        `json:"page"`
+	Limit    int        `json:"limit"`
+	Total    int64      `json:"total"`
+	// Degraded is set when results come from the fallback index
+	Degraded bool `json:"degraded,omitempty"`
 }
 
 // Product represents a product
 type Product struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Price       float64   `json:"price"`
-	Category    string    `json:"category,omitempty"`
-	ImageUrl    string    `json:"imageUrl,omitempty"`
-	Images      []string  `json:"images,omitempty"`
-	SellerID    string    `json:"seller_id,omitempty"`
-	Stock       int32     `json:"stock,omitempty"`
-	InStock     bool      `json:"inStock"`
-	Available   bool      `json:"available,omitempty"`
-	CreatedAt   time.Time `json:"createdAt,omitempty"`
-	UpdatedAt   time.Time `json:"updatedAt,omitempty"`
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price"`
+	Currency    string  `json:"currency,omitempty"`
+	// SettlementPrice is the price in the seller's SettlementCurrency, which
+	// orders are charged in, when Price is shown in another currency
+	SettlementPrice    float64           `json:"settlement_price,omitempty"`
+	SettlementCurrency string            `json:"settlement_currency,omitempty"`
+	Category           string            `json:"category,omitempty"`
+	ImageUrl           string            `json:"imageUrl,omitempty"`
+	Images             []string          `json:"images,omitempty"`
+	ImageHashes        []string          `json:"image_hashes,omitempty"`
+	SellerID           string            `json:"seller_id,omitempty"`
+	Stock              int32             `json:"stock,omitempty"`
+	InStock            bool              `json:"inStock"`
+	Available          bool              `json:"available,omitempty"`
+	VacationUntil      *Timestamp        `json:"vacation_until,omitempty"` // the seller is on vacation until then
+	Dispatch           *DispatchPromise  `json:"dispatch,omitempty"`
+	Restriction        *Restriction      `json:"restriction,omitempty"`
+	Fit                *FitSummary       `json:"fit,omitempty"`
+	Attributes         map[string]string `json:"attributes,omitempty"`
+	Barcodes           []string          `json:"barcodes,omitempty"` // 14-digit GTINs
+	ModerationStatus   string            `json:"moderation_status,omitempty"`
+	Status             string            `json:"status,omitempty"`
+	PublishAt          *Timestamp        `json:"publish_at,omitempty"`
+	Locale             string            `json:"locale,omitempty"`
+	CreatedAt          Timestamp         `json:"createdAt,omitempty"`
+	UpdatedAt          Timestamp         `json:"updatedAt,omitempty"`
+}
+
+// Clone copies a product deeply enough that the copy's images, barcodes,
+// and attributes can be changed without changing the original
+func (p *Product) Clone() *Product {
+	clone := *p
+	clone.Images = slices.Clone(p.Images)
+	clone.ImageHashes = slices.Clone(p.ImageHashes)
+	clone.Barcodes = slices.Clone(p.Barcodes)
+	clone.Attributes = maps.Clone(p.Attributes)
+	return &clone
+}
+
+// Product publishing statuses. Products without a status predate drafts and
+// are published.
+const (
+	ProductStatusDraft     = "draft"
+	ProductStatusScheduled = "scheduled"
+	ProductStatusPublished = "published"
+)
+
+// DispatchPromise is when an order placed now would leave the warehouse:
+// on DispatchDate, in the warehouse's time zone, if placed before OrderBy
+type DispatchPromise struct {
+	SameDay      bool      `json:"same_day"`
+	DispatchDate string    `json:"dispatch_date"`
+	OrderBy      Timestamp `json:"order_by"`
+	Message      string    `json:"message"` // e.g. "Order within 2h 13m for same-day dispatch"
+}
+
+// Holiday is a date with no business. Region is where it is defined,
+// which for a subdivision's holidays may be its country.
+type Holiday struct {
+	Date   string `json:"date"`
+	Name   string `json:"name"`
+	Region string `json:"region"`
+}
+
+// HolidaysResponse lists a region's holidays between two dates
+type HolidaysResponse struct {
+	Region   string    `json:"region"`
+	From     string    `json:"from"`
+	To       string    `json:"to"`
+	Holidays []Holiday `json:"holidays"`
+}
+
+// CalendarDay reports whether a date is a holiday in a region
+type CalendarDay struct {
+	Date    string   `json:"date"`
+	Region  string   `json:"region"`
+	Holiday *Holiday `json:"holiday,omitempty"`
+}
+
+// Restriction represents sale restrictions on a product, such as alcohol or blades
+type Restriction struct {
+	MinimumAge        int  `json:"minimum_age" binding:"gte=0,lte=120"`
+	RequiresSignature bool `json:"requires_signature"`
 }
 
 // CreateProductRequest represents a request to create a product
 type CreateProductRequest struct {
-	Name         string   `json:"name" binding:"required,min=1,max=200"`
-	Description  string   `json:"description" binding:"max=5000"`
-	Price        float64  `json:"price" binding:"required,gt=0"`
-	Category     string   `json:"category" binding:"required"`
-	Images       []string `json:"images"`
-	InitialStock int32    `json:"initial_stock" binding:"gte=0"`
+	Name         string            `json:"name" binding:"required,min=1,max=200" normalize:"nfc,trim,collapse"`
+	Description  string            `json:"description" binding:"max=5000" normalize:"nfc,trim"`
+	Price        float64           `json:"price" binding:"required,gt=0"`
+	Currency     string            `json:"currency,omitempty" binding:"omitempty,len=3,alpha" normalize:"trim,upper"` // the seller's; the payment currency by default
+	Category     Category          `json:"category" binding:"required"`
+	Images       []string          `json:"images"`
+	ImageHashes  []string          `json:"image_hashes,omitempty" binding:"omitempty,dive,hexadecimal,len=16"`
+	InitialStock int32             `json:"initial_stock" binding:"gte=0"`
+	Restriction  *Restriction      `json:"restriction,omitempty"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+	Barcodes     []string          `json:"barcodes,omitempty" binding:"omitempty,max=10"`
+	// Status may be draft or published (the default). Setting PublishAt
+	// schedules the product instead.
+	Status    string     `json:"status" binding:"omitempty,oneof=draft published"`
+	PublishAt *Timestamp `json:"publish_at,omitempty"`
+}
+
+// PublishProductRequest represents a request to publish a draft now or at
+// PublishAt
+type PublishProductRequest struct {
+	PublishAt *Timestamp `json:"publish_at,omitempty"`
+}
+
+// Job statuses
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+)
+
+// Job types
+const (
+	JobTypeSearchReindex = "search_reindex"
+	JobTypeVirusScan     = "virus_scan"
+)
+
+// Job is a long-running background task and its progress
+type Job struct {
+	ID         string            `json:"id"`
+	Type       string            `json:"type"`
+	Status     string            `json:"status"`
+	Params     map[string]string `json:"params,omitempty"`
+	Total      int               `json:"total"`
+	Processed  int               `json:"processed"`
+	Failed     int               `json:"failed"`
+	Error      string            `json:"error,omitempty"`
+	CreatedBy  string            `json:"created_by,omitempty"`
+	CreatedAt  Timestamp         `json:"created_at"`
+	StartedAt  *Timestamp        `json:"started_at,omitempty"`
+	FinishedAt *Timestamp        `json:"finished_at,omitempty"`
+}
+
+// ReindexRequest represents a request to rebuild the search index for the
+// given products, or the whole catalog when none are given
+type ReindexRequest struct {
+	ProductIDs []string `json:"product_ids" binding:"max=1000"`
+}
+
+// Translation sources
+const (
+	TranslationSourceSeller  = "seller"
+	TranslationSourceMachine = "machine"
+)
+
+// ProductTranslation holds a product's name and description in another
+// locale. SourceHash identifies the original text it was translated from; a
+// translation is stale once the product's text no longer matches it.
+type ProductTranslation struct {
+	Locale      string    `json:"locale"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Source      string    `json:"source"`
+	SourceHash  string    `json:"source_hash,omitempty"`
+	Stale       bool      `json:"stale,omitempty"`
+	UpdatedAt   Timestamp `json:"updated_at"`
+}
+
+// SubmitTranslationRequest represents a seller's translation of a product
+type SubmitTranslationRequest struct {
+	Name        string `json:"name" binding:"required,min=1,max=200" normalize:"nfc,trim,collapse"`
+	Description string `json:"description" binding:"max=5000" normalize:"nfc,trim"`
+}
+
+// PreviewTokenResponse represents a shareable preview link for an
+// unpublished product
+type PreviewTokenResponse struct {
+	Token     string    `json:"token"`
+	URL       string    `json:"url"`
+	ExpiresAt Timestamp `json:"expires_at"`
+}
+
+// ProductIncompleteResponse lists the problems preventing a product from
+// being published
+type ProductIncompleteResponse struct {
+	Error   string         `json:"error"`
+	Message string         `json:"message"`
+	Issues  []CatalogIssue `json:"issues"`
+}
+
+// CreateProductResponse represents a created product along with any
+// possible duplicates found when duplicate detection is in warn mode
+type CreateProductResponse struct {
+	*Product
+	PossibleDuplicates []DuplicateMatch `json:"possible_duplicates,omitempty"`
+}
+
+// DuplicateMatch represents an existing product similar to a new listing
+type DuplicateMatch struct {
+	ProductID   string   `json:"product_id"`
+	ProductName string   `json:"product_name"`
+	SellerID    string   `json:"seller_id,omitempty"`
+	Score       float64  `json:"score"`
+	Reasons     []string `json:"reasons"`
+}
+
+// Duplicate flag statuses
+const (
+	DuplicateFlagPending   = "pending"
+	DuplicateFlagDismissed = "dismissed"
+	DuplicateFlagRemoved   = "removed"
+)
+
+// DuplicateFlag represents a listing queued for admin review as a possible duplicate
+type DuplicateFlag struct {
+	ID         string           `json:"id"`
+	ProductID  string           `json:"product_id"`
+	SellerID   string           `json:"seller_id"`
+	Matches    []DuplicateMatch `json:"matches"`
+	Status     string           `json:"status"`
+	ResolvedBy string           `json:"resolved_by,omitempty"`
+	Notes      string           `json:"notes,omitempty"`
+	CreatedAt  Timestamp        `json:"created_at"`
+	ResolvedAt *Timestamp       `json:"resolved_at,omitempty"`
+}
+
+// ResolveDuplicateFlagRequest represents an admin decision on a flagged duplicate
+type ResolveDuplicateFlagRequest struct {
+	Resolution string `json:"resolution" binding:"required,oneof=dismissed removed"`
+	Notes      string `json:"notes" binding:"max=1000"`
 }
 
 // UpdateProductRequest represents a request to update a product
 type UpdateProductRequest struct {
-	Name        *string   `json:"name,omitempty" binding:"omitempty,min=1,max=200"`
-	Description *string   `json:"description,omitempty" binding:"omitempty,max=5000"`
-	Price       *float64  `json:"price,omitempty" binding:"omitempty,gt=0"`
-	Category    *string   `json:"category,omitempty"`
-	Images      *[]string `json:"images,omitempty"`
+	Name        *string            `json:"name,omitempty" binding:"omitempty,min=1,max=200" normalize:"nfc,trim,collapse"`
+	Description *string            `json:"description,omitempty" binding:"omitempty,max=5000" normalize:"nfc,trim"`
+	Price       *float64           `json:"price,omitempty" binding:"omitempty,gt=0"`
+	Category    *Category          `json:"category,omitempty"`
+	Images      *[]string          `json:"images,omitempty"`
+	Restriction *Restriction       `json:"restriction,omitempty"`
+	Attributes  *map[string]string `json:"attributes,omitempty"`
+	Barcodes    *[]string          `json:"barcodes,omitempty" binding:"omitempty,max=10"`
+}
+
+// PriceUpdate sets the price of one product
+type PriceUpdate struct {
+	ProductID string  `json:"product_id" binding:"required"`
+	Price     float64 `json:"price" binding:"required,gt=0"`
+}
+
+// BulkPriceUpdateRequest represents a request to change several prices at once
+type BulkPriceUpdateRequest struct {
+	Prices []PriceUpdate `json:"prices" binding:"required,min=1,max=100,dive"`
+}
+
+// BulkPriceUpdateResponse represents the products after a bulk price change
+type BulkPriceUpdateResponse struct {
+	Products []*Product `json:"products"`
+	Undo     *UndoInfo  `json:"undo,omitempty"`
+}
+
+// Review represents a customer review of a product
+type Review struct {
+	ID               string          `json:"id"`
+	ProductID        string          `json:"product_id"`
+	UserID           string          `json:"user_id"`
+	Rating           int             `json:"rating"`
+	Title            string          `json:"title,omitempty"`
+	Body             string          `json:"body"`
+	ModerationStatus string          `json:"moderation_status"`
+	Response         *ReviewResponse `json:"response,omitempty"`
+	CreatedAt        Timestamp       `json:"created_at"`
+}
+
+// CreateReviewRequest represents a request to review a product
+type CreateReviewRequest struct {
+	Rating int    `json:"rating" binding:"required,min=1,max=5"`
+	Title  string `json:"title" binding:"max=200" normalize:"nfc,trim,collapse"`
+	Body   string `json:"body" binding:"required,min=1,max=5000" normalize:"nfc,trim"`
+}
+
+// ReviewResponse represents a seller's public reply to a review
+type ReviewResponse struct {
+	SellerID         string    `json:"seller_id"`
+	Body             string    `json:"body"`
+	ModerationStatus string    `json:"moderation_status"`
+	CreatedAt        Timestamp `json:"created_at"`
+}
+
+// CreateReviewResponseRequest represents a seller's reply to a review
+type CreateReviewResponseRequest struct {
+	Body string `json:"body" binding:"required,min=1,max=2000" normalize:"nfc,trim"`
+}
+
+// FitFeedback is a reviewer's report of how a sized product fit them
+type FitFeedback struct {
+	ReviewID  string    `json:"review_id"`
+	ProductID string    `json:"product_id"`
+	UserID    string    `json:"-"`
+	Fit       Fit       `json:"fit"`
+	Size      string    `json:"size,omitempty"` // the size they bought
+	CreatedAt Timestamp `json:"created_at"`
+}
+
+// FitFeedbackRequest represents a reviewer's report of how a product fit
+type FitFeedbackRequest struct {
+	Fit  Fit    `json:"fit" binding:"required"`
+	Size string `json:"size" binding:"max=20" normalize:"trim"`
+}
+
+// FitSummary counts how reviewers say a product fits. Hint is the fit most
+// of them report, once enough have.
+type FitSummary struct {
+	Responses  int64  `json:"responses"`
+	RunsSmall  int64  `json:"runs_small"`
+	TrueToSize int64  `json:"true_to_size"`
+	RunsLarge  int64  `json:"runs_large"`
+	Hint       Fit    `json:"hint,omitempty"`
+	Message    string `json:"message,omitempty"` // e.g. "Most customers say this runs small; consider a size up"
+}
+
+// SizeGuide gives a product's, or a category's, body measurements for each
+// size. A product's own guide takes precedence over its category's.
+type SizeGuide struct {
+	ProductID    string          `json:"product_id,omitempty"`
+	Category     Category        `json:"category,omitempty"`
+	Unit         string          `json:"unit"`
+	Measurements []string        `json:"measurements"` // e.g. chest, waist
+	Sizes        []SizeGuideSize `json:"sizes"`
+	UpdatedAt    Timestamp       `json:"updated_at"`
+}
+
+// SizeGuideSize is a size's value for each of its guide's measurements
+type SizeGuideSize struct {
+	Label  string             `json:"label" binding:"required,max=20" normalize:"trim"`
+	Values map[string]float64 `json:"values" binding:"required"`
+}
+
+// SizeGuideRequest represents a request to set a size guide
+type SizeGuideRequest struct {
+	Unit         string          `json:"unit" binding:"required,oneof=cm in"`
+	Measurements []string        `json:"measurements" binding:"required,min=1,max=10,dive,required,max=50"`
+	Sizes        []SizeGuideSize `json:"sizes" binding:"required,min=1,max=30,dive"`
+}
+
+// Question represents a shopper question about a product
+type Question struct {
+	ID               string    `json:"id"`
+	ProductID        string    `json:"product_id"`
+	UserID           string    `json:"user_id"`
+	Body             string    `json:"body"`
+	AnswerCount      int       `json:"answer_count"`
+	ModerationStatus string    `json:"moderation_status"`
+	CreatedAt        Timestamp `json:"created_at"`
+}
+
+// CreateQuestionRequest represents a request to ask a product question
+type CreateQuestionRequest struct {
+	Body string `json:"body" binding:"required,min=1,max=1000" normalize:"nfc,trim"`
+}
+
+// Answer represents an answer to a product question from the seller or
+// another shopper
+type Answer struct {
+	ID               string    `json:"id"`
+	QuestionID       string    `json:"question_id"`
+	UserID           string    `json:"user_id"`
+	Body             string    `json:"body"`
+	IsSeller         bool      `json:"is_seller"`
+	Upvotes          int       `json:"upvotes"`
+	Downvotes        int       `json:"downvotes"`
+	ModerationStatus string    `json:"moderation_status"`
+	CreatedAt        Timestamp `json:"created_at"`
+}
+
+// CreateAnswerRequest represents a request to answer a product question
+type CreateAnswerRequest struct {
+	Body string `json:"body" binding:"required,min=1,max=2000" normalize:"nfc,trim"`
+}
+
+// Answer votes
+const (
+	VoteUp   = "up"
+	VoteDown = "down"
+)
+
+// VoteAnswerRequest represents a helpfulness vote on an answer
+type VoteAnswerRequest struct {
+	Vote string `json:"vote" binding:"required,oneof=up down"`
+}
+
+// Moderation content types
+const (
+	ContentTypeProduct        = "product"
+	ContentTypeReview         = "review"
+	ContentTypeReviewResponse = "review_response"
+	ContentTypeQuestion       = "question"
+	ContentTypeAnswer         = "answer"
+	ContentTypeImage          = "image"
+)
+
+// Media represents an uploaded image
+type Media struct {
+	ID               string    `json:"id"`
+	OwnerID          string    `json:"owner_id"`
+	ProductID        string    `json:"product_id,omitempty"`
+	URL              string    `json:"url,omitempty"`
+	Filename         string    `json:"filename"`
+	ContentType      string    `json:"content_type"`
+	Size             int64     `json:"size"`
+	ModerationStatus string    `json:"moderation_status"`
+	ScanStatus       string    `json:"scan_status,omitempty"`
+	CreatedAt        Timestamp `json:"created_at"`
+}
+
+// Media malware scan statuses. Media is only published once its scan is clean.
+const (
+	ScanStatusPending  = "pending"
+	ScanStatusClean    = "clean"
+	ScanStatusInfected = "infected"
+	ScanStatusFailed   = "failed"
+)
+
+// Notification represents a message delivered to a user
+type Notification struct {
+	Type    string            `json:"type"`
+	Title   string            `json:"title"`
+	Message string            `json:"message"`
+	Data    map[string]string `json:"data,omitempty"`
+}
+
+// Moderation queue item statuses
+const (
+	ModerationItemPending  = "pending"
+	ModerationItemApproved = "approved"
+	ModerationItemRejected = "rejected"
+)
+
+// ModerationItem represents quarantined content awaiting admin review
+type ModerationItem struct {
+	ID          string     `json:"id"`
+	ContentType string     `json:"content_type"`
+	ContentID   string     `json:"content_id"`
+	AuthorID    string     `json:"author_id"`
+	Reasons     []string   `json:"reasons"`
+	Status      string     `json:"status"`
+	ReviewedBy  string     `json:"reviewed_by,omitempty"`
+	Notes       string     `json:"notes,omitempty"`
+	CreatedAt   Timestamp  `json:"created_at"`
+	ReviewedAt  *Timestamp `json:"reviewed_at,omitempty"`
+}
+
+// ResolveModerationItemRequest represents an admin decision on quarantined content
+type ResolveModerationItemRequest struct {
+	Decision string `json:"decision" binding:"required,oneof=approved rejected"`
+	Notes    string `json:"notes" binding:"max=1000"`
+}
+
+// Abuse report reasons
+const (
+	ReportReasonSpam        = "spam"
+	ReportReasonCounterfeit = "counterfeit"
+	ReportReasonProhibited  = "prohibited_item"
+	ReportReasonMisleading  = "misleading"
+	ReportReasonFraud       = "fraud"
+	ReportReasonOffensive   = "offensive"
+	ReportReasonFake        = "fake_review"
+	ReportReasonOffTopic    = "off_topic"
+	ReportReasonOther       = "other"
+)
+
+// ReportReasons lists the reasons that may be reported for each content type
+var ReportReasons = map[string][]string{
+	ContentTypeProduct: {
+		ReportReasonSpam,
+		ReportReasonCounterfeit,
+		ReportReasonProhibited,
+		ReportReasonMisleading,
+		ReportReasonFraud,
+		ReportReasonOffensive,
+		ReportReasonOther,
+	},
+	ContentTypeReview: {
+		ReportReasonSpam,
+		ReportReasonFake,
+		ReportReasonOffensive,
+		ReportReasonOffTopic,
+		ReportReasonOther,
+	},
+}
+
+// Abuse report statuses
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusActioned  = "actioned"
+	ReportStatusDismissed = "dismissed"
+)
+
+// AbuseReport represents a user report of an abusive listing or review
+type AbuseReport struct {
+	ID          string     `json:"id"`
+	ContentType string     `json:"content_type"`
+	ContentID   string     `json:"content_id"`
+	ReporterID  string     `json:"reporter_id"`
+	Reason      string     `json:"reason"`
+	Details     string     `json:"details,omitempty"`
+	Status      string     `json:"status"`
+	ResolvedBy  string     `json:"resolved_by,omitempty"`
+	Notes       string     `json:"notes,omitempty"`
+	CreatedAt   Timestamp  `json:"created_at"`
+	ResolvedAt  *Timestamp `json:"resolved_at,omitempty"`
+}
+
+// CreateAbuseReportRequest represents a request to report content
+type CreateAbuseReportRequest struct {
+	Reason  string `json:"reason" binding:"required"`
+	Details string `json:"details" binding:"max=1000" normalize:"nfc,trim"`
+}
+
+// ResolveAbuseReportRequest represents an admin decision on an abuse report.
+// Actioning a report takes the reported content down.
+type ResolveAbuseReportRequest struct {
+	Decision string `json:"decision" binding:"required,oneof=actioned dismissed"`
+	Notes    string `json:"notes" binding:"max=1000"`
+}
+
+// IP rule lists
+const (
+	IPListAllow = "allow"
+	IPListDeny  = "deny"
+)
+
+// IPRule represents an address range on the admin allowlist or the global
+// denylist. Rules from configuration are static and cannot be removed.
+type IPRule struct {
+	ID        string     `json:"id"`
+	List      string     `json:"list"`
+	CIDR      string     `json:"cidr"`
+	Reason    string     `json:"reason,omitempty"`
+	Static    bool       `json:"static"`
+	CreatedBy string     `json:"created_by,omitempty"`
+	CreatedAt Timestamp  `json:"created_at"`
+	ExpiresAt *Timestamp `json:"expires_at,omitempty"`
+}
+
+// CreateIPRuleRequest represents a request to add an IP rule. A TTL of zero
+// keeps the rule until it is deleted.
+type CreateIPRuleRequest struct {
+	List       string `json:"list" binding:"required,oneof=allow deny"`
+	CIDR       string `json:"cidr" binding:"required"`
+	Reason     string `json:"reason" binding:"max=500"`
+	TTLSeconds int64  `json:"ttl_seconds" binding:"min=0"`
+}
+
+// Account risk levels
+const (
+	RiskLevelLow      = "low"
+	RiskLevelElevated = "elevated"
+	RiskLevelHigh     = "high"
+)
+
+// RiskSignal is one contribution to an account's risk score
+type RiskSignal struct {
+	Name   string `json:"name"`
+	Value  int    `json:"value"`
+	Points int    `json:"points"`
+}
+
+// RiskScore represents an account's aggregated risk. Scores range from 0 to
+// 100; elevated accounts must step up verification for risky actions and
+// high-risk accounts are held for manual review.
+type RiskScore struct {
+	UserID    string       `json:"user_id"`
+	Score     int          `json:"score"`
+	Level     string       `json:"level"`
+	Signals   []RiskSignal `json:"signals"`
+	UpdatedAt Timestamp    `json:"updated_at"`
 }
 
 // Inventory represents inventory information
@@ -76,21 +699,362 @@ type Inventory struct {
 
 // UpdateInventoryRequest represents a request to update inventory
 type UpdateInventoryRequest struct {
-	Quantity  int32  `json:"quantity" binding:"required"`
-	Operation string `json:"operation" binding:"required,oneof=set add subtract"`
+	Quantity  int32              `json:"quantity" binding:"required"`
+	Operation InventoryOperation `json:"operation" binding:"required"`
+}
+
+// Stock transfer statuses
+const (
+	TransferStatusRequested = "requested"
+	TransferStatusInTransit = "in_transit"
+	TransferStatusReceived  = "received"
+)
+
+// StockTransfer represents a movement of stock between warehouses
+type StockTransfer struct {
+	ID                     string     `json:"id"`
+	ProductID              string     `json:"product_id"`
+	SourceWarehouseID      string     `json:"source_warehouse_id"`
+	DestinationWarehouseID string     `json:"destination_warehouse_id"`
+	Quantity               int32      `json:"quantity"`
+	ReceivedQuantity       int32      `json:"received_quantity"`
+	Discrepancy            int32      `json:"discrepancy"`
+	Status                 string     `json:"status"`
+	Notes                  string     `json:"notes,omitempty"`
+	RequestedBy            string     `json:"requested_by"`
+	ApprovedBy             string     `json:"approved_by,omitempty"`
+	ReceivedBy             string     `json:"received_by,omitempty"`
+	CreatedAt              Timestamp  `json:"created_at"`
+	ApprovedAt             *Timestamp `json:"approved_at,omitempty"`
+	ReceivedAt             *Timestamp `json:"received_at,omitempty"`
+}
+
+// CreateStockTransferRequest represents a request to transfer stock between warehouses
+type CreateStockTransferRequest struct {
+	ProductID              string `json:"product_id" binding:"required"`
+	SourceWarehouseID      string `json:"source_warehouse_id" binding:"required"`
+	DestinationWarehouseID string `json:"destination_warehouse_id" binding:"required,nefield=SourceWarehouseID"`
+	Quantity               int32  `json:"quantity" binding:"required,gt=0"`
+	Notes                  string `json:"notes" binding:"max=1000"`
+}
+
+// ReceiveStockTransferRequest represents the quantity counted at the destination
+type ReceiveStockTransferRequest struct {
+	ReceivedQuantity *int32 `json:"received_quantity" binding:"required,gte=0"`
+	Notes            string `json:"notes" binding:"max=1000"`
+}
+
+// Cycle count statuses
+const (
+	CycleCountStatusScheduled = "scheduled"
+	CycleCountStatusCounted   = "counted"
+	CycleCountStatusAdjusted  = "adjusted"
+)
+
+// PickLocation is the bin a product is picked from at a warehouse
+type PickLocation struct {
+	ProductID   string `json:"product_id"`
+	WarehouseID string `json:"warehouse_id"`
+	Bin         string `json:"bin"`
+}
+
+// PickListRequest asks for a pick list of the items of several orders at a
+// warehouse
+type PickListRequest struct {
+	WarehouseID string   `json:"warehouse_id" binding:"required"`
+	OrderIDs    []string `json:"order_ids" binding:"required,min=1,max=100,unique,dive,required"`
+}
+
+// CycleCount represents a scheduled physical count of stock at a warehouse
+type CycleCount struct {
+	ID           string           `json:"id"`
+	WarehouseID  string           `json:"warehouse_id"`
+	Status       string           `json:"status"`
+	ScheduledFor Timestamp        `json:"scheduled_for"`
+	Lines        []CycleCountLine `json:"lines"`
+	CreatedBy    string           `json:"created_by"`
+	CountedBy    string           `json:"counted_by,omitempty"`
+	AdjustedBy   string           `json:"adjusted_by,omitempty"`
+	CreatedAt    Timestamp        `json:"created_at"`
+	CountedAt    *Timestamp       `json:"counted_at,omitempty"`
+	AdjustedAt   *Timestamp       `json:"adjusted_at,omitempty"`
+}
+
+// CycleCountLine represents the count for a single product in a cycle count
+type CycleCountLine struct {
+	ProductID       string `json:"product_id"`
+	SystemQuantity  int32  `json:"system_quantity"`
+	CountedQuantity *int32 `json:"counted_quantity,omitempty"`
+	Variance        int32  `json:"variance"`
+	// AdjustmentID is set once the line's variance has been applied
+	AdjustmentID string `json:"adjustment_id,omitempty"`
+}
+
+// ScheduleCycleCountRequest represents a request to schedule a cycle count
+type ScheduleCycleCountRequest struct {
+	WarehouseID  string    `json:"warehouse_id" binding:"required"`
+	ProductIDs   []string  `json:"product_ids" binding:"required,min=1,dive,required"`
+	ScheduledFor Timestamp `json:"scheduled_for" binding:"required"`
+}
+
+// SubmitCycleCountRequest represents counted quantities for a cycle count
+type SubmitCycleCountRequest struct {
+	Counts []CountedQuantity `json:"counts" binding:"required,min=1,dive"`
+}
+
+// CountedQuantity represents the physically counted quantity of a product
+type CountedQuantity struct {
+	ProductID string `json:"product_id" binding:"required"`
+	Quantity  *int32 `json:"counted_quantity" binding:"required,gte=0"`
+}
+
+// Inventory adjustment reason codes
+const (
+	AdjustmentReasonMiscount = "miscount"
+	AdjustmentReasonDamaged  = "damaged"
+	AdjustmentReasonLost     = "lost"
+	AdjustmentReasonFound    = "found"
+	AdjustmentReasonTheft    = "theft"
+	AdjustmentReasonExpired  = "expired"
+)
+
+// ApplyCycleCountRequest represents a request to apply cycle count variances as adjustments
+type ApplyCycleCountRequest struct {
+	ReasonCode string `json:"reason_code" binding:"required,oneof=miscount damaged lost found theft expired"`
+	Notes      string `json:"notes" binding:"max=1000"`
+}
+
+// InventoryAdjustment represents an audited change to on-hand stock
+type InventoryAdjustment struct {
+	ID               string    `json:"id"`
+	WarehouseID      string    `json:"warehouse_id"`
+	ProductID        string    `json:"product_id"`
+	PreviousQuantity int32     `json:"previous_quantity"`
+	NewQuantity      int32     `json:"new_quantity"`
+	Delta            int32     `json:"delta"`
+	ReasonCode       string    `json:"reason_code"`
+	Notes            string    `json:"notes,omitempty"`
+	CycleCountID     string    `json:"cycle_count_id,omitempty"`
+	AdjustedBy       string    `json:"adjusted_by"`
+	CreatedAt        Timestamp `json:"created_at"`
+}
+
+// SetLogLevelRequest represents a request to change the log level at
+// runtime. A positive duration restores the previous level afterwards.
+type SetLogLevelRequest struct {
+	Level           string `json:"level" binding:"required,oneof=debug info warn error"`
+	DurationSeconds int    `json:"duration_seconds" binding:"gte=0,lte=86400"`
+}
+
+// LogLevelResponse represents the current log level of a gateway instance
+type LogLevelResponse struct {
+	Level     string     `json:"level"`
+	RevertsAt *Timestamp `json:"reverts_at,omitempty"`
+}
+
+// InFlightRequest is a request a gateway instance is still handling
+type InFlightRequest struct {
+	RequestID string    `json:"request_id"`
+	Method    string    `json:"method"`
+	Route     string    `json:"route"`
+	Path      string    `json:"path"`
+	UserID    string    `json:"user_id,omitempty"`
+	ClientIP  string    `json:"client_ip"`
+	StartedAt Timestamp `json:"started_at"`
+	AgeMs     int64     `json:"age_ms"`
+}
+
+// BackendTarget is the address a gateway instance sends a backend service's
+// calls in one region to, and any previous address traffic is moving from
+type BackendTarget struct {
+	Backend       string  `json:"backend"`
+	Region        string  `json:"region"`
+	Addr          string  `json:"addr"`
+	Healthy       bool    `json:"healthy"`
+	PreviousAddr  string  `json:"previous_addr,omitempty"`
+	ShiftProgress float64 `json:"shift_progress,omitempty"` // share of calls on addr while shifting
+}
+
+// SetBackendTargetRequest moves a backend service's calls in a region, or
+// the gateway's own region if none is given, to a new address
+type SetBackendTargetRequest struct {
+	Region string `json:"region"`
+	Addr   string `json:"addr" binding:"required"`
+}
+
+// Undoable action types
+const (
+	ActionProductDelete = "product.delete"
+	ActionPriceChange   = "product.price_change"
+)
+
+// UndoableAction records a destructive seller action that can be reversed
+// until ExpiresAt
+type UndoableAction struct {
+	ID        string        `json:"id"`
+	Type      string        `json:"type"`
+	SellerID  string        `json:"seller_id"`
+	ProductID string        `json:"product_id,omitempty"`
+	Prices    []PriceUpdate `json:"prices,omitempty"`  // prices to restore
+	Applied   []PriceUpdate `json:"applied,omitempty"` // prices set by the change
+	CreatedAt Timestamp     `json:"created_at"`
+	ExpiresAt Timestamp     `json:"expires_at"`
+}
+
+// UndoInfo tells the client how to reverse an action
+type UndoInfo struct {
+	ActionID  string    `json:"action_id"`
+	ExpiresAt Timestamp `json:"expires_at"`
+}
+
+// UndoResponse represents the result of undoing an action. Skipped lists
+// products left alone because they changed again after the action.
+type UndoResponse struct {
+	Message string   `json:"message"`
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// UndoableResponse represents the result of an action that can be undone
+type UndoableResponse struct {
+	Message string    `json:"message"`
+	Undo    *UndoInfo `json:"undo,omitempty"`
+}
+
+// FieldChange records the before and after values of one changed field
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// ProductChange represents one audited update to a product
+type ProductChange struct {
+	ID        string        `json:"id"`
+	ProductID string        `json:"product_id"`
+	ChangedBy string        `json:"changed_by"`
+	Changes   []FieldChange `json:"changes"`
+	RequestID string        `json:"request_id,omitempty"`
+	CreatedAt Timestamp     `json:"created_at"`
+}
+
+// CatalogIssue represents a quality problem found in a product listing
+type CatalogIssue struct {
+	ProductID   string `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Code        string `json:"code"`
+	Field       string `json:"field"`
+	Severity    string `json:"severity"`
+	Message     string `json:"message"`
+	Suggestion  string `json:"suggestion"`
+}
+
+// CatalogIssuesResponse represents the result of linting a seller's catalog
+type CatalogIssuesResponse struct {
+	SellerID        string         `json:"seller_id"`
+	ProductsScanned int            `json:"products_scanned"`
+	Summary         map[string]int `json:"summary"`
+	Issues          []CatalogIssue `json:"issues"`
+	Truncated       bool           `json:"truncated,omitempty"` // issues were cut to the response size cap
+}
+
+// SalesVelocity represents per-SKU sales velocity aggregated from order events
+type SalesVelocity struct {
+	ProductID     string    `json:"product_id"`
+	ProductName   string    `json:"product_name"`
+	UnitsSold     int64     `json:"units_sold"`
+	WindowDays    int       `json:"window_days"`
+	CurrentStock  int32     `json:"current_stock"`
+	DailyVelocity float64   `json:"daily_velocity"`
+	ComputedAt    Timestamp `json:"computed_at"`
+}
+
+// InventoryForecast represents a stock projection for a single SKU
+type InventoryForecast struct {
+	ProductID            string   `json:"product_id"`
+	ProductName          string   `json:"product_name"`
+	CurrentStock         int32    `json:"current_stock"`
+	DailyVelocity        float64  `json:"daily_velocity"`
+	DaysOfStockRemaining *float64 `json:"days_of_stock_remaining"`
+	ReorderPoint         int32    `json:"reorder_point"`
+	SuggestedReorderQty  int32    `json:"suggested_reorder_quantity"`
+	ShouldReorder        bool     `json:"should_reorder"`
+}
+
+// InventoryForecastResponse represents a seller's inventory forecast
+type InventoryForecastResponse struct {
+	SellerID        string               `json:"seller_id"`
+	WindowDays      int                  `json:"window_days"`
+	LeadTimeDays    int                  `json:"lead_time_days"`
+	SafetyStockDays int                  `json:"safety_stock_days"`
+	Items           []*InventoryForecast `json:"items"`
+	Truncated       bool                 `json:"truncated,omitempty"` // items were cut to the response size cap
+}
+
+// SellerProfile is the business details of a seller, printed on invoices
+// for what they sold
+type SellerProfile struct {
+	SellerID string  `json:"seller_id"`
+	Name     string  `json:"name"`
+	Email    string  `json:"email,omitempty"`
+	TaxID    string  `json:"tax_id,omitempty"` // e.g. VAT or EIN
+	Address  Address `json:"address"`
+}
+
+// SellerVacation is a period during which a seller's products can't be
+// bought. It ends by itself at EndsAt.
+type SellerVacation struct {
+	SellerID  string    `json:"seller_id"`
+	StartsAt  Timestamp `json:"starts_at"`
+	EndsAt    Timestamp `json:"ends_at"`
+	Message   string    `json:"message,omitempty"`
+	Active    bool      `json:"active"`
+	UpdatedAt Timestamp `json:"updated_at"`
+}
+
+// SellerVacationRequest schedules a seller's vacation, starting now when
+// StartsAt is omitted
+type SellerVacationRequest struct {
+	StartsAt *Timestamp `json:"starts_at,omitempty"`
+	EndsAt   Timestamp  `json:"ends_at" binding:"required"`
+	Message  string     `json:"message,omitempty" binding:"max=500" normalize:"nfc,trim"`
 }
 
 // Order represents an order
 type Order struct {
-	ID             string      `json:"id"`
-	UserID         string      `json:"user_id"`
-	Items          []OrderItem `json:"items"`
-	Status         string      `json:"status"`
-	TotalAmount    float64     `json:"total_amount"`
-	ShippingAddr   Address     `json:"shipping_address"`
-	ReservationIDs []string    `json:"reservation_ids,omitempty"`
-	CreatedAt      time.Time   `json:"created_at"`
-	UpdatedAt      time.Time   `json:"updated_at"`
+	ID                string         `json:"id"`
+	UserID            string         `json:"user_id"`
+	Items             []OrderItem    `json:"items"`
+	Status            OrderStatus    `json:"status"`
+	TotalAmount       float64        `json:"total_amount"`
+	Currency          string         `json:"currency"` // the seller's, which every amount is in
+	ShippingAddr      Address        `json:"shipping_address"`
+	ReservationIDs    []string       `json:"reservation_ids,omitempty"`
+	SignatureRequired bool           `json:"signature_required"`
+	Payment           *PaymentIntent `json:"payment,omitempty"`
+	GuestEmail        string         `json:"guest_email,omitempty"`
+	// StoreCreditApplied and GiftCardApplied are the parts of the total
+	// paid with store credit and gift cards
+	StoreCreditApplied float64 `json:"store_credit_applied,omitempty"`
+	GiftCardApplied    float64 `json:"gift_card_applied,omitempty"`
+	// ShippingMethod is the shipping method the order goes by, and
+	// ShippingAmount what it costs, included in TotalAmount
+	ShippingMethod string  `json:"shipping_method,omitempty"`
+	ShippingAmount float64 `json:"shipping_amount,omitempty"`
+	// SellerID is the seller whose items the order holds, set on orders
+	// from a single seller and on sub-orders. An order from several sellers
+	// is split into SubOrders, one per seller, that each name it as their
+	// ParentOrderID.
+	SellerID      string   `json:"seller_id,omitempty"`
+	ParentOrderID string   `json:"parent_order_id,omitempty"`
+	SubOrders     []*Order `json:"sub_orders,omitempty"`
+	// CouponCode is the promo code redeemed on the order, and Discount what
+	// it took off the items' prices
+	CouponCode   string    `json:"coupon_code,omitempty"`
+	Discount     float64   `json:"discount,omitempty"`
+	FreeShipping bool      `json:"free_shipping,omitempty"`
+	TaxAmount    float64   `json:"tax_amount,omitempty"` // sales tax included in TotalAmount
+	CreatedAt    Timestamp `json:"created_at"`
+	UpdatedAt    Timestamp `json:"updated_at"`
 }
 
 // OrderItem represents an item in an order
@@ -100,21 +1064,161 @@ type OrderItem struct {
 	Quantity    int32   `json:"quantity"`
 	UnitPrice   float64 `json:"unit_price"`
 	TotalPrice  float64 `json:"total_price"`
+	Currency    string  `json:"currency"`
+	Tax         float64 `json:"tax,omitempty"` // sales tax on the line, charged on top of TotalPrice
+	// Status is where the item is in fulfillment, empty for items of orders
+	// placed before items had statuses
+	Status OrderItemStatus `json:"status,omitempty"`
 }
 
 // Address represents a shipping or billing address
 type Address struct {
-	Street     string `json:"street"`
-	City       string `json:"city"`
-	State      string `json:"state"`
-	PostalCode string `json:"postal_code"`
-	Country    string `json:"country"`
+	Street     string `json:"street" normalize:"nfc,trim,collapse"`
+	City       string `json:"city" normalize:"nfc,trim,collapse"`
+	State      string `json:"state" normalize:"nfc,trim,collapse"`
+	PostalCode string `json:"postal_code" normalize:"trim,collapse"`
+	Country    string `json:"country" normalize:"nfc,trim,collapse"`
+}
+
+// SavedAddress is a shipping address kept on a user's account
+type SavedAddress struct {
+	ID            string `json:"id"`
+	UserID        string `json:"user_id"`
+	Label         string `json:"label,omitempty"`
+	RecipientName string `json:"recipient_name,omitempty"`
+	Phone         string `json:"phone,omitempty"`
+	Address
+	IsDefault bool      `json:"is_default"`
+	CreatedAt Timestamp `json:"created_at"`
+	UpdatedAt Timestamp `json:"updated_at"`
+}
+
+// AddressRequest creates or replaces a saved address. Marking it the
+// default unmarks the user's previous default.
+type AddressRequest struct {
+	Label         string `json:"label" binding:"max=50" normalize:"nfc,trim,collapse"`
+	RecipientName string `json:"recipient_name" binding:"max=100" normalize:"nfc,trim,collapse"`
+	Phone         string `json:"phone" binding:"omitempty,e164" normalize:"trim"`
+	Street        string `json:"street" binding:"required,max=200" normalize:"nfc,trim,collapse"`
+	City          string `json:"city" binding:"required,max=100" normalize:"nfc,trim,collapse"`
+	State         string `json:"state" binding:"max=100" normalize:"nfc,trim,collapse"`
+	PostalCode    string `json:"postal_code" binding:"required,max=20" normalize:"trim,collapse,upper"`
+	Country       string `json:"country" binding:"required,iso3166_1_alpha2" normalize:"trim,upper"`
+	IsDefault     bool   `json:"is_default"`
 }
 
-// CreateOrderRequest represents a request to create an order
+// CreateOrderRequest represents a request to create an order, shipped to
+// either the given address or one of the user's saved addresses
 type CreateOrderRequest struct {
-	Items        []CreateOrderItem `json:"items" binding:"required,min=1,dive"`
-	ShippingAddr Address           `json:"shipping_address" binding:"required"`
+	Items           []CreateOrderItem `json:"items" binding:"required,min=1,dive"`
+	ShippingAddr    *Address          `json:"shipping_address,omitempty" binding:"required_without=AddressID,excluded_with=AddressID"`
+	AddressID       string            `json:"address_id,omitempty"`
+	AgeVerification *AgeVerification  `json:"age_verification,omitempty"`
+	CouponCode      string            `json:"coupon_code,omitempty" binding:"max=50" normalize:"trim,upper"`
+	// ShippingMethod is the ID of a quoted shipping method; the cheapest is
+	// used when omitted
+	ShippingMethod string `json:"shipping_method,omitempty" binding:"max=64" normalize:"trim"`
+	// GuestEmail is the verified email of a guest placing the order, set by
+	// the gateway rather than the client
+	GuestEmail string `json:"-"`
+	// Discount and FreeShipping are what the coupon gives the order, set by
+	// the gateway once it has checked the code
+	Discount     float64 `json:"-"`
+	FreeShipping bool    `json:"-"`
+	// ItemTaxes are the sales tax on each item, in the same order, and Tax
+	// their sum, set by the gateway once it has calculated them
+	ItemTaxes []float64 `json:"-"`
+	Tax       float64   `json:"-"`
+	// ShippingCost is what the shipping method costs, set by the gateway
+	// once it has quoted it
+	ShippingCost float64 `json:"-"`
+	// Currency is the sellers' currency the order is settled in, set by the
+	// gateway from its products
+	Currency string `json:"-"`
+	// SellerID is the seller of every item, set by the gateway when there
+	// is only one; orders from several sellers are split instead
+	SellerID string `json:"-"`
+}
+
+// CheckoutRequest places an order for the items in the user's cart, shipped
+// to the given or saved address and paid for with the payment method if one
+// is given
+type CheckoutRequest struct {
+	ShippingAddr    *Address         `json:"shipping_address,omitempty" binding:"required_without=AddressID,excluded_with=AddressID"`
+	AddressID       string           `json:"address_id,omitempty"`
+	AgeVerification *AgeVerification `json:"age_verification,omitempty"`
+	PaymentMethodID string           `json:"payment_method_id,omitempty"`
+	// SavedPaymentMethodID pays with one of the user's saved payment
+	// methods instead of a provider payment method
+	SavedPaymentMethodID string `json:"saved_payment_method_id,omitempty" binding:"excluded_with=PaymentMethodID"`
+	// ApplyStoreCredit pays what it can of the order with the user's store
+	// credit; when omitted, the configured default applies
+	ApplyStoreCredit *bool `json:"apply_store_credit,omitempty"`
+	// CouponCode redeems a promo code in place of the one applied to the
+	// cart, if any
+	CouponCode string `json:"coupon_code,omitempty" binding:"max=50" normalize:"trim,upper"`
+	// GiftCardCodes pay what they can of the order in the order given,
+	// before store credit and the payment method
+	GiftCardCodes []string `json:"gift_card_codes,omitempty" binding:"max=5,unique,dive,required,max=64" normalize:"trim,upper"`
+	// ShippingMethod is the ID of a quoted shipping method; the cheapest is
+	// used when omitted
+	ShippingMethod string `json:"shipping_method,omitempty" binding:"max=64" normalize:"trim"`
+}
+
+// ShippingQuoteRequest asks for the shipping methods available for the
+// caller's cart to the given or saved address
+type ShippingQuoteRequest struct {
+	ShippingAddr *Address `json:"shipping_address,omitempty" binding:"required_without=AddressID,excluded_with=AddressID"`
+	AddressID    string   `json:"address_id,omitempty"`
+}
+
+// ShippingQuote is a shipping method available for an order, what it costs,
+// and the dates it is expected to arrive between
+type ShippingQuote struct {
+	Method           string  `json:"method"`
+	Carrier          string  `json:"carrier"`
+	Name             string  `json:"name"`
+	Price            float64 `json:"price"`
+	Currency         string  `json:"currency"`
+	EarliestDelivery string  `json:"earliest_delivery"` // YYYY-MM-DD
+	LatestDelivery   string  `json:"latest_delivery"`
+}
+
+// ShippingQuotesResponse lists the shipping methods available for a cart,
+// cheapest first
+type ShippingQuotesResponse struct {
+	Quotes       []ShippingQuote `json:"quotes"`
+	DispatchDate string          `json:"dispatch_date,omitempty"` // when the delivery estimates count from
+}
+
+// GuestVerificationRequest asks for a code to verify a guest's email
+type GuestVerificationRequest struct {
+	Email string `json:"email" binding:"required,email" normalize:"trim,lower"`
+}
+
+// GuestConfirmRequest confirms a guest's email with the code sent to it
+type GuestConfirmRequest struct {
+	Email string `json:"email" binding:"required,email" normalize:"trim,lower"`
+	Code  string `json:"code" binding:"required,len=6,numeric"`
+}
+
+// GuestSession is a guest's verified email and the token to check out with
+type GuestSession struct {
+	Token     string    `json:"guest_token"`
+	Email     string    `json:"email"`
+	ExpiresAt Timestamp `json:"expires_at"`
+}
+
+// ClaimOrdersResponse lists the guest orders attached to a user's account
+type ClaimOrdersResponse struct {
+	Claimed int      `json:"claimed"`
+	Orders  []*Order `json:"orders"`
+}
+
+// AgeVerification carries the customer's age details for orders containing restricted items
+type AgeVerification struct {
+	DateOfBirth string `json:"date_of_birth" binding:"required"`
+	IDToken     string `json:"id_verification_token,omitempty"`
 }
 
 // CreateOrderItem represents an item in a create order request
@@ -123,9 +1227,587 @@ type CreateOrderItem struct {
 	Quantity  int32  `json:"quantity" binding:"required,gt=0"`
 }
 
-// UpdateOrderStatusRequest represents a request to update order status
+// POSOrderRequest is an order taken at an in-store kiosk or till, possibly
+// while it was offline. LocalID is the ID the device queued it under, so the
+// same order can be submitted again safely.
+type POSOrderRequest struct {
+	LocalID       string         `json:"local_id" binding:"required,max=64" normalize:"trim"`
+	Items         []POSOrderItem `json:"items" binding:"required,min=1,max=100,dive"`
+	Payment       POSPayment     `json:"payment"`
+	CustomerEmail string         `json:"customer_email,omitempty" binding:"omitempty,email,max=254" normalize:"trim,lower"`
+	// TakenAt is when the customer placed the order at the device
+	TakenAt Timestamp `json:"taken_at" binding:"required"`
+}
+
+// POSOrderItem is a line of a POS order at the price the device charged
+type POSOrderItem struct {
+	ProductID string  `json:"product_id" binding:"required"`
+	Quantity  int32   `json:"quantity" binding:"required,gt=0"`
+	UnitPrice float64 `json:"unit_price" binding:"gte=0"`
+}
+
+// POSPayment is how a POS order was paid for at the device
+type POSPayment struct {
+	Method POSPaymentMethod `json:"method" binding:"required"`
+	Amount float64          `json:"amount" binding:"gte=0"`
+	// Reference is the card terminal's transaction reference
+	Reference string `json:"reference,omitempty" binding:"max=128" normalize:"trim"`
+}
+
+// POSOrder is the state of an order submitted by an in-store device. It is
+// accepted_pending from the moment the gateway has recorded it, which is
+// when the device can drop it from its local queue, until the order service
+// places or rejects it.
+type POSOrder struct {
+	LocalID  string         `json:"local_id"`
+	DeviceID string         `json:"device_id"`
+	StoreID  string         `json:"store_id"`
+	Status   POSOrderStatus `json:"status"`
+	OrderID  string         `json:"order_id,omitempty"`
+	// Error is why the order was rejected, or while it is pending why the
+	// last attempt to place it failed
+	Error      string     `json:"error,omitempty"`
+	Attempts   int        `json:"attempts"`
+	TakenAt    Timestamp  `json:"taken_at"`
+	AcceptedAt Timestamp  `json:"accepted_at"`
+	PlacedAt   *Timestamp `json:"placed_at,omitempty"`
+	// Replayed is set when the order had already been submitted
+	Replayed bool `json:"replayed,omitempty"`
+}
+
+// POSReconcileRequest resubmits the orders a device queued while offline
+type POSReconcileRequest struct {
+	Orders []POSOrderRequest `json:"orders" binding:"required,min=1,max=100,dive"`
+}
+
+// POSReconcileResult is the outcome of one resubmitted order, with an error
+// in place of the order if it could not be accepted
+type POSReconcileResult struct {
+	LocalID string    `json:"local_id"`
+	Order   *POSOrder `json:"order,omitempty"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// POSReconcileResponse lists the outcome of each resubmitted order, in the
+// order given
+type POSReconcileResponse struct {
+	Results []POSReconcileResult `json:"results"`
+}
+
+// SellerOrderStatusRequest moves a seller's order along as they fulfill it
+type SellerOrderStatusRequest struct {
+	Status OrderStatus `json:"status" binding:"required,oneof=processing shipped delivered"`
+}
+
+// UpdateOrderStatusRequest represents a request to update order status. With
+// items, which only fulfillment may send, it moves those items along and the
+// status may be left out to have it follow from theirs.
 type UpdateOrderStatusRequest struct {
-	Status string `json:"status" binding:"required,oneof=pending confirmed processing shipped delivered cancelled"`
+	Status OrderStatus             `json:"status" binding:"required_without=Items"`
+	Items  []OrderItemStatusUpdate `json:"items" binding:"omitempty,max=100,dive"`
+}
+
+// OrderItemStatusUpdate moves an item of an order to a new status
+type OrderItemStatusUpdate struct {
+	ProductID string          `json:"product_id" binding:"required"`
+	Status    OrderItemStatus `json:"status" binding:"required"`
+}
+
+// Payment intent statuses
+const (
+	PaymentStatusRequiresConfirmation = "requires_confirmation"
+	PaymentStatusProcessing           = "processing"
+	PaymentStatusSucceeded            = "succeeded"
+	PaymentStatusFailed               = "failed"
+	PaymentStatusCanceled             = "canceled"
+	PaymentStatusRefunded             = "refunded"
+)
+
+// PaymentIntent is a payment of an order's total, created before the
+// customer's payment method is charged
+type PaymentIntent struct {
+	ID              string     `json:"id"`
+	OrderID         string     `json:"order_id"`
+	UserID          string     `json:"user_id"`
+	Amount          float64    `json:"amount"`
+	Currency        string     `json:"currency"`
+	Status          string     `json:"status"`
+	PaymentMethodID string     `json:"payment_method_id,omitempty"`
+	FailureReason   string     `json:"failure_reason,omitempty"`
+	CreatedAt       Timestamp  `json:"created_at"`
+	ConfirmedAt     *Timestamp `json:"confirmed_at,omitempty"`
+}
+
+// Payment webhook event types
+const (
+	PaymentEventSucceeded       = "payment_succeeded"
+	PaymentEventFailed          = "payment_failed"
+	PaymentEventRefundCompleted = "refund_completed"
+)
+
+// PaymentWebhookEvent is a notification from the payment provider
+type PaymentWebhookEvent struct {
+	ID   string                  `json:"id" binding:"required"`
+	Type string                  `json:"type" binding:"required"`
+	Data PaymentWebhookEventData `json:"data"`
+}
+
+// PaymentWebhookEventData identifies the payment an event is about
+type PaymentWebhookEventData struct {
+	PaymentIntentID string  `json:"payment_intent_id"`
+	OrderID         string  `json:"order_id" binding:"required"`
+	UserID          string  `json:"user_id" binding:"required"`
+	Amount          float64 `json:"amount"`
+}
+
+// Shipment is a parcel of an order handed to a carrier, with the tracking
+// events the carrier has reported for it, oldest first
+type Shipment struct {
+	ID                string          `json:"id"`
+	OrderID           string          `json:"order_id"`
+	UserID            string          `json:"-"` // the order's owner, for updating it
+	Carrier           string          `json:"carrier"`
+	TrackingNumber    string          `json:"tracking_number"`
+	Status            ShipmentStatus  `json:"status"`
+	EstimatedDelivery string          `json:"estimated_delivery,omitempty"` // YYYY-MM-DD
+	Events            []TrackingEvent `json:"events"`
+	UpdatedAt         Timestamp       `json:"updated_at"`
+}
+
+// TrackingEvent is a scan or status change reported by a carrier
+type TrackingEvent struct {
+	Status      ShipmentStatus `json:"status"`
+	Description string         `json:"description,omitempty"`
+	Location    string         `json:"location,omitempty"`
+	OccurredAt  Timestamp      `json:"occurred_at"`
+}
+
+// OrderTracking is an order's status with the tracking of every shipment
+// it was sent in
+type OrderTracking struct {
+	OrderID   string      `json:"order_id"`
+	Status    OrderStatus `json:"status"`
+	Shipments []*Shipment `json:"shipments"`
+}
+
+// CarrierWebhookEvent is a tracking update pushed by a carrier
+type CarrierWebhookEvent struct {
+	ID                string         `json:"id" binding:"required"`
+	Carrier           string         `json:"carrier" binding:"required"`
+	TrackingNumber    string         `json:"tracking_number" binding:"required"`
+	Status            ShipmentStatus `json:"status" binding:"required"`
+	Description       string         `json:"description,omitempty" binding:"max=500"`
+	Location          string         `json:"location,omitempty" binding:"max=200"`
+	OccurredAt        Timestamp      `json:"occurred_at" binding:"required"`
+	EstimatedDelivery string         `json:"estimated_delivery,omitempty" binding:"omitempty,datetime=2006-01-02"`
+}
+
+// CreatePaymentIntentRequest starts a payment for an order. The amount is
+// always the order's total.
+type CreatePaymentIntentRequest struct {
+	OrderID string `json:"order_id" binding:"required"`
+}
+
+// ConfirmPaymentRequest charges a payment intent to a payment method
+type ConfirmPaymentRequest struct {
+	PaymentMethodID      string `json:"payment_method_id,omitempty" binding:"required_without=SavedPaymentMethodID,excluded_with=SavedPaymentMethodID"`
+	SavedPaymentMethodID string `json:"saved_payment_method_id,omitempty"`
+}
+
+// Reasons store credit is issued
+const (
+	StoreCreditReasonRefund   = "refund"
+	StoreCreditReasonGoodwill = "goodwill"
+)
+
+// StoreCreditBalance is a user's spendable store credit
+type StoreCreditBalance struct {
+	UserID    string    `json:"user_id"`
+	Balance   float64   `json:"balance"`
+	Currency  string    `json:"currency"`
+	UpdatedAt Timestamp `json:"updated_at"`
+}
+
+// StoreCreditBalances is a user's store credit, one balance per currency
+type StoreCreditBalances struct {
+	Balances []*StoreCreditBalance `json:"balances"`
+}
+
+// StoreCredit is an amount of store credit issued to a user
+type StoreCredit struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Amount    float64   `json:"amount"`
+	Currency  string    `json:"currency"`
+	Reason    string    `json:"reason"`
+	ReturnID  string    `json:"return_id,omitempty"`
+	Note      string    `json:"note,omitempty"`
+	IssuedBy  string    `json:"issued_by"`
+	CreatedAt Timestamp `json:"created_at"`
+}
+
+// IssueStoreCreditRequest issues store credit to a user, as goodwill or as
+// the refund of a return. Refunds default to the return's refund amount.
+type IssueStoreCreditRequest struct {
+	Amount   float64 `json:"amount" binding:"required_if=Reason goodwill,omitempty,gt=0"`
+	Reason   string  `json:"reason" binding:"required,oneof=refund goodwill"`
+	ReturnID string  `json:"return_id" binding:"required_if=Reason refund,excluded_if=Reason goodwill"`
+	Note     string  `json:"note" binding:"max=500" normalize:"nfc,trim"`
+}
+
+// StoreCreditHold is store credit set aside for an order until the order is
+// placed, when it is captured, or fails, when it is released
+type StoreCreditHold struct {
+	ID      string  `json:"id"`
+	UserID  string  `json:"user_id"`
+	OrderID string  `json:"order_id"`
+	Amount  float64 `json:"amount"`
+}
+
+// Gift card statuses
+const (
+	GiftCardStatusPending   = "pending" // purchased, payment not yet taken
+	GiftCardStatusActive    = "active"
+	GiftCardStatusCancelled = "cancelled"
+)
+
+// GiftCard is a prepaid balance redeemed at checkout with its code
+type GiftCard struct {
+	ID string `json:"id"`
+	// Code is the secret redeemed at checkout. It is only ever sent to the
+	// recipient, never returned by the API.
+	Code           string     `json:"-"`
+	Last4          string     `json:"last4"`
+	InitialAmount  float64    `json:"initial_amount"`
+	Balance        float64    `json:"balance"`
+	Currency       string     `json:"currency"`
+	Status         string     `json:"status"`
+	PurchaserID    string     `json:"purchaser_id,omitempty"`
+	RecipientEmail string     `json:"recipient_email,omitempty"`
+	RecipientName  string     `json:"recipient_name,omitempty"`
+	Message        string     `json:"message,omitempty"`
+	ExpiresAt      *Timestamp `json:"expires_at,omitempty"`
+	CreatedAt      Timestamp  `json:"created_at"`
+}
+
+// PurchaseGiftCardRequest buys a gift card for a recipient, paid for with a
+// payment method or one of the user's saved payment methods
+type PurchaseGiftCardRequest struct {
+	Amount               float64 `json:"amount" binding:"required,gt=0"`
+	RecipientEmail       string  `json:"recipient_email" binding:"required,email" normalize:"trim,lower"`
+	RecipientName        string  `json:"recipient_name" binding:"max=100" normalize:"nfc,trim,collapse"`
+	Message              string  `json:"message" binding:"max=500" normalize:"nfc,trim"`
+	PaymentMethodID      string  `json:"payment_method_id,omitempty" binding:"required_without=SavedPaymentMethodID"`
+	SavedPaymentMethodID string  `json:"saved_payment_method_id,omitempty" binding:"excluded_with=PaymentMethodID"`
+}
+
+// GiftCardBalanceRequest looks up a gift card's balance by its code
+type GiftCardBalanceRequest struct {
+	Code string `json:"code" binding:"required,max=64" normalize:"trim,upper"`
+}
+
+// GiftCardBalance is what is left on a gift card
+type GiftCardBalance struct {
+	Last4     string     `json:"last4"`
+	Balance   float64    `json:"balance"`
+	Currency  string     `json:"currency"`
+	Status    string     `json:"status"`
+	ExpiresAt *Timestamp `json:"expires_at,omitempty"`
+}
+
+// GiftCardHold is gift card balance set aside for an order until the order
+// is placed, when it is captured, or fails, when it is released
+type GiftCardHold struct {
+	ID         string  `json:"id"`
+	GiftCardID string  `json:"gift_card_id"`
+	OrderID    string  `json:"order_id"`
+	Amount     float64 `json:"amount"`
+}
+
+// SavedPaymentMethod is a payment method kept on a user's account. Only the
+// payment provider's token for it is stored, never the card number.
+type SavedPaymentMethod struct {
+	ID               string    `json:"id"`
+	UserID           string    `json:"user_id"`
+	ProviderMethodID string    `json:"-"`
+	Type             string    `json:"type"`
+	Brand            string    `json:"brand,omitempty"`
+	Last4            string    `json:"last4,omitempty"`
+	ExpMonth         int       `json:"exp_month,omitempty"`
+	ExpYear          int       `json:"exp_year,omitempty"`
+	IsDefault        bool      `json:"is_default"`
+	CreatedAt        Timestamp `json:"created_at"`
+}
+
+// SavePaymentMethodRequest saves a payment method the client tokenized with
+// the payment provider
+type SavePaymentMethodRequest struct {
+	Token      string `json:"token" binding:"required,max=255" normalize:"trim"`
+	SetDefault bool   `json:"set_default"`
+}
+
+// Return statuses
+const (
+	ReturnStatusRequested = "requested"
+	ReturnStatusApproved  = "approved"
+	ReturnStatusRejected  = "rejected"
+	ReturnStatusCompleted = "completed"
+)
+
+// Refund statuses
+const (
+	RefundStatusSucceeded = "succeeded"
+	RefundStatusFailed    = "failed"
+)
+
+// Where a refund's money goes
+const (
+	RefundMethodOriginalPayment = "original_payment"
+	RefundMethodStoreCredit     = "store_credit"
+)
+
+// Return is a customer's request to send back items from an order
+type Return struct {
+	ID           string       `json:"id"`
+	OrderID      string       `json:"order_id"`
+	UserID       string       `json:"user_id"`
+	Items        []ReturnItem `json:"items"`
+	Reason       string       `json:"reason,omitempty"`
+	Status       string       `json:"status"`
+	RefundAmount float64      `json:"refund_amount"`
+	Refund       *Refund      `json:"refund,omitempty"`
+	ReviewedBy   string       `json:"reviewed_by,omitempty"`
+	ReviewNote   string       `json:"review_note,omitempty"`
+	CreatedAt    Timestamp    `json:"created_at"`
+	ReviewedAt   *Timestamp   `json:"reviewed_at,omitempty"`
+	CompletedAt  *Timestamp   `json:"completed_at,omitempty"`
+}
+
+// ReturnItem is a quantity of one order item being returned
+type ReturnItem struct {
+	ProductID string `json:"product_id" binding:"required"`
+	Quantity  int32  `json:"quantity" binding:"required,gt=0"`
+}
+
+// Refund is money returned to the customer's payment method for a return
+type Refund struct {
+	ID              string    `json:"id"`
+	ReturnID        string    `json:"return_id"`
+	PaymentIntentID string    `json:"payment_intent_id,omitempty"`
+	StoreCreditID   string    `json:"store_credit_id,omitempty"`
+	Method          string    `json:"method"`
+	Amount          float64   `json:"amount"`
+	Currency        string    `json:"currency"`
+	Status          string    `json:"status"`
+	FailureReason   string    `json:"failure_reason,omitempty"`
+	CreatedAt       Timestamp `json:"created_at"`
+}
+
+// CreateReturnRequest represents a request to return items from an order
+type CreateReturnRequest struct {
+	Items  []ReturnItem `json:"items" binding:"required,min=1,dive"`
+	Reason string       `json:"reason" binding:"max=1000"`
+}
+
+// ReviewReturnRequest records the reviewer's note on approving or rejecting a return
+type ReviewReturnRequest struct {
+	Note string `json:"note" binding:"max=1000"`
+}
+
+// Cart is a shopping cart belonging to a user or a guest session. Prices are
+// snapshotted when items are added, so totals do not move under the customer.
+type Cart struct {
+	UserID    string     `json:"user_id,omitempty"`
+	SessionID string     `json:"session_id,omitempty"`
+	Items     []CartItem `json:"items"`
+	ItemCount int        `json:"item_count"`
+	Subtotal  float64    `json:"subtotal"`
+	// Coupon is the promo code applied to the cart, and Discount what it
+	// takes off the subtotal
+	Coupon    *AppliedCoupon `json:"coupon,omitempty"`
+	Discount  float64        `json:"discount,omitempty"`
+	Total     float64        `json:"total"`
+	UpdatedAt *Timestamp     `json:"updated_at,omitempty"`
+	ExpiresAt *Timestamp     `json:"expires_at,omitempty"`
+
+	// Dispatch is when the whole cart would be dispatched if ordered now
+	Dispatch *DispatchPromise `json:"dispatch,omitempty"`
+}
+
+// CartItem is a product in a cart at the price it was added at
+type CartItem struct {
+	ProductID   string    `json:"product_id"`
+	ProductName string    `json:"product_name"`
+	SellerID    string    `json:"seller_id,omitempty"`
+	Quantity    int32     `json:"quantity"`
+	UnitPrice   float64   `json:"unit_price"`
+	LineTotal   float64   `json:"line_total"`
+	AddedAt     Timestamp `json:"added_at"`
+}
+
+// AddCartItemRequest adds a product to the cart, or more of one already in it
+type AddCartItemRequest struct {
+	ProductID string `json:"product_id" binding:"required"`
+	Quantity  int32  `json:"quantity" binding:"required,gt=0"`
+}
+
+// UpdateCartItemRequest sets the quantity of a product in the cart; zero
+// removes it
+type UpdateCartItemRequest struct {
+	Quantity *int32 `json:"quantity" binding:"required,gte=0"`
+}
+
+// Coupon types
+const (
+	CouponTypePercentage   = "percentage"    // Value percent off the subtotal
+	CouponTypeFixedAmount  = "fixed_amount"  // Value off the subtotal
+	CouponTypeFreeShipping = "free_shipping" // no shipping charge
+)
+
+// Coupon is a promo code and the rules for redeeming it
+type Coupon struct {
+	Code  string  `json:"code"`
+	Type  string  `json:"type"`
+	Value float64 `json:"value,omitempty"`
+	// MinSubtotal is the smallest subtotal the code applies to
+	MinSubtotal float64 `json:"min_subtotal,omitempty"`
+	// MaxRedemptions caps redemptions by all customers, and PerUserLimit
+	// those by each customer; zero is unlimited
+	MaxRedemptions int `json:"max_redemptions,omitempty"`
+	PerUserLimit   int `json:"per_user_limit,omitempty"`
+	Redemptions    int `json:"redemptions"`
+	// UserRedemptions counts the redemptions by the customer the coupon was
+	// fetched for
+	UserRedemptions int `json:"user_redemptions"`
+	// Segments limits the code to customers in one of the segments
+	Segments  []string   `json:"segments,omitempty"`
+	StartsAt  *Timestamp `json:"starts_at,omitempty"`
+	ExpiresAt *Timestamp `json:"expires_at,omitempty"`
+}
+
+// AppliedCoupon is a promo code applied to a cart
+type AppliedCoupon struct {
+	Code  string  `json:"code"`
+	Type  string  `json:"type"`
+	Value float64 `json:"value,omitempty"`
+}
+
+// CouponRequest names a promo code to check or apply to the cart
+type CouponRequest struct {
+	Code string `json:"code" binding:"required,max=50" normalize:"trim,upper"`
+}
+
+// CouponQuote is what a promo code would take off the cart
+type CouponQuote struct {
+	Code         string     `json:"code"`
+	Type         string     `json:"type"`
+	Discount     float64    `json:"discount"`
+	FreeShipping bool       `json:"free_shipping,omitempty"`
+	Subtotal     float64    `json:"subtotal"`
+	Total        float64    `json:"total"`
+	ExpiresAt    *Timestamp `json:"expires_at,omitempty"`
+}
+
+// TokenPair represents an access token and refresh token issued by the user service
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"` // in seconds
+}
+
+// ClientCredentialsToken represents an access token issued to a machine
+// client with the OAuth2 client credentials grant
+type ClientCredentialsToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"` // in seconds
+	Scope       string `json:"scope"`
+}
+
+// POSTokenRequest authenticates an in-store kiosk or till with its device
+// credentials
+type POSTokenRequest struct {
+	DeviceID     string `json:"device_id" binding:"required,max=64" normalize:"trim"`
+	DeviceSecret string `json:"device_secret" binding:"required"`
+}
+
+// POSToken is a short-lived access token for the POS API, issued to one
+// device of a store
+type POSToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"` // in seconds
+	DeviceID    string `json:"device_id"`
+	StoreID     string `json:"store_id"`
+}
+
+// RefreshTokenRequest represents a request to exchange a refresh token
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutRequest represents a request to log out
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RevokeTokenRequest represents an admin request to revoke an access token.
+// ExpiresAt defaults to the longest token lifetime.
+type RevokeTokenRequest struct {
+	TokenID   string     `json:"token_id" binding:"required"`
+	ExpiresAt *Timestamp `json:"expires_at"`
+}
+
+// APIKey represents a server-to-server API key. Only the hash of the key's
+// secret is stored; the plaintext is returned once at issue or rotation.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	OwnerID    string     `json:"owner_id"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	Roles      []string   `json:"roles"`
+	Tier       string     `json:"tier,omitempty"` // SLA tier, set by admins
+	CreatedAt  Timestamp  `json:"created_at"`
+	ExpiresAt  *Timestamp `json:"expires_at,omitempty"`
+	LastUsedAt *Timestamp `json:"last_used_at,omitempty"`
+	RevokedAt  *Timestamp `json:"revoked_at,omitempty"`
+}
+
+// IssuedAPIKey represents a newly issued or rotated API key with its plaintext secret
+type IssuedAPIKey struct {
+	*APIKey
+	Key string `json:"key"`
+}
+
+// CreateAPIKeyRequest represents a request to issue an API key
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required,min=1,max=100" normalize:"nfc,trim,collapse"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1,dive,required"`
+	ExpiresAt *Timestamp `json:"expires_at,omitempty"`
+}
+
+// UpdateAPIKeyRequest represents a request to update an API key
+type UpdateAPIKeyRequest struct {
+	Name   *string   `json:"name,omitempty" binding:"omitempty,min=1,max=100" normalize:"nfc,trim,collapse"`
+	Scopes *[]string `json:"scopes,omitempty" binding:"omitempty,min=1,dive,required"`
+}
+
+// SetAPIKeyTierRequest assigns an API key to an SLA tier; an empty tier
+// returns it to the default
+type SetAPIKeyTierRequest struct {
+	Tier string `json:"tier" normalize:"trim"`
+}
+
+// OIDCIdentity represents a user identity asserted by an OIDC provider
+type OIDCIdentity struct {
+	Provider      string `json:"provider"`
+	Subject       string `json:"subject"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
 }
 
 // User represents a user
@@ -134,5 +1816,27 @@ type User struct {
 	Email     string    `json:"email"`
 	Name      string    `json:"name"`
 	Role      string    `json:"role"`
-	CreatedAt time.Time `json:"created_at"`
+	Timezone  string    `json:"timezone,omitempty"`
+	CreatedAt Timestamp `json:"created_at"`
+}
+
+// DeprecatedSurface describes a deprecated route, field, or API version and
+// how much the caller still uses it
+type DeprecatedSurface struct {
+	Surface    string     `json:"surface"`
+	Kind       string     `json:"kind"` // route or field
+	Route      string     `json:"route,omitempty"`
+	Field      string     `json:"field,omitempty"`
+	In         string     `json:"in,omitempty"`
+	Since      Timestamp  `json:"since"`
+	Sunset     *Timestamp `json:"sunset,omitempty"`
+	Successor  string     `json:"successor,omitempty"`
+	Calls      int64      `json:"calls"`
+	LastCallAt *Timestamp `json:"last_call_at,omitempty"`
+}
+
+// DeprecationReport lists deprecated surfaces with the caller's usage of each
+type DeprecationReport struct {
+	Consumer     string               `json:"consumer,omitempty"`
+	Deprecations []*DeprecatedSurface `json:"deprecations"`
 }
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// TimestampLayout is the format of every timestamp in API responses: RFC 3339
+// in UTC, to the second
+const TimestampLayout = time.RFC3339
+
+// timestampInputLayouts are the formats accepted in requests. Times must
+// carry a UTC offset unless they are a bare date, which means midnight UTC.
+var timestampInputLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02",
+}
+
+// Timestamp is a point in time that is always written as RFC 3339 UTC and
+// accepts several common formats when read
+type Timestamp struct {
+	time.Time
+}
+
+// NewTimestamp converts t to a Timestamp in UTC
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp{t.UTC()}
+}
+
+// Now returns the current time as a Timestamp
+func Now() Timestamp {
+	return NewTimestamp(time.Now())
+}
+
+// TimestampPtr returns a pointer to t as a Timestamp
+func TimestampPtr(t time.Time) *Timestamp {
+	ts := NewTimestamp(t)
+	return &ts
+}
+
+// ParseTimestamp parses an RFC 3339 time, an RFC 3339 time with a space
+// instead of T or an offset without a colon, or a bare date
+func ParseTimestamp(s string) (Timestamp, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timestampInputLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return NewTimestamp(t), nil
+		}
+	}
+	return Timestamp{}, fmt.Errorf("invalid timestamp %q: use RFC 3339 with a UTC offset, e.g. 2024-01-02T15:04:05Z, or a date such as 2024-01-02", s)
+}
+
+// String formats the timestamp as RFC 3339 UTC
+func (t Timestamp) String() string {
+	return t.UTC().Format(TimestampLayout)
+}
+
+// MarshalJSON writes the timestamp as an RFC 3339 UTC string
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if y := t.UTC().Year(); y < 0 || y > 9999 {
+		return nil, fmt.Errorf("timestamp year %d out of range", y)
+	}
+	return []byte(`"` + t.String() + `"`), nil
+}
+
+// UnmarshalJSON reads a timestamp string in any accepted format, or a
+// number of Unix seconds
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] != '"' {
+		seconds, err := strconv.ParseInt(string(data), 10, 64)
+		if err != nil || seconds < 0 || seconds > 253402300799 {
+			return fmt.Errorf("invalid timestamp %s: Unix seconds must be a non-negative integer", data)
+		}
+		*t = NewTimestamp(time.Unix(seconds, 0))
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := ParseTimestamp(s)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// MarshalText writes the timestamp as RFC 3339 UTC
+func (t Timestamp) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText reads a timestamp in any accepted format
+func (t *Timestamp) UnmarshalText(text []byte) error {
+	parsed, err := ParseTimestamp(string(text))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// RegisterValidators lets validation tags such as required apply to
+// Timestamp fields as they do to time.Time
+func RegisterValidators(v *validator.Validate) {
+	v.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		return field.Interface().(Timestamp).Time
+	}, Timestamp{})
+}
@@ -0,0 +1,91 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ecommerce/be-api-gin/internal/logging"
+)
+
+// Image moderation categories reported by providers
+const (
+	CategoryNudity    = "nudity"
+	CategoryViolence  = "violence"
+	CategoryTrademark = "trademark"
+)
+
+// ImageProvider classifies images with an automated moderation service,
+// returning a score from 0 to 1 per category (e.g. nudity, violence,
+// trademark)
+type ImageProvider interface {
+	ClassifyImage(ctx context.Context, data []byte, contentType string) (map[string]float64, error)
+}
+
+// ScreenImage checks an uploaded image with the image provider. Images are
+// approved when no provider is configured and quarantined if the provider fails.
+func (p *Pipeline) ScreenImage(ctx context.Context, data []byte, contentType string) Decision {
+	decision := Decision{Verdict: VerdictApproved}
+	if p.imageProvider == nil {
+		return decision
+	}
+
+	scores, err := p.imageProvider.ClassifyImage(ctx, data, contentType)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Image moderation provider failed, quarantining image", "error", err)
+		decision.escalate(VerdictQuarantined, "automated image moderation unavailable")
+		return decision
+	}
+
+	for category, score := range scores {
+		switch {
+		case score >= p.rejectThreshold:
+			decision.escalate(VerdictRejected, fmt.Sprintf("image scored %.2f for %s", score, category))
+		case score >= p.quarantineThreshold:
+			decision.escalate(VerdictQuarantined, fmt.Sprintf("image scored %.2f for %s", score, category))
+		}
+	}
+	return decision
+}
+
+// ClassifyImage sends a base64-encoded image to the provider and returns its
+// category scores
+func (p *HTTPProvider) ClassifyImage(ctx context.Context, data []byte, contentType string) (map[string]float64, error) {
+	body, err := json.Marshal(map[string]string{
+		"image":        base64.StdEncoding.EncodeToString(data),
+		"content_type": contentType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("image moderation provider returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Scores map[string]float64 `json:"scores"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.Scores, nil
+}
@@ -0,0 +1,267 @@
+package moderation
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+)
+
+// Moderation verdicts
+const (
+	VerdictApproved    = "approved"
+	VerdictQuarantined = "quarantined"
+	VerdictRejected    = "rejected"
+)
+
+// defaultBlockedTerms are rejected outright
+var defaultBlockedTerms = []string{
+	"counterfeit",
+	"stolen goods",
+	"fake id",
+}
+
+// defaultFlaggedTerms are quarantined for human review
+var defaultFlaggedTerms = []string{
+	"replica",
+	"knockoff",
+	"prescription",
+	"firearm",
+	"ammunition",
+}
+
+// Decision is the outcome of screening a piece of content
+type Decision struct {
+	Verdict string   `json:"verdict"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// Provider classifies text with a machine-learning moderation service,
+// returning a score from 0 to 1 per category (e.g. "toxicity", "sexual")
+type Provider interface {
+	Classify(ctx context.Context, text string) (map[string]float64, error)
+}
+
+// Pipeline screens content against wordlists and, when configured, an ML
+// provider. Wordlist matches are applied first; the provider can only make
+// the verdict stricter.
+type Pipeline struct {
+	blocked             []string
+	flagged             []string
+	provider            Provider
+	imageProvider       ImageProvider
+	rejectThreshold     float64
+	quarantineThreshold float64
+}
+
+// NewPipeline creates the moderation pipeline configured for the application
+func NewPipeline(cfg *config.Config) *Pipeline {
+	p := &Pipeline{
+		blocked:             defaultBlockedTerms,
+		flagged:             defaultFlaggedTerms,
+		rejectThreshold:     cfg.ModerationRejectThreshold,
+		quarantineThreshold: cfg.ModerationQuarantineThreshold,
+	}
+
+	if cfg.ModerationWordlistFile != "" {
+		blocked, flagged, err := loadWordlist(cfg.ModerationWordlistFile)
+		if err != nil {
+			slog.Warn("Failed to load moderation wordlist, using defaults", "path", cfg.ModerationWordlistFile, "error", err)
+		} else {
+			p.blocked, p.flagged = blocked, flagged
+		}
+	}
+
+	if cfg.ModerationProviderURL != "" {
+		p.provider = &HTTPProvider{
+			URL:    cfg.ModerationProviderURL,
+			APIKey: cfg.ModerationProviderAPIKey,
+			Client: &http.Client{Timeout: 5 * time.Second},
+		}
+	}
+
+	if cfg.ModerationImageProviderURL != "" {
+		p.imageProvider = &HTTPProvider{
+			URL:    cfg.ModerationImageProviderURL,
+			APIKey: cfg.ModerationProviderAPIKey,
+			Client: &http.Client{Timeout: 15 * time.Second},
+		}
+	}
+	return p
+}
+
+// Screen checks the given named fields and returns the strictest verdict.
+// If the ML provider fails, content is quarantined rather than approved.
+func (p *Pipeline) Screen(ctx context.Context, fields map[string]string) Decision {
+	decision := Decision{Verdict: VerdictApproved}
+
+	// Check fields in a stable order so reasons are deterministic
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		text := normalize(fields[name])
+		if text == "" {
+			continue
+		}
+		for _, term := range p.blocked {
+			if containsTerm(text, term) {
+				decision.escalate(VerdictRejected, fmt.Sprintf("%s contains prohibited term %q", name, term))
+			}
+		}
+		for _, term := range p.flagged {
+			if containsTerm(text, term) {
+				decision.escalate(VerdictQuarantined, fmt.Sprintf("%s contains restricted term %q", name, term))
+			}
+		}
+	}
+
+	if p.provider == nil || decision.Verdict == VerdictRejected {
+		return decision
+	}
+
+	for _, name := range names {
+		if strings.TrimSpace(fields[name]) == "" {
+			continue
+		}
+		scores, err := p.provider.Classify(ctx, fields[name])
+		if err != nil {
+			logging.FromContext(ctx).Warn("Moderation provider failed, quarantining content", "error", err)
+			decision.escalate(VerdictQuarantined, "automated moderation unavailable")
+			return decision
+		}
+		for category, score := range scores {
+			switch {
+			case score >= p.rejectThreshold:
+				decision.escalate(VerdictRejected, fmt.Sprintf("%s scored %.2f for %s", name, score, category))
+			case score >= p.quarantineThreshold:
+				decision.escalate(VerdictQuarantined, fmt.Sprintf("%s scored %.2f for %s", name, score, category))
+			}
+		}
+	}
+	return decision
+}
+
+// escalate records a reason and raises the verdict if the new one is stricter
+func (d *Decision) escalate(verdict, reason string) {
+	d.Reasons = append(d.Reasons, reason)
+	if severity(verdict) > severity(d.Verdict) {
+		d.Verdict = verdict
+	}
+}
+
+// severity orders verdicts from least to most strict
+func severity(verdict string) int {
+	switch verdict {
+	case VerdictRejected:
+		return 2
+	case VerdictQuarantined:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// normalize lowercases text and collapses punctuation to single spaces so
+// terms match on word boundaries
+func normalize(text string) string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// containsTerm reports whether normalized text contains the term as whole words
+func containsTerm(text, term string) bool {
+	term = normalize(term)
+	if term == "" {
+		return false
+	}
+	return strings.Contains(" "+text+" ", " "+term+" ")
+}
+
+// loadWordlist reads a wordlist file with one "block:<term>" or
+// "flag:<term>" entry per line. Blank lines and lines starting with # are ignored.
+func loadWordlist(path string) (blocked, flagged []string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kind, term, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(kind) {
+		case "block":
+			blocked = append(blocked, strings.TrimSpace(term))
+		case "flag":
+			flagged = append(flagged, strings.TrimSpace(term))
+		}
+	}
+	return blocked, flagged, scanner.Err()
+}
+
+// HTTPProvider is a Provider adapter for moderation services exposing a
+// JSON classification endpoint
+type HTTPProvider struct {
+	URL    string
+	APIKey string
+	Client *http.Client
+}
+
+// Classify sends text to the provider and returns its category scores
+func (p *HTTPProvider) Classify(ctx context.Context, text string) (map[string]float64, error) {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("moderation provider returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Scores map[string]float64 `json:"scores"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.Scores, nil
+}
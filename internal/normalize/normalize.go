@@ -0,0 +1,108 @@
+package normalize
+
+import (
+	"reflect"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// TagName is the struct tag listing the normalizations for a string field,
+// e.g. `normalize:"trim,nfc,collapse"`
+const TagName = "normalize"
+
+// Normalizations applied by the normalize tag, in this order regardless of
+// how they are listed
+const (
+	// NFC composes Unicode characters so visually identical strings compare equal
+	NFC = "nfc"
+	// Trim removes leading and trailing whitespace
+	Trim = "trim"
+	// Collapse replaces runs of internal whitespace with a single space
+	Collapse = "collapse"
+	// Lower lowercases the string, e.g. for email addresses
+	Lower = "lower"
+	// Upper uppercases the string, e.g. for country codes
+	Upper = "upper"
+)
+
+// Struct normalizes the tagged string fields of the struct obj points to, in
+// place. Tags apply to string, *string, and []string fields, and nested
+// structs, pointers, and slices of structs are walked.
+func Struct(obj any) {
+	walk(reflect.ValueOf(obj))
+}
+
+// walk normalizes tagged fields of structs reachable from v
+func walk(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			walk(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walk(v.Index(i))
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if tag, ok := field.Tag.Lookup(TagName); ok {
+				apply(v.Field(i), parseTag(tag))
+				continue
+			}
+			walk(v.Field(i))
+		}
+	}
+}
+
+// apply normalizes a string, *string, or []string field
+func apply(v reflect.Value, ops map[string]bool) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(String(v.String(), ops))
+		}
+	case reflect.Pointer:
+		if !v.IsNil() {
+			apply(v.Elem(), ops)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			apply(v.Index(i), ops)
+		}
+	}
+}
+
+// String applies the given normalizations to s
+func String(s string, ops map[string]bool) string {
+	if ops[NFC] {
+		s = norm.NFC.String(s)
+	}
+	if ops[Trim] {
+		s = strings.TrimSpace(s)
+	}
+	if ops[Collapse] {
+		s = strings.Join(strings.Fields(s), " ")
+	}
+	if ops[Lower] {
+		s = strings.ToLower(s)
+	}
+	if ops[Upper] {
+		s = strings.ToUpper(s)
+	}
+	return s
+}
+
+// parseTag splits a normalize tag into its normalizations
+func parseTag(tag string) map[string]bool {
+	ops := make(map[string]bool)
+	for _, op := range strings.Split(tag, ",") {
+		ops[strings.TrimSpace(op)] = true
+	}
+	return ops
+}
@@ -0,0 +1,188 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// stateTTL is how long a login attempt may take before the state expires
+const stateTTL = 10 * time.Minute
+
+// Common errors
+var (
+	ErrUnknownProvider = errors.New("unknown oidc provider")
+	ErrInvalidState    = errors.New("invalid or expired login state")
+	ErrInvalidIDToken  = errors.New("invalid id token")
+)
+
+// Manager runs the authorization-code flow with PKCE against the configured
+// OIDC providers. Provider discovery happens on first use so the gateway can
+// start while a provider is unreachable.
+type Manager struct {
+	configs     map[string]config.OIDCProvider
+	stateSecret []byte
+
+	mu        sync.Mutex
+	providers map[string]*provider
+}
+
+// provider is a discovered OIDC provider
+type provider struct {
+	oauth2   oauth2.Config
+	verifier *gooidc.IDTokenVerifier
+}
+
+// stateClaims are carried in the signed state cookie between login and callback
+type stateClaims struct {
+	Provider string `json:"provider"`
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	jwt.RegisteredClaims
+}
+
+// NewManager creates a manager for the configured OIDC providers
+func NewManager(cfg *config.Config) *Manager {
+	return &Manager{
+		configs:     cfg.OIDCProviders,
+		stateSecret: []byte(cfg.JWTSecret),
+		providers:   make(map[string]*provider),
+	}
+}
+
+// AuthURL starts a login, returning the provider's authorization URL and a
+// signed state value to be stored in a cookie until the callback
+func (m *Manager) AuthURL(ctx context.Context, name string) (string, string, error) {
+	p, err := m.provider(ctx, name)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, stateClaims{
+		Provider: name,
+		State:    state,
+		Nonce:    nonce,
+		Verifier: verifier,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(stateTTL)),
+		},
+	}).SignedString(m.stateSecret)
+	if err != nil {
+		return "", "", err
+	}
+
+	url := p.oauth2.AuthCodeURL(state, gooidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+	return url, signed, nil
+}
+
+// Exchange completes a login, validating the returned state against the
+// state cookie, redeeming the code, and verifying the ID token
+func (m *Manager) Exchange(ctx context.Context, name, code, state, stateCookie string) (*models.OIDCIdentity, error) {
+	claims := &stateClaims{}
+	_, err := jwt.ParseWithClaims(stateCookie, claims, func(token *jwt.Token) (interface{}, error) {
+		return m.stateSecret, nil
+	}, jwt.WithValidMethods([]string{"HS256"}), jwt.WithExpirationRequired())
+	if err != nil || claims.Provider != name || claims.State == "" || claims.State != state {
+		return nil, ErrInvalidState
+	}
+
+	p, err := m.provider(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := p.oauth2.Exchange(ctx, code, oauth2.VerifierOption(claims.Verifier))
+	if err != nil {
+		return nil, fmt.Errorf("exchange authorization code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, ErrInvalidIDToken
+	}
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil || idToken.Nonce != claims.Nonce {
+		return nil, ErrInvalidIDToken
+	}
+
+	var profile struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := idToken.Claims(&profile); err != nil {
+		return nil, ErrInvalidIDToken
+	}
+
+	return &models.OIDCIdentity{
+		Provider:      name,
+		Subject:       idToken.Subject,
+		Email:         profile.Email,
+		EmailVerified: profile.EmailVerified,
+		Name:          profile.Name,
+	}, nil
+}
+
+// provider returns the named provider, running discovery on first use
+func (m *Manager) provider(ctx context.Context, name string) (*provider, error) {
+	cfg, ok := m.configs[name]
+	if !ok || cfg.IssuerURL == "" || cfg.ClientID == "" {
+		return nil, ErrUnknownProvider
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if p, ok := m.providers[name]; ok {
+		return p, nil
+	}
+
+	discovered, err := gooidc.NewProvider(ctx, cfg.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("discover oidc provider %s: %w", name, err)
+	}
+
+	p := &provider{
+		oauth2: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint:     discovered.Endpoint(),
+			Scopes:       cfg.Scopes,
+		},
+		verifier: discovered.Verifier(&gooidc.Config{ClientID: cfg.ClientID}),
+	}
+	m.providers[name] = p
+	return p, nil
+}
+
+// randomString returns a URL-safe random string
+func randomString() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
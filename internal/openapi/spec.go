@@ -0,0 +1,151 @@
+package openapi
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ecommerce/be-api-gin/internal/middleware"
+)
+
+// APIRoot is the base path described by the spec
+const APIRoot = "/api/v1"
+
+// Spec is an OpenAPI 3 document
+type Spec struct {
+	OpenAPI string                          `json:"openapi"`
+	Info    Info                            `json:"info"`
+	Servers []Server                        `json:"servers"`
+	Paths   map[string]map[string]Operation `json:"paths"`
+}
+
+// Info describes the API
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Server is a base URL for the paths
+type Server struct {
+	URL string `json:"url"`
+}
+
+// Operation describes one method on a path
+type Operation struct {
+	OperationID      string              `json:"operationId"`
+	Parameters       []Parameter         `json:"parameters,omitempty"`
+	Responses        map[string]Response `json:"responses"`
+	MaxResponseBytes int                 `json:"x-max-response-bytes,omitempty"`
+}
+
+// Parameter describes a path or query parameter
+type Parameter struct {
+	Name     string `json:"name"`
+	In       string `json:"in"`
+	Required bool   `json:"required,omitempty"`
+	Schema   Schema `json:"schema"`
+}
+
+// Schema describes a parameter's type and bounds
+type Schema struct {
+	Type    string `json:"type"`
+	Minimum *int   `json:"minimum,omitempty"`
+	Maximum *int   `json:"maximum,omitempty"`
+	Default *int   `json:"default,omitempty"`
+}
+
+// Response describes a response status
+type Response struct {
+	Description string `json:"description"`
+}
+
+// Build describes the versioned API routes registered on the router, with
+// the page size bounds and response byte caps from limits
+func Build(routes gin.RoutesInfo, limits middleware.ResponseLimits) *Spec {
+	spec := &Spec{
+		OpenAPI: "3.0.3",
+		Info:    Info{Title: "E-Commerce API Gateway", Version: "1.0.0"},
+		Servers: []Server{{URL: APIRoot}},
+		Paths:   make(map[string]map[string]Operation),
+	}
+
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Path < routes[j].Path })
+	for _, route := range routes {
+		if !strings.HasPrefix(route.Path, APIRoot+"/") {
+			continue
+		}
+		path := strings.TrimPrefix(route.Path, APIRoot)
+
+		operation := Operation{
+			OperationID: operationID(route.Handler),
+			Parameters:  pathParameters(path),
+			Responses:   map[string]Response{"200": {Description: http.StatusText(http.StatusOK)}},
+		}
+		if limit, ok := limits.Lookup(route.Method, route.Path); ok {
+			if limit.Paginated() {
+				operation.Parameters = append(operation.Parameters,
+					Parameter{Name: "page", In: "query", Schema: Schema{Type: "integer", Minimum: intPtr(1), Default: intPtr(1)}},
+					Parameter{Name: "limit", In: "query", Schema: Schema{
+						Type:    "integer",
+						Minimum: intPtr(1),
+						Maximum: intPtr(limit.MaxPageSize),
+						Default: intPtr(limit.DefaultPageSize),
+					}},
+				)
+			}
+			if limit.Truncates && limit.MaxBytes > 0 {
+				operation.MaxResponseBytes = limit.MaxBytes
+				operation.Responses["200"] = Response{Description: "OK. Item lists over x-max-response-bytes are cut short and flagged with truncated: true."}
+			}
+		}
+
+		key := openAPIPath(path)
+		if spec.Paths[key] == nil {
+			spec.Paths[key] = make(map[string]Operation)
+		}
+		spec.Paths[key][strings.ToLower(route.Method)] = operation
+	}
+	return spec
+}
+
+// openAPIPath converts gin's :param segments to OpenAPI {param} templates
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// pathParameters describes the :param segments of a gin path
+func pathParameters(path string) []Parameter {
+	var params []Parameter
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			params = append(params, Parameter{Name: segment[1:], In: "path", Required: true, Schema: Schema{Type: "string"}})
+		}
+	}
+	return params
+}
+
+// operationID derives an ID such as ProductHandler.ListProducts from a
+// handler's function name
+func operationID(handler string) string {
+	handler = strings.TrimSuffix(handler, "-fm")
+	if i := strings.LastIndex(handler, "/"); i >= 0 {
+		handler = handler[i+1:]
+	}
+	if _, name, ok := strings.Cut(handler, "."); ok {
+		handler = name
+	}
+	return strings.NewReplacer("(*", "", ")", "").Replace(handler)
+}
+
+// intPtr returns a pointer to v
+func intPtr(v int) *int {
+	return &v
+}
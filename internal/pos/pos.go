@@ -0,0 +1,188 @@
+package pos
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// ErrLocalIDReused is returned when a device submits a different order under
+// a local ID it has already used
+var ErrLocalIDReused = errors.New("local ID was already used for a different order")
+
+// Submission is an order a device submitted, with the request it is placed
+// from and the fingerprint telling a resubmission of it from a different
+// order reusing its local ID
+type Submission struct {
+	Order       *models.POSOrder        `json:"order"`
+	Request     *models.POSOrderRequest `json:"request"`
+	Fingerprint string                  `json:"fingerprint"`
+}
+
+// clone copies a submission so its order can be updated independently. The
+// request is never modified once submitted.
+func (s *Submission) clone() *Submission {
+	copied := *s
+	order := *s.Order
+	copied.Order = &order
+	return &copied
+}
+
+// Queue accepts orders from in-store devices and places them with the order
+// service in the background. Devices queue orders locally while offline and
+// can forget an order once the queue has accepted it; placing it is then
+// retried until the order service places or rejects it.
+type Queue struct {
+	store         Store
+	clients       *grpcclient.Clients
+	maxAge        time.Duration
+	retryInterval time.Duration
+}
+
+// NewQueue creates a queue recording submissions in store
+func NewQueue(store Store, clients *grpcclient.Clients, cfg *config.Config) *Queue {
+	return &Queue{
+		store:         store,
+		clients:       clients,
+		maxAge:        time.Duration(cfg.POSMaxOfflineHours) * time.Hour,
+		retryInterval: time.Duration(cfg.POSRetryIntervalSec) * time.Second,
+	}
+}
+
+// Submit accepts an order from a device. A new order is recorded as
+// accepted_pending and placed in the background, or rejected straight away
+// if it was taken too long ago. An order the device has already submitted is
+// returned as it stands, marked as replayed.
+func (q *Queue) Submit(ctx context.Context, deviceID, storeID string, req *models.POSOrderRequest) (*models.POSOrder, error) {
+	fingerprint, err := requestFingerprint(req)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &Submission{
+		Order: &models.POSOrder{
+			LocalID:    req.LocalID,
+			DeviceID:   deviceID,
+			StoreID:    storeID,
+			Status:     models.POSOrderStatusAcceptedPending,
+			TakenAt:    req.TakenAt,
+			AcceptedAt: models.Now(),
+		},
+		Request:     req,
+		Fingerprint: fingerprint,
+	}
+	if q.maxAge > 0 && time.Since(req.TakenAt.Time) > q.maxAge {
+		sub.Order.Status = models.POSOrderStatusRejected
+		sub.Order.Error = fmt.Sprintf("the order was taken more than %s ago and must be entered again", q.maxAge)
+	}
+
+	claimed, existing, err := q.store.Claim(ctx, sub)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		if existing.Fingerprint != fingerprint {
+			return nil, ErrLocalIDReused
+		}
+		existing.Order.Replayed = true
+		return existing.Order, nil
+	}
+
+	accepted := *sub.Order
+	if sub.Order.Status == models.POSOrderStatusAcceptedPending {
+		go q.place(context.WithoutCancel(ctx), sub.clone())
+	}
+	return &accepted, nil
+}
+
+// Get returns an order a device submitted, or nil if it has submitted none
+// under localID
+func (q *Queue) Get(ctx context.Context, deviceID, localID string) (*models.POSOrder, error) {
+	sub, err := q.store.Get(ctx, deviceID, localID)
+	if err != nil || sub == nil {
+		return nil, err
+	}
+	return sub.Order, nil
+}
+
+// Run retries placing pending orders every retry interval until ctx is
+// done. The order service recognises orders it has already placed, so an
+// order being placed by more than one replica, or by its first attempt
+// still running, is placed once.
+func (q *Queue) Run(ctx context.Context) {
+	ticker := time.NewTicker(q.retryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			q.retryPending(ctx)
+		}
+	}
+}
+
+// retryPending tries again to place orders accepted at least a retry
+// interval ago that are still pending
+func (q *Queue) retryPending(ctx context.Context) {
+	pending, err := q.store.Pending(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Failed to list pending POS orders", "error", err)
+		return
+	}
+	for _, sub := range pending {
+		if time.Since(sub.Order.AcceptedAt.Time) >= q.retryInterval {
+			q.place(ctx, sub)
+		}
+	}
+}
+
+// place asks the order service to place a submitted order and records the
+// outcome. Orders naming products that don't exist are rejected; any other
+// failure leaves the order pending to be retried.
+func (q *Queue) place(ctx context.Context, sub *Submission) {
+	logger := logging.FromContext(ctx).With("device_id", sub.Order.DeviceID, "local_id", sub.Order.LocalID)
+
+	sub.Order.Attempts++
+	order, err := q.clients.CreatePOSOrder(ctx, sub.Order.StoreID, sub.Order.DeviceID, sub.Request)
+	switch {
+	case err == nil:
+		sub.Order.Status = models.POSOrderStatusPlaced
+		sub.Order.OrderID = order.ID
+		sub.Order.PlacedAt = models.TimestampPtr(time.Now())
+		sub.Order.Error = ""
+		logger.Info("POS order placed", "order_id", order.ID, "attempts", sub.Order.Attempts)
+	case errors.Is(err, grpcclient.ErrNotFound):
+		sub.Order.Status = models.POSOrderStatusRejected
+		sub.Order.Error = "a product in the order does not exist"
+		logger.Warn("POS order rejected", "error", err)
+	default:
+		sub.Order.Error = err.Error()
+		logger.Warn("Failed to place POS order, will retry", "attempts", sub.Order.Attempts, "error", err)
+	}
+
+	if err := q.store.Save(ctx, sub); err != nil {
+		logger.Error("Failed to record POS order outcome", "status", sub.Order.Status, "error", err)
+	}
+}
+
+// requestFingerprint hashes an order request so a resubmission can be told
+// from a different order under the same local ID
+func requestFingerprint(req *models.POSOrderRequest) (string, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,246 @@
+package pos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+	redisclient "github.com/ecommerce/be-api-gin/pkg/redis"
+)
+
+// Store keeps the orders devices have submitted, by device and local ID
+type Store interface {
+	// Claim records a new submission, returning true if its device hadn't
+	// used the local ID. Otherwise it returns the existing submission.
+	Claim(ctx context.Context, sub *Submission) (bool, *Submission, error)
+	// Save replaces a submission
+	Save(ctx context.Context, sub *Submission) error
+	// Get returns a device's submission, or nil if it does not exist
+	Get(ctx context.Context, deviceID, localID string) (*Submission, error)
+	// Pending returns the submissions not yet placed or rejected
+	Pending(ctx context.Context) ([]*Submission, error)
+	// Purge removes placed and rejected submissions accepted before the
+	// given time, returning how many it removed
+	Purge(ctx context.Context, before time.Time) (int, error)
+}
+
+// submissionKey identifies a submission by its device and local ID
+func submissionKey(deviceID, localID string) string {
+	return deviceID + ":" + localID
+}
+
+// finishedBefore reports whether a submission was placed or rejected and
+// accepted before the given time
+func finishedBefore(sub *Submission, before time.Time) bool {
+	return sub.Order.Status != models.POSOrderStatusAcceptedPending && sub.Order.AcceptedAt.Before(before)
+}
+
+// MemoryStore is an in-process Store. Submissions are lost on restart, and
+// a device resubmitting through another gateway instance has its order
+// accepted again.
+type MemoryStore struct {
+	mu          sync.Mutex
+	submissions map[string]*Submission
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		submissions: make(map[string]*Submission),
+	}
+}
+
+// Claim records a submission unless its local ID is taken
+func (s *MemoryStore) Claim(ctx context.Context, sub *Submission) (bool, *Submission, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := submissionKey(sub.Order.DeviceID, sub.Order.LocalID)
+	if existing, ok := s.submissions[key]; ok {
+		return false, existing.clone(), nil
+	}
+	s.submissions[key] = sub.clone()
+	return true, nil, nil
+}
+
+// Save replaces a submission
+func (s *MemoryStore) Save(ctx context.Context, sub *Submission) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.submissions[submissionKey(sub.Order.DeviceID, sub.Order.LocalID)] = sub.clone()
+	return nil
+}
+
+// Get returns a submission
+func (s *MemoryStore) Get(ctx context.Context, deviceID, localID string) (*Submission, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.submissions[submissionKey(deviceID, localID)]
+	if !ok {
+		return nil, nil
+	}
+	return sub.clone(), nil
+}
+
+// Pending returns the submissions still to be placed
+func (s *MemoryStore) Pending(ctx context.Context) ([]*Submission, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pending []*Submission
+	for _, sub := range s.submissions {
+		if sub.Order.Status == models.POSOrderStatusAcceptedPending {
+			pending = append(pending, sub.clone())
+		}
+	}
+	return pending, nil
+}
+
+// Purge removes finished submissions accepted before the given time
+func (s *MemoryStore) Purge(ctx context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purged := 0
+	for key, sub := range s.submissions {
+		if finishedBefore(sub, before) {
+			delete(s.submissions, key)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// RedisStore is a Store shared by all gateway replicas. Pending submissions
+// are also listed in a set so they can be retried without a scan.
+type RedisStore struct {
+	client *goredis.Client
+	prefix string
+}
+
+// NewRedisStore creates a store using client, namespacing keys with prefix
+func NewRedisStore(client *goredis.Client, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// submissionKey returns the Redis key of a submission
+func (s *RedisStore) submissionKey(deviceID, localID string) string {
+	return s.prefix + "order:" + submissionKey(deviceID, localID)
+}
+
+// pendingKey returns the Redis key of the set of pending submissions
+func (s *RedisStore) pendingKey() string {
+	return s.prefix + "pending"
+}
+
+// Claim atomically records a submission unless its local ID is taken
+func (s *RedisStore) Claim(ctx context.Context, sub *Submission) (bool, *Submission, error) {
+	data, err := json.Marshal(sub)
+	if err != nil {
+		return false, nil, err
+	}
+	key := s.submissionKey(sub.Order.DeviceID, sub.Order.LocalID)
+	claimed, err := s.client.SetNX(ctx, key, data, 0).Result()
+	if err != nil {
+		return false, nil, err
+	}
+	if claimed {
+		if sub.Order.Status == models.POSOrderStatusAcceptedPending {
+			if err := s.client.SAdd(ctx, s.pendingKey(), key).Err(); err != nil {
+				return false, nil, err
+			}
+		}
+		return true, nil, nil
+	}
+
+	existing, err := s.Get(ctx, sub.Order.DeviceID, sub.Order.LocalID)
+	if err != nil {
+		return false, nil, err
+	}
+	if existing == nil {
+		// The submission was purged in between; try again
+		return s.Claim(ctx, sub)
+	}
+	return false, existing, nil
+}
+
+// Save replaces a submission, keeping the pending set up to date
+func (s *RedisStore) Save(ctx context.Context, sub *Submission) error {
+	data, err := json.Marshal(sub)
+	if err != nil {
+		return err
+	}
+	key := s.submissionKey(sub.Order.DeviceID, sub.Order.LocalID)
+	pipe := s.client.TxPipeline()
+	pipe.Set(ctx, key, data, 0)
+	if sub.Order.Status == models.POSOrderStatusAcceptedPending {
+		pipe.SAdd(ctx, s.pendingKey(), key)
+	} else {
+		pipe.SRem(ctx, s.pendingKey(), key)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Get returns a submission
+func (s *RedisStore) Get(ctx context.Context, deviceID, localID string) (*Submission, error) {
+	data, err := s.client.Get(ctx, s.submissionKey(deviceID, localID)).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var sub Submission
+	if err := json.Unmarshal(data, &sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// Pending returns the submissions in the pending set that are still pending
+func (s *RedisStore) Pending(ctx context.Context) ([]*Submission, error) {
+	keys, err := s.client.SMembers(ctx, s.pendingKey()).Result()
+	if err != nil || len(keys) == 0 {
+		return nil, err
+	}
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []*Submission
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var sub Submission
+		if err := json.Unmarshal([]byte(data), &sub); err != nil {
+			return nil, err
+		}
+		if sub.Order.Status == models.POSOrderStatusAcceptedPending {
+			pending = append(pending, &sub)
+		}
+	}
+	return pending, nil
+}
+
+// Purge removes finished submissions accepted before the given time
+func (s *RedisStore) Purge(ctx context.Context, before time.Time) (int, error) {
+	return redisclient.Purge(ctx, s.client, s.prefix+"order:*", func(value []byte) bool {
+		var sub Submission
+		return json.Unmarshal(value, &sub) == nil && finishedBefore(&sub, before)
+	})
+}
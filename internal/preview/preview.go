@@ -0,0 +1,56 @@
+package preview
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+)
+
+// audience marks preview tokens so they can't be confused with access tokens
+const audience = "product-preview"
+
+// QueryParam is the query parameter carrying a preview token
+const QueryParam = "preview_token"
+
+// ErrInvalidToken is returned for malformed, expired, or mismatched tokens
+var ErrInvalidToken = errors.New("invalid preview token")
+
+// claims identify the product a preview token grants access to
+type claims struct {
+	ProductID string `json:"product_id"`
+	jwt.RegisteredClaims
+}
+
+// Issue signs a token granting read access to an unpublished product until
+// it expires. The issuing seller is recorded as the subject.
+func Issue(cfg *config.Config, productID, sellerID string) (string, time.Time, error) {
+	expiresAt := time.Now().Add(time.Duration(cfg.PreviewTokenTTLSec) * time.Second)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		ProductID: productID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   sellerID,
+			Audience:  jwt.ClaimStrings{audience},
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}).SignedString([]byte(cfg.JWTSecret))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// Verify reports whether a preview token is valid for the given product
+func Verify(cfg *config.Config, token, productID string) error {
+	c := &claims{}
+	_, err := jwt.ParseWithClaims(token, c, func(token *jwt.Token) (interface{}, error) {
+		return []byte(cfg.JWTSecret), nil
+	}, jwt.WithValidMethods([]string{"HS256"}), jwt.WithAudience(audience), jwt.WithExpirationRequired())
+	if err != nil || c.ProductID == "" || c.ProductID != productID {
+		return ErrInvalidToken
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package publishing
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/ecommerce/be-api-gin/internal/catalog"
+	"github.com/ecommerce/be-api-gin/internal/lock"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// Claimer grants a key to a single caller for the TTL. It lets one gateway
+// replica run each periodic check; middleware.NonceStore satisfies it.
+type Claimer interface {
+	Claim(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+// Invalidator purges a changed product's cached copies;
+// cache.ProductCache satisfies it
+type Invalidator interface {
+	Invalidate(ctx context.Context, id string)
+}
+
+// lockName is the lock held while publishing due products
+const lockName = "publish-scheduled-products"
+
+// Scheduler publishes scheduled products once their publish time arrives
+type Scheduler struct {
+	clients  *grpcclient.Clients
+	locker   lock.Locker
+	products Invalidator
+	interval time.Duration
+}
+
+// NewScheduler creates a scheduler that checks for due products every
+// interval, purging each product it changes from products. Each check holds
+// a lock from locker, so only one replica publishes at a time.
+func NewScheduler(clients *grpcclient.Clients, locker lock.Locker, products Invalidator, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		clients:  clients,
+		locker:   locker,
+		products: products,
+		interval: interval,
+	}
+}
+
+// Run checks for due products on every tick until the context is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.tick(ctx, now)
+		}
+	}
+}
+
+// tick publishes products due at or before now. Products that are no longer
+// complete are returned to draft and the seller is notified. The work stops
+// when the lock lapses, so it can't overlap another replica's check.
+func (s *Scheduler) tick(ctx context.Context, now time.Time) {
+	release, acquired, err := s.locker.TryLock(ctx, lockName, s.interval)
+	if err != nil {
+		slog.Warn("Failed to lock publishing check", "error", err)
+		return
+	}
+	if !acquired {
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(ctx, s.interval)
+	defer cancel()
+
+	products, err := s.clients.ListDueProducts(ctx, now)
+	if err != nil {
+		slog.Warn("Failed to fetch scheduled products", "error", err)
+		return
+	}
+
+	for _, product := range products {
+		if blockers := catalog.PublishBlockers(product); len(blockers) > 0 {
+			if err := s.clients.SetProductStatus(ctx, product.ID, models.ProductStatusDraft, nil); err != nil {
+				slog.Warn("Failed to return incomplete product to draft", "product_id", product.ID, "error", err)
+				continue
+			}
+			s.products.Invalidate(ctx, product.ID)
+			s.notify(ctx, product, blockers)
+			continue
+		}
+
+		if err := s.clients.SetProductStatus(ctx, product.ID, models.ProductStatusPublished, nil); err != nil {
+			slog.Warn("Failed to publish scheduled product", "product_id", product.ID, "error", err)
+			continue
+		}
+		s.products.Invalidate(ctx, product.ID)
+		slog.Info("Published scheduled product", "product_id", product.ID)
+	}
+}
+
+// notify tells the seller why a scheduled product was not published
+func (s *Scheduler) notify(ctx context.Context, product *models.Product, blockers []models.CatalogIssue) {
+	if product.SellerID == "" {
+		return
+	}
+
+	codes := make([]string, 0, len(blockers))
+	for _, issue := range blockers {
+		codes = append(codes, issue.Code)
+	}
+
+	err := s.clients.NotifyUser(ctx, product.SellerID, &models.Notification{
+		Type:    "product_publish_failed",
+		Title:   "Scheduled product not published",
+		Message: "\"" + product.Name + "\" is incomplete and was returned to draft",
+		Data: map[string]string{
+			"product_id": product.ID,
+			"issues":     strings.Join(codes, ","),
+		},
+	})
+	if err != nil {
+		slog.Warn("Failed to notify seller", "seller_id", product.SellerID, "error", err)
+	}
+}
@@ -0,0 +1,78 @@
+package redact
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Placeholder replaces redacted values
+const Placeholder = "[REDACTED]"
+
+// sensitiveKeys are matched against lowercased field names with separators
+// removed, so "card_number", "cardNumber" and "Card-Number" all match
+var sensitiveKeys = []string{
+	"password",
+	"secret",
+	"token",
+	"authorization",
+	"cookie",
+	"apikey",
+	"cardnumber",
+	"cvv",
+	"cvc",
+	"ssn",
+	"email",
+	"phone",
+	"iban",
+	"address",
+	"street",
+	"postalcode",
+	"zip",
+}
+
+// IsSensitive reports whether a field name looks like it holds credentials
+// or personal data
+func IsSensitive(key string) bool {
+	key = strings.ToLower(key)
+	key = strings.NewReplacer("_", "", "-", "", ".", "").Replace(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// JSON returns a copy of a JSON document with sensitive fields replaced by
+// Placeholder at any depth. Input that isn't valid JSON is replaced entirely
+// since it can't be inspected.
+func JSON(data []byte) []byte {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return []byte(`"` + Placeholder + `"`)
+	}
+	redacted, err := json.Marshal(walk(doc))
+	if err != nil {
+		return []byte(`"` + Placeholder + `"`)
+	}
+	return redacted
+}
+
+// walk redacts sensitive fields in a decoded JSON value
+func walk(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if IsSensitive(key) {
+				v[key] = Placeholder
+			} else {
+				v[key] = walk(value)
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = walk(value)
+		}
+	}
+	return v
+}
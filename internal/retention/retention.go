@@ -0,0 +1,103 @@
+package retention
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/ecommerce/be-api-gin/internal/publishing"
+)
+
+var (
+	purgedRecordsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "retention_purged_records_total",
+		Help: "Records removed by retention purges, by data category.",
+	}, []string{"category"})
+
+	purgeFailuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "retention_purge_failures_total",
+		Help: "Retention purges that failed, by data category.",
+	}, []string{"category"})
+
+	lastPurgeTimestamp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "retention_last_purge_timestamp_seconds",
+		Help: "Unix time of the last successful retention purge, by data category.",
+	}, []string{"category"})
+)
+
+// Purger removes a category's records stored before a cutoff, returning
+// how many it removed
+type Purger func(ctx context.Context, before time.Time) (int, error)
+
+// Policy is how long one category of data is kept, and how to purge it.
+// A zero MaxAge keeps the data for as long as its store does.
+type Policy struct {
+	Category string
+	MaxAge   time.Duration
+	Purge    Purger
+}
+
+// Scheduler purges data that has outlived its retention period
+type Scheduler struct {
+	policies []Policy
+	claimer  publishing.Claimer
+	interval time.Duration
+}
+
+// NewScheduler creates a scheduler that applies the policies every interval
+func NewScheduler(policies []Policy, claimer publishing.Claimer, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		policies: policies,
+		claimer:  claimer,
+		interval: interval,
+	}
+}
+
+// Run purges on every tick until the context is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.tick(ctx, now.Truncate(s.interval))
+		}
+	}
+}
+
+// tick applies every policy once. A failing category doesn't stop the
+// others from being purged.
+func (s *Scheduler) tick(ctx context.Context, now time.Time) {
+	claimed, err := s.claimer.Claim(ctx, "retention:"+strconv.FormatInt(now.Unix(), 10), s.interval)
+	if err != nil {
+		slog.Warn("Failed to claim retention purge", "error", err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	for _, policy := range s.policies {
+		if policy.MaxAge <= 0 {
+			continue
+		}
+
+		start := time.Now()
+		purged, err := policy.Purge(ctx, now.Add(-policy.MaxAge))
+		purgedRecordsTotal.WithLabelValues(policy.Category).Add(float64(purged))
+		if err != nil {
+			purgeFailuresTotal.WithLabelValues(policy.Category).Inc()
+			slog.Warn("Retention purge failed", "category", policy.Category, "purged", purged, "error", err)
+			continue
+		}
+		lastPurgeTimestamp.WithLabelValues(policy.Category).Set(float64(now.Unix()))
+		slog.Info("Retention purge completed", "category", policy.Category, "purged", purged, "max_age", policy.MaxAge, "duration", time.Since(start))
+	}
+}
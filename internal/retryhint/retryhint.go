@@ -0,0 +1,106 @@
+package retryhint
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// failureKey is the context key for the request's backend failure recorder
+type failureKey struct{}
+
+// failure is the most recent failed backend call made for a request
+type failure struct {
+	mu         sync.Mutex
+	recorded   bool
+	code       codes.Code
+	retryAfter time.Duration
+}
+
+// Hint tells a client whether and when to retry a failed request
+type Hint struct {
+	Retryable  bool
+	RetryAfter time.Duration // zero if the client should choose its own backoff
+}
+
+// WithRecorder returns a copy of ctx that records failed backend calls made
+// with it
+func WithRecorder(ctx context.Context) context.Context {
+	return context.WithValue(ctx, failureKey{}, &failure{})
+}
+
+// RecordFailure notes a failed backend call and the delay its service asked
+// for, if any. It does nothing if ctx was not prepared with WithRecorder.
+func RecordFailure(ctx context.Context, code codes.Code, retryAfter time.Duration) {
+	f, ok := ctx.Value(failureKey{}).(*failure)
+	if !ok {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.recorded = true
+	f.code = code
+	f.retryAfter = retryAfter
+}
+
+// ForCode returns the hint for a failed gRPC call. Transient conditions are
+// retryable; failures that would recur on retry, such as invalid arguments
+// or internal errors, are not.
+func ForCode(code codes.Code, retryAfter time.Duration) Hint {
+	switch code {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted, codes.DeadlineExceeded:
+		if retryAfter <= 0 && code == codes.ResourceExhausted {
+			retryAfter = time.Second
+		}
+		return Hint{Retryable: true, RetryAfter: retryAfter}
+	default:
+		return Hint{}
+	}
+}
+
+// ForStatus returns the hint for an HTTP error status, honoring a
+// Retry-After header in seconds
+func ForStatus(status int, retryAfterHeader string) Hint {
+	var retryAfter time.Duration
+	if seconds, err := strconv.Atoi(retryAfterHeader); err == nil && seconds > 0 {
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+	switch status {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return Hint{Retryable: true, RetryAfter: retryAfter}
+	default:
+		return Hint{Retryable: retryAfter > 0, RetryAfter: retryAfter}
+	}
+}
+
+// ForResponse returns the hint for a failed request. Server errors caused by
+// a failed backend call take their hint from the call's gRPC code; other
+// errors take it from the status.
+func ForResponse(ctx context.Context, status int, retryAfterHeader string) Hint {
+	hint := ForStatus(status, retryAfterHeader)
+	if status < http.StatusInternalServerError {
+		return hint
+	}
+
+	f, ok := ctx.Value(failureKey{}).(*failure)
+	if !ok {
+		return hint
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.recorded {
+		return hint
+	}
+
+	fromCode := ForCode(f.code, f.retryAfter)
+	if hint.RetryAfter > fromCode.RetryAfter {
+		fromCode.RetryAfter = hint.RetryAfter
+	}
+	fromCode.Retryable = fromCode.Retryable || hint.Retryable
+	return fromCode
+}
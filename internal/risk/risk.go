@@ -0,0 +1,304 @@
+package risk
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// Signal names reported in risk scores
+const (
+	SignalVelocity      = "velocity"
+	SignalDeviceChanges = "device_changes"
+	SignalChargebacks   = "chargebacks"
+)
+
+// Scoring weights. Each signal is capped so no single one can push an
+// account past the review threshold on its own.
+const (
+	velocityPoints      = 5
+	maxVelocityPoints   = 40
+	devicePoints        = 15
+	maxDevicePoints     = 30
+	chargebackPoints    = 25
+	maxChargebackPoints = 50
+	maxScore            = 100
+)
+
+const (
+	velocityWindow = time.Hour
+	deviceWindow   = 24 * time.Hour
+	// deviceMemory is how long a device stays known to the account
+	deviceMemory = 30 * 24 * time.Hour
+	// sweepInterval is how often idle accounts are dropped
+	sweepInterval = 10 * time.Minute
+)
+
+// Backend provides risk signals and review handling owned by other services
+type Backend interface {
+	CountChargebacks(ctx context.Context, userIDs []string) (map[string]int, error)
+	FlagAccountForReview(ctx context.Context, score *models.RiskScore) error
+}
+
+// account holds the signals observed by the gateway for one user
+type account struct {
+	actions    []time.Time
+	devices    map[string]time.Time // device hash to last seen
+	newDevices []time.Time
+	flagged    bool
+}
+
+// Scorer maintains per-account risk scores in memory. Velocity and device
+// changes are observed by the gateway; chargebacks come from the backend.
+type Scorer struct {
+	backend         Backend
+	velocityLimit   int
+	stepUpThreshold int
+	reviewThreshold int
+
+	mu        sync.Mutex
+	accounts  map[string]*account
+	lastSweep time.Time
+}
+
+// NewScorer creates a scorer using the configured thresholds
+func NewScorer(cfg *config.Config, backend Backend) *Scorer {
+	return &Scorer{
+		backend:         backend,
+		velocityLimit:   cfg.RiskVelocityLimit,
+		stepUpThreshold: cfg.RiskStepUpThreshold,
+		reviewThreshold: cfg.RiskReviewThreshold,
+		accounts:        make(map[string]*account),
+		lastSweep:       time.Now(),
+	}
+}
+
+// Evaluate records a risky action from a device and returns the account's
+// updated score. The review hold is recomputed from every score: an account
+// is flagged for manual review when it reaches the review threshold and the
+// hold lifts once its signals age out. An action the hold blocks isn't
+// recorded, so retrying while held doesn't prolong the hold.
+func (s *Scorer) Evaluate(ctx context.Context, userID, device string) *models.RiskScore {
+	now := time.Now()
+
+	s.mu.Lock()
+	s.sweep(now)
+	acct := s.account(userID)
+	acct.actions = append(acct.actions, now)
+	if device != "" {
+		key := hashDevice(device)
+		if _, known := acct.devices[key]; !known && len(acct.devices) > 0 {
+			acct.newDevices = append(acct.newDevices, now)
+		}
+		acct.devices[key] = now
+	}
+	s.mu.Unlock()
+
+	score := s.Score(ctx, userID)
+	held := s.RequiresReview(score)
+
+	s.mu.Lock()
+	flag := held && !acct.flagged
+	acct.flagged = held
+	if held {
+		acct.forget(now)
+	}
+	s.mu.Unlock()
+
+	if flag {
+		if err := s.backend.FlagAccountForReview(ctx, score); err != nil {
+			logging.FromContext(ctx).Warn("Failed to flag account for review", "account_id", userID, "error", err)
+		}
+	}
+	return score
+}
+
+// Score computes the account's current risk score without recording an action
+func (s *Scorer) Score(ctx context.Context, userID string) *models.RiskScore {
+	return s.score(userID, s.chargebacks(ctx, []string{userID})[userID], time.Now())
+}
+
+// chargebacks fetches chargeback counts for userIDs in one backend call.
+// Fail open: a backend outage shouldn't make every account risky.
+func (s *Scorer) chargebacks(ctx context.Context, userIDs []string) map[string]int {
+	counts, err := s.backend.CountChargebacks(ctx, userIDs)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Failed to fetch chargebacks, scoring without them", "accounts", len(userIDs), "error", err)
+		return map[string]int{}
+	}
+	return counts
+}
+
+// score computes an account's risk score from its observed signals and
+// chargebacks
+func (s *Scorer) score(userID string, chargebacks int, now time.Time) *models.RiskScore {
+	s.mu.Lock()
+	var actions, newDevices int
+	if acct, ok := s.accounts[userID]; ok {
+		acct.prune(now)
+		actions = len(acct.actions)
+		newDevices = len(acct.newDevices)
+	}
+	s.mu.Unlock()
+
+	excess := actions - s.velocityLimit
+	if excess < 0 {
+		excess = 0
+	}
+	signals := []models.RiskSignal{
+		{Name: SignalVelocity, Value: actions, Points: capPoints(excess*velocityPoints, maxVelocityPoints)},
+		{Name: SignalDeviceChanges, Value: newDevices, Points: capPoints(newDevices*devicePoints, maxDevicePoints)},
+		{Name: SignalChargebacks, Value: chargebacks, Points: capPoints(chargebacks*chargebackPoints, maxChargebackPoints)},
+	}
+
+	total := 0
+	for _, signal := range signals {
+		total += signal.Points
+	}
+	total = capPoints(total, maxScore)
+
+	return &models.RiskScore{
+		UserID:    userID,
+		Score:     total,
+		Level:     s.level(total),
+		Signals:   signals,
+		UpdatedAt: models.NewTimestamp(now),
+	}
+}
+
+// Accounts returns the scores of tracked accounts at or above minScore,
+// highest first
+func (s *Scorer) Accounts(ctx context.Context, minScore int) []*models.RiskScore {
+	s.mu.Lock()
+	userIDs := make([]string, 0, len(s.accounts))
+	for userID := range s.accounts {
+		userIDs = append(userIDs, userID)
+	}
+	s.mu.Unlock()
+
+	if len(userIDs) == 0 {
+		return []*models.RiskScore{}
+	}
+
+	now := time.Now()
+	chargebacks := s.chargebacks(ctx, userIDs)
+	scores := make([]*models.RiskScore, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if score := s.score(userID, chargebacks[userID], now); score.Score >= minScore {
+			scores = append(scores, score)
+		}
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		return scores[i].Score > scores[j].Score
+	})
+	return scores
+}
+
+// Reset clears the signals observed by the gateway for an account, e.g.
+// after a manual review clears it. Known devices are kept.
+func (s *Scorer) Reset(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if acct, ok := s.accounts[userID]; ok {
+		acct.actions = nil
+		acct.newDevices = nil
+		acct.flagged = false
+	}
+}
+
+// RequiresStepUp reports whether a score requires step-up verification
+func (s *Scorer) RequiresStepUp(score *models.RiskScore) bool {
+	return s.stepUpThreshold > 0 && score.Score >= s.stepUpThreshold
+}
+
+// RequiresReview reports whether a score holds the account for manual review
+func (s *Scorer) RequiresReview(score *models.RiskScore) bool {
+	return s.reviewThreshold > 0 && score.Score >= s.reviewThreshold
+}
+
+// level maps a score to a risk level
+func (s *Scorer) level(score int) string {
+	switch {
+	case s.reviewThreshold > 0 && score >= s.reviewThreshold:
+		return models.RiskLevelHigh
+	case s.stepUpThreshold > 0 && score >= s.stepUpThreshold:
+		return models.RiskLevelElevated
+	default:
+		return models.RiskLevelLow
+	}
+}
+
+// account returns the state for userID, creating it if needed. Callers must
+// hold s.mu.
+func (s *Scorer) account(userID string) *account {
+	acct, ok := s.accounts[userID]
+	if !ok {
+		acct = &account{devices: make(map[string]time.Time)}
+		s.accounts[userID] = acct
+	}
+	acct.prune(time.Now())
+	return acct
+}
+
+// sweep drops accounts with nothing left to remember. Callers must hold s.mu.
+func (s *Scorer) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for userID, acct := range s.accounts {
+		acct.prune(now)
+		if len(acct.actions) == 0 && len(acct.newDevices) == 0 && len(acct.devices) == 0 && !acct.flagged {
+			delete(s.accounts, userID)
+		}
+	}
+}
+
+// prune drops signals that have aged out of their windows
+func (a *account) prune(now time.Time) {
+	a.actions = dropBefore(a.actions, now.Add(-velocityWindow))
+	a.newDevices = dropBefore(a.newDevices, now.Add(-deviceWindow))
+	for key, lastSeen := range a.devices {
+		if now.Sub(lastSeen) > deviceMemory {
+			delete(a.devices, key)
+		}
+	}
+}
+
+// forget drops the action recorded at now, for an action that was blocked
+func (a *account) forget(now time.Time) {
+	if n := len(a.actions); n > 0 && a.actions[n-1].Equal(now) {
+		a.actions = a.actions[:n-1]
+	}
+}
+
+// dropBefore removes the leading times earlier than cutoff from a sorted slice
+func dropBefore(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && times[i].Before(cutoff) {
+		i++
+	}
+	return times[i:]
+}
+
+// capPoints limits points to limit
+func capPoints(points, limit int) int {
+	if points > limit {
+		return limit
+	}
+	return points
+}
+
+// hashDevice avoids keeping raw device identifiers in memory
+func hashDevice(device string) string {
+	sum := sha256.Sum256([]byte(device))
+	return hex.EncodeToString(sum[:8])
+}
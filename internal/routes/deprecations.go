@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/ecommerce/be-api-gin/internal/deprecation"
+)
+
+// deprecations declares deprecated surfaces. Callers see Deprecation and
+// Sunset headers, and partners can list what they still use at
+// GET /api/v1/deprecations. Routes are relative to the API root, e.g.
+//
+//	Routes: map[string]deprecation.Notice{
+//		"GET /products/search": {Since: ..., Sunset: ..., Successor: "/api/v1/products"},
+//	},
+var deprecations = &deprecation.Registry{
+	Routes: map[string]deprecation.Notice{},
+	Fields: map[string][]deprecation.Field{},
+}
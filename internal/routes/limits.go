@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/ecommerce/be-api-gin/internal/middleware"
+)
+
+// responseLimits declares the page sizes of paginated routes and which
+// aggregate routes cut long item lists to a byte cap. Paths are relative to
+// the API root. MAX_PAGE_SIZE lowers the maximums and MAX_PAGE_SIZES sets them per route.
+var responseLimits = middleware.ResponseLimits{
+	"GET /products":                            {DefaultPageSize: 10, MaxPageSize: 100},
+	"GET /products/:id/reviews":                {DefaultPageSize: 10, MaxPageSize: 100},
+	"GET /products/:id/questions":              {DefaultPageSize: 10, MaxPageSize: 100},
+	"GET /products/:id/questions/:qid/answers": {DefaultPageSize: 10, MaxPageSize: 100},
+	"GET /orders":                              {DefaultPageSize: 10, MaxPageSize: 100},
+	"GET /sellers/me/orders":                   {DefaultPageSize: 10, MaxPageSize: 100},
+	"GET /sellers/me/inventory/forecast":       {Truncates: true},
+	"GET /sellers/me/catalog/issues":           {Truncates: true},
+	"GET /admin/inventory/adjustments":         {DefaultPageSize: 10, MaxPageSize: 100},
+	"GET /admin/products/duplicates":           {DefaultPageSize: 10, MaxPageSize: 100},
+	"GET /admin/products/:id/history":          {DefaultPageSize: 20, MaxPageSize: 100},
+	"GET /admin/moderation/queue":              {DefaultPageSize: 10, MaxPageSize: 100},
+	"GET /admin/reports":                       {DefaultPageSize: 10, MaxPageSize: 100},
+}
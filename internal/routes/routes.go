@@ -1,60 +1,690 @@
 package routes
 
 import (
+	"context"
+	"log/slog"
 	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	goredis "github.com/redis/go-redis/v9"
 
+	"github.com/ecommerce/be-api-gin/internal/cache"
+	"github.com/ecommerce/be-api-gin/internal/calendar"
+	"github.com/ecommerce/be-api-gin/internal/cart"
 	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/currency"
+	"github.com/ecommerce/be-api-gin/internal/deprecation"
+	"github.com/ecommerce/be-api-gin/internal/dispatch"
+	"github.com/ecommerce/be-api-gin/internal/errorreport"
+	"github.com/ecommerce/be-api-gin/internal/expiry"
+	"github.com/ecommerce/be-api-gin/internal/guest"
 	"github.com/ecommerce/be-api-gin/internal/handlers"
+	"github.com/ecommerce/be-api-gin/internal/jobs"
+	"github.com/ecommerce/be-api-gin/internal/localization"
+	"github.com/ecommerce/be-api-gin/internal/lock"
 	"github.com/ecommerce/be-api-gin/internal/middleware"
+	"github.com/ecommerce/be-api-gin/internal/models"
+	"github.com/ecommerce/be-api-gin/internal/moderation"
+	"github.com/ecommerce/be-api-gin/internal/oidc"
+	"github.com/ecommerce/be-api-gin/internal/openapi"
+	"github.com/ecommerce/be-api-gin/internal/pos"
+	"github.com/ecommerce/be-api-gin/internal/publishing"
+	"github.com/ecommerce/be-api-gin/internal/retention"
+	"github.com/ecommerce/be-api-gin/internal/risk"
+	"github.com/ecommerce/be-api-gin/internal/scanning"
+	"github.com/ecommerce/be-api-gin/internal/search"
+	"github.com/ecommerce/be-api-gin/internal/segment"
+	"github.com/ecommerce/be-api-gin/internal/shipping"
+	"github.com/ecommerce/be-api-gin/internal/slo"
+	"github.com/ecommerce/be-api-gin/internal/tax"
+	"github.com/ecommerce/be-api-gin/internal/tracing"
+	"github.com/ecommerce/be-api-gin/internal/undo"
+	"github.com/ecommerce/be-api-gin/internal/verification"
 	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
 )
 
-// Setup configures all routes and returns the router
-func Setup(cfg *config.Config, grpcClients *grpcclient.Clients) *gin.Engine {
+// draining is set once shutdown begins, so readiness checks fail while
+// in-flight requests finish
+var draining atomic.Bool
+
+// StartDraining makes readiness checks report the gateway as not ready so
+// load balancers stop sending it new requests
+func StartDraining() {
+	draining.Store(true)
+}
+
+// Setup configures all routes and returns the router. The background
+// workers it starts run until ctx is done.
+func Setup(ctx context.Context, cfg *config.Config, grpcClients *grpcclient.Clients, redisClient *goredis.Client) *gin.Engine {
 	router := gin.New()
 
+	// Let validation tags such as required apply to timestamp fields
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		models.RegisterValidators(v)
+	}
+
+	// Only honor X-Forwarded-For from known proxies so client IPs can't be spoofed
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		slog.Warn("Invalid TRUSTED_PROXIES, trusting no proxies", "error", err)
+		router.SetTrustedProxies(nil)
+	}
+
+	// IP allow/deny lists and country blocking
+	ipFilter := middleware.NewIPFilter(cfg, redisClient)
+	go ipFilter.Sync(ctx)
+
 	// Global middleware
-	router.Use(gin.Logger())
-	router.Use(middleware.RecoveryMiddleware())
+	router.Use(middleware.MetricsMiddleware())
+	router.Use(middleware.SLOMiddleware(slo.NewTracker(cfg)))
+	router.Use(tracing.Middleware(cfg))
+	router.Use(middleware.AccessLogMiddleware(cfg))
+	router.Use(middleware.RequestIDMiddleware())
+	router.Use(middleware.LoggerMiddleware())
+	router.Use(middleware.InFlightMiddleware())
+	router.Use(middleware.NewLoadShedder(cfg).Middleware())
+	router.Use(middleware.CompressionMiddleware(cfg))
+	router.Use(middleware.BodyLogMiddleware(cfg))
+	router.Use(middleware.AnonymizeMiddleware(cfg))
+	router.Use(middleware.RecoveryMiddleware(errorreport.NewReporter(cfg)))
+	router.Use(ipFilter.DenyMiddleware())
+	router.Use(middleware.WAFMiddleware(cfg))
 	router.Use(middleware.CORSMiddleware(cfg))
 	router.Use(middleware.SecurityHeadersMiddleware())
-	router.Use(middleware.RequestIDMiddleware())
+	router.Use(middleware.TimezoneHintsMiddleware())
+
+	// API key authentication is validated against the user service
+	middleware.SetAPIKeyValidator(grpcClients)
 
 	// Health check endpoints
 	router.GET("/health", healthCheck)
 	router.GET("/ready", readinessCheck(grpcClients))
 
+	// Prometheus metrics
+	router.GET("/metrics", middleware.MetricsHandler(cfg.MetricsToken, cfg.Region))
+
+	// Rate limiting per route group, shared across replicas when Redis is configured
+	var limiter middleware.Limiter = middleware.NewMemoryLimiter()
+	if redisClient != nil {
+		limiter = middleware.NewRedisLimiter(redisClient, "ratelimit:")
+	}
+	rateLimit := func(group string) gin.HandlerFunc {
+		return middleware.RateLimitMiddleware(limiter, group, cfg.RateLimitFor(group))
+	}
+	strictJSON := func(group string) gin.HandlerFunc {
+		return middleware.StrictJSONMiddleware(cfg, group)
+	}
+
+	// Signed partner requests, with nonces shared across replicas when Redis is configured
+	var nonces middleware.NonceStore = middleware.NewMemoryNonceStore()
+	if redisClient != nil {
+		nonces = middleware.NewRedisNonceStore(redisClient, "nonce:")
+	}
+	signatureCheck := middleware.SignatureMiddleware(cfg, nonces)
+
+	// Content moderation shared by products and reviews
+	moderationPipeline := moderation.NewPipeline(cfg)
+
+	// Shared response cache for anonymous product reads, when Redis is configured
+	var responseCache cache.Store
+	if redisClient != nil {
+		responseCache = cache.NewRedisStore(redisClient, "resp:")
+	}
+	productListTags := func(c *gin.Context) []string { return []string{cache.ProductListTag} }
+	productTags := func(c *gin.Context) []string { return []string{cache.ProductTag(c.Param("id"))} }
+	comparisonTags := func(c *gin.Context) []string {
+		var tags []string
+		for _, id := range strings.Split(c.Query("ids"), ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				tags = append(tags, cache.ProductTag(id))
+			}
+		}
+		return tags
+	}
+
+	// Product lookups, purged on every replica when products change
+	productCache := cache.NewProductCache(grpcClients, redisClient, responseCache, cfg)
+	if redisClient != nil && cfg.CacheInvalidationPubSub {
+		go productCache.Subscribe(ctx)
+	}
+
+	// Locale-resolved product content, machine translated in the background
+	localizer := localization.NewLocalizer(grpcClients, cfg, moderationPipeline, productCache)
+	go localizer.Run(ctx)
+
+	// Cached responses are keyed on the locale they were served in
+	cacheFor := func(ttlSec int, tags func(c *gin.Context) []string) gin.HandlerFunc {
+		return middleware.ResponseCacheMiddleware(responseCache, localizer, time.Duration(ttlSec)*time.Second, tags)
+	}
+
+	// Publish scheduled products, with one replica handling each tick
+	if cfg.PublishSchedulerIntervalSec > 0 {
+		var locker lock.Locker = lock.NewMemory()
+		if redisClient != nil {
+			locker = lock.NewRedis(redisClient, "lock:")
+		}
+		scheduler := publishing.NewScheduler(grpcClients, locker, productCache, time.Duration(cfg.PublishSchedulerIntervalSec)*time.Second)
+		go scheduler.Run(ctx)
+	}
+
+	// Cancel orders left unpaid and release their stock, with one replica
+	// handling each check
+	if cfg.UnpaidOrderTimeoutMin > 0 && cfg.UnpaidOrderSweepIntervalSec > 0 {
+		var claimer publishing.Claimer = middleware.NewMemoryNonceStore()
+		if redisClient != nil {
+			claimer = middleware.NewRedisNonceStore(redisClient, "scheduler:")
+		}
+		sweeper := expiry.NewSweeper(grpcClients, claimer, time.Duration(cfg.UnpaidOrderTimeoutMin)*time.Minute, time.Duration(cfg.UnpaidOrderSweepIntervalSec)*time.Second)
+		go sweeper.Run(ctx)
+	}
+
+	// Degraded-mode product search over an in-memory catalog index
+	var searchFallback *search.Index
+	if cfg.SearchFallbackEnabled && cfg.SearchFallbackRefreshSec > 0 {
+		searchFallback = search.NewIndex()
+		go searchFallback.Refresh(ctx, grpcClients, time.Duration(cfg.SearchFallbackRefreshSec)*time.Second)
+	}
+
+	// Customer segments for promotions, recommendations, and experiments
+	middleware.SetSegmentResolver(segment.NewResolver(cfg, grpcClients))
+
+	// Account risk scoring for risky actions
+	riskScorer := risk.NewScorer(cfg, grpcClients)
+	riskCheck := middleware.RiskCheck(cfg, riskScorer)
+
+	// Revoked tokens are shared across replicas when Redis is configured, and
+	// high-value routes can also confirm tokens with the identity provider
+	if redisClient != nil {
+		middleware.SetRevocationList(middleware.NewRedisRevocationList(redisClient, "revoked:", cfg.RevocationFailOpen))
+	}
+	introspect := middleware.IntrospectionMiddleware(cfg)
+
+	// Page size and response byte caps per route
+	limits := responseLimits.WithConfig(cfg)
+
+	// Usage of deprecated surfaces by integration, shared across replicas when Redis is configured
+	var deprecationStore deprecation.Store = deprecation.NewMemoryStore()
+	if redisClient != nil {
+		deprecationStore = deprecation.NewRedisStore(redisClient, "deprecation:")
+	}
+
+	// Machine clients are limited to the routes their scopes cover
+	middleware.SetRouteScopes(routeScopes)
+
+	// Undo window for destructive seller actions, shared across replicas when Redis is configured
+	var undoStore undo.Store = undo.NewMemoryStore()
+	if redisClient != nil {
+		undoStore = undo.NewRedisStore(redisClient, "undo:")
+	}
+
+	// Shopping carts, shared across replicas when Redis is configured
+	var cartStore cart.Store = cart.NewMemoryStore()
+	if redisClient != nil {
+		cartStore = cart.NewRedisStore(redisClient, "cart:")
+	}
+
+	// Rendered invoices, shared across replicas when Redis is configured
+	var invoiceStore cache.Store
+	if redisClient != nil {
+		invoiceStore = cache.NewRedisStore(redisClient, "invoice:")
+	}
+
+	// Holidays by region, refreshed from the external source if configured
+	holidayCalendar := calendar.New(cfg)
+	go holidayCalendar.Refresh(ctx, time.Duration(cfg.HolidaySourceRefreshHours)*time.Hour)
+
+	// Same-day dispatch promises on products and carts, when configured
+	dispatchPlanner := dispatch.NewPlanner(cfg, holidayCalendar)
+
+	// Shipping quotes with delivery estimates, when a rate provider is configured
+	shippingQuoter := shipping.NewQuoter(cfg, dispatchPlanner, holidayCalendar)
+
+	// Exchange rates for display prices and orders in sellers' currencies
+	currencyConverter := currency.NewConverter(cfg)
+
+	// Guest email verifications and checkout tokens, shared across replicas when Redis is configured
+	var guestStore guest.Store = guest.NewMemoryStore()
+	if redisClient != nil {
+		guestStore = guest.NewRedisStore(redisClient, "guest:")
+	}
+	guestVerifier := guest.NewVerifier(guestStore, time.Duration(cfg.GuestCodeTTLMin)*time.Minute, time.Duration(cfg.GuestTokenTTLMin)*time.Minute, cfg.GuestCodeMaxAttempts, guest.Limits{
+		MaxCodes:    cfg.GuestMaxCodes,
+		MaxFailures: cfg.GuestMaxFailures,
+		Lockout:     time.Duration(cfg.GuestLockoutMin) * time.Minute,
+	})
+
+	// Responses to order requests with an Idempotency-Key, shared across replicas when Redis is configured
+	var idempotencyStore middleware.IdempotencyStore = middleware.NewMemoryIdempotencyStore()
+	if redisClient != nil {
+		idempotencyStore = middleware.NewRedisIdempotencyStore(redisClient, "idempotency:")
+	}
+	idempotent := middleware.IdempotencyMiddleware(cfg, idempotencyStore)
+
+	// Payment webhook event IDs already applied, shared across replicas when Redis is configured
+	var webhookEvents middleware.IdempotencyStore = middleware.NewMemoryIdempotencyStore()
+	if redisClient != nil {
+		webhookEvents = middleware.NewRedisIdempotencyStore(redisClient, "webhook:")
+	}
+
+	// Orders from in-store devices, shared across replicas when Redis is
+	// configured, retried until the order service places them
+	var posStore pos.Store = pos.NewMemoryStore()
+	if redisClient != nil {
+		posStore = pos.NewRedisStore(redisClient, "pos:")
+	}
+	posQueue := pos.NewQueue(posStore, grpcClients, cfg)
+	if cfg.POSRetryIntervalSec > 0 {
+		go posQueue.Run(ctx)
+	}
+
+	// Background jobs, with progress shared across replicas when Redis is configured
+	var jobStore jobs.Store = jobs.NewMemoryStore()
+	if redisClient != nil {
+		jobStore = jobs.NewRedisStore(redisClient, "job:")
+	}
+	jobRunner := jobs.NewRunner(jobStore)
+
+	// Gift card code lookups, limited per code and client IP on the shared rate limiter
+	giftCardLookups := handlers.NewGiftCardLookups(limiter, cfg.GiftCardLookupsPerHour)
+
+	// Purge gateway-owned data past its retention period, with one replica
+	// handling each purge when Redis is configured
+	if cfg.RetentionPurgeIntervalSec > 0 {
+		policies := []retention.Policy{
+			{Category: config.RetentionIdempotencyRecords, MaxAge: cfg.RetentionFor(config.RetentionIdempotencyRecords), Purge: idempotencyStore.Purge},
+			{Category: config.RetentionWebhookEvents, MaxAge: cfg.RetentionFor(config.RetentionWebhookEvents), Purge: webhookEvents.Purge},
+			{Category: config.RetentionGuestCarts, MaxAge: cfg.RetentionFor(config.RetentionGuestCarts), Purge: func(ctx context.Context, before time.Time) (int, error) {
+				return cartStore.Purge(ctx, cart.GuestOwner(""), before)
+			}},
+			{Category: config.RetentionJobs, MaxAge: cfg.RetentionFor(config.RetentionJobs), Purge: jobStore.Purge},
+			{Category: config.RetentionPOSOrders, MaxAge: cfg.RetentionFor(config.RetentionPOSOrders), Purge: posStore.Purge},
+		}
+		scheduler := retention.NewScheduler(policies, nonces, time.Duration(cfg.RetentionPurgeIntervalSec)*time.Second)
+		go scheduler.Run(ctx)
+	}
+
 	// Initialize handlers
-	productHandler := handlers.NewProductHandler(grpcClients)
-	orderHandler := handlers.NewOrderHandler(grpcClients)
+	authHandler := handlers.NewAuthHandler(grpcClients, cfg)
+	oauthHandler := handlers.NewOAuthHandler(cfg)
+	oidcHandler := handlers.NewOIDCHandler(grpcClients, oidc.NewManager(cfg), cfg)
+	apiKeyHandler := handlers.NewAPIKeyHandler(grpcClients, cfg)
+	productHandler := handlers.NewProductHandler(grpcClients, cfg, moderationPipeline, undoStore, localizer, productCache, jobRunner, searchFallback, dispatchPlanner, currencyConverter)
+	translationHandler := handlers.NewTranslationHandler(cfg, grpcClients, moderationPipeline, localizer, productCache)
+	reviewHandler := handlers.NewReviewHandler(grpcClients, moderationPipeline)
+	sizeGuideHandler := handlers.NewSizeGuideHandler(grpcClients, responseCache)
+	questionHandler := handlers.NewQuestionHandler(grpcClients, moderationPipeline)
+	reportHandler := handlers.NewReportHandler(grpcClients, productCache, cfg)
+	mediaHandler := handlers.NewMediaHandler(grpcClients, cfg, moderationPipeline, scanning.NewScanner(cfg), jobRunner)
+	cartHandler := handlers.NewCartHandler(grpcClients, productCache, cartStore, dispatchPlanner, cfg)
+	orderHandler := handlers.NewOrderHandler(grpcClients, verification.NewIDVerifier(cfg), tax.NewCalculator(cfg), shippingQuoter, currencyConverter, cartStore, guestVerifier, giftCardLookups, cfg)
+	paymentHandler := handlers.NewPaymentHandler(grpcClients, cfg)
+	returnHandler := handlers.NewReturnHandler(grpcClients, cfg)
+	guestHandler := handlers.NewGuestHandler(grpcClients, guestVerifier)
+	addressHandler := handlers.NewAddressHandler(grpcClients)
+	paymentMethodHandler := handlers.NewPaymentMethodHandler(grpcClients)
+	creditHandler := handlers.NewStoreCreditHandler(grpcClients, cfg)
+	giftCardHandler := handlers.NewGiftCardHandler(grpcClients, giftCardLookups, cfg)
+	// Backend target changes are applied by every replica when Redis is configured
+	if redisClient != nil {
+		grpcClients.ShareBackendTargets(grpcclient.NewRedisTargetStore(redisClient, "backend-targets"))
+		go grpcClients.SyncBackendTargets(ctx)
+	}
+	backendHandler := handlers.NewBackendHandler(grpcClients)
+	sellerHandler := handlers.NewSellerHandler(grpcClients, productCache)
+	transferHandler := handlers.NewTransferHandler(grpcClients)
+	cycleCountHandler := handlers.NewCycleCountHandler(grpcClients, holidayCalendar, cfg)
+	calendarHandler := handlers.NewCalendarHandler(holidayCalendar)
+	shippingHandler := handlers.NewShippingHandler(grpcClients, cartStore, shippingQuoter)
+	trackingHandler := handlers.NewTrackingHandler(grpcClients)
+	invoiceHandler := handlers.NewInvoiceHandler(grpcClients, invoiceStore, cfg)
+	fulfillmentHandler := handlers.NewFulfillmentHandler(grpcClients)
+	riskHandler := handlers.NewRiskHandler(riskScorer)
+	ipRuleHandler := handlers.NewIPRuleHandler(ipFilter)
+	moderationHandler := handlers.NewModerationHandler(grpcClients, productCache)
+	loggingHandler := handlers.NewLoggingHandler()
+	actionHandler := handlers.NewActionHandler(grpcClients, undoStore, productCache)
+	searchHandler := handlers.NewSearchHandler(grpcClients, jobRunner)
+	deprecationHandler := handlers.NewDeprecationHandler(deprecations, deprecationStore)
+	errorCodeHandler := handlers.NewErrorCodeHandler()
+	webhookHandler := handlers.NewWebhookHandler(grpcClients, cfg, webhookEvents)
+	posHandler := handlers.NewPOSHandler(posQueue, cfg)
+
+	// Provider webhooks authenticate with their own signatures, not user credentials
+	router.POST("/webhooks/payments", webhookHandler.PaymentWebhook)
+	router.POST("/webhooks/carriers", webhookHandler.CarrierWebhook)
 
 	// Setup product and order routes function
 	setupAPIRoutes := func(apiGroup *gin.RouterGroup) {
+		apiGroup.Use(signatureCheck, middleware.SLATierMiddleware(cfg), middleware.ResponseLimitMiddleware(limits), middleware.DeprecationMiddleware(deprecations, deprecationStore))
+
+		// Auth routes
+		auth := apiGroup.Group("/auth")
+		auth.Use(rateLimit("auth"), strictJSON("auth"))
+		{
+			auth.POST("/refresh", authHandler.Refresh)
+			auth.POST("/logout", middleware.AuthMiddleware(cfg), authHandler.Logout)
+			auth.GET("/oidc/:provider/login", oidcHandler.Login)
+			auth.GET("/oidc/:provider/callback", oidcHandler.Callback)
+		}
+
+		// OAuth2 token endpoint for machine clients
+		oauth := apiGroup.Group("/oauth")
+		oauth.Use(rateLimit("oauth"), strictJSON("oauth"))
+		{
+			oauth.POST("/token", oauthHandler.Token)
+		}
+
+		// API key management routes (all protected)
+		apiKeys := apiGroup.Group("/api-keys")
+		apiKeys.Use(middleware.AuthMiddleware(cfg), rateLimit("api-keys"), strictJSON("api-keys"))
+		{
+			apiKeys.GET("", apiKeyHandler.ListAPIKeys)
+			apiKeys.POST("", introspect, riskCheck, apiKeyHandler.CreateAPIKey)
+			apiKeys.GET("/:id", apiKeyHandler.GetAPIKey)
+			apiKeys.PUT("/:id", apiKeyHandler.UpdateAPIKey)
+			apiKeys.POST("/:id/rotate", introspect, riskCheck, apiKeyHandler.RotateAPIKey)
+			apiKeys.DELETE("/:id", apiKeyHandler.RevokeAPIKey)
+		}
+
 		// Product routes
 		products := apiGroup.Group("/products")
+		// Signed-in callers are rate limited per user rather than per address
+		products.Use(middleware.OptionalAuthMiddleware(cfg), rateLimit("products"), strictJSON("products"))
 		{
 			// Public routes
-			products.GET("", productHandler.ListProducts)
-			products.GET("/:id", productHandler.GetProduct)
+			products.GET("", middleware.ETagMiddleware(), cacheFor(cfg.ProductListCacheTTLSec, productListTags), productHandler.ListProducts)
+			products.GET("/compare", middleware.ETagMiddleware(), cacheFor(cfg.ProductCacheTTLSec, comparisonTags), productHandler.CompareProducts)
+			products.GET("/lookup", productHandler.LookupBarcode)
+			products.POST("/lookup", productHandler.LookupBarcodes)
+			products.GET("/:id", middleware.ETagMiddleware(), cacheFor(cfg.ProductCacheTTLSec, productTags), productHandler.GetProduct)
+			products.GET("/:id/full", middleware.ETagMiddleware(), cacheFor(cfg.ProductCacheTTLSec, productTags), productHandler.GetProductFull)
+			products.GET("/:id/reviews", reviewHandler.ListReviews)
+			products.GET("/:id/size-guide", middleware.ETagMiddleware(), sizeGuideHandler.GetSizeGuide)
+			products.GET("/:id/questions", questionHandler.ListQuestions)
+			products.GET("/:id/questions/:qid/answers", questionHandler.ListAnswers)
 
 			// Protected routes
-			products.POST("", middleware.AuthMiddleware(cfg), productHandler.CreateProduct)
-			products.PUT("/:id", middleware.AuthMiddleware(cfg), productHandler.UpdateProduct)
-			products.DELETE("/:id", middleware.AuthMiddleware(cfg), productHandler.DeleteProduct)
-			products.PUT("/:id/inventory", middleware.AuthMiddleware(cfg), productHandler.UpdateInventory)
+			products.POST("", middleware.AuthMiddleware(cfg), middleware.RequirePermission(cfg, config.PermProductsCreate), productHandler.CreateProduct)
+			products.PUT("/:id", middleware.AuthMiddleware(cfg), middleware.RequirePermission(cfg, config.PermProductsUpdate), productHandler.UpdateProduct)
+			products.GET("/:id/translations", middleware.AuthMiddleware(cfg), middleware.RequirePermission(cfg, config.PermProductsUpdate), translationHandler.ListTranslations)
+			products.PUT("/:id/translations/:locale", middleware.AuthMiddleware(cfg), middleware.RequirePermission(cfg, config.PermProductsUpdate), translationHandler.SubmitTranslation)
+			products.POST("/:id/preview-token", middleware.AuthMiddleware(cfg), middleware.RequirePermission(cfg, config.PermProductsUpdate), productHandler.CreatePreviewToken)
+			products.POST("/:id/publish", middleware.AuthMiddleware(cfg), middleware.RequirePermission(cfg, config.PermProductsUpdate), productHandler.PublishProduct)
+			products.POST("/prices", middleware.AuthMiddleware(cfg), middleware.RequirePermission(cfg, config.PermProductsUpdate), productHandler.BulkUpdatePrices)
+			products.DELETE("/:id", middleware.AuthMiddleware(cfg), middleware.RequirePermission(cfg, config.PermProductsDelete), productHandler.DeleteProduct)
+			products.PUT("/:id/inventory", middleware.AuthMiddleware(cfg), middleware.RequirePermission(cfg, config.PermInventoryUpdate), productHandler.UpdateInventory)
+			products.POST("/:id/reviews", middleware.AuthMiddleware(cfg), reviewHandler.CreateReview)
+			products.POST("/:id/reviews/:rid/response", middleware.AuthMiddleware(cfg), middleware.RequirePermission(cfg, config.PermReviewsRespond), reviewHandler.RespondToReview)
+			products.POST("/:id/reviews/:rid/fit", middleware.AuthMiddleware(cfg), sizeGuideHandler.SubmitFitFeedback)
+			products.PUT("/:id/size-guide", middleware.AuthMiddleware(cfg), middleware.RequirePermission(cfg, config.PermProductsUpdate), sizeGuideHandler.SetProductSizeGuide)
+			products.POST("/:id/questions", middleware.AuthMiddleware(cfg), questionHandler.AskQuestion)
+			products.POST("/:id/questions/:qid/answers", middleware.AuthMiddleware(cfg), questionHandler.AnswerQuestion)
+			products.POST("/:id/questions/:qid/answers/:aid/vote", middleware.AuthMiddleware(cfg), questionHandler.VoteAnswer)
+			products.POST("/:id/report", middleware.AuthMiddleware(cfg), rateLimit("reports"), reportHandler.ReportProduct)
+		}
+
+		// Deprecated routes and fields, with the calling integration's usage
+		deprecationReport := apiGroup.Group("/deprecations")
+		deprecationReport.Use(rateLimit("deprecations"))
+		{
+			deprecationReport.GET("", middleware.OptionalAuthMiddleware(cfg), deprecationHandler.GetReport)
+		}
+
+		// Undo routes for recent destructive actions
+		actions := apiGroup.Group("/actions")
+		actions.Use(middleware.AuthMiddleware(cfg), rateLimit("actions"), strictJSON("actions"))
+		{
+			actions.POST("/:id/undo", actionHandler.UndoAction)
+		}
+
+		// Review routes
+		reviews := apiGroup.Group("/reviews")
+		reviews.Use(middleware.AuthMiddleware(cfg), rateLimit("reports"), strictJSON("reviews"))
+		{
+			reviews.POST("/:id/report", reportHandler.ReportReview)
+		}
+
+		// Media routes (all protected)
+		media := apiGroup.Group("/media")
+		media.Use(middleware.AuthMiddleware(cfg), rateLimit("media"), strictJSON("media"), middleware.RequirePermission(cfg, config.PermMediaUpload))
+		{
+			media.POST("/uploads", mediaHandler.UploadMedia)
+		}
+
+		// Cart routes for signed-in users and guest sessions
+		carts := apiGroup.Group("/cart")
+		carts.Use(middleware.OptionalAuthMiddleware(cfg), rateLimit("cart"), strictJSON("cart"))
+		{
+			carts.GET("", cartHandler.GetCart)
+			carts.DELETE("", cartHandler.ClearCart)
+			carts.POST("/items", cartHandler.AddItem)
+			carts.PATCH("/items/:productId", cartHandler.UpdateItem)
+			carts.DELETE("/items/:productId", cartHandler.RemoveItem)
+			carts.POST("/coupon/validate", cartHandler.ValidateCoupon)
+			carts.PUT("/coupon", cartHandler.ApplyCoupon)
+			carts.DELETE("/coupon", cartHandler.RemoveCoupon)
+		}
+
+		// Saved shipping addresses of the signed-in user
+		addresses := apiGroup.Group("/me/addresses")
+		addresses.Use(middleware.AuthMiddleware(cfg), rateLimit("me"), strictJSON("me"))
+		{
+			addresses.GET("", addressHandler.ListAddresses)
+			addresses.POST("", addressHandler.CreateAddress)
+			addresses.GET("/:id", addressHandler.GetAddress)
+			addresses.PUT("/:id", addressHandler.UpdateAddress)
+			addresses.DELETE("/:id", addressHandler.DeleteAddress)
+		}
+
+		// Saved payment methods of the signed-in user, as provider tokens
+		paymentMethods := apiGroup.Group("/me/payment-methods")
+		paymentMethods.Use(middleware.AuthMiddleware(cfg), rateLimit("me"), strictJSON("me"))
+		{
+			paymentMethods.GET("", paymentMethodHandler.ListPaymentMethods)
+			paymentMethods.POST("", introspect, riskCheck, paymentMethodHandler.SavePaymentMethod)
+			paymentMethods.POST("/:id/default", paymentMethodHandler.SetDefaultPaymentMethod)
+			paymentMethods.DELETE("/:id", paymentMethodHandler.DeletePaymentMethod)
+		}
+
+		// Store credit of the signed-in user
+		users := apiGroup.Group("/users/me")
+		users.Use(middleware.AuthMiddleware(cfg), rateLimit("me"), strictJSON("me"))
+		{
+			users.GET("/credit", creditHandler.GetBalance)
 		}
 
 		// Order routes (all protected)
 		orders := apiGroup.Group("/orders")
-		orders.Use(middleware.AuthMiddleware(cfg))
+		orders.Use(middleware.AuthMiddleware(cfg), rateLimit("orders"), strictJSON("orders"))
 		{
-			orders.GET("", orderHandler.ListOrders)
-			orders.GET("/:id", orderHandler.GetOrder)
-			orders.POST("", orderHandler.CreateOrder)
+			orders.GET("", middleware.ETagMiddleware(), orderHandler.ListOrders)
+			orders.GET("/:id", middleware.ETagMiddleware(), orderHandler.GetOrder)
+			orders.POST("", introspect, riskCheck, idempotent, orderHandler.CreateOrder)
 			orders.PUT("/:id/status", orderHandler.UpdateOrderStatus)
 			orders.DELETE("/:id", orderHandler.CancelOrder)
+			orders.GET("/:id/payment", paymentHandler.GetOrderPayment)
+			orders.GET("/:id/tracking", trackingHandler.GetOrderTracking)
+			orders.GET("/:id/invoice", invoiceHandler.GetInvoice)
+			orders.POST("/:id/returns", returnHandler.CreateReturn)
+		}
+
+		// Returns of ordered items
+		returns := apiGroup.Group("/returns")
+		returns.Use(middleware.AuthMiddleware(cfg), rateLimit("orders"), strictJSON("orders"))
+		{
+			returns.GET("/:id", returnHandler.GetReturn)
+		}
+
+		// Payments for orders
+		payments := apiGroup.Group("/payments")
+		payments.Use(middleware.AuthMiddleware(cfg), rateLimit("orders"), strictJSON("orders"))
+		{
+			payments.POST("/intents", paymentHandler.CreatePaymentIntent)
+			payments.GET("/intents/:id", paymentHandler.GetPaymentIntent)
+			payments.POST("/intents/:id/confirm", introspect, riskCheck, paymentHandler.ConfirmPayment)
+		}
+
+		// Checkout of the user's cart
+		checkout := apiGroup.Group("/checkout")
+		checkout.Use(middleware.AuthMiddleware(cfg), rateLimit("orders"), strictJSON("orders"))
+		{
+			checkout.POST("", introspect, riskCheck, idempotent, orderHandler.Checkout)
+		}
+
+		// Gift cards: buying one or checking a balance needs an account
+		giftCards := apiGroup.Group("/gift-cards")
+		giftCards.Use(rateLimit("gift-cards"), strictJSON("gift-cards"))
+		{
+			giftCards.POST("", middleware.AuthMiddleware(cfg), introspect, riskCheck, idempotent, giftCardHandler.PurchaseGiftCard)
+			giftCards.POST("/balance", middleware.AuthMiddleware(cfg), giftCardHandler.GetBalance)
+		}
+
+		// Shipping quotes for the caller's cart, signed in or as a guest
+		if shippingQuoter != nil {
+			shippingQuotes := apiGroup.Group("/shipping")
+			shippingQuotes.Use(middleware.OptionalAuthMiddleware(cfg), rateLimit("shipping"), strictJSON("shipping"))
+			{
+				shippingQuotes.POST("/quotes", shippingHandler.QuoteShipping)
+			}
+		}
+
+		// In-store kiosks and tills, authenticated as devices rather than users
+		posAPI := apiGroup.Group("/pos")
+		posAPI.Use(rateLimit("pos"), strictJSON("pos"))
+		{
+			posAPI.POST("/token", posHandler.Token)
+			posAPI.POST("/orders", middleware.DeviceAuthMiddleware(cfg), posHandler.SubmitOrder)
+			posAPI.POST("/orders/reconcile", middleware.DeviceAuthMiddleware(cfg), posHandler.ReconcileOrders)
+			posAPI.GET("/orders/:localId", middleware.DeviceAuthMiddleware(cfg), posHandler.GetOrder)
+		}
+
+		// Holiday calendars (public)
+		holidays := apiGroup.Group("/calendar")
+		holidays.Use(rateLimit("calendar"), strictJSON("calendar"))
+		{
+			holidays.GET("/holidays", calendarHandler.ListHolidays)
+			holidays.GET("/days/:date", calendarHandler.GetDay)
+		}
+
+		// Guest checkout with a verified email, and claiming guest orders
+		// after registering
+		if cfg.GuestCheckoutEnabled {
+			guests := apiGroup.Group("/guest")
+			guests.Use(rateLimit("guest"), strictJSON("guest"))
+			{
+				guests.POST("/verification", guestHandler.RequestVerification)
+				guests.POST("/verification/confirm", guestHandler.ConfirmVerification)
+				guests.POST("/checkout", idempotent, orderHandler.GuestCheckout)
+				guests.GET("/orders/:id", orderHandler.GetGuestOrder)
+			}
+			orders.POST("/claim", orderHandler.ClaimGuestOrders)
+		}
+
+		// Seller routes (all protected, scoped to the authenticated seller)
+		sellers := apiGroup.Group("/sellers/me")
+		sellers.Use(middleware.AuthMiddleware(cfg), rateLimit("sellers"), strictJSON("sellers"), middleware.RequirePermission(cfg, config.PermSellerRead))
+		{
+			sellers.GET("/products", sellerHandler.ListProducts)
+			sellers.GET("/inventory/forecast", sellerHandler.GetInventoryForecast)
+			sellers.GET("/catalog/issues", sellerHandler.GetCatalogIssues)
+			sellers.GET("/orders", sellerHandler.ListOrders)
+			sellers.GET("/orders/:id", sellerHandler.GetOrder)
+			sellers.PUT("/orders/:id/status", middleware.RequirePermission(cfg, config.PermSellerOrdersShip), sellerHandler.UpdateOrderStatus)
+			sellers.GET("/vacation", sellerHandler.GetVacation)
+			sellers.PUT("/vacation", middleware.RequirePermission(cfg, config.PermProductsUpdate), sellerHandler.SetVacation)
+			sellers.DELETE("/vacation", middleware.RequirePermission(cfg, config.PermProductsUpdate), sellerHandler.EndVacation)
+		}
+
+		// Admin routes (all protected, permissions required per resource)
+		admin := apiGroup.Group("/admin")
+		admin.Use(ipFilter.AllowlistMiddleware(), middleware.AuthMiddleware(cfg), introspect, rateLimit("admin"), strictJSON("admin"))
+		{
+			transfers := admin.Group("/inventory/transfers")
+			transfers.Use(middleware.RequirePermission(cfg, config.PermInventoryTransfer))
+			transfers.POST("", transferHandler.CreateTransfer)
+			transfers.GET("/:id", transferHandler.GetTransfer)
+			transfers.POST("/:id/approve", transferHandler.ApproveTransfer)
+			transfers.POST("/:id/receive", transferHandler.ReceiveTransfer)
+
+			adminReturns := admin.Group("/returns")
+			adminReturns.Use(middleware.RequirePermission(cfg, config.PermReturnsManage))
+			adminReturns.GET("/:id", returnHandler.GetReturnAdmin)
+			adminReturns.POST("/:id/approve", returnHandler.ApproveReturn)
+			adminReturns.POST("/:id/reject", returnHandler.RejectReturn)
+			adminReturns.POST("/:id/complete", returnHandler.CompleteReturn)
+			adminReturns.POST("/:id/refund", returnHandler.RefundReturn)
+
+			cycleCounts := admin.Group("/inventory/cycle-counts")
+			cycleCounts.Use(middleware.RequirePermission(cfg, config.PermInventoryAdjust))
+			cycleCounts.POST("", cycleCountHandler.ScheduleCycleCount)
+			cycleCounts.GET("/:id", cycleCountHandler.GetCycleCount)
+			cycleCounts.POST("/:id/counts", cycleCountHandler.SubmitCounts)
+			cycleCounts.POST("/:id/adjustments", cycleCountHandler.ApplyAdjustments)
+
+			admin.GET("/inventory/adjustments", middleware.RequirePermission(cfg, config.PermInventoryAdjust), cycleCountHandler.ListAdjustments)
+
+			admin.GET("/orders/:id/packing-slip", middleware.RequirePermission(cfg, config.PermOrdersFulfill), fulfillmentHandler.PackingSlip)
+			admin.PUT("/orders/:id/items", middleware.RequirePermission(cfg, config.PermOrdersFulfill), orderHandler.UpdateOrderItems)
+			admin.POST("/pick-lists", middleware.RequirePermission(cfg, config.PermOrdersFulfill), fulfillmentHandler.PickList)
+
+			duplicates := admin.Group("/products/duplicates")
+			duplicates.Use(middleware.RequirePermission(cfg, config.PermProductsModerate))
+			duplicates.GET("", moderationHandler.ListDuplicateFlags)
+			duplicates.POST("/:id/resolve", moderationHandler.ResolveDuplicateFlag)
+
+			admin.POST("/search/reindex", middleware.RequirePermission(cfg, config.PermSearchManage), searchHandler.Reindex)
+			admin.GET("/jobs/:id", middleware.RequirePermission(cfg, config.PermSearchManage), searchHandler.GetJob)
+			admin.GET("/products/:id/history", middleware.RequirePermission(cfg, config.PermAuditRead), productHandler.ListProductHistory)
+			admin.PUT("/categories/:category/size-guide", middleware.RequirePermission(cfg, config.PermCatalogManage), sizeGuideHandler.SetCategorySizeGuide)
+
+			moderationQueue := admin.Group("/moderation/queue")
+			moderationQueue.Use(middleware.RequirePermission(cfg, config.PermContentModerate))
+			moderationQueue.GET("", moderationHandler.ListModerationQueue)
+			moderationQueue.POST("/:id/resolve", moderationHandler.ResolveModerationItem)
+
+			reports := admin.Group("/reports")
+			reports.Use(middleware.RequirePermission(cfg, config.PermContentModerate))
+			reports.GET("", reportHandler.ListReports)
+			reports.POST("/:id/resolve", reportHandler.ResolveReport)
+
+			riskAccounts := admin.Group("/risk/accounts")
+			riskAccounts.Use(middleware.RequirePermission(cfg, config.PermRiskManage))
+			riskAccounts.GET("", riskHandler.ListRiskyAccounts)
+			riskAccounts.GET("/:id", riskHandler.GetAccountRisk)
+			riskAccounts.POST("/:id/reset", riskHandler.ResetAccountRisk)
+
+			ipRules := admin.Group("/ip-rules")
+			ipRules.Use(middleware.RequirePermission(cfg, config.PermNetworkManage))
+			ipRules.GET("", ipRuleHandler.ListIPRules)
+			ipRules.POST("", ipRuleHandler.CreateIPRule)
+			ipRules.DELETE("/:id", ipRuleHandler.DeleteIPRule)
+
+			admin.PUT("/api-keys/:id/tier", middleware.RequirePermission(cfg, config.PermAPIKeysManage), apiKeyHandler.SetAPIKeyTier)
+
+			admin.POST("/tokens/revoke", middleware.RequirePermission(cfg, config.PermTokensRevoke), authHandler.RevokeToken)
+
+			admin.POST("/users/:id/credit", middleware.RequirePermission(cfg, config.PermCreditIssue), creditHandler.IssueCredit)
+
+			errorCodes := admin.Group("/errors")
+			errorCodes.Use(middleware.RequirePermission(cfg, config.PermErrorsRead))
+			errorCodes.GET("", errorCodeHandler.ListErrorCodes)
+			errorCodes.GET("/:code", errorCodeHandler.GetErrorCode)
+
+			logLevel := admin.Group("/loglevel")
+			logLevel.Use(middleware.RequirePermission(cfg, config.PermLoggingManage))
+			logLevel.GET("", loggingHandler.GetLogLevel)
+			logLevel.PUT("", loggingHandler.SetLogLevel)
+
+			backends := admin.Group("/backends")
+			backends.Use(middleware.RequirePermission(cfg, config.PermBackendsManage))
+			backends.GET("", backendHandler.ListBackendTargets)
+			backends.PUT("/:backend/target", backendHandler.SetBackendTarget)
+
+			admin.GET("/inflight", middleware.RequirePermission(cfg, config.PermInFlightRead), inFlightStatus)
 		}
 	}
 
@@ -66,6 +696,12 @@ func Setup(cfg *config.Config, grpcClients *grpcclient.Clients) *gin.Engine {
 	v1 := router.Group("/api/v1")
 	setupAPIRoutes(v1)
 
+	// OpenAPI description of the versioned API, including response limits
+	spec := openapi.Build(router.Routes(), limits)
+	router.GET("/openapi.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, spec)
+	})
+
 	// Handle 404
 	router.NoRoute(func(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{
@@ -93,9 +729,28 @@ func healthCheck(c *gin.Context) {
 	})
 }
 
+// inFlightStatus lists the requests this instance is still handling and
+// whether it is draining for shutdown
+// GET /api/v1/admin/inflight
+func inFlightStatus(c *gin.Context) {
+	requests := middleware.InFlightRequests()
+	c.JSON(http.StatusOK, gin.H{
+		"draining": draining.Load(),
+		"count":    len(requests),
+		"requests": requests,
+	})
+}
+
 // readinessCheck checks if all dependencies are ready
 func readinessCheck(grpcClients *grpcclient.Clients) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if draining.Load() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status": "draining",
+			})
+			return
+		}
+
 		// Check gRPC connections
 		status := grpcClients.HealthCheck(c.Request.Context())
 
@@ -111,11 +766,13 @@ func readinessCheck(grpcClients *grpcclient.Clients) gin.HandlerFunc {
 			c.JSON(http.StatusOK, gin.H{
 				"status":   "ready",
 				"services": status,
+				"regions":  grpcClients.RegionHealth(),
 			})
 		} else {
 			c.JSON(http.StatusServiceUnavailable, gin.H{
 				"status":   "not ready",
 				"services": status,
+				"regions":  grpcClients.RegionHealth(),
 			})
 		}
 	}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/middleware"
+)
+
+// routeScopes declares which OAuth2 scopes may call each route with a client
+// credentials token. Paths are relative to the API root; routes not listed
+// are closed to machine clients.
+var routeScopes = middleware.RouteScopes{
+	"GET /products":                            {config.ScopeCatalogRead},
+	"GET /products/:id":                        {config.ScopeCatalogRead},
+	"GET /products/:id/full":                   {config.ScopeCatalogRead},
+	"GET /products/:id/reviews":                {config.ScopeCatalogRead},
+	"GET /products/:id/questions":              {config.ScopeCatalogRead},
+	"GET /products/:id/questions/:qid/answers": {config.ScopeCatalogRead},
+	"PUT /products/:id/inventory":              {config.ScopeInventoryWrite},
+	"GET /deprecations":                        {config.ScopeCatalogRead, config.ScopeInventoryWrite},
+}
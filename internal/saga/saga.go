@@ -0,0 +1,87 @@
+package saga
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/ecommerce/be-api-gin/internal/logging"
+)
+
+var compensationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "saga_compensations_total",
+	Help: "Saga steps undone after a later step failed, by saga, step, and outcome (ok or failed).",
+}, []string{"saga", "step", "outcome"})
+
+// Step is one action of a saga and the action that undoes it
+type Step struct {
+	Name       string
+	Action     func(ctx context.Context) error
+	Compensate func(ctx context.Context) error // nil if the step has no effect to undo
+}
+
+// StepError reports which step of a saga failed
+type StepError struct {
+	Step string
+	Err  error
+}
+
+// Error names the failed step
+func (e *StepError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Step, e.Err)
+}
+
+// Unwrap returns the step's error
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// Saga runs steps in order, undoing the completed ones in reverse order if
+// one fails
+type Saga struct {
+	name  string
+	steps []Step
+}
+
+// New creates an empty saga; name labels its metrics and logs
+func New(name string) *Saga {
+	return &Saga{name: name}
+}
+
+// Add appends a step
+func (s *Saga) Add(step Step) *Saga {
+	s.steps = append(s.steps, step)
+	return s
+}
+
+// Run executes the steps. If one fails, the steps before it are compensated
+// and a *StepError is returned. Compensation runs even if ctx was cancelled,
+// since the effects to undo have already happened; compensations that fail
+// are logged and counted, and leave the remaining ones to run.
+func (s *Saga) Run(ctx context.Context) error {
+	for i, step := range s.steps {
+		if err := step.Action(ctx); err != nil {
+			s.compensate(context.WithoutCancel(ctx), s.steps[:i])
+			return &StepError{Step: step.Name, Err: err}
+		}
+	}
+	return nil
+}
+
+// compensate undoes completed steps, last first
+func (s *Saga) compensate(ctx context.Context, completed []Step) {
+	for i := len(completed) - 1; i >= 0; i-- {
+		step := completed[i]
+		if step.Compensate == nil {
+			continue
+		}
+		if err := step.Compensate(ctx); err != nil {
+			compensationsTotal.WithLabelValues(s.name, step.Name, "failed").Inc()
+			logging.FromContext(ctx).Error("Saga compensation failed", "saga", s.name, "step", step.Name, "error", err)
+			continue
+		}
+		compensationsTotal.WithLabelValues(s.name, step.Name, "ok").Inc()
+	}
+}
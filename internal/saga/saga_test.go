@@ -0,0 +1,137 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name        string
+		failAt      string // step whose action fails, if any
+		failUndo    string // step whose compensation fails, if any
+		noUndo      string // step without a compensation, if any
+		wantErr     bool
+		wantActions []string
+		wantUndone  []string
+	}{
+		{
+			name:        "all steps succeed",
+			wantActions: []string{"reserve", "create", "charge"},
+		},
+		{
+			name:        "first step fails",
+			failAt:      "reserve",
+			wantErr:     true,
+			wantActions: []string{"reserve"},
+		},
+		{
+			name:        "last step fails",
+			failAt:      "charge",
+			wantErr:     true,
+			wantActions: []string{"reserve", "create", "charge"},
+			wantUndone:  []string{"create", "reserve"},
+		},
+		{
+			name:        "steps without compensation are skipped",
+			failAt:      "charge",
+			noUndo:      "create",
+			wantErr:     true,
+			wantActions: []string{"reserve", "create", "charge"},
+			wantUndone:  []string{"reserve"},
+		},
+		{
+			name:        "failed compensation leaves the rest to run",
+			failAt:      "charge",
+			failUndo:    "create",
+			wantErr:     true,
+			wantActions: []string{"reserve", "create", "charge"},
+			wantUndone:  []string{"create", "reserve"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actions, undone []string
+			s := New("test")
+			for _, name := range []string{"reserve", "create", "charge"} {
+				name := name
+				step := Step{
+					Name: name,
+					Action: func(ctx context.Context) error {
+						actions = append(actions, name)
+						if name == tt.failAt {
+							return errFailed
+						}
+						return nil
+					},
+				}
+				if name != tt.noUndo {
+					step.Compensate = func(ctx context.Context) error {
+						undone = append(undone, name)
+						if name == tt.failUndo {
+							return errFailed
+						}
+						return nil
+					}
+				}
+				s.Add(step)
+			}
+
+			err := s.Run(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				var stepErr *StepError
+				if !errors.As(err, &stepErr) || stepErr.Step != tt.failAt {
+					t.Errorf("Run() error = %v, want a StepError for %q", err, tt.failAt)
+				}
+				if !errors.Is(err, errFailed) {
+					t.Errorf("Run() error = %v, want it to wrap the step's error", err)
+				}
+			}
+			if !reflect.DeepEqual(actions, tt.wantActions) {
+				t.Errorf("actions = %v, want %v", actions, tt.wantActions)
+			}
+			if !reflect.DeepEqual(undone, tt.wantUndone) {
+				t.Errorf("compensations = %v, want %v", undone, tt.wantUndone)
+			}
+		})
+	}
+}
+
+func TestRunCompensatesAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var compensateErr error
+	err := New("test").
+		Add(Step{
+			Name:   "reserve",
+			Action: func(ctx context.Context) error { return nil },
+			Compensate: func(ctx context.Context) error {
+				compensateErr = ctx.Err()
+				return nil
+			},
+		}).
+		Add(Step{
+			Name: "charge",
+			Action: func(ctx context.Context) error {
+				// The client goes away mid-saga
+				cancel()
+				return ctx.Err()
+			},
+		}).
+		Run(ctx)
+
+	if err == nil {
+		t.Fatal("Run() succeeded, want the cancelled step's error")
+	}
+	if compensateErr != nil {
+		t.Errorf("compensation ran with a cancelled context: %v", compensateErr)
+	}
+}
@@ -0,0 +1,201 @@
+package scanning
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+)
+
+// Scanning providers
+const (
+	ProviderClamAV = "clamav"
+	ProviderICAP   = "icap"
+)
+
+// clamAVChunkSize is the largest chunk streamed to clamd at a time
+const clamAVChunkSize = 64 << 10
+
+// Result is the outcome of scanning a file
+type Result struct {
+	Infected bool
+	Threat   string // the malware signature found, if any
+}
+
+// Scanner checks uploaded files for malware
+type Scanner interface {
+	Scan(ctx context.Context, data []byte) (*Result, error)
+}
+
+// NewScanner returns the scanner configured for the application, or nil if
+// scanning is disabled
+func NewScanner(cfg *config.Config) Scanner {
+	timeout := time.Duration(cfg.VirusScanTimeoutSec) * time.Second
+	switch cfg.VirusScanProvider {
+	case "":
+		return nil
+	case ProviderClamAV:
+		return &ClamAVScanner{Addr: cfg.VirusScanAddr, Timeout: timeout}
+	case ProviderICAP:
+		return &ICAPScanner{Addr: cfg.VirusScanAddr, Service: cfg.VirusScanICAPService, Timeout: timeout}
+	}
+	slog.Warn("Unknown virus scan provider, scanning disabled", "provider", cfg.VirusScanProvider)
+	return nil
+}
+
+// ClamAVScanner scans files with a clamd daemon using its INSTREAM command
+type ClamAVScanner struct {
+	Addr    string
+	Timeout time.Duration
+}
+
+// Scan streams data to clamd and parses its verdict
+func (s *ClamAVScanner) Scan(ctx context.Context, data []byte) (*Result, error) {
+	conn, err := dial(ctx, s.Addr, s.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// Each chunk is prefixed with its length; a zero length ends the stream
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, err
+	}
+	size := make([]byte, 4)
+	for len(data) > 0 {
+		chunk := data[:min(len(data), clamAVChunkSize)]
+		data = data[len(chunk):]
+		binary.BigEndian.PutUint32(size, uint32(len(chunk)))
+		if _, err := conn.Write(size); err != nil {
+			return nil, err
+		}
+		if _, err := conn.Write(chunk); err != nil {
+			return nil, err
+		}
+	}
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err := conn.Write(size); err != nil {
+		return nil, err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return parseClamAVReply(strings.TrimRight(reply, "\x00\n"))
+}
+
+// parseClamAVReply interprets a reply such as "stream: OK" or
+// "stream: Eicar-Signature FOUND"
+func parseClamAVReply(reply string) (*Result, error) {
+	_, verdict, _ := strings.Cut(reply, ": ")
+	switch {
+	case verdict == "OK":
+		return &Result{}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return &Result{Infected: true, Threat: strings.TrimSuffix(verdict, " FOUND")}, nil
+	}
+	return nil, fmt.Errorf("clamd replied %q", reply)
+}
+
+// ICAPScanner scans files with an ICAP server's RESPMOD service, as offered
+// by most commercial antivirus gateways
+type ICAPScanner struct {
+	Addr    string
+	Service string
+	Timeout time.Duration
+}
+
+// Scan sends data to the ICAP service as the body of an HTTP response. A
+// 204 reply means the service left it alone; a 200 means it would have
+// blocked or altered it.
+func (s *ICAPScanner) Scan(ctx context.Context, data []byte) (*Result, error) {
+	conn, err := dial(ctx, s.Addr, s.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	httpHeader := "HTTP/1.1 200 OK\r\nContent-Type: application/octet-stream\r\nContent-Length: " + strconv.Itoa(len(data)) + "\r\n\r\n"
+	var req bytes.Buffer
+	fmt.Fprintf(&req, "RESPMOD icap://%s/%s ICAP/1.0\r\n", s.Addr, strings.TrimPrefix(s.Service, "/"))
+	fmt.Fprintf(&req, "Host: %s\r\n", s.Addr)
+	req.WriteString("Allow: 204\r\n")
+	fmt.Fprintf(&req, "Encapsulated: res-hdr=0, res-body=%d\r\n\r\n", len(httpHeader))
+	req.WriteString(httpHeader)
+	if len(data) > 0 {
+		fmt.Fprintf(&req, "%x\r\n", len(data))
+		req.Write(data)
+		req.WriteString("\r\n")
+	}
+	req.WriteString("0\r\n\r\n")
+	if _, err := conn.Write(req.Bytes()); err != nil {
+		return nil, err
+	}
+
+	reader := textproto.NewReader(bufio.NewReader(conn))
+	status, err := reader.ReadLine()
+	if err != nil {
+		return nil, err
+	}
+	header, err := reader.ReadMIMEHeader()
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	_, code, _ := strings.Cut(status, " ")
+	switch {
+	case strings.HasPrefix(code, "204"):
+		return &Result{}, nil
+	case strings.HasPrefix(code, "200"):
+		return &Result{Infected: true, Threat: icapThreat(header)}, nil
+	}
+	return nil, fmt.Errorf("ICAP server replied %q", status)
+}
+
+// icapThreat extracts the threat name from the headers ICAP servers use to
+// report infections, e.g. "X-Infection-Found: Type=0; Resolution=2; Threat=Eicar;"
+func icapThreat(header textproto.MIMEHeader) string {
+	if found := header.Get("X-Infection-Found"); found != "" {
+		for _, field := range strings.Split(found, ";") {
+			if name, value, ok := strings.Cut(strings.TrimSpace(field), "="); ok && name == "Threat" {
+				return value
+			}
+		}
+	}
+	for _, name := range []string{"X-Virus-ID", "X-Violations-Found"} {
+		if value := header.Get(name); value != "" {
+			return value
+		}
+	}
+	return "unknown"
+}
+
+// dial connects to a scanning daemon, bounding the whole exchange by timeout
+func dial(ctx context.Context, addr string, timeout time.Duration) (net.Conn, error) {
+	if addr == "" {
+		return nil, errors.New("no virus scan address configured")
+	}
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+	return conn, nil
+}
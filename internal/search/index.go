@@ -0,0 +1,175 @@
+package search
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// refreshPageSize is how many products are fetched per page when rebuilding
+const refreshPageSize = 200
+
+// Token weights: a query word in the title counts more than in the category
+const (
+	nameWeight     = 2
+	categoryWeight = 1
+)
+
+// Index is a compact in-memory inverted index over product titles and
+// categories. It serves degraded search results when the search service is
+// unavailable.
+type Index struct {
+	mu       sync.RWMutex
+	products map[string]*models.Product
+	postings map[string]map[string]int // token -> product ID -> weight
+	builtAt  time.Time
+}
+
+// NewIndex creates an empty index
+func NewIndex() *Index {
+	return &Index{
+		products: make(map[string]*models.Product),
+		postings: make(map[string]map[string]int),
+	}
+}
+
+// Ready reports whether the index has been built at least once
+func (idx *Index) Ready() bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return !idx.builtAt.IsZero()
+}
+
+// Build replaces the index contents with the given products
+func (idx *Index) Build(products []*models.Product) {
+	byID := make(map[string]*models.Product, len(products))
+	postings := make(map[string]map[string]int)
+	add := func(text, id string, weight int) {
+		for _, token := range tokenize(text) {
+			if postings[token] == nil {
+				postings[token] = make(map[string]int)
+			}
+			postings[token][id] += weight
+		}
+	}
+	for _, p := range products {
+		byID[p.ID] = p
+		add(p.Name, p.ID, nameWeight)
+		add(p.Category, p.ID, categoryWeight)
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.products, idx.postings, idx.builtAt = byID, postings, time.Now()
+}
+
+// Search returns a page of products matching any query word, best matches
+// first, optionally limited to a category. An empty query matches every
+// product in the category. Only products keep accepts are matched, so pages
+// and the total count just those.
+func (idx *Index) Search(query, category string, page, limit int, keep func(*models.Product) bool) ([]*models.Product, int64) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	scores := make(map[string]int)
+	tokens := tokenize(query)
+	if len(tokens) == 0 {
+		for id := range idx.products {
+			scores[id] = 0
+		}
+	}
+	for _, token := range tokens {
+		for id, weight := range idx.postings[token] {
+			scores[id] += weight
+		}
+	}
+
+	matches := make([]*models.Product, 0, len(scores))
+	for id := range scores {
+		p := idx.products[id]
+		if (category != "" && !strings.EqualFold(p.Category, category)) || !keep(p) {
+			continue
+		}
+		matches = append(matches, p)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if scores[matches[i].ID] != scores[matches[j].ID] {
+			return scores[matches[i].ID] > scores[matches[j].ID]
+		}
+		return matches[i].Name < matches[j].Name
+	})
+
+	total := int64(len(matches))
+	start := (page - 1) * limit
+	if page < 1 || limit < 1 || start >= len(matches) {
+		return []*models.Product{}, total
+	}
+	end := min(start+limit, len(matches))
+
+	// Copy so callers can decorate results without changing the index
+	results := make([]*models.Product, 0, end-start)
+	for _, p := range matches[start:end] {
+		results = append(results, p.Clone())
+	}
+	return results, total
+}
+
+// Refresh rebuilds the index from the listing service every interval until
+// the context is cancelled. The previous index is kept if a rebuild fails.
+func (idx *Index) Refresh(ctx context.Context, clients *grpcclient.Clients, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := idx.rebuild(ctx, clients); err != nil {
+			slog.Warn("Failed to rebuild search fallback index", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// rebuild pages through the catalog and replaces the index
+func (idx *Index) rebuild(ctx context.Context, clients *grpcclient.Clients) error {
+	var products []*models.Product
+	for page := 1; ; page++ {
+		batch, total, err := clients.ListProducts(ctx, page, refreshPageSize, "", "", false)
+		if err != nil {
+			return err
+		}
+		products = append(products, batch...)
+		if len(batch) < refreshPageSize || int64(len(products)) >= total {
+			break
+		}
+	}
+
+	idx.Build(products)
+	slog.Debug("Rebuilt search fallback index", "products", len(products))
+	return nil
+}
+
+// tokenize lowercases text and splits it into words, dropping one-letter
+// words
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	tokens := words[:0]
+	for _, w := range words {
+		if len(w) > 1 {
+			tokens = append(tokens, w)
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,211 @@
+package segment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+)
+
+// Well-known customer segments. Resolvers may return others, which layers
+// can target by name.
+const (
+	NewCustomer = "new_customer"
+	VIP         = "vip"
+	AtRisk      = "at_risk"
+)
+
+var resolutionFailuresTotal = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "segment_resolution_failures_total",
+	Help: "Customer segment lookups that failed, leaving the request without segments.",
+})
+
+// Resolver returns the segments a customer belongs to
+type Resolver interface {
+	Resolve(ctx context.Context, userID string) ([]string, error)
+}
+
+// Backend provides the segments the user service assigns to customers
+type Backend interface {
+	GetCustomerSegments(ctx context.Context, userID string) ([]string, error)
+}
+
+// NewResolver returns the resolver configured for the application: the
+// rules engine when one is configured, otherwise the user service, with
+// results cached per customer when a cache TTL is set
+func NewResolver(cfg *config.Config, backend Backend) Resolver {
+	var resolver Resolver = backendResolver{backend: backend}
+	if cfg.SegmentRulesEngineURL != "" {
+		resolver = &HTTPRulesEngine{
+			URL:    cfg.SegmentRulesEngineURL,
+			APIKey: cfg.SegmentRulesEngineAPIKey,
+			Client: &http.Client{Timeout: 2 * time.Second},
+		}
+	}
+	if cfg.SegmentCacheTTLSec > 0 {
+		resolver = NewCachedResolver(resolver, time.Duration(cfg.SegmentCacheTTLSec)*time.Second)
+	}
+	return resolver
+}
+
+// backendResolver resolves segments with the user service
+type backendResolver struct {
+	backend Backend
+}
+
+// Resolve asks the user service for the customer's segments
+func (r backendResolver) Resolve(ctx context.Context, userID string) ([]string, error) {
+	return r.backend.GetCustomerSegments(ctx, userID)
+}
+
+// HTTPRulesEngine is a Resolver adapter for rules engines exposing a JSON
+// segment evaluation endpoint
+type HTTPRulesEngine struct {
+	URL    string
+	APIKey string
+	Client *http.Client
+}
+
+// Resolve asks the rules engine which segments the customer falls into
+func (e *HTTPRulesEngine) Resolve(ctx context.Context, userID string) ([]string, error) {
+	body, err := json.Marshal(map[string]string{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.APIKey)
+	}
+
+	resp, err := e.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("segment rules engine returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Segments []string `json:"segments"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.Segments, nil
+}
+
+// cachedSegments is a customer's segments and when they go stale
+type cachedSegments struct {
+	segments  []string
+	expiresAt time.Time
+}
+
+// CachedResolver remembers each customer's segments for a while, so a
+// request doesn't cost a lookup. Failed lookups are not cached.
+type CachedResolver struct {
+	resolver Resolver
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cachedSegments
+}
+
+// NewCachedResolver caches the segments resolver returns for ttl
+func NewCachedResolver(resolver Resolver, ttl time.Duration) *CachedResolver {
+	return &CachedResolver{
+		resolver: resolver,
+		ttl:      ttl,
+		entries:  make(map[string]cachedSegments),
+	}
+}
+
+// Resolve returns the customer's cached segments, resolving them if they
+// are missing or stale
+func (r *CachedResolver) Resolve(ctx context.Context, userID string) ([]string, error) {
+	now := time.Now()
+	r.mu.Lock()
+	entry, ok := r.entries[userID]
+	r.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.segments, nil
+	}
+
+	segments, err := r.resolver.Resolve(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, e := range r.entries {
+		if now.After(e.expiresAt) {
+			delete(r.entries, key)
+		}
+	}
+	r.entries[userID] = cachedSegments{segments: segments, expiresAt: now.Add(r.ttl)}
+	return segments, nil
+}
+
+// ctxKey is the context key for the request's segment lookup
+type ctxKey struct{}
+
+// lookup resolves a customer's segments the first time a layer asks for
+// them, so requests that don't target segments never pay for a lookup
+type lookup struct {
+	resolver Resolver
+	userID   string
+
+	once     sync.Once
+	segments []string
+}
+
+// NewContext returns a copy of ctx whose segments are those resolver
+// returns for userID
+func NewContext(ctx context.Context, resolver Resolver, userID string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &lookup{resolver: resolver, userID: userID})
+}
+
+// FromContext returns the segments of the customer making the request, or
+// none for guests. A failed lookup is logged and treated as no segments,
+// so an outage only loses targeting rather than failing requests.
+func FromContext(ctx context.Context) []string {
+	l, ok := ctx.Value(ctxKey{}).(*lookup)
+	if !ok {
+		return nil
+	}
+	l.once.Do(func() {
+		segments, err := l.resolver.Resolve(ctx, l.userID)
+		if err != nil {
+			resolutionFailuresTotal.Inc()
+			logging.FromContext(ctx).Warn("Failed to resolve customer segments", "user_id", l.userID, "error", err)
+			return
+		}
+		l.segments = segments
+	})
+	return l.segments
+}
+
+// Has reports whether the customer making the request is in a segment
+func Has(ctx context.Context, segment string) bool {
+	for _, s := range FromContext(ctx) {
+		if s == segment {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,287 @@
+package shipping
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ecommerce/be-api-gin/internal/calendar"
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/dispatch"
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// Rate providers
+const (
+	ProviderTable = "table"
+	ProviderHTTP  = "http"
+)
+
+// maxResponseBytes bounds the rate provider responses read
+const maxResponseBytes = 1 << 20
+
+// Item is an order line to ship, at its price after the order's discount
+type Item struct {
+	ProductID string  `json:"product_id"`
+	SellerID  string  `json:"seller_id,omitempty"`
+	Quantity  int32   `json:"quantity"`
+	Amount    float64 `json:"amount"`
+}
+
+// Request is an order to ship to Address
+type Request struct {
+	Currency string         `json:"currency"`
+	Address  models.Address `json:"address"`
+	Items    []Item         `json:"items"`
+}
+
+// Rate is what a shipping method costs for a request, and how many business
+// days it takes from dispatch
+type Rate struct {
+	Method         string  `json:"method"`
+	Carrier        string  `json:"carrier"`
+	Name           string  `json:"name"`
+	Price          float64 `json:"price"`
+	MinTransitDays int     `json:"min_transit_days"`
+	MaxTransitDays int     `json:"max_transit_days"`
+}
+
+// RateProvider prices the shipping methods available for orders
+type RateProvider interface {
+	Rates(ctx context.Context, req *Request) ([]Rate, error)
+}
+
+// NewRateProvider returns the rate provider configured for the application,
+// or nil if no shipping is quoted
+func NewRateProvider(cfg *config.Config) RateProvider {
+	switch cfg.ShippingRateProvider {
+	case "":
+		return nil
+	case ProviderTable:
+		return &TableProvider{Methods: cfg.ShippingRates}
+	case ProviderHTTP:
+		return &HTTPProvider{
+			URL:    cfg.ShippingRateURL,
+			APIKey: cfg.ShippingRateAPIKey,
+			Client: &http.Client{Timeout: time.Duration(cfg.ShippingRateTimeoutSec) * time.Second},
+		}
+	}
+	slog.Warn("Unknown shipping rate provider, no shipping will be quoted", "provider", cfg.ShippingRateProvider)
+	return nil
+}
+
+// TableProvider prices the configured shipping methods that serve the
+// destination country
+type TableProvider struct {
+	Methods []*config.ShippingMethod
+}
+
+// Rates returns the price of each method serving the request's address
+func (t *TableProvider) Rates(ctx context.Context, req *Request) ([]Rate, error) {
+	var subtotal float64
+	var units int32
+	for _, item := range req.Items {
+		subtotal += item.Amount
+		units += item.Quantity
+	}
+	subtotal = roundCents(subtotal)
+
+	rates := make([]Rate, 0, len(t.Methods))
+	for _, method := range t.Methods {
+		if !serves(method, req.Address.Country) {
+			continue
+		}
+		price := roundCents(method.BasePrice + method.PerItem*float64(units))
+		if method.FreeOver > 0 && subtotal >= method.FreeOver {
+			price = 0
+		}
+		rates = append(rates, Rate{
+			Method:         method.ID,
+			Carrier:        method.Carrier,
+			Name:           method.Name,
+			Price:          price,
+			MinTransitDays: method.MinTransitDays,
+			MaxTransitDays: method.MaxTransitDays,
+		})
+	}
+	return rates, nil
+}
+
+// serves reports whether a method ships to a country
+func serves(method *config.ShippingMethod, country string) bool {
+	if len(method.Countries) == 0 {
+		return true
+	}
+	for _, served := range method.Countries {
+		if strings.EqualFold(served, country) {
+			return true
+		}
+	}
+	return false
+}
+
+// HTTPProvider is a RateProvider adapter for carrier-rate services exposing
+// a JSON endpoint that takes a Request and returns {"rates": [Rate]}
+type HTTPProvider struct {
+	URL    string
+	APIKey string
+	Client *http.Client
+}
+
+// Rates asks the provider for the methods available for the request
+func (h *HTTPProvider) Rates(ctx context.Context, req *Request) ([]Rate, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if h.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+h.APIKey)
+	}
+
+	resp, err := h.Client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("shipping rate provider returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Rates []Rate `json:"rates"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.Rates, nil
+}
+
+// Quoter quotes shipping methods with the dates they are expected to
+// arrive between, counting transit days from when the order would be
+// dispatched and skipping weekends and the destination's holidays
+type Quoter struct {
+	provider RateProvider
+	planner  *dispatch.Planner
+	calendar *calendar.Calendar
+	currency string
+}
+
+// NewQuoter creates a quoter for the configured rate provider, or returns
+// nil if no shipping is quoted
+func NewQuoter(cfg *config.Config, planner *dispatch.Planner, holidays *calendar.Calendar) *Quoter {
+	provider := NewRateProvider(cfg)
+	if provider == nil {
+		return nil
+	}
+	return &Quoter{
+		provider: provider,
+		planner:  planner,
+		calendar: holidays,
+		currency: cfg.PaymentCurrency,
+	}
+}
+
+// Quote returns the methods available for shipping items ordered at now to
+// addr, cheapest first. Item amounts must already have the order's discount
+// taken off, so free shipping thresholds apply to what is paid.
+func (q *Quoter) Quote(ctx context.Context, addr models.Address, items []Item, now time.Time) (*models.ShippingQuotesResponse, error) {
+	rates, err := q.provider.Rates(ctx, &Request{
+		Currency: q.currency,
+		Address:  addr,
+		Items:    items,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	region := strings.ToUpper(addr.Country)
+	if addr.State != "" {
+		region += "-" + strings.ToUpper(addr.State)
+	}
+
+	// The order leaves when its last item is dispatched, or today when
+	// there are no dispatch promises
+	response := &models.ShippingQuotesResponse{Quotes: make([]models.ShippingQuote, 0, len(rates))}
+	loc := q.calendar.Location(region)
+	dispatched := now.In(loc)
+	promises := make([]*models.DispatchPromise, 0, len(items))
+	for _, item := range items {
+		promises = append(promises, q.planner.Promise(item.SellerID, now))
+	}
+	if promise := dispatch.Latest(promises...); promise != nil {
+		if date, err := time.ParseInLocation(calendar.DateLayout, promise.DispatchDate, loc); err == nil {
+			dispatched = date
+			response.DispatchDate = promise.DispatchDate
+		}
+	}
+
+	for _, rate := range rates {
+		response.Quotes = append(response.Quotes, models.ShippingQuote{
+			Method:           rate.Method,
+			Carrier:          rate.Carrier,
+			Name:             rate.Name,
+			Price:            roundCents(rate.Price),
+			Currency:         q.currency,
+			EarliestDelivery: q.addBusinessDays(region, dispatched, rate.MinTransitDays).Format(calendar.DateLayout),
+			LatestDelivery:   q.addBusinessDays(region, dispatched, rate.MaxTransitDays).Format(calendar.DateLayout),
+		})
+	}
+	sort.SliceStable(response.Quotes, func(i, j int) bool {
+		a, b := response.Quotes[i], response.Quotes[j]
+		if a.Price != b.Price {
+			return a.Price < b.Price
+		}
+		return a.EarliestDelivery < b.EarliestDelivery
+	})
+	return response, nil
+}
+
+// addBusinessDays returns the date days business days after from in a
+// region, skipping weekends and its holidays
+func (q *Quoter) addBusinessDays(region string, from time.Time, days int) time.Time {
+	date := from
+	for added := 0; added < days; {
+		// Step by calendar date rather than 24 hours to stay on the right
+		// day across daylight saving changes
+		date = time.Date(date.Year(), date.Month(), date.Day()+1, 0, 0, 0, 0, date.Location())
+		if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+			continue
+		}
+		if q.calendar.Holiday(region, date.Format(calendar.DateLayout)) != nil {
+			continue
+		}
+		added++
+	}
+	return date
+}
+
+// Choose returns the quote for method, or the cheapest quote if method is
+// empty, or nil if there is no such quote
+func Choose(quotes []models.ShippingQuote, method string) *models.ShippingQuote {
+	for i := range quotes {
+		if method == "" || quotes[i].Method == method {
+			return &quotes[i]
+		}
+	}
+	return nil
+}
+
+// roundCents rounds an amount to the cent
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,202 @@
+package slo
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+)
+
+// SLI names used as metric labels
+const (
+	SLIAvailability = "availability"
+	SLILatency      = "latency"
+)
+
+var (
+	sloRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "slo_requests_total",
+		Help: "Requests to routes covered by an SLO.",
+	}, []string{"slo"})
+
+	sloErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "slo_errors_total",
+		Help: "Requests to SLO routes that failed with a server error.",
+	}, []string{"slo"})
+
+	sloSlowRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "slo_slow_requests_total",
+		Help: "Requests to SLO routes slower than the SLO's latency threshold.",
+	}, []string{"slo"})
+
+	objectiveDesc = prometheus.NewDesc("slo_objective",
+		"Target ratio of good requests.", []string{"slo", "sli"}, nil)
+	ratioDesc = prometheus.NewDesc("slo_sli_ratio",
+		"Ratio of good requests over the tracking window.", []string{"slo", "sli"}, nil)
+	budgetDesc = prometheus.NewDesc("slo_error_budget_remaining_ratio",
+		"Share of the error budget left over the tracking window; negative once exhausted.", []string{"slo", "sli"}, nil)
+	burnRateDesc = prometheus.NewDesc("slo_burn_rate",
+		"Rate the error budget is being spent over the tracking window; 1 spends it exactly.", []string{"slo", "sli"}, nil)
+)
+
+// Tracker computes availability and latency SLIs for the configured routes
+// over a rolling window. It is a Prometheus collector exposing objectives,
+// SLIs, remaining error budget, and burn rates; latency percentiles are
+// exported as the slo_request_duration_seconds summary.
+type Tracker struct {
+	objectives map[string]*objective
+	ordered    []*objective
+	slotWidth  time.Duration
+	latency    *prometheus.SummaryVec
+}
+
+// objective tracks one SLO in a ring of time slots covering the window
+type objective struct {
+	slo       *config.SLO
+	threshold time.Duration
+
+	mu    sync.Mutex
+	slots []slot
+}
+
+// slot counts requests in one slice of the window
+type slot struct {
+	start  int64
+	total  int64
+	errors int64
+	slow   int64
+}
+
+// slotsPerWindow is how many slices the window is split into
+const slotsPerWindow = 60
+
+// NewTracker creates a tracker for the configured SLOs and registers it with
+// the default Prometheus registry
+func NewTracker(cfg *config.Config) *Tracker {
+	window := time.Duration(cfg.SLOWindowMinutes) * time.Minute
+	if window <= 0 {
+		window = time.Hour
+	}
+
+	t := &Tracker{
+		objectives: make(map[string]*objective, len(cfg.SLOs)),
+		slotWidth:  window / slotsPerWindow,
+		latency: promauto.NewSummaryVec(prometheus.SummaryOpts{
+			Name:       "slo_request_duration_seconds",
+			Help:       "Latency percentiles of SLO routes over the tracking window.",
+			Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.95: 0.005, 0.99: 0.001},
+			MaxAge:     window,
+			AgeBuckets: 5,
+		}, []string{"slo"}),
+	}
+	for _, s := range cfg.SLOs {
+		o := &objective{
+			slo:       s,
+			threshold: time.Duration(s.LatencyThresholdMs) * time.Millisecond,
+			slots:     make([]slot, slotsPerWindow),
+		}
+		t.objectives[s.Method+" "+s.Route] = o
+		t.ordered = append(t.ordered, o)
+	}
+
+	prometheus.MustRegister(t)
+	return t
+}
+
+// Observe records a finished request if its route is covered by an SLO
+func (t *Tracker) Observe(method, route string, status int, elapsed time.Duration) {
+	o, ok := t.objectives[method+" "+route]
+	if !ok {
+		return
+	}
+
+	failed := status >= 500
+	slow := o.threshold > 0 && elapsed > o.threshold
+
+	sloRequestsTotal.WithLabelValues(o.slo.Name).Inc()
+	if failed {
+		sloErrorsTotal.WithLabelValues(o.slo.Name).Inc()
+	}
+	if slow {
+		sloSlowRequestsTotal.WithLabelValues(o.slo.Name).Inc()
+	}
+	t.latency.WithLabelValues(o.slo.Name).Observe(elapsed.Seconds())
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	s := o.slot(time.Now(), t.slotWidth)
+	s.total++
+	if failed {
+		s.errors++
+	}
+	if slow {
+		s.slow++
+	}
+}
+
+// slot returns the slot for now, clearing it if it holds an older slice
+func (o *objective) slot(now time.Time, width time.Duration) *slot {
+	start := now.UnixNano() / int64(width)
+	s := &o.slots[start%int64(len(o.slots))]
+	if s.start != start {
+		*s = slot{start: start}
+	}
+	return s
+}
+
+// totals sums the slots that fall inside the window ending now
+func (o *objective) totals(now time.Time, width time.Duration) (total, errors, slow int64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	current := now.UnixNano() / int64(width)
+	for _, s := range o.slots {
+		if current-s.start < int64(len(o.slots)) {
+			total += s.total
+			errors += s.errors
+			slow += s.slow
+		}
+	}
+	return total, errors, slow
+}
+
+// Describe sends the descriptors of the computed SLO metrics
+func (t *Tracker) Describe(ch chan<- *prometheus.Desc) {
+	ch <- objectiveDesc
+	ch <- ratioDesc
+	ch <- budgetDesc
+	ch <- burnRateDesc
+}
+
+// Collect computes each SLO's SLIs, error budget, and burn rate over the
+// window
+func (t *Tracker) Collect(ch chan<- prometheus.Metric) {
+	now := time.Now()
+	for _, o := range t.ordered {
+		total, errors, slow := o.totals(now, t.slotWidth)
+		collectSLI(ch, o.slo.Name, SLIAvailability, o.slo.AvailabilityObjective, total, errors)
+		if o.threshold > 0 {
+			collectSLI(ch, o.slo.Name, SLILatency, o.slo.LatencyObjective, total, slow)
+		}
+	}
+}
+
+// collectSLI sends the metrics for one SLI. With no traffic the SLI is
+// perfect and no budget has been spent.
+func collectSLI(ch chan<- prometheus.Metric, name, sli string, target float64, total, bad int64) {
+	ratio, burnRate := 1.0, 0.0
+	if total > 0 {
+		ratio = 1 - float64(bad)/float64(total)
+		if target < 1 {
+			burnRate = (1 - ratio) / (1 - target)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(objectiveDesc, prometheus.GaugeValue, target, name, sli)
+	ch <- prometheus.MustNewConstMetric(ratioDesc, prometheus.GaugeValue, ratio, name, sli)
+	ch <- prometheus.MustNewConstMetric(budgetDesc, prometheus.GaugeValue, 1-burnRate, name, sli)
+	ch <- prometheus.MustNewConstMetric(burnRateDesc, prometheus.GaugeValue, burnRate, name, sli)
+}
@@ -0,0 +1,140 @@
+package suborder
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+	"github.com/ecommerce/be-api-gin/internal/tax"
+	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+)
+
+// Sellers returns the distinct sellers of an order's items, in the order
+// they first appear
+func Sellers(itemSellers []string) []string {
+	var sellers []string
+	seen := make(map[string]bool)
+	for _, seller := range itemSellers {
+		if !seen[seller] {
+			seen[seller] = true
+			sellers = append(sellers, seller)
+		}
+	}
+	return sellers
+}
+
+// Split divides a placed order into one sub-order per seller, given the
+// seller of each of its items in the order they were requested. Each
+// sub-order holds its seller's items and the reservations for them, their
+// tax, their share of the discount as it was spread for tax, and a share of
+// shipping in proportion to their value, so the sub-orders add up to the
+// order. It returns nil for an order from a single seller.
+func Split(order *models.Order, itemSellers []string) []*models.Order {
+	sellers := Sellers(itemSellers)
+	if len(sellers) < 2 || len(order.Items) != len(itemSellers) {
+		return nil
+	}
+
+	amounts := make([]float64, len(order.Items))
+	for i, item := range order.Items {
+		amounts[i] = item.TotalPrice
+	}
+	discounted := tax.SpreadDiscount(amounts, order.Discount)
+
+	subOrders := make([]*models.Order, len(sellers))
+	index := make(map[string]int, len(sellers))
+	subtotals := make([]float64, len(sellers))
+	for i, seller := range sellers {
+		index[seller] = i
+		subOrders[i] = &models.Order{
+			UserID:            order.UserID,
+			Status:            order.Status,
+			Currency:          order.Currency,
+			ShippingAddr:      order.ShippingAddr,
+			SignatureRequired: order.SignatureRequired,
+			GuestEmail:        order.GuestEmail,
+			ShippingMethod:    order.ShippingMethod,
+			SellerID:          seller,
+			ParentOrderID:     order.ID,
+			CouponCode:        order.CouponCode,
+			FreeShipping:      order.FreeShipping,
+		}
+	}
+	for i, item := range order.Items {
+		n := index[itemSellers[i]]
+		sub := subOrders[n]
+		sub.Items = append(sub.Items, item)
+		if i < len(order.ReservationIDs) {
+			sub.ReservationIDs = append(sub.ReservationIDs, order.ReservationIDs[i])
+		}
+		sub.Discount += amounts[i] - discounted[i]
+		sub.TaxAmount += item.Tax
+		subtotals[n] += item.TotalPrice
+	}
+
+	shipping := allocate(order.ShippingAmount, subtotals)
+	for i, sub := range subOrders {
+		sub.Discount = roundCents(sub.Discount)
+		sub.TaxAmount = roundCents(sub.TaxAmount)
+		sub.ShippingAmount = shipping[i]
+		sub.TotalAmount = roundCents(subtotals[i] - sub.Discount + sub.TaxAmount + sub.ShippingAmount)
+	}
+	return subOrders
+}
+
+// SetStatus moves the sub-orders of an order to status as the order moves
+// to it on being paid for or cancelled, cancelling them through the order
+// service so their sellers stop fulfilling them. Sub-orders already in the
+// status or cancelled are left alone. Callers move the order itself once
+// its sub-orders have moved, so a failure can be retried as a whole.
+func SetStatus(ctx context.Context, clients *grpcclient.Clients, orderID, userID string, status models.OrderStatus) error {
+	subOrders, err := clients.ListSubOrders(ctx, orderID, userID)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, sub := range subOrders {
+		if sub.Status == status || sub.Status == models.OrderStatusCancelled {
+			continue
+		}
+		if status == models.OrderStatusCancelled {
+			errs = append(errs, clients.CancelOrder(ctx, sub.ID, userID))
+			continue
+		}
+		_, err := clients.UpdateOrderStatus(ctx, sub.ID, userID, status)
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// allocate divides amount in proportion to weights, to the cent. The last
+// share absorbs rounding, and with no weight the amount is split evenly.
+func allocate(amount float64, weights []float64) []float64 {
+	shares := make([]float64, len(weights))
+	var total float64
+	for _, weight := range weights {
+		total += weight
+	}
+
+	remaining := amount
+	for i, weight := range weights {
+		share := amount / float64(len(weights))
+		if total > 0 {
+			share = amount * weight / total
+		}
+		share = roundCents(share)
+		if i == len(weights)-1 || share > remaining {
+			share = remaining
+		}
+		shares[i] = roundCents(share)
+		remaining = roundCents(remaining - share)
+	}
+	return shares
+}
+
+// roundCents rounds an amount to the nearest cent
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,177 @@
+package tax
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// Tax providers
+const (
+	ProviderFlat = "flat"
+	ProviderHTTP = "http"
+)
+
+// Line is an order line to tax, at what the customer pays for it after
+// discounts
+type Line struct {
+	ProductID string  `json:"product_id"`
+	Category  string  `json:"category,omitempty"`
+	Quantity  int32   `json:"quantity"`
+	Amount    float64 `json:"amount"`
+}
+
+// Request is an order to tax, shipped to Address
+type Request struct {
+	Currency string         `json:"currency"`
+	Address  models.Address `json:"address"`
+	Lines    []Line         `json:"lines"`
+}
+
+// Result is the tax on each line of a request, in the same order, and
+// their sum
+type Result struct {
+	Lines []float64 `json:"lines"`
+	Total float64   `json:"total"`
+}
+
+// Calculator computes the sales tax on orders
+type Calculator interface {
+	Calculate(ctx context.Context, req *Request) (*Result, error)
+}
+
+// NewCalculator returns the tax calculator configured for the application,
+// or nil if no tax is charged
+func NewCalculator(cfg *config.Config) Calculator {
+	switch cfg.TaxProvider {
+	case "":
+		return nil
+	case ProviderFlat:
+		return &FlatCalculator{Rate: cfg.TaxRate, Rates: cfg.TaxRates}
+	case ProviderHTTP:
+		return &HTTPCalculator{
+			URL:    cfg.TaxProviderURL,
+			APIKey: cfg.TaxAPIKey,
+			Client: &http.Client{Timeout: time.Duration(cfg.TaxTimeoutSec) * time.Second},
+		}
+	}
+	slog.Warn("Unknown tax provider, no tax will be charged", "provider", cfg.TaxProvider)
+	return nil
+}
+
+// SpreadDiscount takes an order-wide discount off line amounts in
+// proportion to each, so every line is taxed on what is actually paid for
+// it. The last line absorbs rounding.
+func SpreadDiscount(amounts []float64, discount float64) []float64 {
+	net := make([]float64, len(amounts))
+	copy(net, amounts)
+	var total float64
+	for _, amount := range amounts {
+		total += amount
+	}
+	if discount <= 0 || total <= 0 {
+		return net
+	}
+
+	discount = math.Min(discount, total)
+	remaining := discount
+	for i, amount := range amounts {
+		share := roundCents(discount * amount / total)
+		if i == len(amounts)-1 || share > remaining {
+			share = remaining
+		}
+		net[i] = roundCents(amount - share)
+		remaining = roundCents(remaining - share)
+	}
+	return net
+}
+
+// FlatCalculator charges a percentage rate, looked up by the address's
+// country and state (e.g. US-CA), then its country, then Rate
+type FlatCalculator struct {
+	Rate  float64
+	Rates map[string]float64
+}
+
+// Calculate applies the address's rate to each line, rounding each to the
+// cent so the lines add up to the total
+func (f *FlatCalculator) Calculate(ctx context.Context, req *Request) (*Result, error) {
+	rate := f.rateFor(req.Address)
+	result := &Result{Lines: make([]float64, len(req.Lines))}
+	for i, line := range req.Lines {
+		result.Lines[i] = roundCents(line.Amount * rate)
+		result.Total += result.Lines[i]
+	}
+	result.Total = roundCents(result.Total)
+	return result, nil
+}
+
+// rateFor returns the most specific rate configured for an address
+func (f *FlatCalculator) rateFor(addr models.Address) float64 {
+	country := strings.ToUpper(addr.Country)
+	if rate, ok := f.Rates[country+"-"+strings.ToUpper(addr.State)]; ok && addr.State != "" {
+		return rate
+	}
+	if rate, ok := f.Rates[country]; ok {
+		return rate
+	}
+	return f.Rate
+}
+
+// HTTPCalculator is a Calculator adapter for tax providers exposing a JSON
+// endpoint that takes a Request and returns a Result
+type HTTPCalculator struct {
+	URL    string
+	APIKey string
+	Client *http.Client
+}
+
+// Calculate asks the provider for the tax on the request's lines
+func (h *HTTPCalculator) Calculate(ctx context.Context, req *Request) (*Result, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if h.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+h.APIKey)
+	}
+
+	resp, err := h.Client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("tax provider returned status %d", resp.StatusCode)
+	}
+
+	var result Result
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if len(result.Lines) != len(req.Lines) {
+		return nil, fmt.Errorf("tax provider returned %d lines for %d", len(result.Lines), len(req.Lines))
+	}
+	return &result, nil
+}
+
+// roundCents rounds an amount to the cent
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,123 @@
+package tax
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+func TestSpreadDiscount(t *testing.T) {
+	tests := []struct {
+		name     string
+		amounts  []float64
+		discount float64
+		want     []float64
+	}{
+		{
+			name:     "no discount",
+			amounts:  []float64{10, 30},
+			discount: 0,
+			want:     []float64{10, 30},
+		},
+		{
+			name:     "in proportion to each line",
+			amounts:  []float64{10, 30},
+			discount: 4,
+			want:     []float64{9, 27},
+		},
+		{
+			name:     "last line absorbs rounding",
+			amounts:  []float64{10, 10, 10},
+			discount: 10,
+			want:     []float64{6.67, 6.67, 6.66},
+		},
+		{
+			name:     "discount above the total frees every line",
+			amounts:  []float64{10, 30},
+			discount: 50,
+			want:     []float64{0, 0},
+		},
+		{
+			name:     "zero total",
+			amounts:  []float64{0, 0},
+			discount: 5,
+			want:     []float64{0, 0},
+		},
+		{
+			name:     "no lines",
+			amounts:  []float64{},
+			discount: 5,
+			want:     []float64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SpreadDiscount(tt.amounts, tt.discount)
+			if len(got) != len(tt.want) {
+				t.Fatalf("SpreadDiscount() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if math.Abs(got[i]-tt.want[i]) > 0.001 {
+					t.Fatalf("SpreadDiscount() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSpreadDiscountLeavesAmountsAlone(t *testing.T) {
+	amounts := []float64{10, 30}
+	SpreadDiscount(amounts, 4)
+	if amounts[0] != 10 || amounts[1] != 30 {
+		t.Errorf("SpreadDiscount() modified its input: %v", amounts)
+	}
+}
+
+func TestSpreadDiscountAddsUp(t *testing.T) {
+	amounts := []float64{19.99, 5.01, 3.33, 0.5, 71.17}
+	for _, discount := range []float64{0.01, 1, 7.77, 33.33, 99.99} {
+		var total, net float64
+		for i, amount := range SpreadDiscount(amounts, discount) {
+			total += amounts[i]
+			net += amount
+		}
+		if math.Abs(total-discount-net) > 0.001 {
+			t.Errorf("discount %v: lines add up to %v, want %v", discount, net, total-discount)
+		}
+	}
+}
+
+func TestFlatCalculator(t *testing.T) {
+	calc := &FlatCalculator{
+		Rate:  0.1,
+		Rates: map[string]float64{"US-CA": 0.0725, "DE": 0.19},
+	}
+
+	tests := []struct {
+		name    string
+		address models.Address
+		want    float64
+	}{
+		{"country and state", models.Address{Country: "US", State: "CA"}, 7.25},
+		{"country", models.Address{Country: "DE", State: "BE"}, 19},
+		{"default rate", models.Address{Country: "US", State: "NY"}, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calc.Calculate(context.Background(), &Request{
+				Address: tt.address,
+				Lines:   []Line{{ProductID: "a", Quantity: 1, Amount: 60}, {ProductID: "b", Quantity: 2, Amount: 40}},
+			})
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+			if math.Abs(result.Total-tt.want) > 0.001 {
+				t.Errorf("Calculate() total = %v, want %v", result.Total, tt.want)
+			}
+		})
+	}
+}
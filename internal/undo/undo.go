@@ -0,0 +1,133 @@
+package undo
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// Store holds undoable actions until their undo window closes
+type Store interface {
+	// Save keeps an action until its ExpiresAt
+	Save(ctx context.Context, action *models.UndoableAction) error
+	// Take removes and returns a seller's action, or nil if it does not
+	// exist or its window has closed. Each action can be taken only once.
+	Take(ctx context.Context, sellerID, id string) (*models.UndoableAction, error)
+}
+
+// NewAction creates an action of the given type for a seller, undoable for
+// the length of window
+func NewAction(actionType, sellerID string, window time.Duration) (*models.UndoableAction, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &models.UndoableAction{
+		ID:        "act-" + hex.EncodeToString(buf),
+		Type:      actionType,
+		SellerID:  sellerID,
+		CreatedAt: models.NewTimestamp(now),
+		ExpiresAt: models.NewTimestamp(now.Add(window)),
+	}, nil
+}
+
+// MemoryStore is an in-process Store. Actions can only be undone through the
+// gateway instance that recorded them.
+type MemoryStore struct {
+	mu      sync.Mutex
+	actions map[string]*models.UndoableAction
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		actions: make(map[string]*models.UndoableAction),
+	}
+}
+
+// Save keeps an action until it expires
+func (s *MemoryStore) Save(ctx context.Context, action *models.UndoableAction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Purge expired entries
+	now := time.Now()
+	for key, a := range s.actions {
+		if now.After(a.ExpiresAt.Time) {
+			delete(s.actions, key)
+		}
+	}
+
+	s.actions[action.SellerID+":"+action.ID] = action
+	return nil
+}
+
+// Take removes and returns an unexpired action
+func (s *MemoryStore) Take(ctx context.Context, sellerID, id string) (*models.UndoableAction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := sellerID + ":" + id
+	action, ok := s.actions[key]
+	if !ok {
+		return nil, nil
+	}
+	delete(s.actions, key)
+	if time.Now().After(action.ExpiresAt.Time) {
+		return nil, nil
+	}
+	return action, nil
+}
+
+// RedisStore is a Store shared by all gateway replicas
+type RedisStore struct {
+	client *goredis.Client
+	prefix string
+}
+
+// NewRedisStore creates a store using client, namespacing keys with prefix
+func NewRedisStore(client *goredis.Client, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Save keeps an action until it expires
+func (s *RedisStore) Save(ctx context.Context, action *models.UndoableAction) error {
+	ttl := time.Until(action.ExpiresAt.Time)
+	if ttl <= 0 {
+		return nil
+	}
+	data, err := json.Marshal(action)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.prefix+action.SellerID+":"+action.ID, data, ttl).Err()
+}
+
+// Take atomically removes and returns an unexpired action
+func (s *RedisStore) Take(ctx context.Context, sellerID, id string) (*models.UndoableAction, error) {
+	data, err := s.client.GetDel(ctx, s.prefix+sellerID+":"+id).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var action models.UndoableAction
+	if err := json.Unmarshal(data, &action); err != nil {
+		return nil, err
+	}
+	return &action, nil
+}
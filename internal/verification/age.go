@@ -0,0 +1,138 @@
+package verification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+)
+
+// DateLayout is the expected format for dates of birth
+const DateLayout = "2006-01-02"
+
+// Common errors
+var (
+	ErrInvalidDateOfBirth = errors.New("invalid date of birth")
+	ErrUnderage           = errors.New("customer does not meet the minimum age requirement")
+	ErrIDNotVerified      = errors.New("identity verification failed")
+)
+
+// IDVerifier verifies a customer's identity and date of birth with an
+// external ID-verification provider
+type IDVerifier interface {
+	Verify(ctx context.Context, token string, dateOfBirth time.Time) (bool, error)
+}
+
+// NewIDVerifier returns the ID verifier configured for the application,
+// or nil if no provider is configured
+func NewIDVerifier(cfg *config.Config) IDVerifier {
+	if cfg.IDVerificationURL == "" {
+		return nil
+	}
+	return &HTTPIDVerifier{
+		URL:    cfg.IDVerificationURL,
+		APIKey: cfg.IDVerificationAPIKey,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ParseDateOfBirth parses a date of birth in YYYY-MM-DD format
+func ParseDateOfBirth(value string) (time.Time, error) {
+	dob, err := time.Parse(DateLayout, value)
+	if err != nil || dob.After(time.Now()) {
+		return time.Time{}, ErrInvalidDateOfBirth
+	}
+	return dob, nil
+}
+
+// AgeOn returns the age in whole years of someone born on dob at the given time
+func AgeOn(dob, now time.Time) int {
+	age := now.Year() - dob.Year()
+	if now.Month() < dob.Month() || (now.Month() == dob.Month() && now.Day() < dob.Day()) {
+		age--
+	}
+	return age
+}
+
+// CheckAge verifies the customer meets minAge, consulting the ID verifier
+// when one is configured
+func CheckAge(ctx context.Context, verifier IDVerifier, dateOfBirth, idToken string, minAge int) error {
+	if minAge <= 0 {
+		return nil
+	}
+
+	dob, err := ParseDateOfBirth(dateOfBirth)
+	if err != nil {
+		return err
+	}
+	if AgeOn(dob, time.Now()) < minAge {
+		return ErrUnderage
+	}
+
+	if verifier == nil {
+		return nil
+	}
+	if idToken == "" {
+		return ErrIDNotVerified
+	}
+	verified, err := verifier.Verify(ctx, idToken, dob)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return ErrIDNotVerified
+	}
+	return nil
+}
+
+// HTTPIDVerifier is an IDVerifier adapter for providers exposing a JSON
+// verification endpoint
+type HTTPIDVerifier struct {
+	URL    string
+	APIKey string
+	Client *http.Client
+}
+
+// Verify asks the provider to confirm the verification token matches the
+// given date of birth
+func (v *HTTPIDVerifier) Verify(ctx context.Context, token string, dateOfBirth time.Time) (bool, error) {
+	body, err := json.Marshal(map[string]string{
+		"token":         token,
+		"date_of_birth": dateOfBirth.Format(DateLayout),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if v.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+v.APIKey)
+	}
+
+	resp, err := v.Client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("id verification provider returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Verified bool `json:"verified"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, err
+	}
+	return result.Verified, nil
+}
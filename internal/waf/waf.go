@@ -0,0 +1,165 @@
+package waf
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/ecommerce/be-api-gin/internal/redact"
+)
+
+// Inspection modes
+const (
+	ModeOff   = "off"
+	ModeLog   = "log"
+	ModeBlock = "block"
+)
+
+// Rule IDs
+const (
+	RuleSQLiUnion       = "sqli-union"
+	RuleSQLiTautology   = "sqli-tautology"
+	RuleSQLiComment     = "sqli-comment"
+	RuleSQLiStacked     = "sqli-stacked"
+	RuleSQLiTiming      = "sqli-timing"
+	RuleXSSScript       = "xss-script"
+	RuleXSSHandler      = "xss-event-handler"
+	RuleXSSURI          = "xss-javascript-uri"
+	RulePathTraversal   = "path-traversal"
+	RuleOversizedHeader = "oversized-header"
+)
+
+// Rule is a payload signature checked against request paths and parameters
+type Rule struct {
+	ID      string
+	Pattern *regexp.Regexp
+}
+
+// DefaultRules are the signatures applied to every request
+var DefaultRules = []Rule{
+	{RuleSQLiUnion, regexp.MustCompile(`(?i)\bunion\b(\s|/\*.*?\*/)+(all(\s|/\*.*?\*/)+)?select\b`)},
+	{RuleSQLiTautology, regexp.MustCompile(`(?i)['"]\s*(or|and)\s+['"]?[\w-]+['"]?\s*(=|like)\s*['"]?[\w-]+`)},
+	{RuleSQLiComment, regexp.MustCompile(`['"]\s*;?\s*(--|#|/\*)`)},
+	{RuleSQLiStacked, regexp.MustCompile(`(?i);\s*(drop|delete|insert|update|alter|truncate|exec)\s`)},
+	{RuleSQLiTiming, regexp.MustCompile(`(?i)\b(sleep|benchmark|pg_sleep)\s*\(|\bwaitfor\s+delay\b`)},
+	{RuleXSSScript, regexp.MustCompile(`(?i)<\s*/?\s*script\b`)},
+	{RuleXSSHandler, regexp.MustCompile(`(?i)<[^>]*\bon[a-z]+\s*=`)},
+	{RuleXSSURI, regexp.MustCompile(`(?i)\bjavascript\s*:`)},
+	{RulePathTraversal, regexp.MustCompile(`(?i)(^|[/\\])\.\.([/\\]|$)|%2e%2e(%2f|%5c|/|\\)|\.\.%2f|\.\.%5c`)},
+}
+
+// maxReportedValue bounds the matched value kept in a Match
+const maxReportedValue = 100
+
+// Match describes a rule that matched part of a request
+type Match struct {
+	RuleID   string
+	Location string // e.g. "path", "query:search", "header:Cookie"
+	Value    string // the offending value, truncated, or redacted if sensitive
+}
+
+// exclusion disables a rule, or every rule, under a path prefix
+type exclusion struct {
+	ruleID     string // empty for every rule
+	pathPrefix string // empty for every path
+}
+
+// Engine inspects requests against a rule set
+type Engine struct {
+	rules          []Rule
+	maxHeaderBytes int
+	exclusions     []exclusion
+}
+
+// NewEngine creates an engine with the default rules. Exclusions take the
+// form "rule-id", "/path/prefix", or "rule-id@/path/prefix". Header values
+// larger than maxHeaderBytes match the oversized-header rule; zero disables
+// the check.
+func NewEngine(maxHeaderBytes int, exclusions []string) *Engine {
+	e := &Engine{
+		rules:          DefaultRules,
+		maxHeaderBytes: maxHeaderBytes,
+	}
+	for _, entry := range exclusions {
+		entry = strings.TrimSpace(entry)
+		switch {
+		case entry == "":
+			continue
+		case strings.HasPrefix(entry, "/"):
+			e.exclusions = append(e.exclusions, exclusion{pathPrefix: entry})
+		default:
+			ruleID, prefix, _ := strings.Cut(entry, "@")
+			e.exclusions = append(e.exclusions, exclusion{ruleID: ruleID, pathPrefix: prefix})
+		}
+	}
+	return e
+}
+
+// Inspect returns every rule matched by the request's path, query
+// parameters, and headers, minus exclusions
+func (e *Engine) Inspect(r *http.Request) []Match {
+	var matches []Match
+	path := r.URL.Path
+
+	check := func(location, value string, sensitive bool) {
+		for _, rule := range e.rules {
+			if rule.Pattern.MatchString(value) && !e.excluded(rule.ID, path) {
+				matches = append(matches, Match{RuleID: rule.ID, Location: location, Value: reported(value, sensitive)})
+			}
+		}
+	}
+
+	// Check the raw path too so encoded traversal sequences are caught
+	check("path", path, false)
+	if raw := r.URL.EscapedPath(); raw != path {
+		check("path", raw, false)
+	}
+
+	query, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		// Fall back to the raw query string when it can't be decoded
+		check("query", r.URL.RawQuery, true)
+	}
+	for key, values := range query {
+		check("query:"+key, key, false)
+		for _, value := range values {
+			check("query:"+key, value, redact.IsSensitive(key))
+		}
+	}
+
+	if e.maxHeaderBytes > 0 && !e.excluded(RuleOversizedHeader, path) {
+		for name, values := range r.Header {
+			for _, value := range values {
+				if len(value) > e.maxHeaderBytes {
+					matches = append(matches, Match{RuleID: RuleOversizedHeader, Location: "header:" + name, Value: reported(value, redact.IsSensitive(name))})
+				}
+			}
+		}
+	}
+
+	return matches
+}
+
+// excluded reports whether ruleID is disabled for path
+func (e *Engine) excluded(ruleID, path string) bool {
+	for _, ex := range e.exclusions {
+		if (ex.ruleID == "" || ex.ruleID == ruleID) && strings.HasPrefix(path, ex.pathPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// reported returns a matched value as it may be logged: redacted if it
+// holds credentials, such as an Authorization or Cookie header, and
+// truncated otherwise
+func reported(value string, sensitive bool) string {
+	if sensitive {
+		return redact.Placeholder
+	}
+	if len(value) > maxReportedValue {
+		return value[:maxReportedValue] + "..."
+	}
+	return value
+}
@@ -1,28 +1,85 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/ecommerce/be-api-gin/internal/cart"
 	"github.com/ecommerce/be-api-gin/internal/config"
+	"github.com/ecommerce/be-api-gin/internal/diagnostics"
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/middleware"
+	"github.com/ecommerce/be-api-gin/internal/models"
 	"github.com/ecommerce/be-api-gin/internal/routes"
+	"github.com/ecommerce/be-api-gin/internal/tracing"
 	grpcclient "github.com/ecommerce/be-api-gin/pkg/grpc"
+	redisclient "github.com/ecommerce/be-api-gin/pkg/redis"
 )
 
 func main() {
 	// Load configuration
 	cfg := config.Load()
-	log.Printf("Starting API Gateway on port %s", cfg.Port)
+	logging.Setup(cfg)
+	slog.Info("Starting API Gateway", "port", cfg.Port)
+	if err := cart.ValidateStrategy(cfg.CartMergeStrategy); err != nil {
+		fatal("Invalid CART_MERGE_STRATEGY", err)
+	}
+	models.SetCategories(cfg.ProductCategories)
+
+	// Initialize tracing before any clients so their calls are instrumented
+	shutdownTracing, err := tracing.Init(context.Background(), cfg)
+	if err != nil {
+		fatal("Failed to initialize tracing", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Warn("Failed to flush traces", "error", err)
+		}
+	}()
 
-	// Initialize gRPC clients
+	// Initialize gRPC clients. They are closed on return from main, after
+	// the server has finished in-flight requests.
 	grpcClients, err := grpcclient.NewClients(cfg)
 	if err != nil {
-		log.Fatalf("Failed to initialize gRPC clients: %v", err)
+		fatal("Failed to initialize gRPC clients", err)
 	}
 	defer grpcClients.Close()
 
+	// Initialize Redis client (optional)
+	redisClient, err := redisclient.NewClient(cfg)
+	if err != nil {
+		fatal("Failed to initialize Redis client", err)
+	}
+	if redisClient != nil {
+		defer redisClient.Close()
+	}
+
+	// Start the admin listener for profiling (optional)
+	if adminServer := diagnostics.NewServer(cfg); adminServer != nil {
+		go func() {
+			slog.Info("Admin listener started", "addr", adminServer.Addr)
+			if err := adminServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("Admin listener failed", "error", err)
+			}
+		}()
+		defer adminServer.Close()
+	}
+
+	// Termination signals cancel ctx, which stops the background workers
+	// before the server drains
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
 	// Setup routes
-	router := routes.Setup(cfg, grpcClients)
+	router := routes.Setup(ctx, cfg, grpcClients, redisClient)
 
 	// Start server
 	port := cfg.Port
@@ -33,8 +90,84 @@ func main() {
 		}
 	}
 
-	log.Printf("API Gateway listening on port %s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	server := &http.Server{
+		Addr:    ":" + port,
+		Handler: router,
+	}
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("API Gateway listening", "port", port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	// Wait for a termination signal
+	select {
+	case err := <-serverErr:
+		fatal("Failed to start server", err)
+	case <-ctx.Done():
+	}
+	stop()
+
+	shutdown(server, cfg)
+}
+
+// inFlightLogInterval is how often shutdown logs the requests it is waiting on
+const inFlightLogInterval = 5 * time.Second
+
+// maxLoggedInFlight bounds the requests listed in each shutdown log entry
+const maxLoggedInFlight = 10
+
+// shutdown drains the server: it fails readiness checks for the drain period
+// so load balancers stop routing new requests here, then stops accepting
+// connections and waits for in-flight requests, up to the shutdown timeout.
+// While waiting it logs the oldest requests still running.
+func shutdown(server *http.Server, cfg *config.Config) {
+	slog.Info("Shutting down, draining connections", "drain_seconds", cfg.ShutdownDrainSec)
+
+	routes.StartDraining()
+	server.SetKeepAlivesEnabled(false)
+	time.Sleep(time.Duration(cfg.ShutdownDrainSec) * time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeoutSec)*time.Second)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(inFlightLogInterval)
+		defer ticker.Stop()
+		for {
+			logInFlight(slog.LevelInfo, "Waiting for in-flight requests")
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	err := server.Shutdown(ctx)
+	close(done)
+	if err != nil {
+		logInFlight(slog.LevelError, "Shutdown timed out with requests in flight")
+		return
+	}
+	slog.Info("Server stopped")
+}
+
+// logInFlight logs the number of in-flight requests and the oldest of them,
+// if there are any
+func logInFlight(level slog.Level, msg string) {
+	requests := middleware.InFlightRequests()
+	if len(requests) == 0 {
+		return
 	}
+	slog.Log(context.Background(), level, msg, "count", len(requests), "requests", requests[:min(len(requests), maxLoggedInFlight)])
+}
+
+// fatal logs an error and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
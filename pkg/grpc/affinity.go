@@ -0,0 +1,106 @@
+package grpc
+
+import (
+	"hash/fnv"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+
+	"github.com/ecommerce/be-api-gin/internal/affinity"
+)
+
+// AffinityBalancerName is the gRPC load balancing policy that sends a user's
+// calls to the same backend replica
+const AffinityBalancerName = "user_affinity"
+
+// affinityVirtualNodes is the number of points each replica has on the hash
+// ring, which evens out the share of users each replica gets
+const affinityVirtualNodes = 100
+
+func init() {
+	balancer.Register(base.NewBalancerBuilder(AffinityBalancerName, affinityPickerBuilder{}, base.Config{HealthCheck: true}))
+}
+
+// affinityDialOption makes a connection balance calls with the affinity
+// policy. Its target must resolve to every replica, for example with the
+// dns:/// scheme.
+func affinityDialOption() grpc.DialOption {
+	return grpc.WithDefaultServiceConfig(`{"loadBalancingConfig":[{"` + AffinityBalancerName + `":{}}]}`)
+}
+
+// affinityPickerBuilder builds a consistent-hash picker over the ready
+// replicas. The base balancer rebuilds it whenever replicas join, leave, or
+// change readiness, which moves only the users of the replicas that changed.
+type affinityPickerBuilder struct{}
+
+// Build places the ready replicas on a hash ring
+func (affinityPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+
+	p := &affinityPicker{}
+	for sc, scInfo := range info.ReadySCs {
+		p.subConns = append(p.subConns, sc)
+		for i := 0; i < affinityVirtualNodes; i++ {
+			p.ring = append(p.ring, ringPoint{
+				hash:    affinityHash(scInfo.Address.Addr + "#" + strconv.Itoa(i)),
+				subConn: sc,
+			})
+		}
+	}
+	sort.Slice(p.ring, func(i, j int) bool { return p.ring[i].hash < p.ring[j].hash })
+
+	slog.Debug("Affinity ring rebuilt", "replicas", len(info.ReadySCs))
+	return p
+}
+
+// ringPoint is a replica's position on the hash ring
+type ringPoint struct {
+	hash    uint64
+	subConn balancer.SubConn
+}
+
+// affinityPicker sends calls with an affinity key to the first replica at or
+// after the key's hash on the ring, and other calls round-robin
+type affinityPicker struct {
+	ring     []ringPoint
+	subConns []balancer.SubConn
+	next     uint32
+}
+
+// Pick chooses the replica for a call
+func (p *affinityPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	key := affinity.FromContext(info.Ctx)
+	if key == "" {
+		n := atomic.AddUint32(&p.next, 1)
+		return balancer.PickResult{SubConn: p.subConns[(n-1)%uint32(len(p.subConns))]}, nil
+	}
+
+	h := affinityHash(key)
+	i := sort.Search(len(p.ring), func(i int) bool { return p.ring[i].hash >= h })
+	if i == len(p.ring) {
+		i = 0
+	}
+	return balancer.PickResult{SubConn: p.ring[i].subConn}, nil
+}
+
+// affinityHash hashes a ring point or affinity key. FNV alone leaves
+// similar strings close together, so its output is mixed to spread them
+// around the ring.
+func affinityHash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
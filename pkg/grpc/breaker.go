@@ -0,0 +1,177 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/ecommerce/be-api-gin/internal/retryhint"
+)
+
+var (
+	grpcClientBreakerOpen = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "grpc_client_breaker_open",
+		Help: "Whether the circuit breaker to each backend service is open (1) or closed (0), by region.",
+	}, []string{"backend", "backend_region"})
+
+	grpcClientBreakerRejectedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_client_breaker_rejected_total",
+		Help: "Unary calls failed fast because a backend service's circuit breaker was open, by region.",
+	}, []string{"backend", "backend_region"})
+)
+
+// breaker stops calling a backend service after consecutive failures that
+// suggest it is down, failing calls fast until a cooldown passes. A single
+// trial call is then let through, and closes the breaker if it succeeds.
+type breaker struct {
+	backend   string
+	region    string
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+// newBreaker creates a breaker for a backend's deployment in region, opening
+// after threshold consecutive failures, or nil if threshold is not positive
+func newBreaker(backend, region string, threshold int, cooldown time.Duration) *breaker {
+	if threshold <= 0 {
+		return nil
+	}
+	return &breaker{
+		backend:   backend,
+		region:    region,
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// allow reports whether a call may proceed, and whether it is the trial
+// call, or how long until the breaker lets calls through again
+func (b *breaker) allow() (wait time.Duration, trial, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openUntil.IsZero() {
+		return 0, false, true
+	}
+	if remaining := time.Until(b.openUntil); remaining > 0 {
+		return remaining, false, false
+	}
+	if b.trial {
+		return time.Second, false, false
+	}
+	b.trial = true
+	return 0, true, true
+}
+
+// endTrial lets another trial call through once the current one is over,
+// whether or not its outcome was recorded
+func (b *breaker) endTrial() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+// isOpen reports whether calls are currently failed fast
+func (b *breaker) isOpen() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.openUntil.IsZero() && time.Now().Before(b.openUntil)
+}
+
+// record updates the breaker with a call's outcome
+func (b *breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !isBackendFailure(err) {
+		b.failures = 0
+		if !b.openUntil.IsZero() {
+			b.openUntil = time.Time{}
+			grpcClientBreakerOpen.WithLabelValues(b.backend, b.region).Set(0)
+		}
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.threshold || !b.openUntil.IsZero() {
+		b.openUntil = time.Now().Add(b.cooldown)
+		grpcClientBreakerOpen.WithLabelValues(b.backend, b.region).Set(1)
+	}
+}
+
+// isBackendFailure reports whether an error suggests the backend itself is
+// failing, rather than rejecting the request
+func isBackendFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown:
+		return true
+	default:
+		return false
+	}
+}
+
+// unaryInterceptor fails calls fast while the breaker is open. The error
+// carries RetryInfo with the time until the breaker lets calls through.
+// Calls whose context is cancelled, such as hedges that lost or requests
+// the client gave up on, count as neither success nor failure.
+func (b *breaker) unaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	wait, trial, ok := b.allow()
+	if !ok {
+		grpcClientBreakerRejectedTotal.WithLabelValues(b.backend, b.region).Inc()
+		st := status.Newf(codes.Unavailable, "%s is unavailable, circuit breaker open", b.backend)
+		if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(wait.Round(time.Second))}); err == nil {
+			st = detailed
+		}
+		return st.Err()
+	}
+	if trial {
+		defer b.endTrial()
+	}
+
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if ctx.Err() == nil {
+		b.record(err)
+	}
+	return err
+}
+
+// dialOptions returns the options installing the breaker on a connection
+func (b *breaker) dialOptions() []grpc.DialOption {
+	if b == nil {
+		return nil
+	}
+	return []grpc.DialOption{grpc.WithChainUnaryInterceptor(b.unaryInterceptor)}
+}
+
+// retryHintUnaryInterceptor records failed calls so error responses can tell
+// clients whether to retry, using any RetryInfo the failure carries
+func retryHintUnaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if err != nil {
+		st := status.Convert(err)
+		var retryAfter time.Duration
+		for _, detail := range st.Details() {
+			if info, ok := detail.(*errdetails.RetryInfo); ok {
+				retryAfter = info.GetRetryDelay().AsDuration()
+			}
+		}
+		retryhint.RecordFailure(ctx, st.Code(), retryAfter)
+	}
+	return err
+}
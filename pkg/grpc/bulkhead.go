@@ -0,0 +1,116 @@
+package grpc
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	grpcClientBulkheadInUse = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "grpc_client_bulkhead_in_use",
+		Help: "Concurrent unary calls in progress to each backend service.",
+	}, []string{"backend"})
+
+	grpcClientBulkheadQueued = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "grpc_client_bulkhead_queued",
+		Help: "Unary calls waiting for a free slot to each backend service.",
+	}, []string{"backend"})
+
+	grpcClientBulkheadRejectedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_client_bulkhead_rejected_total",
+		Help: "Unary calls rejected because a backend service's concurrency limit was reached.",
+	}, []string{"backend", "reason"})
+)
+
+// bulkhead caps concurrent calls to one backend service so a stalled
+// backend ties up at most its own share of the gateway's goroutines. Calls
+// over the limit wait in a bounded queue for a free slot, and are rejected
+// when the queue is full or the wait times out.
+type bulkhead struct {
+	backend  string
+	slots    chan struct{}
+	maxQueue int64
+	timeout  time.Duration
+	queued   atomic.Int64
+}
+
+// newBulkhead creates a bulkhead allowing limit concurrent calls, or nil if
+// limit is not positive
+func newBulkhead(backend string, limit, maxQueue int, timeout time.Duration) *bulkhead {
+	if limit <= 0 {
+		return nil
+	}
+	return &bulkhead{
+		backend:  backend,
+		slots:    make(chan struct{}, limit),
+		maxQueue: int64(maxQueue),
+		timeout:  timeout,
+	}
+}
+
+// acquire takes a slot, waiting in the queue if all are in use. The returned
+// function releases the slot.
+func (b *bulkhead) acquire(ctx context.Context) (func(), error) {
+	select {
+	case b.slots <- struct{}{}:
+		return b.held(), nil
+	default:
+	}
+
+	if b.queued.Add(1) > b.maxQueue {
+		b.queued.Add(-1)
+		grpcClientBulkheadRejectedTotal.WithLabelValues(b.backend, "queue_full").Inc()
+		return nil, status.Errorf(codes.ResourceExhausted, "%s is at its concurrency limit", b.backend)
+	}
+	grpcClientBulkheadQueued.WithLabelValues(b.backend).Inc()
+	defer func() {
+		b.queued.Add(-1)
+		grpcClientBulkheadQueued.WithLabelValues(b.backend).Dec()
+	}()
+
+	timer := time.NewTimer(b.timeout)
+	defer timer.Stop()
+	select {
+	case b.slots <- struct{}{}:
+		return b.held(), nil
+	case <-timer.C:
+		grpcClientBulkheadRejectedTotal.WithLabelValues(b.backend, "queue_timeout").Inc()
+		return nil, status.Errorf(codes.ResourceExhausted, "timed out waiting for a free %s call slot", b.backend)
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// held records a taken slot and returns its release function
+func (b *bulkhead) held() func() {
+	grpcClientBulkheadInUse.WithLabelValues(b.backend).Inc()
+	return func() {
+		<-b.slots
+		grpcClientBulkheadInUse.WithLabelValues(b.backend).Dec()
+	}
+}
+
+// unaryInterceptor runs each unary call inside the bulkhead
+func (b *bulkhead) unaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	release, err := b.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// dialOptions returns the options installing the bulkhead on a connection
+func (b *bulkhead) dialOptions() []grpc.DialOption {
+	if b == nil {
+		return nil
+	}
+	return []grpc.DialOption{grpc.WithChainUnaryInterceptor(b.unaryInterceptor)}
+}
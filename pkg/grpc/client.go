@@ -3,9 +3,13 @@ package grpc
 import (
 	"context"
 	"errors"
-	"log"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
 	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
@@ -20,76 +24,145 @@ var (
 	ErrNotFound     = errors.New("resource not found")
 	ErrUnauthorized = errors.New("unauthorized")
 	ErrInternal     = errors.New("internal error")
+	ErrConflict     = errors.New("resource already exists")
+	ErrDeclined     = errors.New("payment declined")
+	// ErrInvalidPaymentMethod is returned when the payment provider rejects
+	// a payment method token
+	ErrInvalidPaymentMethod = errors.New("invalid payment method")
+	// ErrInsufficientCredit is returned when a user's store credit balance
+	// can't cover a hold
+	ErrInsufficientCredit = errors.New("insufficient store credit")
+	// ErrInsufficientGiftCard is returned when a gift card's balance can't
+	// cover a hold
+	ErrInsufficientGiftCard = errors.New("insufficient gift card balance")
+	// ErrCouponUsedUp is returned when a promo code reaches its usage limit
+	// before it can be redeemed
+	ErrCouponUsedUp = errors.New("promo code usage limit reached")
+	// ErrStatusChanged is returned when a conditional update finds the
+	// resource no longer in the expected status
+	ErrStatusChanged = errors.New("status changed concurrently")
 )
 
 // Clients holds all gRPC client connections
 type Clients struct {
-	userConn      *grpc.ClientConn
-	listingConn   *grpc.ClientConn
-	inventoryConn *grpc.ClientConn
-	config        *config.Config
+	userBackend      *regionalBackend
+	listingBackend   *regionalBackend
+	inventoryBackend *regionalBackend
+	paymentBackend   *regionalBackend
+	config           *config.Config
+	targets          TargetStore // shares target changes; nil for this instance only
 }
 
 // NewClients creates and initializes all gRPC client connections
 func NewClients(cfg *config.Config) (*Clients, error) {
-	opts := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithBlock(),
+	// Metrics and slow-call logs are labelled with the region a call went to
+	baseOpts := func(region string) []grpc.DialOption {
+		return []grpc.DialOption{
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithBlock(),
+			grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+			grpc.WithChainUnaryInterceptor(
+				requestIDUnaryInterceptor,
+				upstreamUnaryInterceptor,
+				retryHintUnaryInterceptor,
+				metricsUnaryInterceptor(region),
+				slowCallUnaryInterceptor(region, time.Duration(cfg.GRPCSlowCallThresholdMs)*time.Millisecond),
+			),
+			grpc.WithChainStreamInterceptor(requestIDStreamInterceptor, upstreamStreamInterceptor, metricsStreamInterceptor(region)),
+		}
 	}
 
 	// Context with timeout for connection
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Connect to User Service
-	userConn, err := grpc.DialContext(ctx, cfg.UserServiceAddr, opts...)
-	if err != nil {
-		log.Printf("Warning: Failed to connect to user service at %s: %v", cfg.UserServiceAddr, err)
-		// Don't fail - service might not be available yet
-	}
+	// Each backend gets its own bulkhead, shared by its regions, so a stalled
+	// service cannot tie up the calls to the others. Each region gets its
+	// own circuit breaker so calls to a region that is down fail fast and
+	// move to another. The breaker sits inside the bulkhead so bulkhead
+	// rejections do not count as backend failures.
+	connect := func(backend, name, localAddr string, remoteAddrs map[string]string) *regionalBackend {
+		limit := cfg.GRPCMaxConcurrentCalls
+		if n, ok := cfg.GRPCMaxConcurrentCallsByBackend[backend]; ok {
+			limit = n
+		}
+		b := newBulkhead(backend, limit, cfg.GRPCMaxQueuedCalls, time.Duration(cfg.GRPCQueueTimeoutMs)*time.Millisecond)
 
-	// Connect to Listing Service
-	listingConn, err := grpc.DialContext(ctx, cfg.ListingServiceAddr, opts...)
-	if err != nil {
-		log.Printf("Warning: Failed to connect to listing service at %s: %v", cfg.ListingServiceAddr, err)
-	}
+		addrs := map[string]string{cfg.Region: localAddr}
+		for region, addr := range remoteAddrs {
+			if region != cfg.Region {
+				addrs[region] = addr
+			}
+		}
 
-	// Connect to Inventory Service
-	inventoryConn, err := grpc.DialContext(ctx, cfg.InventoryServiceAddr, opts...)
-	if err != nil {
-		log.Printf("Warning: Failed to connect to inventory service at %s: %v", cfg.InventoryServiceAddr, err)
+		// Open GRPCPoolSize connections to each region
+		var targets []*regionTarget
+		for region, addr := range addrs {
+			t := &regionTarget{
+				backend: backend,
+				region:  region,
+				breaker: newBreaker(backend, region, cfg.GRPCBreakerFailures, time.Duration(cfg.GRPCBreakerCooldownSec)*time.Second),
+				addr:    addr,
+			}
+			opts := append(baseOpts(region), b.dialOptions()...)
+			if slices.Contains(cfg.GRPCAffinityBackends, backend) {
+				opts = append(opts, affinityDialOption())
+			}
+			opts = append(opts, t.breaker.dialOptions()...)
+			opts = append(opts, grpc.WithChainUnaryInterceptor(t.latencyUnaryInterceptor))
+			t.dial = func(ctx context.Context, addr string) *connPool {
+				return newConnPool(ctx, name, addr, cfg.GRPCPoolSize, opts...)
+			}
+			t.pool = t.dial(ctx, addr)
+			targets = append(targets, t)
+		}
+		return newRegionalBackend(backend, cfg.RegionSelection, cfg.Region, cfg.RegionFailoverOrder, targets)
 	}
 
 	return &Clients{
-		userConn:      userConn,
-		listingConn:   listingConn,
-		inventoryConn: inventoryConn,
-		config:        cfg,
+		userBackend:      connect("user", "user service", cfg.UserServiceAddr, cfg.UserServiceRegionAddrs),
+		listingBackend:   connect("listing", "listing service", cfg.ListingServiceAddr, cfg.ListingServiceRegionAddrs),
+		inventoryBackend: connect("inventory", "inventory service", cfg.InventoryServiceAddr, cfg.InventoryServiceRegionAddrs),
+		paymentBackend:   connect("payment", "payment service", cfg.PaymentServiceAddr, cfg.PaymentServiceRegionAddrs),
+		config:           cfg,
 	}, nil
 }
 
 // Close closes all gRPC connections
 func (c *Clients) Close() {
-	if c.userConn != nil {
-		c.userConn.Close()
-	}
-	if c.listingConn != nil {
-		c.listingConn.Close()
-	}
-	if c.inventoryConn != nil {
-		c.inventoryConn.Close()
-	}
+	c.userBackend.Close()
+	c.listingBackend.Close()
+	c.inventoryBackend.Close()
+	c.paymentBackend.Close()
 }
 
-// HealthCheck checks the health of all connected services
+// HealthCheck checks the health of all connected services. A service is
+// healthy if any of its regions can take calls.
 func (c *Clients) HealthCheck(ctx context.Context) map[string]bool {
 	return map[string]bool{
-		"user-service":      c.userConn != nil && c.userConn.GetState().String() == "READY",
-		"listing-service":   c.listingConn != nil && c.listingConn.GetState().String() == "READY",
-		"inventory-service": c.inventoryConn != nil && c.inventoryConn.GetState().String() == "READY",
+		"user-service":      c.userBackend.Healthy(),
+		"listing-service":   c.listingBackend.Healthy(),
+		"inventory-service": c.inventoryBackend.Healthy(),
+		"payment-service":   c.paymentBackend.Healthy(),
 	}
 }
 
+// RegionHealth reports the health of each service's regions
+func (c *Clients) RegionHealth() map[string]map[string]bool {
+	return map[string]map[string]bool{
+		"user-service":      c.userBackend.Regions(),
+		"listing-service":   c.listingBackend.Regions(),
+		"inventory-service": c.inventoryBackend.Regions(),
+		"payment-service":   c.paymentBackend.Regions(),
+	}
+}
+
+// hedgeDelay returns how long idempotent reads wait before sending a hedge
+// request
+func (c *Clients) hedgeDelay() time.Duration {
+	return time.Duration(c.config.GRPCHedgeDelayMs) * time.Millisecond
+}
+
 // handleGRPCError converts gRPC errors to application errors
 func handleGRPCError(err error) error {
 	if err == nil {
@@ -111,10 +184,205 @@ func handleGRPCError(err error) error {
 	}
 }
 
+// --- User Service Auth Methods ---
+
+// RefreshToken exchanges a refresh token for a new token pair via the user service
+func (c *Clients) RefreshToken(ctx context.Context, refreshToken string) (*models.TokenPair, error) {
+	// TODO: Implement actual gRPC call
+	if refreshToken == "invalid" {
+		return nil, ErrUnauthorized
+	}
+	return &models.TokenPair{
+		AccessToken:  "access-token",
+		RefreshToken: "refresh-token",
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(c.config.JWTExpiration) * 3600,
+	}, nil
+}
+
+// LoginWithOIDC exchanges a verified provider identity for a gateway
+// session via the user service, linking or creating the user account
+func (c *Clients) LoginWithOIDC(ctx context.Context, identity *models.OIDCIdentity) (*models.TokenPair, error) {
+	// TODO: Implement actual gRPC call
+	return &models.TokenPair{
+		AccessToken:  "access-token",
+		RefreshToken: "refresh-token",
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(c.config.JWTExpiration) * 3600,
+	}, nil
+}
+
+// NotifyUser sends a notification to a user via the user service
+func (c *Clients) NotifyUser(ctx context.Context, userID string, notification *models.Notification) error {
+	// TODO: Implement actual gRPC call
+	return nil
+}
+
+// SendVerificationEmail emails a code that verifies the address belongs to
+// the guest who entered it
+func (c *Clients) SendVerificationEmail(ctx context.Context, email, code string) error {
+	// TODO: Implement actual gRPC call
+	return nil
+}
+
+// CountChargebacks returns the number of chargebacks filed against each
+// user's orders via the user service, in one call. Users without
+// chargebacks may be left out.
+func (c *Clients) CountChargebacks(ctx context.Context, userIDs []string) (map[string]int, error) {
+	// TODO: Implement actual gRPC call
+	return map[string]int{}, nil
+}
+
+// GetCustomerSegments returns the segments the user service assigns to a
+// customer, such as new_customer, vip, or at_risk
+func (c *Clients) GetCustomerSegments(ctx context.Context, userID string) ([]string, error) {
+	// TODO: Implement actual gRPC call
+	return []string{}, nil
+}
+
+// FlagAccountForReview holds a high-risk account for manual review via the
+// user service
+func (c *Clients) FlagAccountForReview(ctx context.Context, score *models.RiskScore) error {
+	// TODO: Implement actual gRPC call
+	return nil
+}
+
+// RevokeRefreshToken invalidates a refresh token via the user service
+func (c *Clients) RevokeRefreshToken(ctx context.Context, userID, refreshToken string) error {
+	// TODO: Implement actual gRPC call
+	return nil
+}
+
+// ListAddresses fetches a user's saved shipping addresses via the user
+// service, the default first
+func (c *Clients) ListAddresses(ctx context.Context, userID string) ([]*models.SavedAddress, error) {
+	// TODO: Implement actual gRPC call
+	return []*models.SavedAddress{}, nil
+}
+
+// GetAddress fetches one of a user's saved addresses via the user service
+func (c *Clients) GetAddress(ctx context.Context, addressID, userID string) (*models.SavedAddress, error) {
+	// TODO: Implement actual gRPC call
+	if addressID == "not-found" {
+		return nil, ErrNotFound
+	}
+	return &models.SavedAddress{
+		ID:     addressID,
+		UserID: userID,
+		Address: models.Address{
+			Street:     "1 Sample Street",
+			City:       "Springfield",
+			State:      "IL",
+			PostalCode: "62701",
+			Country:    "US",
+		},
+	}, nil
+}
+
+// CreateAddress saves a shipping address to a user's account via the user
+// service. A default address replaces the user's previous default, and a
+// user's first address is always the default.
+func (c *Clients) CreateAddress(ctx context.Context, address *models.SavedAddress) (*models.SavedAddress, error) {
+	// TODO: Implement actual gRPC call
+	address.ID = "addr-new"
+	address.CreatedAt = models.Now()
+	address.UpdatedAt = address.CreatedAt
+	return address, nil
+}
+
+// UpdateAddress replaces a saved address via the user service. A default
+// address replaces the user's previous default.
+func (c *Clients) UpdateAddress(ctx context.Context, address *models.SavedAddress) (*models.SavedAddress, error) {
+	// TODO: Implement actual gRPC call
+	if address.ID == "not-found" {
+		return nil, ErrNotFound
+	}
+	address.UpdatedAt = models.Now()
+	return address, nil
+}
+
+// DeleteAddress removes a saved address via the user service
+func (c *Clients) DeleteAddress(ctx context.Context, addressID, userID string) error {
+	// TODO: Implement actual gRPC call
+	if addressID == "not-found" {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CreateAPIKey stores a new API key via the user service
+func (c *Clients) CreateAPIKey(ctx context.Context, key *models.APIKey) (*models.APIKey, error) {
+	// TODO: Implement actual gRPC call
+	key.ID = "key-new"
+	key.CreatedAt = models.Now()
+	return key, nil
+}
+
+// ListAPIKeys fetches the API keys owned by a user
+func (c *Clients) ListAPIKeys(ctx context.Context, ownerID string) ([]*models.APIKey, error) {
+	// TODO: Implement actual gRPC call
+	return []*models.APIKey{}, nil
+}
+
+// GetAPIKey fetches a single API key owned by a user
+func (c *Clients) GetAPIKey(ctx context.Context, id, ownerID string) (*models.APIKey, error) {
+	// TODO: Implement actual gRPC call
+	if id == "not-found" {
+		return nil, ErrNotFound
+	}
+	return &models.APIKey{
+		ID:        id,
+		Name:      "Sample Key",
+		OwnerID:   ownerID,
+		Prefix:    "ak_00000000",
+		Scopes:    []string{"GET /products/*"},
+		Roles:     []string{},
+		CreatedAt: models.Now(),
+	}, nil
+}
+
+// UpdateAPIKey persists changes to an API key's name, scopes, or secret hash
+func (c *Clients) UpdateAPIKey(ctx context.Context, key *models.APIKey) (*models.APIKey, error) {
+	// TODO: Implement actual gRPC call
+	return key, nil
+}
+
+// SetAPIKeyTier assigns any user's API key to an SLA tier via the user service
+func (c *Clients) SetAPIKeyTier(ctx context.Context, id, tier string) (*models.APIKey, error) {
+	// TODO: Implement actual gRPC call
+	if id == "not-found" {
+		return nil, ErrNotFound
+	}
+	return &models.APIKey{
+		ID:        id,
+		Name:      "Sample Key",
+		Prefix:    "ak_00000000",
+		Scopes:    []string{"GET /products/*"},
+		Roles:     []string{},
+		Tier:      tier,
+		CreatedAt: models.Now(),
+	}, nil
+}
+
+// RevokeAPIKey revokes an API key owned by a user
+func (c *Clients) RevokeAPIKey(ctx context.Context, id, ownerID string) error {
+	// TODO: Implement actual gRPC call
+	return nil
+}
+
+// ValidateAPIKey looks up an API key by the hash of its secret, returning
+// nil if no key matches
+func (c *Clients) ValidateAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	// TODO: Implement actual gRPC call
+	return nil, nil
+}
+
 // --- Listing Service Methods ---
 
-// ListProducts fetches products from the listing service
-func (c *Clients) ListProducts(ctx context.Context, page, limit int, category, search string) ([]*models.Product, int64, error) {
+// ListProducts fetches products from the listing service. With visibleOnly,
+// only products approved by moderation and published are listed, so pages
+// and the total count just those.
+func (c *Clients) ListProducts(ctx context.Context, page, limit int, category, search string, visibleOnly bool) ([]*models.Product, int64, error) {
 	// TODO: Implement actual gRPC call when proto files are available
 	// For now, return mock data for development
 	products := []*models.Product{
@@ -132,157 +400,1445 @@ func (c *Clients) ListProducts(ctx context.Context, page, limit int, category, s
 
 // GetProduct fetches a single product from the listing service
 func (c *Clients) GetProduct(ctx context.Context, id string) (*models.Product, error) {
+	return hedged(ctx, c.hedgeDelay(), "GetProduct", func(ctx context.Context) (*models.Product, error) {
+		// TODO: Implement actual gRPC call
+		if id == "not-found" {
+			return nil, ErrNotFound
+		}
+		return &models.Product{
+			ID:          id,
+			Name:        "Sample Product",
+			Description: "A sample product for testing",
+			Price:       29.99,
+			Category:    "electronics",
+			Available:   true,
+		}, nil
+	})
+}
+
+// GetProductsByBarcode fetches the products carrying each of a batch of
+// 14-digit GTINs, keyed by GTIN. GTINs no product carries are left out.
+func (c *Clients) GetProductsByBarcode(ctx context.Context, gtins []string) (map[string]*models.Product, error) {
 	// TODO: Implement actual gRPC call
-	if id == "not-found" {
-		return nil, ErrNotFound
+	products := make(map[string]*models.Product, len(gtins))
+	for _, gtin := range gtins {
+		products[gtin] = &models.Product{
+			ID:          "prod-" + gtin[len(gtin)-6:],
+			Name:        "Sample Product",
+			Description: "A sample product for testing",
+			Price:       29.99,
+			Category:    "electronics",
+			Barcodes:    []string{gtin},
+			Available:   true,
+		}
 	}
-	return &models.Product{
-		ID:          id,
-		Name:        "Sample Product",
-		Description: "A sample product for testing",
-		Price:       29.99,
-		Category:    "electronics",
-		Available:   true,
-	}, nil
+	return products, nil
 }
 
 // CreateProduct creates a new product via the listing service
-func (c *Clients) CreateProduct(ctx context.Context, req *models.CreateProductRequest, userID string) (*models.Product, error) {
+func (c *Clients) CreateProduct(ctx context.Context, req *models.CreateProductRequest, userID, moderationStatus string) (*models.Product, error) {
 	// TODO: Implement actual gRPC call
 	return &models.Product{
-		ID:          "prod-new",
-		Name:        req.Name,
-		Description: req.Description,
-		Price:       req.Price,
-		Category:    req.Category,
-		Images:      req.Images,
-		SellerID:    userID,
-		Available:   true,
+		ID:               "prod-new",
+		Name:             req.Name,
+		Description:      req.Description,
+		Price:            req.Price,
+		Currency:         req.Currency,
+		Category:         string(req.Category),
+		Images:           req.Images,
+		SellerID:         userID,
+		Restriction:      req.Restriction,
+		Attributes:       req.Attributes,
+		Barcodes:         req.Barcodes,
+		ImageHashes:      req.ImageHashes,
+		ModerationStatus: moderationStatus,
+		Status:           req.Status,
+		PublishAt:        req.PublishAt,
+		Available:        true,
 	}, nil
 }
 
-// UpdateProduct updates an existing product
-func (c *Clients) UpdateProduct(ctx context.Context, id string, req *models.UpdateProductRequest, userID string) (*models.Product, error) {
+// ListSellerProducts fetches all products listed by a seller
+func (c *Clients) ListSellerProducts(ctx context.Context, sellerID string) ([]*models.Product, error) {
 	// TODO: Implement actual gRPC call
-	return &models.Product{
-		ID:       id,
-		SellerID: userID,
+	return []*models.Product{
+		{
+			ID:          "prod-001",
+			Name:        "Sample Product",
+			Description: "A sample product for testing",
+			Price:       29.99,
+			Category:    "electronics",
+			SellerID:    sellerID,
+			Available:   true,
+		},
 	}, nil
 }
 
+// UpdateProduct updates an existing product. A non-empty moderationStatus
+// is written with the update, so quarantined changes are never shown.
+func (c *Clients) UpdateProduct(ctx context.Context, id string, req *models.UpdateProductRequest, userID, moderationStatus string) (*models.Product, error) {
+	// TODO: Implement actual gRPC call
+	product, err := c.GetProduct(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		product.Name = *req.Name
+	}
+	if req.Description != nil {
+		product.Description = *req.Description
+	}
+	if req.Price != nil {
+		product.Price = *req.Price
+	}
+	if req.Category != nil {
+		product.Category = string(*req.Category)
+	}
+	if req.Images != nil {
+		product.Images = *req.Images
+	}
+	if req.Restriction != nil {
+		product.Restriction = req.Restriction
+	}
+	if req.Attributes != nil {
+		product.Attributes = *req.Attributes
+	}
+	if req.Barcodes != nil {
+		product.Barcodes = *req.Barcodes
+	}
+	if moderationStatus != "" {
+		product.ModerationStatus = moderationStatus
+	}
+	product.UpdatedAt = models.Now()
+	return product, nil
+}
+
+// RecordProductChange writes a product update to the audit log via the
+// listing service
+func (c *Clients) RecordProductChange(ctx context.Context, change *models.ProductChange) (*models.ProductChange, error) {
+	// TODO: Implement actual gRPC call
+	change.ID = "chg-" + change.ProductID
+	change.CreatedAt = models.Now()
+	return change, nil
+}
+
+// ListProductChanges fetches a product's audited changes, newest first
+func (c *Clients) ListProductChanges(ctx context.Context, productID string, page, limit int) ([]*models.ProductChange, int64, error) {
+	// TODO: Implement actual gRPC call
+	return []*models.ProductChange{}, 0, nil
+}
+
 // DeleteProduct deletes a product
 func (c *Clients) DeleteProduct(ctx context.Context, id, userID string) error {
 	// TODO: Implement actual gRPC call
 	return nil
 }
 
-// --- Inventory Service Methods ---
+// RestoreProduct restores a product deleted within the listing service's
+// retention period
+func (c *Clients) RestoreProduct(ctx context.Context, id, userID string) (*models.Product, error) {
+	// TODO: Implement actual gRPC call
+	return c.GetProduct(ctx, id)
+}
 
-// GetInventory gets inventory for a product
-func (c *Clients) GetInventory(ctx context.Context, productID string) (*models.Inventory, error) {
+// GetProductTranslations fetches the translations of several products for a
+// locale in one call via the listing service, keyed by product ID. Products
+// without a translation are left out.
+func (c *Clients) GetProductTranslations(ctx context.Context, productIDs []string, locale string) (map[string]*models.ProductTranslation, error) {
 	// TODO: Implement actual gRPC call
-	return &models.Inventory{
-		ProductID: productID,
-		Quantity:  100,
-		Reserved:  5,
-		Available: true,
-	}, nil
+	return map[string]*models.ProductTranslation{}, nil
 }
 
-// InitializeInventory sets up initial inventory for a new product
-func (c *Clients) InitializeInventory(ctx context.Context, productID string, quantity int32) error {
+// ListProductTranslations fetches all of a product's translations via the
+// listing service
+func (c *Clients) ListProductTranslations(ctx context.Context, productID string) ([]*models.ProductTranslation, error) {
+	// TODO: Implement actual gRPC call
+	return []*models.ProductTranslation{}, nil
+}
+
+// UpsertProductTranslation creates or replaces a product's translation for
+// its locale via the listing service
+func (c *Clients) UpsertProductTranslation(ctx context.Context, productID string, translation *models.ProductTranslation) error {
 	// TODO: Implement actual gRPC call
 	return nil
 }
 
-// UpdateInventory updates inventory quantity
-func (c *Clients) UpdateInventory(ctx context.Context, productID string, quantity int32, operation string) (*models.Inventory, error) {
+// ListProductIDs fetches a page of all product IDs in the catalog via the
+// listing service
+func (c *Clients) ListProductIDs(ctx context.Context, page, limit int) ([]string, int64, error) {
 	// TODO: Implement actual gRPC call
-	return &models.Inventory{
-		ProductID: productID,
-		Quantity:  quantity,
-		Available: quantity > 0,
-	}, nil
+	if page > 1 {
+		return []string{}, 1, nil
+	}
+	return []string{"prod-001"}, 1, nil
 }
 
-// CheckInventory checks if requested quantity is available
-func (c *Clients) CheckInventory(ctx context.Context, productID string, quantity int32) (bool, error) {
+// ReindexProducts rebuilds the search index entries for the given products
+// via the listing service
+func (c *Clients) ReindexProducts(ctx context.Context, productIDs []string) error {
 	// TODO: Implement actual gRPC call
-	return true, nil
+	return nil
 }
 
-// ReserveInventory reserves inventory for an order
-func (c *Clients) ReserveInventory(ctx context.Context, productID string, quantity int32) (string, error) {
+// SetProductStatus changes a product's publishing status and scheduled
+// publish time via the listing service
+func (c *Clients) SetProductStatus(ctx context.Context, productID, status string, publishAt *models.Timestamp) error {
 	// TODO: Implement actual gRPC call
-	return "reservation-" + productID, nil
+	return nil
 }
 
-// CancelReservation cancels an inventory reservation
-func (c *Clients) CancelReservation(ctx context.Context, reservationID string) error {
+// ListDueProducts fetches scheduled products whose publish time is at or
+// before the given time
+func (c *Clients) ListDueProducts(ctx context.Context, before time.Time) ([]*models.Product, error) {
+	// TODO: Implement actual gRPC call
+	return []*models.Product{}, nil
+}
+
+// SetProductModerationStatus updates the moderation status of a product
+func (c *Clients) SetProductModerationStatus(ctx context.Context, productID, status string) error {
 	// TODO: Implement actual gRPC call
 	return nil
 }
 
-// --- User/Order Service Methods ---
+// ListReviews fetches a product's reviews approved by moderation, or
+// predating it, so pages and the total count just those
+func (c *Clients) ListReviews(ctx context.Context, productID string, page, limit int) ([]*models.Review, int64, error) {
+	// TODO: Implement actual gRPC call
+	return []*models.Review{}, 0, nil
+}
 
-// ListOrders fetches orders for a user
-func (c *Clients) ListOrders(ctx context.Context, userID string, page, limit int, status string) ([]*models.Order, int64, error) {
+// CreateReview creates a product review
+func (c *Clients) CreateReview(ctx context.Context, review *models.Review) (*models.Review, error) {
 	// TODO: Implement actual gRPC call
-	return []*models.Order{}, 0, nil
+	review.ID = "review-new"
+	review.CreatedAt = models.Now()
+	return review, nil
 }
 
-// GetOrder fetches a single order
-func (c *Clients) GetOrder(ctx context.Context, orderID, userID string) (*models.Order, error) {
+// SetReviewModerationStatus updates the moderation status of a review
+func (c *Clients) SetReviewModerationStatus(ctx context.Context, reviewID, status string) error {
 	// TODO: Implement actual gRPC call
-	if orderID == "not-found" {
+	return nil
+}
+
+// SetReviewResponse stores the seller's reply to a review, replacing any
+// previous reply. Returns ErrUnauthorized if sellerID does not own the product.
+func (c *Clients) SetReviewResponse(ctx context.Context, productID, reviewID, sellerID string, response *models.ReviewResponse) (*models.Review, error) {
+	// TODO: Implement actual gRPC call
+	if reviewID == "not-found" {
 		return nil, ErrNotFound
 	}
-	return &models.Order{
-		ID:     orderID,
-		UserID: userID,
-		Status: "pending",
+	response.CreatedAt = models.Now()
+	return &models.Review{
+		ID:               reviewID,
+		ProductID:        productID,
+		UserID:           "user-123",
+		Rating:           4,
+		Body:             "Sample review",
+		ModerationStatus: models.ModerationItemApproved,
+		Response:         response,
+		CreatedAt:        models.Now(),
 	}, nil
 }
 
-// CreateOrder creates a new order
-func (c *Clients) CreateOrder(ctx context.Context, userID string, req *models.CreateOrderRequest, reservationIDs []string) (*models.Order, error) {
+// SetReviewResponseModerationStatus updates the moderation status of the
+// seller's reply to a review
+func (c *Clients) SetReviewResponseModerationStatus(ctx context.Context, reviewID, status string) error {
 	// TODO: Implement actual gRPC call
-	var items []models.OrderItem
-	var total float64
-	for _, item := range req.Items {
-		orderItem := models.OrderItem{
-			ProductID:  item.ProductID,
-			Quantity:   item.Quantity,
-			UnitPrice:  29.99, // Would come from product lookup
-			TotalPrice: float64(item.Quantity) * 29.99,
-		}
-		items = append(items, orderItem)
-		total += orderItem.TotalPrice
+	return nil
+}
+
+// SetFitFeedback records how a product fit the author of one of its
+// reviews, replacing any earlier report. Only the reviewer can report it.
+func (c *Clients) SetFitFeedback(ctx context.Context, feedback *models.FitFeedback) (*models.FitFeedback, error) {
+	// TODO: Implement actual gRPC call
+	if feedback.ReviewID == "not-found" {
+		return nil, ErrNotFound
 	}
+	feedback.CreatedAt = models.Now()
+	return feedback, nil
+}
 
-	return &models.Order{
-		ID:             "order-new",
-		UserID:         userID,
-		Items:          items,
-		Status:         "pending",
-		TotalAmount:    total,
-		ShippingAddr:   req.ShippingAddr,
-		ReservationIDs: reservationIDs,
+// GetFitSummary counts the fits reviewers have reported for a product
+func (c *Clients) GetFitSummary(ctx context.Context, productID string) (*models.FitSummary, error) {
+	// TODO: Implement actual gRPC call
+	return &models.FitSummary{
+		Responses:  12,
+		RunsSmall:  8,
+		TrueToSize: 3,
+		RunsLarge:  1,
 	}, nil
 }
 
-// UpdateOrderStatus updates the status of an order
-func (c *Clients) UpdateOrderStatus(ctx context.Context, orderID, userID, status string) (*models.Order, error) {
+// GetProductSizeGuide fetches a product's own size guide
+func (c *Clients) GetProductSizeGuide(ctx context.Context, productID string) (*models.SizeGuide, error) {
 	// TODO: Implement actual gRPC call
-	return &models.Order{
-		ID:     orderID,
-		UserID: userID,
-		Status: status,
+	return nil, ErrNotFound
+}
+
+// GetCategorySizeGuide fetches the size guide shared by a category's products
+func (c *Clients) GetCategorySizeGuide(ctx context.Context, category models.Category) (*models.SizeGuide, error) {
+	// TODO: Implement actual gRPC call
+	if category != models.CategoryClothing {
+		return nil, ErrNotFound
+	}
+	return &models.SizeGuide{
+		Category:     category,
+		Unit:         "cm",
+		Measurements: []string{"chest", "waist"},
+		Sizes: []models.SizeGuideSize{
+			{Label: "S", Values: map[string]float64{"chest": 92, "waist": 78}},
+			{Label: "M", Values: map[string]float64{"chest": 100, "waist": 86}},
+			{Label: "L", Values: map[string]float64{"chest": 108, "waist": 94}},
+		},
+		UpdatedAt: models.Now(),
 	}, nil
 }
 
-// CancelOrder cancels an order
-func (c *Clients) CancelOrder(ctx context.Context, orderID, userID string) error {
+// SetProductSizeGuide replaces a product's own size guide. Only the
+// product's seller can set it.
+func (c *Clients) SetProductSizeGuide(ctx context.Context, userID string, guide *models.SizeGuide) (*models.SizeGuide, error) {
+	// TODO: Implement actual gRPC call
+	if guide.ProductID == "not-found" {
+		return nil, ErrNotFound
+	}
+	guide.UpdatedAt = models.Now()
+	return guide, nil
+}
+
+// SetCategorySizeGuide replaces the size guide shared by a category's products
+func (c *Clients) SetCategorySizeGuide(ctx context.Context, guide *models.SizeGuide) (*models.SizeGuide, error) {
+	// TODO: Implement actual gRPC call
+	guide.UpdatedAt = models.Now()
+	return guide, nil
+}
+
+// ListQuestions fetches questions about a product approved by moderation,
+// or predating it, so pages and the total count just those
+func (c *Clients) ListQuestions(ctx context.Context, productID string, page, limit int) ([]*models.Question, int64, error) {
+	// TODO: Implement actual gRPC call
+	return []*models.Question{}, 0, nil
+}
+
+// GetQuestion fetches a single question about a product
+func (c *Clients) GetQuestion(ctx context.Context, productID, questionID string) (*models.Question, error) {
+	// TODO: Implement actual gRPC call
+	if questionID == "not-found" {
+		return nil, ErrNotFound
+	}
+	return &models.Question{
+		ID:               questionID,
+		ProductID:        productID,
+		UserID:           "user-123",
+		Body:             "Sample question",
+		ModerationStatus: models.ModerationItemApproved,
+		CreatedAt:        models.Now(),
+	}, nil
+}
+
+// CreateQuestion creates a product question
+func (c *Clients) CreateQuestion(ctx context.Context, question *models.Question) (*models.Question, error) {
+	// TODO: Implement actual gRPC call
+	question.ID = "question-new"
+	question.CreatedAt = models.Now()
+	return question, nil
+}
+
+// SetQuestionModerationStatus updates the moderation status of a question
+func (c *Clients) SetQuestionModerationStatus(ctx context.Context, questionID, status string) error {
+	// TODO: Implement actual gRPC call
+	return nil
+}
+
+// ListAnswers fetches answers to a question approved by moderation, or
+// predating it, most helpful first. Pages and the total count just those.
+func (c *Clients) ListAnswers(ctx context.Context, questionID string, page, limit int) ([]*models.Answer, int64, error) {
+	// TODO: Implement actual gRPC call
+	return []*models.Answer{}, 0, nil
+}
+
+// CreateAnswer creates an answer to a question
+func (c *Clients) CreateAnswer(ctx context.Context, answer *models.Answer) (*models.Answer, error) {
+	// TODO: Implement actual gRPC call
+	answer.ID = "answer-new"
+	answer.CreatedAt = models.Now()
+	return answer, nil
+}
+
+// SetAnswerModerationStatus updates the moderation status of an answer
+func (c *Clients) SetAnswerModerationStatus(ctx context.Context, answerID, status string) error {
+	// TODO: Implement actual gRPC call
+	return nil
+}
+
+// VoteAnswer records a user's vote on an answer, replacing any earlier vote
+// by the same user, and returns the updated tallies
+func (c *Clients) VoteAnswer(ctx context.Context, questionID, answerID, userID, vote string) (*models.Answer, error) {
+	// TODO: Implement actual gRPC call
+	if answerID == "not-found" {
+		return nil, ErrNotFound
+	}
+	answer := &models.Answer{
+		ID:               answerID,
+		QuestionID:       questionID,
+		UserID:           "user-456",
+		Body:             "Sample answer",
+		ModerationStatus: models.ModerationItemApproved,
+		CreatedAt:        models.Now(),
+	}
+	if vote == models.VoteUp {
+		answer.Upvotes = 1
+	} else {
+		answer.Downvotes = 1
+	}
+	return answer, nil
+}
+
+// StoreMedia uploads an image to the listing service's media store. Media
+// that is not approved is stored privately and has no public URL.
+func (c *Clients) StoreMedia(ctx context.Context, media *models.Media, data []byte) (*models.Media, error) {
+	// TODO: Implement actual gRPC call
+	media.ID = "media-new"
+	if media.ModerationStatus == models.ModerationItemApproved && media.ScanStatus != models.ScanStatusPending {
+		media.URL = "https://cdn.example.com/media/" + media.ID
+	}
+	media.CreatedAt = models.Now()
+	return media, nil
+}
+
+// SetMediaModerationStatus updates the moderation status of uploaded media,
+// publishing it when approved
+func (c *Clients) SetMediaModerationStatus(ctx context.Context, mediaID, status string) error {
+	// TODO: Implement actual gRPC call
+	return nil
+}
+
+// SetMediaScanStatus records the outcome of scanning uploaded media for
+// malware. Media is published once clean if moderation approved it, and
+// infected media is quarantined so it is never served.
+func (c *Clients) SetMediaScanStatus(ctx context.Context, mediaID, status, threat string) error {
+	// TODO: Implement actual gRPC call
+	return nil
+}
+
+// CreateAbuseReport records a user's report of a listing or review. Returns
+// ErrConflict if the user already has an open report on the content.
+func (c *Clients) CreateAbuseReport(ctx context.Context, report *models.AbuseReport) (*models.AbuseReport, error) {
+	// TODO: Implement actual gRPC call
+	if report.ContentID == "not-found" {
+		return nil, ErrNotFound
+	}
+	report.ID = "report-new"
+	report.CreatedAt = models.Now()
+	return report, nil
+}
+
+// CountOpenAbuseReports returns the number of distinct users with an open
+// report on the content
+func (c *Clients) CountOpenAbuseReports(ctx context.Context, contentType, contentID string) (int64, error) {
+	// TODO: Implement actual gRPC call
+	return 1, nil
+}
+
+// ListAbuseReports fetches abuse reports, optionally filtered by status and
+// content type
+func (c *Clients) ListAbuseReports(ctx context.Context, status, contentType string, page, limit int) ([]*models.AbuseReport, int64, error) {
+	// TODO: Implement actual gRPC call
+	return []*models.AbuseReport{}, 0, nil
+}
+
+// GetAbuseReport fetches a single abuse report
+func (c *Clients) GetAbuseReport(ctx context.Context, id string) (*models.AbuseReport, error) {
+	// TODO: Implement actual gRPC call
+	if id == "not-found" {
+		return nil, ErrNotFound
+	}
+	return &models.AbuseReport{
+		ID:          id,
+		ContentType: models.ContentTypeProduct,
+		ContentID:   "prod-1",
+		ReporterID:  "user-123",
+		Reason:      models.ReportReasonSpam,
+		Status:      models.ReportStatusOpen,
+		CreatedAt:   models.Now(),
+	}, nil
+}
+
+// UpdateAbuseReport persists an admin decision on an abuse report
+func (c *Clients) UpdateAbuseReport(ctx context.Context, report *models.AbuseReport) (*models.AbuseReport, error) {
+	// TODO: Implement actual gRPC call
+	return report, nil
+}
+
+// CreateModerationItem queues quarantined content for admin review. Content
+// already pending review keeps its existing item.
+func (c *Clients) CreateModerationItem(ctx context.Context, item *models.ModerationItem) (*models.ModerationItem, error) {
+	// TODO: Implement actual gRPC call
+	item.ID = "mod-" + item.ContentID
+	item.CreatedAt = models.Now()
+	return item, nil
+}
+
+// ListModerationItems fetches the moderation queue, optionally filtered by
+// status and content type
+func (c *Clients) ListModerationItems(ctx context.Context, status, contentType string, page, limit int) ([]*models.ModerationItem, int64, error) {
+	// TODO: Implement actual gRPC call
+	return []*models.ModerationItem{}, 0, nil
+}
+
+// GetModerationItem fetches a single moderation queue item
+func (c *Clients) GetModerationItem(ctx context.Context, id string) (*models.ModerationItem, error) {
+	// TODO: Implement actual gRPC call
+	if id == "not-found" {
+		return nil, ErrNotFound
+	}
+	return &models.ModerationItem{
+		ID:          id,
+		ContentType: models.ContentTypeProduct,
+		ContentID:   "prod-001",
+		Status:      models.ModerationItemPending,
+		CreatedAt:   models.Now(),
+	}, nil
+}
+
+// UpdateModerationItem persists an admin decision on a moderation queue item
+func (c *Clients) UpdateModerationItem(ctx context.Context, item *models.ModerationItem) (*models.ModerationItem, error) {
+	// TODO: Implement actual gRPC call
+	return item, nil
+}
+
+// CreateDuplicateFlag queues a product for duplicate review
+func (c *Clients) CreateDuplicateFlag(ctx context.Context, flag *models.DuplicateFlag) (*models.DuplicateFlag, error) {
+	// TODO: Implement actual gRPC call
+	flag.ID = "dup-" + flag.ProductID
+	flag.CreatedAt = models.Now()
+	return flag, nil
+}
+
+// ListDuplicateFlags fetches flagged duplicates, optionally filtered by status
+func (c *Clients) ListDuplicateFlags(ctx context.Context, status string, page, limit int) ([]*models.DuplicateFlag, int64, error) {
+	// TODO: Implement actual gRPC call
+	return []*models.DuplicateFlag{}, 0, nil
+}
+
+// GetDuplicateFlag fetches a single duplicate flag
+func (c *Clients) GetDuplicateFlag(ctx context.Context, id string) (*models.DuplicateFlag, error) {
+	// TODO: Implement actual gRPC call
+	if id == "not-found" {
+		return nil, ErrNotFound
+	}
+	return &models.DuplicateFlag{
+		ID:        id,
+		ProductID: "prod-001",
+		Status:    models.DuplicateFlagPending,
+		CreatedAt: models.Now(),
+	}, nil
+}
+
+// UpdateDuplicateFlag persists an admin decision on a duplicate flag
+func (c *Clients) UpdateDuplicateFlag(ctx context.Context, flag *models.DuplicateFlag) (*models.DuplicateFlag, error) {
+	// TODO: Implement actual gRPC call
+	return flag, nil
+}
+
+// --- Inventory Service Methods ---
+
+// GetInventory gets inventory for a product
+func (c *Clients) GetInventory(ctx context.Context, productID string) (*models.Inventory, error) {
+	return hedged(ctx, c.hedgeDelay(), "GetInventory", func(ctx context.Context) (*models.Inventory, error) {
+		// TODO: Implement actual gRPC call
+		return &models.Inventory{
+			ProductID: productID,
+			Quantity:  100,
+			Reserved:  5,
+			Available: true,
+		}, nil
+	})
+}
+
+// InitializeInventory sets up initial inventory for a new product
+func (c *Clients) InitializeInventory(ctx context.Context, productID string, quantity int32) error {
+	// TODO: Implement actual gRPC call
+	return nil
+}
+
+// UpdateInventory updates inventory quantity
+func (c *Clients) UpdateInventory(ctx context.Context, productID string, quantity int32, operation models.InventoryOperation) (*models.Inventory, error) {
+	// TODO: Implement actual gRPC call
+	if !operation.Valid() {
+		return nil, fmt.Errorf("unknown inventory operation %q", operation)
+	}
+	return &models.Inventory{
+		ProductID: productID,
+		Quantity:  quantity,
+		Available: quantity > 0,
+	}, nil
+}
+
+// CheckInventory checks if requested quantity is available
+func (c *Clients) CheckInventory(ctx context.Context, productID string, quantity int32) (bool, error) {
+	// TODO: Implement actual gRPC call
+	return true, nil
+}
+
+// ReserveInventory reserves inventory for an order
+func (c *Clients) ReserveInventory(ctx context.Context, productID string, quantity int32) (string, error) {
+	// TODO: Implement actual gRPC call
+	return "reservation-" + productID, nil
+}
+
+// CancelReservation cancels an inventory reservation
+func (c *Clients) CancelReservation(ctx context.Context, reservationID string) error {
+	// TODO: Implement actual gRPC call
+	return nil
+}
+
+// CreateStockTransfer records a new stock transfer request
+func (c *Clients) CreateStockTransfer(ctx context.Context, req *models.CreateStockTransferRequest, userID string) (*models.StockTransfer, error) {
+	// TODO: Implement actual gRPC call
+	return &models.StockTransfer{
+		ID:                     "transfer-new",
+		ProductID:              req.ProductID,
+		SourceWarehouseID:      req.SourceWarehouseID,
+		DestinationWarehouseID: req.DestinationWarehouseID,
+		Quantity:               req.Quantity,
+		Status:                 models.TransferStatusRequested,
+		Notes:                  req.Notes,
+		RequestedBy:            userID,
+		CreatedAt:              models.Now(),
+	}, nil
+}
+
+// GetStockTransfer fetches a stock transfer
+func (c *Clients) GetStockTransfer(ctx context.Context, id string) (*models.StockTransfer, error) {
+	// TODO: Implement actual gRPC call
+	if id == "not-found" {
+		return nil, ErrNotFound
+	}
+	return &models.StockTransfer{
+		ID:                     id,
+		ProductID:              "prod-001",
+		SourceWarehouseID:      "wh-001",
+		DestinationWarehouseID: "wh-002",
+		Quantity:               10,
+		Status:                 models.TransferStatusRequested,
+		CreatedAt:              models.Now(),
+	}, nil
+}
+
+// UpdateStockTransfer persists changes to a stock transfer's state if it is
+// still in fromStatus, returning ErrStatusChanged otherwise, so concurrent
+// transitions can't both apply
+func (c *Clients) UpdateStockTransfer(ctx context.Context, transfer *models.StockTransfer, fromStatus string) (*models.StockTransfer, error) {
+	// TODO: Implement actual gRPC call
+	return transfer, nil
+}
+
+// GetPickLocations fetches the bins products are picked from at a warehouse
+// via the inventory service. Products without a bin there are left out.
+func (c *Clients) GetPickLocations(ctx context.Context, warehouseID string, productIDs []string) ([]models.PickLocation, error) {
+	// TODO: Implement actual gRPC call
+	locations := make([]models.PickLocation, 0, len(productIDs))
+	for i, productID := range productIDs {
+		locations = append(locations, models.PickLocation{
+			ProductID:   productID,
+			WarehouseID: warehouseID,
+			Bin:         fmt.Sprintf("A-%02d-%d", i/4+1, i%4+1),
+		})
+	}
+	return locations, nil
+}
+
+// AdjustWarehouseStock changes on-hand stock for a product at a warehouse by
+// delta. The inventory service applies each key once, so a retried
+// adjustment doesn't change stock twice.
+func (c *Clients) AdjustWarehouseStock(ctx context.Context, warehouseID, productID string, delta int32, reason, key string) error {
+	// TODO: Implement actual gRPC call
+	return nil
+}
+
+// GetWarehouseStock fetches on-hand stock for a product at a warehouse
+func (c *Clients) GetWarehouseStock(ctx context.Context, warehouseID, productID string) (int32, error) {
+	// TODO: Implement actual gRPC call
+	return 100, nil
+}
+
+// CreateCycleCount schedules a cycle count
+func (c *Clients) CreateCycleCount(ctx context.Context, count *models.CycleCount) (*models.CycleCount, error) {
+	// TODO: Implement actual gRPC call
+	count.ID = "count-new"
+	count.CreatedAt = models.Now()
+	return count, nil
+}
+
+// GetCycleCount fetches a cycle count
+func (c *Clients) GetCycleCount(ctx context.Context, id string) (*models.CycleCount, error) {
+	// TODO: Implement actual gRPC call
+	if id == "not-found" {
+		return nil, ErrNotFound
+	}
+	return &models.CycleCount{
+		ID:           id,
+		WarehouseID:  "wh-001",
+		Status:       models.CycleCountStatusScheduled,
+		ScheduledFor: models.Now(),
+		Lines:        []models.CycleCountLine{{ProductID: "prod-001"}},
+		CreatedAt:    models.Now(),
+	}, nil
+}
+
+// UpdateCycleCount persists changes to a cycle count
+func (c *Clients) UpdateCycleCount(ctx context.Context, count *models.CycleCount) (*models.CycleCount, error) {
+	// TODO: Implement actual gRPC call
+	return count, nil
+}
+
+// RecordInventoryAdjustment writes an adjustment to the audit trail
+func (c *Clients) RecordInventoryAdjustment(ctx context.Context, adjustment *models.InventoryAdjustment) (*models.InventoryAdjustment, error) {
+	// TODO: Implement actual gRPC call
+	adjustment.ID = "adj-" + adjustment.ProductID
+	adjustment.CreatedAt = models.Now()
+	return adjustment, nil
+}
+
+// ListInventoryAdjustments fetches the adjustment audit trail, optionally
+// filtered by warehouse and product
+func (c *Clients) ListInventoryAdjustments(ctx context.Context, warehouseID, productID string, page, limit int) ([]*models.InventoryAdjustment, int64, error) {
+	// TODO: Implement actual gRPC call
+	return []*models.InventoryAdjustment{}, 0, nil
+}
+
+// ListSalesVelocity fetches per-SKU sales velocity for a seller's products.
+// Velocity is computed by the inventory service's scheduled aggregation over
+// order events within the trailing window.
+func (c *Clients) ListSalesVelocity(ctx context.Context, sellerID string, windowDays int) ([]*models.SalesVelocity, error) {
+	// TODO: Implement actual gRPC call
+	return []*models.SalesVelocity{
+		{
+			ProductID:     "prod-001",
+			ProductName:   "Sample Product",
+			UnitsSold:     int64(3 * windowDays),
+			WindowDays:    windowDays,
+			CurrentStock:  95,
+			DailyVelocity: 3,
+			ComputedAt:    models.NewTimestamp(time.Now().Truncate(time.Hour)),
+		},
+	}, nil
+}
+
+// GetSellerProfile fetches a seller's business details via the user service
+func (c *Clients) GetSellerProfile(ctx context.Context, sellerID string) (*models.SellerProfile, error) {
+	// TODO: Implement actual gRPC call
+	if sellerID == "" {
+		return nil, ErrNotFound
+	}
+	return &models.SellerProfile{
+		SellerID: sellerID,
+		Name:     "Sample Seller " + sellerID,
+		Email:    "billing@" + sellerID + ".example.com",
+		TaxID:    "US-12-3456789",
+		Address: models.Address{
+			Street:     "100 Market St",
+			City:       "San Francisco",
+			State:      "CA",
+			PostalCode: "94105",
+			Country:    "US",
+		},
+	}, nil
+}
+
+// GetSellerVacation fetches a seller's scheduled or current vacation via the
+// listing service, returning ErrNotFound if none is set
+func (c *Clients) GetSellerVacation(ctx context.Context, sellerID string) (*models.SellerVacation, error) {
+	// TODO: Implement actual gRPC call
+	if sellerID != "seller-away" {
+		return nil, ErrNotFound
+	}
+	now := time.Now().UTC().Truncate(time.Hour)
+	return &models.SellerVacation{
+		SellerID:  sellerID,
+		StartsAt:  models.NewTimestamp(now.Add(-24 * time.Hour)),
+		EndsAt:    models.NewTimestamp(now.Add(7 * 24 * time.Hour)),
+		Message:   "Back next week",
+		UpdatedAt: models.NewTimestamp(now.Add(-48 * time.Hour)),
+	}, nil
+}
+
+// SetSellerVacation schedules a seller's vacation via the listing service,
+// replacing any already set
+func (c *Clients) SetSellerVacation(ctx context.Context, vacation *models.SellerVacation) (*models.SellerVacation, error) {
+	// TODO: Implement actual gRPC call
+	vacation.UpdatedAt = models.Now()
+	return vacation, nil
+}
+
+// DeleteSellerVacation ends or cancels a seller's vacation via the listing
+// service, returning ErrNotFound if none is set
+func (c *Clients) DeleteSellerVacation(ctx context.Context, sellerID string) error {
+	// TODO: Implement actual gRPC call
+	return nil
+}
+
+// --- User/Order Service Methods ---
+
+// ListOrders fetches orders for a user
+func (c *Clients) ListOrders(ctx context.Context, userID string, page, limit int, status models.OrderStatus) ([]*models.Order, int64, error) {
+	// TODO: Implement actual gRPC call
+	return []*models.Order{}, 0, nil
+}
+
+// GetOrder fetches a single order
+func (c *Clients) GetOrder(ctx context.Context, orderID, userID string) (*models.Order, error) {
+	// TODO: Implement actual gRPC call
+	if orderID == "not-found" {
+		return nil, ErrNotFound
+	}
+	return &models.Order{
+		ID:     orderID,
+		UserID: userID,
+		Status: models.OrderStatusPending,
+	}, nil
+}
+
+// CreateOrder creates a new order
+func (c *Clients) CreateOrder(ctx context.Context, userID string, req *models.CreateOrderRequest, reservationIDs []string, signatureRequired bool) (*models.Order, error) {
+	// TODO: Implement actual gRPC call
+	var items []models.OrderItem
+	total := req.Tax + req.ShippingCost - req.Discount
+	for i, item := range req.Items {
+		orderItem := models.OrderItem{
+			ProductID:  item.ProductID,
+			Quantity:   item.Quantity,
+			UnitPrice:  29.99, // Would come from product lookup
+			TotalPrice: float64(item.Quantity) * 29.99,
+			Currency:   req.Currency,
+			Status:     models.OrderItemStatusPending,
+		}
+		if i < len(req.ItemTaxes) {
+			orderItem.Tax = req.ItemTaxes[i]
+		}
+		items = append(items, orderItem)
+		total += orderItem.TotalPrice
+	}
+
+	return &models.Order{
+		ID:                "order-new",
+		UserID:            userID,
+		Items:             items,
+		Status:            models.OrderStatusPending,
+		TotalAmount:       math.Round(total*100) / 100,
+		Currency:          req.Currency,
+		ShippingAddr:      *req.ShippingAddr,
+		ReservationIDs:    reservationIDs,
+		SignatureRequired: signatureRequired,
+		GuestEmail:        req.GuestEmail,
+		CouponCode:        req.CouponCode,
+		Discount:          req.Discount,
+		FreeShipping:      req.FreeShipping,
+		TaxAmount:         req.Tax,
+		ShippingMethod:    req.ShippingMethod,
+		ShippingAmount:    req.ShippingCost,
+		SellerID:          req.SellerID,
+	}, nil
+}
+
+// CreateSubOrders records the per-seller sub-orders a customer's order is
+// split into, returning them with their IDs. Cancelling the parent order
+// cancels its sub-orders, and the parent's status follows theirs.
+func (c *Clients) CreateSubOrders(ctx context.Context, userID, parentID string, subOrders []*models.Order) ([]*models.Order, error) {
+	// TODO: Implement actual gRPC call
+	created := make([]*models.Order, len(subOrders))
+	for i, sub := range subOrders {
+		order := *sub
+		order.ID = fmt.Sprintf("%s-%d", parentID, i+1)
+		order.CreatedAt = models.Now()
+		order.UpdatedAt = order.CreatedAt
+		created[i] = &order
+	}
+	return created, nil
+}
+
+// ListSubOrders fetches the per-seller sub-orders an order was split into,
+// or none for an order from a single seller
+func (c *Clients) ListSubOrders(ctx context.Context, orderID, userID string) ([]*models.Order, error) {
+	// TODO: Implement actual gRPC call
+	return []*models.Order{}, nil
+}
+
+// ListSellerOrders fetches the orders and sub-orders holding a seller's
+// items, newest first
+func (c *Clients) ListSellerOrders(ctx context.Context, sellerID string, page, limit int, status models.OrderStatus) ([]*models.Order, int64, error) {
+	// TODO: Implement actual gRPC call
+	return []*models.Order{}, 0, nil
+}
+
+// GetSellerOrder fetches one of a seller's orders or sub-orders.
+// ErrUnauthorized means the order holds another seller's items.
+func (c *Clients) GetSellerOrder(ctx context.Context, orderID, sellerID string) (*models.Order, error) {
+	// TODO: Implement actual gRPC call
+	if orderID == "not-found" {
+		return nil, ErrNotFound
+	}
+	return &models.Order{
+		ID:       orderID,
+		SellerID: sellerID,
+		Status:   models.OrderStatusConfirmed,
+	}, nil
+}
+
+// UpdateSellerOrderStatus moves one of a seller's orders or sub-orders to a
+// new status as the seller fulfills it
+func (c *Clients) UpdateSellerOrderStatus(ctx context.Context, orderID, sellerID string, status models.OrderStatus) (*models.Order, error) {
+	// TODO: Implement actual gRPC call
+	if orderID == "not-found" {
+		return nil, ErrNotFound
+	}
+	return &models.Order{
+		ID:        orderID,
+		SellerID:  sellerID,
+		Status:    status,
+		UpdatedAt: models.Now(),
+	}, nil
+}
+
+// ListShipments fetches the shipments an order was sent in via the order
+// service, oldest first
+func (c *Clients) ListShipments(ctx context.Context, orderID string) ([]*models.Shipment, error) {
+	// TODO: Implement actual gRPC call
+	if orderID != "order-shipped" {
+		return []*models.Shipment{}, nil
+	}
+	return []*models.Shipment{
+		{
+			ID:             "shipment-1",
+			OrderID:        orderID,
+			Carrier:        "UPS",
+			TrackingNumber: "1Z999AA10123456784",
+			Status:         models.ShipmentStatusDelivered,
+			Events: []models.TrackingEvent{
+				{Status: models.ShipmentStatusInTransit, Location: "Oakland, CA", OccurredAt: models.NewTimestamp(time.Now().Add(-48 * time.Hour))},
+				{Status: models.ShipmentStatusDelivered, Location: "San Francisco, CA", OccurredAt: models.NewTimestamp(time.Now().Add(-24 * time.Hour))},
+			},
+			UpdatedAt: models.NewTimestamp(time.Now().Add(-24 * time.Hour)),
+		},
+		{
+			ID:             "shipment-2",
+			OrderID:        orderID,
+			Carrier:        "UPS",
+			TrackingNumber: "1Z999AA10123456785",
+			Status:         models.ShipmentStatusInTransit,
+			Events: []models.TrackingEvent{
+				{Status: models.ShipmentStatusInTransit, Location: "Oakland, CA", OccurredAt: models.NewTimestamp(time.Now().Add(-12 * time.Hour))},
+			},
+			UpdatedAt: models.NewTimestamp(time.Now().Add(-12 * time.Hour)),
+		},
+	}, nil
+}
+
+// GetShipmentByTracking fetches a shipment by its carrier and tracking
+// number via the order service. It returns ErrNotFound if no order was
+// shipped with it.
+func (c *Clients) GetShipmentByTracking(ctx context.Context, carrier, trackingNumber string) (*models.Shipment, error) {
+	// TODO: Implement actual gRPC call
+	shipments, err := c.ListShipments(ctx, "order-shipped")
+	if err != nil {
+		return nil, err
+	}
+	for _, shipment := range shipments {
+		if strings.EqualFold(shipment.Carrier, carrier) && shipment.TrackingNumber == trackingNumber {
+			shipment.UserID = "user-1"
+			return shipment, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// AddTrackingEvent records a carrier's tracking event on a shipment via the
+// order service, keeping its events in the order they occurred, moving the
+// shipment to the status of the latest one, so an event delivered late
+// doesn't move it back, and updating its estimated delivery if one is given
+func (c *Clients) AddTrackingEvent(ctx context.Context, shipment *models.Shipment, event models.TrackingEvent, estimatedDelivery string) (*models.Shipment, error) {
+	// TODO: Implement actual gRPC call
+	updated := *shipment
+	updated.Events = append(append([]models.TrackingEvent{}, shipment.Events...), event)
+	slices.SortStableFunc(updated.Events, func(a, b models.TrackingEvent) int {
+		return a.OccurredAt.Compare(b.OccurredAt.Time)
+	})
+	updated.Status = updated.Events[len(updated.Events)-1].Status
+	if estimatedDelivery != "" {
+		updated.EstimatedDelivery = estimatedDelivery
+	}
+	updated.UpdatedAt = models.Now()
+	return &updated, nil
+}
+
+// UpdateOrderStatus updates the status of an order
+func (c *Clients) UpdateOrderStatus(ctx context.Context, orderID, userID string, status models.OrderStatus) (*models.Order, error) {
+	// TODO: Implement actual gRPC call
+	return &models.Order{
+		ID:     orderID,
+		UserID: userID,
+		Status: status,
+	}, nil
+}
+
+// UpdateOrderItemStatuses moves items of an order to new statuses and the
+// order to status, returning the updated order
+func (c *Clients) UpdateOrderItemStatuses(ctx context.Context, order *models.Order, updates []models.OrderItemStatusUpdate, status models.OrderStatus) (*models.Order, error) {
+	// TODO: Implement actual gRPC call
+	updated := *order
+	updated.Items = append([]models.OrderItem{}, order.Items...)
+	for _, update := range updates {
+		for i := range updated.Items {
+			if updated.Items[i].ProductID == update.ProductID {
+				updated.Items[i].Status = update.Status
+			}
+		}
+	}
+	updated.Status = status
+	updated.UpdatedAt = models.Now()
+	return &updated, nil
+}
+
+// CancelOrder cancels an order
+func (c *Clients) CancelOrder(ctx context.Context, orderID, userID string) error {
+	// TODO: Implement actual gRPC call
+	return nil
+}
+
+// ClaimGuestOrders moves the orders placed by guests with the given verified
+// email to a user's account, returning the orders moved
+func (c *Clients) ClaimGuestOrders(ctx context.Context, email, userID string) ([]*models.Order, error) {
+	// TODO: Implement actual gRPC call
+	return []*models.Order{}, nil
+}
+
+// CreatePOSOrder places an order taken and paid for at an in-store device,
+// for the store's stock. The order service keys it on the device and the
+// device's local ID, so placing the same order again returns the first one.
+// ErrNotFound means a product doesn't exist.
+func (c *Clients) CreatePOSOrder(ctx context.Context, storeID, deviceID string, req *models.POSOrderRequest) (*models.Order, error) {
+	// TODO: Implement actual gRPC call
+	items := make([]models.OrderItem, len(req.Items))
+	total := 0.0
+	for i, item := range req.Items {
+		items[i] = models.OrderItem{
+			ProductID:  item.ProductID,
+			Quantity:   item.Quantity,
+			UnitPrice:  item.UnitPrice,
+			TotalPrice: float64(item.Quantity) * item.UnitPrice,
+		}
+		total += items[i].TotalPrice
+	}
+	return &models.Order{
+		ID:          "order-pos-" + deviceID + "-" + req.LocalID,
+		UserID:      "store:" + storeID,
+		Items:       items,
+		Status:      models.OrderStatusConfirmed,
+		TotalAmount: math.Round(total*100) / 100,
+		GuestEmail:  req.CustomerEmail,
+		CreatedAt:   req.TakenAt,
+		UpdatedAt:   models.Now(),
+	}, nil
+}
+
+// ListPendingOrders fetches orders of every user still pending that were
+// created before the given time
+func (c *Clients) ListPendingOrders(ctx context.Context, createdBefore time.Time) ([]*models.Order, error) {
+	// TODO: Implement actual gRPC call
+	return []*models.Order{}, nil
+}
+
+// CreateReturn records a customer's return request for items of an order
+func (c *Clients) CreateReturn(ctx context.Context, order *models.Order, req *models.CreateReturnRequest, refundAmount float64) (*models.Return, error) {
+	// TODO: Implement actual gRPC call
+	return &models.Return{
+		ID:           "return-new",
+		OrderID:      order.ID,
+		UserID:       order.UserID,
+		Items:        req.Items,
+		Reason:       req.Reason,
+		Status:       models.ReturnStatusRequested,
+		RefundAmount: refundAmount,
+		CreatedAt:    models.Now(),
+	}, nil
+}
+
+// ListOrderReturns fetches every return requested for an order, in any
+// status
+func (c *Clients) ListOrderReturns(ctx context.Context, orderID string) ([]*models.Return, error) {
+	// TODO: Implement actual gRPC call
+	return []*models.Return{}, nil
+}
+
+// GetReturn fetches a return. A non-empty userID restricts it to that
+// user's returns, returning ErrUnauthorized for anyone else's.
+func (c *Clients) GetReturn(ctx context.Context, returnID, userID string) (*models.Return, error) {
+	// TODO: Implement actual gRPC call
+	if returnID == "not-found" {
+		return nil, ErrNotFound
+	}
+	owner := userID
+	if owner == "" {
+		owner = "user-001"
+	}
+	return &models.Return{
+		ID:           returnID,
+		OrderID:      "order-001",
+		UserID:       owner,
+		Items:        []models.ReturnItem{{ProductID: "prod-001", Quantity: 1}},
+		Status:       models.ReturnStatusRequested,
+		RefundAmount: 29.99,
+		CreatedAt:    models.Now(),
+	}, nil
+}
+
+// UpdateReturn persists changes to a return's state
+func (c *Clients) UpdateReturn(ctx context.Context, ret *models.Return) (*models.Return, error) {
+	// TODO: Implement actual gRPC call
+	return ret, nil
+}
+
+// --- Payment Service Methods ---
+
+// CreatePaymentIntent starts a payment of amount for an order via the
+// payment service
+func (c *Clients) CreatePaymentIntent(ctx context.Context, userID, orderID string, amount float64, currency string) (*models.PaymentIntent, error) {
+	// TODO: Implement actual gRPC call
+	return &models.PaymentIntent{
+		ID:        "pi-" + orderID,
+		OrderID:   orderID,
+		UserID:    userID,
+		Amount:    amount,
+		Currency:  currency,
+		Status:    models.PaymentStatusRequiresConfirmation,
+		CreatedAt: models.NewTimestamp(time.Now()),
+	}, nil
+}
+
+// ConfirmPayment charges a payment intent to a payment method via the
+// payment service. It returns ErrDeclined if the charge is declined.
+func (c *Clients) ConfirmPayment(ctx context.Context, intentID, userID, paymentMethodID string) (*models.PaymentIntent, error) {
+	// TODO: Implement actual gRPC call
+	if paymentMethodID == "pm_card_declined" {
+		return nil, ErrDeclined
+	}
+	intent, err := c.GetPaymentIntent(ctx, intentID, userID)
+	if err != nil {
+		return nil, err
+	}
+	intent.Status = models.PaymentStatusSucceeded
+	intent.PaymentMethodID = paymentMethodID
+	intent.ConfirmedAt = models.TimestampPtr(time.Now())
+	return intent, nil
+}
+
+// GetPaymentIntent fetches a payment intent via the payment service
+func (c *Clients) GetPaymentIntent(ctx context.Context, intentID, userID string) (*models.PaymentIntent, error) {
+	// TODO: Implement actual gRPC call
+	if intentID == "not-found" {
+		return nil, ErrNotFound
+	}
+	return &models.PaymentIntent{
+		ID:        intentID,
+		OrderID:   strings.TrimPrefix(intentID, "pi-"),
+		UserID:    userID,
+		Amount:    29.99,
+		Currency:  c.config.PaymentCurrency,
+		Status:    models.PaymentStatusRequiresConfirmation,
+		CreatedAt: models.NewTimestamp(time.Now()),
+	}, nil
+}
+
+// SavePaymentMethod attaches a provider payment method token to the user
+// via the payment service, which keeps the token and the card details the
+// provider reports. A default method replaces the user's previous default,
+// and a user's first method is always the default.
+func (c *Clients) SavePaymentMethod(ctx context.Context, userID, token string, setDefault bool) (*models.SavedPaymentMethod, error) {
+	// TODO: Implement actual gRPC call
+	if token == "tok_invalid" {
+		return nil, ErrInvalidPaymentMethod
+	}
+	return &models.SavedPaymentMethod{
+		ID:               "spm-new",
+		UserID:           userID,
+		ProviderMethodID: "pm_" + token,
+		Type:             "card",
+		Brand:            "visa",
+		Last4:            "4242",
+		ExpMonth:         12,
+		ExpYear:          time.Now().Year() + 2,
+		IsDefault:        setDefault,
+		CreatedAt:        models.Now(),
+	}, nil
+}
+
+// ListPaymentMethods fetches a user's saved payment methods via the payment
+// service, the default first
+func (c *Clients) ListPaymentMethods(ctx context.Context, userID string) ([]*models.SavedPaymentMethod, error) {
+	// TODO: Implement actual gRPC call
+	return []*models.SavedPaymentMethod{}, nil
+}
+
+// GetPaymentMethod fetches one of a user's saved payment methods via the
+// payment service
+func (c *Clients) GetPaymentMethod(ctx context.Context, methodID, userID string) (*models.SavedPaymentMethod, error) {
+	// TODO: Implement actual gRPC call
+	if methodID == "not-found" {
+		return nil, ErrNotFound
+	}
+	return &models.SavedPaymentMethod{
+		ID:               methodID,
+		UserID:           userID,
+		ProviderMethodID: "pm_card_visa",
+		Type:             "card",
+		Brand:            "visa",
+		Last4:            "4242",
+		ExpMonth:         12,
+		ExpYear:          time.Now().Year() + 2,
+	}, nil
+}
+
+// SetDefaultPaymentMethod makes a saved payment method the user's default
+// via the payment service
+func (c *Clients) SetDefaultPaymentMethod(ctx context.Context, methodID, userID string) (*models.SavedPaymentMethod, error) {
+	// TODO: Implement actual gRPC call
+	method, err := c.GetPaymentMethod(ctx, methodID, userID)
+	if err != nil {
+		return nil, err
+	}
+	method.IsDefault = true
+	return method, nil
+}
+
+// DeletePaymentMethod removes a saved payment method via the payment
+// service, which also detaches it from the provider so the token can no
+// longer be charged
+func (c *Clients) DeletePaymentMethod(ctx context.Context, methodID, userID string) error {
+	// TODO: Implement actual gRPC call
+	if methodID == "not-found" {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetStoreCredit fetches a user's store credit balance via the payment
+// service
+func (c *Clients) GetStoreCredit(ctx context.Context, userID, currency string) (*models.StoreCreditBalance, error) {
+	// TODO: Implement actual gRPC call
+	return &models.StoreCreditBalance{
+		UserID:    userID,
+		Balance:   25.00,
+		Currency:  currency,
+		UpdatedAt: models.Now(),
+	}, nil
+}
+
+// ListStoreCredit fetches a user's store credit balances in every currency
+// they hold credit in via the payment service
+func (c *Clients) ListStoreCredit(ctx context.Context, userID string) ([]*models.StoreCreditBalance, error) {
+	// TODO: Implement actual gRPC call
+	balance, err := c.GetStoreCredit(ctx, userID, c.config.PaymentCurrency)
+	if err != nil {
+		return nil, err
+	}
+	return []*models.StoreCreditBalance{balance}, nil
+}
+
+// IssueStoreCredit adds store credit to a user's balance via the payment
+// service
+func (c *Clients) IssueStoreCredit(ctx context.Context, credit *models.StoreCredit) (*models.StoreCredit, error) {
+	// TODO: Implement actual gRPC call
+	credit.ID = "sc-new"
+	credit.CreatedAt = models.Now()
+	return credit, nil
+}
+
+// HoldStoreCredit sets aside amount of a user's store credit for an order
+// via the payment service, returning ErrInsufficientCredit if the balance
+// is short
+func (c *Clients) HoldStoreCredit(ctx context.Context, userID, orderID string, amount float64) (*models.StoreCreditHold, error) {
+	// TODO: Implement actual gRPC call
+	return &models.StoreCreditHold{
+		ID:      "sch-" + orderID,
+		UserID:  userID,
+		OrderID: orderID,
+		Amount:  amount,
+	}, nil
+}
+
+// CaptureStoreCredit spends held store credit on its order via the payment
+// service
+func (c *Clients) CaptureStoreCredit(ctx context.Context, holdID string) error {
+	// TODO: Implement actual gRPC call
+	return nil
+}
+
+// ReleaseStoreCredit returns held store credit to the user's balance via
+// the payment service
+func (c *Clients) ReleaseStoreCredit(ctx context.Context, holdID string) error {
+	// TODO: Implement actual gRPC call
+	return nil
+}
+
+// RefundOrderStoreCredit returns the store credit an order held or spent to
+// the customer's balance via the payment service. Refunding again, or an
+// order with no store credit, is a no-op.
+func (c *Clients) RefundOrderStoreCredit(ctx context.Context, orderID string) error {
+	// TODO: Implement actual gRPC call
+	return nil
+}
+
+// GetCoupon fetches a promo code and how often userID has redeemed it
+func (c *Clients) GetCoupon(ctx context.Context, code, userID string) (*models.Coupon, error) {
+	// TODO: Implement actual gRPC call
+	coupons := map[string]models.Coupon{
+		"SAVE10":   {Type: models.CouponTypePercentage, Value: 10},
+		"FIVEOFF":  {Type: models.CouponTypeFixedAmount, Value: 5, MinSubtotal: 20},
+		"FREESHIP": {Type: models.CouponTypeFreeShipping, PerUserLimit: 1},
+		"VIP20":    {Type: models.CouponTypePercentage, Value: 20, Segments: []string{"vip"}},
+	}
+	coupon, ok := coupons[code]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	coupon.Code = code
+	return &coupon, nil
+}
+
+// RedeemCoupon records a redemption of a promo code by userID on an order,
+// enforcing its usage limits atomically. It returns ErrCouponUsedUp if a
+// limit has been reached.
+func (c *Clients) RedeemCoupon(ctx context.Context, code, userID, orderID string) (string, error) {
+	// TODO: Implement actual gRPC call
+	return "cr-" + orderID, nil
+}
+
+// GetOrderCouponRedemption fetches the ID of the promo code redemption
+// recorded for an order. It returns ErrNotFound if there is none.
+func (c *Clients) GetOrderCouponRedemption(ctx context.Context, orderID string) (string, error) {
+	// TODO: Implement actual gRPC call
+	return "cr-" + orderID, nil
+}
+
+// ReleaseCouponRedemption undoes a promo code redemption, so it no longer
+// counts toward the code's limits
+func (c *Clients) ReleaseCouponRedemption(ctx context.Context, redemptionID string) error {
+	// TODO: Implement actual gRPC call
+	return nil
+}
+
+// GetGiftCard fetches a gift card by its code via the payment service
+func (c *Clients) GetGiftCard(ctx context.Context, code string) (*models.GiftCard, error) {
+	// TODO: Implement actual gRPC call
+	if !strings.HasPrefix(code, "GC") || len(code) < 8 {
+		return nil, ErrNotFound
+	}
+	return &models.GiftCard{
+		ID:            "gc-" + strings.ToLower(code[len(code)-4:]),
+		Code:          code,
+		Last4:         code[len(code)-4:],
+		InitialAmount: 50.00,
+		Balance:       20.00,
+		Currency:      c.config.PaymentCurrency,
+		Status:        models.GiftCardStatusActive,
+		CreatedAt:     models.Now(),
+	}, nil
+}
+
+// CreateGiftCard issues a gift card via the payment service, pending until
+// ActivateGiftCard is called once it is paid for. The service generates its
+// code.
+func (c *Clients) CreateGiftCard(ctx context.Context, card *models.GiftCard) (*models.GiftCard, error) {
+	// TODO: Implement actual gRPC call
+	card.ID = "gc-new"
+	card.Last4 = "7Q2X"
+	card.Balance = card.InitialAmount
+	card.Status = models.GiftCardStatusPending
+	card.CreatedAt = models.Now()
+	return card, nil
+}
+
+// ActivateGiftCard makes a paid-for gift card redeemable via the payment
+// service, which emails its code to the recipient
+func (c *Clients) ActivateGiftCard(ctx context.Context, giftCardID string) (*models.GiftCard, error) {
+	// TODO: Implement actual gRPC call
+	return &models.GiftCard{
+		ID:        giftCardID,
+		Last4:     "7Q2X",
+		Status:    models.GiftCardStatusActive,
+		CreatedAt: models.Now(),
+	}, nil
+}
+
+// CancelGiftCard voids a gift card that was never paid for via the payment
+// service
+func (c *Clients) CancelGiftCard(ctx context.Context, giftCardID string) error {
+	// TODO: Implement actual gRPC call
+	return nil
+}
+
+// HoldGiftCard sets aside amount of a gift card's balance for an order via
+// the payment service, returning ErrInsufficientGiftCard if the balance is
+// short
+func (c *Clients) HoldGiftCard(ctx context.Context, giftCardID, orderID string, amount float64) (*models.GiftCardHold, error) {
+	// TODO: Implement actual gRPC call
+	return &models.GiftCardHold{
+		ID:         "gch-" + giftCardID + "-" + orderID,
+		GiftCardID: giftCardID,
+		OrderID:    orderID,
+		Amount:     amount,
+	}, nil
+}
+
+// CaptureGiftCard spends a held gift card balance on its order via the
+// payment service
+func (c *Clients) CaptureGiftCard(ctx context.Context, holdID string) error {
+	// TODO: Implement actual gRPC call
+	return nil
+}
+
+// ReleaseGiftCard returns a held amount to its gift card's balance via the
+// payment service
+func (c *Clients) ReleaseGiftCard(ctx context.Context, holdID string) error {
+	// TODO: Implement actual gRPC call
+	return nil
+}
+
+// RefundOrderGiftCards returns the amounts an order held or spent on gift
+// cards to their balances via the payment service. Refunding again, or an
+// order with no gift cards, is a no-op.
+func (c *Clients) RefundOrderGiftCards(ctx context.Context, orderID string) error {
+	// TODO: Implement actual gRPC call
+	return nil
+}
+
+// GetOrderPayment fetches the latest payment intent for an order via the
+// payment service. It returns ErrNotFound if the order has no payment.
+func (c *Clients) GetOrderPayment(ctx context.Context, orderID, userID string) (*models.PaymentIntent, error) {
+	// TODO: Implement actual gRPC call
+	return c.GetPaymentIntent(ctx, "pi-"+orderID, userID)
+}
+
+// RefundPayment returns amount of a charged payment intent to the customer's
+// payment method via the payment service, for the given return if any
+func (c *Clients) RefundPayment(ctx context.Context, intent *models.PaymentIntent, amount float64, returnID string) (*models.Refund, error) {
+	// TODO: Implement actual gRPC call
+	return &models.Refund{
+		ID:              "re-" + returnID,
+		ReturnID:        returnID,
+		PaymentIntentID: intent.ID,
+		Method:          models.RefundMethodOriginalPayment,
+		Amount:          amount,
+		Currency:        intent.Currency,
+		Status:          models.RefundStatusSucceeded,
+		CreatedAt:       models.Now(),
+	}, nil
+}
+
+// CancelPayment cancels an unconfirmed payment intent, or refunds it in full
+// if it was already charged, via the payment service
+func (c *Clients) CancelPayment(ctx context.Context, intentID, userID string) error {
 	// TODO: Implement actual gRPC call
 	return nil
 }
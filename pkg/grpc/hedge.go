@@ -0,0 +1,70 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var grpcClientHedgesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "grpc_client_hedges_total",
+	Help: "Hedge requests sent for slow idempotent reads, by whether the hedge answered first.",
+}, []string{"method", "result"})
+
+// hedgeResult is the outcome of one attempt at a hedged call
+type hedgeResult[T any] struct {
+	value T
+	err   error
+	hedge bool
+}
+
+// hedged runs call, and if it has not answered after delay, runs it again
+// and returns whichever attempt answers first, cancelling the other. Each
+// attempt picks its own pooled connection, so a slow replica behind one
+// connection does not hold up the read. Only use it for idempotent reads. A
+// non-positive delay disables hedging.
+func hedged[T any](ctx context.Context, delay time.Duration, method string, call func(ctx context.Context) (T, error)) (T, error) {
+	if delay <= 0 {
+		return call(ctx)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult[T], 2)
+	attempt := func(hedge bool) {
+		value, err := call(ctx)
+		results <- hedgeResult[T]{value: value, err: err, hedge: hedge}
+	}
+	go attempt(false)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	pending, hedgeSent := 1, false
+	var last hedgeResult[T]
+	for {
+		select {
+		case <-timer.C:
+			hedgeSent = true
+			pending++
+			go attempt(true)
+		case last = <-results:
+			pending--
+			// Wait for the other attempt if this one failed
+			if last.err != nil && pending > 0 {
+				continue
+			}
+			if hedgeSent {
+				result := "lost"
+				if last.hedge && last.err == nil {
+					result = "won"
+				}
+				grpcClientHedgesTotal.WithLabelValues(method, result).Inc()
+			}
+			return last.value, last.err
+		}
+	}
+}
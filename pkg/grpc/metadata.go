@@ -0,0 +1,44 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/requestid"
+)
+
+// requestIDUnaryInterceptor attaches the request ID to outgoing unary calls
+func requestIDUnaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(withRequestID(ctx), method, req, reply, cc, opts...)
+}
+
+// requestIDStreamInterceptor attaches the request ID to outgoing streams
+func requestIDStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(withRequestID(ctx), desc, cc, method, opts...)
+}
+
+// withRequestID adds the context's request ID to the outgoing gRPC metadata
+// so backend services can correlate their logs with the gateway's
+func withRequestID(ctx context.Context) context.Context {
+	if id := requestid.FromContext(ctx); id != "" {
+		return metadata.AppendToOutgoingContext(ctx, requestid.MetadataKey, id)
+	}
+	return ctx
+}
+
+// upstreamUnaryInterceptor records the called service for the access log
+func upstreamUnaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	service, _ := splitMethod(method)
+	logging.RecordUpstream(ctx, service)
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// upstreamStreamInterceptor records the called service for the access log
+func upstreamStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	service, _ := splitMethod(method)
+	logging.RecordUpstream(ctx, service)
+	return streamer(ctx, desc, cc, method, opts...)
+}
@@ -0,0 +1,65 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	grpcClientCallsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_client_calls_total",
+		Help: "gRPC calls made to backend services, by region and result code.",
+	}, []string{"service", "method", "code", "backend_region"})
+
+	grpcClientCallDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_client_call_duration_seconds",
+		Help:    "gRPC call latency to backend services by region. Streams are timed until established.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"service", "method", "code", "backend_region"})
+)
+
+// metricsUnaryInterceptor records the count, result code, and latency of
+// outgoing unary calls to a region
+func metricsUnaryInterceptor(region string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		observeCall(method, region, err, time.Since(start))
+		return err
+	}
+}
+
+// metricsStreamInterceptor records the count, result code, and setup latency
+// of outgoing streams to a region
+func metricsStreamInterceptor(region string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		observeCall(method, region, err, time.Since(start))
+		return stream, err
+	}
+}
+
+// observeCall records a finished call under its service, method, and region
+func observeCall(fullMethod, region string, err error, elapsed time.Duration) {
+	service, method := splitMethod(fullMethod)
+	code := status.Code(err).String()
+
+	grpcClientCallsTotal.WithLabelValues(service, method, code, region).Inc()
+	grpcClientCallDuration.WithLabelValues(service, method, code, region).Observe(elapsed.Seconds())
+}
+
+// splitMethod splits "/package.Service/Method" into service and method
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "unknown", fullMethod
+}
@@ -0,0 +1,113 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// connPool holds multiple client connections to a single backend and
+// distributes calls across them round-robin. A single HTTP/2 connection
+// caps the number of concurrent streams, so opening several connections
+// raises the throughput ceiling under heavy load.
+type connPool struct {
+	name     string
+	conns    []*grpc.ClientConn
+	next     uint32
+	inflight atomic.Int64 // unary calls in progress
+}
+
+// drainPollInterval is how often a draining pool checks for in-flight calls
+const drainPollInterval = 100 * time.Millisecond
+
+// newConnPool dials size connections to addr. Connections that fail to dial
+// are logged and skipped so the gateway can still start without the backend.
+func newConnPool(ctx context.Context, name, addr string, size int, opts ...grpc.DialOption) *connPool {
+	if size < 1 {
+		size = 1
+	}
+
+	pool := &connPool{name: name}
+	opts = append(opts[:len(opts):len(opts)], grpc.WithChainUnaryInterceptor(pool.trackUnaryInterceptor))
+	for i := 0; i < size; i++ {
+		conn, err := grpc.DialContext(ctx, addr, opts...)
+		if err != nil {
+			slog.Warn("Failed to connect to backend", "service", name, "addr", addr, "connection", i+1, "pool_size", size, "error", err)
+			// Don't fail - service might not be available yet
+			continue
+		}
+		pool.conns = append(pool.conns, conn)
+	}
+	return pool
+}
+
+// Get returns the next connection in the pool, or nil if none are open
+func (p *connPool) Get() *grpc.ClientConn {
+	if p == nil || len(p.conns) == 0 {
+		return nil
+	}
+	n := atomic.AddUint32(&p.next, 1)
+	return p.conns[(n-1)%uint32(len(p.conns))]
+}
+
+// Size returns the number of open connections in the pool
+func (p *connPool) Size() int {
+	if p == nil {
+		return 0
+	}
+	return len(p.conns)
+}
+
+// Healthy reports whether at least one connection in the pool is ready
+func (p *connPool) Healthy() bool {
+	if p == nil {
+		return false
+	}
+	for _, conn := range p.conns {
+		if conn.GetState() == connectivity.Ready {
+			return true
+		}
+	}
+	return false
+}
+
+// trackUnaryInterceptor counts the pool's in-flight calls so it can be
+// drained before closing
+func (p *connPool) trackUnaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	p.inflight.Add(1)
+	defer p.inflight.Add(-1)
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// CloseWhenIdle closes the pool once the calls in flight on it have
+// finished, waiting at most maxWait. Callers must already have stopped
+// handing out its connections.
+func (p *connPool) CloseWhenIdle(maxWait time.Duration) {
+	if p == nil {
+		return
+	}
+	// Give calls that just got a connection time to start
+	deadline := time.Now().Add(maxWait)
+	time.Sleep(min(drainPollInterval, maxWait))
+	for p.inflight.Load() > 0 && time.Now().Before(deadline) {
+		time.Sleep(drainPollInterval)
+	}
+	if n := p.inflight.Load(); n > 0 {
+		slog.Warn("Closing backend connections with calls in flight", "service", p.name, "calls", n)
+	}
+	p.Close()
+}
+
+// Close closes all connections in the pool
+func (p *connPool) Close() {
+	if p == nil {
+		return
+	}
+	for _, conn := range p.conns {
+		conn.Close()
+	}
+}
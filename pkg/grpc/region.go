@@ -0,0 +1,191 @@
+package grpc
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+)
+
+// Ways of choosing the region a backend's calls go to
+const (
+	RegionSelectionStatic  = "static"  // the local region, then the failover order
+	RegionSelectionLatency = "latency" // the healthy region answering fastest
+)
+
+// latencyWeight is the weight of each new call in a region's moving average
+// latency
+const latencyWeight = 0.2
+
+var grpcClientRegionFailoversTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "grpc_client_region_failovers_total",
+	Help: "Calls sent to another region because the local region's backend was unhealthy.",
+}, []string{"backend", "backend_region"})
+
+// regionTarget is a backend's deployment in one region
+type regionTarget struct {
+	backend string
+	region  string
+	breaker *breaker
+	dial    func(ctx context.Context, addr string) *connPool
+
+	mu      sync.Mutex
+	latency time.Duration // moving average; zero until the first call
+	addr    string
+	pool    *connPool
+	shift   *targetShift // set while traffic moves from a previous target
+}
+
+// healthy reports whether the region can take calls: a connection is ready
+// and its circuit breaker is closed
+func (t *regionTarget) healthy() bool {
+	t.mu.Lock()
+	pool, shift := t.pool, t.shift
+	t.mu.Unlock()
+
+	ready := pool.Healthy() || (shift != nil && shift.from.Healthy())
+	return ready && !t.breaker.isOpen()
+}
+
+// observe folds a call's latency into the region's moving average
+func (t *regionTarget) observe(elapsed time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.latency == 0 {
+		t.latency = elapsed
+		return
+	}
+	t.latency = time.Duration(latencyWeight*float64(elapsed) + (1-latencyWeight)*float64(t.latency))
+}
+
+// averageLatency returns the region's moving average latency
+func (t *regionTarget) averageLatency() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.latency
+}
+
+// latencyUnaryInterceptor measures successful calls to the region
+func (t *regionTarget) latencyUnaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	start := time.Now()
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if err == nil {
+		t.observe(time.Since(start))
+	}
+	return err
+}
+
+// regionalBackend sends a backend service's calls to its nearest healthy
+// region, failing over to other regions while the local one is down
+type regionalBackend struct {
+	name      string
+	selection string
+	targets   []*regionTarget // local region first, then in failover order
+}
+
+// newRegionalBackend orders a backend's regions for selection: the local
+// region, the regions in failover, then any others by name
+func newRegionalBackend(name, selection, local string, failover []string, targets []*regionTarget) *regionalBackend {
+	rank := func(region string) int {
+		if region == local {
+			return -1
+		}
+		if i := slices.Index(failover, region); i >= 0 {
+			return i
+		}
+		return len(failover)
+	}
+	sort.SliceStable(targets, func(i, j int) bool {
+		ri, rj := rank(targets[i].region), rank(targets[j].region)
+		if ri != rj {
+			return ri < rj
+		}
+		return targets[i].region < targets[j].region
+	})
+	return &regionalBackend{
+		name:      name,
+		selection: selection,
+		targets:   targets,
+	}
+}
+
+// pick returns the region calls should go to. With no healthy region it
+// returns the local one, so calls fail with the local backend's error.
+func (b *regionalBackend) pick() *regionTarget {
+	if len(b.targets) == 0 {
+		return nil
+	}
+
+	var chosen *regionTarget
+	for _, t := range b.targets {
+		if !t.healthy() {
+			continue
+		}
+		if b.selection != RegionSelectionLatency {
+			chosen = t
+			break
+		}
+		// Regions not yet measured are tried before slower measured ones
+		if chosen == nil || t.averageLatency() < chosen.averageLatency() {
+			chosen = t
+		}
+	}
+	if chosen == nil {
+		return b.targets[0]
+	}
+	if chosen != b.targets[0] && !b.targets[0].healthy() {
+		grpcClientRegionFailoversTotal.WithLabelValues(b.name, chosen.region).Inc()
+	}
+	return chosen
+}
+
+// Get returns a connection in the region calls should go to, or nil if none
+// are open
+func (b *regionalBackend) Get() *grpc.ClientConn {
+	t := b.pick()
+	if t == nil {
+		return nil
+	}
+	return t.conns().Get()
+}
+
+// Healthy reports whether any region can take calls
+func (b *regionalBackend) Healthy() bool {
+	for _, t := range b.targets {
+		if t.healthy() {
+			return true
+		}
+	}
+	return false
+}
+
+// Regions reports each region's health
+func (b *regionalBackend) Regions() map[string]bool {
+	regions := make(map[string]bool, len(b.targets))
+	for _, t := range b.targets {
+		regions[t.region] = t.healthy()
+	}
+	return regions
+}
+
+// Close closes the connections to every region
+func (b *regionalBackend) Close() {
+	for _, t := range b.targets {
+		t.close()
+	}
+}
+
+// target returns the backend's deployment in region, or nil if it has none
+func (b *regionalBackend) target(region string) *regionTarget {
+	for _, t := range b.targets {
+		if t.region == region {
+			return t
+		}
+	}
+	return nil
+}
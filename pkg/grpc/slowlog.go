@@ -0,0 +1,64 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ecommerce/be-api-gin/internal/logging"
+	"github.com/ecommerce/be-api-gin/internal/redact"
+)
+
+var grpcClientSlowCallsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "grpc_client_slow_calls_total",
+	Help: "gRPC calls to backend services that exceeded the slow-call threshold, by region.",
+}, []string{"service", "method", "backend_region"})
+
+// slowCallUnaryInterceptor logs unary calls to a region taking longer than
+// threshold, including their request with sensitive fields redacted
+func slowCallUnaryInterceptor(region string, threshold time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		elapsed := time.Since(start)
+		if threshold <= 0 || elapsed < threshold {
+			return err
+		}
+
+		service, name := splitMethod(method)
+		grpcClientSlowCallsTotal.WithLabelValues(service, name, region).Inc()
+		logging.FromContext(ctx).Warn("Slow gRPC call",
+			"grpc_service", service,
+			"grpc_method", name,
+			"backend_region", region,
+			"code", status.Code(err).String(),
+			"duration_ms", elapsed.Milliseconds(),
+			"threshold_ms", threshold.Milliseconds(),
+			"request", string(redactedRequest(req)),
+		)
+		return err
+	}
+}
+
+// redactedRequest encodes a request message as JSON with sensitive fields
+// redacted
+func redactedRequest(req interface{}) []byte {
+	var data []byte
+	var err error
+	if msg, ok := req.(proto.Message); ok {
+		data, err = protojson.Marshal(msg)
+	} else {
+		data, err = json.Marshal(req)
+	}
+	if err != nil {
+		return []byte(`"` + redact.Placeholder + `"`)
+	}
+	return redact.JSON(data)
+}
@@ -0,0 +1,217 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/ecommerce/be-api-gin/internal/models"
+)
+
+// ErrTargetUnhealthy is returned when a new backend target could not be
+// connected to and health-checked, in which case traffic stays where it was
+var ErrTargetUnhealthy = errors.New("backend target is not healthy")
+
+// warmTimeout bounds how long a new target has to connect and pass its
+// health check
+const warmTimeout = 10 * time.Second
+
+var grpcClientTargetChangesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "grpc_client_target_changes_total",
+	Help: "Backend target changes, by whether the new target was warmed and took over or was rejected as unhealthy.",
+}, []string{"backend", "backend_region", "outcome"})
+
+// targetShift moves a region's traffic from a previous target to the
+// current one, sending the current target a share of calls that grows
+// linearly from none at start to all after duration
+type targetShift struct {
+	from     *connPool
+	fromAddr string
+	start    time.Time
+	duration time.Duration
+}
+
+// progress returns the share of calls going to the new target
+func (s *targetShift) progress() float64 {
+	if s.duration <= 0 {
+		return 1
+	}
+	return min(float64(time.Since(s.start))/float64(s.duration), 1)
+}
+
+// conns returns the pool a call should use, picking between the previous
+// and current targets while traffic shifts
+func (t *regionTarget) conns() *connPool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.shift != nil && rand.Float64() >= t.shift.progress() {
+		return t.shift.from
+	}
+	return t.pool
+}
+
+// close closes the connections to the current and any previous target
+func (t *regionTarget) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pool.Close()
+	if t.shift != nil {
+		t.shift.from.Close()
+	}
+}
+
+// retarget moves the region to addr. The new target's connections are
+// established and health-checked before it takes any calls; traffic then
+// shifts to it over shift, and the previous target's connections are closed
+// once the calls still on them have finished, waiting at most drain.
+func (t *regionTarget) retarget(ctx context.Context, addr string, shift, drain time.Duration) error {
+	warmCtx, cancel := context.WithTimeout(ctx, warmTimeout)
+	defer cancel()
+
+	pool := t.dial(warmCtx, addr)
+	if err := warm(warmCtx, pool); err != nil {
+		pool.Close()
+		grpcClientTargetChangesTotal.WithLabelValues(t.backend, t.region, "rejected").Inc()
+		slog.Warn("Backend target rejected", "backend", t.backend, "backend_region", t.region, "addr", addr, "error", err)
+		return ErrTargetUnhealthy
+	}
+
+	t.mu.Lock()
+	// A shift still in progress is cut short: its oldest target is drained
+	if t.shift != nil {
+		go t.shift.from.CloseWhenIdle(drain)
+	}
+	previous := t.pool
+	t.shift = &targetShift{from: previous, fromAddr: t.addr, start: time.Now(), duration: shift}
+	t.pool = pool
+	t.addr = addr
+	t.mu.Unlock()
+
+	grpcClientTargetChangesTotal.WithLabelValues(t.backend, t.region, "switched").Inc()
+	slog.Info("Backend target warmed, shifting traffic", "backend", t.backend, "backend_region", t.region, "addr", addr, "shift", shift)
+
+	time.AfterFunc(shift, func() {
+		t.mu.Lock()
+		current := t.shift != nil && t.shift.from == previous
+		if current {
+			t.shift = nil
+		}
+		t.mu.Unlock()
+		// A later retarget that cut this shift short drains previous itself
+		if current {
+			previous.CloseWhenIdle(drain)
+		}
+	})
+	return nil
+}
+
+// warm checks that every connection in a new pool is up and that the backend
+// reports itself serving. Backends without the standard health service are
+// judged by their connections alone.
+func warm(ctx context.Context, pool *connPool) error {
+	if pool.Size() == 0 || !pool.Healthy() {
+		return errors.New("no connection could be established")
+	}
+	for _, conn := range pool.conns {
+		resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		if status.Code(err) == codes.Unimplemented {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+			return errors.New("backend reports " + resp.GetStatus().String())
+		}
+	}
+	return nil
+}
+
+// status describes the region's target for the admin API
+func (t *regionTarget) status() models.BackendTarget {
+	healthy := t.healthy()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	target := models.BackendTarget{
+		Backend: t.backend,
+		Region:  t.region,
+		Addr:    t.addr,
+		Healthy: healthy,
+	}
+	if t.shift != nil {
+		target.PreviousAddr = t.shift.fromAddr
+		target.ShiftProgress = t.shift.progress()
+	}
+	return target
+}
+
+// backend returns the named backend service, or nil if there is none
+func (c *Clients) backend(name string) *regionalBackend {
+	switch name {
+	case "user":
+		return c.userBackend
+	case "listing":
+		return c.listingBackend
+	case "inventory":
+		return c.inventoryBackend
+	case "payment":
+		return c.paymentBackend
+	}
+	return nil
+}
+
+// BackendTargets describes every backend service's target in each region
+func (c *Clients) BackendTargets() []models.BackendTarget {
+	var targets []models.BackendTarget
+	for _, b := range []*regionalBackend{c.userBackend, c.listingBackend, c.inventoryBackend, c.paymentBackend} {
+		for _, t := range b.targets {
+			targets = append(targets, t.status())
+		}
+	}
+	return targets
+}
+
+// SetBackendTarget moves a backend service's calls in region to addr,
+// pre-warming the new target and shifting traffic to it gradually. With a
+// shared target store, the change is then saved for the other replicas to
+// apply. An empty region means the gateway's own region. It returns
+// ErrNotFound for an unknown backend or region, and ErrTargetUnhealthy if the
+// new target is not healthy, leaving the current one in place.
+func (c *Clients) SetBackendTarget(ctx context.Context, backend, region, addr string) (*models.BackendTarget, error) {
+	b := c.backend(backend)
+	if b == nil {
+		return nil, ErrNotFound
+	}
+	if region == "" {
+		region = c.config.Region
+	}
+	t := b.target(region)
+	if t == nil {
+		return nil, ErrNotFound
+	}
+
+	shift := time.Duration(c.config.GRPCTargetShiftSec) * time.Second
+	drain := time.Duration(c.config.GRPCTargetDrainSec) * time.Second
+	if err := t.retarget(ctx, addr, shift, drain); err != nil {
+		return nil, err
+	}
+	if c.targets != nil {
+		if err := c.targets.Save(ctx, backend, region, addr); err != nil {
+			return nil, fmt.Errorf("target changed on this instance but could not be shared: %w", err)
+		}
+	}
+	target := t.status()
+	return &target, nil
+}
@@ -0,0 +1,121 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	// targetSyncInterval is how often shared target changes are applied
+	targetSyncInterval = 5 * time.Second
+	// targetRetryAfter is how long a shared target this instance rejected
+	// as unhealthy is left before it is tried again
+	targetRetryAfter = time.Minute
+)
+
+// TargetStore shares backend target changes between gateway replicas
+type TargetStore interface {
+	// Save records addr as the target of a backend service in region
+	Save(ctx context.Context, backend, region, addr string) error
+	// Load returns every recorded target by backend and region
+	Load(ctx context.Context) (map[[2]string]string, error)
+}
+
+// RedisTargetStore is a TargetStore keeping targets in a Redis hash keyed
+// "backend|region"
+type RedisTargetStore struct {
+	client *goredis.Client
+	key    string
+}
+
+// NewRedisTargetStore creates a store keeping targets in the hash at key
+func NewRedisTargetStore(client *goredis.Client, key string) *RedisTargetStore {
+	return &RedisTargetStore{
+		client: client,
+		key:    key,
+	}
+}
+
+// Save records addr as the target of a backend service in region
+func (s *RedisTargetStore) Save(ctx context.Context, backend, region, addr string) error {
+	return s.client.HSet(ctx, s.key, backend+"|"+region, addr).Err()
+}
+
+// Load returns every recorded target by backend and region
+func (s *RedisTargetStore) Load(ctx context.Context) (map[[2]string]string, error) {
+	fields, err := s.client.HGetAll(ctx, s.key).Result()
+	if err != nil {
+		return nil, err
+	}
+	targets := make(map[[2]string]string, len(fields))
+	for field, addr := range fields {
+		if backend, region, ok := strings.Cut(field, "|"); ok {
+			targets[[2]string{backend, region}] = addr
+		}
+	}
+	return targets, nil
+}
+
+// ShareBackendTargets saves target changes made on this instance to store,
+// for SyncBackendTargets to apply on every replica
+func (c *Clients) ShareBackendTargets(store TargetStore) {
+	c.targets = store
+}
+
+// SyncBackendTargets applies the targets in the shared store now and every
+// targetSyncInterval until ctx is cancelled, so a change made on any replica
+// moves them all. Each change is warmed and shifted to like a local one. It
+// returns at once when no store is shared.
+func (c *Clients) SyncBackendTargets(ctx context.Context) {
+	if c.targets == nil {
+		return
+	}
+	rejected := make(map[[2]string]time.Time) // addresses rejected, by target, until retry
+	ticker := time.NewTicker(targetSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		c.applySharedTargets(ctx, rejected)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// applySharedTargets moves this instance's targets to those in the store
+func (c *Clients) applySharedTargets(ctx context.Context, rejected map[[2]string]time.Time) {
+	targets, err := c.targets.Load(ctx)
+	if err != nil {
+		slog.Warn("Failed to load shared backend targets", "error", err)
+		return
+	}
+
+	shift := time.Duration(c.config.GRPCTargetShiftSec) * time.Second
+	drain := time.Duration(c.config.GRPCTargetDrainSec) * time.Second
+	now := time.Now()
+	for key, addr := range targets {
+		b := c.backend(key[0])
+		if b == nil {
+			continue
+		}
+		t := b.target(key[1])
+		if t == nil || t.status().Addr == addr {
+			continue
+		}
+		retryKey := [2]string{key[0] + "|" + key[1], addr}
+		if now.Before(rejected[retryKey]) {
+			continue
+		}
+		if err := t.retarget(ctx, addr, shift, drain); err != nil {
+			rejected[retryKey] = now.Add(targetRetryAfter)
+			continue
+		}
+		delete(rejected, retryKey)
+	}
+}
@@ -0,0 +1,35 @@
+package redis
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/ecommerce/be-api-gin/internal/config"
+)
+
+// NewClient creates a Redis client from configuration, returning nil if
+// Redis is not configured. An unreachable server is logged rather than
+// treated as fatal so the gateway can start and degrade gracefully.
+func NewClient(cfg *config.Config) (*goredis.Client, error) {
+	if cfg.RedisURL == "" {
+		return nil, nil
+	}
+
+	opts, err := goredis.ParseURL(cfg.RedisURL)
+	if err != nil {
+		return nil, err
+	}
+	client := goredis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		slog.Warn("Failed to connect to Redis", "addr", opts.Addr, "error", err)
+		// Don't fail - Redis might not be available yet
+	}
+
+	return client, nil
+}
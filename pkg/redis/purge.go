@@ -0,0 +1,48 @@
+package redis
+
+import (
+	"context"
+	"errors"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// purgeScanCount is how many keys each SCAN step asks for
+const purgeScanCount = 100
+
+// deleteIfUnchanged deletes a key only if it still holds the value that was
+// read, so a purge never removes data written after it looked
+var deleteIfUnchanged = goredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Purge deletes the string keys matching pattern whose value stale reports
+// as due for removal, returning how many it deleted. Keys that are rewritten
+// while the purge runs are kept.
+func Purge(ctx context.Context, client *goredis.Client, pattern string, stale func(value []byte) bool) (int, error) {
+	purged := 0
+	iter := client.Scan(ctx, 0, pattern, purgeScanCount).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		value, err := client.Get(ctx, key).Bytes()
+		if errors.Is(err, goredis.Nil) {
+			continue
+		}
+		if err != nil {
+			return purged, err
+		}
+		if !stale(value) {
+			continue
+		}
+
+		deleted, err := deleteIfUnchanged.Run(ctx, client, []string{key}, value).Int()
+		if err != nil {
+			return purged, err
+		}
+		purged += deleted
+	}
+	return purged, iter.Err()
+}